@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/helpers"
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// AccountDeletionGracePeriod là thời gian từ lúc yêu cầu đến lúc tài khoản thực sự bị xóa
+	AccountDeletionGracePeriod = 30 * 24 * time.Hour
+	// AccountDeletionSweepInterval là chu kỳ quét các yêu cầu xóa đã đến hạn
+	AccountDeletionSweepInterval = 1 * time.Hour
+	accountDeletionBatchSize     = 50
+)
+
+// accountDataExport là gói dữ liệu cá nhân người dùng tải về
+type accountDataExport struct {
+	NgayXuat   time.Time                      `json:"ngay_xuat"`
+	HoSo       db.ExportUserProfileRow        `json:"ho_so"`
+	DatCho     []db.ExportUserBookingsRow     `json:"dat_cho"`
+	HanhKhach  []db.HanhKhach                 `json:"hanh_khach"`
+	DanhGia    []db.ExportUserReviewsRow      `json:"danh_gia"`
+	YeuThich   []db.ExportUserFavoritesRow    `json:"tour_yeu_thich"`
+	LichSuChat []db.ExportUserChatHistoryRow  `json:"lich_su_chat"`
+	LichSuXem  []db.ExportUserViewHistoryRow  `json:"lich_su_xem_tour"`
+	LienKet    []db.GetOAuthAccountsByUserRow `json:"tai_khoan_lien_ket"`
+}
+
+func (s *Server) collectAccountData(ctx context.Context, userID pgtype.UUID) (*accountDataExport, error) {
+	var (
+		data = &accountDataExport{NgayXuat: time.Now()}
+		err  error
+	)
+	if data.HoSo, err = s.z.ExportUserProfile(ctx, userID); err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	if data.DatCho, err = s.z.ExportUserBookings(ctx, userID); err != nil {
+		return nil, fmt.Errorf("bookings: %w", err)
+	}
+	if data.HanhKhach, err = s.z.ExportUserPassengers(ctx, userID); err != nil {
+		return nil, fmt.Errorf("passengers: %w", err)
+	}
+	if data.DanhGia, err = s.z.ExportUserReviews(ctx, userID); err != nil {
+		return nil, fmt.Errorf("reviews: %w", err)
+	}
+	if data.YeuThich, err = s.z.ExportUserFavorites(ctx, userID); err != nil {
+		return nil, fmt.Errorf("favorites: %w", err)
+	}
+	if data.LichSuChat, err = s.z.ExportUserChatHistory(ctx, userID); err != nil {
+		return nil, fmt.Errorf("chat history: %w", err)
+	}
+	if data.LichSuXem, err = s.z.ExportUserViewHistory(ctx, userID); err != nil {
+		return nil, fmt.Errorf("view history: %w", err)
+	}
+	if data.LienKet, err = s.z.GetOAuthAccountsByUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("oauth accounts: %w", err)
+	}
+	return data, nil
+}
+
+// zipAccountData đóng gói mỗi nhóm dữ liệu thành một file JSON trong file ZIP
+func zipAccountData(data *accountDataExport) ([]byte, error) {
+	files := []struct {
+		name  string
+		value any
+	}{
+		{"ho_so.json", data.HoSo},
+		{"dat_cho.json", data.DatCho},
+		{"hanh_khach.json", data.HanhKhach},
+		{"danh_gia.json", data.DanhGia},
+		{"tour_yeu_thich.json", data.YeuThich},
+		{"lich_su_chat.json", data.LichSuChat},
+		{"lich_su_xem_tour.json", data.LichSuXem},
+		{"tai_khoan_lien_ket.json", data.LienKet},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		content, err := json.MarshalIndent(f.value, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: data.NgayXuat})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportMyData godoc
+// @Summary Export my personal data
+// @Description Tải về dữ liệu cá nhân: hồ sơ, booking, hành khách, đánh giá, tour yêu thích, lịch sử chat và lịch sử xem tour. Mặc định JSON, format=zip trả về file ZIP
+// @Tags auth
+// @Produce json
+// @Produce application/zip
+// @Param format query string false "json hoặc zip" default(json)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/account/export [get]
+func (s *Server) ExportMyData(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format phải là json hoặc zip"})
+		return
+	}
+
+	data, err := s.collectAccountData(ctx, claims.Id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy người dùng"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data", "details": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("travia-du-lieu-ca-nhan-%s", data.NgayXuat.Format("20060102-150405"))
+	if format == "zip" {
+		archive, err := zipAccountData(data)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build archive", "details": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", filename))
+		c.Data(http.StatusOK, "application/zip", archive)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", filename))
+	c.JSON(http.StatusOK, data)
+}
+
+// GetMyAccountDeletion godoc
+// @Summary Get account deletion status
+// @Description Trạng thái yêu cầu xóa tài khoản gần nhất của người dùng hiện tại
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/account/deletion [get]
+func (s *Server) GetMyAccountDeletion(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	request, err := s.z.GetLatestAccountDeletion(ctx, claims.Id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chưa có yêu cầu xóa tài khoản"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get deletion request", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": request})
+}
+
+// RequestAccountDeletion godoc
+// @Summary Request account deletion
+// @Description Khách hàng yêu cầu xóa tài khoản (cần nhập lại mật khẩu). Tài khoản bị xóa sau 30 ngày ân hạn; booking và giao dịch được ẩn danh để lưu cho kế toán, dữ liệu còn lại bị xóa hẳn
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.RequestAccountDeletionRequest true "Mật khẩu và lý do"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/account/deletion [post]
+func (s *Server) RequestAccountDeletion(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	var req models.RequestAccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	user, err := s.z.GetUserById(ctx, claims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user", "details": err.Error()})
+		return
+	}
+	if !utils.CheckHashPassword(req.MatKhau, user.MatKhauMaHoa) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mật khẩu không chính xác"})
+		return
+	}
+
+	activeBookings, err := s.z.CountActiveBookingsByUser(ctx, claims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bookings", "details": err.Error()})
+		return
+	}
+	if activeBookings > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Bạn còn booking chưa hoàn tất, hãy hủy hoặc chờ chuyến đi kết thúc trước khi xóa tài khoản",
+			"details": fmt.Sprintf("%d booking đang hiệu lực", activeBookings),
+		})
+		return
+	}
+
+	request, err := s.z.CreateAccountDeletionRequest(ctx, db.CreateAccountDeletionRequestParams{
+		NguoiDungID: claims.Id,
+		LyDo:        req.LyDo,
+		HanXoa:      pgtype.Timestamp{Time: time.Now().Add(AccountDeletionGracePeriod), Valid: true},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tài khoản đã có yêu cầu xóa đang chờ xử lý"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create deletion request", "details": err.Error()})
+		return
+	}
+
+	helpers.SendAccountDeletionScheduledAsync(user.Email, user.HoTen, request.HanXoa.Time.Format("02/01/2006 15:04"), s.config.EmailConfig)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Đã ghi nhận yêu cầu xóa tài khoản",
+		"data":    request,
+	})
+}
+
+// CancelAccountDeletion godoc
+// @Summary Cancel account deletion
+// @Description Hủy yêu cầu xóa tài khoản khi vẫn còn trong thời gian ân hạn
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/account/deletion [delete]
+func (s *Server) CancelAccountDeletion(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	request, err := s.z.CancelAccountDeletion(ctx, claims.Id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không có yêu cầu xóa tài khoản đang chờ"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel deletion request", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã hủy yêu cầu xóa tài khoản",
+		"data":    request,
+	})
+}
+
+// StartAccountDeletionSweeper chạy định kỳ để xóa các tài khoản đã hết thời gian ân hạn.
+// Dừng khi ctx bị hủy.
+func (s *Server) StartAccountDeletionSweeper(ctx context.Context) {
+	ticker := time.NewTicker(AccountDeletionSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.eraseDueAccounts(ctx)
+		}
+	}
+}
+
+func (s *Server) eraseDueAccounts(ctx context.Context) {
+	sweepCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	requests, err := s.z.GetDueAccountDeletions(sweepCtx, accountDeletionBatchSize)
+	if err != nil {
+		log.Printf("[AccountDeletion] list due requests failed: %v", err)
+		return
+	}
+	for _, r := range requests {
+		if err := s.eraseAccount(sweepCtx, r); err != nil {
+			log.Printf("[AccountDeletion] request %d failed: %v", r.ID, err)
+			continue
+		}
+		log.Printf("[AccountDeletion] request %d: account erased", r.ID)
+	}
+}
+
+// eraseAccount thu hồi mọi phiên rồi ẩn danh/xóa dữ liệu của tài khoản
+func (s *Server) eraseAccount(ctx context.Context, request db.YeuCauXoaTaiKhoan) error {
+	// Khách đặt tour mới trong thời gian ân hạn: hoãn xóa đến khi chuyến đi kết thúc
+	activeBookings, err := s.z.CountActiveBookingsByUser(ctx, request.NguoiDungID)
+	if err != nil {
+		return fmt.Errorf("count active bookings: %w", err)
+	}
+	if activeBookings > 0 {
+		return fmt.Errorf("postponed, %d active bookings", activeBookings)
+	}
+
+	if _, err := s.revokeUserSessions(ctx, request.NguoiDungID, nil, SessionRevokeAccountDeleted); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+
+	// Mật khẩu ngẫu nhiên không ai biết để tài khoản không thể đăng nhập lại
+	secret, err := newInvitationCode()
+	if err != nil {
+		return fmt.Errorf("generate password: %w", err)
+	}
+	passwordHash, err := utils.HashPassword(secret)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	anonymizedEmail := fmt.Sprintf("deleted-%x@deleted.travia.local", request.NguoiDungID.Bytes)
+
+	if err := s.z.EraseUserAccount(ctx, request.ID, request.NguoiDungID, anonymizedEmail, passwordHash); err != nil {
+		return err
+	}
+	s.invalidatePermissionCache(ctx, request.NguoiDungID)
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	db "travia.backend/db/sqlc"
 )
@@ -708,11 +709,21 @@ func (s *Server) ApproveSupplier(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID"})
 		return
 	}
+	before, err := s.z.GetUserById(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Supplier not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
 	result, err := s.z.ApproveSupplier(context.Background(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
+	s.recordAudit(c, AuditSupplierApprove, AuditTargetSupplier, id.String(), before, result)
 	c.JSON(http.StatusOK, gin.H{"message": "Supplier approved successfully", "data": result})
 }
 
@@ -734,11 +745,21 @@ func (s *Server) RejectSupplier(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID"})
 		return
 	}
+	before, err := s.z.GetUserById(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Supplier not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
 	result, err := s.z.RejectSupplier(context.Background(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
+	s.recordAudit(c, AuditSupplierReject, AuditTargetSupplier, id.String(), before, result)
 	c.JSON(http.StatusOK, gin.H{"message": "Supplier rejected successfully", "data": result})
 }
 
@@ -760,12 +781,26 @@ func (s *Server) SoftDeleteSupplier(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID"})
 		return
 	}
-	err := s.z.SoftDeleteSupplier(context.Background(), id)
+	before, err := s.z.GetUserById(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Supplier not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	err = s.z.SoftDeleteSupplier(context.Background(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		log.Println("Error restoring supplier:", err)
 		return
 	}
+	var after any
+	if row, err := s.z.GetUserById(context.Background(), id); err == nil {
+		after = row
+	}
+	s.recordAudit(c, AuditSupplierSoftDelete, AuditTargetSupplier, id.String(), before, after)
 	c.JSON(http.StatusOK, gin.H{"message": "Supplier soft deleted successfully"})
 }
 
@@ -787,11 +822,21 @@ func (s *Server) RestoreSupplier(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID"})
 		return
 	}
+	before, err := s.z.GetUserById(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Supplier not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
 	data, err := s.z.RestoreSupplier(context.Background(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
+	s.recordAudit(c, AuditSupplierRestore, AuditTargetSupplier, id.String(), before, data)
 	c.JSON(http.StatusOK, gin.H{"message": "Supplier restored successfully", "data": data})
 }
 
@@ -0,0 +1,383 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/helpers"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// Hành động ghi vào nhật ký kiểm toán (nhat_ky_kiem_toan.hanh_dong)
+const (
+	AuditSupplierApprove     = "supplier.approve"
+	AuditSupplierReject      = "supplier.reject"
+	AuditSupplierSoftDelete  = "supplier.soft_delete"
+	AuditSupplierRestore     = "supplier.restore"
+	AuditTourUpdateStatus    = "tour.update_status"
+	AuditDepartureCancel     = "departure.cancel"
+	AuditUserUpdate          = "user.update"
+	AuditBlogDelete          = "blog.delete"
+	AuditContactStatus       = "contact.update_status"
+	AuditContactRead         = "contact.mark_read"
+	AuditPartnerCreate       = "partner.create"
+	AuditPartnerUpdate       = "partner.update"
+	AuditApiKeyCreate        = "api_key.create"
+	AuditApiKeyRevoke        = "api_key.revoke"
+	AuditJwtKeyRotate        = "jwt_key.rotate"
+	AuditTourRevisionApprove = "tour_revision.approve"
+	AuditTourRevisionReject  = "tour_revision.reject"
+	AuditRoleCreate          = "role.create"
+	AuditRoleDelete          = "role.delete"
+	AuditRolePermissions     = "role.set_permissions"
+	AuditUserRoles           = "user.set_roles"
+)
+
+// Loại đối tượng bị tác động (nhat_ky_kiem_toan.loai_doi_tuong)
+const (
+	AuditTargetSupplier     = "nha_cung_cap"
+	AuditTargetTour         = "tour"
+	AuditTargetDeparture    = "khoi_hanh_tour"
+	AuditTargetUser         = "nguoi_dung"
+	AuditTargetBlog         = "blog"
+	AuditTargetContact      = "lien_he"
+	AuditTargetPartner      = "doi_tac"
+	AuditTargetApiKey       = "khoa_api"
+	AuditTargetJwtKey       = "khoa_ky_jwt"
+	AuditTargetTourRevision = "phien_ban_tour"
+	AuditTargetRole         = "vai_tro"
+)
+
+// AuditExportMaxRows giới hạn số dòng một lần xuất CSV
+const AuditExportMaxRows = 10000
+
+// auditRedactedFields không bao giờ được lưu vào nhật ký
+var auditRedactedFields = []string{"mat_khau", "ma_loi_moi", "secret", "ma_bam"}
+
+// recordAudit ghi một thao tác đặc quyền vào nhật ký kiểm toán. before/after là trạng thái đối tượng
+// trước và sau thao tác (nil nếu không có, vd: xóa). Lỗi ghi nhật ký chỉ được log, không làm hỏng request
+func (s *Server) recordAudit(c *gin.Context, action, targetType, targetID string, before, after any) {
+	beforeMap := auditState(before)
+	afterMap := auditState(after)
+
+	arg := db.CreateAuditLogParams{
+		HanhDong:     action,
+		LoaiDoiTuong: targetType,
+		MaDoiTuong:   targetID,
+		DuLieuTruoc:  auditJSON(beforeMap),
+		DuLieuSau:    auditJSON(afterMap),
+		ThayDoi:      auditJSON(auditDiff(beforeMap, afterMap)),
+	}
+	if v, exists := c.Get("claims"); exists {
+		if claims, ok := v.(*utils.JwtClams); ok {
+			arg.NguoiThucHien = claims.Id
+			arg.EmailNguoiThucHien = &claims.Email
+			arg.VaiTro = &claims.Vaitro
+		}
+	}
+	if ip := GetClientIP(c); ip != "" {
+		arg.DiaChiIp = &ip
+	}
+	if requestID := c.GetString("requestID"); requestID != "" {
+		arg.MaYeuCau = &requestID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.z.CreateAuditLog(ctx, arg); err != nil {
+		log.Printf("[Audit] record %s %s/%s failed: %v", action, targetType, targetID, err)
+	}
+}
+
+// auditState chuyển trạng thái đối tượng thành map JSON và bỏ các trường nhạy cảm
+func auditState(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var state map[string]any
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	for key := range state {
+		for _, redacted := range auditRedactedFields {
+			if strings.Contains(key, redacted) {
+				delete(state, key)
+				break
+			}
+		}
+	}
+	return state
+}
+
+// auditDiff so sánh các trường có ở cả hai trạng thái; khi tạo/xóa thì toàn bộ trường là thay đổi.
+// Trường chỉ có ở một phía (vd: cột join thêm khi đọc trạng thái trước) được bỏ qua
+func auditDiff(before, after map[string]any) map[string]any {
+	if before == nil && after == nil {
+		return nil
+	}
+	diff := make(map[string]any)
+	switch {
+	case before == nil:
+		for key, value := range after {
+			diff[key] = gin.H{"truoc": nil, "sau": value}
+		}
+	case after == nil:
+		for key, value := range before {
+			diff[key] = gin.H{"truoc": value, "sau": nil}
+		}
+	default:
+		for key, oldValue := range before {
+			newValue, ok := after[key]
+			if !ok || key == "ngay_cap_nhat" || reflect.DeepEqual(oldValue, newValue) {
+				continue
+			}
+			diff[key] = gin.H{"truoc": oldValue, "sau": newValue}
+		}
+	}
+	return diff
+}
+
+func auditJSON(v map[string]any) []byte {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// auditLogFilters đọc bộ lọc chung của danh sách và xuất CSV
+func auditLogFilters(c *gin.Context) (db.CountAuditLogsParams, bool) {
+	var filters db.CountAuditLogsParams
+	if actor := c.Query("nguoi_thuc_hien"); actor != "" {
+		if err := filters.NguoiThucHien.Scan(actor); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "nguoi_thuc_hien không hợp lệ"})
+			return filters, false
+		}
+	}
+	optional := func(key string) *string {
+		if v := strings.TrimSpace(c.Query(key)); v != "" {
+			return &v
+		}
+		return nil
+	}
+	filters.VaiTro = optional("vai_tro")
+	filters.HanhDong = optional("hanh_dong")
+	filters.LoaiDoiTuong = optional("loai_doi_tuong")
+	filters.MaDoiTuong = optional("ma_doi_tuong")
+	filters.MaYeuCau = optional("ma_yeu_cau")
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date phải có dạng YYYY-MM-DD"})
+			return filters, false
+		}
+		filters.TuNgay = pgtype.Timestamp{Time: t, Valid: true}
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_date phải có dạng YYYY-MM-DD"})
+			return filters, false
+		}
+		// Bao gồm cả ngày kết thúc
+		filters.DenNgay = pgtype.Timestamp{Time: t.AddDate(0, 0, 1), Valid: true}
+	}
+	return filters, true
+}
+
+func listAuditLogsParams(filters db.CountAuditLogsParams, limit, offset int) db.ListAuditLogsParams {
+	return db.ListAuditLogsParams{
+		NguoiThucHien: filters.NguoiThucHien,
+		VaiTro:        filters.VaiTro,
+		HanhDong:      filters.HanhDong,
+		LoaiDoiTuong:  filters.LoaiDoiTuong,
+		MaDoiTuong:    filters.MaDoiTuong,
+		MaYeuCau:      filters.MaYeuCau,
+		TuNgay:        filters.TuNgay,
+		DenNgay:       filters.DenNgay,
+		Limit:         int32(limit),
+		Offset:        int32(offset),
+	}
+}
+
+func auditLogResponse(row db.NhatKyKiemToan) gin.H {
+	return gin.H{
+		"id":                    row.ID,
+		"nguoi_thuc_hien":       row.NguoiThucHien,
+		"email_nguoi_thuc_hien": row.EmailNguoiThucHien,
+		"vai_tro":               row.VaiTro,
+		"hanh_dong":             row.HanhDong,
+		"loai_doi_tuong":        row.LoaiDoiTuong,
+		"ma_doi_tuong":          row.MaDoiTuong,
+		"du_lieu_truoc":         rawJSONOrNil(row.DuLieuTruoc),
+		"du_lieu_sau":           rawJSONOrNil(row.DuLieuSau),
+		"thay_doi":              rawJSONOrNil(row.ThayDoi),
+		"dia_chi_ip":            row.DiaChiIp,
+		"ma_yeu_cau":            row.MaYeuCau,
+		"ngay_tao":              row.NgayTao,
+	}
+}
+
+func rawJSONOrNil(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.RawMessage(data)
+}
+
+// GetAuditLogs godoc
+// @Summary Get audit logs
+// @Description Tra cứu nhật ký kiểm toán thao tác đặc quyền (ai, vai trò, hành động, đối tượng, dữ liệu trước/sau, IP, request ID)
+// @Tags Admin
+// @Produce json
+// @Param nguoi_thuc_hien query string false "ID người thực hiện"
+// @Param vai_tro query string false "Vai trò người thực hiện"
+// @Param hanh_dong query string false "Hành động (vd: supplier.approve)"
+// @Param loai_doi_tuong query string false "Loại đối tượng (vd: nha_cung_cap, tour)"
+// @Param ma_doi_tuong query string false "ID đối tượng"
+// @Param ma_yeu_cau query string false "Request ID (header X-Request-ID)"
+// @Param start_date query string false "Từ ngày (YYYY-MM-DD)"
+// @Param end_date query string false "Đến ngày (YYYY-MM-DD)"
+// @Param limit query int false "Limit (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/audit-logs [get]
+func (s *Server) GetAuditLogs(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	filters, ok := auditLogFilters(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := s.z.ListAuditLogs(ctx, listAuditLogsParams(filters, limit, offset))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audit logs", "details": err.Error()})
+		return
+	}
+	total, err := s.z.CountAuditLogs(ctx, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count audit logs", "details": err.Error()})
+		return
+	}
+
+	data := make([]gin.H, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, auditLogResponse(row))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":     data,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": int64(offset+len(rows)) < total,
+	})
+}
+
+// ExportAuditLogs godoc
+// @Summary Export audit logs as CSV
+// @Description Xuất nhật ký kiểm toán theo cùng bộ lọc với /admin/audit-logs (tối đa 10000 dòng mới nhất)
+// @Tags Admin
+// @Produce text/csv
+// @Param nguoi_thuc_hien query string false "ID người thực hiện"
+// @Param vai_tro query string false "Vai trò người thực hiện"
+// @Param hanh_dong query string false "Hành động"
+// @Param loai_doi_tuong query string false "Loại đối tượng"
+// @Param ma_doi_tuong query string false "ID đối tượng"
+// @Param ma_yeu_cau query string false "Request ID"
+// @Param start_date query string false "Từ ngày (YYYY-MM-DD)"
+// @Param end_date query string false "Đến ngày (YYYY-MM-DD)"
+// @Security ApiKeyAuth
+// @Success 200 {file} file
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/audit-logs/export [get]
+func (s *Server) ExportAuditLogs(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	filters, ok := auditLogFilters(c)
+	if !ok {
+		return
+	}
+	rows, err := s.z.ListAuditLogs(ctx, listAuditLogsParams(filters, AuditExportMaxRows, 0))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audit logs", "details": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=nhat-ky-kiem-toan-%s.csv", time.Now().Format("20060102-150405")))
+	c.Status(http.StatusOK)
+
+	// BOM để Excel đọc đúng tiếng Việt
+	c.Writer.Write([]byte("\xEF\xBB\xBF"))
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"id", "ngay_tao", "nguoi_thuc_hien", "email_nguoi_thuc_hien", "vai_tro", "hanh_dong",
+		"loai_doi_tuong", "ma_doi_tuong", "thay_doi", "du_lieu_truoc", "du_lieu_sau", "dia_chi_ip", "ma_yeu_cau",
+	})
+	for _, row := range rows {
+		actor := ""
+		if row.NguoiThucHien.Valid {
+			actor = row.NguoiThucHien.String()
+		}
+		w.Write([]string{
+			strconv.FormatInt(row.ID, 10),
+			row.NgayTao.Time.Format(time.DateTime),
+			actor,
+			helpers.StringValue(row.EmailNguoiThucHien),
+			helpers.StringValue(row.VaiTro),
+			row.HanhDong,
+			row.LoaiDoiTuong,
+			row.MaDoiTuong,
+			string(row.ThayDoi),
+			string(row.DuLieuTruoc),
+			string(row.DuLieuSau),
+			helpers.StringValue(row.DiaChiIp),
+			helpers.StringValue(row.MaYeuCau),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("[Audit] export csv failed: %v", err)
+	}
+}
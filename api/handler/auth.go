@@ -5,118 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/markbates/goth"
-	"github.com/markbates/goth/gothic"
-	"github.com/markbates/goth/providers/facebook"
-	"github.com/markbates/goth/providers/google"
 	"travia.backend/api/helpers"
 	"travia.backend/api/models"
 	"travia.backend/api/utils"
 	db "travia.backend/db/sqlc"
 )
 
-func (s *Server) SetupAuthProviders() {
-	// Sử dụng secret key từ config
-	key := s.config.ServerConfig.SecretKey
-	maxAge := 86400 * 30 // 30 days
-	isProd := s.config.ServerConfig.Environment == "production"
-
-	store := cookie.NewStore([]byte(key))
-	options := sessions.Options{
-		Path:     "/",
-		Domain:   "",
-		MaxAge:   maxAge,
-		Secure:   isProd,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-	}
-	store.Options(options)
-
-	gothic.Store = store
-	goth.UseProviders(
-		google.New(
-			s.config.GoogleCloudConfig.GoogleClientId,
-			s.config.GoogleCloudConfig.GoogleClientSecret,
-			s.config.GoogleCloudConfig.GoogleRedirectUris,
-			"email",
-			"profile",
-		),
-		facebook.New("", "", "", ""),
-	)
-}
-
-func (s *Server) AuthHandler() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		provider := c.Param("provider")
-		fmt.Printf("AuthHandler called with provider: %s\n", provider)
-
-		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), gothic.ProviderParamKey, provider))
-
-		if provider != "google" {
-			helpers.BadRequest(c, "Unsupported OAuth provider", nil)
-			return
-		}
-
-		fmt.Println("Starting Google OAuth...")
-		gothic.BeginAuthHandler(c.Writer, c.Request)
-	}
-}
-
-func (s *Server) AuthCallbackHandler() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		provider := c.Param("provider")
-
-		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), gothic.ProviderParamKey, provider))
-
-		// Check if provider is supported
-		if provider != "google" {
-			helpers.BadRequest(c, "Unsupported OAuth provider", nil)
-			return
-		}
-		user, err := gothic.CompleteUserAuth(c.Writer, c.Request)
-		if err != nil {
-			helpers.BadRequest(c, "Failed to complete OAuth authentication", err)
-			return
-		}
-		var id pgtype.UUID
-		err = id.Scan(user.UserID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "xx",
-			})
-		}
-
-		tokenPair, err := utils.GenerateToken(id, user.Email, "user", s.config.ServerConfig.SecretKey)
-		if err != nil {
-			helpers.InternalServerError(c, "Failed to generate token", err)
-			return
-		}
-
-		response := map[string]interface{}{
-			"message": "Google OAuth login successful",
-			"user": map[string]interface{}{
-				"id":       user.UserID,
-				"email":    user.Email,
-				"name":     user.Name,
-				"picture":  user.AvatarURL,
-				"provider": user.Provider,
-			},
-			"token": tokenPair.AccessToken,
-		}
-
-		helpers.Ok(c, response)
-	}
-}
-
 // tạo tài khoản từ form
 // @summary Tạo tài khoản từ form
 // @description Tạo tài khoản từ form
@@ -360,11 +262,17 @@ func (s *Server) loginWithRole(c *gin.Context, requiredRole db.VaiTroNguoiDung)
 		return
 	}
 
+	// Tài khoản đang bị khóa tạm do đăng nhập sai nhiều lần
+	if s.checkLoginLockout(c, req.Email) {
+		return
+	}
+
 	// Get user by email
 	user, err := s.z.GetUserByEmail(context.Background(), req.Email)
 	if err != nil {
 		// Kiểm tra nếu lỗi là "no rows found" - có nghĩa là tài khoản không tồn tại
 		if errors.Is(err, pgx.ErrNoRows) {
+			s.recordLoginFailure(c, req.Email, pgtype.UUID{}, LoginFailUnknownEmail)
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Email hoặc mật khẩu không chính xác",
 			})
@@ -396,36 +304,15 @@ func (s *Server) loginWithRole(c *gin.Context, requiredRole db.VaiTroNguoiDung)
 
 	// Verify password
 	if !utils.CheckHashPassword(req.Password, user.MatKhauMaHoa) {
+		s.recordLoginFailure(c, req.Email, user.ID, LoginFailWrongPassword)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "Email hoặc mật khẩu không chính xác",
 		})
 		return
 	}
 
-	// Generate JWT tokens
-	tokenPair, err := utils.GenerateToken(user.ID, user.Email, string(user.VaiTro.VaiTroNguoiDung), s.config.ServerConfig.ApiSecret)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Không thể tạo token xác thực",
-		})
-		return
-	}
-
-	// Return tokens in response body (no cookies)
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Đăng nhập thành công",
-		"user": gin.H{
-			"id":       user.ID,
-			"email":    user.Email,
-			"name":     user.HoTen,
-			"role":     user.VaiTro.VaiTroNguoiDung,
-			"ngay_tao": user.NgayTao.Time.Format(time.DateTime),
-		},
-		"tokens": gin.H{
-			"accessToken":  tokenPair.AccessToken,
-			"refreshToken": tokenPair.RefreshToken,
-		},
-	})
+	// Tài khoản bật 2FA nhận challenge token, còn lại nhận cặp token ngay
+	s.completeLogin(c, user)
 }
 
 // đăng nhập (deprecated - sử dụng endpoint cụ thể theo vai trò)
@@ -465,11 +352,17 @@ func (s *Server) Login(c *gin.Context) {
 		return
 	}
 
+	// Tài khoản đang bị khóa tạm do đăng nhập sai nhiều lần
+	if s.checkLoginLockout(c, req.Email) {
+		return
+	}
+
 	// Get user by email
 	user, err := s.z.GetUserByEmail(context.Background(), req.Email)
 	if err != nil {
 		// Kiểm tra nếu lỗi là "no rows found" - có nghĩa là tài khoản không tồn tại
 		if errors.Is(err, pgx.ErrNoRows) {
+			s.recordLoginFailure(c, req.Email, pgtype.UUID{}, LoginFailUnknownEmail)
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Email hoặc mật khẩu không chính xác",
 			})
@@ -493,34 +386,15 @@ func (s *Server) Login(c *gin.Context) {
 
 	// Verify password
 	if !utils.CheckHashPassword(req.Password, user.MatKhauMaHoa) {
+		s.recordLoginFailure(c, req.Email, user.ID, LoginFailWrongPassword)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "Email hoặc mật khẩu không chính xác",
 		})
 		return
 	}
-	// Generate JWT tokens
-	tokenPair, err := utils.GenerateToken(user.ID, user.Email, string(user.VaiTro.VaiTroNguoiDung), s.config.ServerConfig.ApiSecret)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Không thể tạo token xác thực",
-		})
-		return
-	}
 
-	// Return tokens in response body (no cookies)
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Đăng nhập thành công",
-		"user": gin.H{
-			"id":    user.ID,
-			"email": user.Email,
-			"name":  user.HoTen,
-			"role":  user.VaiTro.VaiTroNguoiDung,
-		},
-		"tokens": gin.H{
-			"accessToken":  tokenPair.AccessToken,
-			"refreshToken": tokenPair.RefreshToken,
-		},
-	})
+	// Tài khoản bật 2FA nhận challenge token, còn lại nhận cặp token ngay
+	s.completeLogin(c, user)
 }
 
 // đăng nhập cho người dùng/khách hàng
@@ -596,15 +470,17 @@ func (s *Server) RefreshToken(c *gin.Context) {
 
 	// Validate refresh token
 	claims, err := utils.ValidateToken(req.RefreshToken, s.config.ServerConfig.ApiSecret)
-	if err != nil {
+	if err != nil || claims.TokenType != utils.TokenTypeRefresh || claims.SessionID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "Refresh token không hợp lệ hoặc đã hết hạn",
 		})
 		return
 	}
 
-	// Generate new token pair
-	tokenPair, err := utils.GenerateToken(claims.Id, claims.Email, claims.Vaitro, s.config.ServerConfig.ApiSecret)
+	ctx := c.Request.Context()
+
+	// Generate new token pair cho cùng phiên
+	tokenPair, err := utils.GenerateSessionToken(claims.Id, claims.Email, claims.Vaitro, claims.SessionID, s.config.ServerConfig.ApiSecret)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Không thể tạo token mới",
@@ -612,6 +488,43 @@ func (s *Server) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	// Xoay vòng: refresh token cũ chỉ dùng được đúng một lần
+	_, err = s.z.RotateSessionTokens(ctx, db.RotateSessionTokensParams{
+		ID:               claims.SessionID,
+		MaTokenLamMoiCu:  utils.HashToken(req.RefreshToken),
+		MaTokenLamMoiMoi: utils.HashToken(tokenPair.RefreshToken),
+		MaTokenTruyCap:   utils.HashToken(tokenPair.AccessToken),
+		ThoiHanToken:     pgtype.Timestamp{Time: time.Now().Add(utils.RefreshTokenDuration), Valid: true},
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Không thể làm mới phiên đăng nhập",
+			})
+			return
+		}
+
+		// Phiên còn hoạt động nhưng refresh token không phải token hiện hành:
+		// token cũ đã bị dùng lại (có thể bị đánh cắp) nên thu hồi cả phiên
+		session, getErr := s.z.GetSessionByID(ctx, claims.SessionID)
+		if getErr == nil && session.DangHoatDong != nil && *session.DangHoatDong &&
+			session.MaTokenLamMoi != utils.HashToken(req.RefreshToken) {
+			if _, revokeErr := s.revokeSession(ctx, session.NguoiDungID, session.ID, SessionRevokeTokenReuse); revokeErr != nil {
+				log.Printf("[Session] revoke session %d after token reuse failed: %v", session.ID, revokeErr)
+			}
+			log.Printf("[Session] refresh token reuse detected for session %d (user %s)", session.ID, session.NguoiDungID.String())
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Phát hiện refresh token bị dùng lại, phiên đăng nhập đã bị thu hồi. Vui lòng đăng nhập lại",
+			})
+			return
+		}
+
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Phiên đăng nhập đã hết hạn hoặc đã bị thu hồi",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Làm mới token thành công",
 		"tokens": gin.H{
@@ -623,14 +536,36 @@ func (s *Server) RefreshToken(c *gin.Context) {
 
 // đăng xuất
 // @summary Đăng xuất
-// @description Đăng xuất (tokens được xóa ở frontend)
+// @description Đăng xuất: thu hồi phiên đăng nhập hiện tại, access/refresh token của phiên không còn dùng được
 // @tags auth
 // @accept json
 // @produce json
+// @Security ApiKeyAuth
 // @success 200 {object} gin.H "Thành công"
+// @failure 401 {object} gin.H "Chưa xác thực"
+// @failure 500 {object} gin.H "Lỗi server"
 // @router /auth/logout [post]
 func (s *Server) Logout(c *gin.Context) {
-	// Tokens are stored in localStorage on frontend, so no need to clear cookies
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
+	if jwtClaims.SessionID != 0 {
+		if _, err := s.revokeSession(c.Request.Context(), jwtClaims.Id, jwtClaims.SessionID, SessionRevokeLogout); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Không thể đăng xuất",
+			})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Đăng xuất thành công",
 	})
@@ -741,6 +676,19 @@ func (s *Server) UpdateUserById(c *gin.Context) {
 		}
 	}
 
+	before, err := s.z.GetUserById(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Không tìm thấy user",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Không thể cập nhật thông tin user",
+		})
+		return
+	}
 	user, err := s.z.UpdateUserById(context.Background(), db.UpdateUserByIdParams{
 		ID:          id,
 		HoTen:       req.FullName,
@@ -754,6 +702,7 @@ func (s *Server) UpdateUserById(c *gin.Context) {
 		})
 		return
 	}
+	s.recordAudit(c, AuditUserUpdate, AuditTargetUser, id.String(), before, user)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Cập nhật thông tin user thành công",
 		"data": gin.H{
@@ -877,6 +826,10 @@ func (s *Server) ChangePassword(c *gin.Context) {
 		})
 		return
 	}
+	// Đổi mật khẩu thì đăng xuất các thiết bị khác
+	if _, err := s.revokeUserSessions(context.Background(), claims.(*utils.JwtClams).Id, currentSessionID(claims.(*utils.JwtClams)), SessionRevokePasswordChange); err != nil {
+		log.Printf("[Auth] Failed to revoke sessions after password change: %v", err)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Đổi mật khẩu thành công",
 	})
@@ -1088,11 +1041,16 @@ func (s *Server) ResetPassword(c *gin.Context) {
 		return
 	}
 
+	// Đặt lại mật khẩu thì đăng xuất mọi thiết bị
+	if _, err := s.revokeUserSessions(context.Background(), otp.NguoiDungID, nil, SessionRevokePasswordReset); err != nil {
+		log.Printf("[Auth] Failed to revoke sessions after password reset: %v", err)
+	}
+
 	// Invalidate all OTPs for this email after successful password reset
 	err = s.z.InvalidateAllOTPsForEmail(context.Background(), req.Email)
 	if err != nil {
 		// Log but don't fail
-		fmt.Printf("Warning: Failed to invalidate OTPs after password reset: %v\n", err)
+		log.Printf("[Auth] Failed to invalidate OTPs after password reset: %v", err)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// Sorted set: câu tìm kiếm đã chuẩn hóa -> số lần được tìm / chọn
+	popularQueriesKey = "autocomplete:popular_queries"
+	// Sorted set: "<loai>:<id>" -> số lần gợi ý được chọn
+	selectedSuggestionsKey = "autocomplete:selected"
+
+	popularQueriesMaxSize      = 5000
+	popularQueriesScanSize     = 200
+	selectedSuggestionsMaxSize = 5000
+	autocompleteMaxQuery       = 100
+	autocompleteMaxLimit       = 20
+)
+
+// autocompleteSuggestion là một gợi ý: tour, điểm đến, danh mục hoặc câu tìm kiếm phổ biến (tu_khoa)
+type autocompleteSuggestion struct {
+	Loai    string  `json:"loai"`
+	ID      int32   `json:"id,omitempty"`
+	Nhan    string  `json:"nhan"`
+	MoTaPhu string  `json:"mo_ta_phu,omitempty"`
+	Diem    float64 `json:"diem"`
+}
+
+// popularityBoost đổi số lượt tìm / chọn thành điểm cộng, tăng theo log và tối đa 0.3
+func popularityBoost(count float64) float64 {
+	if count <= 0 {
+		return 0
+	}
+	return math.Min(0.3, 0.05*math.Log1p(count))
+}
+
+// recordSearchQuery tăng độ phổ biến của câu tìm kiếm, giữ lại tối đa popularQueriesMaxSize câu phổ biến nhất
+func (s *Server) recordSearchQuery(ctx context.Context, query string) {
+	q := utils.NormalizeSearchQuery(query)
+	if len([]rune(q)) < 2 || len([]rune(q)) > autocompleteMaxQuery {
+		return
+	}
+	pipe := s.redis.TxPipeline()
+	pipe.ZIncrBy(ctx, popularQueriesKey, 1, q)
+	pipe.ZRemRangeByRank(ctx, popularQueriesKey, 0, -popularQueriesMaxSize-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[Autocomplete] record query failed: %v", err)
+	}
+}
+
+// popularQuerySuggestions lấy các câu tìm kiếm phổ biến khớp tiền tố (của cả câu hoặc của một từ)
+func (s *Server) popularQuerySuggestions(ctx context.Context, q string) []autocompleteSuggestion {
+	popular, err := s.redis.ZRevRangeWithScores(ctx, popularQueriesKey, 0, popularQueriesScanSize-1).Result()
+	if err != nil {
+		return nil
+	}
+
+	var result []autocompleteSuggestion
+	for _, z := range popular {
+		text, _ := z.Member.(string)
+		if text == "" || text == q {
+			continue
+		}
+		var score float64
+		switch {
+		case strings.HasPrefix(text, q):
+			score = 0.6
+		case strings.Contains(" "+text, " "+q):
+			score = 0.4
+		default:
+			continue
+		}
+		result = append(result, autocompleteSuggestion{
+			Loai: "tu_khoa",
+			Nhan: text,
+			Diem: score + popularityBoost(z.Score),
+		})
+	}
+	return result
+}
+
+// Autocomplete godoc
+// @Summary Gợi ý tìm kiếm
+// @Description Gợi ý hỗn hợp tour, điểm đến, danh mục và câu tìm kiếm phổ biến ngay khi gõ (khớp tiền tố, không dấu, chịu lỗi chính tả như "Ha Lng").
+// @Description Gợi ý được chọn nhiều (POST /tour/autocomplete/select) và câu được tìm nhiều được ưu tiên
+// @Tags tour
+// @Produce json
+// @Param q query string true "Nội dung đang gõ"
+// @Param limit query int false "Số gợi ý (tối đa 20)" default(10)
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /tour/autocomplete [get]
+func (s *Server) Autocomplete(c *gin.Context) {
+	raw := strings.TrimSpace(c.Query("q"))
+	if len([]rune(raw)) > autocompleteMaxQuery {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nội dung tìm kiếm quá dài"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > autocompleteMaxLimit {
+		limit = autocompleteMaxLimit
+	}
+
+	q := utils.NormalizeSearchQuery(raw)
+	if q == "" {
+		c.JSON(http.StatusOK, gin.H{"q": raw, "data": []autocompleteSuggestion{}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	rows, err := s.z.GetAutocompleteSuggestions(ctx, db.GetAutocompleteSuggestionsParams{
+		Q:     q,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy gợi ý", "details": err.Error()})
+		return
+	}
+
+	suggestions := make([]autocompleteSuggestion, 0, len(rows)+limit)
+	members := make([]string, 0, len(rows))
+	for _, r := range rows {
+		suggestions = append(suggestions, autocompleteSuggestion{
+			Loai:    r.Loai,
+			ID:      r.ID,
+			Nhan:    r.Nhan,
+			MoTaPhu: r.MoTaPhu,
+			Diem:    r.Diem,
+		})
+		members = append(members, fmt.Sprintf("%s:%d", r.Loai, r.ID))
+	}
+
+	// Ưu tiên gợi ý được người dùng chọn nhiều; Redis lỗi thì bỏ qua phần ưu tiên
+	if len(members) > 0 {
+		if counts, err := s.redis.ZMScore(ctx, selectedSuggestionsKey, members...).Result(); err == nil {
+			for i := range suggestions {
+				suggestions[i].Diem += popularityBoost(counts[i])
+			}
+		}
+	}
+	suggestions = append(suggestions, s.popularQuerySuggestions(ctx, q)...)
+
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Diem > suggestions[j].Diem })
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	for i := range suggestions {
+		suggestions[i].Diem = math.Round(suggestions[i].Diem*1000) / 1000
+	}
+
+	c.JSON(http.StatusOK, gin.H{"q": raw, "data": suggestions})
+}
+
+// SelectAutocompleteSuggestion godoc
+// @Summary Ghi nhận gợi ý được chọn
+// @Description Tăng độ phổ biến của gợi ý và câu tìm kiếm để các lần gợi ý sau ưu tiên chúng.
+// @Description Chỉ ghi nhận gợi ý thực sự xuất hiện trong kết quả gợi ý của q (tu_khoa: câu đã có trong danh sách phổ biến)
+// @Tags tour
+// @Accept json
+// @Produce json
+// @Param request body models.AutocompleteSelectRequest true "Gợi ý đã chọn"
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Gợi ý không có trong kết quả của q"
+// @Failure 429 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /tour/autocomplete/select [post]
+func (s *Server) SelectAutocompleteSuggestion(c *gin.Context) {
+	var req models.AutocompleteSelectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dữ liệu không hợp lệ", "details": err.Error()})
+		return
+	}
+	if req.Loai != "tu_khoa" && req.ID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Thiếu id của gợi ý"})
+		return
+	}
+	q := utils.NormalizeSearchQuery(req.Q)
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Thiếu nội dung tìm kiếm"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	if req.Loai == "tu_khoa" {
+		// Câu tìm kiếm phổ biến chỉ được gợi ý từ popularQueriesKey, câu lạ không được thêm vào
+		if err := s.redis.ZScore(ctx, popularQueriesKey, q).Err(); err != nil {
+			if errors.Is(err, redis.Nil) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy gợi ý"})
+				return
+			}
+			log.Printf("[Autocomplete] check popular query failed: %v", err)
+			c.Status(http.StatusNoContent)
+			return
+		}
+		s.recordSearchQuery(ctx, q)
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	// Gợi ý được chọn phải nằm trong kết quả gợi ý của q: vừa xác nhận id tồn tại, vừa chỉ ghi nhận câu có kết quả
+	rows, err := s.z.GetAutocompleteSuggestions(ctx, db.GetAutocompleteSuggestionsParams{
+		Q:     q,
+		Limit: autocompleteMaxLimit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể kiểm tra gợi ý", "details": err.Error()})
+		return
+	}
+	found := false
+	for _, r := range rows {
+		if r.Loai == req.Loai && r.ID == req.ID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy gợi ý"})
+		return
+	}
+
+	member := fmt.Sprintf("%s:%d", req.Loai, req.ID)
+	pipe := s.redis.TxPipeline()
+	pipe.ZIncrBy(ctx, selectedSuggestionsKey, 1, member)
+	pipe.ZRemRangeByRank(ctx, selectedSuggestionsKey, 0, -selectedSuggestionsMaxSize-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[Autocomplete] record selection failed: %v", err)
+	}
+	s.recordSearchQuery(ctx, q)
+	c.Status(http.StatusNoContent)
+}
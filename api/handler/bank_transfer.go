@@ -0,0 +1,509 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"travia.backend/api/helpers"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// BankTransferGatewayID là cong_thanh_toan.id của thanh toán chuyển khoản
+	BankTransferGatewayID = "bank_transfer"
+	// BankStatementMaxFileSize là dung lượng tối đa của file sao kê tải lên
+	BankStatementMaxFileSize = 5 << 20
+	// VietQRImageSize là kích thước ảnh PNG mã VietQR (pixel)
+	VietQRImageSize = 512
+)
+
+type CreateBankTransferRequest struct {
+	BookingID int32 `json:"booking_id" binding:"required"`
+}
+
+// BankReconcileSummary là kết quả một lần đối soát sao kê
+type BankReconcileSummary struct {
+	DaDoiSoat int `json:"da_doi_soat"`
+	SaiSoTien int `json:"sai_so_tien"`
+	KhongKhop int `json:"khong_khop"`
+	Loi       int `json:"loi"`
+}
+
+// CreateBankTransfer tạo hướng dẫn chuyển khoản kèm mã VietQR cho booking
+// @Summary Tạo hướng dẫn chuyển khoản (VietQR)
+// @Description Tạo (hoặc tái sử dụng) giao dịch chuyển khoản với nội dung chuyển khoản duy nhất cho booking, trả về thông tin tài khoản nhận và mã VietQR
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param request body CreateBankTransferRequest true "Booking"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /payment/bank-transfer/create [post]
+func (s *Server) CreateBankTransfer(c *gin.Context) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	claimsMap, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req CreateBankTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bankConfig := s.config.BankTransferConfig
+	if bankConfig.BankBin == "" || bankConfig.AccountNumber == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Chưa cấu hình tài khoản nhận chuyển khoản"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	booking, ok := s.getPayableBooking(c, ctx, req.BookingID, claimsMap)
+	if !ok {
+		return
+	}
+	tongTien, err := booking.TongTien.Float64Value()
+	if err != nil || !tongTien.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking không có số tiền hợp lệ"})
+		return
+	}
+	if booking.DonViTienTe != nil && *booking.DonViTienTe != "" && !strings.EqualFold(*booking.DonViTienTe, "VND") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chuyển khoản chỉ hỗ trợ booking thanh toán bằng VND"})
+		return
+	}
+
+	// Tái sử dụng nội dung chuyển khoản đang chờ nếu số tiền không đổi,
+	// để khách đã chuyển theo mã cũ vẫn được đối soát
+	var transaction db.LichSuGiaoDich
+	existing, err := s.z.GetPendingBankTransferByBooking(ctx, &booking.ID)
+	switch {
+	case err == nil && sameAmount(existing.SoTien, tongTien.Float64):
+		transaction = existing
+	case err == nil || errors.Is(err, pgx.ErrNoRows):
+		if err == nil {
+			// Số tiền booking đã thay đổi: hủy mã cũ và tạo mã mới
+			if _, err := s.z.FailPendingTransaction(ctx, db.FailPendingTransactionParams{ID: existing.ID}); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				fmt.Printf("ERROR: Failed to expire bank transfer %d: %v\n", existing.ID, err)
+			}
+		}
+		memo, err := helpers.GenerateTransferMemo(booking.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo nội dung chuyển khoản", "details": err.Error()})
+			return
+		}
+		congThanhToanID := BankTransferGatewayID
+		bookingID := booking.ID
+		transaction, err = s.z.CreateTransaction(ctx, db.CreateTransactionParams{
+			DatChoID:           &bookingID,
+			NguoiDungID:        booking.NguoiDungID,
+			MaGiaoDichNoiBo:    memo,
+			CongThanhToanID:    &congThanhToanID,
+			SoTien:             booking.TongTien,
+			NoiDungChuyenKhoan: &memo,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Không thể tạo giao dịch chuyển khoản",
+				"details": err.Error(),
+			})
+			return
+		}
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get pending bank transfer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	payload, err := helpers.BuildVietQRPayload(bankConfig.BankBin, bankConfig.AccountNumber, int64(math.Round(tongTien.Float64)), transaction.MaGiaoDichNoiBo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo mã VietQR", "details": err.Error()})
+		return
+	}
+	png, err := helpers.VietQRPNG(payload, VietQRImageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo ảnh mã VietQR", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bank transfer instructions created successfully",
+		"data": gin.H{
+			"booking_id":            booking.ID,
+			"transaction_code":      transaction.MaGiaoDichNoiBo,
+			"noi_dung_chuyen_khoan": transaction.MaGiaoDichNoiBo,
+			"so_tien":               int64(math.Round(tongTien.Float64)),
+			"don_vi_tien_te":        "VND",
+			"ngan_hang": gin.H{
+				"bin":           bankConfig.BankBin,
+				"ten_ngan_hang": bankConfig.BankName,
+				"so_tai_khoan":  bankConfig.AccountNumber,
+				"chu_tai_khoan": bankConfig.AccountName,
+			},
+			"qr_payload": payload,
+			"qr_image":   "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+		},
+	})
+}
+
+// GetBankTransferQR trả về ảnh PNG mã VietQR của giao dịch chuyển khoản
+// @Summary Ảnh mã VietQR
+// @Description Trả về ảnh PNG mã VietQR của giao dịch chuyển khoản đang chờ
+// @Tags Payment
+// @Produce png
+// @Param transaction_code path string true "Mã giao dịch (nội dung chuyển khoản)"
+// @Security ApiKeyAuth
+// @Success 200 {file} file
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /payment/bank-transfer/{transaction_code}/qr.png [get]
+func (s *Server) GetBankTransferQR(c *gin.Context) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	claimsMap, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	transaction, err := s.z.GetTransactionByCode(ctx, c.Param("transaction_code"))
+	if err != nil || transaction.CongThanhToanID == nil || *transaction.CongThanhToanID != BankTransferGatewayID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	if transaction.NguoiDungID.String() != claimsMap.Id.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	if !transaction.TrangThai.Valid || transaction.TrangThai.TrangThaiThanhToan != "dang_cho_thanh_toan" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Giao dịch không còn chờ chuyển khoản"})
+		return
+	}
+
+	soTien, err := transaction.SoTien.Float64Value()
+	if err != nil || !soTien.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Giao dịch không có số tiền hợp lệ"})
+		return
+	}
+	bankConfig := s.config.BankTransferConfig
+	payload, err := helpers.BuildVietQRPayload(bankConfig.BankBin, bankConfig.AccountNumber, int64(math.Round(soTien.Float64)), transaction.MaGiaoDichNoiBo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo mã VietQR", "details": err.Error()})
+		return
+	}
+	png, err := helpers.VietQRPNG(payload, VietQRImageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo ảnh mã VietQR", "details": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// UploadBankStatement tải lên sao kê ngân hàng (CSV) và đối soát ngay
+// @Summary Tải lên sao kê ngân hàng
+// @Description Admin tải lên file CSV sao kê (cột mã giao dịch, ngày, số tiền, nội dung); các dòng tiền vào được đối soát với giao dịch chuyển khoản đang chờ theo nội dung và số tiền
+// @Tags Admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File sao kê CSV"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/bank-statements/upload [post]
+func (s *Server) UploadBankStatement(c *gin.Context) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	claimsMap, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File sao kê là bắt buộc", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > BankStatementMaxFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File sao kê vượt quá 5MB"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Không thể đọc file", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	lines, err := helpers.ParseBankStatementCSV(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File sao kê không hợp lệ", "details": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	tenTep := fileHeader.Filename
+	var nguoiTaiLenID pgtype.UUID
+	if err := nguoiTaiLenID.Scan(claimsMap.Id.String()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	inserted, skipped := 0, 0
+	for _, line := range lines {
+		var soTien pgtype.Numeric
+		if err := soTien.Scan(fmt.Sprintf("%.2f", line.SoTien)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Số tiền không hợp lệ", "details": err.Error()})
+			return
+		}
+		var ngayGiaoDich pgtype.Timestamp
+		if line.NgayGiaoDich != nil {
+			ngayGiaoDich = pgtype.Timestamp{Time: *line.NgayGiaoDich, Valid: true}
+		}
+		_, err := s.z.CreateBankStatementLine(ctx, db.CreateBankStatementLineParams{
+			MaGiaoDichNganHang: line.MaGiaoDich,
+			NgayGiaoDich:       ngayGiaoDich,
+			SoTien:             soTien,
+			NoiDung:            line.NoiDung,
+			TenTep:             &tenTep,
+			NguoiTaiLenID:      nguoiTaiLenID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			skipped++ // Dòng đã được tải lên trước đó
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to save bank statement line",
+				"details": err.Error(),
+			})
+			return
+		}
+		inserted++
+	}
+
+	summary, err := s.ReconcileBankStatements(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to reconcile bank statements",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bank statement uploaded successfully",
+		"data": gin.H{
+			"so_dong_doc_duoc": len(lines),
+			"so_dong_moi":      inserted,
+			"so_dong_trung":    skipped,
+			"doi_soat":         summary,
+		},
+	})
+}
+
+// ReconcileBankStatementsHandler chạy lại đối soát các dòng sao kê chưa đối soát
+// @Summary Đối soát sao kê ngân hàng
+// @Description Đối soát lại các dòng sao kê chưa đối soát với giao dịch chuyển khoản đang chờ
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/bank-statements/reconcile [post]
+func (s *Server) ReconcileBankStatementsHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	summary, err := s.ReconcileBankStatements(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to reconcile bank statements",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bank statements reconciled successfully",
+		"data":    summary,
+	})
+}
+
+// GetBankStatementLines danh sách dòng sao kê đã tải lên
+// @Summary Danh sách dòng sao kê ngân hàng
+// @Description Admin xem các dòng sao kê và kết quả đối soát
+// @Tags Admin
+// @Produce json
+// @Param trang_thai query string false "chua_doi_soat | da_doi_soat | sai_so_tien | khong_khop"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/bank-statements [get]
+func (s *Server) GetBankStatementLines(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var trangThai db.NullTrangThaiDoiSoat
+	if v := c.Query("trang_thai"); v != "" {
+		switch db.TrangThaiDoiSoat(v) {
+		case db.TrangThaiDoiSoatChuaDoiSoat, db.TrangThaiDoiSoatDaDoiSoat, db.TrangThaiDoiSoatSaiSoTien, db.TrangThaiDoiSoatKhongKhop:
+			trangThai = db.NullTrangThaiDoiSoat{TrangThaiDoiSoat: db.TrangThaiDoiSoat(v), Valid: true}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Trạng thái đối soát không hợp lệ"})
+			return
+		}
+	}
+
+	lines, err := s.z.GetBankStatementLines(ctx, db.GetBankStatementLinesParams{
+		TrangThai: trangThai,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get bank statement lines",
+			"details": err.Error(),
+		})
+		return
+	}
+	totalCount, err := s.z.CountBankStatementLines(ctx, trangThai)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to count bank statement lines",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Bank statement lines fetched successfully",
+		"data":     lines,
+		"total":    totalCount,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": (offset + limit) < int(totalCount),
+	})
+}
+
+// ReconcileBankStatements đối soát các dòng sao kê chưa đối soát với giao dịch chuyển khoản đang chờ:
+// khớp nội dung chuyển khoản và số tiền thì giao dịch thanh_cong, booking da_thanh_toan
+func (s *Server) ReconcileBankStatements(ctx context.Context) (*BankReconcileSummary, error) {
+	lines, err := s.z.GetUnreconciledBankStatementLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &BankReconcileSummary{}
+	for _, line := range lines {
+		transaction, err := s.z.FindPendingBankTransferByContent(ctx, helpers.NormalizeTransferContent(line.NoiDung))
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := s.z.UpdateBankStatementLineStatus(ctx, db.UpdateBankStatementLineStatusParams{
+				ID:        line.ID,
+				TrangThai: db.TrangThaiDoiSoatKhongKhop,
+			}); err != nil {
+				log.Printf("Bank reconcile: failed to update line %d: %v", line.ID, err)
+				summary.Loi++
+				continue
+			}
+			summary.KhongKhop++
+			continue
+		}
+		if err != nil {
+			log.Printf("Bank reconcile: failed to find transaction for line %d: %v", line.ID, err)
+			summary.Loi++
+			continue
+		}
+
+		soTienSaoKe, _ := line.SoTien.Float64Value()
+		if !sameAmount(transaction.SoTien, soTienSaoKe.Float64) {
+			soTienGiaoDich, _ := transaction.SoTien.Float64Value()
+			ghiChu := fmt.Sprintf("Số tiền chuyển %.0f khác số tiền giao dịch %.0f", soTienSaoKe.Float64, soTienGiaoDich.Float64)
+			if _, err := s.z.UpdateBankStatementLineStatus(ctx, db.UpdateBankStatementLineStatusParams{
+				ID:         line.ID,
+				TrangThai:  db.TrangThaiDoiSoatSaiSoTien,
+				GiaoDichID: &transaction.ID,
+				GhiChu:     &ghiChu,
+			}); err != nil {
+				log.Printf("Bank reconcile: failed to update line %d: %v", line.ID, err)
+				summary.Loi++
+				continue
+			}
+			summary.SaiSoTien++
+			continue
+		}
+
+		confirmed, err := s.z.ReconcileBankStatementLine(ctx, line.ID, transaction.ID, line.MaGiaoDichNganHang)
+		if err != nil {
+			log.Printf("Bank reconcile: failed to confirm transaction %d for line %d: %v", transaction.ID, line.ID, err)
+			summary.Loi++
+			continue
+		}
+		if !confirmed.DaCapNhatDatCho {
+			log.Printf("Bank reconcile: booking of transaction %s was not updated to da_thanh_toan", confirmed.MaGiaoDichNoiBo)
+		}
+		summary.DaDoiSoat++
+	}
+	return summary, nil
+}
+
+// sameAmount so sánh số tiền (làm tròn tới đồng)
+func sameAmount(value pgtype.Numeric, amount float64) bool {
+	v, err := value.Float64Value()
+	if err != nil || !v.Valid {
+		return false
+	}
+	return math.Round(v.Float64) == math.Round(amount)
+}
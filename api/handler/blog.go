@@ -3,11 +3,13 @@ package handler
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"travia.backend/api/helpers"
 	"travia.backend/api/utils"
@@ -515,11 +517,21 @@ func (s *Server) DeleteBlog(c *gin.Context) {
 		return
 	}
 
+	before, err := s.z.GetBlogByID(ctx, int32(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			helpers.NotFound(c, "Không tìm thấy blog")
+			return
+		}
+		helpers.InternalServerError(c, "Lỗi khi xóa blog", err)
+		return
+	}
 	err = s.z.DeleteBlog(ctx, int32(id))
 	if err != nil {
 		helpers.InternalServerError(c, "Lỗi khi xóa blog", err)
 		return
 	}
+	s.recordAudit(c, AuditBlogDelete, AuditTargetBlog, strconv.Itoa(id), before, nil)
 
 	helpers.Ok(c, gin.H{
 		"message": "Xóa blog thành công",
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,7 +11,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"travia.backend/api/middleware"
 	"travia.backend/api/models"
 	"travia.backend/api/utils"
 	db "travia.backend/db/sqlc"
@@ -17,21 +21,36 @@ import (
 
 // HoldSeat godoc
 // @Summary Hold seat
-// @Description Hold seat
+// @Description Giữ chỗ có thời hạn cho một lịch khởi hành. Trả về mã giữ chỗ dùng để tạo booking trước khi hết hạn. Hết chỗ (409) thì có thể đăng ký danh sách chờ qua /booking/waitlist/{khoi_hanh_id}
 // @Tags Booking
 // @Accept json
 // @Produce json
 // @Param khoi_hanh_id path int true "Khoi Hanh ID"
 // @Param so_nguoi_lon path int true "So Nguoi Lon"
 // @Param so_tre_em path int true "So Tre Em"
+// @Security ApiKeyAuth
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /booking/hold-seat/{khoi_hanh_id}/{so_nguoi_lon}/{so_tre_em} [post]
 func (s *Server) HoldSeat(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
 	khoi_hanh_id, err := strconv.Atoi(c.Param("khoi_hanh_id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid khoi_hanh_id"})
@@ -57,10 +76,46 @@ func (s *Server) HoldSeat(c *gin.Context) {
 		return
 	}
 
-	err = s.z.HoldSeat(ctx, db.HoldSeatParams{
-		KhoiHanhID: int32(khoi_hanh_id),
-		SoNguoiLon: int32(so_nguoi_lon),
-		SoTreEm:    int32(so_tre_em),
+	// Validation: Kiểm tra departure tồn tại và hợp lệ trước khi giữ chỗ
+	departure, err := s.z.GetDepartureByID(ctx, int32(khoi_hanh_id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy lịch khởi hành"})
+		return
+	}
+	if !departure.TrangThai.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Lịch khởi hành không hợp lệ"})
+		return
+	}
+	trangThai := departure.TrangThai.TrangThaiKhoiHanh
+
+	// Kiểm tra trạng thái hợp lệ: len_lich, xac_nhan, hoặc con_cho
+	// Không chấp nhận het_cho vì đã hết chỗ
+	isValidStatus := trangThai == db.TrangThaiKhoiHanhLenLich ||
+		trangThai == db.TrangThaiKhoiHanhXacNhan ||
+		trangThai == db.TrangThaiKhoiHanhConCho
+
+	if !isValidStatus {
+		if trangThai == db.TrangThaiKhoiHanhHetCho {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":      "Lịch khởi hành đã hết chỗ",
+				"trang_thai": string(trangThai),
+			})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":          "Lịch khởi hành không ở trạng thái hợp lệ để đặt tour",
+				"trang_thai":     string(trangThai),
+				"valid_statuses": []string{"len_lich", "xac_nhan", "con_cho"},
+			})
+		}
+		return
+	}
+
+	hold, err := s.z.CreateSeatHold(ctx, db.CreateSeatHoldParams{
+		KhoiHanhID:  int32(khoi_hanh_id),
+		NguoiDungID: jwtClaims.Id,
+		SoNguoiLon:  int32(so_nguoi_lon),
+		SoTreEm:     int32(so_tre_em),
+		SoPhut:      int32(SeatHoldDuration / time.Minute),
 	})
 	if err != nil {
 		// Phân loại lỗi để trả về status code phù hợp
@@ -70,6 +125,13 @@ func (s *Server) HoldSeat(c *gin.Context) {
 				"error":   "Không tìm thấy lịch khởi hành",
 				"details": errMsg,
 			})
+		} else if strings.Contains(errMsg, "giới hạn nhóm") {
+			response := gin.H{"error": "Số khách nằm ngoài giới hạn nhóm của tour", "details": errMsg}
+			if rules, rulesErr := s.z.GetDepartureGroupRules(ctx, int32(khoi_hanh_id)); rulesErr == nil {
+				response["so_nho_nhat"] = rules.SoNhoNhat
+				response["so_lon_nhat"] = rules.SoLonNhat
+			}
+			c.JSON(http.StatusBadRequest, response)
 		} else if strings.Contains(errMsg, "Không đủ chỗ") {
 			totalPeople := so_nguoi_lon + so_tre_em
 			availability, availErr := s.z.CheckDepartureAvailability(ctx, db.CheckDepartureAvailabilityParams{
@@ -78,15 +140,17 @@ func (s *Server) HoldSeat(c *gin.Context) {
 			})
 			if availErr == nil {
 				c.JSON(http.StatusConflict, gin.H{
-					"error":          "Không đủ chỗ trống",
-					"details":        errMsg,
-					"so_cho_trong":   availability.SoChoTrong,
-					"so_cho_yeu_cau": totalPeople,
+					"error":              "Không đủ chỗ trống",
+					"details":            errMsg,
+					"so_cho_trong":       availability.SoChoTrong,
+					"so_cho_yeu_cau":     totalPeople,
+					"co_the_dang_ky_cho": true,
 				})
 			} else {
 				c.JSON(http.StatusConflict, gin.H{
-					"error":   "Không đủ chỗ trống",
-					"details": errMsg,
+					"error":              "Không đủ chỗ trống",
+					"details":            errMsg,
+					"co_the_dang_ky_cho": true,
 				})
 			}
 		} else {
@@ -97,19 +161,25 @@ func (s *Server) HoldSeat(c *gin.Context) {
 		}
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Seat held successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Seat held successfully",
+		"data":    hold,
+	})
 }
 
 // CreateBooking godoc
 // @Summary Create booking
-// @Description Create booking
+// @Description Tạo booking từ mã giữ chỗ còn hiệu lực (lấy từ /booking/hold-seat)
 // @Tags Booking
 // @Accept json
 // @Produce json
-// @Param booking body db.CreateBookingParams true "Booking"
+// @Param booking body models.CreateBookingRequest true "Booking"
 // @Security ApiKeyAuth
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /booking/create [post]
 func (s *Server) CreateBooking(c *gin.Context) {
@@ -129,120 +199,60 @@ func (s *Server) CreateBooking(c *gin.Context) {
 	}
 
 	userUUID := jwtClaims.Id
-	var req db.CreateBookingParams
+	var req models.CreateBookingRequest
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Validation: Kiểm tra số lượng người
-	if req.SoNguoiLon <= 0 && req.SoTreEm <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Số lượng người phải lớn hơn 0"})
+	var maGiuCho pgtype.UUID
+	if err := maGiuCho.Scan(req.MaGiuCho); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mã giữ chỗ không hợp lệ"})
 		return
 	}
 
-	// Validation: Kiểm tra departure tồn tại và hợp lệ trước
-	departure, err := s.z.GetDepartureByID(ctx, req.KhoiHanhID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy lịch khởi hành"})
-		fmt.Printf("Error getting departure: %v\n", err)
-		return
+	// Tiêu thụ mã giữ chỗ: chỗ đã được giữ nên không cần kiểm tra lại số chỗ trống
+	params := db.CreateBookingFromHoldParams{
+		NguoiDungID:         userUUID,
+		MaGiuCho:            maGiuCho,
+		PhuongThucThanhToan: req.PhuongThucThanhToan,
 	}
-
-	// Kiểm tra trạng thái departure
-	if !departure.TrangThai.Valid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Lịch khởi hành không hợp lệ"})
-		fmt.Printf("Error getting departure: %v\n", err)
-		return
+	var bookingID int32
+	if partner, ok := middleware.GetApiKeyPrincipal(c); ok {
+		// Booking qua khóa API được ghi nhận cho đối tác để tính hoa hồng
+		bookingID, err = s.z.CreatePartnerBooking(ctx, params, partner.PartnerID, partner.KeyID)
+	} else {
+		bookingID, err = s.z.CreateBookingFromHold(ctx, params)
 	}
-	trangThai := departure.TrangThai.TrangThaiKhoiHanh
-
-	// Kiểm tra trạng thái hợp lệ: len_lich, xac_nhan, hoặc con_cho
-	// Không chấp nhận het_cho vì đã hết chỗ
-	isValidStatus := trangThai == db.TrangThaiKhoiHanhLenLich ||
-		trangThai == db.TrangThaiKhoiHanhXacNhan ||
-		trangThai == db.TrangThaiKhoiHanhConCho
-
-	if !isValidStatus {
-		if trangThai == db.TrangThaiKhoiHanhHetCho {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":      "Lịch khởi hành đã hết chỗ",
-				"trang_thai": string(trangThai),
-			})
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "không tồn tại") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy mã giữ chỗ", "details": errMsg})
+		} else if strings.Contains(errMsg, "không thuộc về") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Mã giữ chỗ không thuộc về bạn"})
+		} else if strings.Contains(errMsg, "đã được sử dụng") || strings.Contains(errMsg, "hết hạn") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Mã giữ chỗ không còn hiệu lực", "details": errMsg})
+		} else if strings.Contains(errMsg, "giới hạn nhóm") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Số khách nằm ngoài giới hạn nhóm của tour", "details": errMsg})
 		} else {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":          "Lịch khởi hành không ở trạng thái hợp lệ để đặt tour",
-				"trang_thai":     string(trangThai),
-				"valid_statuses": []string{"len_lich", "xac_nhan", "con_cho"},
-			})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking", "details": errMsg})
 		}
 		return
 	}
 
-	// Validation: Kiểm tra số chỗ còn lại trước khi tạo booking
-	totalPeople := req.SoNguoiLon + req.SoTreEm
-	availability, err := s.z.CheckDepartureAvailability(ctx, db.CheckDepartureAvailabilityParams{
-		ID:      req.KhoiHanhID,
-		SucChua: int32(totalPeople),
-	})
-	if err != nil {
-		// Lỗi có thể là "no rows" nếu departure không thỏa mãn điều kiện
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Không thể kiểm tra số chỗ. Lịch khởi hành có thể không hợp lệ hoặc đã hết chỗ",
-		})
-		fmt.Printf("Error checking availability: %v\n", err)
-		return
-	}
-	if !availability.ConCho {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":        "Không đủ chỗ trống",
-			"so_cho_trong": availability.SoChoTrong,
-		})
-		return
-	}
-
-	_, err = s.z.CreateBooking(ctx, db.CreateBookingParams{
-		NguoiDungID:         userUUID,
-		KhoiHanhID:          req.KhoiHanhID,
-		SoNguoiLon:          req.SoNguoiLon,
-		SoTreEm:             req.SoTreEm,
-		PhuongThucThanhToan: req.PhuongThucThanhToan,
-	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking", "details": err.Error()})
-		return
-	}
-
-	// CreateBookingRow is empty (sqlc issue with composite types), so we need to get the booking
-	// Get the most recent booking for this user and departure
-	bookings, err := s.z.GetBookingsByUserId(ctx, userUUID)
+	createdBooking, err := s.z.GetBookingById(ctx, bookingID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve created booking", "details": err.Error()})
 		return
 	}
 
-	// Find the booking that matches this departure (should be the most recent one)
-	var createdBooking *db.GetBookingsByUserIdRow
-	for i := range bookings {
-		if bookings[i].KhoiHanhID == req.KhoiHanhID {
-			// Take the first match (should be the most recent)
-			createdBooking = &bookings[i]
-			break
-		}
-	}
-
-	if createdBooking == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find created booking"})
-		return
-	}
-
 	c.JSON(http.StatusOK, gin.H{"message": "Booking created successfully", "booking": createdBooking})
 }
 
 // AddPassengers godoc
 // @Summary Add passengers
-// @Description Add passengers
+// @Description Thêm danh sách hành khách cho booking. Kiểm tra giới hạn nhóm, độ tuổi trẻ em của tour và tính lại giá khi số người lớn / trẻ em thay đổi
 // @Tags Booking
 // @Accept json
 // @Produce json
@@ -250,12 +260,26 @@ func (s *Server) CreateBooking(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /booking/add-passengers [post]
 func (s *Server) AddPassengers(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
 	// Debug: Log incoming request
 	fmt.Printf("AddPassengers called - Method: %s, Path: %s\n", c.Request.Method, c.Request.URL.Path)
 
@@ -288,8 +312,26 @@ func (s *Server) AddPassengers(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
 		return
 	}
+	if booking.NguoiDungID != jwtClaims.Id {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Booking không thuộc về bạn"})
+		return
+	}
+	// Chỉ sửa hành khách khi booking chưa thanh toán
+	if !bookingPassengersEditable(booking.TrangThai) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      "Booking đã thanh toán, đã hủy hoặc đã hoàn thành, không thể thay đổi hành khách",
+			"trang_thai": booking.TrangThai,
+		})
+		return
+	}
+
+	rules, err := s.z.GetDepartureGroupRules(ctx, booking.KhoiHanhID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get group rules", "details": err.Error()})
+		return
+	}
 
-	// Validation: Kiểm tra số lượng hành khách khớp với booking
+	// Validation: Tổng số hành khách phải khớp booking (số chỗ đã giữ) và nằm trong giới hạn nhóm của tour
 	var soNguoiLon, soTreEm int32
 	if booking.SoNguoiLon != nil {
 		soNguoiLon = *booking.SoNguoiLon
@@ -306,39 +348,67 @@ func (s *Server) AddPassengers(c *gin.Context) {
 		})
 		return
 	}
-
-	// Validation: Kiểm tra số lượng người lớn và trẻ em
-	var nguoiLonCount, treEmCount int32
-	for _, p := range req {
-		if p.LoaiKhach != nil {
-			if *p.LoaiKhach == "nguoi_lon" {
-				nguoiLonCount++
-			} else if *p.LoaiKhach == "tre_em" {
-				treEmCount++
-			}
-		}
-	}
-
-	if nguoiLonCount != soNguoiLon || treEmCount != soTreEm {
+	if int32(len(req)) < rules.SoNhoNhat || (rules.SoLonNhat != nil && int32(len(req)) > *rules.SoLonNhat) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Số lượng người lớn và trẻ em không khớp với booking",
-			"expected": gin.H{
-				"so_nguoi_lon": soNguoiLon,
-				"so_tre_em":    soTreEm,
-			},
-			"provided": gin.H{
-				"so_nguoi_lon": nguoiLonCount,
-				"so_tre_em":    treEmCount,
-			},
+			"error":       "Số hành khách nằm ngoài giới hạn nhóm của tour",
+			"so_nho_nhat": rules.SoNhoNhat,
+			"so_lon_nhat": rules.SoLonNhat,
 		})
 		return
 	}
+
+	// Validation: Loại khách và ngày sinh; trẻ em phải nằm trong độ tuổi trẻ em của tour tại ngày khởi hành
+	var nguoiLonCount, treEmCount int32
 	var dbReq []db.AddPassengersParams
-	for _, p := range req {
+	for i, p := range req {
+		if p.LoaiKhach == nil || (*p.LoaiKhach != "nguoi_lon" && *p.LoaiKhach != "tre_em") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Loại khách không hợp lệ",
+				"details": fmt.Sprintf("Hành khách %d: loai_khach phải là nguoi_lon hoặc tre_em", i+1),
+			})
+			return
+		}
+
+		var ngaySinh pgtype.Date
+		if p.NgaySinh != "" {
+			t, parseErr := time.Parse(time.DateOnly, p.NgaySinh)
+			if parseErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Ngày sinh không hợp lệ",
+					"details": fmt.Sprintf("Hành khách %d: ngay_sinh phải có định dạng YYYY-MM-DD", i+1),
+				})
+				return
+			}
+			ngaySinh = pgtype.Date{Time: t, Valid: true}
+		}
+
+		if *p.LoaiKhach == "tre_em" {
+			treEmCount++
+			if !ngaySinh.Valid {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Trẻ em bắt buộc có ngày sinh",
+					"details": fmt.Sprintf("Hành khách %d: thiếu ngay_sinh", i+1),
+				})
+				return
+			}
+			tuoi := ageAt(ngaySinh.Time, rules.NgayKhoiHanh.Time)
+			if tuoi < int(rules.TuoiTreEmToiThieu) || tuoi > int(rules.TuoiTreEmToiDa) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":                 "Tuổi trẻ em nằm ngoài độ tuổi quy định của tour",
+					"details":               fmt.Sprintf("Hành khách %d: %d tuổi tại ngày khởi hành", i+1, tuoi),
+					"tuoi_tre_em_toi_thieu": rules.TuoiTreEmToiThieu,
+					"tuoi_tre_em_toi_da":    rules.TuoiTreEmToiDa,
+				})
+				return
+			}
+		} else {
+			nguoiLonCount++
+		}
+
 		dbReq = append(dbReq, db.AddPassengersParams{
 			DatChoID:         int32(bookingID),
 			HoTen:            p.HoTen,
-			NgaySinh:         pgtype.Date{Time: utils.StringToDate(p.NgaySinh), Valid: true},
+			NgaySinh:         ngaySinh,
 			LoaiKhach:        p.LoaiKhach,
 			GioiTinh:         p.GioiTinh,
 			SoGiayToTuyThanh: p.SoGiayToTuyThanh,
@@ -347,12 +417,58 @@ func (s *Server) AddPassengers(c *gin.Context) {
 		})
 	}
 
-	_, err = s.z.AddPassengers(ctx, dbReq)
+	// Số người lớn / trẻ em thay đổi thì tính lại giá booking (chỉ khi booking chưa thanh toán)
+	tinhLaiGia := nguoiLonCount != soNguoiLon || treEmCount != soTreEm
+	repriced, err := s.z.ReplaceBookingPassengers(ctx, db.ReplaceBookingPassengersParams{
+		BookingID:  int32(bookingID),
+		SoNguoiLon: nguoiLonCount,
+		SoTreEm:    treEmCount,
+		TinhLaiGia: tinhLaiGia,
+		Passengers: dbReq,
+	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) && !tinhLaiGia {
+			c.JSON(http.StatusConflict, gin.H{"error": "Booking đã thanh toán, đã hủy hoặc đã hoàn thành, không thể thay đổi hành khách"})
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Booking đã thanh toán hoặc đã xác nhận, không thể thay đổi số người lớn / trẻ em",
+				"expected": gin.H{
+					"so_nguoi_lon": soNguoiLon,
+					"so_tre_em":    soTreEm,
+				},
+				"provided": gin.H{
+					"so_nguoi_lon": nguoiLonCount,
+					"so_tre_em":    treEmCount,
+				},
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add passengers", "details": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Passengers added successfully"})
+
+	response := gin.H{"message": "Passengers added successfully", "da_tinh_lai_gia": tinhLaiGia}
+	if repriced != nil {
+		response["booking"] = repriced
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// bookingPassengersEditable: danh sách hành khách chỉ được thay khi booking chưa thanh toán
+func bookingPassengersEditable(status db.NullTrangThaiDatCho) bool {
+	return status.Valid && (status.TrangThaiDatCho == db.TrangThaiDatChoChoXacNhan ||
+		status.TrangThaiDatCho == db.TrangThaiDatChoDaXacNhan)
+}
+
+// ageAt tính số tuổi tròn tại ngày tham chiếu
+func ageAt(birth, ref time.Time) int {
+	age := ref.Year() - birth.Year()
+	if ref.Month() < birth.Month() || (ref.Month() == birth.Month() && ref.Day() < birth.Day()) {
+		age--
+	}
+	return age
 }
 
 // Get my bookings
@@ -481,6 +597,11 @@ func (s *Server) GetBookingById(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found", "details": err.Error()})
 		return
 	}
+	// Đối tác chỉ xem được booking do chính mình tạo
+	if partner, ok := middleware.GetApiKeyPrincipal(c); ok && (booking.DoiTacID == nil || *booking.DoiTacID != partner.PartnerID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Booking fetched successfully",
@@ -538,8 +659,9 @@ func (s *Server) CancelBooking(c *gin.Context) {
 		return
 	}
 
-	// Hủy booking và tính số tiền hoàn lại
-	refundInfo, err := s.z.CancelBooking(ctx, int32(bookingID))
+	// Hủy booking, tính số tiền hoàn lại và tạo yêu cầu hoàn tiền chờ admin duyệt
+	refundCode := fmt.Sprintf("TRAVIAHT%d%d", bookingID, time.Now().Unix())
+	refundInfo, err := s.z.CancelBookingWithRefund(ctx, int32(bookingID), refundCode)
 	if err != nil {
 		errorMsg := err.Error()
 		if strings.Contains(errorMsg, "đã bị hủy") {
@@ -569,6 +691,9 @@ func (s *Server) CancelBooking(c *gin.Context) {
 		}
 	}
 
+	// Chỗ vừa được trả lại: mời lượt chờ tiếp theo
+	s.promoteWaitlistAsync(booking.KhoiHanhID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Hủy booking thành công",
 		"data": gin.H{
@@ -576,6 +701,7 @@ func (s *Server) CancelBooking(c *gin.Context) {
 			"phan_tram_hoan":          phanTramHoan,
 			"so_ngay_truoc_khoi_hanh": refundInfo.SoNgayTruocKhoiHanh,
 			"ly_do":                   refundInfo.LyDo,
+			"hoan_tien":               refundInfo.HoanTien,
 		},
 	})
 }
@@ -650,7 +776,7 @@ func (s *Server) DeleteBookings(c *gin.Context) {
 
 // CalculateRefundAmount godoc
 // @Summary Tính số tiền hoàn lại
-// @Description Tính số tiền hoàn lại dựa trên chính sách hoàn tiền (không hủy booking)
+// @Description Tính số tiền hoàn lại dựa trên chính sách hủy của khởi hành/tour (không hủy booking)
 // @Tags Booking
 // @Accept json
 // @Produce json
@@ -729,6 +855,17 @@ func (s *Server) CalculateRefundAmount(c *gin.Context) {
 		}
 	}
 
+	phiHuy, _ := numericToFloat64(refundInfo.PhiHuy)
+
+	// Chính sách hủy lưu trên booking lúc đặt (rỗng khi áp dụng chính sách mặc định)
+	var chinhSach struct {
+		ID  *int32  `json:"id"`
+		Ten *string `json:"ten"`
+	}
+	if len(refundInfo.ChinhSachHuyApDung) > 0 {
+		_ = json.Unmarshal(refundInfo.ChinhSachHuyApDung, &chinhSach)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Tính số tiền hoàn lại thành công",
 		"data": gin.H{
@@ -737,6 +874,10 @@ func (s *Server) CalculateRefundAmount(c *gin.Context) {
 			"phan_tram_hoan":          phanTramHoan,
 			"so_ngay_truoc_khoi_hanh": refundInfo.SoNgayTruocKhoiHanh,
 			"ly_do":                   refundInfo.LyDo,
+			"phi_huy":                 phiHuy,
+			"chinh_sach_huy_id":       chinhSach.ID,
+			"ten_chinh_sach_huy":      chinhSach.Ten,
+			"chinh_sach_huy":          refundInfo.ChinhSachHuyApDung,
 		},
 	})
 }
@@ -766,23 +907,7 @@ func (s *Server) GetAllRefunds(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	// Kiểm tra authentication và role admin
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-	jwtClaims, ok := claims.(*utils.JwtClams)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
-		return
-	}
-
-	// Kiểm tra role admin
-	if jwtClaims.Vaitro != "quan_tri" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Chỉ admin mới có quyền xem tất cả refund"})
-		return
-	}
+	// Quyền refund.view được kiểm tra ở route (RequirePermission)
 
 	// Parse query parameters
 	var startDate, endDate pgtype.Timestamp
@@ -933,7 +1058,7 @@ func (s *Server) GetSupplierRefunds(c *gin.Context) {
 		return
 	}
 
-	supplierID := jwtClaims.Id
+	supplierID := currentSupplierID(c)
 
 	// Parse query parameters
 	var startDate, endDate pgtype.Timestamp
@@ -1054,23 +1179,7 @@ func (s *Server) GetRefundStats(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	// Kiểm tra authentication và role admin
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-	jwtClaims, ok := claims.(*utils.JwtClams)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
-		return
-	}
-
-	// Kiểm tra role admin
-	if jwtClaims.Vaitro != "quan_tri" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Chỉ admin mới có quyền xem thống kê refund"})
-		return
-	}
+	// Quyền refund.view được kiểm tra ở route (RequirePermission)
 
 	// Parse query parameters
 	var startDate, endDate pgtype.Timestamp
@@ -1174,7 +1283,7 @@ func (s *Server) GetSupplierRefundStats(c *gin.Context) {
 		return
 	}
 
-	supplierID := jwtClaims.Id
+	supplierID := currentSupplierID(c)
 
 	// Parse query parameters
 	var startDate, endDate pgtype.Timestamp
@@ -0,0 +1,372 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"travia.backend/api/models"
+	db "travia.backend/db/sqlc"
+)
+
+// defaultCancellationPolicy mô tả chính sách hủy mặc định (khớp với tinh_tien_hoan_lai khi tour không gắn chính sách)
+func defaultCancellationPolicy() gin.H {
+	return gin.H{
+		"id":              nil,
+		"ten":             "Chính sách hủy mặc định",
+		"khong_hoan_tien": false,
+		"phi_co_dinh":     0,
+		"cac_bac": []gin.H{
+			{"so_ngay_toi_thieu": 15, "phan_tram_hoan": 100},
+			{"so_ngay_toi_thieu": 7, "phan_tram_hoan": 90},
+			{"so_ngay_toi_thieu": 3, "phan_tram_hoan": 70},
+			{"so_ngay_toi_thieu": 1, "phan_tram_hoan": 50},
+		},
+	}
+}
+
+// cancellationPolicyResponse chuyển chính sách hủy (cac_bac dạng JSON) thành response
+func cancellationPolicyResponse(id int32, ten string, moTa *string, khongHoanTien bool, phiCoDinh pgtype.Numeric, cacBac []byte) gin.H {
+	phi, _ := numericToFloat64(phiCoDinh)
+	return gin.H{
+		"id":              id,
+		"ten":             ten,
+		"mo_ta":           moTa,
+		"khong_hoan_tien": khongHoanTien,
+		"phi_co_dinh":     phi,
+		"cac_bac":         json.RawMessage(cacBac),
+	}
+}
+
+// tourCancellationPolicies trả về chính sách hủy của tour (mặc định nếu chưa gắn)
+// và các khởi hành sắp tới có chính sách riêng
+func (s *Server) tourCancellationPolicies(ctx context.Context, tourID int32) (gin.H, []gin.H, error) {
+	rows, err := s.z.GetTourCancellationPolicies(ctx, tourID)
+	if err != nil {
+		return nil, nil, err
+	}
+	tourPolicy := defaultCancellationPolicy()
+	departurePolicies := make([]gin.H, 0)
+	for _, row := range rows {
+		policy := cancellationPolicyResponse(row.ID, row.Ten, row.MoTa, row.KhongHoanTien, row.PhiCoDinh, row.CacBac)
+		if row.KhoiHanhID == nil {
+			tourPolicy = policy
+			continue
+		}
+		policy["khoi_hanh_id"] = *row.KhoiHanhID
+		policy["ngay_khoi_hanh"] = row.NgayKhoiHanh
+		departurePolicies = append(departurePolicies, policy)
+	}
+	return tourPolicy, departurePolicies, nil
+}
+
+// cancellationPolicyParams kiểm tra và chuyển request thành tham số lưu chính sách hủy
+func cancellationPolicyParams(req models.CancellationPolicyRequest) (pgtype.Numeric, []db.CancellationPolicyTierInput, error) {
+	var phiCoDinh pgtype.Numeric
+	if err := phiCoDinh.Scan(fmt.Sprintf("%.2f", req.PhiCoDinh)); err != nil {
+		return phiCoDinh, nil, fmt.Errorf("phí hủy không hợp lệ")
+	}
+	if req.KhongHoanTien {
+		// Chính sách không hoàn tiền không dùng mốc hoàn tiền
+		return phiCoDinh, []db.CancellationPolicyTierInput{}, nil
+	}
+	if len(req.CacBac) == 0 {
+		return phiCoDinh, nil, fmt.Errorf("chính sách hoàn tiền phải có ít nhất một mốc")
+	}
+
+	tiers := make([]db.CancellationPolicyTierInput, 0, len(req.CacBac))
+	seen := make(map[int32]bool, len(req.CacBac))
+	for _, bac := range req.CacBac {
+		if seen[bac.SoNgayToiThieu] {
+			return phiCoDinh, nil, fmt.Errorf("mốc %d ngày bị trùng", bac.SoNgayToiThieu)
+		}
+		seen[bac.SoNgayToiThieu] = true
+
+		var phanTram pgtype.Numeric
+		if err := phanTram.Scan(fmt.Sprintf("%.2f", bac.PhanTramHoan)); err != nil {
+			return phiCoDinh, nil, fmt.Errorf("phần trăm hoàn không hợp lệ")
+		}
+		tiers = append(tiers, db.CancellationPolicyTierInput{
+			SoNgayToiThieu: bac.SoNgayToiThieu,
+			PhanTramHoan:   phanTram,
+		})
+	}
+	return phiCoDinh, tiers, nil
+}
+
+// GetMyCancellationPolicies godoc
+// @Summary Danh sách chính sách hủy của nhà cung cấp
+// @Description Lấy các chính sách hủy kèm mốc hoàn tiền và số tour/khởi hành đang áp dụng
+// @Tags Supplier
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/cancellation-policies [get]
+func (s *Server) GetMyCancellationPolicies(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.z.GetCancellationPoliciesBySupplier(ctx, currentSupplierID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get cancellation policies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	policies := make([]gin.H, 0, len(rows))
+	for _, row := range rows {
+		policy := cancellationPolicyResponse(row.ID, row.Ten, row.MoTa, row.KhongHoanTien, row.PhiCoDinh, row.CacBac)
+		policy["so_tour"] = row.SoTour
+		policy["so_khoi_hanh"] = row.SoKhoiHanh
+		policy["ngay_tao"] = row.NgayTao
+		policy["ngay_cap_nhat"] = row.NgayCapNhat
+		policies = append(policies, policy)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cancellation policies fetched successfully", "data": policies})
+}
+
+// CreateMyCancellationPolicy godoc
+// @Summary Tạo chính sách hủy
+// @Description Tạo chính sách hủy với các mốc hoàn tiền theo số ngày trước khởi hành, phí hủy cố định hoặc không hoàn tiền
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param request body models.CancellationPolicyRequest true "Cancellation policy"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/cancellation-policies [post]
+func (s *Server) CreateMyCancellationPolicy(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.CancellationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	phiCoDinh, tiers, err := cancellationPolicyParams(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := s.z.CreateCancellationPolicyWithTiers(ctx, db.CreateCancellationPolicyParams{
+		NhaCungCapID:  currentSupplierID(c),
+		Ten:           req.Ten,
+		MoTa:          req.MoTa,
+		KhongHoanTien: req.KhongHoanTien,
+		PhiCoDinh:     phiCoDinh,
+	}, tiers)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create cancellation policy",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Cancellation policy created successfully", "data": policy})
+}
+
+// UpdateMyCancellationPolicy godoc
+// @Summary Cập nhật chính sách hủy
+// @Description Cập nhật chính sách hủy và thay toàn bộ mốc hoàn tiền. Chỉ áp dụng cho booking đặt sau thời điểm cập nhật, booking đã đặt giữ chính sách lúc đặt
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path int true "Cancellation policy ID"
+// @Param request body models.CancellationPolicyRequest true "Cancellation policy"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/cancellation-policies/{id} [put]
+func (s *Server) UpdateMyCancellationPolicy(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	policyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cancellation policy ID"})
+		return
+	}
+	var req models.CancellationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	phiCoDinh, tiers, err := cancellationPolicyParams(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := s.z.UpdateCancellationPolicyWithTiers(ctx, db.UpdateCancellationPolicyParams{
+		ID:            int32(policyID),
+		NhaCungCapID:  currentSupplierID(c),
+		Ten:           req.Ten,
+		MoTa:          req.MoTa,
+		KhongHoanTien: req.KhongHoanTien,
+		PhiCoDinh:     phiCoDinh,
+	}, tiers)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cancellation policy not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update cancellation policy",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cancellation policy updated successfully", "data": policy})
+}
+
+// DeleteMyCancellationPolicy godoc
+// @Summary Xóa chính sách hủy
+// @Description Xóa chính sách hủy; tour/khởi hành đang dùng quay về chính sách mặc định
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "Cancellation policy ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/cancellation-policies/{id} [delete]
+func (s *Server) DeleteMyCancellationPolicy(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	policyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cancellation policy ID"})
+		return
+	}
+
+	deleted, err := s.z.DeleteCancellationPolicy(ctx, db.DeleteCancellationPolicyParams{
+		ID:           int32(policyID),
+		NhaCungCapID: currentSupplierID(c),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete cancellation policy",
+			"details": err.Error(),
+		})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cancellation policy not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cancellation policy deleted successfully"})
+}
+
+// SetTourCancellationPolicy godoc
+// @Summary Gắn chính sách hủy cho tour
+// @Description Gắn chính sách hủy áp dụng cho mọi khởi hành của tour (chinh_sach_huy_id = null để dùng chính sách mặc định)
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Param request body models.AttachCancellationPolicyRequest true "Cancellation policy"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/tours/{id}/cancellation-policy [put]
+func (s *Server) SetTourCancellationPolicy(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	tourID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tour ID"})
+		return
+	}
+	var req models.AttachCancellationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := s.z.SetTourCancellationPolicy(ctx, db.SetTourCancellationPolicyParams{
+		TourID:         int32(tourID),
+		NhaCungCapID:   currentSupplierID(c),
+		ChinhSachHuyID: req.ChinhSachHuyID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tour hoặc chính sách hủy không tồn tại"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to set tour cancellation policy",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tour cancellation policy updated successfully", "data": result})
+}
+
+// SetDepartureCancellationPolicy godoc
+// @Summary Gắn chính sách hủy cho khởi hành
+// @Description Gắn chính sách hủy riêng cho một khởi hành (vd: mùa cao điểm), ưu tiên hơn chính sách của tour. chinh_sach_huy_id = null để dùng chính sách của tour
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path int true "Departure ID"
+// @Param request body models.AttachCancellationPolicyRequest true "Cancellation policy"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/departures/{id}/cancellation-policy [put]
+func (s *Server) SetDepartureCancellationPolicy(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	departureID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid departure ID"})
+		return
+	}
+	var req models.AttachCancellationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := s.z.SetDepartureCancellationPolicy(ctx, db.SetDepartureCancellationPolicyParams{
+		KhoiHanhID:     int32(departureID),
+		NhaCungCapID:   currentSupplierID(c),
+		ChinhSachHuyID: req.ChinhSachHuyID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Khởi hành hoặc chính sách hủy không tồn tại"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to set departure cancellation policy",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Departure cancellation policy updated successfully", "data": result})
+}
@@ -261,6 +261,14 @@ func (s *Server) UpdateContactStatus(c *gin.Context) {
 		return
 	}
 
+	before, err := s.z.GetContactByID(ctx, req.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Contact not found",
+			"message": "Không tìm thấy liên hệ",
+		})
+		return
+	}
 	contact, err := s.z.UpdateContactStatus(ctx, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -269,6 +277,7 @@ func (s *Server) UpdateContactStatus(c *gin.Context) {
 		})
 		return
 	}
+	s.recordAudit(c, AuditContactStatus, AuditTargetContact, strconv.Itoa(id), before, contact)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Cập nhật trạng thái thành công",
@@ -435,6 +444,14 @@ func (s *Server) MarkContactAsRead(c *gin.Context) {
 		return
 	}
 
+	before, err := s.z.GetContactByID(ctx, int32(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Contact not found",
+			"message": "Không tìm thấy liên hệ",
+		})
+		return
+	}
 	contact, err := s.z.MarkContactAsRead(ctx, int32(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -443,6 +460,7 @@ func (s *Server) MarkContactAsRead(c *gin.Context) {
 		})
 		return
 	}
+	s.recordAudit(c, AuditContactRead, AuditTargetContact, strconv.Itoa(id), before, contact)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Đánh dấu đã đọc thành công",
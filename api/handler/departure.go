@@ -51,6 +51,10 @@ func (s *Server) CreateDeparture(c *gin.Context) {
 		return
 	}
 
+	if _, ok := s.ensureTourOwned(c, req.TourID); !ok {
+		return
+	}
+
 	// Parse dates
 	ngayKhoiHanh, _ := time.Parse("2006-01-02", req.NgayKhoiHanh)
 	ngayKetThuc, _ := time.Parse("2006-01-02", req.NgayKetThuc)
@@ -111,6 +115,15 @@ func (s *Server) UpdateLichTrinh(c *gin.Context) {
 		})
 		return
 	}
+	// Tour đang công bố / chờ duyệt chỉ được sửa qua phiên bản chờ duyệt
+	lichTrinh, err := s.z.GetItineraryByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy lịch trình"})
+		return
+	}
+	if !s.ensureTourEditable(c, lichTrinh.TourID) {
+		return
+	}
 	// Parse and convert time strings to pgtype.Time
 	var gioBatDau, gioKetThuc pgtype.Time
 	if req.GioBatDau != "" {
@@ -178,6 +191,20 @@ func (s *Server) UpdateHoatDongTrongNgay(c *gin.Context) {
 		})
 		return
 	}
+	// Tour đang công bố / chờ duyệt chỉ được sửa qua phiên bản chờ duyệt
+	hoatDong, err := s.z.GetActivityByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy hoạt động trong ngày"})
+		return
+	}
+	lichTrinh, err := s.z.GetItineraryByID(c.Request.Context(), hoatDong.LichTrinhID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy lịch trình"})
+		return
+	}
+	if !s.ensureTourEditable(c, lichTrinh.TourID) {
+		return
+	}
 	var gioBatDau, gioKetThuc pgtype.Time
 	if req.GioBatDau != "" {
 		if err := gioBatDau.Scan(utils.OnlyTine(req.GioBatDau)); err != nil {
@@ -320,6 +347,9 @@ func (s *Server) UpdateDeparture(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if _, ok := s.ensureDepartureOwned(c, int32(id)); !ok {
+		return
+	}
 	ngayKhoiHanh, _ := time.Parse(time.DateOnly, *req.NgayKhoiHanh)
 	ngayKetThuc, _ := time.Parse(time.DateOnly, *req.NgayKetThuc)
 	var dateKhoiHanh, dateKetThuc pgtype.Date
@@ -367,6 +397,10 @@ func (s *Server) DeleteDeparture(c *gin.Context) {
 		return
 	}
 
+	if _, ok := s.ensureDepartureOwned(c, int32(id)); !ok {
+		return
+	}
+
 	err = s.z.DeleteDeparture(context.Background(), int32(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể xóa lịch khởi hành"})
@@ -395,11 +429,16 @@ func (s *Server) CancelDeparture(c *gin.Context) {
 		return
 	}
 
+	before, ok := s.ensureDepartureOwned(c, int32(id))
+	if !ok {
+		return
+	}
 	departure, err := s.z.CancelDeparture(context.Background(), int32(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể hủy lịch khởi hành"})
 		return
 	}
+	s.recordAudit(c, AuditDepartureCancel, AuditTargetDeparture, idStr, before, departure)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Hủy lịch khởi hành thành công",
@@ -430,6 +469,9 @@ func (s *Server) AddHinhAnhTour(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Dữ liệu không hợp lệ"})
 		return
 	}
+	if !s.ensureTourEditable(c, int32(id)) {
+		return
+	}
 	result, err := s.z.AddHinhAnhTour(context.Background(), db.AddHinhAnhTourParams{
 		TourID:       int32(id),
 		DuongDan:     req.DuongDan,
@@ -473,6 +515,9 @@ func (s *Server) DeleteHinhAnhTour(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy hình ảnh"})
 		return
 	}
+	if !s.ensureTourEditable(c, imageInfo.TourID) {
+		return
+	}
 
 	// Xóa file từ Supabase storage nếu có duong_dan
 	if imageInfo.DuongDan != "" {
@@ -551,6 +596,9 @@ func (s *Server) AddTourDestination(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Dữ liệu không hợp lệ"})
 		return
 	}
+	if !s.ensureTourEditable(c, int32(id)) {
+		return
+	}
 	err = s.z.AddTourDestination(context.Background(), db.AddTourDestinationParams{
 		TourID:        int32(id),
 		DiemDenID:     req.DiemDenID,
@@ -588,6 +636,9 @@ func (s *Server) DeleteTourDestination(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Diem den ID không hợp lệ"})
 		return
 	}
+	if !s.ensureTourEditable(c, int32(id)) {
+		return
+	}
 	err = s.z.DeleteTourDestination(context.Background(), db.DeleteTourDestinationParams{
 		TourID:    int32(id),
 		DiemDenID: int32(diemDenID),
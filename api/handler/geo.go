@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	earthRadiusKm = 6371.0
+
+	geoDefaultRadiusKm = 50
+	geoMaxRadiusKm     = 1000
+	geoDefaultLimit    = 100
+	geoMaxLimit        = 500
+)
+
+// geoJSONFeatureCollection là FeatureCollection theo RFC 7946, kèm total / limit để bản đồ biết còn điểm bị cắt bớt
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+	Total    int32            `json:"total"`
+	Limit    int              `json:"limit"`
+}
+
+type geoJSONFeature struct {
+	Type       string       `json:"type"`
+	ID         int32        `json:"id"`
+	Geometry   geoJSONPoint `json:"geometry"`
+	Properties gin.H        `json:"properties"`
+}
+
+// geoJSONPoint lưu tọa độ theo thứ tự [kinh_do, vi_do] như GeoJSON quy định
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+func newGeoJSONPoint(lat, lng float64) geoJSONPoint {
+	return geoJSONPoint{Type: "Point", Coordinates: [2]float64{lng, lat}}
+}
+
+// geoArea là vùng tìm kiếm: điểm tính khoảng cách, khung tọa độ và bán kính (nil khi tìm theo khung)
+type geoArea struct {
+	Lat, Lng                       float64
+	MinLat, MaxLat, MinLng, MaxLng float64
+	RadiusKm                       *float64
+}
+
+// numericParams chuyển khung tọa độ sang NUMERIC để so sánh trực tiếp với cột vi_do / kinh_do (có index)
+func (a geoArea) numericParams() (minLat, maxLat, minLng, maxLng pgtype.Numeric, err error) {
+	for _, p := range []struct {
+		dst *pgtype.Numeric
+		v   float64
+	}{{&minLat, a.MinLat}, {&maxLat, a.MaxLat}, {&minLng, a.MinLng}, {&maxLng, a.MaxLng}} {
+		if err = p.dst.Scan(strconv.FormatFloat(p.v, 'f', 6, 64)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func parseCoordinate(c *gin.Context, name string, limit float64) (float64, error) {
+	v, err := strconv.ParseFloat(c.Query(name), 64)
+	if err != nil || math.IsNaN(v) || v < -limit || v > limit {
+		return 0, fmt.Errorf("%s phải là số trong khoảng [-%g, %g]", name, limit, limit)
+	}
+	return v, nil
+}
+
+// parseRadiusArea đọc lat, lng, ban_kinh_km và tính khung bao quanh vòng tròn để lọc trước theo index
+func parseRadiusArea(c *gin.Context) (geoArea, error) {
+	var a geoArea
+	var err error
+	if a.Lat, err = parseCoordinate(c, "lat", 90); err != nil {
+		return a, err
+	}
+	if a.Lng, err = parseCoordinate(c, "lng", 180); err != nil {
+		return a, err
+	}
+
+	radius := float64(geoDefaultRadiusKm)
+	if v := c.Query("ban_kinh_km"); v != "" {
+		radius, err = strconv.ParseFloat(v, 64)
+		if err != nil || radius <= 0 || radius > geoMaxRadiusKm {
+			return a, fmt.Errorf("ban_kinh_km phải lớn hơn 0 và không quá %d", geoMaxRadiusKm)
+		}
+	}
+	a.RadiusKm = &radius
+
+	// Khung bao: vĩ độ lệch r/R radian; kinh độ lệch asin(sin(r/R) / cos(lat)), mở rộng ra toàn bộ kinh độ nếu vòng tròn chứa cực
+	angular := radius / earthRadiusKm
+	dLat := angular * 180 / math.Pi
+	a.MinLat, a.MaxLat = a.Lat-dLat, a.Lat+dLat
+	if a.MinLat <= -90 || a.MaxLat >= 90 {
+		a.MinLat, a.MaxLat = math.Max(a.MinLat, -90), math.Min(a.MaxLat, 90)
+		a.MinLng, a.MaxLng = -180, 180
+		return a, nil
+	}
+	dLng := math.Asin(math.Sin(angular)/math.Cos(a.Lat*math.Pi/180)) * 180 / math.Pi
+	a.MinLng, a.MaxLng = a.Lng-dLng, a.Lng+dLng
+	// Khung vắt qua kinh tuyến 180 được biểu diễn bằng min_lng > max_lng
+	if a.MinLng < -180 {
+		a.MinLng += 360
+	}
+	if a.MaxLng > 180 {
+		a.MaxLng -= 360
+	}
+	return a, nil
+}
+
+// parseBoundsArea đọc khung bản đồ min_lat, min_lng, max_lat, max_lng.
+// Kết quả sắp xếp theo khoảng cách tới lat, lng nếu có (ví dụ vị trí người dùng), mặc định là tâm khung
+func parseBoundsArea(c *gin.Context) (geoArea, error) {
+	var a geoArea
+	var err error
+	if a.MinLat, err = parseCoordinate(c, "min_lat", 90); err != nil {
+		return a, err
+	}
+	if a.MaxLat, err = parseCoordinate(c, "max_lat", 90); err != nil {
+		return a, err
+	}
+	if a.MinLng, err = parseCoordinate(c, "min_lng", 180); err != nil {
+		return a, err
+	}
+	if a.MaxLng, err = parseCoordinate(c, "max_lng", 180); err != nil {
+		return a, err
+	}
+	if a.MinLat > a.MaxLat {
+		return a, fmt.Errorf("min_lat phải nhỏ hơn hoặc bằng max_lat")
+	}
+
+	if c.Query("lat") != "" || c.Query("lng") != "" {
+		if a.Lat, err = parseCoordinate(c, "lat", 90); err != nil {
+			return a, err
+		}
+		if a.Lng, err = parseCoordinate(c, "lng", 180); err != nil {
+			return a, err
+		}
+		return a, nil
+	}
+	a.Lat = (a.MinLat + a.MaxLat) / 2
+	if a.MinLng <= a.MaxLng {
+		a.Lng = (a.MinLng + a.MaxLng) / 2
+	} else {
+		a.Lng = (a.MinLng + a.MaxLng + 360) / 2
+		if a.Lng > 180 {
+			a.Lng -= 360
+		}
+	}
+	return a, nil
+}
+
+func parseGeoLimit(c *gin.Context) int {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(geoDefaultLimit)))
+	if limit <= 0 {
+		limit = geoDefaultLimit
+	}
+	if limit > geoMaxLimit {
+		limit = geoMaxLimit
+	}
+	return limit
+}
+
+func roundKm(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// GetToursNearby godoc
+// @Summary Tìm tour quanh một vị trí
+// @Description Tìm tour có điểm đến nằm trong bán kính N km quanh (lat, lng), sắp xếp theo khoảng cách gần nhất.
+// @Description Trả về GeoJSON FeatureCollection, mỗi tour là một Point tại điểm đến gần nhất của tour
+// @Tags tour
+// @Produce json
+// @Param lat query number true "Vĩ độ"
+// @Param lng query number true "Kinh độ"
+// @Param ban_kinh_km query number false "Bán kính (km, tối đa 1000)" default(50)
+// @Param limit query int false "Số tour tối đa (tối đa 500)" default(100)
+// @Success 200 {object} geoJSONFeatureCollection
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /tour/nearby [get]
+func (s *Server) GetToursNearby(c *gin.Context) {
+	area, err := parseRadiusArea(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vị trí tìm kiếm không hợp lệ", "details": err.Error()})
+		return
+	}
+	s.respondToursInArea(c, area)
+}
+
+// GetToursInBounds godoc
+// @Summary Tìm tour trong khung bản đồ
+// @Description Tìm tour có điểm đến nằm trong khung tọa độ đang hiển thị trên bản đồ, sắp xếp theo khoảng cách tới (lat, lng) hoặc tới tâm khung.
+// @Description min_lng > max_lng nghĩa là khung vắt qua kinh tuyến 180. Trả về GeoJSON FeatureCollection
+// @Tags tour
+// @Produce json
+// @Param min_lat query number true "Vĩ độ nhỏ nhất"
+// @Param min_lng query number true "Kinh độ phía tây"
+// @Param max_lat query number true "Vĩ độ lớn nhất"
+// @Param max_lng query number true "Kinh độ phía đông"
+// @Param lat query number false "Vĩ độ điểm tính khoảng cách"
+// @Param lng query number false "Kinh độ điểm tính khoảng cách"
+// @Param limit query int false "Số tour tối đa (tối đa 500)" default(100)
+// @Success 200 {object} geoJSONFeatureCollection
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /tour/in-bounds [get]
+func (s *Server) GetToursInBounds(c *gin.Context) {
+	area, err := parseBoundsArea(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Khung bản đồ không hợp lệ", "details": err.Error()})
+		return
+	}
+	s.respondToursInArea(c, area)
+}
+
+func (s *Server) respondToursInArea(c *gin.Context, area geoArea) {
+	limit := parseGeoLimit(c)
+	minLat, maxLat, minLng, maxLng, err := area.numericParams()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vị trí tìm kiếm không hợp lệ", "details": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	tours, err := s.z.GetToursNearPoint(ctx, db.GetToursNearPointParams{
+		Lat:       area.Lat,
+		Lng:       area.Lng,
+		MinLat:    minLat,
+		MaxLat:    maxLat,
+		MinLng:    minLng,
+		MaxLng:    maxLng,
+		BanKinhKm: area.RadiusKm,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tìm tour theo vị trí", "details": err.Error()})
+		return
+	}
+
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, 0, len(tours)), Limit: limit}
+	for _, t := range tours {
+		fc.Total = t.Tong
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type:     "Feature",
+			ID:       t.ID,
+			Geometry: newGeoJSONPoint(t.ViDo, t.KinhDo),
+			Properties: gin.H{
+				"tieu_de":        t.TieuDe,
+				"so_ngay":        t.SoNgay,
+				"so_dem":         t.SoDem,
+				"gia_nguoi_lon":  t.GiaNguoiLon,
+				"don_vi_tien_te": t.DonViTienTe,
+				"noi_bat":        t.NoiBat,
+				"anh_chinh":      t.AnhChinh,
+				"diem_den_id":    t.DiemDenID,
+				"diem_den_ten":   t.DiemDenTen,
+				"tinh":           t.Tinh,
+				"quoc_gia":       t.QuocGia,
+				"khoang_cach_km": roundKm(t.KhoangCachKm),
+			},
+		})
+	}
+	c.JSON(http.StatusOK, fc)
+}
+
+// GetDestinationsNearby godoc
+// @Summary Tìm điểm đến quanh một vị trí
+// @Description Tìm điểm đến trong bán kính N km quanh (lat, lng), sắp xếp theo khoảng cách gần nhất. Trả về GeoJSON FeatureCollection
+// @Tags Destination
+// @Produce json
+// @Param lat query number true "Vĩ độ"
+// @Param lng query number true "Kinh độ"
+// @Param ban_kinh_km query number false "Bán kính (km, tối đa 1000)" default(50)
+// @Param chi_co_tour query bool false "Chỉ lấy điểm đến đang có tour"
+// @Param limit query int false "Số điểm đến tối đa (tối đa 500)" default(100)
+// @Success 200 {object} geoJSONFeatureCollection
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /destination/nearby [get]
+func (s *Server) GetDestinationsNearby(c *gin.Context) {
+	area, err := parseRadiusArea(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vị trí tìm kiếm không hợp lệ", "details": err.Error()})
+		return
+	}
+	s.respondDestinationsInArea(c, area)
+}
+
+// GetDestinationsInBounds godoc
+// @Summary Tìm điểm đến trong khung bản đồ
+// @Description Tìm điểm đến nằm trong khung tọa độ đang hiển thị trên bản đồ, sắp xếp theo khoảng cách tới (lat, lng) hoặc tới tâm khung.
+// @Description min_lng > max_lng nghĩa là khung vắt qua kinh tuyến 180. Trả về GeoJSON FeatureCollection
+// @Tags Destination
+// @Produce json
+// @Param min_lat query number true "Vĩ độ nhỏ nhất"
+// @Param min_lng query number true "Kinh độ phía tây"
+// @Param max_lat query number true "Vĩ độ lớn nhất"
+// @Param max_lng query number true "Kinh độ phía đông"
+// @Param lat query number false "Vĩ độ điểm tính khoảng cách"
+// @Param lng query number false "Kinh độ điểm tính khoảng cách"
+// @Param chi_co_tour query bool false "Chỉ lấy điểm đến đang có tour"
+// @Param limit query int false "Số điểm đến tối đa (tối đa 500)" default(100)
+// @Success 200 {object} geoJSONFeatureCollection
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /destination/in-bounds [get]
+func (s *Server) GetDestinationsInBounds(c *gin.Context) {
+	area, err := parseBoundsArea(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Khung bản đồ không hợp lệ", "details": err.Error()})
+		return
+	}
+	s.respondDestinationsInArea(c, area)
+}
+
+func (s *Server) respondDestinationsInArea(c *gin.Context, area geoArea) {
+	limit := parseGeoLimit(c)
+	minLat, maxLat, minLng, maxLng, err := area.numericParams()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vị trí tìm kiếm không hợp lệ", "details": err.Error()})
+		return
+	}
+	onlyWithTours, _ := strconv.ParseBool(c.DefaultQuery("chi_co_tour", "false"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	destinations, err := s.z.GetDestinationsNearPoint(ctx, db.GetDestinationsNearPointParams{
+		Lat:       area.Lat,
+		Lng:       area.Lng,
+		MinLat:    minLat,
+		MaxLat:    maxLat,
+		MinLng:    minLng,
+		MaxLng:    maxLng,
+		BanKinhKm: area.RadiusKm,
+		ChiCoTour: onlyWithTours,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tìm điểm đến theo vị trí", "details": err.Error()})
+		return
+	}
+
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, 0, len(destinations)), Limit: limit}
+	for _, d := range destinations {
+		fc.Total = d.Tong
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type:     "Feature",
+			ID:       d.ID,
+			Geometry: newGeoJSONPoint(d.ViDo, d.KinhDo),
+			Properties: gin.H{
+				"ten":            d.Ten,
+				"tinh":           d.Tinh,
+				"quoc_gia":       d.QuocGia,
+				"khu_vuc":        d.KhuVuc,
+				"anh":            d.Anh,
+				"so_luong_tour":  d.SoLuongTour,
+				"khoang_cach_km": roundKm(d.KhoangCachKm),
+			},
+		})
+	}
+	c.JSON(http.StatusOK, fc)
+}
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// JwtKeyReloadInterval là chu kỳ tải lại khóa ký từ DB và kiểm tra lịch xoay khóa
+	JwtKeyReloadInterval = 1 * time.Minute
+	// JwtKeyPrePublish là thời gian khóa mới nằm trong JWKS trước khi được dùng để ký,
+	// đủ để mọi instance tải lại khóa và các dịch vụ xác thực làm mới cache JWKS
+	JwtKeyPrePublish = 15 * time.Minute
+	// jwksCacheMaxAge là thời gian dịch vụ khác được cache JWKS (phải nhỏ hơn JwtKeyPrePublish)
+	jwksCacheMaxAge = 5 * time.Minute
+
+	jwtKeyRotateLockKey = "jwt:rotate:lock"
+)
+
+// jwtKeyInfo là thông tin công khai của một khóa ký (không bao giờ trả khóa riêng)
+type jwtKeyInfo struct {
+	Kid         string           `json:"kid"`
+	ThuatToan   string           `json:"thuat_toan"`
+	TrangThai   string           `json:"trang_thai"` // cho_kich_hoat | dang_ky | ngung_ky
+	KichHoatLuc pgtype.Timestamp `json:"kich_hoat_luc"`
+	NgungKyLuc  pgtype.Timestamp `json:"ngung_ky_luc"`
+	HetHanLuc   pgtype.Timestamp `json:"het_han_luc"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+}
+
+func (s *Server) asymmetricSigning() bool {
+	return s.config.JwtConfig.Algorithm != utils.SigningAlgHS256
+}
+
+// SetupTokenSigning áp cấu hình issuer / audience và nạp khóa ký bất đối xứng (tạo khóa đầu tiên nếu chưa có)
+func (s *Server) SetupTokenSigning() {
+	jc := s.config.JwtConfig
+	utils.ConfigureTokens(jc.Issuer, jc.Audience, jc.AcceptLegacyHS256)
+	if !s.asymmetricSigning() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := s.rotateSigningKey(ctx, false, false); err != nil {
+		log.Printf("[JwtKeys] Không thể tạo khóa ký: %v", err)
+	}
+	if err := s.reloadSigningKeys(ctx); err != nil {
+		log.Printf("[JwtKeys] Không thể tải khóa ký, tạm thời ký HS256: %v", err)
+	}
+}
+
+// StartJwtKeyRotation tải lại khóa ký định kỳ và tạo khóa mới khi khóa hiện tại tới hạn xoay vòng
+func (s *Server) StartJwtKeyRotation(ctx context.Context) {
+	if !s.asymmetricSigning() {
+		return
+	}
+	ticker := time.NewTicker(JwtKeyReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rotateCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			created, err := s.rotateSigningKey(rotateCtx, false, false)
+			if err != nil {
+				log.Printf("[JwtKeys] rotation failed: %v", err)
+			} else if created != nil {
+				log.Printf("[JwtKeys] published key %s, signing from %s", created.Kid, created.KichHoatLuc.Time.Format(time.RFC3339))
+			}
+			if err := s.reloadSigningKeys(rotateCtx); err != nil {
+				log.Printf("[JwtKeys] reload failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// reloadSigningKeys nạp các khóa còn trong JWKS, ngừng ký bằng các khóa đã có khóa mới thay thế
+func (s *Server) reloadSigningKeys(ctx context.Context) error {
+	rows, err := s.z.GetPublishedJwtSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	keys := make([]utils.SigningKey, 0, len(rows))
+	var newestActive *db.KhoaKyJwt
+	for i, row := range rows {
+		privatePEM, err := utils.DecryptString(row.KhoaRiengMaHoa, s.config.ServerConfig.ApiSecret)
+		if err != nil {
+			log.Printf("[JwtKeys] cannot decrypt key %s: %v", row.Kid, err)
+			continue
+		}
+		key, err := utils.ParseSigningKey(row.Kid, row.ThuatToan, privatePEM, row.KichHoatLuc.Time)
+		if err != nil {
+			log.Printf("[JwtKeys] cannot parse key %s: %v", row.Kid, err)
+			continue
+		}
+		keys = append(keys, key)
+		if !row.KichHoatLuc.Time.After(now) {
+			newestActive = &rows[i]
+		}
+	}
+	utils.SetSigningKeys(keys)
+
+	// Token ký bằng khóa cũ còn sống tối đa RefreshTokenDuration kể từ lúc khóa mới kích hoạt
+	if newestActive != nil {
+		retiredAt := newestActive.KichHoatLuc.Time
+		if _, err := s.z.RetireJwtSigningKeys(ctx, db.RetireJwtSigningKeysParams{
+			NgungKyLuc: pgtype.Timestamp{Time: retiredAt, Valid: true},
+			HetHanLuc:  pgtype.Timestamp{Time: retiredAt.Add(utils.RefreshTokenDuration + JwtKeyPrePublish), Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to retire old keys: %w", err)
+		}
+	}
+	if _, err := s.z.DeleteExpiredJwtSigningKeys(ctx); err != nil {
+		return fmt.Errorf("failed to delete expired keys: %w", err)
+	}
+	return nil
+}
+
+// rotateSigningKey tạo khóa ký mới khi chưa có khóa, khóa hiện tại đã dùng đủ RotationInterval hoặc khi force.
+// Khóa mới kích hoạt sau JwtKeyPrePublish; emergency kích hoạt ngay và gỡ mọi khóa khác khỏi JWKS.
+// Trả về nil nếu chưa tới hạn, đã có khóa chờ kích hoạt hoặc instance khác đang xoay khóa
+func (s *Server) rotateSigningKey(ctx context.Context, force, emergency bool) (*db.KhoaKyJwt, error) {
+	alg := s.config.JwtConfig.Algorithm
+	rows, err := s.z.GetPublishedJwtSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var current, pending *db.KhoaKyJwt
+	for i, row := range rows {
+		if row.ThuatToan != alg {
+			continue
+		}
+		if row.KichHoatLuc.Time.After(now) {
+			pending = &rows[i]
+		} else if !row.NgungKyLuc.Valid || row.NgungKyLuc.Time.After(now) {
+			current = &rows[i]
+		}
+	}
+
+	if !emergency {
+		if pending != nil {
+			return nil, nil
+		}
+		due := current == nil ||
+			now.Sub(current.KichHoatLuc.Time) >= s.config.JwtConfig.RotationInterval-JwtKeyPrePublish
+		if !force && !due {
+			return nil, nil
+		}
+	}
+
+	// Nhiều instance cùng chạy: chỉ một instance tạo khóa trong mỗi lượt
+	acquired, err := s.redis.SetNX(ctx, jwtKeyRotateLockKey, 1, JwtKeyReloadInterval).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire rotation lock: %w", err)
+	}
+	if !acquired {
+		return nil, nil
+	}
+	defer s.redis.Del(context.Background(), jwtKeyRotateLockKey)
+
+	privatePEM, publicPEM, err := utils.GenerateSigningKeyPEM(alg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	encrypted, err := utils.EncryptString(privatePEM, s.config.ServerConfig.ApiSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt key: %w", err)
+	}
+
+	// Khóa đầu tiên dùng ngay vì chưa có token bất đối xứng nào cần xác thực
+	activeFrom := now.Add(JwtKeyPrePublish)
+	if current == nil || emergency {
+		activeFrom = now
+	}
+	created, err := s.z.CreateJwtSigningKey(ctx, db.CreateJwtSigningKeyParams{
+		Kid:            utils.NewKeyID(),
+		ThuatToan:      alg,
+		KhoaRiengMaHoa: encrypted,
+		KhoaCongKhai:   publicPEM,
+		KichHoatLuc:    pgtype.Timestamp{Time: activeFrom, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save key: %w", err)
+	}
+
+	if emergency {
+		if _, err := s.z.ExpireJwtSigningKeysExcept(ctx, created.Kid); err != nil {
+			return nil, fmt.Errorf("failed to expire old keys: %w", err)
+		}
+	}
+	return &created, nil
+}
+
+func toJwtKeyInfo(row db.KhoaKyJwt, now time.Time) jwtKeyInfo {
+	status := "dang_ky"
+	if row.KichHoatLuc.Time.After(now) {
+		status = "cho_kich_hoat"
+	} else if row.NgungKyLuc.Valid && !row.NgungKyLuc.Time.After(now) {
+		status = "ngung_ky"
+	}
+	return jwtKeyInfo{
+		Kid:         row.Kid,
+		ThuatToan:   row.ThuatToan,
+		TrangThai:   status,
+		KichHoatLuc: row.KichHoatLuc,
+		NgungKyLuc:  row.NgungKyLuc,
+		HetHanLuc:   row.HetHanLuc,
+		NgayTao:     row.NgayTao,
+	}
+}
+
+// GetJWKS godoc
+// @Summary JSON Web Key Set
+// @Description Khóa công khai dùng xác thực access token (RS256 / EdDSA), chọn khóa theo kid trong header token
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (s *Server) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksCacheMaxAge.Seconds())))
+	c.JSON(http.StatusOK, utils.CurrentJWKS())
+}
+
+// GetJwtSigningKeys godoc
+// @Summary List JWT signing keys
+// @Description Danh sách khóa ký JWT còn trong JWKS cùng trạng thái (không gồm khóa riêng)
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/jwt-keys [get]
+func (s *Server) GetJwtSigningKeys(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.z.GetPublishedJwtSigningKeys(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Không thể tải khóa ký",
+			"details": err.Error(),
+		})
+		return
+	}
+	now := time.Now()
+	keys := make([]jwtKeyInfo, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, toJwtKeyInfo(row, now))
+	}
+
+	jc := s.config.JwtConfig
+	c.JSON(http.StatusOK, gin.H{
+		"data":             keys,
+		"thuat_toan":       jc.Algorithm,
+		"issuer":           jc.Issuer,
+		"audience":         jc.Audience,
+		"chu_ky_xoay_vong": jc.RotationInterval.String(),
+	})
+}
+
+// RotateJwtSigningKey godoc
+// @Summary Rotate JWT signing key
+// @Description Tạo khóa ký mới ngay. Mặc định khóa mới được công bố trước và bắt đầu ký sau 15 phút;
+// @Description khan_cap=true (khi lộ khóa) ký ngay bằng khóa mới và gỡ mọi khóa cũ, mọi phiên phải đăng nhập lại
+// @Tags Admin
+// @Produce json
+// @Param khan_cap query bool false "Xoay khóa khẩn cấp"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H "Server đang dùng HS256"
+// @Failure 409 {object} gin.H "Đang có khóa chờ kích hoạt hoặc đang xoay khóa"
+// @Failure 500 {object} gin.H
+// @Router /admin/jwt-keys/rotate [post]
+func (s *Server) RotateJwtSigningKey(c *gin.Context) {
+	if !s.asymmetricSigning() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Server đang ký JWT bằng HS256, không có khóa để xoay vòng"})
+		return
+	}
+	emergency := c.Query("khan_cap") == "true"
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	created, err := s.rotateSigningKey(ctx, true, emergency)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Không thể xoay khóa ký",
+			"details": err.Error(),
+		})
+		return
+	}
+	if created == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Đã có khóa chờ kích hoạt hoặc một tiến trình khác đang xoay khóa"})
+		return
+	}
+	if err := s.reloadSigningKeys(ctx); err != nil {
+		log.Printf("[JwtKeys] reload after rotation failed: %v", err)
+	}
+
+	info := toJwtKeyInfo(*created, time.Now())
+	s.recordAudit(c, AuditJwtKeyRotate, AuditTargetJwtKey, created.Kid, nil, gin.H{"khoa": info, "khan_cap": emergency})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Đã tạo khóa ký mới",
+		"data":    info,
+	})
+}
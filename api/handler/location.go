@@ -296,6 +296,30 @@ func fetchLocationFromAPI(ip string) (*LocationResponse, error) {
 	return nil, fmt.Errorf("all providers failed - primary: %v, backup: %v", err, err2)
 }
 
+// lookupLocation tra vị trí của IP công khai, dùng chung cache Redis 24 giờ với API /location
+func (s *Server) lookupLocation(ctx context.Context, ip string) (*LocationResponse, error) {
+	cacheKey := fmt.Sprintf("location:%s", ip)
+
+	cachedData, err := s.redis.Get(ctx, cacheKey).Result()
+	if err == nil && cachedData != "" {
+		var location LocationResponse
+		if err := json.Unmarshal([]byte(cachedData), &location); err == nil {
+			return &location, nil
+		}
+	}
+
+	location, err := fetchLocationFromAPI(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	locationJSON, err := json.Marshal(location)
+	if err == nil {
+		s.redis.Set(ctx, cacheKey, locationJSON, 24*time.Hour)
+	}
+	return location, nil
+}
+
 // GetLocation godoc
 // @Summary Lấy thông tin vị trí địa lý của người dùng
 // @Description Phát hiện quốc gia và thông tin địa lý dựa trên địa chỉ IP của người dùng. Kết quả được cache trong 24 giờ.
@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/helpers"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// LoginFailureWindow là thời gian giữ bộ đếm đăng nhập sai của một tài khoản (reset khi đăng nhập đúng)
+	LoginFailureWindow = 24 * time.Hour
+	// LoginLockoutThreshold là số lần sai liên tiếp bắt đầu bị khóa tạm
+	LoginLockoutThreshold = 5
+	// LoginLockoutBase là thời gian khóa ở lần vượt ngưỡng đầu tiên, mỗi lần sai tiếp theo tăng gấp đôi
+	LoginLockoutBase = 1 * time.Minute
+	// LoginLockoutMax là thời gian khóa tối đa
+	LoginLockoutMax = 24 * time.Hour
+
+	loginFailKeyPrefix = "login:fail:"
+	loginLockKeyPrefix = "login:lock:"
+)
+
+// Lý do đăng nhập thất bại (lich_su_dang_nhap.ly_do_that_bai)
+const (
+	LoginFailWrongPassword = "sai_mat_khau"
+	LoginFailUnknownEmail  = "khong_ton_tai"
+	LoginFailLocked        = "dang_bi_khoa"
+	LoginFailTwoFactor     = "sai_ma_2fa"
+)
+
+// loginAccountKey chuẩn hóa email làm khóa đếm: đếm theo tài khoản chứ không theo IP
+// nên tấn công dò mật khẩu từ nhiều IP vào cùng một email vẫn bị chặn
+func loginAccountKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// loginLockoutDuration tính thời gian khóa theo số lần sai: 5 lần -> 1 phút, 6 -> 2 phút, 7 -> 4 phút... tối đa 24 giờ
+func loginLockoutDuration(failures int64) time.Duration {
+	if failures < LoginLockoutThreshold {
+		return 0
+	}
+	duration := LoginLockoutBase
+	for i := int64(LoginLockoutThreshold); i < failures; i++ {
+		duration *= 2
+		if duration >= LoginLockoutMax {
+			return LoginLockoutMax
+		}
+	}
+	return duration
+}
+
+// checkLoginLockout trả về true (và đã ghi response 429) nếu tài khoản đang bị khóa tạm
+func (s *Server) checkLoginLockout(c *gin.Context, email string) bool {
+	ttl, err := s.redis.TTL(c.Request.Context(), loginLockKeyPrefix+loginAccountKey(email)).Result()
+	if err != nil || ttl <= 0 {
+		return false
+	}
+
+	s.recordLoginAttempt(c, email, pgtype.UUID{}, false, LoginFailLocked)
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Tài khoản tạm thời bị khóa do đăng nhập sai nhiều lần, vui lòng thử lại sau",
+		"thu_lai_sau": int(ttl.Seconds()) + 1,
+	})
+	return true
+}
+
+// recordLoginFailure tăng bộ đếm sai của tài khoản, khóa tạm khi vượt ngưỡng và ghi lịch sử đăng nhập
+func (s *Server) recordLoginFailure(c *gin.Context, email string, userID pgtype.UUID, reason string) {
+	ctx := c.Request.Context()
+	key := loginAccountKey(email)
+
+	failures, err := s.redis.Incr(ctx, loginFailKeyPrefix+key).Result()
+	if err != nil {
+		log.Printf("[LoginSecurity] count failure for %s failed: %v", key, err)
+	} else {
+		if failures == 1 {
+			s.redis.Expire(ctx, loginFailKeyPrefix+key, LoginFailureWindow)
+		}
+		if lockout := loginLockoutDuration(failures); lockout > 0 {
+			if err := s.redis.SetEx(ctx, loginLockKeyPrefix+key, 1, lockout).Err(); err != nil {
+				log.Printf("[LoginSecurity] lock %s failed: %v", key, err)
+			}
+			log.Printf("[LoginSecurity] %s locked for %s after %d failed attempts", key, lockout, failures)
+		}
+	}
+
+	s.recordLoginAttempt(c, email, userID, false, reason)
+}
+
+// recordLoginSuccess xóa bộ đếm sai, ghi lịch sử đăng nhập và kiểm tra quốc gia đăng nhập ở nền
+func (s *Server) recordLoginSuccess(c *gin.Context, user db.NguoiDung) {
+	s.redis.Del(c.Request.Context(), loginFailKeyPrefix+loginAccountKey(user.Email))
+
+	attempt, ok := s.recordLoginAttempt(c, user.Email, user.ID, true, "")
+	if !ok {
+		return
+	}
+	go s.detectNewCountryLogin(attempt, user)
+}
+
+// recordLoginAttempt lưu IP và user agent của lần đăng nhập
+func (s *Server) recordLoginAttempt(c *gin.Context, email string, userID pgtype.UUID, success bool, reason string) (db.LichSuDangNhap, bool) {
+	ip := GetClientIP(c)
+	device := c.Request.UserAgent()
+	var lyDo *string
+	if reason != "" {
+		lyDo = &reason
+	}
+
+	attempt, err := s.z.CreateLoginAttempt(c.Request.Context(), db.CreateLoginAttemptParams{
+		NguoiDungID: userID,
+		Email:       loginAccountKey(email),
+		DiaChiIp:    &ip,
+		ThietBi:     &device,
+		ThanhCong:   success,
+		LyDoThatBai: lyDo,
+	})
+	if err != nil {
+		log.Printf("[LoginSecurity] record login attempt for %s failed: %v", email, err)
+		return db.LichSuDangNhap{}, false
+	}
+	return attempt, true
+}
+
+// detectNewCountryLogin tra quốc gia của IP đăng nhập; nếu tài khoản đã có lịch sử đăng nhập
+// nhưng chưa từng đăng nhập từ quốc gia này thì đánh dấu đáng ngờ và gửi email cảnh báo
+func (s *Server) detectNewCountryLogin(attempt db.LichSuDangNhap, user db.NguoiDung) {
+	if attempt.DiaChiIp == nil || net.ParseIP(*attempt.DiaChiIp) == nil || isPrivateIP(*attempt.DiaChiIp) {
+		return
+	}
+	ip := *attempt.DiaChiIp
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	location, err := s.lookupLocation(ctx, ip)
+	if err != nil || location.CountryCode == "" {
+		log.Printf("[LoginSecurity] lookup location for %s failed: %v", ip, err)
+		return
+	}
+	countryCode := strings.ToUpper(location.CountryCode)
+
+	history, err := s.z.GetLoginCountryHistory(ctx, db.GetLoginCountryHistoryParams{
+		NguoiDungID: user.ID,
+		MaQuocGia:   &countryCode,
+		NgoaiTruID:  attempt.ID,
+	})
+	if err != nil {
+		log.Printf("[LoginSecurity] get country history for %s failed: %v", user.Email, err)
+		return
+	}
+	// Lần đầu xác định được quốc gia thì chỉ ghi nhận, chưa có gì để so sánh
+	suspicious := history.SoLanCoQuocGia > 0 && history.SoLanCungQuocGia == 0
+
+	var city *string
+	if location.City != "" {
+		city = &location.City
+	}
+	if err := s.z.UpdateLoginAttemptLocation(ctx, db.UpdateLoginAttemptLocationParams{
+		ID:        attempt.ID,
+		QuocGia:   &location.Country,
+		MaQuocGia: &countryCode,
+		ThanhPho:  city,
+		DangNgo:   suspicious,
+	}); err != nil {
+		log.Printf("[LoginSecurity] update login attempt %d failed: %v", attempt.ID, err)
+		return
+	}
+
+	if !suspicious {
+		return
+	}
+	log.Printf("[LoginSecurity] suspicious login for %s from new country %s (%s)", user.Email, countryCode, ip)
+
+	place := location.Country
+	if location.City != "" {
+		place = fmt.Sprintf("%s, %s", location.City, location.Country)
+	}
+	device := ""
+	if attempt.ThietBi != nil {
+		device = *attempt.ThietBi
+	}
+	helpers.SendSuspiciousLoginAlertAsync(user.Email, user.HoTen, place, ip, device,
+		attempt.NgayTao.Time.Format("02/01/2006 15:04"), s.config.EmailConfig)
+}
+
+// GetMyLoginHistory godoc
+// @Summary Get my login history
+// @Description Lịch sử đăng nhập (IP, thiết bị, quốc gia) của người dùng hiện tại, đánh dấu các lần đăng nhập đáng ngờ
+// @Tags auth
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/login-history [get]
+func (s *Server) GetMyLoginHistory(c *gin.Context) {
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	s.respondLoginHistory(c, jwtClaims.Id)
+}
+
+// AdminGetUserLoginHistory godoc
+// @Summary Get user login history
+// @Description Admin xem lịch sử đăng nhập của một người dùng
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/login-history [get]
+func (s *Server) AdminGetUserLoginHistory(c *gin.Context) {
+	var userID pgtype.UUID
+	if err := userID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	s.respondLoginHistory(c, userID)
+}
+
+func (s *Server) respondLoginHistory(c *gin.Context, userID pgtype.UUID) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	history, err := s.z.GetLoginHistoryByUser(ctx, db.GetLoginHistoryByUserParams{
+		NguoiDungID: userID,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get login history", "details": err.Error()})
+		return
+	}
+	total, err := s.z.CountLoginHistoryByUser(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count login history", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Login history retrieved successfully",
+		"data":    history,
+		"pagination": gin.H{
+			"limit":    limit,
+			"offset":   offset,
+			"total":    total,
+			"has_more": offset+len(history) < int(total),
+		},
+	})
+}
+
+// AdminUnlockUserLogin godoc
+// @Summary Unlock user login
+// @Description Admin gỡ khóa tạm do đăng nhập sai nhiều lần và xóa bộ đếm sai của tài khoản
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/login-lockout [delete]
+func (s *Server) AdminUnlockUserLogin(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var userID pgtype.UUID
+	if err := userID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := s.z.GetUserById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy người dùng"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user", "details": err.Error()})
+		return
+	}
+
+	key := loginAccountKey(user.Email)
+	if err := s.redis.Del(ctx, loginFailKeyPrefix+key, loginLockKeyPrefix+key).Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã gỡ khóa đăng nhập cho người dùng"})
+}
@@ -0,0 +1,725 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/apple"
+	"github.com/markbates/goth/providers/facebook"
+	"github.com/markbates/goth/providers/google"
+	"github.com/redis/go-redis/v9"
+	"travia.backend/api/helpers"
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	OAuthProviderGoogle   = "google"
+	OAuthProviderFacebook = "facebook"
+	OAuthProviderApple    = "apple"
+
+	// OAuthLinkMaxAttempts là số lần xác nhận sai tối đa cho một yêu cầu liên kết
+	OAuthLinkMaxAttempts = 5
+
+	oauthLinkIntentKeyPrefix  = "oauth:link:intent:"
+	oauthPendingLinkKeyPrefix = "oauth:link:pending:"
+	oauthLinkIntentTTL        = 10 * time.Minute
+	oauthPendingLinkTTL       = 15 * time.Minute
+	// oauthLinkSessionKey lưu nonce liên kết trong cookie phiên OAuth của trình duyệt đã gọi API liên kết
+	oauthLinkSessionKey = "oauth_link_nonce"
+	// Apple cho phép client secret sống tối đa 6 tháng, secret được ký lại mỗi lần khởi động server
+	appleClientSecretTTL = 180 * 24 * time.Hour
+)
+
+// oauthIdentity là thông tin người dùng nhà cung cấp OAuth trả về
+type oauthIdentity struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Avatar   string `json:"avatar"`
+}
+
+func (i oauthIdentity) accountParams(userID pgtype.UUID) db.CreateOAuthAccountParams {
+	return db.CreateOAuthAccountParams{
+		NguoiDungID:  userID,
+		NhaCungCap:   i.Provider,
+		NhaCungCapID: i.Subject,
+		Email:        optionalString(i.Email),
+		TenHienThi:   optionalString(i.Name),
+		AnhDaiDien:   optionalString(i.Avatar),
+	}
+}
+
+// oauthLinkIntent được tạo khi người dùng đang đăng nhập bắt đầu liên kết thêm nhà cung cấp;
+// khóa Redis chính là tham số state của luồng OAuth. NonceHash gắn yêu cầu với cookie phiên OAuth của trình duyệt
+// đã gọi API liên kết, để state bị gửi cho người khác không liên kết được tài khoản mạng xã hội của họ
+type oauthLinkIntent struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	Provider  string      `json:"provider"`
+	NonceHash string      `json:"nonce_hash"`
+}
+
+// oauthPendingLink là đăng nhập OAuth có email trùng tài khoản hiện có, chờ chủ tài khoản xác nhận
+type oauthPendingLink struct {
+	UserID   pgtype.UUID   `json:"user_id"`
+	Identity oauthIdentity `json:"identity"`
+	CodeHash string        `json:"code_hash"`
+	Attempts int           `json:"attempts"`
+}
+
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// newOAuthLinkToken tạo token ngẫu nhiên cho state liên kết / mã liên kết chờ xác nhận
+func newOAuthLinkToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *Server) SetupAuthProviders() {
+	// Sử dụng secret key từ config
+	key := s.config.ServerConfig.SecretKey
+	maxAge := 86400 * 30 // 30 days
+	isProd := s.config.ServerConfig.Environment == "production"
+
+	// Apple trả kết quả bằng form_post (POST cross-site) nên cookie phiên OAuth cần SameSite=None khi chạy HTTPS
+	sameSite := http.SameSiteLaxMode
+	if isProd {
+		sameSite = http.SameSiteNoneMode
+	}
+
+	store := cookie.NewStore([]byte(key))
+	options := sessions.Options{
+		Path:     "/",
+		Domain:   "",
+		MaxAge:   maxAge,
+		Secure:   isProd,
+		HttpOnly: true,
+		SameSite: sameSite,
+	}
+	store.Options(options)
+	gothic.Store = store
+
+	providers := []goth.Provider{
+		google.New(
+			s.config.GoogleCloudConfig.GoogleClientId,
+			s.config.GoogleCloudConfig.GoogleClientSecret,
+			s.config.GoogleCloudConfig.GoogleRedirectUris,
+			"email",
+			"profile",
+		),
+	}
+
+	oc := s.config.OAuthConfig
+	if oc.FacebookClientId != "" {
+		providers = append(providers, facebook.New(
+			oc.FacebookClientId,
+			oc.FacebookClientSecret,
+			oc.FacebookRedirectUri,
+			"email",
+			"public_profile",
+		))
+	}
+
+	if oc.AppleClientId != "" {
+		now := time.Now()
+		secret, err := apple.MakeSecret(apple.SecretParams{
+			PKCS8PrivateKey: oc.ApplePrivateKey,
+			TeamId:          oc.AppleTeamId,
+			KeyId:           oc.AppleKeyId,
+			ClientId:        oc.AppleClientId,
+			Iat:             int(now.Unix()),
+			Exp:             int(now.Add(appleClientSecretTTL).Unix()),
+		})
+		if err != nil {
+			log.Printf("[OAuth] Không thể tạo client secret Apple, tắt Apple Sign-In: %v", err)
+		} else {
+			providers = append(providers, apple.New(
+				oc.AppleClientId,
+				*secret,
+				oc.AppleRedirectUri,
+				nil,
+				apple.ScopeName,
+				apple.ScopeEmail,
+			))
+		}
+	}
+
+	goth.UseProviders(providers...)
+}
+
+// withOAuthProvider kiểm tra nhà cung cấp đã được cấu hình và gắn vào context cho gothic
+func withOAuthProvider(c *gin.Context) (string, bool) {
+	provider := c.Param("provider")
+	if _, err := goth.GetProvider(provider); err != nil {
+		helpers.BadRequest(c, "Unsupported OAuth provider", nil)
+		return "", false
+	}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), gothic.ProviderParamKey, provider))
+	return provider, true
+}
+
+// AuthHandler godoc
+// @Summary Bắt đầu đăng nhập OAuth
+// @Description Chuyển hướng tới trang đăng nhập của nhà cung cấp (google, facebook, apple). Tham số state do API liên kết tài khoản cấp
+// @Tags auth
+// @Param provider path string true "Nhà cung cấp" Enums(google, facebook, apple)
+// @Param state query string false "State liên kết tài khoản"
+// @Success 307 "Chuyển hướng tới nhà cung cấp"
+// @Failure 400 {object} gin.H "Nhà cung cấp không được hỗ trợ"
+// @Router /auth/oauth/{provider} [get]
+func (s *Server) AuthHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := withOAuthProvider(c); !ok {
+			return
+		}
+		gothic.BeginAuthHandler(c.Writer, c.Request)
+	}
+}
+
+// AuthCallbackHandler godoc
+// @Summary Callback đăng nhập OAuth
+// @Description Hoàn tất đăng nhập OAuth (Apple gọi bằng POST). Email trùng tài khoản có sẵn nhưng chưa liên kết trả 409 kèm ma_lien_ket để xác nhận
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Nhà cung cấp" Enums(google, facebook, apple)
+// @Success 200 {object} gin.H "Đăng nhập hoặc liên kết thành công"
+// @Failure 400 {object} gin.H "Xác thực OAuth thất bại"
+// @Failure 401 {object} gin.H "Tài khoản đã bị khóa"
+// @Failure 403 {object} gin.H "Yêu cầu liên kết không được tạo từ trình duyệt này"
+// @Failure 409 {object} gin.H "Cần xác nhận liên kết hoặc tài khoản đã được liên kết"
+// @Failure 500 {object} gin.H "Lỗi server"
+// @Router /auth/oauth/{provider}/callback [get]
+func (s *Server) AuthCallbackHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, ok := withOAuthProvider(c)
+		if !ok {
+			return
+		}
+		// Đọc trước khi CompleteUserAuth xóa phiên OAuth
+		linkNonce, _ := gothic.GetFromSession(oauthLinkSessionKey, c.Request)
+		user, err := gothic.CompleteUserAuth(c.Writer, c.Request)
+		if err != nil {
+			helpers.BadRequest(c, "Failed to complete OAuth authentication", err)
+			return
+		}
+		if user.UserID == "" {
+			helpers.BadRequest(c, "Nhà cung cấp không trả về định danh người dùng", nil)
+			return
+		}
+
+		identity := oauthIdentity{
+			Provider: provider,
+			Subject:  user.UserID,
+			Email:    strings.ToLower(strings.TrimSpace(user.Email)),
+			Name:     strings.TrimSpace(user.Name),
+			Avatar:   user.AvatarURL,
+		}
+		if identity.Name == "" {
+			identity.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		}
+		// Apple chỉ gửi họ tên ở lần đăng nhập đầu tiên, trong trường form "user"
+		if provider == OAuthProviderApple && identity.Name == "" {
+			var appleUser struct {
+				Name struct {
+					FirstName string `json:"firstName"`
+					LastName  string `json:"lastName"`
+				} `json:"name"`
+			}
+			if err := json.Unmarshal([]byte(c.Request.FormValue("user")), &appleUser); err == nil {
+				identity.Name = strings.TrimSpace(appleUser.Name.FirstName + " " + appleUser.Name.LastName)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		// state trùng yêu cầu liên kết: gắn nhà cung cấp vào tài khoản đang đăng nhập thay vì đăng nhập
+		if state := gothic.GetState(c.Request); state != "" {
+			raw, err := s.redis.GetDel(ctx, oauthLinkIntentKeyPrefix+state).Result()
+			if err == nil {
+				var intent oauthLinkIntent
+				if err := json.Unmarshal([]byte(raw), &intent); err != nil || intent.Provider != provider {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Yêu cầu liên kết không hợp lệ"})
+					return
+				}
+				if linkNonce == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(linkNonce)), []byte(intent.NonceHash)) != 1 {
+					c.JSON(http.StatusForbidden, gin.H{"error": "Yêu cầu liên kết không được tạo từ trình duyệt này, vui lòng bắt đầu liên kết lại"})
+					return
+				}
+				s.linkOAuthIdentity(c, ctx, intent.UserID, identity)
+				return
+			} else if !errors.Is(err, redis.Nil) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống", "details": err.Error()})
+				return
+			}
+		}
+
+		account, err := s.z.GetOAuthAccount(ctx, db.GetOAuthAccountParams{
+			NhaCungCap:   provider,
+			NhaCungCapID: identity.Subject,
+		})
+		if err == nil {
+			if err := s.z.TouchOAuthAccount(ctx, db.TouchOAuthAccountParams{
+				Email:      optionalString(identity.Email),
+				TenHienThi: optionalString(identity.Name),
+				AnhDaiDien: optionalString(identity.Avatar),
+				ID:         account.ID,
+			}); err != nil {
+				log.Printf("[OAuth] Không thể cập nhật tài khoản liên kết %d: %v", account.ID, err)
+			}
+			s.completeOAuthLogin(c, ctx, account.NguoiDungID)
+			return
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống", "details": err.Error()})
+			return
+		}
+
+		if identity.Email == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Nhà cung cấp không trả về email, vui lòng cấp quyền truy cập email hoặc đăng nhập bằng mật khẩu",
+			})
+			return
+		}
+
+		// Email đã thuộc tài khoản khác: không tự gộp, chủ tài khoản phải xác nhận
+		existing, err := s.z.GetUserByEmail(ctx, identity.Email)
+		if err == nil {
+			s.startPendingOAuthLink(c, ctx, existing, identity)
+			return
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống", "details": err.Error()})
+			return
+		}
+
+		s.createOAuthUser(c, ctx, identity)
+	}
+}
+
+// completeOAuthLogin đăng nhập tài khoản đã liên kết với nhà cung cấp
+func (s *Server) completeOAuthLogin(c *gin.Context, ctx context.Context, userID pgtype.UUID) {
+	user, err := s.z.GetUserAccountByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tải tài khoản", "details": err.Error()})
+		return
+	}
+	if user.DangHoatDong != nil && !*user.DangHoatDong {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tài khoản đã bị khóa"})
+		return
+	}
+	s.completeLogin(c, user)
+}
+
+// createOAuthUser tạo tài khoản khách hàng mới cho email chưa đăng ký (email đã được nhà cung cấp xác thực)
+func (s *Server) createOAuthUser(c *gin.Context, ctx context.Context, identity oauthIdentity) {
+	// Tài khoản OAuth không có mật khẩu riêng: lưu bản băm của chuỗi ngẫu nhiên không ai biết
+	secret, err := newOAuthLinkToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	hashed, err := utils.HashPassword(secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = strings.Split(identity.Email, "@")[0]
+	}
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	user, err := s.z.CreateOAuthUser(ctx, db.CreateUserParams{
+		HoTen:        name,
+		Email:        identity.Email,
+		MatKhauMaHoa: hashed,
+		VaiTro:       db.NullVaiTroNguoiDung{VaiTroNguoiDung: db.VaiTroNguoiDungKhachHang, Valid: true},
+		DangHoatDong: helpers.NewBool(true),
+		XacThuc:      helpers.NewBool(true),
+		NgayTao:      now,
+		NgayCapNhat:  now,
+	}, identity.accountParams(pgtype.UUID{}))
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email hoặc tài khoản mạng xã hội đã được đăng ký, vui lòng thử lại"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo tài khoản", "details": err.Error()})
+		return
+	}
+	s.completeLogin(c, user)
+}
+
+// startPendingOAuthLink lưu đăng nhập OAuth chờ xác nhận và gửi mã xác nhận tới email của tài khoản hiện có
+func (s *Server) startPendingOAuthLink(c *gin.Context, ctx context.Context, user db.NguoiDung, identity oauthIdentity) {
+	if user.DangHoatDong != nil && !*user.DangHoatDong {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tài khoản đã bị khóa"})
+		return
+	}
+	token, err := newOAuthLinkToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	code := helpers.GenerateVerificationCode()
+	data, err := json.Marshal(oauthPendingLink{
+		UserID:   user.ID,
+		Identity: identity,
+		CodeHash: utils.HashToken(code),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if err := s.redis.SetEx(ctx, oauthPendingLinkKeyPrefix+token, data, oauthPendingLinkTTL).Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lưu yêu cầu liên kết"})
+		return
+	}
+
+	helpers.SendOAuthLinkCodeAsync(user.Email, user.HoTen, oauthProviderLabel(identity.Provider), code, s.config.EmailConfig)
+
+	c.JSON(http.StatusConflict, gin.H{
+		"error":            "Email đã được dùng cho một tài khoản Travia. Vui lòng xác nhận để liên kết tài khoản",
+		"yeu_cau_lien_ket": true,
+		"ma_lien_ket":      token,
+		"nha_cung_cap":     identity.Provider,
+		"email":            user.Email,
+		"co_mat_khau":      user.CoMatKhau,
+		"het_han_sau":      int(oauthPendingLinkTTL.Seconds()),
+	})
+}
+
+// linkOAuthIdentity gắn tài khoản nhà cung cấp vào người dùng
+func (s *Server) linkOAuthIdentity(c *gin.Context, ctx context.Context, userID pgtype.UUID, identity oauthIdentity) {
+	existing, err := s.z.GetOAuthAccount(ctx, db.GetOAuthAccountParams{
+		NhaCungCap:   identity.Provider,
+		NhaCungCapID: identity.Subject,
+	})
+	if err == nil {
+		if existing.NguoiDungID == userID {
+			c.JSON(http.StatusOK, gin.H{"message": "Tài khoản đã được liên kết", "data": existing})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Tài khoản " + oauthProviderLabel(identity.Provider) + " này đã được liên kết với người dùng khác"})
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống", "details": err.Error()})
+		return
+	}
+
+	account, err := s.z.CreateOAuthAccount(ctx, identity.accountParams(userID))
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bạn đã liên kết một tài khoản " + oauthProviderLabel(identity.Provider) + " khác"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liên kết tài khoản", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Liên kết tài khoản thành công", "data": account})
+}
+
+func oauthProviderLabel(provider string) string {
+	switch provider {
+	case OAuthProviderGoogle:
+		return "Google"
+	case OAuthProviderFacebook:
+		return "Facebook"
+	case OAuthProviderApple:
+		return "Apple"
+	}
+	return provider
+}
+
+// ConfirmOAuthLink godoc
+// @Summary Xác nhận liên kết đăng nhập mạng xã hội với tài khoản có sẵn
+// @Description Xác nhận bằng mật khẩu hiện tại (nếu tài khoản có mật khẩu) hoặc mã 6 số gửi qua email, sau đó đăng nhập
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ConfirmOAuthLinkRequest true "Mã liên kết và mật khẩu / mã xác nhận"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H "Mật khẩu hoặc mã xác nhận không đúng"
+// @Failure 404 {object} gin.H "Yêu cầu liên kết không tồn tại hoặc đã hết hạn"
+// @Failure 409 {object} gin.H "Tài khoản đã được liên kết"
+// @Failure 429 {object} gin.H "Nhập sai quá nhiều lần"
+// @Router /auth/oauth/link/confirm [post]
+func (s *Server) ConfirmOAuthLink(c *gin.Context) {
+	var req models.ConfirmOAuthLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dữ liệu không hợp lệ", "details": err.Error()})
+		return
+	}
+	if req.MatKhau == nil && req.MaXacNhan == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cần nhập mật khẩu hoặc mã xác nhận"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	key := oauthPendingLinkKeyPrefix + req.MaLienKet
+	raw, err := s.redis.Get(ctx, key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Yêu cầu liên kết không tồn tại hoặc đã hết hạn"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống", "details": err.Error()})
+		return
+	}
+	var pending oauthPendingLink
+	if err := json.Unmarshal([]byte(raw), &pending); err != nil {
+		s.redis.Del(ctx, key)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Yêu cầu liên kết không tồn tại hoặc đã hết hạn"})
+		return
+	}
+
+	user, err := s.z.GetUserAccountByID(ctx, pending.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tải tài khoản", "details": err.Error()})
+		return
+	}
+
+	// Tài khoản đang bị khóa tạm do đăng nhập sai thì không cho xác nhận liên kết
+	if s.checkLoginLockout(c, user.Email) {
+		return
+	}
+
+	verified := false
+	if req.MatKhau != nil {
+		if !user.CoMatKhau {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tài khoản chưa đặt mật khẩu, vui lòng dùng mã xác nhận gửi qua email"})
+			return
+		}
+		verified = utils.CheckHashPassword(*req.MatKhau, user.MatKhauMaHoa)
+		// Sai mật khẩu ở đây tính chung ngưỡng khóa tạm với /auth/login
+		if !verified {
+			s.recordLoginFailure(c, user.Email, user.ID, LoginFailWrongPassword)
+		}
+	} else {
+		verified = subtle.ConstantTimeCompare([]byte(utils.HashToken(*req.MaXacNhan)), []byte(pending.CodeHash)) == 1
+	}
+
+	if !verified {
+		pending.Attempts++
+		if pending.Attempts >= OAuthLinkMaxAttempts {
+			s.redis.Del(ctx, key)
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Nhập sai quá nhiều lần, vui lòng đăng nhập lại bằng mạng xã hội"})
+			return
+		}
+		if data, err := json.Marshal(pending); err == nil {
+			s.redis.SetArgs(ctx, key, data, redis.SetArgs{KeepTTL: true})
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":          "Mật khẩu hoặc mã xác nhận không đúng",
+			"so_lan_con_lai": OAuthLinkMaxAttempts - pending.Attempts,
+		})
+		return
+	}
+	s.redis.Del(ctx, key)
+
+	if user.DangHoatDong != nil && !*user.DangHoatDong {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tài khoản đã bị khóa"})
+		return
+	}
+
+	if _, err := s.z.CreateOAuthAccount(ctx, pending.Identity.accountParams(user.ID)); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tài khoản " + oauthProviderLabel(pending.Identity.Provider) + " đã được liên kết"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liên kết tài khoản", "details": err.Error()})
+		return
+	}
+	s.completeLogin(c, user)
+}
+
+// GetMyOAuthAccounts godoc
+// @Summary Danh sách tài khoản mạng xã hội đã liên kết
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Router /auth/oauth/accounts [get]
+func (s *Server) GetMyOAuthAccounts(c *gin.Context) {
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	user, err := s.z.GetUserAccountByID(ctx, claims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tải tài khoản", "details": err.Error()})
+		return
+	}
+	accounts, err := s.z.GetOAuthAccountsByUser(ctx, claims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tải tài khoản liên kết", "details": err.Error()})
+		return
+	}
+
+	available := []string{}
+	for _, p := range []string{OAuthProviderGoogle, OAuthProviderFacebook, OAuthProviderApple} {
+		if _, err := goth.GetProvider(p); err == nil {
+			available = append(available, p)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":                accounts,
+		"co_mat_khau":         user.CoMatKhau,
+		"nha_cung_cap_ho_tro": available,
+	})
+}
+
+// LinkOAuthProvider godoc
+// @Summary Bắt đầu liên kết nhà cung cấp OAuth vào tài khoản đang đăng nhập
+// @Description Trả về auth_url và đặt cookie phiên OAuth; mở auth_url trong vòng 10 phút bằng chính trình duyệt đã gọi API này
+// @Description (gọi kèm credentials) để hoàn tất liên kết. Callback từ trình duyệt khác bị từ chối
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Nhà cung cấp" Enums(google, facebook, apple)
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H "Nhà cung cấp không được hỗ trợ"
+// @Failure 409 {object} gin.H "Đã liên kết nhà cung cấp này"
+// @Router /auth/oauth/{provider}/link [post]
+func (s *Server) LinkOAuthProvider(c *gin.Context) {
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	provider, ok := withOAuthProvider(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	accounts, err := s.z.GetOAuthAccountsByUser(ctx, claims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tải tài khoản liên kết", "details": err.Error()})
+		return
+	}
+	for _, a := range accounts {
+		if a.NhaCungCap == provider {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bạn đã liên kết tài khoản " + oauthProviderLabel(provider)})
+			return
+		}
+	}
+
+	state, err := newOAuthLinkToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	nonce, err := newOAuthLinkToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if err := gothic.StoreInSession(oauthLinkSessionKey, nonce, c.Request, c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo yêu cầu liên kết"})
+		return
+	}
+	data, err := json.Marshal(oauthLinkIntent{UserID: claims.Id, Provider: provider, NonceHash: utils.HashToken(nonce)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if err := s.redis.SetEx(ctx, oauthLinkIntentKeyPrefix+state, data, oauthLinkIntentTTL).Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo yêu cầu liên kết"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"auth_url":    "/api/auth/oauth/" + provider + "?state=" + state,
+		"het_han_sau": int(oauthLinkIntentTTL.Seconds()),
+	})
+}
+
+// UnlinkOAuthProvider godoc
+// @Summary Hủy liên kết nhà cung cấp OAuth
+// @Description Không cho hủy phương thức đăng nhập cuối cùng của tài khoản chưa đặt mật khẩu
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Nhà cung cấp" Enums(google, facebook, apple)
+// @Success 200 {object} gin.H
+// @Failure 404 {object} gin.H "Chưa liên kết nhà cung cấp này"
+// @Failure 409 {object} gin.H "Không thể hủy phương thức đăng nhập cuối cùng"
+// @Router /auth/oauth/{provider} [delete]
+func (s *Server) UnlinkOAuthProvider(c *gin.Context) {
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	provider := c.Param("provider")
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	user, err := s.z.GetUserAccountByID(ctx, claims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tải tài khoản", "details": err.Error()})
+		return
+	}
+	if !user.CoMatKhau {
+		count, err := s.z.CountOAuthAccountsByUser(ctx, claims.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống", "details": err.Error()})
+			return
+		}
+		if count <= 1 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Đây là phương thức đăng nhập duy nhất của bạn. Vui lòng đặt mật khẩu (qua chức năng quên mật khẩu) trước khi hủy liên kết",
+			})
+			return
+		}
+	}
+
+	rows, err := s.z.DeleteOAuthAccount(ctx, db.DeleteOAuthAccountParams{
+		NguoiDungID: claims.Id,
+		NhaCungCap:  provider,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể hủy liên kết", "details": err.Error()})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bạn chưa liên kết tài khoản " + oauthProviderLabel(provider)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Đã hủy liên kết tài khoản " + oauthProviderLabel(provider)})
+}
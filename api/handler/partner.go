@@ -0,0 +1,658 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/helpers"
+	"travia.backend/api/middleware"
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// apiKeyPrefix giúp nhận diện khóa API Travia (vd: khi bị lộ trong mã nguồn)
+	apiKeyPrefix = "trv_"
+	// apiKeyDisplayLength là số ký tự đầu của khóa được lưu để hiển thị
+	apiKeyDisplayLength     = 12
+	defaultApiKeyRateLimit  = 60
+	defaultApiKeyUsageDays  = 30
+	maxApiKeyUsageDays      = 365
+	apiKeyUsageWriteTimeout = 5 * time.Second
+	// apiKeyUsageQueueSize là số lượt gọi tối đa chờ ghi; hàng đợi đầy thì bỏ qua lượt ghi nhận
+	apiKeyUsageQueueSize = 4096
+	// ApiKeyUsageFlushInterval là chu kỳ ghi dồn lượt gọi của các khóa vào DB
+	ApiKeyUsageFlushInterval = 10 * time.Second
+)
+
+// newApiKey sinh khóa API ngẫu nhiên dạng trv_<48 hex>
+func newApiKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// authenticateApiKey dùng cho ApiKeyMiddleware: tra khóa theo mã băm SHA-256
+func (s *Server) authenticateApiKey(ctx context.Context, rawKey string) (*middleware.ApiKeyPrincipal, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil
+	}
+	key, err := s.z.GetApiKeyForAuth(ctx, utils.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &middleware.ApiKeyPrincipal{
+		KeyID:     key.ID,
+		PartnerID: key.DoiTacID,
+		UserID:    key.NguoiDungID,
+		Email:     key.Email,
+		Scopes:    key.PhamVi,
+		RateLimit: int(key.GioiHanMoiPhut),
+	}, nil
+}
+
+// recordApiKeyUsage đưa lượt gọi vào hàng đợi để StartApiKeyUsageWriter ghi dồn, không làm chậm request
+func (s *Server) recordApiKeyUsage(keyID int32) {
+	select {
+	case s.apiKeyUsage <- keyID:
+	default:
+		s.apiKeyUsageDropped.Add(1)
+	}
+}
+
+// StartApiKeyUsageWriter gom lượt gọi theo khóa và ghi vào DB mỗi ApiKeyUsageFlushInterval
+// (một goroutine duy nhất thay vì một goroutine cho mỗi request)
+func (s *Server) StartApiKeyUsageWriter(ctx context.Context) {
+	ticker := time.NewTicker(ApiKeyUsageFlushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[int32]int32)
+	for {
+		select {
+		case <-ctx.Done():
+			s.flushApiKeyUsage(pending)
+			return
+		case keyID := <-s.apiKeyUsage:
+			pending[keyID]++
+		case <-ticker.C:
+			s.flushApiKeyUsage(pending)
+			pending = make(map[int32]int32)
+		}
+	}
+}
+
+// flushApiKeyUsage ghi số lượt gọi đã gom của từng khóa
+func (s *Server) flushApiKeyUsage(pending map[int32]int32) {
+	if dropped := s.apiKeyUsageDropped.Swap(0); dropped > 0 {
+		log.Printf("[ApiKey] usage queue full, dropped %d calls", dropped)
+	}
+	for keyID, count := range pending {
+		ctx, cancel := context.WithTimeout(context.Background(), apiKeyUsageWriteTimeout)
+		if err := s.z.TouchApiKey(ctx, db.TouchApiKeyParams{ID: keyID, SoLanGoi: count}); err != nil {
+			log.Printf("[ApiKey] update usage of key %d failed: %v", keyID, err)
+		}
+		if err := s.z.IncrementApiKeyDailyUsage(ctx, db.IncrementApiKeyDailyUsageParams{KhoaApiID: keyID, SoLanGoi: count}); err != nil {
+			log.Printf("[ApiKey] update daily usage of key %d failed: %v", keyID, err)
+		}
+		cancel()
+	}
+}
+
+// apiKeyResponse ẩn mã băm của khóa
+func apiKeyResponse(k db.KhoaApi) db.GetApiKeysByPartnerRow {
+	return db.GetApiKeysByPartnerRow{
+		ID:             k.ID,
+		DoiTacID:       k.DoiTacID,
+		Ten:            k.Ten,
+		TienTo:         k.TienTo,
+		PhamVi:         k.PhamVi,
+		GioiHanMoiPhut: k.GioiHanMoiPhut,
+		SoLanGoi:       k.SoLanGoi,
+		LanDungCuoi:    k.LanDungCuoi,
+		HetHan:         k.HetHan,
+		DaThuHoiLuc:    k.DaThuHoiLuc,
+		NguoiTao:       k.NguoiTao,
+		NgayTao:        k.NgayTao,
+	}
+}
+
+func validateApiKeyScopes(scopes []string) error {
+	allowed := make(map[string]bool, len(middleware.ApiKeyScopes))
+	for _, s := range middleware.ApiKeyScopes {
+		allowed[s] = true
+	}
+	for _, s := range scopes {
+		if !allowed[s] {
+			return fmt.Errorf("phạm vi không hợp lệ: %s", s)
+		}
+	}
+	return nil
+}
+
+func parsePartnerID(c *gin.Context) (int32, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partner ID"})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func parseApiKeyID(c *gin.Context) (int32, bool) {
+	id, err := strconv.Atoi(c.Param("key_id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// CreatePartner godoc
+// @Summary Create partner
+// @Description Tạo đối tác (đại lý, đơn vị bán lại) kèm tài khoản dịch vụ đứng tên các booking tạo qua khóa API
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.CreatePartnerRequest true "Thông tin đối tác"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/partners [post]
+func (s *Server) CreatePartner(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, ok := getAdminClaims(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	var tiLeHoaHong pgtype.Numeric
+	if err := tiLeHoaHong.Scan(fmt.Sprintf("%.2f", req.TiLeHoaHong)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tỉ lệ hoa hồng không hợp lệ"})
+		return
+	}
+
+	// Tài khoản dịch vụ không đăng nhập được bằng mật khẩu, chỉ dùng qua khóa API
+	suffix, err := newInvitationCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account", "details": err.Error()})
+		return
+	}
+	passwordHash, err := utils.HashPassword(suffix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account", "details": err.Error()})
+		return
+	}
+
+	partner, err := s.z.CreatePartnerWithAccount(ctx, db.CreateUserParams{
+		HoTen:        req.Ten,
+		Email:        fmt.Sprintf("doi-tac-%s@partner.travia.local", suffix[:16]),
+		MatKhauMaHoa: passwordHash,
+		VaiTro:       db.NullVaiTroNguoiDung{VaiTroNguoiDung: db.VaiTroNguoiDungKhachHang, Valid: true},
+		DangHoatDong: helpers.NewBool(true),
+		XacThuc:      helpers.NewBool(true),
+	}, db.CreatePartnerParams{
+		Ten:         req.Ten,
+		EmailLienHe: req.EmailLienHe,
+		SoDienThoai: req.SoDienThoai,
+		TiLeHoaHong: tiLeHoaHong,
+		NguoiTao:    claims.Id,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create partner", "details": err.Error()})
+		return
+	}
+
+	s.recordAudit(c, AuditPartnerCreate, AuditTargetPartner, strconv.Itoa(int(partner.ID)), nil, partner)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Đã tạo đối tác",
+		"data":    partner,
+	})
+}
+
+// GetPartners godoc
+// @Summary List partners
+// @Description Danh sách đối tác và số khóa API còn hiệu lực
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Số bản ghi" default(20)
+// @Param offset query int false "Vị trí bắt đầu" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/partners [get]
+func (s *Server) GetPartners(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	partners, err := s.z.ListPartners(ctx, db.ListPartnersParams{Limit: int32(limit), Offset: int32(offset)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list partners", "details": err.Error()})
+		return
+	}
+	total, err := s.z.CountPartners(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count partners", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     partners,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": offset+len(partners) < int(total),
+	})
+}
+
+// GetPartner godoc
+// @Summary Get partner
+// @Description Chi tiết đối tác kèm danh sách khóa API (không bao gồm khóa gốc)
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Partner ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/partners/{id} [get]
+func (s *Server) GetPartner(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	partnerID, ok := parsePartnerID(c)
+	if !ok {
+		return
+	}
+
+	partner, err := s.z.GetPartnerByID(ctx, partnerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy đối tác"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get partner", "details": err.Error()})
+		return
+	}
+	keys, err := s.z.GetApiKeysByPartner(ctx, partnerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get API keys", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     partner,
+		"khoa_api": keys,
+	})
+}
+
+// UpdatePartner godoc
+// @Summary Update partner
+// @Description Cập nhật thông tin, tỉ lệ hoa hồng hoặc tạm ngưng đối tác. Đối tác bị tạm ngưng thì mọi khóa API bị từ chối ngay
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Partner ID"
+// @Param request body models.UpdatePartnerRequest true "Thông tin cập nhật"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/partners/{id} [put]
+func (s *Server) UpdatePartner(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	partnerID, ok := parsePartnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	before, err := s.z.GetPartnerByID(ctx, partnerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy đối tác"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get partner", "details": err.Error()})
+		return
+	}
+
+	arg := db.UpdatePartnerParams{
+		ID:           partnerID,
+		Ten:          req.Ten,
+		EmailLienHe:  req.EmailLienHe,
+		SoDienThoai:  req.SoDienThoai,
+		DangHoatDong: req.DangHoatDong,
+	}
+	if req.TiLeHoaHong != nil {
+		if err := arg.TiLeHoaHong.Scan(fmt.Sprintf("%.2f", *req.TiLeHoaHong)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tỉ lệ hoa hồng không hợp lệ"})
+			return
+		}
+	}
+
+	partner, err := s.z.UpdatePartner(ctx, arg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update partner", "details": err.Error()})
+		return
+	}
+
+	s.recordAudit(c, AuditPartnerUpdate, AuditTargetPartner, strconv.Itoa(int(partnerID)), before, partner)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã cập nhật đối tác",
+		"data":    partner,
+	})
+}
+
+// CreatePartnerApiKey godoc
+// @Summary Issue partner API key
+// @Description Cấp khóa API cho đối tác với phạm vi và giới hạn request mỗi phút. Khóa gốc chỉ trả về một lần, hệ thống chỉ lưu mã băm
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Partner ID"
+// @Param request body models.CreateApiKeyRequest true "Thông tin khóa"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/partners/{id}/api-keys [post]
+func (s *Server) CreatePartnerApiKey(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, ok := getAdminClaims(c)
+	if !ok {
+		return
+	}
+	partnerID, ok := parsePartnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateApiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+	if err := validateApiKeyScopes(req.PhamVi); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "pham_vi_hop_le": middleware.ApiKeyScopes})
+		return
+	}
+	rateLimit := req.GioiHanMoiPhut
+	if rateLimit == 0 {
+		rateLimit = defaultApiKeyRateLimit
+	}
+	var hetHan pgtype.Timestamp
+	if req.HetHan != "" {
+		t, err := time.Parse("2006-01-02", req.HetHan)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "het_han phải có dạng YYYY-MM-DD"})
+			return
+		}
+		// Khóa còn hiệu lực hết ngày het_han
+		t = t.Add(24 * time.Hour)
+		if !t.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "het_han phải là ngày trong tương lai"})
+			return
+		}
+		hetHan = pgtype.Timestamp{Time: t, Valid: true}
+	}
+
+	if _, err := s.z.GetPartnerByID(ctx, partnerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy đối tác"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get partner", "details": err.Error()})
+		return
+	}
+
+	rawKey, err := newApiKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key", "details": err.Error()})
+		return
+	}
+	key, err := s.z.CreateApiKey(ctx, db.CreateApiKeyParams{
+		DoiTacID:       partnerID,
+		Ten:            req.Ten,
+		TienTo:         rawKey[:apiKeyDisplayLength],
+		MaBam:          utils.HashToken(rawKey),
+		PhamVi:         req.PhamVi,
+		GioiHanMoiPhut: rateLimit,
+		HetHan:         hetHan,
+		NguoiTao:       claims.Id,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key", "details": err.Error()})
+		return
+	}
+
+	s.recordAudit(c, AuditApiKeyCreate, AuditTargetApiKey, strconv.Itoa(int(key.ID)), nil, key)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Đã cấp khóa API. Hãy lưu lại khóa, hệ thống sẽ không hiển thị lại",
+		"khoa_api": rawKey,
+		"data":     apiKeyResponse(key),
+	})
+}
+
+// RevokePartnerApiKey godoc
+// @Summary Revoke partner API key
+// @Description Thu hồi khóa API, các request dùng khóa bị từ chối ngay
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Partner ID"
+// @Param key_id path int true "API key ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/partners/{id}/api-keys/{key_id} [delete]
+func (s *Server) RevokePartnerApiKey(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	partnerID, ok := parsePartnerID(c)
+	if !ok {
+		return
+	}
+	keyID, ok := parseApiKeyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := s.z.RevokeApiKey(ctx, db.RevokeApiKeyParams{ID: keyID, DoiTacID: partnerID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy khóa API còn hiệu lực"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key", "details": err.Error()})
+		return
+	}
+
+	s.recordAudit(c, AuditApiKeyRevoke, AuditTargetApiKey, strconv.Itoa(int(keyID)), nil, key)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã thu hồi khóa API",
+		"data":    key,
+	})
+}
+
+// GetPartnerApiKeyUsage godoc
+// @Summary Get API key usage
+// @Description Số lượt gọi theo ngày của một khóa API
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Partner ID"
+// @Param key_id path int true "API key ID"
+// @Param days query int false "Số ngày gần nhất (tối đa 365)" default(30)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/partners/{id}/api-keys/{key_id}/usage [get]
+func (s *Server) GetPartnerApiKeyUsage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	partnerID, ok := parsePartnerID(c)
+	if !ok {
+		return
+	}
+	keyID, ok := parseApiKeyID(c)
+	if !ok {
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultApiKeyUsageDays)))
+	if days <= 0 {
+		days = defaultApiKeyUsageDays
+	}
+	if days > maxApiKeyUsageDays {
+		days = maxApiKeyUsageDays
+	}
+
+	from := time.Now().AddDate(0, 0, -days+1)
+	usage, err := s.z.GetApiKeyDailyUsage(ctx, db.GetApiKeyDailyUsageParams{
+		KhoaApiID: keyID,
+		DoiTacID:  partnerID,
+		TuNgay:    pgtype.Date{Time: from, Valid: true},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get API key usage", "details": err.Error()})
+		return
+	}
+
+	var total int64
+	for _, u := range usage {
+		total += u.SoLanGoi
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":     usage,
+		"so_ngay":  days,
+		"tong_goi": total,
+	})
+}
+
+// GetPartnerCommissions godoc
+// @Summary Partner commission report
+// @Description Doanh thu và hoa hồng của đối tác từ các booking tạo qua khóa API đã thanh toán / hoàn thành. Mặc định là tháng hiện tại
+// @Tags Admin
+// @Produce json
+// @Param start_date query string false "Từ ngày (YYYY-MM-DD)"
+// @Param end_date query string false "Đến ngày (YYYY-MM-DD)"
+// @Param doi_tac_id query int false "Lọc theo đối tác"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/partners/commissions [get]
+func (s *Server) GetPartnerCommissions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0)
+	if v := c.Query("start_date"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date phải có dạng YYYY-MM-DD"})
+			return
+		}
+		start = t
+	}
+	if v := c.Query("end_date"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_date phải có dạng YYYY-MM-DD"})
+			return
+		}
+		// Bao gồm cả ngày end_date
+		end = t.Add(24 * time.Hour)
+	}
+	if !end.After(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date phải sau start_date"})
+		return
+	}
+
+	arg := db.GetPartnerCommissionReportParams{
+		TuNgay:  pgtype.Timestamp{Time: start, Valid: true},
+		DenNgay: pgtype.Timestamp{Time: end, Valid: true},
+	}
+	if v := c.Query("doi_tac_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partner ID"})
+			return
+		}
+		partnerID := int32(id)
+		arg.DoiTacID = &partnerID
+	}
+
+	report, err := s.z.GetPartnerCommissionReport(ctx, arg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get commission report", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     report,
+		"tu_ngay":  start.Format("2006-01-02"),
+		"den_ngay": end.Add(-24 * time.Hour).Format("2006-01-02"),
+	})
+}
@@ -1,42 +1,71 @@
 package handler
 
 import (
-	"crypto/hmac"
-	"crypto/sha512"
-	"encoding/hex"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"sort"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"travia.backend/api/services"
 	"travia.backend/api/utils"
 	db "travia.backend/db/sqlc"
 )
 
 const (
-	// VNPayPaymentExpirationTime là thời gian hết hạn của payment URL VNPay (30 phút)
-	// VNPay cho phép tối đa 30 phút cho giao dịch thanh toán
-	VNPayPaymentExpirationTime = 30 * time.Minute
+	// PaymentReuseWindow là thời gian tái sử dụng mã giao dịch đang chờ thanh toán của cùng cổng
+	PaymentReuseWindow = 15 * time.Minute
 )
 
-// CreateVNPayPaymentURL tạo URL thanh toán VNPay
-// @Summary Tạo URL thanh toán VNPay
-// @Description Tạo URL thanh toán VNPay cho booking
+type CreateGatewayPaymentRequest struct {
+	BookingID int32  `json:"booking_id" binding:"required"`
+	ReturnURL string `json:"return_url"`
+}
+
+// SetupPaymentGateways đăng ký các cổng thanh toán theo cong_thanh_toan.id
+func (s *Server) SetupPaymentGateways() {
+	s.gateways = services.NewPaymentGatewayRegistry()
+	s.gateways.Register(services.NewVNPayGateway(s.config.VNPayConfig))
+	s.gateways.Register(services.NewMoMoGateway(s.config.MoMoConfig))
+}
+
+// paymentGateway lấy cổng thanh toán từ path param :gateway
+func (s *Server) paymentGateway(c *gin.Context) (services.PaymentGateway, bool) {
+	gateway, ok := s.gateways.Get(c.Param("gateway"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":              "Cổng thanh toán không được hỗ trợ",
+			"supported_gateways": s.gateways.IDs(),
+		})
+		return nil, false
+	}
+	return gateway, true
+}
+
+// CreatePayment tạo thanh toán qua cổng thanh toán
+// @Summary Tạo thanh toán
+// @Description Tạo URL thanh toán cho booking qua cổng thanh toán (vnpay, momo, ...)
 // @Tags Payment
 // @Accept json
 // @Produce json
-// @Param request body CreateVNPayPaymentRequest true "Payment Request"
+// @Param gateway path string true "Cổng thanh toán (cong_thanh_toan.id)"
+// @Param request body CreateGatewayPaymentRequest true "Payment Request"
 // @Security ApiKeyAuth
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
 // @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
 // @Failure 500 {object} gin.H
-// @Router /payment/vnpay/create [post]
-func (s *Server) CreateVNPayPaymentURL(c *gin.Context) {
+// @Router /payment/{gateway}/create [post]
+func (s *Server) CreatePayment(c *gin.Context) {
+	gateway, ok := s.paymentGateway(c)
+	if !ok {
+		return
+	}
+
 	// Xác thực user
 	claims, ok := c.Get("claims")
 	if !ok {
@@ -49,120 +78,81 @@ func (s *Server) CreateVNPayPaymentURL(c *gin.Context) {
 		return
 	}
 
-	var req CreateVNPayPaymentRequest
+	var req CreateGatewayPaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Log request để debug
-	fmt.Printf("=== CreateVNPayPaymentURL Request ===\n")
-	fmt.Printf("Booking ID: %d\n", req.BookingID)
-	fmt.Printf("Return URL from request: %s\n", req.ReturnURL)
-	fmt.Printf("=====================================\n")
-
 	// Validate và fix returnURL nếu là "string"
-	if req.ReturnURL == "string" || req.ReturnURL == "" {
-		fmt.Printf("⚠️  Return URL is empty or 'string', using config default\n")
+	if req.ReturnURL == "string" {
 		req.ReturnURL = "" // Sẽ dùng config default
 	}
 
-	// Kiểm tra booking tồn tại và thuộc về user
-	booking, err := s.z.GetBookingById(c.Request.Context(), req.BookingID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
-		return
-	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
 
-	// Kiểm tra booking thuộc về user
-	if booking.NguoiDungID.String() != claimsMap.Id.String() {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	booking, ok := s.getPayableBooking(c, ctx, req.BookingID, claimsMap)
+	if !ok {
 		return
 	}
 
-	// Kiểm tra booking chưa thanh toán
-	if booking.TrangThai.Valid && (booking.TrangThai.TrangThaiDatCho == "da_thanh_toan" || booking.TrangThai.TrangThaiDatCho == "hoan_thanh") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking already paid"})
+	// Validate TongTien
+	tongTien, err := booking.TongTien.Float64Value()
+	if err != nil || !tongTien.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking không có số tiền hợp lệ"})
 		return
 	}
-
-	// Kiểm tra booking status hợp lệ (chỉ cho phép thanh toán khi ở trạng thái chờ xác nhận hoặc đã xác nhận)
-	if booking.TrangThai.Valid && booking.TrangThai.TrangThaiDatCho != "cho_xac_nhan" && booking.TrangThai.TrangThaiDatCho != "da_xac_nhan" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "Booking không ở trạng thái hợp lệ để thanh toán",
-			"trang_thai": booking.TrangThai.TrangThaiDatCho,
-		})
-		return
+	currency := "VND"
+	if booking.DonViTienTe != nil && *booking.DonViTienTe != "" {
+		currency = *booking.DonViTienTe
 	}
-
-	// Get client IP for vnp_IpAddr
-	clientIP := c.ClientIP()
-	if clientIP == "" || clientIP == "::1" {
-		clientIP = "127.0.0.1"
+	paymentReq := services.CreatePaymentRequest{
+		BookingID: booking.ID,
+		Amount:    tongTien.Float64,
+		Currency:  currency,
+		ReturnURL: req.ReturnURL,
+		ClientIP:  c.ClientIP(),
 	}
 
-	// Lấy múi giờ VN để so sánh thời gian chính xác
-	location, _ := time.LoadLocation("Asia/Ho_Chi_Minh")
-	now := time.Now().In(location)
-
-	existingTransactions, err := s.z.GetTransactionsByBooking(c.Request.Context(), &booking.ID)
-	if err == nil && len(existingTransactions) > 0 {
+	// REUSE: Giao dịch cùng cổng đang chờ và còn hạn thì tạo yêu cầu mới với mã cũ
+	existingTransactions, err := s.z.GetTransactionsByBooking(ctx, &booking.ID)
+	if err == nil {
 		for _, tx := range existingTransactions {
-			if tx.TrangThai.Valid && tx.TrangThai.TrangThaiThanhToan == "dang_cho_thanh_toan" {
-				if tx.NgayTao.Valid {
-					// VNPayPaymentExpirationTime nên là 15 * time.Minute
-					if now.Before(tx.NgayTao.Time.In(location).Add(15 * time.Minute)) {
-						// REUSE: Nếu còn hạn, tạo URL mới với mã cũ nhưng Time mới
-						paymentURL, err := s.createVNPayPaymentURL(booking, tx.MaGiaoDichNoiBo, req.ReturnURL, clientIP)
-						if err == nil {
-							c.JSON(http.StatusOK, gin.H{"payment_url": paymentURL, "transaction_code": tx.MaGiaoDichNoiBo})
-							return
-						}
-					}
-				}
+			if tx.CongThanhToanID == nil || *tx.CongThanhToanID != gateway.ID() {
+				continue
+			}
+			if !tx.TrangThai.Valid || tx.TrangThai.TrangThaiThanhToan != "dang_cho_thanh_toan" {
+				continue
+			}
+			if !tx.NgayTao.Valid || time.Since(tx.NgayTao.Time) > PaymentReuseWindow {
+				continue
+			}
+			paymentReq.TransactionCode = tx.MaGiaoDichNoiBo
+			result, err := gateway.CreatePayment(ctx, paymentReq)
+			if err == nil {
+				c.JSON(http.StatusOK, paymentResponse(result, tx.MaGiaoDichNoiBo, booking.ID))
+				return
 			}
 		}
 	}
 
-	// CREATE NEW: Tạo mã mới hoàn toàn để tránh xung đột "Giao dịch cũ" trên VNPay
-	transactionCode := fmt.Sprintf("TRAVIA%d%d", booking.ID, now.Unix())
-	paymentURL, err := s.createVNPayPaymentURL(booking, transactionCode, req.ReturnURL, clientIP)
+	// CREATE NEW: Tạo mã mới hoàn toàn để tránh xung đột "Giao dịch cũ" trên cổng thanh toán
+	transactionCode := fmt.Sprintf("TRAVIA%d%d", booking.ID, time.Now().Unix())
+	paymentReq.TransactionCode = transactionCode
+	result, err := gateway.CreatePayment(ctx, paymentReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError,
-			gin.H{
-				"error":   "Không thể tạo liên kết thanh toán",
-				"message": err.Error(),
-			})
-		return
-	}
-
-	// Validate TongTien
-	if !booking.TongTien.Valid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking không có số tiền hợp lệ"})
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Không thể tạo liên kết thanh toán",
+			"message": err.Error(),
+		})
 		return
 	}
 
-	// Lưu vào DB (Giữ nguyên logic CreateTransaction cũ của bạn)
-	congThanhToanID := "vnpay"
-	noiDungChuyenKhoan := fmt.Sprintf("Thanh toan don dat cho #%d qua VNPay", booking.ID)
+	congThanhToanID := gateway.ID()
+	noiDungChuyenKhoan := fmt.Sprintf("Thanh toan don dat cho #%d qua %s", booking.ID, congThanhToanID)
 	bookingID := booking.ID
-
-	// Log thông tin để debug
-	fmt.Printf("=== Creating Transaction ===\n")
-	fmt.Printf("Booking ID: %d\n", bookingID)
-	fmt.Printf("User ID: %s\n", booking.NguoiDungID.String())
-	fmt.Printf("Transaction Code: %s\n", transactionCode)
-	fmt.Printf("Payment Gateway ID: %s\n", congThanhToanID)
-	fmt.Printf("TongTien Valid: %v\n", booking.TongTien.Valid)
-	if booking.TongTien.Valid {
-		floatVal, _ := booking.TongTien.Float64Value()
-		if floatVal.Valid {
-			fmt.Printf("TongTien Value: %.2f\n", floatVal.Float64)
-		}
-	}
-	fmt.Printf("===========================\n")
-
-	_, err = s.z.CreateTransaction(c.Request.Context(), db.CreateTransactionParams{
+	transaction, err := s.z.CreateTransaction(ctx, db.CreateTransactionParams{
 		DatChoID:           &bookingID,
 		NguoiDungID:        booking.NguoiDungID,
 		MaGiaoDichNoiBo:    transactionCode,
@@ -171,8 +161,7 @@ func (s *Server) CreateVNPayPaymentURL(c *gin.Context) {
 		NoiDungChuyenKhoan: &noiDungChuyenKhoan,
 	})
 	if err != nil {
-		fmt.Printf("ERROR: Failed to create transaction: %v\n", err)
-		fmt.Printf("ERROR Details - Booking ID: %d, Gateway ID: %s, Transaction Code: %s\n", bookingID, congThanhToanID, transactionCode)
+		fmt.Printf("ERROR: Failed to create transaction - Booking ID: %d, Gateway ID: %s, Transaction Code: %s, Error: %v\n", bookingID, congThanhToanID, transactionCode, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Không thể tạo giao dịch thanh toán",
 			"details": err.Error(),
@@ -180,198 +169,218 @@ func (s *Server) CreateVNPayPaymentURL(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"payment_url":      paymentURL,
+	// Lưu mã tham chiếu phía cổng nếu cổng trả về ngay khi tạo
+	if result.GatewayRef != "" {
+		_, err = s.z.SetTransactionGatewayReference(ctx, db.SetTransactionGatewayReferenceParams{
+			ID:          transaction.ID,
+			MaThamChieu: result.GatewayRef,
+		})
+		if err != nil {
+			fmt.Printf("ERROR: Failed to save gateway reference - Transaction ID: %d, Error: %v\n", transaction.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, paymentResponse(result, transactionCode, booking.ID))
+}
+
+// getPayableBooking lấy booking của user và kiểm tra booking còn có thể thanh toán
+func (s *Server) getPayableBooking(c *gin.Context, ctx context.Context, bookingID int32, claims *utils.JwtClams) (db.GetBookingByIdRow, bool) {
+	// Kiểm tra booking tồn tại và thuộc về user
+	booking, err := s.z.GetBookingById(ctx, bookingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return booking, false
+	}
+	if booking.NguoiDungID.String() != claims.Id.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return booking, false
+	}
+
+	// Kiểm tra booking chưa thanh toán
+	if booking.TrangThai.Valid && (booking.TrangThai.TrangThaiDatCho == "da_thanh_toan" || booking.TrangThai.TrangThaiDatCho == "hoan_thanh") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking already paid"})
+		return booking, false
+	}
+
+	// Kiểm tra booking status hợp lệ (chỉ cho phép thanh toán khi ở trạng thái chờ xác nhận hoặc đã xác nhận)
+	if booking.TrangThai.Valid && booking.TrangThai.TrangThaiDatCho != "cho_xac_nhan" && booking.TrangThai.TrangThaiDatCho != "da_xac_nhan" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Booking không ở trạng thái hợp lệ để thanh toán",
+			"trang_thai": booking.TrangThai.TrangThaiDatCho,
+		})
+		return booking, false
+	}
+	return booking, true
+}
+
+// paymentResponse gộp kết quả tạo thanh toán và dữ liệu riêng của cổng
+func paymentResponse(result *services.CreatePaymentResult, transactionCode string, bookingID int32) gin.H {
+	resp := gin.H{
+		"payment_url":      result.PaymentURL,
 		"transaction_code": transactionCode,
-		"booking_id":       booking.ID,
-	})
+		"booking_id":       bookingID,
+	}
+	for k, v := range result.Extra {
+		resp[k] = v
+	}
+	return resp
 }
 
-// VNPayCallback xử lý callback từ VNPay (Return URL)
-// @Summary VNPay Return Callback
-// @Description Xử lý callback khi user quay lại từ VNPay
-// @Tags Payment
-// @Accept json
-// @Produce json
-// @Param vnp_Amount query string true "Amount"
-// @Param vnp_BankCode query string false "Bank Code"
-// @Param vnp_BankTranNo query string false "Bank Transaction No"
-// @Param vnp_CardType query string false "Card Type"
-// @Param vnp_OrderInfo query string true "Order Info"
-// @Param vnp_PayDate query string true "Pay Date"
-// @Param vnp_ResponseCode query string true "Response Code"
-// @Param vnp_TmnCode query string true "TMN Code"
-// @Param vnp_TransactionNo query string true "Transaction No"
-// @Param vnp_TransactionStatus query string true "Transaction Status"
-// @Param vnp_TxnRef query string true "Transaction Ref"
-// @Param vnp_SecureHash query string true "Secure Hash"
-// @Success 200 {object} gin.H
-// @Failure 400 {object} gin.H
-// @Router /payment/vnpay/return [get]
-func (s *Server) VNPayCallback(c *gin.Context) {
-	// Log để debug
-	fmt.Printf("=== VNPay Return URL Callback ===\n")
-	fmt.Printf("Request URL: %s\n", c.Request.URL.String())
-	fmt.Printf("Query Params: %v\n", c.Request.URL.Query())
-	fmt.Printf("Client IP: %s\n", c.ClientIP())
-	fmt.Printf("User Agent: %s\n", c.Request.UserAgent())
-	fmt.Printf("================================\n")
-
-	// Lấy tất cả query parameters
-	queryParams := c.Request.URL.Query()
-
-	// Verify signature
-	if !s.verifyVNPaySignature(queryParams) {
-		fmt.Printf("ERROR: Invalid signature in Return URL callback\n")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature"})
-		return
+// applyPaymentResult áp dụng kết quả thanh toán đã xác thực chữ ký vào giao dịch và booking.
+// Dùng chung cho return URL, verify và IPN của mọi cổng; gọi lại nhiều lần không cập nhật lại.
+func (s *Server) applyPaymentResult(ctx context.Context, gatewayID string, result *services.PaymentResult) (services.PaymentOutcome, int32) {
+	transaction, err := s.z.GetTransactionByCode(ctx, result.TransactionCode)
+	if err != nil || transaction.CongThanhToanID == nil || *transaction.CongThanhToanID != gatewayID {
+		return services.OutcomeTransactionNotFound, 0
+	}
+	var bookingID int32
+	if transaction.DatChoID != nil {
+		bookingID = *transaction.DatChoID
 	}
-	fmt.Printf("Signature verified successfully\n")
 
-	// Parse response code
-	responseCode := queryParams.Get("vnp_ResponseCode")
-	transactionStatus := queryParams.Get("vnp_TransactionStatus")
-	txnRef := queryParams.Get("vnp_TxnRef")
-	transactionNo := queryParams.Get("vnp_TransactionNo")
+	// Kiểm tra nếu đã xử lý rồi
+	if transaction.TrangThai.Valid && transaction.TrangThai.TrangThaiThanhToan == "thanh_cong" {
+		return services.OutcomeAlreadyConfirmed, bookingID
+	}
 
-	// Extract booking ID from transaction code (format: TRAVIA{bookingID}{timestamp})
-	// Tìm booking ID từ transaction code
-	transaction, err := s.z.GetTransactionByCode(c.Request.Context(), txnRef)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
-		return
+	// Kiểm tra số tiền
+	soTien, err := transaction.SoTien.Float64Value()
+	if err != nil || !soTien.Valid || int64(soTien.Float64) != int64(result.Amount) {
+		fmt.Printf("ERROR: Amount mismatch - Transaction: %s, Expected: %.2f, Got: %.2f\n", result.TransactionCode, soTien.Float64, result.Amount)
+		return services.OutcomeInvalidAmount, bookingID
+	}
+
+	var gatewayRef *string
+	if result.GatewayRef != "" {
+		gatewayRef = &result.GatewayRef
+	}
+
+	if !result.Success {
+		fmt.Printf("Payment FAILED - Gateway: %s, Transaction: %s, ResponseCode: %s\n", gatewayID, result.TransactionCode, result.ResponseCode)
+		_, err = s.z.FailPendingTransaction(ctx, db.FailPendingTransactionParams{
+			ID:          transaction.ID,
+			MaThamChieu: gatewayRef,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			fmt.Printf("ERROR: Failed to update transaction status to failed - ID: %d, Error: %v\n", transaction.ID, err)
+		}
+		return services.OutcomePaymentFailed, bookingID
 	}
 
 	if transaction.DatChoID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction"})
-		return
+		return services.OutcomeInvalidTransaction, bookingID
 	}
-	bookingID := int(*transaction.DatChoID)
 
 	// Kiểm tra booking tồn tại và ở trạng thái hợp lệ
-	booking, err := s.z.GetBookingById(c.Request.Context(), int32(bookingID))
+	booking, err := s.z.GetBookingById(ctx, bookingID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
-		return
+		return services.OutcomeBookingNotFound, bookingID
 	}
-
-	// Kiểm tra booking status hợp lệ
 	if !booking.TrangThai.Valid || (booking.TrangThai.TrangThaiDatCho != "cho_xac_nhan" && booking.TrangThai.TrangThaiDatCho != "da_xac_nhan") {
-		returnURL := s.config.VNPayConfig.ReturnURL
-		c.Redirect(http.StatusFound, fmt.Sprintf("%s?status=failed&booking_id=%d&transaction_code=%s&error=invalid_booking_status", returnURL, bookingID, txnRef))
-		return
+		fmt.Printf("ERROR: Invalid booking status - ID: %d, Status: %v\n", bookingID, booking.TrangThai)
+		return services.OutcomeInvalidBookingStatus, bookingID
 	}
 
-	// Kiểm tra response code
-	if responseCode == "00" && transactionStatus == "00" {
-		fmt.Printf("Payment SUCCESS - ResponseCode: %s, TransactionStatus: %s\n", responseCode, transactionStatus)
-		// Thanh toán thành công
-		// Cập nhật transaction
-		transaction, err = s.z.GetTransactionByCode(c.Request.Context(), txnRef)
-		if err == nil {
-			trangThai := db.NullTrangThaiThanhToan{
-				TrangThaiThanhToan: "thanh_cong",
-				Valid:              true,
-			}
-			_, err = s.z.UpdateTransactionStatus(c.Request.Context(), db.UpdateTransactionStatusParams{
-				ID:          transaction.ID,
-				TrangThai:   trangThai,
-				MaThamChieu: &transactionNo,
-			})
-			if err != nil {
-				// Log error nhưng vẫn tiếp tục cập nhật booking
-				fmt.Printf("ERROR: Failed to update transaction status: %v\n", err)
-			} else {
-				fmt.Printf("Transaction status updated successfully - ID: %d\n", transaction.ID)
-			}
-		}
+	// Cập nhật giao dịch và booking trong cùng một câu lệnh
+	confirmed, err := s.z.ConfirmTransactionPayment(ctx, db.ConfirmTransactionPaymentParams{
+		ID:                  transaction.ID,
+		MaThamChieu:         gatewayRef,
+		PhuongThucThanhToan: gatewayID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return services.OutcomeAlreadyConfirmed, bookingID
+	}
+	if err != nil {
+		fmt.Printf("ERROR: Failed to confirm transaction - ID: %d, Error: %v\n", transaction.ID, err)
+		return services.OutcomeUpdateFailed, bookingID
+	}
+	if !confirmed.DaCapNhatDatCho {
+		fmt.Printf("WARNING: Booking status not updated correctly - Booking ID: %d, Transaction: %s\n", bookingID, result.TransactionCode)
+	}
 
-		// Cập nhật booking status
-		phuongThuc := "vnpay"
-		updatedBooking, err := s.z.UpdateBookingPaymentStatus(c.Request.Context(), db.UpdateBookingPaymentStatusParams{
-			ID:                  int32(bookingID),
-			PhuongThucThanhToan: &phuongThuc,
-		})
-		if err != nil {
-			// Log error
-			fmt.Printf("ERROR: Failed to update booking payment status: %v\n", err)
-			returnURL := s.config.VNPayConfig.ReturnURL
-			c.Redirect(http.StatusFound, fmt.Sprintf("%s?status=failed&booking_id=%d&transaction_code=%s&error=update_failed", returnURL, bookingID, txnRef))
-			return
-		}
-		fmt.Printf("Booking payment status updated successfully - Booking ID: %d\n", bookingID)
+	fmt.Printf("Payment SUCCESS - Gateway: %s, Transaction: %s, Booking ID: %d\n", gatewayID, result.TransactionCode, bookingID)
+	return services.OutcomeSuccess, bookingID
+}
 
-		// Kiểm tra xem booking đã được cập nhật thành công chưa
-		if !updatedBooking.TrangThai.Valid || updatedBooking.TrangThai.TrangThaiDatCho != "da_thanh_toan" {
-			fmt.Printf("WARNING: Booking status not updated correctly. Booking ID: %d, Status: %v\n", bookingID, updatedBooking.TrangThai)
-		}
+// outcomeFromParseError phân loại lỗi khi đọc callback/IPN
+func outcomeFromParseError(err error) services.PaymentOutcome {
+	switch {
+	case errors.Is(err, services.ErrInvalidSignature):
+		return services.OutcomeInvalidSignature
+	case errors.Is(err, services.ErrInvalidAmount):
+		return services.OutcomeInvalidAmount
+	default:
+		return services.OutcomeInvalidRequest
+	}
+}
 
-		// Redirect về frontend với success
-		returnURL := s.config.VNPayConfig.ReturnURL
-		fmt.Printf("Redirecting to frontend: %s?status=success&booking_id=%d&transaction_code=%s\n", returnURL, bookingID, txnRef)
-		c.Redirect(http.StatusFound, fmt.Sprintf("%s?status=success&booking_id=%d&transaction_code=%s", returnURL, bookingID, txnRef))
+// PaymentReturn xử lý callback khi khách hàng quay lại từ cổng thanh toán (Return URL)
+// @Summary Payment Return Callback
+// @Description Xác thực callback từ cổng thanh toán, cập nhật giao dịch và chuyển hướng về frontend
+// @Tags Payment
+// @Produce json
+// @Param gateway path string true "Cổng thanh toán (cong_thanh_toan.id)"
+// @Success 302 {string} string "Redirect to frontend"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /payment/{gateway}/return [get]
+func (s *Server) PaymentReturn(c *gin.Context) {
+	gateway, ok := s.paymentGateway(c)
+	if !ok {
 		return
 	}
 
-	// Thanh toán thất bại
-	fmt.Printf("Payment FAILED - ResponseCode: %s, TransactionStatus: %s\n", responseCode, transactionStatus)
-	transaction, err = s.z.GetTransactionByCode(c.Request.Context(), txnRef)
-	if err == nil {
-		trangThai := db.NullTrangThaiThanhToan{
-			TrangThaiThanhToan: "that_bai",
-			Valid:              true,
-		}
-		_, err = s.z.UpdateTransactionStatus(c.Request.Context(), db.UpdateTransactionStatusParams{
-			ID:        transaction.ID,
-			TrangThai: trangThai,
-		})
-		if err != nil {
-			fmt.Printf("ERROR: Failed to update transaction status to failed: %v\n", err)
-		} else {
-			fmt.Printf("Transaction status updated to failed - ID: %d\n", transaction.ID)
-		}
+	result, err := gateway.VerifyCallback(c.Request)
+	if err != nil {
+		fmt.Printf("ERROR: Invalid %s return callback: %v\n", gateway.ID(), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature"})
+		return
 	}
 
-	returnURL := s.config.VNPayConfig.ReturnURL
-	fmt.Printf("Redirecting to frontend: %s?status=failed&booking_id=%d&transaction_code=%s&error_code=%s\n", returnURL, bookingID, txnRef, responseCode)
-	c.Redirect(http.StatusFound, fmt.Sprintf("%s?status=failed&booking_id=%d&transaction_code=%s&error_code=%s", returnURL, bookingID, txnRef, responseCode))
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	outcome, bookingID := s.applyPaymentResult(ctx, gateway.ID(), result)
+	if outcome == services.OutcomeTransactionNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	query := url.Values{}
+	query.Set("booking_id", fmt.Sprint(bookingID))
+	query.Set("transaction_code", result.TransactionCode)
+	switch outcome {
+	case services.OutcomeSuccess, services.OutcomeAlreadyConfirmed:
+		query.Set("status", "success")
+	case services.OutcomePaymentFailed:
+		query.Set("status", "failed")
+		query.Set("error_code", result.ResponseCode)
+	default:
+		query.Set("status", "failed")
+		query.Set("error", string(outcome))
+	}
+	c.Redirect(http.StatusFound, fmt.Sprintf("%s?%s", gateway.ReturnURL(), query.Encode()))
 }
 
-// VNPayVerifyCallback xử lý callback từ frontend (khi VNPay redirect về frontend)
-// Frontend sẽ gọi endpoint này với VNPay params để verify và xử lý
-// @Summary VNPay Verify Callback
-// @Description Frontend gọi endpoint này với VNPay params để verify và xử lý transaction
+// PaymentVerify xử lý callback từ frontend (khi cổng thanh toán redirect về frontend)
+// Frontend sẽ gọi endpoint này với params nhận từ cổng để verify và xử lý
+// @Summary Payment Verify Callback
+// @Description Frontend gọi endpoint này với params từ cổng thanh toán để xác thực và xử lý giao dịch
 // @Tags Payment
-// @Accept json
 // @Produce json
-// @Param vnp_Amount query string true "Amount"
-// @Param vnp_BankCode query string false "Bank Code"
-// @Param vnp_BankTranNo query string false "Bank Transaction No"
-// @Param vnp_CardType query string false "Card Type"
-// @Param vnp_OrderInfo query string true "Order Info"
-// @Param vnp_PayDate query string true "Pay Date"
-// @Param vnp_ResponseCode query string true "Response Code"
-// @Param vnp_TmnCode query string true "TMN Code"
-// @Param vnp_TransactionNo query string true "Transaction No"
-// @Param vnp_TransactionStatus query string true "Transaction Status"
-// @Param vnp_TxnRef query string true "Transaction Ref"
-// @Param vnp_SecureHash query string true "Secure Hash"
+// @Param gateway path string true "Cổng thanh toán (cong_thanh_toan.id)"
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
-// @Router /payment/vnpay/verify [get]
-func (s *Server) VNPayVerifyCallback(c *gin.Context) {
-	// Log để debug
-	fmt.Printf("=== VNPay Verify Callback (from Frontend) ===\n")
-	fmt.Printf("Request URL: %s\n", c.Request.URL.String())
-	fmt.Printf("Query Params: %v\n", c.Request.URL.Query())
-	fmt.Printf("==========================================\n")
-
-	// Lấy tất cả query parameters
-	queryParams := c.Request.URL.Query()
-
-	// Verify signature
-	if !s.verifyVNPaySignature(queryParams) {
-		fmt.Printf("ERROR: Invalid signature in Verify Callback\n")
+// @Failure 404 {object} gin.H
+// @Router /payment/{gateway}/verify [get]
+func (s *Server) PaymentVerify(c *gin.Context) {
+	gateway, ok := s.paymentGateway(c)
+	if !ok {
+		return
+	}
+
+	result, err := gateway.VerifyCallback(c.Request)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"status":  "failed",
 			"error":   "Invalid signature",
@@ -379,512 +388,87 @@ func (s *Server) VNPayVerifyCallback(c *gin.Context) {
 		})
 		return
 	}
-	fmt.Printf("Signature verified successfully\n")
 
-	// Parse response code
-	responseCode := queryParams.Get("vnp_ResponseCode")
-	transactionStatus := queryParams.Get("vnp_TransactionStatus")
-	txnRef := queryParams.Get("vnp_TxnRef")
-	transactionNo := queryParams.Get("vnp_TransactionNo")
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
 
-	// Tìm transaction trong database
-	transaction, err := s.z.GetTransactionByCode(c.Request.Context(), txnRef)
-	if err != nil {
-		fmt.Printf("ERROR: Transaction not found: %v\n", err)
+	outcome, bookingID := s.applyPaymentResult(ctx, gateway.ID(), result)
+	switch outcome {
+	case services.OutcomeSuccess, services.OutcomeAlreadyConfirmed:
+		c.JSON(http.StatusOK, gin.H{
+			"status":           "success",
+			"message":          "Thanh toán thành công",
+			"booking_id":       bookingID,
+			"transaction_code": result.TransactionCode,
+		})
+	case services.OutcomeTransactionNotFound:
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "failed",
 			"error":   "Transaction not found",
 			"message": "Không tìm thấy giao dịch",
 		})
-		return
-	}
-
-	if transaction.DatChoID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "failed",
-			"error":   "Invalid transaction",
-			"message": "Giao dịch không hợp lệ",
-		})
-		return
-	}
-	bookingID := int(*transaction.DatChoID)
-
-	// Kiểm tra booking tồn tại và ở trạng thái hợp lệ
-	booking, err := s.z.GetBookingById(c.Request.Context(), int32(bookingID))
-	if err != nil {
-		fmt.Printf("ERROR: Booking not found: %v\n", err)
+	case services.OutcomeBookingNotFound:
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "failed",
 			"error":   "Booking not found",
 			"message": "Không tìm thấy đơn đặt chỗ",
 		})
-		return
-	}
-
-	// Kiểm tra booking status hợp lệ
-	if !booking.TrangThai.Valid || (booking.TrangThai.TrangThaiDatCho != "cho_xac_nhan" && booking.TrangThai.TrangThaiDatCho != "da_xac_nhan") {
-		c.JSON(http.StatusBadRequest, gin.H{
+	case services.OutcomePaymentFailed:
+		c.JSON(http.StatusOK, gin.H{
 			"status":           "failed",
-			"error":            "invalid_booking_status",
-			"message":          "Đơn đặt chỗ không ở trạng thái hợp lệ để thanh toán",
+			"message":          "Thanh toán thất bại",
+			"error_code":       result.ResponseCode,
 			"booking_id":       bookingID,
-			"transaction_code": txnRef,
-		})
-		return
-	}
-
-	// Kiểm tra response code
-	if responseCode == "00" && transactionStatus == "00" {
-		fmt.Printf("Payment SUCCESS - ResponseCode: %s, TransactionStatus: %s\n", responseCode, transactionStatus)
-
-		// Cập nhật transaction
-		transaction, err = s.z.GetTransactionByCode(c.Request.Context(), txnRef)
-		if err == nil {
-			trangThai := db.NullTrangThaiThanhToan{
-				TrangThaiThanhToan: "thanh_cong",
-				Valid:              true,
-			}
-			_, err = s.z.UpdateTransactionStatus(c.Request.Context(), db.UpdateTransactionStatusParams{
-				ID:          transaction.ID,
-				TrangThai:   trangThai,
-				MaThamChieu: &transactionNo,
-			})
-			if err != nil {
-				fmt.Printf("ERROR: Failed to update transaction status: %v\n", err)
-			} else {
-				fmt.Printf("Transaction status updated successfully - ID: %d\n", transaction.ID)
-			}
-		}
-
-		// Cập nhật booking status
-		phuongThuc := "vnpay"
-		updatedBooking, err := s.z.UpdateBookingPaymentStatus(c.Request.Context(), db.UpdateBookingPaymentStatusParams{
-			ID:                  int32(bookingID),
-			PhuongThucThanhToan: &phuongThuc,
+			"transaction_code": result.TransactionCode,
 		})
-		if err != nil {
-			fmt.Printf("ERROR: Failed to update booking payment status: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"status":           "failed",
-				"error":            "update_failed",
-				"message":          "Không thể cập nhật trạng thái đơn đặt chỗ",
-				"booking_id":       bookingID,
-				"transaction_code": txnRef,
-			})
-			return
-		}
-		fmt.Printf("Booking payment status updated successfully - Booking ID: %d\n", bookingID)
-
-		// Kiểm tra xem booking đã được cập nhật thành công chưa
-		if !updatedBooking.TrangThai.Valid || updatedBooking.TrangThai.TrangThaiDatCho != "da_thanh_toan" {
-			fmt.Printf("WARNING: Booking status not updated correctly. Booking ID: %d, Status: %v\n", bookingID, updatedBooking.TrangThai)
-		}
-
-		// Trả về success
-		c.JSON(http.StatusOK, gin.H{
-			"status":           "success",
-			"message":          "Thanh toán thành công",
+	case services.OutcomeUpdateFailed:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":           "failed",
+			"error":            string(outcome),
+			"message":          "Không thể cập nhật trạng thái đơn đặt chỗ",
 			"booking_id":       bookingID,
-			"transaction_code": txnRef,
+			"transaction_code": result.TransactionCode,
 		})
-		return
-	}
-
-	// Thanh toán thất bại
-	fmt.Printf("Payment FAILED - ResponseCode: %s, TransactionStatus: %s\n", responseCode, transactionStatus)
-	transaction, err = s.z.GetTransactionByCode(c.Request.Context(), txnRef)
-	if err == nil {
-		trangThai := db.NullTrangThaiThanhToan{
-			TrangThaiThanhToan: "that_bai",
-			Valid:              true,
-		}
-		_, err = s.z.UpdateTransactionStatus(c.Request.Context(), db.UpdateTransactionStatusParams{
-			ID:        transaction.ID,
-			TrangThai: trangThai,
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":           "failed",
+			"error":            string(outcome),
+			"message":          "Giao dịch không hợp lệ",
+			"booking_id":       bookingID,
+			"transaction_code": result.TransactionCode,
 		})
-		if err != nil {
-			fmt.Printf("ERROR: Failed to update transaction status to failed: %v\n", err)
-		} else {
-			fmt.Printf("Transaction status updated to failed - ID: %d\n", transaction.ID)
-		}
 	}
-
-	// Trả về failed với error code
-	c.JSON(http.StatusOK, gin.H{
-		"status":           "failed",
-		"message":          "Thanh toán thất bại",
-		"error_code":       responseCode,
-		"booking_id":       bookingID,
-		"transaction_code": txnRef,
-	})
 }
 
-// VNPayIPN xử lý IPN (Instant Payment Notification) từ VNPay
-// @Summary VNPay IPN Callback
-// @Description Xử lý IPN callback từ VNPay server (tương đương payment_ipn trong Python)
+// PaymentIPN xử lý IPN (Instant Payment Notification) từ cổng thanh toán
+// @Summary Payment IPN Callback
+// @Description Xử lý thông báo server-to-server từ cổng thanh toán, phản hồi theo định dạng của từng cổng (vd: RspCode của VNPay)
 // @Tags Payment
 // @Accept json
 // @Produce json
-// @Param vnp_Amount formData string true "Amount"
-// @Param vnp_BankCode formData string false "Bank Code"
-// @Param vnp_BankTranNo formData string false "Bank Transaction No"
-// @Param vnp_CardType formData string false "Card Type"
-// @Param vnp_OrderInfo formData string true "Order Info"
-// @Param vnp_PayDate formData string true "Pay Date"
-// @Param vnp_ResponseCode formData string true "Response Code"
-// @Param vnp_TmnCode formData string true "TMN Code"
-// @Param vnp_TransactionNo formData string true "Transaction No"
-// @Param vnp_TransactionStatus formData string true "Transaction Status"
-// @Param vnp_TxnRef formData string true "Transaction Ref"
-// @Param vnp_SecureHash formData string true "Secure Hash"
+// @Param gateway path string true "Cổng thanh toán (cong_thanh_toan.id)"
 // @Success 200 {object} gin.H
-// @Failure 400 {object} gin.H
-// @Router /payment/vnpay/ipn [post]
-func (s *Server) VNPayIPN(c *gin.Context) {
-	// Log để debug
-	fmt.Printf("=== VNPay IPN Callback ===\n")
-	fmt.Printf("Request URL: %s\n", c.Request.URL.String())
-	fmt.Printf("Method: %s\n", c.Request.Method)
-	fmt.Printf("Content-Type: %s\n", c.Request.Header.Get("Content-Type"))
-	fmt.Printf("Client IP: %s\n", c.ClientIP())
-	fmt.Printf("User Agent: %s\n", c.Request.UserAgent())
-
-	// Lấy tất cả form data (VNPay gửi qua POST form-data)
-	inputData := c.Request.PostForm
-	fmt.Printf("Form Data: %v\n", inputData)
-	fmt.Printf("========================\n")
-
-	// Kiểm tra nếu không có data
-	if len(inputData) == 0 {
-		fmt.Printf("ERROR: No form data received in IPN\n")
-		c.JSON(http.StatusOK, gin.H{
-			"RspCode": "99",
-			"Message": "Invalid request",
-		})
-		return
-	}
-
-	// Verify signature
-	if !s.verifyVNPaySignature(inputData) {
-		fmt.Printf("ERROR: Invalid signature in IPN callback\n")
-		// Invalid Signature
-		c.JSON(http.StatusOK, gin.H{
-			"RspCode": "97",
-			"Message": "Invalid Signature",
-		})
-		return
-	}
-	fmt.Printf("IPN Signature verified successfully\n")
-
-	// Parse các tham số từ VNPay (tương đương Python: order_id, amount, order_desc, vnp_TransactionNo, etc.)
-	txnRef := inputData.Get("vnp_TxnRef")               // order_id
-	amountStr := inputData.Get("vnp_Amount")            // amount
-	_ = inputData.Get("vnp_OrderInfo")                  // order_desc
-	transactionNo := inputData.Get("vnp_TransactionNo") // vnp_TransactionNo
-	responseCode := inputData.Get("vnp_ResponseCode")   // vnp_ResponseCode
-	// Các tham số khác (có thể dùng để log hoặc debug)
-	_ = inputData.Get("vnp_TmnCode")  // tmnCode
-	_ = inputData.Get("vnp_PayDate")  // payDate
-	_ = inputData.Get("vnp_BankCode") // bankCode
-	_ = inputData.Get("vnp_CardType") // cardType
-
-	// Tìm transaction trong database
-	transaction, err := s.z.GetTransactionByCode(c.Request.Context(), txnRef)
-	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"RspCode": "01",
-			"Message": "Transaction not found",
-		})
-		return
-	}
-
-	// Kiểm tra nếu đã xử lý rồi (firstTimeUpdate = false)
-	firstTimeUpdate := !(transaction.TrangThai.Valid && transaction.TrangThai.TrangThaiThanhToan == "thanh_cong")
-
-	if !firstTimeUpdate {
-		// Already Update
-		c.JSON(http.StatusOK, gin.H{
-			"RspCode": "02",
-			"Message": "Order Already Update",
-		})
+// @Router /payment/{gateway}/ipn [post]
+func (s *Server) PaymentIPN(c *gin.Context) {
+	gateway, ok := s.paymentGateway(c)
+	if !ok {
 		return
 	}
 
-	// Kiểm tra amount (totalAmount check)
-	// Convert amount từ VNPay (amount * 100) về số tiền thực tế
-	amountFromVNPay, err := strconv.ParseInt(amountStr, 10, 64)
+	result, err := gateway.ParseIPN(c.Request)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"RspCode": "03",
-			"Message": "Invalid amount format",
-		})
-		return
-	}
-	amountFromVNPay = amountFromVNPay / 100 // VNPay gửi amount * 100
-
-	// So sánh với số tiền trong transaction
-	var transactionAmount float64
-	if transaction.SoTien.Valid {
-		floatVal, err := transaction.SoTien.Float64Value()
-		if err == nil && floatVal.Valid {
-			transactionAmount = floatVal.Float64
-		} else {
-			intVal, err := transaction.SoTien.Int64Value()
-			if err == nil && intVal.Valid {
-				transactionAmount = float64(intVal.Int64)
-			}
-		}
-	}
-
-	totalAmount := int64(transactionAmount) == amountFromVNPay
-	if !totalAmount {
-		// invalid amount
-		c.JSON(http.StatusOK, gin.H{
-			"RspCode": "04",
-			"Message": "invalid amount",
-		})
-		return
-	}
-
-	// Xử lý thanh toán thành công
-	if responseCode == "00" {
-		fmt.Printf("IPN: Payment SUCCESS - ResponseCode: %s, TransactionNo: %s\n", responseCode, transactionNo)
-		if transaction.DatChoID == nil {
-			fmt.Printf("IPN ERROR: Transaction has no booking ID\n")
-			c.JSON(http.StatusOK, gin.H{
-				"RspCode": "05",
-				"Message": "Invalid transaction",
-			})
-			return
-		}
-		bookingID := int(*transaction.DatChoID)
-		fmt.Printf("IPN: Processing booking ID: %d\n", bookingID)
-
-		// Kiểm tra booking tồn tại và ở trạng thái hợp lệ
-		booking, err := s.z.GetBookingById(c.Request.Context(), int32(bookingID))
-		if err != nil {
-			fmt.Printf("IPN ERROR: Booking not found - ID: %d, Error: %v\n", bookingID, err)
-			c.JSON(http.StatusOK, gin.H{
-				"RspCode": "06",
-				"Message": "Booking not found",
-			})
-			return
-		}
-
-		// Kiểm tra booking status hợp lệ
-		if !booking.TrangThai.Valid || (booking.TrangThai.TrangThaiDatCho != "cho_xac_nhan" && booking.TrangThai.TrangThaiDatCho != "da_xac_nhan") {
-			fmt.Printf("IPN ERROR: Invalid booking status - ID: %d, Status: %v\n", bookingID, booking.TrangThai)
-			c.JSON(http.StatusOK, gin.H{
-				"RspCode": "07",
-				"Message": "Invalid booking status",
-			})
-			return
-		}
-
-		// Cập nhật transaction
-		trangThai := db.NullTrangThaiThanhToan{
-			TrangThaiThanhToan: "thanh_cong",
-			Valid:              true,
-		}
-		_, err = s.z.UpdateTransactionStatus(c.Request.Context(), db.UpdateTransactionStatusParams{
-			ID:          transaction.ID,
-			TrangThai:   trangThai,
-			MaThamChieu: &transactionNo,
-		})
-		if err != nil {
-			fmt.Printf("IPN ERROR: Failed to update transaction status - ID: %d, Error: %v\n", transaction.ID, err)
-			c.JSON(http.StatusOK, gin.H{
-				"RspCode": "08",
-				"Message": "Failed to update transaction",
-			})
-			return
-		}
-		fmt.Printf("IPN: Transaction status updated successfully - ID: %d\n", transaction.ID)
-
-		// Cập nhật booking status
-		phuongThuc := "vnpay"
-		updatedBooking, err := s.z.UpdateBookingPaymentStatus(c.Request.Context(), db.UpdateBookingPaymentStatusParams{
-			ID:                  int32(bookingID),
-			PhuongThucThanhToan: &phuongThuc,
-		})
-		if err != nil {
-			fmt.Printf("IPN ERROR: Failed to update booking payment status - ID: %d, Error: %v\n", bookingID, err)
-			c.JSON(http.StatusOK, gin.H{
-				"RspCode": "09",
-				"Message": "Failed to update booking",
-			})
-			return
-		}
-		fmt.Printf("IPN: Booking payment status updated successfully - ID: %d\n", bookingID)
-
-		// Kiểm tra xem booking đã được cập nhật thành công chưa
-		if !updatedBooking.TrangThai.Valid || updatedBooking.TrangThai.TrangThaiDatCho != "da_thanh_toan" {
-			fmt.Printf("IPN WARNING: Booking status not updated correctly - ID: %d, Status: %v\n", bookingID, updatedBooking.TrangThai)
-		}
-
-		// Payment Success
-		fmt.Printf("IPN: Successfully processed payment - Transaction: %s, Booking: %d\n", txnRef, bookingID)
-		c.JSON(http.StatusOK, gin.H{
-			"RspCode": "00",
-			"Message": "Confirm Success",
-		})
+		fmt.Printf("ERROR: Invalid %s IPN: %v\n", gateway.ID(), err)
+		status, body := gateway.IPNResponse(outcomeFromParseError(err))
+		c.JSON(status, body)
 		return
 	}
 
-	// Payment Error (responseCode != "00")
-	fmt.Printf("IPN: Payment FAILED - ResponseCode: %s\n", responseCode)
-	trangThai := db.NullTrangThaiThanhToan{
-		TrangThaiThanhToan: "that_bai",
-		Valid:              true,
-	}
-	_, err = s.z.UpdateTransactionStatus(c.Request.Context(), db.UpdateTransactionStatusParams{
-		ID:        transaction.ID,
-		TrangThai: trangThai,
-	})
-	if err != nil {
-		fmt.Printf("IPN ERROR: Failed to update transaction status to failed - ID: %d, Error: %v\n", transaction.ID, err)
-	} else {
-		fmt.Printf("IPN: Transaction status updated to failed - ID: %d\n", transaction.ID)
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"RspCode": "10",
-		"Message": "Payment Error",
-	})
-}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
 
-// Helper functions
-
-type CreateVNPayPaymentRequest struct {
-	BookingID int32  `json:"booking_id" binding:"required"`
-	ReturnURL string `json:"return_url"`
-}
-
-func (s *Server) createVNPayPaymentURL(booking db.GetBookingByIdRow, transactionCode, returnURL, clientIP string) (string, error) {
-	vnpayConfig := s.config.VNPayConfig
-
-	// Validate VNPay config trước khi tạo URL
-	if vnpayConfig.TMNCode == "" {
-		return "", fmt.Errorf("VNPAY_TMN_CODE is not configured. Please set VNPAY_TMN_CODE in environment variables")
-	}
-	if vnpayConfig.HashSecret == "" {
-		return "", fmt.Errorf("VNPAY_HASH_SECRET is not configured. Please set VNPAY_HASH_SECRET in environment variables")
-	}
-	if vnpayConfig.PaymentURL == "" {
-		return "", fmt.Errorf("VNPAY_PAYMENT_URL is not configured. Please set VNPAY_PAYMENT_URL in environment variables (e.g., https://sandbox.vnpayment.vn/paymentv2/vpcpay.html)")
-	}
-
-	// 1. CỐ ĐỊNH MÚI GIỜ VIỆT NAM (Sửa lỗi "Quá thời gian")
-	// Sử dụng FixedZone để đảm bảo không phụ thuộc vào OS/Docker timezone
-	location := time.FixedZone("ICT", 7*3600)
-	now := time.Now().In(location)
-
-	// 2. Tính toán số tiền (VND * 100)
-	var amount int64
-	if booking.TongTien.Valid {
-		floatVal, _ := booking.TongTien.Float64Value()
-		amount = int64(floatVal.Float64)
-	}
-
-	// 3. Khởi tạo danh sách tham số
-	params := url.Values{}
-	params.Add("vnp_Version", "2.1.0")
-	params.Add("vnp_Command", "pay")
-	params.Add("vnp_TmnCode", vnpayConfig.TMNCode)
-	params.Add("vnp_Amount", strconv.FormatInt(amount*100, 10))
-	params.Add("vnp_CurrCode", "VND")
-	params.Add("vnp_TxnRef", transactionCode)
-	params.Add("vnp_OrderInfo", fmt.Sprintf("Thanh toan don dat cho #%d", booking.ID))
-	params.Add("vnp_OrderType", "other")
-	params.Add("vnp_Locale", "vn")
-	// Sử dụng returnURL từ request hoặc config
-	finalReturnURL := returnURL
-	if finalReturnURL == "" || finalReturnURL == "string" {
-		finalReturnURL = vnpayConfig.ReturnURL
-	}
-
-	// Validate Return URL
-	if finalReturnURL == "" || finalReturnURL == "string" {
-		return "", fmt.Errorf("Return URL is not configured. Please set VNPAY_RETURN_URL in .env or provide return_url in request")
-	}
-
-	// Log để debug
-	fmt.Printf("=== Creating VNPay Payment URL ===\n")
-	fmt.Printf("TMN Code: %s\n", vnpayConfig.TMNCode)
-	fmt.Printf("Payment URL (VNPay Gateway): %s\n", vnpayConfig.PaymentURL)
-	fmt.Printf("Return URL (from request): %s\n", returnURL)
-	fmt.Printf("Return URL (final): %s\n", finalReturnURL)
-	fmt.Printf("IPN URL (from config): %s\n", vnpayConfig.IPNURL)
-	fmt.Printf("Transaction Code: %s\n", transactionCode)
-
-	// Cảnh báo nếu TMN Code rỗng
-	if vnpayConfig.TMNCode == "" {
-		fmt.Printf("❌ ERROR: TMN Code is empty! Payment will fail.\n")
-	}
-
-	// Cảnh báo nếu Payment URL rỗng hoặc không phải VNPay gateway
-	if vnpayConfig.PaymentURL == "" {
-		fmt.Printf("❌ ERROR: Payment URL is empty! Cannot create payment URL.\n")
-	} else if !strings.Contains(vnpayConfig.PaymentURL, "vnpayment.vn") {
-		fmt.Printf("⚠️  WARNING: Payment URL does not look like VNPay gateway: %s\n", vnpayConfig.PaymentURL)
-	}
-
-	// Cảnh báo nếu IPN URL là localhost
-	if strings.Contains(vnpayConfig.IPNURL, "localhost") || strings.Contains(vnpayConfig.IPNURL, "127.0.0.1") {
-		fmt.Printf("⚠️  WARNING: IPN URL is localhost! VNPay cannot call localhost. Use ngrok or deploy to production.\n")
-	}
-
-	// Cảnh báo nếu Return URL là localhost
-	if strings.Contains(finalReturnURL, "localhost") || strings.Contains(finalReturnURL, "127.0.0.1") {
-		fmt.Printf("⚠️  WARNING: Return URL is localhost! VNPay may not redirect correctly. Use ngrok or deploy to production.\n")
-	}
-
-	fmt.Printf("================================\n")
-
-	params.Add("vnp_ReturnUrl", finalReturnURL)
-	params.Add("vnp_IpAddr", clientIP)
-	params.Add("vnp_CreateDate", now.Format("20060102150405"))
-	params.Add("vnp_ExpireDate", now.Add(15*time.Minute).Format("20060102150405"))
-
-	// 4. SẮP XẾP KEY THEO ALPHABET (Bắt buộc để đúng chữ ký)
-	keys := make([]string, 0, len(params))
-	for k := range params {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	// 5. TẠO CHUỖI QUERY (Dùng dấu + đồng nhất)
-	// params.Encode() của Go sẽ tự sắp xếp và dùng dấu + cho khoảng trắng
-	// Nhưng ta build thủ công theo thứ tự keys đã sort ở trên cho chắc chắn
-	var queryBuilder strings.Builder
-	for i, k := range keys {
-		if i > 0 {
-			queryBuilder.WriteString("&")
-		}
-		queryBuilder.WriteString(k)
-		queryBuilder.WriteString("=")
-		queryBuilder.WriteString(url.QueryEscape(params.Get(k)))
-	}
-	queryString := queryBuilder.String()
-
-	// 6. TÍNH CHỮ KÝ (HMAC-SHA512)
-	mac := hmac.New(sha512.New, []byte(vnpayConfig.HashSecret))
-	mac.Write([]byte(queryString))
-	secureHash := hex.EncodeToString(mac.Sum(nil))
-
-	// 7. URL CUỐI CÙNG
-	finalURL := fmt.Sprintf("%s?%s&vnp_SecureHash=%s", vnpayConfig.PaymentURL, queryString, secureHash)
-
-	// Validate final URL
-	if !strings.HasPrefix(finalURL, "https://") && !strings.HasPrefix(finalURL, "http://") {
-		return "", fmt.Errorf("Invalid payment URL format: %s", finalURL)
-	}
-
-	// Log final URL (chỉ log một phần để không expose sensitive data)
-	fmt.Printf("Final Payment URL (first 100 chars): %s...\n", finalURL[:min(100, len(finalURL))])
-	fmt.Printf("================================\n")
-
-	return finalURL, nil
+	outcome, _ := s.applyPaymentResult(ctx, gateway.ID(), result)
+	status, body := gateway.IPNResponse(outcome)
+	c.JSON(status, body)
 }
 
 // Helper function for min
@@ -894,45 +478,3 @@ func min(a, b int) int {
 	}
 	return b
 }
-
-// verifyVNPaySignature xác thực signature từ VNPay callback
-func (s *Server) verifyVNPaySignature(params url.Values) bool {
-	receivedHash := params.Get("vnp_SecureHash")
-	if receivedHash == "" {
-		return false
-	}
-
-	// Tạo bản sao của params và loại bỏ SecureHash
-	verifyParams := make(url.Values)
-	for k, v := range params {
-		if k != "vnp_SecureHash" && k != "vnp_SecureHashType" {
-			verifyParams[k] = v
-		}
-	}
-
-	// Sắp xếp keys theo alphabet (giống như createVNPayPaymentURL)
-	keys := make([]string, 0, len(verifyParams))
-	for k := range verifyParams {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	// Tạo query string thủ công (giống như createVNPayPaymentURL)
-	var queryBuilder strings.Builder
-	for i, k := range keys {
-		if i > 0 {
-			queryBuilder.WriteString("&")
-		}
-		queryBuilder.WriteString(k)
-		queryBuilder.WriteString("=")
-		queryBuilder.WriteString(url.QueryEscape(verifyParams.Get(k)))
-	}
-	queryString := queryBuilder.String()
-
-	// Tính signature bằng HMAC SHA512
-	mac := hmac.New(sha512.New, []byte(s.config.VNPayConfig.HashSecret))
-	mac.Write([]byte(queryString))
-	calculatedHash := hex.EncodeToString(mac.Sum(nil))
-
-	return receivedHash == calculatedHash
-}
@@ -0,0 +1,482 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// PayoutSyncInterval là chu kỳ tính lại sổ chi trả nhà cung cấp
+const PayoutSyncInterval = 1 * time.Hour
+
+// StartPayoutSync định kỳ tính lại các khoản chi trả nhà cung cấp
+// (khởi hành hoàn thành -> san_sang, khởi hành bị hủy -> da_huy). Dừng khi ctx bị hủy.
+func (s *Server) StartPayoutSync(ctx context.Context) {
+	ticker := time.NewTicker(PayoutSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			syncCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+			result, err := s.syncSupplierPayouts(syncCtx)
+			cancel()
+			if err != nil {
+				log.Printf("[PayoutSync] sync failed: %v", err)
+				continue
+			}
+			if len(result.CapNhat) > 0 || len(result.DaHuy) > 0 {
+				log.Printf("[PayoutSync] updated %d payouts, cancelled %d payouts", len(result.CapNhat), len(result.DaHuy))
+			}
+		}
+	}
+}
+
+func (s *Server) syncSupplierPayouts(ctx context.Context) (*db.SyncSupplierPayoutsResult, error) {
+	var tyLeHoaHong pgtype.Numeric
+	if err := tyLeHoaHong.Scan(fmt.Sprintf("%.2f", s.config.PayoutConfig.CommissionPercent)); err != nil {
+		return nil, fmt.Errorf("invalid commission percent: %w", err)
+	}
+	return s.z.SyncSupplierPayouts(ctx, tyLeHoaHong)
+}
+
+// parsePayoutStatus đọc filter trạng thái chi trả từ query
+func parsePayoutStatus(value string) (db.NullTrangThaiChiTra, bool) {
+	if value == "" {
+		return db.NullTrangThaiChiTra{}, true
+	}
+	status := db.TrangThaiChiTra(value)
+	switch status {
+	case db.TrangThaiChiTraChoChiTra, db.TrangThaiChiTraSanSang, db.TrangThaiChiTraDangXuLy,
+		db.TrangThaiChiTraDaChiTra, db.TrangThaiChiTraThatBai, db.TrangThaiChiTraDaGiu, db.TrangThaiChiTraDaHuy:
+		return db.NullTrangThaiChiTra{TrangThaiChiTra: status, Valid: true}, true
+	}
+	return db.NullTrangThaiChiTra{}, false
+}
+
+// GetPayouts godoc
+// @Summary Lấy danh sách chi trả nhà cung cấp
+// @Description Lấy danh sách khoản chi trả theo khởi hành với filter trạng thái và nhà cung cấp
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param trang_thai query string false "Trạng thái (cho_chi_tra, san_sang, dang_xu_ly, da_chi_tra, that_bai, da_giu, da_huy)"
+// @Param nha_cung_cap_id query string false "Nhà cung cấp ID"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/payouts [get]
+func (s *Server) GetPayouts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	trangThai, ok := parsePayoutStatus(c.Query("trang_thai"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trạng thái chi trả không hợp lệ"})
+		return
+	}
+	var nhaCungCapID pgtype.UUID
+	if v := c.Query("nha_cung_cap_id"); v != "" {
+		if err := nhaCungCapID.Scan(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid nha_cung_cap_id"})
+			return
+		}
+	}
+
+	payouts, err := s.z.GetPayouts(ctx, db.GetPayoutsParams{
+		TrangThai:    trangThai,
+		NhaCungCapID: nhaCungCapID,
+		Limit:        int32(limit),
+		Offset:       int32(offset),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get payouts",
+			"details": err.Error(),
+		})
+		return
+	}
+	totalCount, err := s.z.CountPayouts(ctx, db.CountPayoutsParams{
+		TrangThai:    trangThai,
+		NhaCungCapID: nhaCungCapID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to count payouts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Payouts fetched successfully",
+		"data":     payouts,
+		"total":    totalCount,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": (offset + limit) < int(totalCount),
+	})
+}
+
+// SyncPayouts godoc
+// @Summary Tính lại sổ chi trả nhà cung cấp
+// @Description Tính lại doanh thu, phí cổng thanh toán, hoa hồng nền tảng và số tiền thực nhận cho các khoản chưa duyệt
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/payouts/sync [post]
+func (s *Server) SyncPayouts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := s.syncSupplierPayouts(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to sync payouts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payouts synced successfully",
+		"data": gin.H{
+			"ty_le_hoa_hong": s.config.PayoutConfig.CommissionPercent,
+			"cap_nhat":       result.CapNhat,
+			"da_huy":         result.DaHuy,
+		},
+	})
+}
+
+// BatchUpdatePayouts godoc
+// @Summary Duyệt / giữ / đánh dấu đã chi trả hàng loạt
+// @Description approve: san_sang|that_bai -> dang_xu_ly (yêu cầu tài khoản ngân hàng mặc định); hold: -> da_giu; release: da_giu -> san_sang|cho_chi_tra; mark_paid: dang_xu_ly -> da_chi_tra; mark_failed: dang_xu_ly -> that_bai
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.BatchPayoutRequest true "Batch payout request"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/payouts/batch [put]
+func (s *Server) BatchUpdatePayouts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
+	var req models.BatchPayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var updated []db.ChiTraNhaCungCap
+	var err error
+	switch req.HanhDong {
+	case "approve":
+		updated, err = s.z.ApprovePayouts(ctx, db.ApprovePayoutsParams{
+			NguoiDuyetID: jwtClaims.Id,
+			GhiChu:       req.GhiChu,
+			Ids:          req.Ids,
+		})
+	case "hold":
+		updated, err = s.z.HoldPayouts(ctx, db.HoldPayoutsParams{
+			NguoiDuyetID: jwtClaims.Id,
+			GhiChu:       req.GhiChu,
+			Ids:          req.Ids,
+		})
+	case "release":
+		updated, err = s.z.ReleasePayouts(ctx, db.ReleasePayoutsParams{
+			NguoiDuyetID: jwtClaims.Id,
+			GhiChu:       req.GhiChu,
+			Ids:          req.Ids,
+		})
+	case "mark_paid":
+		updated, err = s.z.MarkPayoutsPaid(ctx, db.MarkPayoutsPaidParams{
+			MaThamChieuChiTra: req.MaThamChieuChiTra,
+			GhiChu:            req.GhiChu,
+			Ids:               req.Ids,
+		})
+	case "mark_failed":
+		updated, err = s.z.MarkPayoutsFailed(ctx, db.MarkPayoutsFailedParams{
+			GhiChu: req.GhiChu,
+			Ids:    req.Ids,
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update payouts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Các khoản không chuyển trạng thái được (sai trạng thái hoặc chưa có tài khoản ngân hàng)
+	updatedIDs := make(map[int32]bool, len(updated))
+	for _, p := range updated {
+		updatedIDs[p.ID] = true
+	}
+	skipped := make([]int32, 0)
+	for _, id := range req.Ids {
+		if !updatedIDs[id] {
+			skipped = append(skipped, id)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payouts updated successfully",
+		"data": gin.H{
+			"hanh_dong": req.HanhDong,
+			"cap_nhat":  updated,
+			"bo_qua":    skipped,
+		},
+	})
+}
+
+// GetMyPayouts godoc
+// @Summary Lịch sử chi trả của nhà cung cấp
+// @Description Lấy danh sách khoản chi trả theo khởi hành (doanh thu, phí cổng thanh toán, hoa hồng, thực nhận)
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param trang_thai query string false "Trạng thái chi trả"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/revenue/payouts [get]
+func (s *Server) GetMyPayouts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	trangThai, ok := parsePayoutStatus(c.Query("trang_thai"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Trạng thái chi trả không hợp lệ"})
+		return
+	}
+
+	payouts, err := s.z.GetSupplierPayouts(ctx, db.GetSupplierPayoutsParams{
+		NhaCungCapID: currentSupplierID(c),
+		TrangThai:    trangThai,
+		Limit:        int32(limit),
+		Offset:       int32(offset),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	totalCount, err := s.z.CountSupplierPayouts(ctx, db.CountSupplierPayoutsParams{
+		NhaCungCapID: currentSupplierID(c),
+		TrangThai:    trangThai,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Payouts fetched successfully",
+		"data":     payouts,
+		"total":    totalCount,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": (offset + limit) < int(totalCount),
+	})
+}
+
+// GetMyPayoutSummary godoc
+// @Summary Tổng hợp chi trả của nhà cung cấp
+// @Description Tổng số tiền thực nhận theo từng trạng thái chi trả
+// @Tags Supplier
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/revenue/payouts/summary [get]
+func (s *Server) GetMyPayoutSummary(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	summary, err := s.z.GetSupplierPayoutSummary(ctx, currentSupplierID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Payout summary fetched successfully", "data": summary})
+}
+
+// GetMyBankAccounts godoc
+// @Summary Danh sách tài khoản ngân hàng
+// @Description Lấy danh sách tài khoản ngân hàng nhận chi trả của nhà cung cấp
+// @Tags Supplier
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/bank-accounts [get]
+func (s *Server) GetMyBankAccounts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	accounts, err := s.z.GetSupplierBankAccounts(ctx, currentSupplierID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bank accounts fetched successfully", "data": accounts})
+}
+
+// CreateMyBankAccount godoc
+// @Summary Thêm tài khoản ngân hàng
+// @Description Thêm tài khoản ngân hàng nhận chi trả. Tài khoản đầu tiên tự động là mặc định
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param request body models.CreateBankAccountRequest true "Bank account"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/bank-accounts [post]
+func (s *Server) CreateMyBankAccount(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.CreateBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	account, err := s.z.CreateSupplierBankAccount(ctx, db.CreateBankAccountParams{
+		NhaCungCapID:   currentSupplierID(c),
+		TenNganHang:    req.TenNganHang,
+		SoTaiKhoan:     req.SoTaiKhoan,
+		TenChuTaiKhoan: req.TenChuTaiKhoan,
+		ChiNhanh:       req.ChiNhanh,
+		LaMacDinh:      req.LaMacDinh,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Bank account created successfully", "data": account})
+}
+
+// SetMyDefaultBankAccount godoc
+// @Summary Đặt tài khoản ngân hàng mặc định
+// @Description Tài khoản mặc định được dùng khi admin duyệt chi trả
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "Bank account ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /supplier/bank-accounts/{id}/default [put]
+func (s *Server) SetMyDefaultBankAccount(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid id"})
+		return
+	}
+
+	account, err := s.z.ChangeDefaultBankAccount(ctx, db.SetDefaultBankAccountParams{
+		ID:           int32(id),
+		NhaCungCapID: currentSupplierID(c),
+	})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Không tìm thấy tài khoản ngân hàng", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Default bank account updated successfully", "data": account})
+}
+
+// DeleteMyBankAccount godoc
+// @Summary Xóa tài khoản ngân hàng
+// @Description Xóa tài khoản ngân hàng của nhà cung cấp
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "Bank account ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /supplier/bank-accounts/{id} [delete]
+func (s *Server) DeleteMyBankAccount(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid id"})
+		return
+	}
+
+	account, err := s.z.DeleteBankAccount(ctx, db.DeleteBankAccountParams{
+		ID:           int32(id),
+		NhaCungCapID: currentSupplierID(c),
+	})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Không tìm thấy tài khoản ngân hàng", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bank account deleted successfully", "data": account})
+}
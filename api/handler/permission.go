@@ -0,0 +1,557 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/middleware"
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// PermissionCacheDuration là thời gian cache tập quyền của người dùng trong Redis
+	PermissionCacheDuration = 5 * time.Minute
+
+	permissionCacheKeyPrefix = "permissions:user:"
+)
+
+var roleCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// userPermissions dùng cho RequirePermission: quyền của vai trò hệ thống (theo enum) + vai trò được gán, cache ngắn trong Redis
+func (s *Server) userPermissions(ctx context.Context, claims *utils.JwtClams) (map[string]bool, error) {
+	key := permissionCacheKeyPrefix + claims.Id.String()
+
+	var codes []string
+	cached, err := s.redis.Get(ctx, key).Result()
+	if err != nil || json.Unmarshal([]byte(cached), &codes) != nil {
+		codes, err = s.z.GetUserPermissions(ctx, db.GetUserPermissionsParams{
+			NguoiDungID: claims.Id,
+			VaiTro:      claims.Vaitro,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if data, err := json.Marshal(codes); err == nil {
+			s.redis.Set(ctx, key, data, PermissionCacheDuration)
+		}
+	}
+
+	granted := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		granted[code] = true
+	}
+	return granted, nil
+}
+
+// invalidatePermissionCache xóa cache quyền của các người dùng chỉ định, không truyền ai thì xóa toàn bộ
+func (s *Server) invalidatePermissionCache(ctx context.Context, userIDs ...pgtype.UUID) {
+	var err error
+	if len(userIDs) == 0 {
+		err = utils.NewCacheHelper(s.redis).DeletePattern(ctx, permissionCacheKeyPrefix+"*")
+	} else {
+		keys := make([]string, 0, len(userIDs))
+		for _, id := range userIDs {
+			keys = append(keys, permissionCacheKeyPrefix+id.String())
+		}
+		err = s.redis.Del(ctx, keys...).Err()
+	}
+	if err != nil {
+		log.Printf("[Permission] invalidate permission cache failed: %v", err)
+	}
+}
+
+// normalizeCodes bỏ khoảng trắng, trùng lặp và sắp xếp danh sách mã
+func normalizeCodes(codes []string) []string {
+	seen := make(map[string]bool, len(codes))
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		result = append(result, code)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// validatePermissionCodes trả về false (và đã ghi response 400) nếu có mã quyền không tồn tại
+func (s *Server) validatePermissionCodes(c *gin.Context, ctx context.Context, codes []string) bool {
+	if len(codes) == 0 {
+		return true
+	}
+	unknown, err := s.z.GetUnknownPermissions(ctx, codes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate permissions", "details": err.Error()})
+		return false
+	}
+	if len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":              "Mã quyền không tồn tại",
+			"quyen_khong_hop_le": unknown,
+		})
+		return false
+	}
+	return true
+}
+
+// GetMyPermissions godoc
+// @Summary Get my permissions
+// @Description Danh sách quyền hiệu lực của người dùng hiện tại (dùng để ẩn/hiện chức năng ở giao diện)
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/permissions [get]
+func (s *Server) GetMyPermissions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	granted, err := s.userPermissions(ctx, jwtClaims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get permissions", "details": err.Error()})
+		return
+	}
+	roles, err := s.z.GetUserRoles(ctx, jwtClaims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get roles", "details": err.Error()})
+		return
+	}
+
+	codes := make([]string, 0, len(granted))
+	for code := range granted {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	roleCodes := []string{jwtClaims.Vaitro}
+	for _, role := range roles {
+		roleCodes = append(roleCodes, role.Ma)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"vai_tro": roleCodes,
+			"quyen":   codes,
+		},
+	})
+}
+
+// GetPermissions godoc
+// @Summary Get permission catalog
+// @Description Danh mục quyền có thể gán cho vai trò
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/permissions [get]
+func (s *Server) GetPermissions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	permissions, err := s.z.GetAllPermissions(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get permissions", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": permissions})
+}
+
+// GetRoles godoc
+// @Summary Get roles
+// @Description Danh sách vai trò kèm quyền và số người dùng được gán
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/roles [get]
+func (s *Server) GetRoles(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	roles, err := s.z.GetRolesWithPermissions(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get roles", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": roles})
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Description Tạo vai trò mới (vd: ho_tro, ke_toan) kèm danh sách quyền
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.CreateRoleRequest true "Vai trò"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/roles [post]
+func (s *Server) CreateRole(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !roleCodePattern.MatchString(req.Ma) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mã vai trò chỉ gồm chữ thường, số và dấu gạch dưới, bắt đầu bằng chữ cái"})
+		return
+	}
+	permissions := normalizeCodes(req.Quyen)
+	if !s.validatePermissionCodes(c, ctx, permissions) {
+		return
+	}
+
+	role, err := s.z.CreateRole(ctx, db.CreateRoleParams{
+		Ma:   req.Ma,
+		Ten:  req.Ten,
+		MoTa: req.MoTa,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Mã vai trò đã tồn tại"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role", "details": err.Error()})
+		return
+	}
+	if err := s.z.SetRolePermissions(ctx, role.Ma, permissions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set role permissions", "details": err.Error()})
+		return
+	}
+	s.recordAudit(c, AuditRoleCreate, AuditTargetRole, role.Ma,
+		nil, gin.H{"ma": role.Ma, "ten": role.Ten, "quyen": permissions})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tạo vai trò thành công",
+		"data": gin.H{
+			"vai_tro": role,
+			"quyen":   permissions,
+		},
+	})
+}
+
+// UpdateRole godoc
+// @Summary Update role
+// @Description Cập nhật tên / mô tả vai trò
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param ma path string true "Mã vai trò"
+// @Param request body models.UpdateRoleRequest true "Thông tin vai trò"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/roles/{ma} [put]
+func (s *Server) UpdateRole(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := s.z.UpdateRole(ctx, db.UpdateRoleParams{
+		Ma:   c.Param("ma"),
+		Ten:  req.Ten,
+		MoTa: req.MoTa,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy vai trò"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cập nhật vai trò thành công",
+		"data":    role,
+	})
+}
+
+// SetRolePermissions godoc
+// @Summary Set role permissions
+// @Description Thay toàn bộ quyền của vai trò. Vai trò quan_tri luôn phải giữ quyền role.manage để không tự khóa quyền quản trị
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param ma path string true "Mã vai trò"
+// @Param request body models.SetRolePermissionsRequest true "Danh sách quyền"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/roles/{ma}/permissions [put]
+func (s *Server) SetRolePermissions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.SetRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := s.z.GetRoleByCode(ctx, c.Param("ma"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy vai trò"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get role", "details": err.Error()})
+		return
+	}
+
+	permissions := normalizeCodes(req.Quyen)
+	if !s.validatePermissionCodes(c, ctx, permissions) {
+		return
+	}
+	if role.Ma == string(db.VaiTroNguoiDungQuanTri) && !slices.Contains(permissions, middleware.PermRoleManage) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vai trò quan_tri phải giữ quyền " + middleware.PermRoleManage})
+		return
+	}
+
+	before, err := s.z.GetRolePermissionCodes(ctx, role.Ma)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get role permissions", "details": err.Error()})
+		return
+	}
+
+	if err := s.z.SetRolePermissions(ctx, role.Ma, permissions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set role permissions", "details": err.Error()})
+		return
+	}
+	s.invalidatePermissionCache(ctx)
+	s.recordAudit(c, AuditRolePermissions, AuditTargetRole, role.Ma,
+		gin.H{"quyen": before}, gin.H{"quyen": permissions})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cập nhật quyền của vai trò thành công",
+		"data": gin.H{
+			"vai_tro": role.Ma,
+			"quyen":   permissions,
+		},
+	})
+}
+
+// DeleteRole godoc
+// @Summary Delete role
+// @Description Xóa vai trò tự tạo (vai trò hệ thống không xóa được). Người dùng đang được gán vai trò sẽ mất các quyền tương ứng
+// @Tags Admin
+// @Produce json
+// @Param ma path string true "Mã vai trò"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/roles/{ma} [delete]
+func (s *Server) DeleteRole(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	role, err := s.z.GetRoleByCode(ctx, c.Param("ma"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy vai trò"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get role", "details": err.Error()})
+		return
+	}
+	if role.HeThong {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Không thể xóa vai trò hệ thống"})
+		return
+	}
+
+	permissions, err := s.z.GetRolePermissionCodes(ctx, role.Ma)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get role permissions", "details": err.Error()})
+		return
+	}
+
+	if _, err := s.z.DeleteRole(ctx, role.Ma); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role", "details": err.Error()})
+		return
+	}
+	s.invalidatePermissionCache(ctx)
+	s.recordAudit(c, AuditRoleDelete, AuditTargetRole, role.Ma,
+		gin.H{"ma": role.Ma, "ten": role.Ten, "quyen": permissions}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Xóa vai trò thành công"})
+}
+
+// GetUserRoles godoc
+// @Summary Get user roles
+// @Description Vai trò hệ thống và các vai trò được gán thêm của người dùng, kèm quyền hiệu lực
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/roles [get]
+func (s *Server) GetUserRoles(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var userID pgtype.UUID
+	if err := userID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	user, err := s.z.GetUserById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy người dùng"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user", "details": err.Error()})
+		return
+	}
+
+	roles, err := s.z.GetUserRoles(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user roles", "details": err.Error()})
+		return
+	}
+	permissions, err := s.z.GetUserPermissions(ctx, db.GetUserPermissionsParams{
+		NguoiDungID: userID,
+		VaiTro:      string(user.VaiTro.VaiTroNguoiDung),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user permissions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"vai_tro_he_thong": user.VaiTro.VaiTroNguoiDung,
+			"vai_tro_duoc_gan": roles,
+			"quyen":            permissions,
+		},
+	})
+}
+
+// SetUserRoles godoc
+// @Summary Set user roles
+// @Description Thay toàn bộ vai trò được gán thêm cho người dùng (vd: gán ho_tro cho nhân viên hỗ trợ)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.SetUserRolesRequest true "Danh sách vai trò"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/roles [put]
+func (s *Server) SetUserRoles(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	adminClaims, ok := getAdminClaims(c)
+	if !ok {
+		return
+	}
+
+	var userID pgtype.UUID
+	if err := userID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var req models.SetUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := s.z.GetUserById(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy người dùng"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user", "details": err.Error()})
+		return
+	}
+	current, err := s.z.GetUserRoles(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user roles", "details": err.Error()})
+		return
+	}
+	before := make([]string, 0, len(current))
+	for _, role := range current {
+		before = append(before, role.Ma)
+	}
+
+	roles := normalizeCodes(req.VaiTro)
+	if len(roles) > 0 {
+		unknown, err := s.z.GetUnknownRoles(ctx, roles)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate roles", "details": err.Error()})
+			return
+		}
+		if len(unknown) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":                "Vai trò không tồn tại",
+				"vai_tro_khong_hop_le": unknown,
+			})
+			return
+		}
+	}
+
+	if err := s.z.SetUserRoles(ctx, userID, roles, adminClaims.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set user roles", "details": err.Error()})
+		return
+	}
+	s.invalidatePermissionCache(ctx, userID)
+	s.recordAudit(c, AuditUserRoles, AuditTargetUser, userID.String(),
+		gin.H{"vai_tro_duoc_gan": before}, gin.H{"vai_tro_duoc_gan": roles})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cập nhật vai trò của người dùng thành công",
+		"data": gin.H{
+			"nguoi_dung_id":    userID,
+			"vai_tro_duoc_gan": roles,
+		},
+	})
+}
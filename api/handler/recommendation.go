@@ -247,10 +247,11 @@ func (s *Server) getAIRecommendedTours(ctx context.Context, userUUID pgtype.UUID
 	if len(preferences) > 0 {
 		var prefParts []string
 		for _, p := range preferences {
+			diemSo, _ := p.DiemSo.Float64Value()
 			if p.LoaiSoThich == "danh_muc" {
-				prefParts = append(prefParts, fmt.Sprintf("Danh mục ID %d: điểm số %.2f", p.GiaTriID, p.DiemSo))
+				prefParts = append(prefParts, fmt.Sprintf("Danh mục ID %d: điểm số %.2f", p.GiaTriID, diemSo.Float64))
 			} else if p.LoaiSoThich == "diem_den" {
-				prefParts = append(prefParts, fmt.Sprintf("Điểm đến ID %d: điểm số %.2f", p.GiaTriID, p.DiemSo))
+				prefParts = append(prefParts, fmt.Sprintf("Điểm đến ID %d: điểm số %.2f", p.GiaTriID, diemSo.Float64))
 			}
 		}
 		if len(prefParts) > 0 {
@@ -0,0 +1,402 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"travia.backend/api/services"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// RefundExecutionTimeout là thời gian tối đa cho một lần gọi API hoàn tiền của cổng thanh toán
+const RefundExecutionTimeout = 30 * time.Second
+
+// ProcessRefundRequest là yêu cầu duyệt / thử lại hoàn tiền.
+// thu_cong = true: admin đã hoàn tiền thủ công (vd: chuyển khoản, trang quản trị cổng) và chỉ ghi nhận kết quả
+type ProcessRefundRequest struct {
+	GhiChu      *string `json:"ghi_chu"`
+	ThuCong     bool    `json:"thu_cong"`
+	MaThamChieu *string `json:"ma_tham_chieu"`
+}
+
+// RejectRefundRequest là yêu cầu từ chối hoàn tiền
+type RejectRefundRequest struct {
+	LyDo string `json:"ly_do" binding:"required"`
+}
+
+// parseRefundStatus đọc filter trạng thái khoản hoàn tiền từ query
+func parseRefundStatus(value string) (db.NullTrangThaiThanhToan, bool) {
+	if value == "" {
+		return db.NullTrangThaiThanhToan{}, true
+	}
+	status := db.TrangThaiThanhToan(value)
+	switch status {
+	case db.TrangThaiThanhToanDangXuly, db.TrangThaiThanhToanThatBai, db.TrangThaiThanhToanDaHuy,
+		db.TrangThaiThanhToanDaHoanTien, db.TrangThaiThanhToanHoanMotPhan:
+		return db.NullTrangThaiThanhToan{TrangThaiThanhToan: status, Valid: true}, true
+	}
+	return db.NullTrangThaiThanhToan{}, false
+}
+
+// GetRefundTransactions godoc
+// @Summary Danh sách khoản hoàn tiền
+// @Description Lấy danh sách giao dịch hoàn tiền (loai_giao_dich = hoan_tien) kèm giao dịch thanh toán gốc
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param trang_thai query string false "Trạng thái (dang_xuly, that_bai, da_huy, da_hoan_tien, hoan_mot_phan)"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/refunds/transactions [get]
+func (s *Server) GetRefundTransactions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	trangThai, ok := parseRefundStatus(c.Query("trang_thai"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trạng thái hoàn tiền không hợp lệ"})
+		return
+	}
+
+	refunds, err := s.z.GetRefundTransactions(ctx, db.GetRefundTransactionsParams{
+		TrangThai: trangThai,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get refund transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+	totalCount, err := s.z.CountRefundTransactions(ctx, trangThai)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to count refund transactions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Refund transactions fetched successfully",
+		"data":     refunds,
+		"total":    totalCount,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": (offset + limit) < int(totalCount),
+	})
+}
+
+// ApproveRefund godoc
+// @Summary Duyệt và thực hiện hoàn tiền
+// @Description Duyệt khoản hoàn tiền đang chờ và gọi API hoàn tiền của cổng thanh toán gốc (VNPay, MoMo). Cổng không hỗ trợ hoàn tiền tự động (chuyển khoản, Stripe) phải dùng thu_cong = true sau khi đã hoàn tiền thủ công
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Refund transaction ID"
+// @Param request body ProcessRefundRequest false "Approve refund request"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 502 {object} gin.H
+// @Router /admin/refunds/{id}/approve [put]
+func (s *Server) ApproveRefund(c *gin.Context) {
+	s.processRefund(c, false)
+}
+
+// RetryRefund godoc
+// @Summary Thử lại hoàn tiền
+// @Description Thử lại khoản hoàn tiền thất bại. Khoản bị treo quá 10 phút sau khi duyệt có thể đã được hoàn phía cổng nên chỉ được ghi nhận thủ công (thu_cong = true) sau khi đối soát với cổng thanh toán
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Refund transaction ID"
+// @Param request body ProcessRefundRequest false "Retry refund request"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 502 {object} gin.H
+// @Router /admin/refunds/{id}/retry [put]
+func (s *Server) RetryRefund(c *gin.Context) {
+	s.processRefund(c, true)
+}
+
+// RejectRefund godoc
+// @Summary Từ chối hoàn tiền
+// @Description Từ chối khoản hoàn tiền đang chờ duyệt hoặc đã thất bại
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Refund transaction ID"
+// @Param request body RejectRefundRequest true "Reject refund request"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /admin/refunds/{id}/reject [put]
+func (s *Server) RejectRefund(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAdminClaims(c)
+	if !ok {
+		return
+	}
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refund ID"})
+		return
+	}
+	var req RejectRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refund, err := s.z.RejectRefund(ctx, db.RejectRefundParams{
+		ID:           int32(refundID),
+		NguoiDuyetID: jwtClaims.Id,
+		GhiChuXuLy:   req.LyDo,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Khoản hoàn tiền không tồn tại hoặc không ở trạng thái có thể từ chối"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to reject refund",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund rejected successfully",
+		"data":    refund,
+	})
+}
+
+// getAdminClaims đọc claims của người dùng đăng nhập, trả về false nếu đã phản hồi lỗi
+func getAdminClaims(c *gin.Context) (*utils.JwtClams, bool) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return nil, false
+	}
+	return jwtClaims, true
+}
+
+// processRefund duyệt (retry = false) hoặc thử lại (retry = true) một khoản hoàn tiền rồi thực hiện hoàn tiền
+func (s *Server) processRefund(c *gin.Context, retry bool) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), RefundExecutionTimeout)
+	defer cancel()
+
+	jwtClaims, ok := getAdminClaims(c)
+	if !ok {
+		return
+	}
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refund ID"})
+		return
+	}
+	var req ProcessRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	refund, err := s.z.GetRefundTransactionByID(ctx, int32(refundID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Refund transaction not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get refund transaction",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Cổng thanh toán gốc phải hỗ trợ hoàn tiền tự động, nếu không admin phải hoàn tiền thủ công
+	var gateway services.PaymentGateway
+	if !req.ThuCong {
+		var gatewayID string
+		if refund.CongThanhToanID != nil {
+			gatewayID = *refund.CongThanhToanID
+		}
+		var found bool
+		gateway, found = s.gateways.Get(gatewayID)
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Cổng thanh toán không hỗ trợ hoàn tiền tự động, hãy hoàn tiền thủ công và gửi thu_cong = true",
+				"details": gatewayID,
+			})
+			return
+		}
+	}
+
+	if retry {
+		_, err = s.z.ClaimRefundForRetry(ctx, db.ClaimRefundForRetryParams{
+			ID:           refund.ID,
+			NguoiDuyetID: jwtClaims.Id,
+			ThuCong:      req.ThuCong,
+		})
+	} else {
+		_, err = s.z.ClaimRefundForApproval(ctx, db.ClaimRefundForApprovalParams{
+			ID:           refund.ID,
+			NguoiDuyetID: jwtClaims.Id,
+			GhiChuXuLy:   req.GhiChu,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Khoản hoàn tiền không ở trạng thái có thể xử lý"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to claim refund",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	amount, _ := numericToFloat64(refund.SoTien)
+	originalAmount, _ := numericToFloat64(refund.SoTienGoc)
+	completedStatus := db.TrangThaiThanhToanDaHoanTien
+	if amount < originalAmount {
+		completedStatus = db.TrangThaiThanhToanHoanMotPhan
+	}
+
+	if req.ThuCong {
+		completed, err := s.z.CompleteRefund(ctx, db.CompleteRefundParams{
+			ID:          refund.ID,
+			TrangThai:   completedStatus,
+			MaThamChieu: req.MaThamChieu,
+			GhiChuXuLy:  req.GhiChu,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to complete refund",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Refund recorded successfully",
+			"data":    completed,
+		})
+		return
+	}
+
+	refundReq := services.RefundRequest{
+		TransactionCode: refund.MaGiaoDichGoc,
+		RefundCode:      refund.MaGiaoDichNoiBo,
+		Amount:          amount,
+		OriginalAmount:  originalAmount,
+		CreatedBy:       jwtClaims.Email,
+		ClientIP:        c.ClientIP(),
+	}
+	if refund.MaThamChieuGoc != nil {
+		refundReq.GatewayRef = *refund.MaThamChieuGoc
+	}
+	if refund.NgayThanhToanGoc.Valid {
+		refundReq.TransactionDate = refund.NgayThanhToanGoc.Time
+	}
+	if refund.LyDo != nil {
+		refundReq.Reason = *refund.LyDo
+	}
+
+	result, refundErr := gateway.Refund(ctx, refundReq)
+	if refundErr != nil || !result.Success {
+		message := "Cổng thanh toán từ chối hoàn tiền"
+		if refundErr != nil {
+			message = refundErr.Error()
+		} else if result.Message != "" {
+			message = fmt.Sprintf("%s (%s)", result.Message, result.ResponseCode)
+		}
+		fmt.Printf("Refund %s via %s failed: %s\n", refund.MaGiaoDichNoiBo, gateway.ID(), message)
+
+		// Dùng context riêng để vẫn ghi nhận thất bại khi request đã hết thời gian chờ
+		failCtx, failCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer failCancel()
+		failed, err := s.z.FailRefund(failCtx, db.FailRefundParams{
+			ID:         refund.ID,
+			GhiChuXuLy: &message,
+		})
+		if err != nil {
+			fmt.Printf("Failed to mark refund %s as failed: %v\n", refund.MaGiaoDichNoiBo, err)
+		}
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Hoàn tiền qua cổng thanh toán thất bại",
+			"details": message,
+			"data":    failed,
+		})
+		return
+	}
+
+	var gatewayRef *string
+	if result.GatewayRef != "" {
+		gatewayRef = &result.GatewayRef
+	}
+	completeCtx, completeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer completeCancel()
+	completed, err := s.z.CompleteRefund(completeCtx, db.CompleteRefundParams{
+		ID:          refund.ID,
+		TrangThai:   completedStatus,
+		MaThamChieu: gatewayRef,
+	})
+	if err != nil {
+		// Tiền đã được hoàn phía cổng: không chuyển that_bai để tránh hoàn tiền hai lần.
+		// Khoản này chỉ có thể được ghi nhận lại qua /retry với thu_cong = true (ClaimRefundForRetry không gọi lại cổng)
+		fmt.Printf("Refund %s succeeded at %s (ref %v) but status update failed: %v\n", refund.MaGiaoDichNoiBo, gateway.ID(), result.GatewayRef, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Refund succeeded at gateway but failed to update status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund completed successfully",
+		"data":    completed,
+	})
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/services"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// fakeRefundStore giả lập một khoản hoàn tiền và các câu SQL nhận / hoàn tất / đánh dấu thất bại của nó.
+// ClaimRefundForRetry giữ đúng điều kiện WHERE của câu SQL thật
+type fakeRefundStore struct {
+	db.Z
+	refund      db.GetRefundTransactionByIDRow
+	completeErr error
+}
+
+func newFakeRefundStore(gatewayID string) *fakeRefundStore {
+	var amount pgtype.Numeric
+	if err := amount.Scan("500000"); err != nil {
+		panic(err)
+	}
+	return &fakeRefundStore{refund: db.GetRefundTransactionByIDRow{
+		ID:              1,
+		MaGiaoDichNoiBo: "RF1",
+		CongThanhToanID: &gatewayID,
+		SoTien:          amount,
+		TrangThai:       db.NullTrangThaiThanhToan{TrangThaiThanhToan: db.TrangThaiThanhToanDangXuly, Valid: true},
+		MaGiaoDichGoc:   "TXN1",
+		SoTienGoc:       amount,
+	}}
+}
+
+func (f *fakeRefundStore) status() db.TrangThaiThanhToan {
+	return f.refund.TrangThai.TrangThaiThanhToan
+}
+
+func (f *fakeRefundStore) setStatus(status db.TrangThaiThanhToan) {
+	f.refund.TrangThai = db.NullTrangThaiThanhToan{TrangThaiThanhToan: status, Valid: true}
+}
+
+func (f *fakeRefundStore) claim(approver pgtype.UUID) db.LichSuGiaoDich {
+	f.setStatus(db.TrangThaiThanhToanDangXuly)
+	f.refund.NguoiDuyetID = approver
+	f.refund.NgayDuyet = pgtype.Timestamp{Time: time.Now(), Valid: true}
+	f.refund.SoLanThu++
+	return db.LichSuGiaoDich{ID: f.refund.ID}
+}
+
+func (f *fakeRefundStore) GetRefundTransactionByID(_ context.Context, id int32) (db.GetRefundTransactionByIDRow, error) {
+	if id != f.refund.ID {
+		return db.GetRefundTransactionByIDRow{}, pgx.ErrNoRows
+	}
+	return f.refund, nil
+}
+
+func (f *fakeRefundStore) ClaimRefundForApproval(_ context.Context, arg db.ClaimRefundForApprovalParams) (db.LichSuGiaoDich, error) {
+	if f.status() != db.TrangThaiThanhToanDangXuly || f.refund.NguoiDuyetID.Valid {
+		return db.LichSuGiaoDich{}, pgx.ErrNoRows
+	}
+	return f.claim(arg.NguoiDuyetID), nil
+}
+
+func (f *fakeRefundStore) ClaimRefundForRetry(_ context.Context, arg db.ClaimRefundForRetryParams) (db.LichSuGiaoDich, error) {
+	stuck := arg.ThuCong &&
+		f.status() == db.TrangThaiThanhToanDangXuly &&
+		f.refund.NguoiDuyetID.Valid &&
+		f.refund.NgayDuyet.Time.Before(time.Now().Add(-10*time.Minute))
+	if f.status() != db.TrangThaiThanhToanThatBai && !stuck {
+		return db.LichSuGiaoDich{}, pgx.ErrNoRows
+	}
+	return f.claim(arg.NguoiDuyetID), nil
+}
+
+func (f *fakeRefundStore) CompleteRefund(_ context.Context, arg db.CompleteRefundParams) (db.LichSuGiaoDich, error) {
+	if f.completeErr != nil {
+		return db.LichSuGiaoDich{}, f.completeErr
+	}
+	if f.status() != db.TrangThaiThanhToanDangXuly {
+		return db.LichSuGiaoDich{}, pgx.ErrNoRows
+	}
+	f.setStatus(arg.TrangThai)
+	f.refund.MaThamChieuCongThanhToan = arg.MaThamChieu
+	return db.LichSuGiaoDich{ID: f.refund.ID}, nil
+}
+
+func (f *fakeRefundStore) FailRefund(_ context.Context, arg db.FailRefundParams) (db.LichSuGiaoDich, error) {
+	f.setStatus(db.TrangThaiThanhToanThatBai)
+	f.refund.GhiChuXuLy = arg.GhiChuXuLy
+	return db.LichSuGiaoDich{ID: f.refund.ID}, nil
+}
+
+// fakeRefundGateway đếm số lần gọi API hoàn tiền; fail = true thì cổng từ chối
+type fakeRefundGateway struct {
+	services.PaymentGateway
+	calls int
+	fail  bool
+}
+
+func (g *fakeRefundGateway) ID() string { return "fakepay" }
+
+func (g *fakeRefundGateway) Refund(_ context.Context, req services.RefundRequest) (*services.RefundResult, error) {
+	g.calls++
+	if g.fail {
+		return &services.RefundResult{Success: false, ResponseCode: "99", Message: "declined"}, nil
+	}
+	return &services.RefundResult{Success: true, GatewayRef: "GW-" + req.RefundCode}, nil
+}
+
+func newRefundTestServer(store db.Z, gateway services.PaymentGateway) *Server {
+	gin.SetMode(gin.TestMode)
+	gateways := services.NewPaymentGatewayRegistry()
+	gateways.Register(gateway)
+	return &Server{z: store, gateways: gateways}
+}
+
+func callRefundHandler(t *testing.T, handler gin.HandlerFunc, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/api/admin/refunds/1", bytes.NewReader(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("claims", &utils.JwtClams{
+		Id:    pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		Email: "admin@travia.vn",
+	})
+	handler(c)
+	return w
+}
+
+// Cổng đã hoàn tiền nhưng cập nhật trạng thái lỗi: khoản treo ở dang_xuly không được gọi lại cổng khi thử lại,
+// chỉ được ghi nhận thủ công sau khi đối soát
+func TestRefundRetryDoesNotRefundTwiceAfterStatusUpdateFailure(t *testing.T) {
+	store := newFakeRefundStore("fakepay")
+	gateway := &fakeRefundGateway{}
+	s := newRefundTestServer(store, gateway)
+
+	store.completeErr = errors.New("connection reset")
+	w := callRefundHandler(t, s.ApproveRefund, nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("approve: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if gateway.calls != 1 || store.status() != db.TrangThaiThanhToanDangXuly {
+		t.Fatalf("approve: gateway calls = %d, status = %s; want 1, dang_xuly", gateway.calls, store.status())
+	}
+
+	// Khoản hoàn tiền bị treo quá 10 phút, DB đã hoạt động lại
+	store.completeErr = nil
+	store.refund.NgayDuyet.Time = time.Now().Add(-11 * time.Minute)
+
+	w = callRefundHandler(t, s.RetryRefund, nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("retry: status = %d, want 409, body = %s", w.Code, w.Body.String())
+	}
+	if gateway.calls != 1 {
+		t.Fatalf("retry: gateway calls = %d, want 1", gateway.calls)
+	}
+
+	ref := "GW-RF1"
+	w = callRefundHandler(t, s.RetryRefund, ProcessRefundRequest{ThuCong: true, MaThamChieu: &ref})
+	if w.Code != http.StatusOK {
+		t.Fatalf("manual retry: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if gateway.calls != 1 {
+		t.Fatalf("manual retry: gateway calls = %d, want 1", gateway.calls)
+	}
+	if store.status() != db.TrangThaiThanhToanDaHoanTien {
+		t.Fatalf("status = %s, want da_hoan_tien", store.status())
+	}
+	if got := store.refund.MaThamChieuCongThanhToan; got == nil || *got != ref {
+		t.Fatalf("ma_tham_chieu = %v, want %s", got, ref)
+	}
+}
+
+func TestRefundRetryAfterGatewayFailure(t *testing.T) {
+	store := newFakeRefundStore("fakepay")
+	gateway := &fakeRefundGateway{fail: true}
+	s := newRefundTestServer(store, gateway)
+
+	w := callRefundHandler(t, s.ApproveRefund, nil)
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("approve: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if store.status() != db.TrangThaiThanhToanThatBai {
+		t.Fatalf("approve: status = %s, want that_bai", store.status())
+	}
+
+	gateway.fail = false
+	w = callRefundHandler(t, s.RetryRefund, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("retry: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if gateway.calls != 2 || store.status() != db.TrangThaiThanhToanDaHoanTien {
+		t.Fatalf("retry: gateway calls = %d, status = %s; want 2, da_hoan_tien", gateway.calls, store.status())
+	}
+}
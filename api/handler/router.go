@@ -17,6 +17,9 @@ func (s *Server) SetupRoutes() {
 		c.JSON(200, gin.H{"message": "Travia API is running"})
 	})
 
+	// Khóa công khai để các dịch vụ khác tự xác thực access token
+	s.router.GET("/.well-known/jwks.json", s.GetJWKS)
+
 	api := s.router.Group("/api")
 	auth := api.Group("/auth")
 	{
@@ -34,6 +37,9 @@ func (s *Server) SetupRoutes() {
 		auth.POST("/forgot-password/request", middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute), s.RequestPasswordReset) // Step 1: Request OTP
 		auth.POST("/forgot-password/verify", middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute), s.VerifyOTP)             // Step 2: Verify OTP
 		auth.POST("/forgot-password/reset", middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute), s.ResetPassword)          // Step 3: Reset password
+		// Bước 2 đăng nhập khi tài khoản bật 2FA (dùng challenge token thay cho access token)
+		auth.POST("/2fa/challenge/setup", middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute), s.TwoFactorChallengeSetup)
+		auth.POST("/2fa/challenge/verify", middleware.RateLimitMiddleware(s.redis, 15, 1*time.Minute), s.TwoFactorChallengeVerify)
 		// Protected
 		authAuth := auth.Group("")
 		authAuth.Use(middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret)) // Chỉ đọc từ Authorization header
@@ -41,13 +47,39 @@ func (s *Server) SetupRoutes() {
 			authAuth.GET("/getUserById/:id", s.GetUserById)
 			authAuth.PUT("/updateUser", s.UpdateUser)
 			authAuth.POST("/logout", s.Logout)
+			authAuth.GET("/sessions", s.GetMySessions)
+			authAuth.DELETE("/sessions", s.RevokeOtherSessions)
+			authAuth.DELETE("/sessions/:id", s.RevokeMySession)
+			authAuth.GET("/login-history", s.GetMyLoginHistory)
+			authAuth.GET("/permissions", s.GetMyPermissions)
 			authAuth.PUT("/updateUserById/:id", middleware.SelfOrRoles("quan_tri"), s.UpdateUserById)
 			authAuth.PUT("/changePassword", s.ChangePassword) // Cần xác thực để đổi mật khẩu
+			// Dữ liệu cá nhân: tải về và yêu cầu xóa tài khoản
+			authAuth.GET("/account/export", middleware.RateLimitMiddleware(s.redis, 5, 1*time.Hour), s.ExportMyData)
+			authAuth.GET("/account/deletion", s.GetMyAccountDeletion)
+			authAuth.POST("/account/deletion", middleware.RequireRoles("khach_hang"), middleware.RateLimitMiddleware(s.redis, 5, 1*time.Minute), s.RequestAccountDeletion)
+			authAuth.DELETE("/account/deletion", s.CancelAccountDeletion)
+			// Xác thực hai bước (người dùng có quyền quản trị hoặc quyền nhà cung cấp)
+			authAuth.GET("/2fa/status", s.TwoFactorEligible(), s.GetTwoFactorStatus)
+			authAuth.POST("/2fa/setup", s.TwoFactorEligible(), s.SetupTwoFactor)
+			authAuth.POST("/2fa/enable", s.TwoFactorEligible(), s.EnableTwoFactor)
+			authAuth.POST("/2fa/disable", s.TwoFactorEligible(), s.DisableTwoFactor)
+			authAuth.POST("/2fa/recovery-codes", s.TwoFactorEligible(), s.RegenerateRecoveryCodes)
 		}
 		oauth := auth.Group("/oauth")
 		{
 			oauth.GET("/:provider", s.AuthHandler())
 			oauth.GET("/:provider/callback", s.AuthCallbackHandler())
+			oauth.POST("/:provider/callback", s.AuthCallbackHandler()) // Apple Sign-In dùng response_mode=form_post
+			oauth.POST("/link/confirm", middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute), s.ConfirmOAuthLink)
+
+			oauthAuth := oauth.Group("")
+			oauthAuth.Use(middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret))
+			{
+				oauthAuth.GET("/accounts", s.GetMyOAuthAccounts)
+				oauthAuth.POST("/:provider/link", middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute), s.LinkOAuthProvider)
+				oauthAuth.DELETE("/:provider", s.UnlinkOAuthProvider)
+			}
 		}
 	}
 
@@ -89,112 +121,357 @@ func (s *Server) SetupRoutes() {
 			// middleware.CacheMiddleware(s.redis, 10*time.Minute),
 			s.SearchTours,
 		)
+		tour.GET("/search/faceted",
+			middleware.CacheMiddleware(s.redis, 5*time.Minute),
+			s.SearchToursFaceted,
+		)
+		tour.GET("/search/semantic",
+			middleware.CacheMiddleware(s.redis, 5*time.Minute),
+			s.SearchToursSemantic,
+		)
+		tour.GET("/autocomplete",
+			middleware.CacheMiddleware(s.redis, time.Minute),
+			s.Autocomplete,
+		)
+		tour.POST("/autocomplete/select",
+			middleware.RateLimitMiddleware(s.redis, 30, 1*time.Minute),
+			s.SelectAutocompleteSuggestion,
+		)
+		tour.GET("/nearby",
+			middleware.CacheMiddleware(s.redis, 5*time.Minute),
+			s.GetToursNearby,
+		)
+		tour.GET("/in-bounds",
+			middleware.CacheMiddleware(s.redis, 5*time.Minute),
+			s.GetToursInBounds,
+		)
 		tour.POST("/",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
 			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:GET:/tour*"),
 			s.CreateTour,
 		)
-		tour.GET("/discount/:id", middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret), s.GetDiscountsByTourID)
-		tour.POST("/discount", middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret), s.CreateDiscountTour)
-		tour.PUT("/discount", middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret), s.UpdateDiscountTour)
-		tour.DELETE("/discount/:id", middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret), s.DeleteDiscountTour)
+		tour.GET("/discount/:id",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.GetDiscountsByTourID,
+		)
+		tour.POST("/discount",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.CreateDiscountTour,
+		)
+		tour.PUT("/discount",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.UpdateDiscountTour,
+		)
+		tour.DELETE("/discount/:id",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.DeleteDiscountTour,
+		)
 		tour.PUT("/:id",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
 			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:GET:/tour*"),
 			s.UpdateTour,
 		)
+		tour.PUT("/:id/full",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:GET:/tour*"),
+			s.UpdateTourFull,
+		)
 	}
 	// ========== ADMIN ROUTES (with short cache for fresh stats) ==========
 	admin := api.Group("/admin")
+	// Mỗi route kiểm tra quyền riêng để có thể cấp từng phần (vd: hỗ trợ, kế toán) qua vai trò trong DB
 	admin.Use(
 		middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-		middleware.RequireRoles("quan_tri"),
 	)
 	{
 		admin.GET("/supplierOptions",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.GetSupplierOptions,
 		)
 		admin.GET("/getDashboardOverview",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.GetDashboardOverview,
 		)
 		admin.GET("/getDashboardOverviewByMonthAndYear",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.GetDashboardOverviewByMonthAndYear,
 		)
 		admin.GET("/getUserStatsByRole",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.GetUserStatsByRole,
 		)
 
 		admin.GET("/getTopBookedTours",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.GetTopBookedTours,
 		)
 		admin.GET("/getTourPriceDistribution",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.GetTourPriceDistribution,
 		)
 		admin.GET("/getRevenueByDay",
+			middleware.RequirePermission(middleware.PermRevenueView),
 			s.GetRevenueByDay,
 		)
 		admin.GET("/getBookingsByDayOfWeek",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.GetBookingsByDayOfWeek,
 		)
 		admin.GET("/getRecentBookings",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.GetRecentBookings,
 		)
 		admin.GET("/transactions",
+			middleware.RequirePermission(middleware.PermRevenueView),
 			s.GetTransactions,
 		)
 		admin.GET("/chartRevenueTrend",
+			middleware.RequirePermission(middleware.PermRevenueView),
 			s.AdminChartRevenueTrend,
 		)
 		admin.GET("/chartCategoryDistribution",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.AdminChartCategoryDistribution,
 		)
 		admin.GET("/chartTopSuppliers",
+			middleware.RequirePermission(middleware.PermRevenueView),
 			s.AdminChartTopSuppliers,
 		)
 		admin.GET("/chartBookingStatusStats",
+			middleware.RequirePermission(middleware.PermDashboardView),
 			s.AdminChartBookingStatusStats,
 		)
 
 		//=====================================Nhà cung cấp=====================================
 		admin.GET("/suppliers",
+			middleware.RequirePermission(middleware.PermSupplierManage),
 			s.GetAllSuppliers,
 		)
 		admin.PUT("/suppliers/approve/:id",
+			middleware.RequirePermission(middleware.PermSupplierManage),
 			s.ApproveSupplier,
 		)
 		admin.PUT("/suppliers/reject/:id",
+			middleware.RequirePermission(middleware.PermSupplierManage),
 			s.RejectSupplier,
 		)
 		admin.DELETE("/suppliers/soft-delete/:id",
+			middleware.RequirePermission(middleware.PermSupplierManage),
 			s.SoftDeleteSupplier,
 		)
 		admin.PUT("/suppliers/restore/:id",
+			middleware.RequirePermission(middleware.PermSupplierManage),
 			s.RestoreSupplier,
 		)
 		admin.GET("/suppliers/:id",
+			middleware.RequirePermission(middleware.PermSupplierManage),
 			s.GetSupplierByID,
 		)
+		//=====================================Kiểm duyệt tour=====================================
+		admin.GET("/tour-revisions",
+			middleware.RequirePermission(middleware.PermTourModerate),
+			s.GetTourRevisionQueue,
+		)
+		admin.GET("/tour-revisions/:id",
+			middleware.RequirePermission(middleware.PermTourModerate),
+			s.GetTourRevisionDetail,
+		)
+		admin.PUT("/tour-revisions/:id/approve",
+			middleware.RequirePermission(middleware.PermTourModerate),
+			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:GET:/tour*"),
+			s.ApproveTourRevision,
+		)
+		admin.PUT("/tour-revisions/:id/reject",
+			middleware.RequirePermission(middleware.PermTourModerate),
+			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:GET:/tour*"),
+			s.RejectTourRevision,
+		)
+		//=====================================Chi trả nhà cung cấp=====================================
+		admin.GET("/payouts",
+			middleware.RequirePermission(middleware.PermPayoutManage),
+			s.GetPayouts,
+		)
+		admin.POST("/payouts/sync",
+			middleware.RequirePermission(middleware.PermPayoutManage),
+			s.SyncPayouts,
+		)
+		admin.PUT("/payouts/batch",
+			middleware.RequirePermission(middleware.PermPayoutManage),
+			s.BatchUpdatePayouts,
+		)
+		//=====================================Đối soát chuyển khoản=====================================
+		admin.GET("/bank-statements",
+			middleware.RequirePermission(middleware.PermBankStatement),
+			s.GetBankStatementLines,
+		)
+		admin.POST("/bank-statements/upload",
+			middleware.RequirePermission(middleware.PermBankStatement),
+			s.UploadBankStatement,
+		)
+		admin.POST("/bank-statements/reconcile",
+			middleware.RequirePermission(middleware.PermBankStatement),
+			s.ReconcileBankStatementsHandler,
+		)
 		//=====================================Khách hàng=====================================
 		admin.GET("/customers/getTopActiveUsers",
+			middleware.RequirePermission(middleware.PermCustomerView),
 			s.GetTopActiveUsers,
 		)
 		admin.GET("/customers/adminCustomerGrowthMonthlyReport",
+			middleware.RequirePermission(middleware.PermCustomerView),
 			s.AdminCustomerGrowthMonthlyReport,
 		)
+		admin.DELETE("/users/:id/sessions",
+			middleware.RequirePermission(middleware.PermUserSecurity),
+			s.AdminRevokeUserSessions,
+		)
+		admin.GET("/users/:id/login-history",
+			middleware.RequirePermission(middleware.PermCustomerView),
+			s.AdminGetUserLoginHistory,
+		)
+		admin.DELETE("/users/:id/login-lockout",
+			middleware.RequirePermission(middleware.PermUserSecurity),
+			s.AdminUnlockUserLogin,
+		)
+		admin.GET("/2fa/policies",
+			middleware.RequirePermission(middleware.PermUserSecurity),
+			s.GetTwoFactorPolicies,
+		)
+		admin.PUT("/2fa/policies/:vai_tro",
+			middleware.RequirePermission(middleware.PermUserSecurity),
+			s.UpdateTwoFactorPolicy,
+		)
 		//=====================================Booking Management=====================================
 		admin.GET("/bookings",
+			middleware.RequirePermission(middleware.PermBookingView),
 			s.GetAllBookingsForAdmin,
 		)
 		admin.GET("/bookings/statistics",
+			middleware.RequirePermission(middleware.PermBookingView),
 			s.GetAdminBookingStatistics,
 		)
 		//=====================================Refund Management=====================================
 		admin.GET("/refunds",
+			middleware.RequirePermission(middleware.PermRefundView),
 			s.GetAllRefunds,
 		)
 		admin.GET("/refunds/stats",
+			middleware.RequirePermission(middleware.PermRefundView),
 			s.GetRefundStats,
 		)
+		admin.GET("/refunds/transactions",
+			middleware.RequirePermission(middleware.PermRefundView),
+			s.GetRefundTransactions,
+		)
+		admin.PUT("/refunds/:id/approve",
+			middleware.RequirePermission(middleware.PermRefundManage),
+			s.ApproveRefund,
+		)
+		admin.PUT("/refunds/:id/retry",
+			middleware.RequirePermission(middleware.PermRefundManage),
+			s.RetryRefund,
+		)
+		admin.PUT("/refunds/:id/reject",
+			middleware.RequirePermission(middleware.PermRefundManage),
+			s.RejectRefund,
+		)
+		//=====================================Phân quyền=====================================
+		admin.GET("/permissions",
+			middleware.RequirePermission(middleware.PermRoleManage),
+			s.GetPermissions,
+		)
+		admin.GET("/roles",
+			middleware.RequirePermission(middleware.PermRoleManage),
+			s.GetRoles,
+		)
+		admin.POST("/roles",
+			middleware.RequirePermission(middleware.PermRoleManage),
+			s.CreateRole,
+		)
+		admin.PUT("/roles/:ma",
+			middleware.RequirePermission(middleware.PermRoleManage),
+			s.UpdateRole,
+		)
+		admin.PUT("/roles/:ma/permissions",
+			middleware.RequirePermission(middleware.PermRoleManage),
+			s.SetRolePermissions,
+		)
+		admin.DELETE("/roles/:ma",
+			middleware.RequirePermission(middleware.PermRoleManage),
+			s.DeleteRole,
+		)
+		admin.GET("/users/:id/roles",
+			middleware.RequirePermission(middleware.PermRoleManage),
+			s.GetUserRoles,
+		)
+		admin.PUT("/users/:id/roles",
+			middleware.RequirePermission(middleware.PermRoleManage),
+			s.SetUserRoles,
+		)
+		//=====================================Nhật ký kiểm toán=====================================
+		admin.GET("/audit-logs",
+			middleware.RequirePermission(middleware.PermAuditView),
+			s.GetAuditLogs,
+		)
+		admin.GET("/audit-logs/export",
+			middleware.RequirePermission(middleware.PermAuditView),
+			s.ExportAuditLogs,
+		)
+		//=====================================Đối tác & khóa API=====================================
+		admin.GET("/partners",
+			middleware.RequirePermission(middleware.PermPartnerManage),
+			s.GetPartners,
+		)
+		admin.POST("/partners",
+			middleware.RequirePermission(middleware.PermPartnerManage),
+			s.CreatePartner,
+		)
+		admin.GET("/partners/commissions",
+			middleware.RequirePermission(middleware.PermPartnerManage),
+			s.GetPartnerCommissions,
+		)
+		admin.GET("/partners/:id",
+			middleware.RequirePermission(middleware.PermPartnerManage),
+			s.GetPartner,
+		)
+		admin.PUT("/partners/:id",
+			middleware.RequirePermission(middleware.PermPartnerManage),
+			s.UpdatePartner,
+		)
+		admin.POST("/partners/:id/api-keys",
+			middleware.RequirePermission(middleware.PermPartnerManage),
+			s.CreatePartnerApiKey,
+		)
+		admin.DELETE("/partners/:id/api-keys/:key_id",
+			middleware.RequirePermission(middleware.PermPartnerManage),
+			s.RevokePartnerApiKey,
+		)
+		admin.GET("/partners/:id/api-keys/:key_id/usage",
+			middleware.RequirePermission(middleware.PermPartnerManage),
+			s.GetPartnerApiKeyUsage,
+		)
+		admin.GET("/jwt-keys",
+			middleware.RequirePermission(middleware.PermJwtKeyManage),
+			s.GetJwtSigningKeys,
+		)
+		admin.POST("/jwt-keys/rotate",
+			middleware.RequirePermission(middleware.PermJwtKeyManage),
+			s.RotateJwtSigningKey,
+		)
 	}
 	// ========== DESTINATION ROUTES (with Redis caching) ==========
 	destination := api.Group("/destination")
@@ -219,6 +496,14 @@ func (s *Server) SetupRoutes() {
 			// middleware.CacheMiddleware(s.redis, 1*time.Hour),
 			s.GetTopPopularDestinations,
 		)
+		destination.GET("/nearby",
+			middleware.CacheMiddleware(s.redis, 30*time.Minute),
+			s.GetDestinationsNearby,
+		)
+		destination.GET("/in-bounds",
+			middleware.CacheMiddleware(s.redis, 30*time.Minute),
+			s.GetDestinationsInBounds,
+		)
 		destination.GET("/:id",
 			// middleware.CacheMiddleware(s.redis, 1*time.Hour),
 			s.GetDestinationByID,
@@ -236,7 +521,7 @@ func (s *Server) SetupRoutes() {
 			destWrite.POST("/createDestination", s.CreateDestination)
 			destWrite.PUT("/:id/image",
 				middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-				middleware.RequireRoles("quan_tri"),
+				middleware.RequirePermission(middleware.PermDestinationManage),
 				s.UpdateDestinationImage,
 			)
 		}
@@ -250,101 +535,213 @@ func (s *Server) SetupRoutes() {
 		// Create supplier - Admin only
 		supplier.POST("/createSupplier",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("quan_tri"),
+			middleware.RequirePermission(middleware.PermSupplierManage),
 			s.CreateSupplier,
 		)
 
 		// Specific routes must be defined before parameterized routes
 		supplier.GET("/tours/my",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
 			//middleware.CacheMiddleware(s.redis, 30*time.Minute),
 			s.GetMyTours,
 		)
 		supplier.GET("/info",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierProfileView),
+			s.SupplierContext(),
 			s.GetInfoSupplier,
 		)
 
 		// Dashboard routes - must be before parameterized routes
 		supplier.GET("/dashboard/overview",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierDashboardOverview,
 		)
 		supplier.GET("/dashboard/revenue-by-time",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierRevenueView),
+			s.SupplierContext(),
 			s.GetSupplierRevenueByTimeRange,
 		)
 		supplier.GET("/dashboard/top-tours",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierTopTours,
 		)
 		supplier.GET("/dashboard/booking-stats",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierBookingStatsByStatus,
 		)
 		supplier.GET("/dashboard/tour-stats",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierTourStatsByStatus,
 		)
 		supplier.GET("/dashboard/tour-stats-by-category",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierTourStatsByCategory,
 		)
 		supplier.GET("/dashboard/revenue-chart",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierRevenueView),
+			s.SupplierContext(),
 			s.GetSupplierRevenueChart,
 		)
 		supplier.GET("/dashboard/customer-stats",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierCustomerStats,
 		)
 
 		supplier.GET("/dashboard/rating-analysis",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierRatingAnalysis,
 		)
 		supplier.GET("/dashboard/upcoming-departures",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierUpcomingDepartures,
 		)
 		supplier.GET("/dashboard/recent-bookings",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierBookingView),
+			s.SupplierContext(),
 			s.GetSupplierRecentBookings,
 		)
 		supplier.GET("/revenue/statistics",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierRevenueView),
+			s.SupplierContext(),
 			s.GetSupplierRevenueStatistics,
 		)
 		supplier.GET("/revenue/transactions",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierRevenueView),
+			s.SupplierContext(),
 			s.GetSupplierTransactions,
 		)
+		supplier.GET("/revenue/payouts",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierRevenueView),
+			s.SupplierContext(),
+			s.GetMyPayouts,
+		)
+		supplier.GET("/revenue/payouts/summary",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierRevenueView),
+			s.SupplierContext(),
+			s.GetMyPayoutSummary,
+		)
+		//=====================================Tài khoản ngân hàng=====================================
+		supplier.GET("/bank-accounts",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierBankAccount),
+			s.SupplierContext(),
+			s.GetMyBankAccounts,
+		)
+		supplier.POST("/bank-accounts",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierBankAccount),
+			s.SupplierContext(),
+			s.CreateMyBankAccount,
+		)
+		supplier.PUT("/bank-accounts/:id/default",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierBankAccount),
+			s.SupplierContext(),
+			s.SetMyDefaultBankAccount,
+		)
+		supplier.DELETE("/bank-accounts/:id",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierBankAccount),
+			s.SupplierContext(),
+			s.DeleteMyBankAccount,
+		)
+		//=====================================Chính sách hủy=====================================
+		supplier.GET("/cancellation-policies",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.GetMyCancellationPolicies,
+		)
+		supplier.POST("/cancellation-policies",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.CreateMyCancellationPolicy,
+		)
+		supplier.PUT("/cancellation-policies/:id",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.UpdateMyCancellationPolicy,
+		)
+		supplier.DELETE("/cancellation-policies/:id",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.DeleteMyCancellationPolicy,
+		)
+		supplier.PUT("/tours/:id/cancellation-policy",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.SetTourCancellationPolicy,
+		)
+		supplier.PUT("/departures/:id/cancellation-policy",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.SetDepartureCancellationPolicy,
+		)
 		// Advanced bookings query - must be before parameterized routes
 		supplier.GET("/bookings/advanced",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierBookingView),
+			s.SupplierContext(),
 			s.GetSupplierBookingsByStatusAdvanced,
 		)
 		supplier.PUT("/tours/update-status/:id",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
 			s.UpdateTourStatus,
 		)
+		// Kiểm duyệt tour: gửi duyệt, rút phiên bản chờ duyệt, lịch sử phiên bản
+		supplier.POST("/tours/:id/submit",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:GET:/tour*"),
+			s.SubmitTourForReview,
+		)
+		supplier.GET("/tours/:id/revisions",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			s.GetTourRevisionHistory,
+		)
+		supplier.DELETE("/tours/:id/revisions/pending",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierTourManage),
+			s.SupplierContext(),
+			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:GET:/tour*"),
+			s.WithdrawTourRevision,
+		)
 		supplier.GET("/search/:keyword",
 			middleware.CacheMiddleware(s.redis, 10*time.Minute),
 			s.SearchSuppliers,
@@ -360,28 +757,74 @@ func (s *Server) SetupRoutes() {
 
 		supplier.GET("/dashboard/review-statistics",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetSupplierReviewStatistics,
 		)
 		supplier.GET("/dashboard/reviews",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierDashboardView),
+			s.SupplierContext(),
 			s.GetDetailedSupplierReviews,
 		)
 		supplier.GET("/dashboard/options-tour",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierProfileView),
+			s.SupplierContext(),
 			s.GetOptionTour,
 		)
+		supplier.POST("/dashboard/feedback-review/:danh_gia_id",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierReviewReply),
+			s.SupplierContext(),
+			s.FeedbackReview,
+		)
+		//=====================================Nhân viên nhà cung cấp=====================================
+		supplier.POST("/staff/invitations/accept",
+			middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute),
+			s.AcceptSupplierStaffInvitation,
+		)
+		supplier.GET("/staff",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierStaffManage),
+			s.SupplierContext(),
+			s.GetSupplierStaff,
+		)
+		supplier.POST("/staff",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierStaffManage),
+			s.SupplierContext(),
+			s.InviteSupplierStaff,
+		)
+		supplier.PUT("/staff/:id",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierStaffManage),
+			s.SupplierContext(),
+			s.UpdateSupplierStaff,
+		)
+		supplier.DELETE("/staff/:id",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierStaffManage),
+			s.SupplierContext(),
+			s.RemoveSupplierStaff,
+		)
+		supplier.POST("/staff/:id/resend",
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			middleware.RequirePermission(middleware.PermSupplierStaffManage),
+			s.SupplierContext(),
+			s.ResendSupplierStaffInvitation,
+		)
 		//=====================================Refund Management=====================================
 		supplier.GET("/refunds",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierRevenueView),
+			s.SupplierContext(),
 			s.GetSupplierRefunds,
 		)
 		supplier.GET("/refunds/stats",
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("nha_cung_cap"),
+			middleware.RequirePermission(middleware.PermSupplierRevenueView),
+			s.SupplierContext(),
 			s.GetSupplierRefundStats,
 		)
 
@@ -413,16 +856,35 @@ func (s *Server) SetupRoutes() {
 	// ========== PAYMENT ROUTES (with rate limiting) ==========
 	payment := api.Group("/payment")
 	{
-		vnpay := payment.Group("/vnpay")
+		// Cổng thanh toán đăng ký trong registry (:gateway = cong_thanh_toan.id)
+		payment.POST("/:gateway/create",
+			middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute),
+			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			s.CreatePayment,
+		)
+		payment.GET("/:gateway/return", s.PaymentReturn)
+		payment.GET("/:gateway/verify", s.PaymentVerify)
+		payment.GET("/:gateway/ipn", s.PaymentIPN)
+		payment.POST("/:gateway/ipn", s.PaymentIPN)
+
+		bankTransfer := payment.Group("/bank-transfer")
+		bankTransfer.Use(middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret))
 		{
-			vnpay.POST("/create",
+			bankTransfer.POST("/create",
+				middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute),
+				s.CreateBankTransfer,
+			)
+			bankTransfer.GET("/:transaction_code/qr.png", s.GetBankTransferQR)
+		}
+
+		stripe := payment.Group("/stripe")
+		{
+			stripe.POST("/create",
 				middleware.RateLimitMiddleware(s.redis, 10, 1*time.Minute),
 				middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-				s.CreateVNPayPaymentURL,
+				s.CreateStripePaymentIntent,
 			)
-			vnpay.GET("/return", s.VNPayCallback)
-			vnpay.GET("/verify", s.VNPayVerifyCallback)
-			vnpay.POST("/ipn", s.VNPayIPN)
+			stripe.POST("/webhook", s.StripeWebhook)
 		}
 	}
 
@@ -433,7 +895,7 @@ func (s *Server) SetupRoutes() {
 		contactAdmin := contact.Group("")
 		contactAdmin.Use(
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("quan_tri"),
+			middleware.RequirePermission(middleware.PermContactManage),
 		)
 		{
 			contactAdmin.GET("", s.GetAllContacts)
@@ -468,8 +930,14 @@ func (s *Server) SetupRoutes() {
 		bookingAuth := booking.Group("")
 		bookingAuth.Use(middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret))
 		{
+			bookingAuth.POST("/hold-seat/:khoi_hanh_id/:so_nguoi_lon/:so_tre_em", middleware.RateLimitMiddleware(s.redis, 30, 1*time.Minute), s.HoldSeat)
+			bookingAuth.GET("/hold/:ma_giu_cho", s.GetSeatHold)
+			bookingAuth.DELETE("/hold/:ma_giu_cho", s.CancelSeatHold)
 			bookingAuth.POST("/create", middleware.RateLimitMiddleware(s.redis, 20, 1*time.Minute), s.CreateBooking)
 			bookingAuth.POST("/add-passengers", s.AddPassengers)
+			bookingAuth.POST("/waitlist/:khoi_hanh_id", middleware.RateLimitMiddleware(s.redis, 20, 1*time.Minute), s.JoinWaitlist)
+			bookingAuth.GET("/waitlist", s.GetMyWaitlist)
+			bookingAuth.DELETE("/waitlist/:id", s.LeaveWaitlist)
 			bookingAuth.GET("/:id", s.GetBookingById)
 			bookingAuth.GET("/:id/calculate-refund", s.CalculateRefundAmount)
 			bookingAuth.PUT("/:id/cancel", s.CancelBooking)
@@ -480,9 +948,27 @@ func (s *Server) SetupRoutes() {
 			bookingAuth.DELETE("/:id", s.DeleteBooking)
 			bookingAuth.DELETE("/delete-bookings", s.DeleteBookings)
 		}
+	}
 
-		// Public endpoints (no auth required) - Đăng ký sau
-		booking.POST("/hold-seat/:khoi_hanh_id/:so_nguoi_lon/:so_tre_em", middleware.RateLimitMiddleware(s.redis, 30, 1*time.Minute), s.HoldSeat)
+	// ========== PARTNER API ROUTES (khóa API của đối tác) ==========
+	// Xác thực bằng header X-API-Key; booking tạo qua đây đứng tên tài khoản dịch vụ và được ghi nhận cho đối tác.
+	// Không cache để đối tác luôn thấy số chỗ trống mới nhất
+	partnerAPI := api.Group("/partner/v1")
+	partnerAPI.Use(
+		middleware.ApiKeyMiddleware(s.redis),
+		middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+	)
+	{
+		partnerAPI.GET("/tours/search", middleware.RequireScope(middleware.ScopeToursRead), s.SearchTours)
+		partnerAPI.GET("/tours/:id", middleware.RequireScope(middleware.ScopeToursRead), s.GetTourDetailByID)
+		partnerAPI.GET("/departures/tour/:tour_id", middleware.RequireScope(middleware.ScopeDeparturesRead), s.GetDeparturesByTour)
+		partnerAPI.GET("/departures/:id", middleware.RequireScope(middleware.ScopeDeparturesRead), s.GetDepartureByID)
+		partnerAPI.POST("/bookings/hold-seat/:khoi_hanh_id/:so_nguoi_lon/:so_tre_em", middleware.RequireScope(middleware.ScopeBookingsWrite), s.HoldSeat)
+		partnerAPI.GET("/bookings/hold/:ma_giu_cho", middleware.RequireScope(middleware.ScopeBookingsRead), s.GetSeatHold)
+		partnerAPI.DELETE("/bookings/hold/:ma_giu_cho", middleware.RequireScope(middleware.ScopeBookingsWrite), s.CancelSeatHold)
+		partnerAPI.POST("/bookings", middleware.RequireScope(middleware.ScopeBookingsWrite), s.CreateBooking)
+		partnerAPI.POST("/bookings/passengers", middleware.RequireScope(middleware.ScopePassengersWrite), s.AddPassengers)
+		partnerAPI.GET("/bookings/:id", middleware.RequireScope(middleware.ScopeBookingsRead), s.GetBookingById)
 	}
 
 	// ========== DEPARTURE ROUTES (Tour schedule management) ==========
@@ -506,48 +992,49 @@ func (s *Server) SetupRoutes() {
 		departureWrite := departure.Group("")
 		departureWrite.Use(
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
+			s.SupplierContext(),
 			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:*departure*"),
 		)
 		{
-			// Admin/Supplier only
+			// Supplier only: chỉ sửa tour / lịch khởi hành của nhà cung cấp mình
 			departureWrite.POST("/create",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.CreateDeparture,
 			)
 			departureWrite.PUT("/:id",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.UpdateDeparture,
 			)
 			departureWrite.DELETE("/:id",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.DeleteDeparture,
 			)
 			departureWrite.PUT("/:id/cancel",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.CancelDeparture,
 			)
 			departureWrite.PUT("/lich-trinh/:id",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.UpdateLichTrinh,
 			)
 			departureWrite.PUT("/hoat-dong-trong-ngay/:id",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.UpdateHoatDongTrongNgay,
 			)
 			departureWrite.POST("/add-hinh-anh/:id",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.AddHinhAnhTour,
 			)
 			departureWrite.DELETE("/delete-hinh-anh/:id",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.DeleteHinhAnhTour,
 			)
 			departureWrite.POST("/add-tour-destination/:id",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.AddTourDestination,
 			)
 			departureWrite.DELETE("/delete-tour-destination/:tour_id/:diem_den_id",
-				middleware.RequireRoles("nha_cung_cap"),
+				middleware.RequirePermission(middleware.PermSupplierTourManage),
 				s.DeleteTourDestination,
 			)
 		}
@@ -581,7 +1068,7 @@ func (s *Server) SetupRoutes() {
 		imagesAuth := images.Group("")
 		imagesAuth.Use(
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("quan_tri"),
+			middleware.RequirePermission(middleware.PermDestinationManage),
 			middleware.InvalidateCacheMiddleware(s.redis, "cache:http:*destination*"),
 		)
 	}
@@ -615,7 +1102,7 @@ func (s *Server) SetupRoutes() {
 		blogAdmin := blog.Group("")
 		blogAdmin.Use(
 			middleware.AuthMiddleware(s.config.ServerConfig.ApiSecret),
-			middleware.RequireRoles("quan_tri"),
+			middleware.RequirePermission(middleware.PermBlogManage),
 		)
 		{
 			blogAdmin.GET("/admin", s.GetAllBlogsForAdmin)
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// SeatHoldDuration là thời gian giữ chỗ trước khi chỗ được trả lại
+	SeatHoldDuration = 15 * time.Minute
+	// SeatHoldSweepInterval là chu kỳ quét các giữ chỗ đã hết hạn
+	SeatHoldSweepInterval = 1 * time.Minute
+)
+
+// StartSeatHoldSweeper chạy định kỳ để trả lại chỗ của các giữ chỗ đã hết hạn
+// và mời danh sách chờ ở các khởi hành vừa có chỗ trống.
+// Dừng khi ctx bị hủy.
+func (s *Server) StartSeatHoldSweeper(ctx context.Context) {
+	ticker := time.NewTicker(SeatHoldSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.releaseExpiredSeatHolds(ctx)
+			s.processWaitlists(ctx)
+		}
+	}
+}
+
+func (s *Server) releaseExpiredSeatHolds(ctx context.Context) {
+	sweepCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	released, err := s.z.ReleaseExpiredSeatHolds(sweepCtx)
+	if err != nil {
+		log.Printf("[SeatHoldSweeper] release expired holds failed: %v", err)
+		return
+	}
+	for _, r := range released {
+		log.Printf("[SeatHoldSweeper] khoi_hanh_id=%d released %d seats", r.KhoiHanhID, r.SoChoGiaiPhong)
+	}
+}
+
+// parseSeatHoldCode đọc mã giữ chỗ từ path param
+func parseSeatHoldCode(c *gin.Context) (pgtype.UUID, bool) {
+	var maGiuCho pgtype.UUID
+	if err := maGiuCho.Scan(c.Param("ma_giu_cho")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mã giữ chỗ không hợp lệ"})
+		return maGiuCho, false
+	}
+	return maGiuCho, true
+}
+
+// GetSeatHold godoc
+// @Summary Get seat hold
+// @Description Lấy thông tin giữ chỗ (trạng thái, thời điểm hết hạn) của người dùng hiện tại
+// @Tags Booking
+// @Produce json
+// @Param ma_giu_cho path string true "Mã giữ chỗ"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /booking/hold/{ma_giu_cho} [get]
+func (s *Server) GetSeatHold(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
+	maGiuCho, ok := parseSeatHoldCode(c)
+	if !ok {
+		return
+	}
+
+	hold, err := s.z.GetSeatHoldByCode(ctx, maGiuCho)
+	if err != nil || hold.NguoiDungID != jwtClaims.Id {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy giữ chỗ"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Seat hold retrieved successfully",
+		"data":    hold,
+	})
+}
+
+// CancelSeatHold godoc
+// @Summary Cancel seat hold
+// @Description Hủy giữ chỗ đang hiệu lực và trả lại chỗ cho lịch khởi hành
+// @Tags Booking
+// @Produce json
+// @Param ma_giu_cho path string true "Mã giữ chỗ"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /booking/hold/{ma_giu_cho} [delete]
+func (s *Server) CancelSeatHold(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
+	maGiuCho, ok := parseSeatHoldCode(c)
+	if !ok {
+		return
+	}
+
+	hold, err := s.z.CancelSeatHold(ctx, db.CancelSeatHoldParams{
+		MaGiuCho:    maGiuCho,
+		NguoiDungID: jwtClaims.Id,
+	})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Không tìm thấy giữ chỗ đang hiệu lực",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Chỗ vừa được trả lại: mời lượt chờ tiếp theo
+	s.promoteWaitlistAsync(hold.KhoiHanhID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Seat hold cancelled successfully",
+		"data":    hold,
+	})
+}
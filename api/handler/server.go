@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"context"
 	"log"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 
+	"travia.backend/api/helpers"
+	"travia.backend/api/middleware"
+	"travia.backend/api/services"
 	"travia.backend/config"
 	db "travia.backend/db/sqlc"
 )
@@ -15,6 +20,14 @@ type Server struct {
 	router *gin.Engine
 	z      db.Z
 	redis  *redis.Client
+	stripe *helpers.StripeClient
+	// Registry cổng thanh toán theo cong_thanh_toan.id
+	gateways *services.PaymentGatewayRegistry
+	// Bộ tạo embedding cho tìm kiếm ngữ nghĩa
+	embedder services.Embedder
+	// Hàng đợi lượt gọi khóa API chờ ghi dồn vào DB
+	apiKeyUsage        chan int32
+	apiKeyUsageDropped atomic.Int64
 }
 
 func NewServer(config *config.Config, z db.Z, redisClient *redis.Client) *Server {
@@ -23,6 +36,8 @@ func NewServer(config *config.Config, z db.Z, redisClient *redis.Client) *Server
 		z:      z,
 		redis:  redisClient,
 		router: gin.Default(),
+
+		apiKeyUsage: make(chan int32, apiKeyUsageQueueSize),
 	}
 
 	// Disable trailing slash redirects to avoid 301 redirects
@@ -32,10 +47,32 @@ func NewServer(config *config.Config, z db.Z, redisClient *redis.Client) *Server
 	// Setup router components
 	server.SetupMiddlewares()
 	server.SetupAuthProviders()
-	//server.InitStripe() // Initialize Stripe
+	server.SetupTokenSigning()    // Issuer / audience và khóa ký JWT
+	server.InitStripe()           // Initialize Stripe
+	server.SetupPaymentGateways() // Đăng ký các cổng thanh toán
+	server.SetupEmbedder()        // Bộ tạo embedding cho tìm kiếm ngữ nghĩa
+	// AuthMiddleware từ chối access token của phiên đã đăng xuất / bị thu hồi
+	middleware.SetSessionValidator(server.isSessionActive)
+	// RequirePermission tra quyền theo vai trò cấu hình trong DB
+	middleware.SetPermissionResolver(server.userPermissions)
+	// ApiKeyMiddleware xác thực khóa API đối tác và đếm lượt gọi
+	middleware.SetApiKeyValidator(server.authenticateApiKey, server.recordApiKeyUsage)
 	server.SetupRoutes()
 	server.SetupSwagger()
 
+	// Tiến trình nền trả lại chỗ của các giữ chỗ đã hết hạn
+	go server.StartSeatHoldSweeper(context.Background())
+	// Tiến trình nền tính lại sổ chi trả nhà cung cấp
+	go server.StartPayoutSync(context.Background())
+	// Tiến trình nền xóa các tài khoản đã hết thời gian ân hạn
+	go server.StartAccountDeletionSweeper(context.Background())
+	// Tiến trình nền tải lại và xoay vòng khóa ký JWT
+	go server.StartJwtKeyRotation(context.Background())
+	// Tiến trình nền tạo lại embedding cho tour có nội dung thay đổi
+	go server.StartTourEmbeddingSync(context.Background())
+	// Tiến trình nền ghi dồn lượt gọi khóa API
+	go server.StartApiKeyUsageWriter(context.Background())
+
 	return server
 }
 
@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// sessionRevokedKeyPrefix đánh dấu phiên đã thu hồi trong Redis để AuthMiddleware từ chối ngay access token còn hạn
+const sessionRevokedKeyPrefix = "session:revoked:"
+
+// Lý do thu hồi phiên (phien_dang_nhap.ly_do_thu_hoi)
+const (
+	SessionRevokeLogout         = "dang_xuat"
+	SessionRevokeByUser         = "nguoi_dung_thu_hoi"
+	SessionRevokeTokenReuse     = "tai_su_dung_token"
+	SessionRevokePasswordChange = "doi_mat_khau"
+	SessionRevokePasswordReset  = "dat_lai_mat_khau"
+	SessionRevokeByAdmin        = "quan_tri_thu_hoi"
+	SessionRevokeStaffRemoved   = "nhan_vien_bi_vo_hieu"
+	SessionRevokeAccountDeleted = "xoa_tai_khoan"
+)
+
+// createSession tạo phiên đăng nhập mới cho thiết bị hiện tại và cấp cặp token gắn với phiên
+func (s *Server) createSession(c *gin.Context, userID pgtype.UUID, email, vaiTro string) (*utils.TokenPair, error) {
+	ctx := c.Request.Context()
+
+	sessionID, err := s.z.NextSessionID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate session id: %w", err)
+	}
+
+	tokenPair, err := utils.GenerateSessionToken(userID, email, vaiTro, sessionID, s.config.ServerConfig.ApiSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	thietBi := c.Request.UserAgent()
+	diaChiIP := c.ClientIP()
+	_, err = s.z.CreateSession(ctx, db.CreateSessionParams{
+		ID:              sessionID,
+		NguoiDungID:     userID,
+		MaTokenTruyCap:  utils.HashToken(tokenPair.AccessToken),
+		MaTokenLamMoi:   utils.HashToken(tokenPair.RefreshToken),
+		ThoiHanToken:    pgtype.Timestamp{Time: time.Now().Add(utils.RefreshTokenDuration), Valid: true},
+		ThongTinThietBi: &thietBi,
+		DiaChiIp:        &diaChiIP,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return tokenPair, nil
+}
+
+// isSessionActive dùng cho AuthMiddleware: phiên bị đánh dấu thu hồi trong Redis coi như hết hiệu lực,
+// Redis lỗi thì kiểm tra trực tiếp trong DB
+func (s *Server) isSessionActive(ctx context.Context, sessionID int32) bool {
+	n, err := s.redis.Exists(ctx, sessionRevokedKeyPrefix+strconv.Itoa(int(sessionID))).Result()
+	if err == nil {
+		return n == 0
+	}
+
+	active, err := s.z.IsSessionActive(ctx, sessionID)
+	if err != nil {
+		log.Printf("[Session] check session %d failed: %v", sessionID, err)
+		return false
+	}
+	return active
+}
+
+// markSessionsRevoked ghi dấu thu hồi vào Redis trong suốt thời hạn còn lại của access token.
+// AuthMiddleware chỉ dựa vào dấu này khi Redis hoạt động, nên ghi lỗi phải báo cho người gọi
+func (s *Server) markSessionsRevoked(ctx context.Context, sessionIDs ...int32) error {
+	for _, id := range sessionIDs {
+		if err := s.redis.SetEx(ctx, sessionRevokedKeyPrefix+strconv.Itoa(int(id)), 1, utils.AccessTokenDuration).Err(); err != nil {
+			return fmt.Errorf("failed to mark session %d revoked: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// revokeSession thu hồi một phiên của người dùng, trả về false nếu phiên không tồn tại hoặc đã bị thu hồi.
+// Dấu thu hồi trong Redis được ghi trước khi cập nhật DB để lỗi Redis không để lại phiên đã thu hồi mà token vẫn dùng được
+func (s *Server) revokeSession(ctx context.Context, userID pgtype.UUID, sessionID int32, lyDo string) (bool, error) {
+	session, err := s.z.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if session.NguoiDungID != userID || session.DangHoatDong == nil || !*session.DangHoatDong {
+		return false, nil
+	}
+	if err := s.markSessionsRevoked(ctx, sessionID); err != nil {
+		return false, err
+	}
+
+	n, err := s.z.RevokeSession(ctx, db.RevokeSessionParams{
+		ID:          sessionID,
+		NguoiDungID: userID,
+		LyDoThuHoi:  &lyDo,
+	})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// revokeUserSessions thu hồi mọi phiên của người dùng (trừ exceptID nếu khác nil), trả về số phiên bị thu hồi.
+// Các phiên đang hoạt động được đánh dấu trong Redis trước, phiên tạo thêm trong lúc thu hồi được đánh dấu sau
+func (s *Server) revokeUserSessions(ctx context.Context, userID pgtype.UUID, exceptID *int32, lyDo string) (int, error) {
+	active, err := s.z.GetActiveSessionsByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	marked := make(map[int32]bool, len(active))
+	for _, session := range active {
+		if exceptID != nil && session.ID == *exceptID {
+			continue
+		}
+		if err := s.markSessionsRevoked(ctx, session.ID); err != nil {
+			return 0, err
+		}
+		marked[session.ID] = true
+	}
+
+	ids, err := s.z.RevokeUserSessions(ctx, db.RevokeUserSessionsParams{
+		NguoiDungID: userID,
+		NgoaiTruID:  exceptID,
+		LyDoThuHoi:  &lyDo,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if marked[id] {
+			continue
+		}
+		if err := s.markSessionsRevoked(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+// currentSessionID trả về id phiên của access token hiện tại (nil nếu token không gắn phiên)
+func currentSessionID(claims *utils.JwtClams) *int32 {
+	if claims.SessionID == 0 {
+		return nil
+	}
+	id := claims.SessionID
+	return &id
+}
+
+// getAuthClaims lấy claims của người dùng đã đăng nhập (đã ghi response 401 nếu không có)
+func getAuthClaims(c *gin.Context) (*utils.JwtClams, bool) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return nil, false
+	}
+	return jwtClaims, true
+}
+
+// GetMySessions godoc
+// @Summary Get my sessions
+// @Description Danh sách thiết bị đang đăng nhập của người dùng hiện tại
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/sessions [get]
+func (s *Server) GetMySessions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := s.z.GetActiveSessionsByUser(ctx, jwtClaims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sessions", "details": err.Error()})
+		return
+	}
+
+	data := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, gin.H{
+			"id":                 session.ID,
+			"thong_tin_thiet_bi": session.ThongTinThietBi,
+			"dia_chi_ip":         session.DiaChiIp,
+			"thoi_han_token":     session.ThoiHanToken,
+			"lan_lam_moi_cuoi":   session.LanLamMoiCuoi,
+			"ngay_tao":           session.NgayTao,
+			"hien_tai":           session.ID == jwtClaims.SessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sessions retrieved successfully",
+		"data":    data,
+	})
+}
+
+// RevokeMySession godoc
+// @Summary Revoke session
+// @Description Đăng xuất một thiết bị của người dùng hiện tại
+// @Tags auth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/sessions/{id} [delete]
+func (s *Server) RevokeMySession(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	revoked, err := s.revokeSession(ctx, jwtClaims.Id, int32(sessionID), SessionRevokeByUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session", "details": err.Error()})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy phiên đăng nhập đang hoạt động"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã đăng xuất thiết bị"})
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke other sessions
+// @Description Đăng xuất tất cả thiết bị khác, giữ lại phiên hiện tại
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/sessions [delete]
+func (s *Server) RevokeOtherSessions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	count, err := s.revokeUserSessions(ctx, jwtClaims.Id, currentSessionID(jwtClaims), SessionRevokeByUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Đã đăng xuất các thiết bị khác",
+		"so_phien": count,
+	})
+}
+
+// AdminRevokeUserSessions godoc
+// @Summary Revoke all sessions of a user
+// @Description Admin đăng xuất người dùng khỏi mọi thiết bị (vd: tài khoản bị xâm nhập). Access token hiện có bị từ chối ngay
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/sessions [delete]
+func (s *Server) AdminRevokeUserSessions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var userID pgtype.UUID
+	if err := userID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if _, err := s.z.GetUserById(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy người dùng"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user", "details": err.Error()})
+		return
+	}
+
+	count, err := s.revokeUserSessions(ctx, userID, nil, SessionRevokeByAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Đã thu hồi mọi phiên đăng nhập của người dùng",
+		"so_phien": count,
+	})
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"travia.backend/api/helpers"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// StripePaymentReuseWindow là thời gian tái sử dụng PaymentIntent đang chờ của cùng booking
+const StripePaymentReuseWindow = 30 * time.Minute
+
+type CreateStripePaymentRequest struct {
+	BookingID int32 `json:"booking_id" binding:"required"`
+}
+
+// InitStripe khởi tạo Stripe client từ cấu hình (bỏ qua nếu chưa cấu hình STRIPE_SECRET_KEY)
+func (s *Server) InitStripe() {
+	if s.config.StripeConfig == nil || s.config.StripeConfig.SecretKey == "" {
+		fmt.Printf("⚠️  STRIPE_SECRET_KEY is not configured, Stripe payments are disabled\n")
+		return
+	}
+	s.stripe = helpers.NewStripeClient(s.config.StripeConfig.SecretKey, s.config.StripeConfig.APIBaseURL)
+}
+
+// CreateStripePaymentIntent tạo Stripe PaymentIntent cho booking
+// @Summary Tạo Stripe PaymentIntent
+// @Description Tạo PaymentIntent cho booking và ghi nhận giao dịch (cong_thanh_toan_id = stripe). Frontend dùng client_secret để xác nhận thanh toán
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param request body CreateStripePaymentRequest true "Payment Request"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /payment/stripe/create [post]
+func (s *Server) CreateStripePaymentIntent(c *gin.Context) {
+	if s.stripe == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Stripe payment is not configured"})
+		return
+	}
+
+	claims, ok := c.Get("claims")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	claimsMap, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req CreateStripePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	booking, err := s.z.GetBookingById(ctx, req.BookingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	if booking.NguoiDungID.String() != claimsMap.Id.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+	if booking.TrangThai.Valid && (booking.TrangThai.TrangThaiDatCho == "da_thanh_toan" || booking.TrangThai.TrangThaiDatCho == "hoan_thanh") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking already paid"})
+		return
+	}
+	if booking.TrangThai.Valid && booking.TrangThai.TrangThaiDatCho != "cho_xac_nhan" && booking.TrangThai.TrangThaiDatCho != "da_xac_nhan" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Booking không ở trạng thái hợp lệ để thanh toán",
+			"trang_thai": booking.TrangThai.TrangThaiDatCho,
+		})
+		return
+	}
+	if !booking.TongTien.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking không có số tiền hợp lệ"})
+		return
+	}
+
+	currency := s.config.StripeConfig.Currency
+	if booking.DonViTienTe != nil && *booking.DonViTienTe != "" {
+		currency = *booking.DonViTienTe
+	}
+	currency = strings.ToLower(currency)
+	tongTien, _ := booking.TongTien.Float64Value()
+	amount := helpers.ToStripeAmount(tongTien.Float64, currency)
+
+	// REUSE: PaymentIntent còn chờ và chưa quá hạn thì trả lại client_secret cũ
+	existingTransactions, err := s.z.GetTransactionsByBooking(ctx, &booking.ID)
+	if err == nil {
+		for _, tx := range existingTransactions {
+			if tx.CongThanhToanID == nil || *tx.CongThanhToanID != "stripe" || tx.MaThamChieuCongThanhToan == nil {
+				continue
+			}
+			if !tx.TrangThai.Valid || tx.TrangThai.TrangThaiThanhToan != "dang_cho_thanh_toan" {
+				continue
+			}
+			if !tx.NgayTao.Valid || time.Since(tx.NgayTao.Time) > StripePaymentReuseWindow {
+				continue
+			}
+			intent, err := s.stripe.GetPaymentIntent(ctx, *tx.MaThamChieuCongThanhToan)
+			if err == nil && intent.Amount == amount && intent.ClientSecret != "" &&
+				(intent.Status == "requires_payment_method" || intent.Status == "requires_confirmation" || intent.Status == "requires_action") {
+				c.JSON(http.StatusOK, gin.H{
+					"client_secret":     intent.ClientSecret,
+					"payment_intent_id": intent.ID,
+					"publishable_key":   s.config.StripeConfig.PublishableKey,
+					"transaction_code":  tx.MaGiaoDichNoiBo,
+					"booking_id":        booking.ID,
+				})
+				return
+			}
+		}
+	}
+
+	// CREATE NEW: ghi nhận giao dịch trước, sau đó tạo PaymentIntent với metadata trỏ về giao dịch
+	transactionCode := fmt.Sprintf("TRAVIA%d%d", booking.ID, time.Now().Unix())
+	congThanhToanID := "stripe"
+	noiDungChuyenKhoan := fmt.Sprintf("Thanh toan don dat cho #%d qua Stripe", booking.ID)
+	bookingID := booking.ID
+	transaction, err := s.z.CreateTransaction(ctx, db.CreateTransactionParams{
+		DatChoID:           &bookingID,
+		NguoiDungID:        booking.NguoiDungID,
+		MaGiaoDichNoiBo:    transactionCode,
+		CongThanhToanID:    &congThanhToanID,
+		SoTien:             booking.TongTien,
+		NoiDungChuyenKhoan: &noiDungChuyenKhoan,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Không thể tạo giao dịch thanh toán",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	intent, err := s.stripe.CreatePaymentIntent(ctx, amount, currency, map[string]string{
+		"transaction_code": transactionCode,
+		"booking_id":       strconv.Itoa(int(booking.ID)),
+	}, transactionCode)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to create Stripe PaymentIntent: %v\n", err)
+		if _, failErr := s.z.FailPendingTransaction(ctx, db.FailPendingTransactionParams{ID: transaction.ID}); failErr != nil {
+			fmt.Printf("ERROR: Failed to mark transaction %d as failed: %v\n", transaction.ID, failErr)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Không thể tạo thanh toán Stripe",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if _, err := s.z.SetTransactionGatewayReference(ctx, db.SetTransactionGatewayReferenceParams{
+		ID:          transaction.ID,
+		MaThamChieu: intent.ID,
+	}); err != nil {
+		// Webhook vẫn tìm được giao dịch qua metadata.transaction_code
+		fmt.Printf("ERROR: Failed to save PaymentIntent ID for transaction %d: %v\n", transaction.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"client_secret":     intent.ClientSecret,
+		"payment_intent_id": intent.ID,
+		"publishable_key":   s.config.StripeConfig.PublishableKey,
+		"transaction_code":  transactionCode,
+		"booking_id":        booking.ID,
+	})
+}
+
+// StripeWebhook xử lý webhook từ Stripe
+// @Summary Stripe Webhook
+// @Description Xác thực chữ ký Stripe-Signature bằng STRIPE_WEBHOOK_SECRET và cập nhật giao dịch/booking (idempotent)
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param Stripe-Signature header string true "Stripe signature"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /payment/stripe/webhook [post]
+func (s *Server) StripeWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<16))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot read request body"})
+		return
+	}
+
+	if err := helpers.VerifyStripeWebhookSignature(payload, c.GetHeader("Stripe-Signature"), s.config.StripeConfig.WebhookSecret, helpers.StripeWebhookTolerance, time.Now()); err != nil {
+		fmt.Printf("ERROR: Invalid Stripe webhook signature: %v\n", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	var event helpers.StripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payload"})
+		return
+	}
+	fmt.Printf("Stripe webhook received - Event: %s, Type: %s\n", event.ID, event.Type)
+
+	switch event.Type {
+	case "payment_intent.succeeded", "payment_intent.payment_failed", "payment_intent.canceled":
+	default:
+		// Các sự kiện khác không cần xử lý
+		c.JSON(http.StatusOK, gin.H{"received": true})
+		return
+	}
+
+	var intent helpers.StripePaymentIntent
+	if err := json.Unmarshal(event.Data.Object, &intent); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment intent"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	transactionCode := intent.Metadata["transaction_code"]
+	transaction, err := s.z.GetTransactionByCode(ctx, transactionCode)
+	if err != nil {
+		// Không phải giao dịch do hệ thống tạo - trả 200 để Stripe không gửi lại
+		fmt.Printf("Stripe webhook: transaction %q not found for PaymentIntent %s\n", transactionCode, intent.ID)
+		c.JSON(http.StatusOK, gin.H{"received": true, "message": "Transaction not found"})
+		return
+	}
+	if transaction.CongThanhToanID == nil || *transaction.CongThanhToanID != "stripe" ||
+		(transaction.MaThamChieuCongThanhToan != nil && *transaction.MaThamChieuCongThanhToan != intent.ID) {
+		fmt.Printf("Stripe webhook: PaymentIntent %s does not match transaction %s\n", intent.ID, transactionCode)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment intent does not match transaction"})
+		return
+	}
+
+	if event.Type != "payment_intent.succeeded" {
+		_, err := s.z.FailPendingTransaction(ctx, db.FailPendingTransactionParams{
+			ID:          transaction.ID,
+			MaThamChieu: &intent.ID,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transaction", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"received": true})
+		return
+	}
+
+	// Kiểm tra số tiền đã nhận khớp với giao dịch
+	soTien, _ := transaction.SoTien.Float64Value()
+	if intent.AmountReceived != helpers.ToStripeAmount(soTien.Float64, intent.Currency) {
+		fmt.Printf("Stripe webhook ERROR: amount mismatch - Transaction: %s, Expected: %.2f, Received: %d %s\n", transactionCode, soTien.Float64, intent.AmountReceived, intent.Currency)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount"})
+		return
+	}
+
+	confirmed, err := s.z.ConfirmTransactionPayment(ctx, db.ConfirmTransactionPaymentParams{
+		ID:                  transaction.ID,
+		MaThamChieu:         &intent.ID,
+		PhuongThucThanhToan: "stripe",
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Already Update - Stripe có thể gửi lại cùng sự kiện nhiều lần
+		c.JSON(http.StatusOK, gin.H{"received": true, "message": "Order Already Update"})
+		return
+	}
+	if err != nil {
+		fmt.Printf("Stripe webhook ERROR: Failed to confirm transaction %d: %v\n", transaction.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm payment", "details": err.Error()})
+		return
+	}
+	if !confirmed.DaCapNhatDatCho {
+		// Tiền đã thu nhưng booking không còn ở trạng thái chờ thanh toán (vd: đã hủy) - cần hoàn tiền thủ công
+		fmt.Printf("Stripe webhook WARNING: Booking %v was not moved to da_thanh_toan for transaction %s\n", confirmed.DatChoID, transactionCode)
+	}
+
+	fmt.Printf("Stripe webhook: Successfully processed payment - Transaction: %s, PaymentIntent: %s\n", transactionCode, intent.ID)
+	c.JSON(http.StatusOK, gin.H{"received": true, "message": "Confirm Success"})
+}
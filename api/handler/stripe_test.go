@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/helpers"
+	"travia.backend/config"
+	db "travia.backend/db/sqlc"
+)
+
+const testStripeWebhookSecret = "whsec_test"
+
+// fakeStripeStore giả lập các truy vấn giao dịch mà webhook Stripe sử dụng.
+// ConfirmTransactionPayment trả pgx.ErrNoRows khi giao dịch đã xác nhận, giống câu SQL thật
+type fakeStripeStore struct {
+	db.Z
+	transactions map[string]db.LichSuGiaoDich
+	confirmed    map[int32]int
+	failed       map[int32]int
+}
+
+func newFakeStripeStore(txns ...db.LichSuGiaoDich) *fakeStripeStore {
+	store := &fakeStripeStore{
+		transactions: make(map[string]db.LichSuGiaoDich),
+		confirmed:    make(map[int32]int),
+		failed:       make(map[int32]int),
+	}
+	for _, txn := range txns {
+		store.transactions[txn.MaGiaoDichNoiBo] = txn
+	}
+	return store
+}
+
+func (f *fakeStripeStore) GetTransactionByCode(_ context.Context, code string) (db.LichSuGiaoDich, error) {
+	txn, ok := f.transactions[code]
+	if !ok {
+		return db.LichSuGiaoDich{}, pgx.ErrNoRows
+	}
+	return txn, nil
+}
+
+func (f *fakeStripeStore) ConfirmTransactionPayment(_ context.Context, arg db.ConfirmTransactionPaymentParams) (db.ConfirmTransactionPaymentRow, error) {
+	if f.confirmed[arg.ID] > 0 {
+		return db.ConfirmTransactionPaymentRow{}, pgx.ErrNoRows
+	}
+	f.confirmed[arg.ID]++
+	return db.ConfirmTransactionPaymentRow{ID: arg.ID, DaCapNhatDatCho: true}, nil
+}
+
+func (f *fakeStripeStore) FailPendingTransaction(_ context.Context, arg db.FailPendingTransactionParams) (db.LichSuGiaoDich, error) {
+	f.failed[arg.ID]++
+	return db.LichSuGiaoDich{ID: arg.ID}, nil
+}
+
+func newStripeTestServer(store db.Z) *Server {
+	gin.SetMode(gin.TestMode)
+	return &Server{
+		config: &config.Config{StripeConfig: &config.StripeConfig{WebhookSecret: testStripeWebhookSecret}},
+		z:      store,
+	}
+}
+
+func stripeTestTransaction(id int32, code string, amount string) db.LichSuGiaoDich {
+	var soTien pgtype.Numeric
+	if err := soTien.Scan(amount); err != nil {
+		panic(err)
+	}
+	gateway := "stripe"
+	intentID := "pi_" + code
+	return db.LichSuGiaoDich{
+		ID:                       id,
+		MaGiaoDichNoiBo:          code,
+		CongThanhToanID:          &gateway,
+		MaThamChieuCongThanhToan: &intentID,
+		SoTien:                   soTien,
+	}
+}
+
+func stripeTestEvent(t *testing.T, eventType, code string, amountReceived int64) []byte {
+	t.Helper()
+	payload, err := json.Marshal(map[string]any{
+		"id":   "evt_" + code,
+		"type": eventType,
+		"data": map[string]any{
+			"object": map[string]any{
+				"id":              "pi_" + code,
+				"object":          "payment_intent",
+				"amount_received": amountReceived,
+				"currency":        "vnd",
+				"status":          "succeeded",
+				"metadata":        map[string]string{"transaction_code": code},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func postStripeWebhook(s *Server, payload []byte, signature string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/payment/stripe/webhook", bytes.NewReader(payload))
+	c.Request.Header.Set("Stripe-Signature", signature)
+	s.StripeWebhook(c)
+	return w
+}
+
+func signStripePayload(payload []byte, ts time.Time) string {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + helpers.ComputeStripeSignature(payload, timestamp, testStripeWebhookSecret)
+}
+
+func TestStripeWebhookConfirmsPayment(t *testing.T) {
+	store := newFakeStripeStore(stripeTestTransaction(1, "TXN1", "250000"))
+	s := newStripeTestServer(store)
+
+	payload := stripeTestEvent(t, "payment_intent.succeeded", "TXN1", 250000)
+	w := postStripeWebhook(s, payload, signStripePayload(payload, time.Now()))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if store.confirmed[1] != 1 {
+		t.Fatalf("transaction confirmed %d times, want 1", store.confirmed[1])
+	}
+}
+
+func TestStripeWebhookRejectsTamperedPayload(t *testing.T) {
+	store := newFakeStripeStore(stripeTestTransaction(1, "TXN1", "250000"))
+	s := newStripeTestServer(store)
+
+	payload := stripeTestEvent(t, "payment_intent.succeeded", "TXN1", 250000)
+	signature := signStripePayload(payload, time.Now())
+	tampered := stripeTestEvent(t, "payment_intent.succeeded", "TXN1", 1)
+	w := postStripeWebhook(s, tampered, signature)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if store.confirmed[1] != 0 {
+		t.Fatal("tampered event must not confirm the transaction")
+	}
+}
+
+func TestStripeWebhookRejectsExpiredSignature(t *testing.T) {
+	store := newFakeStripeStore(stripeTestTransaction(1, "TXN1", "250000"))
+	s := newStripeTestServer(store)
+
+	payload := stripeTestEvent(t, "payment_intent.succeeded", "TXN1", 250000)
+	w := postStripeWebhook(s, payload, signStripePayload(payload, time.Now().Add(-helpers.StripeWebhookTolerance-time.Minute)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if store.confirmed[1] != 0 {
+		t.Fatal("replayed event must not confirm the transaction")
+	}
+}
+
+func TestStripeWebhookRejectsAmountMismatch(t *testing.T) {
+	store := newFakeStripeStore(stripeTestTransaction(1, "TXN1", "250000"))
+	s := newStripeTestServer(store)
+
+	payload := stripeTestEvent(t, "payment_intent.succeeded", "TXN1", 100000)
+	w := postStripeWebhook(s, payload, signStripePayload(payload, time.Now()))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if store.confirmed[1] != 0 {
+		t.Fatal("amount mismatch must not confirm the transaction")
+	}
+}
+
+func TestStripeWebhookDuplicateEvent(t *testing.T) {
+	store := newFakeStripeStore(stripeTestTransaction(1, "TXN1", "250000"))
+	s := newStripeTestServer(store)
+
+	payload := stripeTestEvent(t, "payment_intent.succeeded", "TXN1", 250000)
+	for i := 0; i < 2; i++ {
+		w := postStripeWebhook(s, payload, signStripePayload(payload, time.Now()))
+		if w.Code != http.StatusOK {
+			t.Fatalf("delivery %d: status = %d, body = %s", i+1, w.Code, w.Body.String())
+		}
+	}
+	if store.confirmed[1] != 1 {
+		t.Fatalf("transaction confirmed %d times, want 1", store.confirmed[1])
+	}
+}
+
+func TestStripeWebhookPaymentFailed(t *testing.T) {
+	store := newFakeStripeStore(stripeTestTransaction(1, "TXN1", "250000"))
+	s := newStripeTestServer(store)
+
+	payload := stripeTestEvent(t, "payment_intent.payment_failed", "TXN1", 0)
+	w := postStripeWebhook(s, payload, signStripePayload(payload, time.Now()))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if store.failed[1] != 1 || store.confirmed[1] != 0 {
+		t.Fatalf("failed = %d, confirmed = %d; want 1, 0", store.failed[1], store.confirmed[1])
+	}
+}
@@ -2,12 +2,15 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"travia.backend/api/helpers"
 	"travia.backend/api/models"
@@ -350,17 +353,19 @@ func (s *Server) GetMyTours(c *gin.Context) {
 	}
 
 	// Validate trang_thai: giá trị rỗng "" là hợp lệ (lấy tất cả tours)
-	// Nếu có giá trị, chỉ chấp nhận: nhap, cong_bo, luu_tru
+	// Nếu có giá trị, chỉ chấp nhận: nhap, cho_duyet, cong_bo, tu_choi, luu_tru
 	var trangThaiPtr *string
 	if trang_thai != "" {
 		validStatuses := map[string]bool{
-			"nhap":    true,
-			"cong_bo": true,
-			"luu_tru": true,
+			"nhap":      true,
+			"cho_duyet": true,
+			"cong_bo":   true,
+			"tu_choi":   true,
+			"luu_tru":   true,
 		}
 		if !validStatuses[trang_thai] {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"message": "Trạng thái không hợp lệ. Chỉ chấp nhận: nhap, cong_bo, luu_tru hoặc rỗng để lấy tất cả",
+				"message": "Trạng thái không hợp lệ. Chỉ chấp nhận: nhap, cho_duyet, cong_bo, tu_choi, luu_tru hoặc rỗng để lấy tất cả",
 			})
 			return
 		}
@@ -386,7 +391,7 @@ func (s *Server) GetMyTours(c *gin.Context) {
 		return
 	}
 	data, err := s.z.GetMyTours(context.Background(), db.GetMyToursParams{
-		NhaCungCapID: claimsMap.Id,
+		NhaCungCapID: currentSupplierID(c),
 		Limit:        int32(limit),
 		Offset:       int32(offset),
 		TrangThai:    trangThaiPtr,
@@ -400,7 +405,8 @@ func (s *Server) GetMyTours(c *gin.Context) {
 
 // Cập nhật trạng thái tour
 // @Summary Cập nhật trạng thái tour
-// @Description Cập nhật trạng thái tour
+// @Description Chuyển tour về nháp (nhap) hoặc lưu trữ (luu_tru); phiên bản đang chờ duyệt (nếu có) bị hủy.
+// @Description Tour chỉ được công bố sau khi quản trị viên duyệt: gửi duyệt qua POST /supplier/tours/{id}/submit
 // @Tags Supplier
 // @Accept json
 // @Produce json
@@ -408,6 +414,7 @@ func (s *Server) GetMyTours(c *gin.Context) {
 // @Param trang_thai body string true "Trạng thái"
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /supplier/tours/update-status/{id} [put]
 func (s *Server) UpdateTourStatus(c *gin.Context) {
@@ -424,26 +431,46 @@ func (s *Server) UpdateTourStatus(c *gin.Context) {
 	}
 	var trangThaiPtr *string
 	if trang_thai != "" {
+		if trang_thai == db.TourStatusPublished {
+			c.JSON(http.StatusConflict, gin.H{
+				"message": "Tour cần được duyệt trước khi công bố. Gửi duyệt qua POST /supplier/tours/{id}/submit",
+			})
+			return
+		}
 		validStatuses := map[string]bool{
 			"nhap":    true,
-			"cong_bo": true,
 			"luu_tru": true,
 		}
 		if !validStatuses[trang_thai] {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"message": "Trạng thái không hợp lệ. Chỉ chấp nhận: nhap, cong_bo, luu_tru hoặc rỗng để lấy tất cả",
+				"message": "Trạng thái không hợp lệ. Chỉ chấp nhận: nhap, luu_tru",
 			})
 			return
 		}
 		trangThaiPtr = &trang_thai
-		result, err := s.z.UpdateTourStatus(context.Background(), db.UpdateTourStatusParams{
-			ID:        int32(id),
-			TrangThai: trangThaiPtr,
+		before, err := s.z.GetTourByID(context.Background(), int32(id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"message": "Tour not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		result, err := s.z.WithdrawTourRevision(context.Background(), db.UpdateTourStatusParams{
+			ID:           int32(id),
+			NhaCungCapID: currentSupplierID(c),
+			TrangThai:    trangThaiPtr,
 		})
 		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"message": "Tour not found"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 			return
 		}
+		s.recordAudit(c, AuditTourUpdateStatus, AuditTargetTour, strconv.Itoa(id), before, result)
 		c.JSON(http.StatusOK, gin.H{"message": "Tour status updated successfully", "data": result})
 	}
 }
@@ -474,7 +501,7 @@ func (s *Server) GetInfoSupplier(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
 		return
 	}
-	data, err := s.z.GetSupplierById(context.Background(), claimsMap.Id)
+	data, err := s.z.GetSupplierById(context.Background(), currentSupplierID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
@@ -656,7 +683,7 @@ func (s *Server) GetSupplierDashboardOverview(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
 		return
 	}
-	data, err := s.z.GetSupplierDashboardOverview(context.Background(), claimsMap.Id)
+	data, err := s.z.GetSupplierDashboardOverview(context.Background(), currentSupplierID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
@@ -721,7 +748,7 @@ func (s *Server) GetSupplierRevenueByTimeRange(c *gin.Context) {
 		endDatePg = pgtype.Timestamp{Time: *endDate, Valid: true}
 	}
 	data, err := s.z.GetSupplierRevenueByTimeRange(context.Background(), db.GetSupplierRevenueByTimeRangeParams{
-		ID:      claimsMap.Id,
+		ID:      currentSupplierID(c),
 		Column2: period,
 		Column3: startDatePg,
 		Column4: endDatePg,
@@ -790,7 +817,7 @@ func (s *Server) GetSupplierTopTours(c *gin.Context) {
 		endDatePg = pgtype.Timestamp{Time: *endDate, Valid: true}
 	}
 	data, err := s.z.GetSupplierTopTours(context.Background(), db.GetSupplierTopToursParams{
-		ID:      claimsMap.Id,
+		ID:      currentSupplierID(c),
 		Column2: startDatePg,
 		Column3: endDatePg,
 		Column4: sortBy,
@@ -851,7 +878,7 @@ func (s *Server) GetSupplierBookingStatsByStatus(c *gin.Context) {
 		endDatePg = pgtype.Timestamp{Time: *endDate, Valid: true}
 	}
 	data, err := s.z.GetSupplierBookingStatsByStatus(context.Background(), db.GetSupplierBookingStatsByStatusParams{
-		ID:        claimsMap.Id,
+		ID:        currentSupplierID(c),
 		Column2:   "day",
 		NgayDat:   startDatePg,
 		NgayDat_2: endDatePg,
@@ -889,7 +916,7 @@ func (s *Server) GetSupplierTourStatsByStatus(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
 		return
 	}
-	data, err := s.z.GetSupplierTourStatsByStatus(context.Background(), claimsMap.Id)
+	data, err := s.z.GetSupplierTourStatsByStatus(context.Background(), currentSupplierID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
@@ -954,7 +981,7 @@ func (s *Server) GetSupplierRevenueChart(c *gin.Context) {
 		endDatePg = pgtype.Timestamp{Time: *endDate, Valid: true}
 	}
 	data, err := s.z.GetSupplierRevenueChart(context.Background(), db.GetSupplierRevenueChartParams{
-		ID:      claimsMap.Id,
+		ID:      currentSupplierID(c),
 		Column2: period,
 		Column3: startDatePg,
 		Column4: endDatePg,
@@ -994,7 +1021,7 @@ func (s *Server) GetSupplierTourStatsByCategory(c *gin.Context) {
 		return
 	}
 
-	data, err := s.z.GetSupplierTourStatsByCategory(context.Background(), claimsMap.Id)
+	data, err := s.z.GetSupplierTourStatsByCategory(context.Background(), currentSupplierID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
@@ -1059,7 +1086,7 @@ func (s *Server) GetSupplierCustomerStats(c *gin.Context) {
 		endDatePg = pgtype.Timestamp{Time: *endDate, Valid: true}
 	}
 	data, err := s.z.GetSupplierCustomerStats(context.Background(), db.GetSupplierCustomerStatsParams{
-		ID:      claimsMap.Id,
+		ID:      currentSupplierID(c),
 		Column2: startDatePg,
 		Column3: endDatePg,
 		Column4: sortBy,
@@ -1097,7 +1124,7 @@ func (s *Server) GetSupplierRatingAnalysis(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
 		return
 	}
-	data, err := s.z.GetSupplierRatingAnalysis(context.Background(), claimsMap.Id)
+	data, err := s.z.GetSupplierRatingAnalysis(context.Background(), currentSupplierID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
@@ -1137,7 +1164,7 @@ func (s *Server) GetSupplierUpcomingDepartures(c *gin.Context) {
 		limit = 10
 	}
 	data, err := s.z.GetSupplierUpcomingDepartures(context.Background(), db.GetSupplierUpcomingDeparturesParams{
-		ID:    claimsMap.Id,
+		ID:    currentSupplierID(c),
 		Limit: int32(limit),
 	})
 	if err != nil {
@@ -1179,7 +1206,7 @@ func (s *Server) GetSupplierRecentBookings(c *gin.Context) {
 		limit = 10
 	}
 	data, err := s.z.GetSupplierRecentBookings(context.Background(), db.GetSupplierRecentBookingsParams{
-		ID:    claimsMap.Id,
+		ID:    currentSupplierID(c),
 		Limit: int32(limit),
 	})
 	if err != nil {
@@ -1238,7 +1265,7 @@ func (s *Server) GetSupplierRevenueStatistics(c *gin.Context) {
 	}
 
 	data, err := s.z.GetSupplierRevenueStatistics(context.Background(), db.GetSupplierRevenueStatisticsParams{
-		ID:      claimsMap.Id,
+		ID:      currentSupplierID(c),
 		Column2: startDatePg,
 		Column3: endDatePg,
 	})
@@ -1350,7 +1377,7 @@ func (s *Server) GetSupplierTransactions(c *gin.Context) {
 	}
 
 	data, err := s.z.GetSupplierTransactions(context.Background(), db.GetSupplierTransactionsParams{
-		ID:      claimsMap.Id,
+		ID:      currentSupplierID(c),
 		Column2: startDatePg,
 		Column3: endDatePg,
 		Limit:   int32(limit),
@@ -1528,7 +1555,7 @@ func (s *Server) GetSupplierBookingsByStatusAdvanced(c *gin.Context) {
 
 	// Fetch data
 	data, err := s.z.GetSupplierBookingsByStatusAdvanced(context.Background(), db.GetSupplierBookingsByStatusAdvancedParams{
-		ID:                  claimsMap.Id,
+		ID:                  currentSupplierID(c),
 		Limit:               int32(limit),
 		Offset:              int32(offset),
 		TrangThai:           trangThaiPtr,
@@ -1550,7 +1577,7 @@ func (s *Server) GetSupplierBookingsByStatusAdvanced(c *gin.Context) {
 
 	// Get total count
 	totalCount, err := s.z.CountSupplierBookingsByStatusAdvanced(context.Background(), db.CountSupplierBookingsByStatusAdvancedParams{
-		ID:                  claimsMap.Id,
+		ID:                  currentSupplierID(c),
 		TrangThai:           trangThaiPtr,
 		TourID:              tourIDPtr,
 		StartDate:           startDatePg,
@@ -1606,7 +1633,7 @@ func (s *Server) GetSupplierReviewStatistics(c *gin.Context) {
 		return
 	}
 	data, err := s.z.GetSupplierReviewStatistics(context.Background(), db.GetSupplierReviewStatisticsParams{
-		ID:      claimsMap.Id,
+		ID:      currentSupplierID(c),
 		Column2: int32(tourID),
 	})
 	if err != nil {
@@ -1647,7 +1674,7 @@ func (s *Server) GetDetailedSupplierReviews(c *gin.Context) {
 		return
 	}
 	data, err := s.z.GetDetailedSupplierReviews(context.Background(), db.GetDetailedSupplierReviewsParams{
-		NhaCungCapID: claimsMap.Id,
+		NhaCungCapID: currentSupplierID(c),
 		Column2:      int32(rating),
 		Column3:      int32(tourID),
 	})
@@ -1684,7 +1711,7 @@ func (s *Server) GetOptionTour(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
 		return
 	}
-	data, err := s.z.OptionTour(context.Background(), pgtype.UUID(claimsMap.Id))
+	data, err := s.z.OptionTour(context.Background(), currentSupplierID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
@@ -1695,20 +1722,30 @@ func (s *Server) GetOptionTour(c *gin.Context) {
 
 // Phản hồi đánh giá
 // @Summary Phản hồi đánh giá
-// @Description Phản hồi đánh giá
+// @Description Chủ sở hữu hoặc nhân viên được cấp quyền trả lời đánh giá tour của nhà cung cấp (mỗi đánh giá một phản hồi)
 // @Tags Supplier
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param danh_gia_id path int true "Danh gia ID"
-// @Param noi_dung body string true "Noi dung phan hoi"
+// @Param request body models.ReplyReviewRequest true "Noi dung phan hoi"
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /supplier/dashboard/feedback-review/{danh_gia_id} [post]
 func (s *Server) FeedbackReview(c *gin.Context) {
-	danhGiaID, _ := strconv.Atoi(c.Param("danh_gia_id"))
-	noiDung := c.PostForm("noi_dung")
+	danhGiaID, err := strconv.Atoi(c.Param("danh_gia_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID"})
+		return
+	}
+	var req models.ReplyReviewRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
 	claims, ok := c.Get("claims")
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
@@ -1719,16 +1756,22 @@ func (s *Server) FeedbackReview(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
 		return
 	}
-	if claimsMap.Vaitro != "nha_cung_cap" {
-		c.JSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
-		return
-	}
-	id, err := s.z.FeedbackReview(context.Background(), db.FeedbackReviewParams{
-		DanhGiaID:   int32(danhGiaID),
-		NguoiDungID: claimsMap.Id,
-		NoiDung:     noiDung,
+	// Người trả lời là người dùng đang đăng nhập, đánh giá phải thuộc tour của nhà cung cấp
+	id, err := s.z.ReplySupplierReview(context.Background(), db.ReplySupplierReviewParams{
+		DanhGiaID:    int32(danhGiaID),
+		NguoiDungID:  claimsMap.Id,
+		NoiDung:      req.NoiDung,
+		NhaCungCapID: currentSupplierID(c),
 	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Review not found"})
+			return
+		}
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{"message": "Review already has a reply"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
@@ -0,0 +1,518 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/helpers"
+	"travia.backend/api/middleware"
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// SupplierStaffInvitationDuration là thời hạn của mã mời nhân viên nhà cung cấp
+const SupplierStaffInvitationDuration = 72 * time.Hour
+
+// SupplierContext xác định nhà cung cấp của người dùng đang đăng nhập (chủ sở hữu hoặc nhân viên đang hoạt động)
+// và đưa vào context ("supplier_id", "supplier_owner"). Đặt sau AuthMiddleware
+func (s *Server) SupplierContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jwtClaims, ok := getAuthClaims(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		member, err := s.z.GetSupplierContext(c.Request.Context(), jwtClaims.Id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Tài khoản không thuộc nhà cung cấp nào"})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve supplier", "details": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("supplier_id", member.NhaCungCapID)
+		c.Set("supplier_owner", member.ChuSoHuu)
+		c.Next()
+	}
+}
+
+// currentSupplierID trả về id nhà cung cấp do SupplierContext xác định
+func currentSupplierID(c *gin.Context) pgtype.UUID {
+	v, _ := c.Get("supplier_id")
+	id, _ := v.(pgtype.UUID)
+	return id
+}
+
+// requireSupplierOwner trả về false (và đã ghi response 403) nếu người dùng không phải chủ sở hữu nhà cung cấp
+func requireSupplierOwner(c *gin.Context) bool {
+	if owner, _ := c.Get("supplier_owner"); owner != true {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Chỉ chủ sở hữu nhà cung cấp được quản lý nhân viên"})
+		return false
+	}
+	return true
+}
+
+// newInvitationCode tạo mã mời ngẫu nhiên (chỉ lưu bản băm trong DB)
+func newInvitationCode() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// assignableStaffPermissions là các quyền nhóm nha_cung_cap chủ sở hữu được cấp cho nhân viên
+// (trừ quyền quản lý nhân viên)
+func (s *Server) assignableStaffPermissions(ctx context.Context) ([]string, error) {
+	all, err := s.z.GetAllPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(all))
+	for _, p := range all {
+		if p.Nhom == string(db.VaiTroNguoiDungNhaCungCap) && p.Ma != middleware.PermSupplierStaffManage {
+			codes = append(codes, p.Ma)
+		}
+	}
+	return codes, nil
+}
+
+// validateStaffPermissions chuẩn hóa danh sách quyền, trả về false (và đã ghi response) nếu có quyền không được phép cấp
+func (s *Server) validateStaffPermissions(c *gin.Context, ctx context.Context, codes []string) ([]string, bool) {
+	assignable, err := s.assignableStaffPermissions(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get permissions", "details": err.Error()})
+		return nil, false
+	}
+	allowed := make(map[string]bool, len(assignable))
+	for _, code := range assignable {
+		allowed[code] = true
+	}
+
+	codes = normalizeCodes(codes)
+	var invalid []string
+	for _, code := range codes {
+		if !allowed[code] {
+			invalid = append(invalid, code)
+		}
+	}
+	if len(invalid) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":              "Quyền không thể cấp cho nhân viên",
+			"quyen_khong_hop_le": invalid,
+			"quyen_co_the_cap":   assignable,
+		})
+		return nil, false
+	}
+	return codes, true
+}
+
+// sendStaffInvitation gửi mã mời cho nhân viên (không chặn request)
+func (s *Server) sendStaffInvitation(ctx context.Context, supplierID pgtype.UUID, email, hoTen, code string, expiresAt time.Time) {
+	supplierName := "Travia"
+	if supplier, err := s.z.GetSupplierById(ctx, supplierID); err == nil {
+		supplierName = supplier.Ten
+	}
+	helpers.SendSupplierStaffInvitationAsync(email, hoTen, supplierName, code, expiresAt.Format("15:04 02/01/2006"), s.config.EmailConfig)
+}
+
+// parseStaffID đọc :id của nhân viên, trả về false (và đã ghi response 400) nếu không hợp lệ
+func parseStaffID(c *gin.Context) (int32, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid staff ID"})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// GetSupplierStaff godoc
+// @Summary List supplier staff
+// @Description Danh sách nhân viên của nhà cung cấp và các quyền có thể cấp (chỉ chủ sở hữu)
+// @Tags Supplier
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/staff [get]
+func (s *Server) GetSupplierStaff(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if !requireSupplierOwner(c) {
+		return
+	}
+
+	staff, err := s.z.GetSupplierStaff(ctx, currentSupplierID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get staff", "details": err.Error()})
+		return
+	}
+	assignable, err := s.assignableStaffPermissions(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get permissions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":             staff,
+		"quyen_co_the_cap": assignable,
+	})
+}
+
+// InviteSupplierStaff godoc
+// @Summary Invite supplier staff
+// @Description Chủ sở hữu mời nhân viên bằng email và cấp quyền (quản lý lịch khởi hành, xem booking, trả lời đánh giá, xem doanh thu...).
+// @Description Tài khoản nhân viên chưa đăng nhập được cho đến khi nhận lời mời bằng mã gửi qua email
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param request body models.InviteSupplierStaffRequest true "Thông tin nhân viên"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/staff [post]
+func (s *Server) InviteSupplierStaff(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	if !requireSupplierOwner(c) {
+		return
+	}
+
+	var req models.InviteSupplierStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	quyen, ok := s.validateStaffPermissions(c, ctx, req.Quyen)
+	if !ok {
+		return
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if _, err := s.z.GetUserByEmail(ctx, email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email đã được sử dụng cho tài khoản khác"})
+		return
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email", "details": err.Error()})
+		return
+	}
+
+	code, err := newInvitationCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation", "details": err.Error()})
+		return
+	}
+	// Mật khẩu tạm ngẫu nhiên, nhân viên đặt mật khẩu riêng khi nhận lời mời
+	placeholder, err := newInvitationCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation", "details": err.Error()})
+		return
+	}
+	hashedPassword, err := utils.HashPassword(placeholder)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể mã hóa mật khẩu"})
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(SupplierStaffInvitationDuration)
+	codeHash := utils.HashToken(code)
+	supplierID := currentSupplierID(c)
+	member, err := s.z.InviteSupplierStaff(ctx, db.CreateUserParams{
+		HoTen:        strings.TrimSpace(req.HoTen),
+		Email:        email,
+		MatKhauMaHoa: hashedPassword,
+		SoDienThoai:  req.SoDienThoai,
+		VaiTro:       db.NullVaiTroNguoiDung{VaiTroNguoiDung: db.VaiTroNguoiDungNhaCungCap, Valid: true},
+		DangHoatDong: helpers.NewBool(false),
+		XacThuc:      helpers.NewBool(false),
+		NgayTao:      pgtype.Timestamp{Time: now, Valid: true},
+		NgayCapNhat:  pgtype.Timestamp{Time: now, Valid: true},
+	}, db.CreateSupplierStaffParams{
+		NhaCungCapID: supplierID,
+		Quyen:        quyen,
+		MaLoiMoiBam:  &codeHash,
+		HanLoiMoi:    pgtype.Timestamp{Time: expiresAt, Valid: true},
+		NguoiMoi:     jwtClaims.Id,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email đã được sử dụng cho tài khoản khác"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite staff", "details": err.Error()})
+		return
+	}
+
+	s.sendStaffInvitation(ctx, supplierID, email, req.HoTen, code, expiresAt)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Đã gửi lời mời nhân viên",
+		"data": gin.H{
+			"id":            member.ID,
+			"nguoi_dung_id": member.NguoiDungID,
+			"email":         email,
+			"quyen":         member.Quyen,
+			"trang_thai":    member.TrangThai,
+			"han_loi_moi":   member.HanLoiMoi,
+		},
+	})
+}
+
+// UpdateSupplierStaff godoc
+// @Summary Update supplier staff permissions
+// @Description Thay toàn bộ quyền của nhân viên (chỉ chủ sở hữu). Có hiệu lực ngay với phiên đang đăng nhập của nhân viên
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Param request body models.UpdateSupplierStaffRequest true "Danh sách quyền"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/staff/{id} [put]
+func (s *Server) UpdateSupplierStaff(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if !requireSupplierOwner(c) {
+		return
+	}
+	staffID, ok := parseStaffID(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateSupplierStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	quyen, ok := s.validateStaffPermissions(c, ctx, req.Quyen)
+	if !ok {
+		return
+	}
+
+	member, err := s.z.UpdateSupplierStaffPermissions(ctx, db.UpdateSupplierStaffPermissionsParams{
+		ID:           staffID,
+		NhaCungCapID: currentSupplierID(c),
+		Quyen:        quyen,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy nhân viên"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update staff", "details": err.Error()})
+		return
+	}
+	s.invalidatePermissionCache(ctx, member.NguoiDungID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cập nhật quyền nhân viên thành công",
+		"data": gin.H{
+			"id":         member.ID,
+			"quyen":      member.Quyen,
+			"trang_thai": member.TrangThai,
+		},
+	})
+}
+
+// RemoveSupplierStaff godoc
+// @Summary Deactivate supplier staff
+// @Description Vô hiệu hóa nhân viên (chỉ chủ sở hữu): khóa tài khoản, thu hồi mọi phiên đăng nhập và quyền truy cập
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/staff/{id} [delete]
+func (s *Server) RemoveSupplierStaff(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if !requireSupplierOwner(c) {
+		return
+	}
+	staffID, ok := parseStaffID(c)
+	if !ok {
+		return
+	}
+
+	member, err := s.z.DeactivateSupplierStaff(ctx, db.DeactivateSupplierStaffParams{
+		ID:           staffID,
+		NhaCungCapID: currentSupplierID(c),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy nhân viên"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate staff", "details": err.Error()})
+		return
+	}
+
+	if err := s.z.DeactivateUser(ctx, member.NguoiDungID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user", "details": err.Error()})
+		return
+	}
+	if _, err := s.revokeUserSessions(ctx, member.NguoiDungID, nil, SessionRevokeStaffRemoved); err != nil {
+		log.Printf("[SupplierStaff] revoke sessions of staff %d failed: %v", member.ID, err)
+	}
+	s.invalidatePermissionCache(ctx, member.NguoiDungID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã vô hiệu hóa nhân viên"})
+}
+
+// ResendSupplierStaffInvitation godoc
+// @Summary Resend supplier staff invitation
+// @Description Tạo mã mời mới cho nhân viên chưa kích hoạt và gửi lại email (mã cũ hết hiệu lực)
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/staff/{id}/resend [post]
+func (s *Server) ResendSupplierStaffInvitation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if !requireSupplierOwner(c) {
+		return
+	}
+	staffID, ok := parseStaffID(c)
+	if !ok {
+		return
+	}
+	supplierID := currentSupplierID(c)
+
+	staff, err := s.z.GetSupplierStaffByID(ctx, db.GetSupplierStaffByIDParams{ID: staffID, NhaCungCapID: supplierID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy nhân viên"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get staff", "details": err.Error()})
+		return
+	}
+
+	code, err := newInvitationCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation", "details": err.Error()})
+		return
+	}
+	codeHash := utils.HashToken(code)
+	expiresAt := time.Now().Add(SupplierStaffInvitationDuration)
+	member, err := s.z.RenewSupplierStaffInvitation(ctx, db.RenewSupplierStaffInvitationParams{
+		ID:           staffID,
+		NhaCungCapID: supplierID,
+		MaLoiMoiBam:  &codeHash,
+		HanLoiMoi:    pgtype.Timestamp{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nhân viên không có lời mời đang chờ kích hoạt"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to renew invitation", "details": err.Error()})
+		return
+	}
+
+	s.sendStaffInvitation(ctx, supplierID, staff.Email, staff.HoTen, code, expiresAt)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã gửi lại lời mời",
+		"data": gin.H{
+			"id":          member.ID,
+			"han_loi_moi": member.HanLoiMoi,
+		},
+	})
+}
+
+// AcceptSupplierStaffInvitation godoc
+// @Summary Accept supplier staff invitation
+// @Description Nhân viên dùng mã mời trong email để đặt mật khẩu và kích hoạt tài khoản, sau đó đăng nhập qua /auth/login/supplier
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param request body models.AcceptSupplierStaffInvitationRequest true "Mã mời và mật khẩu"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/staff/invitations/accept [post]
+func (s *Server) AcceptSupplierStaffInvitation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.AcceptSupplierStaffInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codeHash := utils.HashToken(strings.TrimSpace(req.MaLoiMoi))
+	invitation, err := s.z.GetSupplierStaffByInvitation(ctx, &codeHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Mã mời không hợp lệ hoặc đã hết hạn"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invitation", "details": err.Error()})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.MatKhau)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể mã hóa mật khẩu"})
+		return
+	}
+	if err := s.z.AcceptSupplierStaffInvitation(ctx, invitation.ID, invitation.NguoiDungID, hashedPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation", "details": err.Error()})
+		return
+	}
+	s.invalidatePermissionCache(ctx, invitation.NguoiDungID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Kích hoạt tài khoản nhân viên thành công",
+		"data": gin.H{
+			"email": invitation.Email,
+		},
+	})
+}
@@ -3,16 +3,17 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"travia.backend/api/helpers"
 	"travia.backend/api/models"
-	"travia.backend/api/utils"
 	db "travia.backend/db/sqlc"
 )
 
@@ -112,6 +113,16 @@ func (s *Server) GetTourDetailByID(c *gin.Context) {
 		return
 	}
 
+	// Chính sách hủy của tour và các khởi hành có chính sách riêng
+	chinhSachHuy, chinhSachHuyKhoiHanh, err := s.tourCancellationPolicies(ctx, int32(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+			"error":   "Không thể lấy chính sách hủy tour",
+		})
+		return
+	}
+
 	// Convert pgtype.UUID to string for nha_cung_cap_id
 	var nhaCungCapIDStr *string
 	if tour.NhaCungCapID.Valid {
@@ -120,32 +131,36 @@ func (s *Server) GetTourDetailByID(c *gin.Context) {
 	}
 
 	response := gin.H{
-		"id":                 tour.ID,
-		"tieu_de":            tour.TieuDe,
-		"mo_ta":              tour.MoTa,
-		"danh_muc_id":        tour.DanhMucID,
-		"so_ngay":            tour.SoNgay,
-		"so_dem":             tour.SoDem,
-		"gia_nguoi_lon":      tour.GiaNguoiLon,
-		"gia_tre_em":         tour.GiaTreEm,
-		"don_vi_tien_te":     tour.DonViTienTe,
-		"trang_thai":         tour.TrangThai,
-		"noi_bat":            tour.NoiBat,
-		"nha_cung_cap_id":    nhaCungCapIDStr,
-		"ngay_tao":           tour.NgayTao,
-		"ngay_cap_nhat":      tour.NgayCapNhat,
-		"ten_danh_muc":       tour.TenDanhMuc,
-		"ten_nha_cung_cap":   tour.TenNhaCungCap,
-		"logo_ncc":           tour.LogoNcc,
-		"hinh_anh":           json.RawMessage(tour.Images),
-		"diem_den":           json.RawMessage(tour.Destinations),
-		"lich_trinh":         json.RawMessage(tour.Itinerary),
-		"lich_khoi_hanh":     json.RawMessage(tour.Departures),
-		"giam_gia_phan_tram": tour.GiamGiaPhanTram,
-		"giam_gia_tu":        tour.GiamGiaTu,
-		"giam_gia_den":       tour.GiamGiaDen,
-		"so_nho_nhat":        tour.SoNhoNhat,
-		"so_lon_nhat":        tour.SoLonNhat,
+		"id":                       tour.ID,
+		"tieu_de":                  tour.TieuDe,
+		"mo_ta":                    tour.MoTa,
+		"danh_muc_id":              tour.DanhMucID,
+		"so_ngay":                  tour.SoNgay,
+		"so_dem":                   tour.SoDem,
+		"gia_nguoi_lon":            tour.GiaNguoiLon,
+		"gia_tre_em":               tour.GiaTreEm,
+		"don_vi_tien_te":           tour.DonViTienTe,
+		"trang_thai":               tour.TrangThai,
+		"noi_bat":                  tour.NoiBat,
+		"nha_cung_cap_id":          nhaCungCapIDStr,
+		"ngay_tao":                 tour.NgayTao,
+		"ngay_cap_nhat":            tour.NgayCapNhat,
+		"ten_danh_muc":             tour.TenDanhMuc,
+		"ten_nha_cung_cap":         tour.TenNhaCungCap,
+		"logo_ncc":                 tour.LogoNcc,
+		"hinh_anh":                 json.RawMessage(tour.Images),
+		"diem_den":                 json.RawMessage(tour.Destinations),
+		"lich_trinh":               json.RawMessage(tour.Itinerary),
+		"lich_khoi_hanh":           json.RawMessage(tour.Departures),
+		"giam_gia_phan_tram":       tour.GiamGiaPhanTram,
+		"giam_gia_tu":              tour.GiamGiaTu,
+		"giam_gia_den":             tour.GiamGiaDen,
+		"so_nho_nhat":              tour.SoNhoNhat,
+		"so_lon_nhat":              tour.SoLonNhat,
+		"tuoi_tre_em_toi_thieu":    tour.TuoiTreEmToiThieu,
+		"tuoi_tre_em_toi_da":       tour.TuoiTreEmToiDa,
+		"chinh_sach_huy":           chinhSachHuy,
+		"chinh_sach_huy_khoi_hanh": chinhSachHuyKhoiHanh,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -159,6 +174,7 @@ func (s *Server) GetTourDetailByID(c *gin.Context) {
 // CreateTourFull godoc
 // @Summary      Tạo tour với đầy đủ thông tin (1 transaction)
 // @Description  Tạo tour bao gồm ảnh, điểm đến, lịch trình và hoạt động trong 1 transaction. Đảm bảo tính toàn vẹn dữ liệu (all or nothing)
+// @Description  Tour được tạo ở trạng thái nhap; trang_thai = cong_bo thì tour được gửi duyệt ngay (cho_duyet), chỉ công bố khi quản trị viên duyệt
 // @Tags         tour
 // @Accept       json
 // @Produce      json
@@ -179,30 +195,184 @@ func (s *Server) CreateTour(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from JWT claims
-	claims, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+	// Tour thuộc nhà cung cấp của người dùng (chủ sở hữu hoặc nhân viên), xác định bởi SupplierContext
+	supplierID := currentSupplierID(c)
+
+	params, err := buildTourDetailsParams(req, supplierID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
 		return
 	}
+	// Tour mới luôn là bản nháp; trang_thai = cong_bo nghĩa là gửi duyệt ngay sau khi tạo
+	draft := db.TourStatusDraft
+	params.Tour.TrangThai = &draft
 
-	jwtClaims, ok := claims.(*utils.JwtClams)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+	// Execute transaction
+	result, err := s.z.CreateTourWithDetails(c.Request.Context(), params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Không thể tạo tour",
+			"details": err.Error(),
+		})
 		return
 	}
+	s.scheduleTourEmbedding(result.Tour.ID)
 
-	userUUID := jwtClaims.Id
+	var revision any
+	if req.TrangThai == db.TourStatusPublished {
+		submitted, err := s.submitTourRevision(c, result.Tour.ID, db.TourRevisionSubmit, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Đã tạo tour nhưng không thể gửi duyệt",
+				"details": err.Error(),
+				"tour_id": result.Tour.ID,
+			})
+			return
+		}
+		pending := db.TourStatusPending
+		result.Tour.TrangThai = &pending
+		revision = tourRevisionResponse(submitted)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tạo tour thành công",
+		"data": gin.H{
+			"tour_id":             result.Tour.ID,
+			"tour":                result.Tour,
+			"images_count":        len(result.Images),
+			"destinations_count":  len(result.Destinations),
+			"itineraries_count":   len(result.Itineraries),
+			"departures_count":    len(result.Departures),
+			"images":              result.Images,
+			"itineraries":         result.Itineraries,
+			"departures":          result.Departures,
+			"phien_ban_cho_duyet": revision,
+		},
+	})
+}
 
-	// Convert giá sang pgtype.Numeric
-	var giaMoiNguoi pgtype.Numeric
-	if err := giaMoiNguoi.Scan(fmt.Sprintf("%.2f", req.GiaNguoiLon)); err != nil {
+// UpdateTourFull godoc
+// @Summary      Cập nhật tour với đầy đủ thông tin (1 transaction)
+// @Description  Thay ảnh, điểm đến, lịch trình và hoạt động của tour theo dữ liệu gửi lên; cập nhật cấu hình nhóm.
+// @Description  Lịch khởi hành đã có được giữ nguyên, lich_khoi_hanh_tours chỉ thêm lịch mới.
+// @Description  Tour đang công bố hoặc chờ duyệt: nội dung được lưu thành phiên bản chờ duyệt (202), chỉ áp dụng khi quản trị viên duyệt.
+// @Description  Tour nháp / bị từ chối / lưu trữ: áp dụng ngay, giữ trạng thái hiện tại; trang_thai = cong_bo thì gửi duyệt sau khi lưu
+// @Tags         tour
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Tour ID"
+// @Param        request body models.CreateTourRequest true "Tour data"
+// @Success      200 {object} map[string]interface{}
+// @Success      202 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Failure      500 {object} map[string]interface{}
+// @Security     BearerAuth
+// @Router       /tour/{id}/full [put]
+func (s *Server) UpdateTourFull(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID không hợp lệ"})
+		return
+	}
+	var req models.CreateTourRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Giá không hợp lệ",
+			"error":   "Dữ liệu không hợp lệ",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	supplierID := currentSupplierID(c)
+	existing, err := s.z.GetTourByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy tour"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy tour", "details": err.Error()})
+		return
+	}
+	if existing.NhaCungCapID != supplierID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Tour không thuộc nhà cung cấp của bạn"})
+		return
+	}
+
+	params, err := buildTourDetailsParams(req, supplierID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Tour đang công bố / chờ duyệt: lưu nội dung thành phiên bản chờ duyệt, tour đang hiển thị giữ nguyên
+	if tourUnderReview(existing.TrangThai) {
+		req.TrangThai = ""
+		noiDung, err := json.Marshal(req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lưu nội dung tour", "details": err.Error()})
+			return
+		}
+		revision, err := s.submitTourRevision(c, existing.ID, tourRevisionKind(existing.TrangThai), noiDung, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể gửi chỉnh sửa chờ duyệt", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Đã gửi chỉnh sửa, tour sẽ cập nhật khi được duyệt",
+			"data":    tourRevisionResponse(revision),
+		})
+		return
+	}
+	// Trạng thái chỉ đổi qua gửi duyệt / UpdateTourStatus
+	params.Tour.TrangThai = existing.TrangThai
+
+	result, err := s.z.UpdateTourWithDetails(c.Request.Context(), int32(id), params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Không thể cập nhật tour",
+			"details": err.Error(),
 		})
 		return
 	}
+	s.scheduleTourEmbedding(result.Tour.ID)
+
+	var revision any
+	if req.TrangThai == db.TourStatusPublished {
+		submitted, err := s.submitTourRevision(c, result.Tour.ID, db.TourRevisionSubmit, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Đã lưu tour nhưng không thể gửi duyệt", "details": err.Error()})
+			return
+		}
+		pending := db.TourStatusPending
+		result.Tour.TrangThai = &pending
+		revision = tourRevisionResponse(submitted)
+	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cập nhật tour thành công",
+		"data": gin.H{
+			"tour_id":             result.Tour.ID,
+			"tour":                result.Tour,
+			"images_count":        len(result.Images),
+			"destinations_count":  len(result.Destinations),
+			"itineraries_count":   len(result.Itineraries),
+			"departures_count":    len(result.Departures),
+			"images":              result.Images,
+			"itineraries":         result.Itineraries,
+			"departures":          result.Departures,
+			"phien_ban_cho_duyet": revision,
+		},
+	})
+}
+
+// buildTourDetailsParams chuyển request tạo / cập nhật tour đầy đủ thành params cho transaction
+func buildTourDetailsParams(req models.CreateTourRequest, supplierID pgtype.UUID) (db.CreateTourWithDetailsParams, error) {
 	// Tạo params cho transaction
 	trangThai := req.TrangThai
 	if trangThai == "" {
@@ -215,16 +385,10 @@ func (s *Server) CreateTour(c *gin.Context) {
 	}
 	var giaNguoiLon, giaTreEm pgtype.Numeric
 	if err := giaNguoiLon.Scan(fmt.Sprintf("%.2f", req.GiaNguoiLon)); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Giá không hợp lệ",
-		})
-		return
+		return db.CreateTourWithDetailsParams{}, errors.New("Giá không hợp lệ")
 	}
 	if err := giaTreEm.Scan(fmt.Sprintf("%.2f", req.GiaTreEm)); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Giá không hợp lệ",
-		})
-		return
+		return db.CreateTourWithDetailsParams{}, errors.New("Giá không hợp lệ")
 	}
 	params := db.CreateTourWithDetailsParams{
 		Tour: db.CreateTourParams{
@@ -238,7 +402,7 @@ func (s *Server) CreateTour(c *gin.Context) {
 			DonViTienTe:  &donViTienTe,
 			TrangThai:    &trangThai,
 			NoiBat:       &req.NoiBat,
-			NhaCungCapID: userUUID,
+			NhaCungCapID: supplierID,
 			DangHoatDong: helpers.NewBool(true),
 		},
 		HinhAnhTours:      make([]db.TourImageInput, 0),
@@ -294,9 +458,18 @@ func (s *Server) CreateTour(c *gin.Context) {
 
 	// Convert group config (if provided)
 	if req.CauHinhNhomTours != nil {
+		gc := req.CauHinhNhomTours
+		if gc.SoNhoNhat < 1 || gc.SoLonNhat < gc.SoNhoNhat {
+			return db.CreateTourWithDetailsParams{}, errors.New("Cấu hình nhóm không hợp lệ: so_nho_nhat phải >= 1 và không lớn hơn so_lon_nhat")
+		}
+		if gc.TuoiTreEmToiThieu != nil && gc.TuoiTreEmToiDa != nil && *gc.TuoiTreEmToiThieu > *gc.TuoiTreEmToiDa {
+			return db.CreateTourWithDetailsParams{}, errors.New("Cấu hình nhóm không hợp lệ: tuoi_tre_em_toi_thieu không được lớn hơn tuoi_tre_em_toi_da")
+		}
 		params.CauHinhNhomTours = &db.GroupConfigInput{
-			SoNhoNhat: &req.CauHinhNhomTours.SoNhoNhat,
-			SoLonNhat: &req.CauHinhNhomTours.SoLonNhat,
+			SoNhoNhat:         &gc.SoNhoNhat,
+			SoLonNhat:         &gc.SoLonNhat,
+			TuoiTreEmToiThieu: gc.TuoiTreEmToiThieu,
+			TuoiTreEmToiDa:    gc.TuoiTreEmToiDa,
 		}
 	}
 
@@ -312,30 +485,7 @@ func (s *Server) CreateTour(c *gin.Context) {
 		params.LichKhoiHanhTours = append(params.LichKhoiHanhTours, depInput)
 	}
 
-	// Execute transaction
-	result, err := s.z.CreateTourWithDetails(c.Request.Context(), params)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Không thể tạo tour",
-			"details": err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Tạo tour thành công",
-		"data": gin.H{
-			"tour_id":            result.Tour.ID,
-			"tour":               result.Tour,
-			"images_count":       len(result.Images),
-			"destinations_count": len(result.Destinations),
-			"itineraries_count":  len(result.Itineraries),
-			"departures_count":   len(result.Departures),
-			"images":             result.Images,
-			"itineraries":        result.Itineraries,
-			"departures":         result.Departures,
-		},
-	})
+	return params, nil
 }
 
 // Helper functions
@@ -525,6 +675,7 @@ func (s *Server) SearchTours(c *gin.Context) {
 // @param request body models.CreateDiscountTourRequest true "Khuyến mãi tour"
 // @security ApiKeyAuth
 // @success 200 {object} gin.H "Thành công"
+// @failure 403 {object} gin.H "Tour không thuộc nhà cung cấp của bạn"
 // @failure 500 {object} gin.H "Lỗi server"
 // @router /tour/discount [post]
 func (s *Server) CreateDiscountTour(c *gin.Context) {
@@ -536,6 +687,9 @@ func (s *Server) CreateDiscountTour(c *gin.Context) {
 		})
 		return
 	}
+	if _, ok := s.ensureTourOwned(c, req.TourID); !ok {
+		return
+	}
 	var phanTram pgtype.Numeric
 	err := phanTram.Scan(fmt.Sprintf("%.2f", req.PhanTram))
 	if err != nil {
@@ -591,6 +745,7 @@ func (s *Server) CreateDiscountTour(c *gin.Context) {
 // @param request body models.UpdateDiscountTourRequest true "Khuyến mãi tour"
 // @security ApiKeyAuth
 // @success 200 {object} gin.H "Thành công"
+// @failure 403 {object} gin.H "Tour không thuộc nhà cung cấp của bạn"
 // @failure 500 {object} gin.H "Lỗi server"
 // @router /tour/discount [put]
 func (s *Server) UpdateDiscountTour(c *gin.Context) {
@@ -601,6 +756,9 @@ func (s *Server) UpdateDiscountTour(c *gin.Context) {
 		})
 		return
 	}
+	if _, ok := s.ensureTourOwned(c, req.TourID); !ok {
+		return
+	}
 	var phanTram pgtype.Numeric
 	_ = phanTram.Scan(fmt.Sprintf("%.2f", req.PhanTram))
 	ngayBatDau, err := time.Parse(time.DateOnly, req.NgayBatDau)
@@ -648,6 +806,7 @@ func (s *Server) UpdateDiscountTour(c *gin.Context) {
 // @param id path int true "ID khuyến mãi tour"
 // @ApiKeyAuth ApiKeyAuth
 // @success 200 {object} gin.H "Thành công"
+// @failure 403 {object} gin.H "Tour không thuộc nhà cung cấp của bạn"
 // @failure 500 {object} gin.H "Lỗi server"
 // @router /tour/discount/{id} [delete]
 func (s *Server) DeleteDiscountTour(c *gin.Context) {
@@ -658,16 +817,24 @@ func (s *Server) DeleteDiscountTour(c *gin.Context) {
 		})
 		return
 	}
-	tourID, err := strconv.Atoi(c.Param("tour_id"))
+	discount, err := s.z.GetDiscountTourByID(c.Request.Context(), int32(id))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Tour ID không hợp lệ",
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy khuyến mãi tour"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Không thể lấy khuyến mãi tour",
+			"details": err.Error(),
 		})
 		return
 	}
+	if _, ok := s.ensureTourOwned(c, discount.TourID); !ok {
+		return
+	}
 	err = s.z.DeleteDiscountTour(c.Request.Context(), db.DeleteDiscountTourParams{
 		ID:     int32(id),
-		TourID: int32(tourID),
+		TourID: discount.TourID,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -690,6 +857,7 @@ func (s *Server) DeleteDiscountTour(c *gin.Context) {
 // @param id path int true "ID tour"
 // @security ApiKeyAuth
 // @success 200 {object} gin.H "Thành công"
+// @failure 403 {object} gin.H "Tour không thuộc nhà cung cấp của bạn"
 // @failure 500 {object} gin.H "Lỗi server"
 // @router /tour/discount/{id} [get]
 func (s *Server) GetDiscountsByTourID(c *gin.Context) {
@@ -700,6 +868,9 @@ func (s *Server) GetDiscountsByTourID(c *gin.Context) {
 		})
 		return
 	}
+	if _, ok := s.ensureTourOwned(c, int32(id)); !ok {
+		return
+	}
 	discounts, err := s.z.GetDiscountsByTourID(c.Request.Context(), int32(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -716,13 +887,17 @@ func (s *Server) GetDiscountsByTourID(c *gin.Context) {
 
 // cập nhật tour
 // @summary Cập nhật tour
-// @description Cập nhật tour
+// @description Cập nhật thông tin cơ bản của tour. Tour đang công bố hoặc chờ duyệt: thay đổi được gộp vào phiên bản chờ duyệt (202).
+// @description trang_thai chỉ nhận cong_bo (gửi duyệt tour nháp), các trạng thái khác đổi qua /supplier/tours/update-status/{id}
 // @tags tour
 // @accept json
 // @produce json
 // @param request body models.UpdateTourRequest true "Tour"
 // @security ApiKeyAuth
 // @success 200 {object} gin.H "Thành công"
+// @success 202 {object} gin.H "Đã gửi chỉnh sửa chờ duyệt"
+// @failure 403 {object} gin.H "Tour không thuộc nhà cung cấp của bạn"
+// @failure 404 {object} gin.H "Không tìm thấy tour"
 // @failure 500 {object} gin.H "Lỗi server"
 // @router /tour/{id} [put]
 func (s *Server) UpdateTour(c *gin.Context) {
@@ -754,13 +929,16 @@ func (s *Server) UpdateTour(c *gin.Context) {
 		})
 		return
 	}
-	var nhaCungCapID pgtype.UUID
-	if err := nhaCungCapID.Scan(req.NhaCungCapID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Nha cung cap ID không hợp lệ",
-		})
+	existing, ok := s.ensureTourOwned(c, int32(id))
+	if !ok {
 		return
 	}
+	if tourUnderReview(existing.TrangThai) {
+		s.submitTourBasicRevision(c, existing, req)
+		return
+	}
+
+	// Trạng thái giữ nguyên (COALESCE), tour chỉ được công bố sau khi duyệt
 	result, err := s.z.UpdateTour(c.Request.Context(), db.UpdateTourParams{
 		ID:           int32(id),
 		TieuDe:       &req.TieuDe,
@@ -771,9 +949,8 @@ func (s *Server) UpdateTour(c *gin.Context) {
 		GiaNguoiLon:  giaNguoiLon,
 		GiaTreEm:     giaTreEm,
 		DonViTienTe:  &req.DonViTienTe,
-		TrangThai:    &req.TrangThai,
 		NoiBat:       &req.NoiBat,
-		NhaCungCapID: nhaCungCapID,
+		NhaCungCapID: existing.NhaCungCapID,
 		DangHoatDong: helpers.NewBool(true),
 	})
 	if err != nil {
@@ -783,8 +960,71 @@ func (s *Server) UpdateTour(c *gin.Context) {
 		})
 		return
 	}
+	s.scheduleTourEmbedding(result.ID)
+
+	var revision any
+	if req.TrangThai == db.TourStatusPublished {
+		submitted, err := s.submitTourRevision(c, result.ID, db.TourRevisionSubmit, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Đã lưu tour nhưng không thể gửi duyệt", "details": err.Error()})
+			return
+		}
+		pending := db.TourStatusPending
+		result.TrangThai = &pending
+		revision = tourRevisionResponse(submitted)
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Cập nhật tour thành công",
-		"data":    result,
+		"message":             "Cập nhật tour thành công",
+		"data":                result,
+		"phien_ban_cho_duyet": revision,
+	})
+}
+
+// submitTourBasicRevision gộp thông tin cơ bản mới vào nội dung đang chờ duyệt (hoặc nội dung hiện tại nếu chưa có)
+// của tour đang công bố / chờ duyệt và gửi thành phiên bản chờ duyệt
+func (s *Server) submitTourBasicRevision(c *gin.Context, tour db.Tour, req models.UpdateTourRequest) {
+	var noiDung []byte
+	pending, err := s.z.GetPendingTourRevision(c.Request.Context(), tour.ID)
+	switch {
+	case err == nil:
+		noiDung = pending.NoiDung
+	case errors.Is(err, pgx.ErrNoRows):
+		noiDung, err = s.z.GetTourContentSnapshot(c.Request.Context(), tour.ID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy nội dung tour", "details": err.Error()})
+		return
+	}
+
+	var content models.CreateTourRequest
+	if err := json.Unmarshal(noiDung, &content); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể đọc nội dung tour", "details": err.Error()})
+		return
+	}
+	content.TieuDe = req.TieuDe
+	content.MoTa = req.MoTa
+	content.DanhMucID = req.DanhMucID
+	content.SoNgay = req.SoNgay
+	content.SoDem = req.SoDem
+	content.GiaNguoiLon = req.GiaNguoiLon
+	content.GiaTreEm = req.GiaTreEm
+	if req.DonViTienTe != "" {
+		content.DonViTienTe = req.DonViTienTe
+	}
+	content.NoiBat = req.NoiBat
+	content.TrangThai = ""
+	if noiDung, err = json.Marshal(content); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lưu nội dung tour", "details": err.Error()})
+		return
+	}
+
+	revision, err := s.submitTourRevision(c, tour.ID, tourRevisionKind(tour.TrangThai), noiDung, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể gửi chỉnh sửa chờ duyệt", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Đã gửi chỉnh sửa, tour sẽ cập nhật khi được duyệt",
+		"data":    tourRevisionResponse(revision),
 	})
 }
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pgvector/pgvector-go"
+	"travia.backend/api/services"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	TourEmbeddingSyncInterval = 10 * time.Minute
+	tourEmbeddingBatchSize    = 50
+	queryEmbeddingCacheTTL    = 24 * time.Hour
+	semanticQueryMaxLength    = 500
+)
+
+// SetupEmbedder khởi tạo bộ tạo embedding theo cấu hình (EMBEDDING_PROVIDER, OPENAI_API_KEY)
+func (s *Server) SetupEmbedder() {
+	embedder, err := services.NewEmbedder(s.config.OpenAIConfig.EmbeddingProvider, s.config.OpenAIConfig.APIKey)
+	if err != nil {
+		log.Printf("[Embedding] %v, dùng bộ tạo embedding local", err)
+		embedder = services.NewLocalEmbedder()
+	}
+	s.embedder = embedder
+	log.Printf("[Embedding] model: %s", embedder.Model())
+}
+
+// scheduleTourEmbedding tạo lại embedding của tour ở nền sau khi nội dung tour thay đổi.
+// Bỏ qua nếu nội dung không đổi; lỗi sẽ được StartTourEmbeddingSync thử lại ở lần quét sau
+func (s *Server) scheduleTourEmbedding(tourID int32) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if _, err := s.syncTourEmbeddings(ctx, &tourID, 1); err != nil {
+			log.Printf("[Embedding] tour %d: %v", tourID, err)
+		}
+	}()
+}
+
+// StartTourEmbeddingSync định kỳ tạo embedding cho tour chưa có, có nội dung đã đổi
+// (kể cả khi sửa lịch trình / hoạt động / điểm đến riêng lẻ) hoặc được tạo bằng mô hình khác.
+// Dừng khi ctx bị hủy.
+func (s *Server) StartTourEmbeddingSync(ctx context.Context) {
+	ticker := time.NewTicker(TourEmbeddingSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		s.syncStaleTourEmbeddings(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) syncStaleTourEmbeddings(ctx context.Context) {
+	syncCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	total := 0
+	for {
+		n, err := s.syncTourEmbeddings(syncCtx, nil, tourEmbeddingBatchSize)
+		total += n
+		if err != nil {
+			log.Printf("[Embedding] sync failed after %d tours: %v", total, err)
+			return
+		}
+		if n < tourEmbeddingBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("[Embedding] %d tour embeddings updated", total)
+	}
+}
+
+// syncTourEmbeddings tạo lại embedding cho tối đa limit tour lỗi thời (tourID = nil: mọi tour), trả về số tour đã cập nhật
+func (s *Server) syncTourEmbeddings(ctx context.Context, tourID *int32, limit int32) (int, error) {
+	model := s.embedder.Model()
+	sources, err := s.z.GetStaleTourEmbeddingSources(ctx, db.GetStaleTourEmbeddingSourcesParams{
+		MoHinh: model,
+		TourID: tourID,
+		Limit:  limit,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, src := range sources {
+		vec, err := s.embedder.Embed(ctx, src.NoiDung)
+		if err != nil {
+			return i, err
+		}
+		embedding := pgvector.NewVector(vec)
+		if err := s.z.UpsertTourEmbedding(ctx, db.UpsertTourEmbeddingParams{
+			TourID:      src.ID,
+			Embedding:   &embedding,
+			MoHinh:      &model,
+			NoiDungHash: &src.NoiDungHash,
+		}); err != nil {
+			return i, err
+		}
+	}
+	return len(sources), nil
+}
+
+// embedQuery tạo embedding cho câu tìm kiếm, cache trong Redis theo mô hình + nội dung câu
+func (s *Server) embedQuery(ctx context.Context, query string) ([]float32, error) {
+	sum := sha256.Sum256([]byte(strings.ToLower(query)))
+	key := "embedding:query:" + s.embedder.Model() + ":" + hex.EncodeToString(sum[:])
+
+	if cached, err := s.redis.Get(ctx, key).Bytes(); err == nil {
+		var vec []float32
+		if json.Unmarshal(cached, &vec) == nil && len(vec) > 0 {
+			return vec, nil
+		}
+	}
+
+	vec, err := s.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(vec); err == nil {
+		s.redis.Set(ctx, key, data, queryEmbeddingCacheTTL)
+	}
+	return vec, nil
+}
+
+// SearchToursSemantic godoc
+// @Summary Tìm kiếm tour bằng ngôn ngữ tự nhiên
+// @Description Xếp hạng tour theo độ tương đồng ngữ nghĩa giữa câu tìm kiếm (vd: "chuyến biển yên tĩnh cho bố mẹ lớn tuổi vào tháng 3") và nội dung tour.
+// @Description Các bộ lọc có cấu trúc (danh mục, điểm đến, giá, thời lượng, đánh giá, ngày khởi hành, giảm giá) là điều kiện bắt buộc
+// @Tags tour
+// @Produce json
+// @Param q query string true "Câu tìm kiếm (tối đa 500 ký tự)"
+// @Param danh_muc_id query []int false "ID danh mục (lặp lại hoặc phân tách bằng dấu phẩy)"
+// @Param diem_den_id query []int false "ID điểm đến (lặp lại hoặc phân tách bằng dấu phẩy)"
+// @Param gia_min query number false "Giá người lớn sau giảm tối thiểu"
+// @Param gia_max query number false "Giá người lớn sau giảm tối đa"
+// @Param so_ngay_min query int false "Số ngày tối thiểu"
+// @Param so_ngay_max query int false "Số ngày tối đa"
+// @Param rating_min query number false "Điểm đánh giá tối thiểu (0 - 5)"
+// @Param khoi_hanh_tu query string false "Có ngày khởi hành từ (YYYY-MM-DD)"
+// @Param khoi_hanh_den query string false "Có ngày khởi hành đến (YYYY-MM-DD)"
+// @Param co_giam_gia query bool false "Đang có giảm giá"
+// @Param do_tuong_dong_min query number false "Độ tương đồng tối thiểu (0 - 1)"
+// @Param limit query int false "Số kết quả (tối đa 100)" default(20)
+// @Param offset query int false "Vị trí bắt đầu" default(0)
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /tour/search/semantic [get]
+func (s *Server) SearchToursSemantic(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Thiếu câu tìm kiếm (q)"})
+		return
+	}
+	if len([]rune(query)) > semanticQueryMaxLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Câu tìm kiếm quá dài", "details": "tối đa 500 ký tự"})
+		return
+	}
+	filters, err := parseTourSearchFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tham số tìm kiếm không hợp lệ", "details": err.Error()})
+		return
+	}
+	var minSimilarity *float64
+	if v := c.Query("do_tuong_dong_min"); v != "" {
+		sim, err := strconv.ParseFloat(v, 64)
+		if err != nil || sim < 0 || sim > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "do_tuong_dong_min phải trong khoảng 0 - 1"})
+			return
+		}
+		minSimilarity = &sim
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	vec, err := s.embedQuery(ctx, query)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Không thể phân tích câu tìm kiếm", "details": err.Error()})
+		return
+	}
+	embedding := pgvector.NewVector(vec)
+
+	tours, err := s.z.SearchToursSemantic(ctx, db.SearchToursSemanticParams{
+		Embedding:      &embedding,
+		MoHinh:         s.embedder.Model(),
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+		DanhMucIds:     filters.DanhMucIds,
+		DiemDenIds:     filters.DiemDenIds,
+		GiaMin:         filters.GiaMin,
+		GiaMax:         filters.GiaMax,
+		SoNgayMin:      filters.SoNgayMin,
+		SoNgayMax:      filters.SoNgayMax,
+		RatingMin:      filters.RatingMin,
+		KhoiHanhTu:     filters.KhoiHanhTu,
+		KhoiHanhDen:    filters.KhoiHanhDen,
+		CoGiamGia:      filters.CoGiamGia,
+		DoTuongDongMin: minSimilarity,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tìm kiếm tour", "details": err.Error()})
+		return
+	}
+
+	var total int32
+	if len(tours) > 0 {
+		total = tours[0].Tong
+		s.recordSearchQuery(ctx, query)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":     tours,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": offset+len(tours) < int(total),
+		"mo_hinh":  s.embedder.Model(),
+	})
+}
@@ -0,0 +1,640 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// tourUnderReview cho biết nội dung tour không được sửa trực tiếp: tour đang công bố (sửa tạo phiên bản chờ duyệt)
+// hoặc đang chờ duyệt (sửa thay phiên bản đang chờ)
+func tourUnderReview(status *string) bool {
+	return status != nil && (*status == db.TourStatusPublished || *status == db.TourStatusPending)
+}
+
+// tourRevisionKind là loại phiên bản tạo ra khi gửi nội dung của tour ở trạng thái status
+func tourRevisionKind(status *string) string {
+	if status != nil && *status == db.TourStatusPublished {
+		return db.TourRevisionEdit
+	}
+	return db.TourRevisionSubmit
+}
+
+// submitTourRevision gửi nội dung tour chờ duyệt (noiDung nil: chụp nội dung hiện tại), người gửi là người đang đăng nhập
+func (s *Server) submitTourRevision(c *gin.Context, tourID int32, loai string, noiDung []byte, ghiChu *string) (db.PhienBanTour, error) {
+	arg := db.CreateTourRevisionParams{
+		TourID:  tourID,
+		Loai:    loai,
+		NoiDung: noiDung,
+		GhiChu:  ghiChu,
+	}
+	if v, exists := c.Get("claims"); exists {
+		if claims, ok := v.(*utils.JwtClams); ok {
+			arg.NguoiGuiID = claims.Id
+		}
+	}
+	return s.z.SubmitTourRevision(c.Request.Context(), arg)
+}
+
+// ensureTourOwned trả về false (và đã ghi response) nếu tour không tồn tại hoặc không thuộc nhà cung cấp
+// của người dùng (xác định bởi SupplierContext)
+func (s *Server) ensureTourOwned(c *gin.Context, tourID int32) (db.Tour, bool) {
+	tour, err := s.z.GetTourByID(c.Request.Context(), tourID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy tour"})
+			return db.Tour{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy tour", "details": err.Error()})
+		return db.Tour{}, false
+	}
+	if tour.NhaCungCapID != currentSupplierID(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Tour không thuộc nhà cung cấp của bạn"})
+		return db.Tour{}, false
+	}
+	return tour, true
+}
+
+// ensureTourEditable trả về false (và đã ghi response) nếu tour không thuộc nhà cung cấp của người dùng
+// hoặc đang công bố / chờ duyệt: các API sửa từng phần (lịch trình, hoạt động, ảnh, điểm đến) chỉ áp dụng cho tour chưa công bố
+func (s *Server) ensureTourEditable(c *gin.Context, tourID int32) bool {
+	tour, ok := s.ensureTourOwned(c, tourID)
+	if !ok {
+		return false
+	}
+	if tourUnderReview(tour.TrangThai) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Tour đang công bố hoặc chờ duyệt, không thể sửa trực tiếp",
+			"details": "Gửi nội dung mới qua PUT /tour/{id}/full để tạo phiên bản chờ duyệt",
+		})
+		return false
+	}
+	return true
+}
+
+// ensureDepartureOwned trả về false (và đã ghi response) nếu lịch khởi hành không tồn tại
+// hoặc thuộc tour của nhà cung cấp khác
+func (s *Server) ensureDepartureOwned(c *gin.Context, departureID int32) (db.GetDepartureByIDRow, bool) {
+	departure, err := s.z.GetDepartureByID(c.Request.Context(), departureID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy lịch khởi hành"})
+			return db.GetDepartureByIDRow{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy lịch khởi hành", "details": err.Error()})
+		return db.GetDepartureByIDRow{}, false
+	}
+	if _, ok := s.ensureTourOwned(c, departure.TourID); !ok {
+		return db.GetDepartureByIDRow{}, false
+	}
+	return departure, true
+}
+
+// tourRevisionDetails dựng params cập nhật tour từ nội dung phiên bản; tour được công bố khi áp dụng
+func tourRevisionDetails(noiDung []byte, supplierID pgtype.UUID) (db.CreateTourWithDetailsParams, error) {
+	var req models.CreateTourRequest
+	if err := json.Unmarshal(noiDung, &req); err != nil {
+		return db.CreateTourWithDetailsParams{}, fmt.Errorf("nội dung phiên bản không hợp lệ: %w", err)
+	}
+	params, err := buildTourDetailsParams(req, supplierID)
+	if err != nil {
+		return db.CreateTourWithDetailsParams{}, err
+	}
+	published := db.TourStatusPublished
+	params.Tour.TrangThai = &published
+	return params, nil
+}
+
+func tourRevisionResponse(r db.PhienBanTour) gin.H {
+	return gin.H{
+		"id":             r.ID,
+		"tour_id":        r.TourID,
+		"so_phien_ban":   r.SoPhienBan,
+		"loai":           r.Loai,
+		"trang_thai":     r.TrangThai,
+		"noi_dung":       rawJSONOrNil(r.NoiDung),
+		"ghi_chu":        r.GhiChu,
+		"nguoi_gui_id":   r.NguoiGuiID,
+		"nguoi_duyet_id": r.NguoiDuyetID,
+		"nhan_xet_duyet": r.NhanXetDuyet,
+		"ngay_tao":       r.NgayTao,
+		"ngay_duyet":     r.NgayDuyet,
+	}
+}
+
+// tourRevisionAuditState là trạng thái phiên bản ghi vào nhật ký kiểm toán (không kèm nội dung)
+func tourRevisionAuditState(r db.PhienBanTour) gin.H {
+	return gin.H{
+		"tour_id":        r.TourID,
+		"so_phien_ban":   r.SoPhienBan,
+		"loai":           r.Loai,
+		"trang_thai":     r.TrangThai,
+		"nhan_xet_duyet": r.NhanXetDuyet,
+	}
+}
+
+// notifyTourRevisionReviewed báo kết quả duyệt cho người đã gửi phiên bản
+func (s *Server) notifyTourRevisionReviewed(ctx context.Context, r db.PhienBanTour, tenTour string) {
+	if !r.NguoiGuiID.Valid {
+		return
+	}
+	tieuDe := "Tour đã được duyệt"
+	noiDung := fmt.Sprintf("Phiên bản %d của tour %s đã được duyệt và đang hiển thị.", r.SoPhienBan, tenTour)
+	if r.TrangThai == db.TourRevisionRejected {
+		tieuDe = "Tour bị từ chối"
+		noiDung = fmt.Sprintf("Phiên bản %d của tour %s bị từ chối.", r.SoPhienBan, tenTour)
+		if r.NhanXetDuyet != nil {
+			noiDung += " Nhận xét: " + *r.NhanXetDuyet
+		}
+	}
+	loai := "system"
+	lienKet := fmt.Sprintf("/supplier/tours/%d/revisions", r.TourID)
+	if _, err := s.z.CreateNotification(ctx, db.CreateNotificationParams{
+		NguoiDungID: r.NguoiGuiID,
+		TieuDe:      &tieuDe,
+		NoiDung:     &noiDung,
+		Loai:        &loai,
+		LienKet:     &lienKet,
+	}); err != nil {
+		log.Printf("[Moderation] notify revision %d failed: %v", r.ID, err)
+	}
+}
+
+// getOwnedTour trả về tour của nhà cung cấp hiện tại; false (đã ghi response) nếu không tồn tại hoặc không thuộc nhà cung cấp
+func (s *Server) getOwnedTour(c *gin.Context, tourID int32) (db.Tour, bool) {
+	tour, err := s.z.GetTourByID(c.Request.Context(), tourID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy tour"})
+			return tour, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy tour", "details": err.Error()})
+		return tour, false
+	}
+	if tour.NhaCungCapID != currentSupplierID(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Tour không thuộc nhà cung cấp của bạn"})
+		return tour, false
+	}
+	return tour, true
+}
+
+// SubmitTourForReview godoc
+// @Summary Gửi tour chờ duyệt
+// @Description Gửi nội dung hiện tại của bản nháp (hoặc tour bị từ chối / lưu trữ) cho quản trị viên duyệt. Tour chuyển sang cho_duyet và chỉ công bố khi được duyệt.
+// @Description Tour đang công bố thì gửi chỉnh sửa qua PUT /tour/{id}/full
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Param request body models.SubmitTourReviewRequest false "Ghi chú cho người duyệt"
+// @Security ApiKeyAuth
+// @Success 201 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/tours/{id}/submit [post]
+func (s *Server) SubmitTourForReview(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID không hợp lệ"})
+		return
+	}
+	var req models.SubmitTourReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dữ liệu không hợp lệ", "details": err.Error()})
+		return
+	}
+
+	tour, ok := s.getOwnedTour(c, int32(id))
+	if !ok {
+		return
+	}
+	if tour.TrangThai != nil && *tour.TrangThai == db.TourStatusPublished {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Tour đã công bố",
+			"details": "Gửi chỉnh sửa qua PUT /tour/{id}/full để tạo phiên bản chờ duyệt",
+		})
+		return
+	}
+
+	revision, err := s.submitTourRevision(c, tour.ID, db.TourRevisionSubmit, nil, stringPtrIfNotEmpty(req.GhiChu))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể gửi duyệt tour", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Đã gửi tour chờ duyệt", "data": tourRevisionResponse(revision)})
+}
+
+// WithdrawTourRevision godoc
+// @Summary Rút phiên bản chờ duyệt
+// @Description Hủy phiên bản đang chờ duyệt của tour; bản nháp đang chờ duyệt quay về nhap, tour đang công bố giữ nguyên nội dung hiện tại
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/tours/{id}/revisions/pending [delete]
+func (s *Server) WithdrawTourRevision(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID không hợp lệ"})
+		return
+	}
+	if _, ok := s.getOwnedTour(c, int32(id)); !ok {
+		return
+	}
+	if _, err := s.z.GetPendingTourRevision(c.Request.Context(), int32(id)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tour không có phiên bản chờ duyệt"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy phiên bản chờ duyệt", "details": err.Error()})
+		return
+	}
+
+	tour, err := s.z.WithdrawTourRevision(c.Request.Context(), db.UpdateTourStatusParams{
+		ID:           int32(id),
+		NhaCungCapID: currentSupplierID(c),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể rút phiên bản chờ duyệt", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Đã rút phiên bản chờ duyệt", "data": tour})
+}
+
+// GetTourRevisionHistory godoc
+// @Summary Lịch sử phiên bản tour
+// @Description Các phiên bản nội dung (tour, lịch trình, hoạt động, ảnh, điểm đến) đã gửi duyệt của tour, mới nhất trước, kèm trạng thái và nhận xét duyệt
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Param limit query int false "Số phiên bản (tối đa 100)" default(20)
+// @Param offset query int false "Vị trí bắt đầu" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /supplier/tours/{id}/revisions [get]
+func (s *Server) GetTourRevisionHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID không hợp lệ"})
+		return
+	}
+	if _, ok := s.getOwnedTour(c, int32(id)); !ok {
+		return
+	}
+	limit, offset := moderationPagination(c)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.z.ListTourRevisionsByTour(ctx, db.ListTourRevisionsByTourParams{
+		TourID: int32(id),
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy lịch sử phiên bản", "details": err.Error()})
+		return
+	}
+
+	var total int32
+	data := make([]gin.H, 0, len(rows))
+	for _, r := range rows {
+		total = r.Tong
+		data = append(data, tourRevisionResponse(db.PhienBanTour{
+			ID:           r.ID,
+			TourID:       r.TourID,
+			SoPhienBan:   r.SoPhienBan,
+			Loai:         r.Loai,
+			TrangThai:    r.TrangThai,
+			NoiDung:      r.NoiDung,
+			GhiChu:       r.GhiChu,
+			NguoiGuiID:   r.NguoiGuiID,
+			NguoiDuyetID: r.NguoiDuyetID,
+			NhanXetDuyet: r.NhanXetDuyet,
+			NgayTao:      r.NgayTao,
+			NgayDuyet:    r.NgayDuyet,
+		}))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":     data,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": offset+len(rows) < int(total),
+	})
+}
+
+func moderationPagination(c *gin.Context) (int, int) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// GetTourRevisionQueue godoc
+// @Summary Hàng đợi kiểm duyệt tour
+// @Description Danh sách phiên bản tour theo trạng thái (mặc định cho_duyet, cũ nhất trước): bản nháp gửi duyệt lần đầu (gui_duyet) và chỉnh sửa tour đang công bố (chinh_sua)
+// @Tags Admin
+// @Produce json
+// @Param trang_thai query string false "cho_duyet | da_duyet | tu_choi | da_huy" default(cho_duyet)
+// @Param loai query string false "gui_duyet | chinh_sua"
+// @Param tour_id query int false "Tour ID"
+// @Param nha_cung_cap_id query string false "ID nhà cung cấp"
+// @Param limit query int false "Số phiên bản (tối đa 100)" default(20)
+// @Param offset query int false "Vị trí bắt đầu" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/tour-revisions [get]
+func (s *Server) GetTourRevisionQueue(c *gin.Context) {
+	var params db.ListTourRevisionsParams
+	if v := c.Query("trang_thai"); v != "" {
+		switch v {
+		case db.TourRevisionPending, db.TourRevisionApproved, db.TourRevisionRejected, db.TourRevisionCancelled:
+			params.TrangThai = &v
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "trang_thai không hợp lệ"})
+			return
+		}
+	}
+	if v := c.Query("loai"); v != "" {
+		if v != db.TourRevisionSubmit && v != db.TourRevisionEdit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "loai phải là gui_duyet hoặc chinh_sua"})
+			return
+		}
+		params.Loai = &v
+	}
+	if v := c.Query("tour_id"); v != "" {
+		tourID, err := strconv.Atoi(v)
+		if err != nil || tourID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "tour_id không hợp lệ"})
+			return
+		}
+		id := int32(tourID)
+		params.TourID = &id
+	}
+	if v := c.Query("nha_cung_cap_id"); v != "" {
+		if err := params.NhaCungCapID.Scan(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "nha_cung_cap_id không hợp lệ"})
+			return
+		}
+	}
+	limit, offset := moderationPagination(c)
+	params.Limit = int32(limit)
+	params.Offset = int32(offset)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.z.ListTourRevisions(ctx, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy hàng đợi kiểm duyệt", "details": err.Error()})
+		return
+	}
+	var total int32
+	if len(rows) > 0 {
+		total = rows[0].Tong
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":     rows,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": offset+len(rows) < int(total),
+	})
+}
+
+// GetTourRevisionDetail godoc
+// @Summary Chi tiết phiên bản tour
+// @Description Nội dung phiên bản gửi duyệt (noi_dung) kèm nội dung đang áp dụng của tour (hien_tai) để so sánh
+// @Tags Admin
+// @Produce json
+// @Param id path int true "ID phiên bản"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/tour-revisions/{id} [get]
+func (s *Server) GetTourRevisionDetail(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID không hợp lệ"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rev, err := s.z.GetTourRevisionByID(ctx, int32(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy phiên bản"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy phiên bản", "details": err.Error()})
+		return
+	}
+	current, err := s.z.GetTourContentSnapshot(ctx, rev.TourID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy nội dung hiện tại của tour", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"id":               rev.ID,
+			"tour_id":          rev.TourID,
+			"so_phien_ban":     rev.SoPhienBan,
+			"loai":             rev.Loai,
+			"trang_thai":       rev.TrangThai,
+			"ghi_chu":          rev.GhiChu,
+			"nguoi_gui_id":     rev.NguoiGuiID,
+			"nguoi_duyet_id":   rev.NguoiDuyetID,
+			"nhan_xet_duyet":   rev.NhanXetDuyet,
+			"ngay_tao":         rev.NgayTao,
+			"ngay_duyet":       rev.NgayDuyet,
+			"tieu_de_tour":     rev.TieuDeTour,
+			"trang_thai_tour":  rev.TrangThaiTour,
+			"nha_cung_cap_id":  rev.NhaCungCapID,
+			"ten_nha_cung_cap": rev.TenNhaCungCap,
+			"noi_dung":         rawJSONOrNil(rev.NoiDung),
+			"hien_tai":         rawJSONOrNil(current),
+		},
+	})
+}
+
+// loadPendingTourRevision đọc phiên bản cần duyệt; false (đã ghi response) nếu không tồn tại hoặc không còn chờ duyệt
+func (s *Server) loadPendingTourRevision(c *gin.Context) (db.GetTourRevisionByIDRow, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID không hợp lệ"})
+		return db.GetTourRevisionByIDRow{}, false
+	}
+	rev, err := s.z.GetTourRevisionByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy phiên bản"})
+			return rev, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy phiên bản", "details": err.Error()})
+		return rev, false
+	}
+	if rev.TrangThai != db.TourRevisionPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Phiên bản không còn chờ duyệt", "details": "trang_thai: " + rev.TrangThai})
+		return rev, false
+	}
+	return rev, true
+}
+
+// ApproveTourRevision godoc
+// @Summary Duyệt phiên bản tour
+// @Description Áp dụng nội dung phiên bản lên tour và công bố tour; thông báo cho người gửi
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID phiên bản"
+// @Param request body models.ReviewTourRevisionRequest false "Nhận xét"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/tour-revisions/{id}/approve [put]
+func (s *Server) ApproveTourRevision(c *gin.Context) {
+	var req models.ReviewTourRevisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dữ liệu không hợp lệ", "details": err.Error()})
+		return
+	}
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	rev, ok := s.loadPendingTourRevision(c)
+	if !ok {
+		return
+	}
+	details, err := tourRevisionDetails(rev.NoiDung, rev.NhaCungCapID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nội dung phiên bản không hợp lệ", "details": err.Error()})
+		return
+	}
+
+	revision, result, err := s.z.ApproveTourRevision(c.Request.Context(), rev.TourID, db.ReviewTourRevisionParams{
+		ID:           rev.ID,
+		NguoiDuyetID: claims.Id,
+		NhanXetDuyet: stringPtrIfNotEmpty(req.NhanXet),
+	}, details)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Phiên bản không còn chờ duyệt"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể duyệt phiên bản", "details": err.Error()})
+		return
+	}
+	s.scheduleTourEmbedding(rev.TourID)
+	s.notifyTourRevisionReviewed(c.Request.Context(), revision, result.Tour.TieuDe)
+	s.recordAudit(c, AuditTourRevisionApprove, AuditTargetTourRevision, strconv.Itoa(int(rev.ID)),
+		gin.H{"tour_id": rev.TourID, "so_phien_ban": rev.SoPhienBan, "loai": rev.Loai, "trang_thai": rev.TrangThai, "trang_thai_tour": rev.TrangThaiTour},
+		tourRevisionAuditState(revision))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã duyệt và công bố tour",
+		"data": gin.H{
+			"phien_ban": tourRevisionResponse(revision),
+			"tour":      result.Tour,
+		},
+	})
+}
+
+// RejectTourRevision godoc
+// @Summary Từ chối phiên bản tour
+// @Description Từ chối phiên bản kèm nhận xét (bắt buộc). Bản nháp gửi duyệt bị từ chối thì tour chuyển sang tu_choi; chỉnh sửa tour đang công bố bị từ chối thì tour giữ nguyên nội dung đang hiển thị
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID phiên bản"
+// @Param request body models.ReviewTourRevisionRequest true "Nhận xét"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/tour-revisions/{id}/reject [put]
+func (s *Server) RejectTourRevision(c *gin.Context) {
+	var req models.ReviewTourRevisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dữ liệu không hợp lệ", "details": err.Error()})
+		return
+	}
+	if req.NhanXet == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cần nhận xét lý do từ chối (nhan_xet)"})
+		return
+	}
+	claims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	rev, ok := s.loadPendingTourRevision(c)
+	if !ok {
+		return
+	}
+
+	revision, err := s.z.RejectTourRevision(c.Request.Context(), rev.TourID, db.ReviewTourRevisionParams{
+		ID:           rev.ID,
+		NguoiDuyetID: claims.Id,
+		NhanXetDuyet: &req.NhanXet,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Phiên bản không còn chờ duyệt"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể từ chối phiên bản", "details": err.Error()})
+		return
+	}
+	s.notifyTourRevisionReviewed(c.Request.Context(), revision, rev.TieuDeTour)
+	s.recordAudit(c, AuditTourRevisionReject, AuditTargetTourRevision, strconv.Itoa(int(rev.ID)),
+		gin.H{"tour_id": rev.TourID, "so_phien_ban": rev.SoPhienBan, "loai": rev.Loai, "trang_thai": rev.TrangThai, "trang_thai_tour": rev.TrangThaiTour},
+		tourRevisionAuditState(revision))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã từ chối phiên bản", "data": tourRevisionResponse(revision)})
+}
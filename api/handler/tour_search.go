@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "travia.backend/db/sqlc"
+)
+
+// tourSearchBucket là một lựa chọn cố định trong facet khoảng giá / thời lượng.
+// Ngưỡng phải khớp với các CASE trong GetTourSearchFacets (db/query/tour_search.sql)
+type tourSearchBucket struct {
+	Khoa    string   `json:"khoa"`
+	Nhan    string   `json:"nhan"`
+	Tu      *float64 `json:"tu"`
+	Den     *float64 `json:"den"`
+	SoLuong int32    `json:"so_luong"`
+}
+
+func bucketBound(v float64) *float64 { return &v }
+
+var tourPriceBuckets = []tourSearchBucket{
+	{Khoa: "duoi_2tr", Nhan: "Dưới 2 triệu", Den: bucketBound(2000000)},
+	{Khoa: "2tr_5tr", Nhan: "2 - 5 triệu", Tu: bucketBound(2000000), Den: bucketBound(5000000)},
+	{Khoa: "5tr_10tr", Nhan: "5 - 10 triệu", Tu: bucketBound(5000000), Den: bucketBound(10000000)},
+	{Khoa: "10tr_20tr", Nhan: "10 - 20 triệu", Tu: bucketBound(10000000), Den: bucketBound(20000000)},
+	{Khoa: "tren_20tr", Nhan: "Trên 20 triệu", Tu: bucketBound(20000000)},
+}
+
+var tourDurationBuckets = []tourSearchBucket{
+	{Khoa: "1_ngay", Nhan: "1 ngày", Tu: bucketBound(1), Den: bucketBound(1)},
+	{Khoa: "2_3_ngay", Nhan: "2 - 3 ngày", Tu: bucketBound(2), Den: bucketBound(3)},
+	{Khoa: "4_7_ngay", Nhan: "4 - 7 ngày", Tu: bucketBound(4), Den: bucketBound(7)},
+	{Khoa: "tren_7_ngay", Nhan: "Trên 7 ngày", Tu: bucketBound(8)},
+}
+
+var tourSearchSorts = map[string]bool{
+	"":           true,
+	"price_asc":  true,
+	"price_desc": true,
+	"rating":     true,
+	"departure":  true,
+	"newest":     true,
+}
+
+// tourSearchFacet là một lựa chọn trong facet danh mục / điểm đến
+type tourSearchFacet struct {
+	ID      int32  `json:"id"`
+	Ten     string `json:"ten"`
+	SoLuong int32  `json:"so_luong"`
+}
+
+// parseIDList nhận cả tham số lặp lại (?id=1&id=2) lẫn danh sách phân tách bằng dấu phẩy (?id=1,2)
+func parseIDList(values []string) ([]int32, error) {
+	var ids []int32
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.Atoi(part)
+			if err != nil || id <= 0 {
+				return nil, fmt.Errorf("id không hợp lệ: %s", part)
+			}
+			ids = append(ids, int32(id))
+		}
+	}
+	return ids, nil
+}
+
+// parseTourSearchFilters đọc bộ lọc chung của trang kết quả và facet
+func parseTourSearchFilters(c *gin.Context) (db.GetTourSearchFacetsParams, error) {
+	var f db.GetTourSearchFacetsParams
+
+	if kw := strings.TrimSpace(c.Query("keyword")); kw != "" {
+		f.Keyword = &kw
+	}
+
+	var err error
+	if f.DanhMucIds, err = parseIDList(c.QueryArray("danh_muc_id")); err != nil {
+		return f, fmt.Errorf("danh_muc_id: %w", err)
+	}
+	if f.DiemDenIds, err = parseIDList(c.QueryArray("diem_den_id")); err != nil {
+		return f, fmt.Errorf("diem_den_id: %w", err)
+	}
+
+	parsePrice := func(name string, dst *pgtype.Numeric) error {
+		v := c.Query(name)
+		if v == "" {
+			return nil
+		}
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			return fmt.Errorf("%s không hợp lệ", name)
+		}
+		return dst.Scan(fmt.Sprintf("%.2f", price))
+	}
+	if err := parsePrice("gia_min", &f.GiaMin); err != nil {
+		return f, err
+	}
+	if err := parsePrice("gia_max", &f.GiaMax); err != nil {
+		return f, err
+	}
+
+	parseDays := func(name string) (*int32, error) {
+		v := c.Query(name)
+		if v == "" {
+			return nil, nil
+		}
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			return nil, fmt.Errorf("%s không hợp lệ", name)
+		}
+		d := int32(days)
+		return &d, nil
+	}
+	if f.SoNgayMin, err = parseDays("so_ngay_min"); err != nil {
+		return f, err
+	}
+	if f.SoNgayMax, err = parseDays("so_ngay_max"); err != nil {
+		return f, err
+	}
+
+	if v := c.Query("rating_min"); v != "" {
+		rating, err := strconv.ParseFloat(v, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			return f, fmt.Errorf("rating_min phải trong khoảng 0 - 5")
+		}
+		f.RatingMin = &rating
+	}
+
+	parseDate := func(name string, dst *pgtype.Date) error {
+		v := c.Query(name)
+		if v == "" {
+			return nil
+		}
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return fmt.Errorf("%s phải có định dạng YYYY-MM-DD", name)
+		}
+		*dst = pgtype.Date{Time: d, Valid: true}
+		return nil
+	}
+	if err := parseDate("khoi_hanh_tu", &f.KhoiHanhTu); err != nil {
+		return f, err
+	}
+	if err := parseDate("khoi_hanh_den", &f.KhoiHanhDen); err != nil {
+		return f, err
+	}
+	if f.KhoiHanhTu.Valid && f.KhoiHanhDen.Valid && f.KhoiHanhDen.Time.Before(f.KhoiHanhTu.Time) {
+		return f, fmt.Errorf("khoi_hanh_den phải sau khoi_hanh_tu")
+	}
+
+	if v := c.Query("co_giam_gia"); v != "" {
+		hasDiscount, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("co_giam_gia phải là true hoặc false")
+		}
+		f.CoGiamGia = &hasDiscount
+	}
+	return f, nil
+}
+
+// SearchToursFaceted godoc
+// @Summary Tìm kiếm tour có facet
+// @Description Tìm tour theo từ khóa và bộ lọc, trả về trang kết quả, tổng số và số lượng theo từng lựa chọn của danh mục, điểm đến, khoảng giá, thời lượng.
+// @Description Số lượng của mỗi nhóm facet được tính theo mọi bộ lọc khác (bỏ qua bộ lọc của chính nhóm đó)
+// @Tags tour
+// @Produce json
+// @Param keyword query string false "Từ khóa (tiêu đề, mô tả, hoạt động, điểm đến)"
+// @Param danh_muc_id query []int false "ID danh mục (lặp lại hoặc phân tách bằng dấu phẩy)"
+// @Param diem_den_id query []int false "ID điểm đến (lặp lại hoặc phân tách bằng dấu phẩy)"
+// @Param gia_min query number false "Giá người lớn sau giảm tối thiểu"
+// @Param gia_max query number false "Giá người lớn sau giảm tối đa"
+// @Param so_ngay_min query int false "Số ngày tối thiểu"
+// @Param so_ngay_max query int false "Số ngày tối đa"
+// @Param rating_min query number false "Điểm đánh giá tối thiểu (0 - 5)"
+// @Param khoi_hanh_tu query string false "Có ngày khởi hành từ (YYYY-MM-DD)"
+// @Param khoi_hanh_den query string false "Có ngày khởi hành đến (YYYY-MM-DD)"
+// @Param co_giam_gia query bool false "Đang có giảm giá"
+// @Param sort_by query string false "Sắp xếp" Enums(price_asc, price_desc, rating, departure, newest)
+// @Param limit query int false "Số kết quả (tối đa 100)" default(20)
+// @Param offset query int false "Vị trí bắt đầu" default(0)
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /tour/search/faceted [get]
+func (s *Server) SearchToursFaceted(c *gin.Context) {
+	filters, err := parseTourSearchFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tham số tìm kiếm không hợp lệ", "details": err.Error()})
+		return
+	}
+	sortBy := c.Query("sort_by")
+	if !tourSearchSorts[sortBy] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort_by không hợp lệ"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	tours, err := s.z.SearchToursFaceted(ctx, db.SearchToursFacetedParams{
+		SortBy:      sortBy,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+		Keyword:     filters.Keyword,
+		DanhMucIds:  filters.DanhMucIds,
+		DiemDenIds:  filters.DiemDenIds,
+		GiaMin:      filters.GiaMin,
+		GiaMax:      filters.GiaMax,
+		SoNgayMin:   filters.SoNgayMin,
+		SoNgayMax:   filters.SoNgayMax,
+		RatingMin:   filters.RatingMin,
+		KhoiHanhTu:  filters.KhoiHanhTu,
+		KhoiHanhDen: filters.KhoiHanhDen,
+		CoGiamGia:   filters.CoGiamGia,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tìm kiếm tour", "details": err.Error()})
+		return
+	}
+	facetRows, err := s.z.GetTourSearchFacets(ctx, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tính facet", "details": err.Error()})
+		return
+	}
+
+	var total int32
+	categories := []tourSearchFacet{}
+	destinations := []tourSearchFacet{}
+	priceCounts := map[string]int32{}
+	durationCounts := map[string]int32{}
+	for _, row := range facetRows {
+		switch row.Nhom {
+		case "tong":
+			total = row.SoLuong
+		case "danh_muc", "diem_den":
+			id, err := strconv.Atoi(row.Khoa)
+			if err != nil {
+				continue
+			}
+			facet := tourSearchFacet{ID: int32(id), Ten: row.Nhan, SoLuong: row.SoLuong}
+			if row.Nhom == "danh_muc" {
+				categories = append(categories, facet)
+			} else {
+				destinations = append(destinations, facet)
+			}
+		case "khoang_gia":
+			priceCounts[row.Khoa] = row.SoLuong
+		case "thoi_luong":
+			durationCounts[row.Khoa] = row.SoLuong
+		}
+	}
+	sortFacets(categories)
+	sortFacets(destinations)
+	// Chỉ tính câu tìm kiếm có kết quả vào danh sách gợi ý phổ biến
+	if filters.Keyword != nil && total > 0 {
+		s.recordSearchQuery(ctx, *filters.Keyword)
+	}
+
+	// Khoảng giá / thời lượng luôn đủ các lựa chọn theo thứ tự cố định, kể cả lựa chọn có 0 tour
+	prices := make([]tourSearchBucket, len(tourPriceBuckets))
+	for i, b := range tourPriceBuckets {
+		b.SoLuong = priceCounts[b.Khoa]
+		prices[i] = b
+	}
+	durations := make([]tourSearchBucket, len(tourDurationBuckets))
+	for i, b := range tourDurationBuckets {
+		b.SoLuong = durationCounts[b.Khoa]
+		durations[i] = b
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     tours,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": offset+len(tours) < int(total),
+		"facets": gin.H{
+			"danh_muc":   categories,
+			"diem_den":   destinations,
+			"khoang_gia": prices,
+			"thoi_luong": durations,
+		},
+	})
+}
+
+// sortFacets sắp xếp lựa chọn nhiều tour trước, cùng số lượng thì theo tên
+func sortFacets(facets []tourSearchFacet) {
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].SoLuong != facets[j].SoLuong {
+			return facets[i].SoLuong > facets[j].SoLuong
+		}
+		return facets[i].Ten < facets[j].Ten
+	})
+}
@@ -0,0 +1,769 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/skip2/go-qrcode"
+
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+const (
+	// TwoFactorIssuer là tên hiển thị trong ứng dụng xác thực
+	TwoFactorIssuer = "Travia"
+	// TwoFactorRecoveryCodeCount là số mã khôi phục cấp mỗi lần
+	TwoFactorRecoveryCodeCount = 10
+	// TwoFactorMaxAttempts là số lần nhập sai tối đa cho mỗi challenge token
+	TwoFactorMaxAttempts = 5
+
+	twoFactorChallengeKeyPrefix = "2fa:challenge:"
+	twoFactorQRSize             = 256
+)
+
+// twoFactorRoles là các vai trò có chính sách 2FA (chinh_sach_2fa)
+var twoFactorRoles = map[db.VaiTroNguoiDung]bool{
+	db.VaiTroNguoiDungQuanTri:    true,
+	db.VaiTroNguoiDungNhaCungCap: true,
+}
+
+// twoFactorPolicyRole xác định chính sách 2FA áp dụng cho người dùng theo quyền hiệu lực, không chỉ theo enum vai trò:
+// người được gán quyền quản trị (vd: ke_toan, ho_tro) dùng chính sách của quan_tri,
+// người chỉ có quyền supplier.* (chủ hoặc nhân viên nhà cung cấp) dùng chính sách của nha_cung_cap.
+// ok = false: người dùng không có quyền đặc quyền nào nên không dùng 2FA
+func (s *Server) twoFactorPolicyRole(ctx context.Context, userID pgtype.UUID, role string) (db.VaiTroNguoiDung, bool, error) {
+	if twoFactorRoles[db.VaiTroNguoiDung(role)] {
+		return db.VaiTroNguoiDung(role), true, nil
+	}
+
+	codes, err := s.z.GetUserPermissions(ctx, db.GetUserPermissionsParams{
+		NguoiDungID: userID,
+		VaiTro:      role,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	supplier := false
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "supplier.") {
+			return db.VaiTroNguoiDungQuanTri, true, nil
+		}
+		supplier = true
+	}
+	if supplier {
+		return db.VaiTroNguoiDungNhaCungCap, true, nil
+	}
+	return "", false, nil
+}
+
+// isTwoFactorRequiredFor cho biết chính sách 2FA áp dụng cho người dùng (theo quyền hiệu lực) có bắt buộc hay không
+func (s *Server) isTwoFactorRequiredFor(ctx context.Context, userID pgtype.UUID, role string) (bool, error) {
+	policyRole, ok, err := s.twoFactorPolicyRole(ctx, userID, role)
+	if err != nil || !ok {
+		return false, err
+	}
+	return s.z.IsTwoFactorRequired(ctx, policyRole)
+}
+
+// TwoFactorEligible chỉ cho phép người dùng có chính sách 2FA (cùng quy tắc twoFactorPolicyRole dùng khi đăng nhập)
+// quản lý 2FA của mình, kể cả người có enum khach_hang nhưng được gán vai trò quản trị (vd: ho_tro, ke_toan)
+func (s *Server) TwoFactorEligible() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jwtClaims, ok := getAuthClaims(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		_, eligible, err := s.twoFactorPolicyRole(c.Request.Context(), jwtClaims.Id, jwtClaims.Vaitro)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống", "details": err.Error()})
+			c.Abort()
+			return
+		}
+		if !eligible {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Tài khoản không thuộc đối tượng xác thực hai bước"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// completeLogin chạy sau khi mật khẩu đã đúng: tài khoản bật 2FA (hoặc vai trò bắt buộc 2FA) nhận challenge token
+// thay cho cặp token thật. Đăng nhập chỉ được ghi nhận thành công (xóa bộ đếm sai) khi đã qua đủ các bước xác thực
+func (s *Server) completeLogin(c *gin.Context, user db.NguoiDung) {
+	ctx := c.Request.Context()
+	role := user.VaiTro.VaiTroNguoiDung
+
+	policyRole, usesTwoFactor, err := s.twoFactorPolicyRole(ctx, user.ID, string(role))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if usesTwoFactor {
+		enabled := false
+		tf, err := s.z.GetTwoFactorByUser(ctx, user.ID)
+		if err == nil {
+			enabled = tf.DaKichHoat
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+			return
+		}
+		required, err := s.z.IsTwoFactorRequired(ctx, policyRole)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+			return
+		}
+
+		if enabled || required {
+			challenge, jti, err := utils.GenerateTwoFactorChallenge(user.ID, user.Email, string(role), s.config.ServerConfig.ApiSecret)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo token xác thực"})
+				return
+			}
+			// Giá trị 1 đánh dấu challenge còn hiệu lực; số lần thử = giá trị - 1
+			if err := s.redis.SetEx(ctx, twoFactorChallengeKeyPrefix+jti, 1, utils.TwoFactorChallengeDuration).Err(); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo token xác thực"})
+				return
+			}
+
+			message := "Vui lòng nhập mã xác thực hai bước"
+			if !enabled {
+				message = "Vai trò của bạn bắt buộc xác thực hai bước, vui lòng thiết lập trước khi đăng nhập"
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"message":           message,
+				"yeu_cau_2fa":       true,
+				"can_thiet_lap_2fa": !enabled,
+				"challenge_token":   challenge,
+				"het_han_sau":       int(utils.TwoFactorChallengeDuration.Seconds()),
+			})
+			return
+		}
+	}
+
+	s.recordLoginSuccess(c, user)
+	tokenPair, err := s.createSession(c, user.ID, user.Email, string(role))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Không thể tạo token xác thực",
+		})
+		return
+	}
+	respondLoginSuccess(c, user, tokenPair, nil)
+}
+
+// respondLoginSuccess trả về thông tin người dùng và cặp token sau khi đăng nhập thành công
+func respondLoginSuccess(c *gin.Context, user db.NguoiDung, tokenPair *utils.TokenPair, extra gin.H) {
+	response := gin.H{
+		"message": "Đăng nhập thành công",
+		"user": gin.H{
+			"id":       user.ID,
+			"email":    user.Email,
+			"name":     user.HoTen,
+			"role":     user.VaiTro.VaiTroNguoiDung,
+			"ngay_tao": user.NgayTao.Time.Format(time.DateTime),
+		},
+		"tokens": gin.H{
+			"accessToken":  tokenPair.AccessToken,
+			"refreshToken": tokenPair.RefreshToken,
+		},
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// validateTwoFactorChallenge kiểm tra challenge token và đếm số lần thử.
+// Trả về claims và jti; đã ghi response lỗi nếu ok = false
+func (s *Server) validateTwoFactorChallenge(c *gin.Context, challengeToken string) (*utils.JwtClams, bool) {
+	claims, err := utils.ValidateToken(challengeToken, s.config.ServerConfig.ApiSecret)
+	if err != nil || claims.TokenType != utils.TokenTypeTwoFactor || claims.ID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Phiên xác thực hai bước không hợp lệ hoặc đã hết hạn, vui lòng đăng nhập lại"})
+		return nil, false
+	}
+
+	key := twoFactorChallengeKeyPrefix + claims.ID
+	n, err := s.redis.Incr(c.Request.Context(), key).Result()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return nil, false
+	}
+	// n == 1: key không tồn tại (challenge đã dùng hoặc hết hạn) nên Incr vừa tạo mới
+	if n == 1 || n-1 > TwoFactorMaxAttempts {
+		s.redis.Del(c.Request.Context(), key)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Phiên xác thực hai bước không hợp lệ hoặc đã vượt quá số lần thử, vui lòng đăng nhập lại"})
+		return nil, false
+	}
+	return claims, true
+}
+
+// verifySecondFactor kiểm tra mã TOTP (chặn dùng lại cùng mã) hoặc một mã khôi phục chưa dùng
+func (s *Server) verifySecondFactor(ctx context.Context, tf db.XacThucHaiBuoc, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		secret, err := utils.DecryptString(tf.BiMatMaHoa, s.config.ServerConfig.ApiSecret)
+		if err != nil {
+			return false, fmt.Errorf("failed to decrypt totp secret: %w", err)
+		}
+		step, ok := utils.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		n, err := s.z.ConsumeTwoFactorStep(ctx, db.ConsumeTwoFactorStepParams{
+			NguoiDungID:  tf.NguoiDungID,
+			BuocThoiGian: step,
+		})
+		if err != nil {
+			return false, err
+		}
+		return n > 0, nil
+	}
+
+	if recoveryCode != "" && tf.DaKichHoat {
+		n, err := s.z.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			NguoiDungID: tf.NguoiDungID,
+			MaBam:       utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)),
+		})
+		if err != nil {
+			return false, err
+		}
+		return n > 0, nil
+	}
+	return false, nil
+}
+
+// newPendingTwoFactor tạo khóa TOTP chờ kích hoạt và trả về thông tin để người dùng thêm vào ứng dụng xác thực
+func (s *Server) newPendingTwoFactor(ctx context.Context, userID pgtype.UUID, email string) (gin.H, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := utils.EncryptString(secret, s.config.ServerConfig.ApiSecret)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.z.UpsertPendingTwoFactor(ctx, db.UpsertPendingTwoFactorParams{
+		NguoiDungID: userID,
+		BiMatMaHoa:  encrypted,
+	}); err != nil {
+		return nil, err
+	}
+
+	uri := utils.TOTPProvisioningURI(secret, email, TwoFactorIssuer)
+	png, err := qrcode.Encode(uri, qrcode.Medium, twoFactorQRSize)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"bi_mat":         secret,
+		"otpauth_uri":    uri,
+		"qr_code_base64": base64.StdEncoding.EncodeToString(png),
+		"qr_code_mime":   "image/png",
+		"chu_ky_giay":    utils.TOTPPeriod,
+		"so_chu_so":      utils.TOTPDigits,
+	}, nil
+}
+
+// newRecoveryCodes tạo mã khôi phục mới, trả về mã gốc (hiển thị một lần) và bản băm để lưu
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(TwoFactorRecoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// TwoFactorChallengeSetup godoc
+// @Summary Set up 2FA during login
+// @Description Bước thiết lập 2FA khi vai trò bắt buộc 2FA nhưng tài khoản chưa bật: trả về khóa TOTP, URI otpauth và mã QR
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorChallengeRequest true "Challenge token"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/2fa/challenge/setup [post]
+func (s *Server) TwoFactorChallengeSetup(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	claims, ok := s.validateTwoFactorChallenge(c, req.ChallengeToken)
+	if !ok {
+		return
+	}
+
+	if tf, err := s.z.GetTwoFactorByUser(ctx, claims.Id); err == nil && tf.DaKichHoat {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tài khoản đã bật xác thực hai bước"})
+		return
+	}
+
+	data, err := s.newPendingTwoFactor(ctx, claims.Id, claims.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo khóa xác thực hai bước", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Quét mã QR bằng ứng dụng xác thực rồi gửi mã 6 số để hoàn tất đăng nhập",
+		"data":    data,
+	})
+}
+
+// TwoFactorChallengeVerify godoc
+// @Summary Verify 2FA code during login
+// @Description Bước 2 của đăng nhập: đổi challenge token + mã TOTP (hoặc mã khôi phục) lấy cặp token. Nếu đang thiết lập 2FA, bước này kích hoạt 2FA và trả về mã khôi phục
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorVerifyRequest true "Challenge token và mã xác thực"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/2fa/challenge/verify [post]
+func (s *Server) TwoFactorChallengeVerify(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Ma == "" && req.MaKhoiPhuc == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vui lòng nhập mã xác thực hoặc mã khôi phục"})
+		return
+	}
+	claims, ok := s.validateTwoFactorChallenge(c, req.ChallengeToken)
+	if !ok {
+		return
+	}
+	// Nhập sai mã 2FA dùng chung ngưỡng khóa tạm với sai mật khẩu
+	if s.checkLoginLockout(c, claims.Email) {
+		return
+	}
+
+	tf, err := s.z.GetTwoFactorByUser(ctx, claims.Id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Chưa thiết lập xác thực hai bước, gọi /auth/2fa/challenge/setup trước"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+
+	valid, err := s.verifySecondFactor(ctx, tf, req.Ma, req.MaKhoiPhuc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if !valid {
+		s.recordLoginFailure(c, claims.Email, claims.Id, LoginFailTwoFactor)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Mã xác thực không đúng hoặc đã được sử dụng"})
+		return
+	}
+
+	// Tài khoản có thể đã bị khóa / đổi vai trò trong lúc chờ nhập mã
+	user, err := s.z.GetUserByEmail(ctx, claims.Email)
+	if err != nil || user.ID != claims.Id || (user.DangHoatDong != nil && !*user.DangHoatDong) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tài khoản không còn hiệu lực"})
+		return
+	}
+
+	extra := gin.H{}
+	if !tf.DaKichHoat {
+		codes, hashes, err := newRecoveryCodes()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo mã khôi phục"})
+			return
+		}
+		if err := s.z.EnableTwoFactor(ctx, claims.Id, hashes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể bật xác thực hai bước", "details": err.Error()})
+			return
+		}
+		extra["ma_khoi_phuc"] = codes
+	}
+
+	s.redis.Del(ctx, twoFactorChallengeKeyPrefix+claims.ID)
+	s.recordLoginSuccess(c, user)
+
+	tokenPair, err := s.createSession(c, user.ID, user.Email, string(user.VaiTro.VaiTroNguoiDung))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo token xác thực"})
+		return
+	}
+	respondLoginSuccess(c, user, tokenPair, extra)
+}
+
+// GetTwoFactorStatus godoc
+// @Summary Get 2FA status
+// @Description Trạng thái xác thực hai bước của tài khoản hiện tại
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/2fa/status [get]
+func (s *Server) GetTwoFactorStatus(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	required, err := s.isTwoFactorRequiredFor(ctx, jwtClaims.Id, jwtClaims.Vaitro)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+
+	data := gin.H{"da_kich_hoat": false, "bat_buoc": required}
+	tf, err := s.z.GetTwoFactorByUser(ctx, jwtClaims.Id)
+	if err == nil && tf.DaKichHoat {
+		remaining, err := s.z.CountRemainingRecoveryCodes(ctx, jwtClaims.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+			return
+		}
+		data["da_kich_hoat"] = true
+		data["ngay_kich_hoat"] = tf.NgayKichHoat
+		data["so_ma_khoi_phuc_con_lai"] = remaining
+	} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// SetupTwoFactor godoc
+// @Summary Start 2FA enrollment
+// @Description Tạo khóa TOTP chờ kích hoạt, trả về URI otpauth và mã QR. Gọi /auth/2fa/enable với mã 6 số để kích hoạt
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/2fa/setup [post]
+func (s *Server) SetupTwoFactor(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+
+	if tf, err := s.z.GetTwoFactorByUser(ctx, jwtClaims.Id); err == nil && tf.DaKichHoat {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tài khoản đã bật xác thực hai bước"})
+		return
+	}
+
+	data, err := s.newPendingTwoFactor(ctx, jwtClaims.Id, jwtClaims.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo khóa xác thực hai bước", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Quét mã QR bằng ứng dụng xác thực rồi gửi mã 6 số để kích hoạt",
+		"data":    data,
+	})
+}
+
+// EnableTwoFactor godoc
+// @Summary Enable 2FA
+// @Description Kích hoạt 2FA bằng mã TOTP từ ứng dụng xác thực. Trả về mã khôi phục (chỉ hiển thị một lần)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "Mã TOTP"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/2fa/enable [post]
+func (s *Server) EnableTwoFactor(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Ma == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vui lòng nhập mã xác thực"})
+		return
+	}
+
+	tf, err := s.z.GetTwoFactorByUser(ctx, jwtClaims.Id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Chưa tạo khóa xác thực, gọi /auth/2fa/setup trước"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if tf.DaKichHoat {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tài khoản đã bật xác thực hai bước"})
+		return
+	}
+
+	valid, err := s.verifySecondFactor(ctx, tf, req.Ma, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Mã xác thực không đúng"})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo mã khôi phục"})
+		return
+	}
+	if err := s.z.EnableTwoFactor(ctx, jwtClaims.Id, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể bật xác thực hai bước", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Đã bật xác thực hai bước. Hãy lưu các mã khôi phục ở nơi an toàn",
+		"ma_khoi_phuc": codes,
+	})
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable 2FA
+// @Description Tắt 2FA (cần mã TOTP hoặc mã khôi phục). Không thể tắt khi vai trò bắt buộc 2FA
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "Mã TOTP hoặc mã khôi phục"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/2fa/disable [post]
+func (s *Server) DisableTwoFactor(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Ma == "" && req.MaKhoiPhuc == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vui lòng nhập mã xác thực hoặc mã khôi phục"})
+		return
+	}
+
+	required, err := s.isTwoFactorRequiredFor(ctx, jwtClaims.Id, jwtClaims.Vaitro)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if required {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Vai trò của bạn bắt buộc xác thực hai bước"})
+		return
+	}
+
+	tf, err := s.z.GetTwoFactorByUser(ctx, jwtClaims.Id)
+	if err != nil || !tf.DaKichHoat {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tài khoản chưa bật xác thực hai bước"})
+		return
+	}
+
+	valid, err := s.verifySecondFactor(ctx, tf, req.Ma, req.MaKhoiPhuc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Mã xác thực không đúng hoặc đã được sử dụng"})
+		return
+	}
+
+	if err := s.z.DisableTwoFactor(ctx, jwtClaims.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tắt xác thực hai bước", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã tắt xác thực hai bước"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate 2FA recovery codes
+// @Description Cấp lại mã khôi phục (cần mã TOTP), các mã cũ hết hiệu lực
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "Mã TOTP"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/2fa/recovery-codes [post]
+func (s *Server) RegenerateRecoveryCodes(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	jwtClaims, ok := getAuthClaims(c)
+	if !ok {
+		return
+	}
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Ma == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vui lòng nhập mã xác thực"})
+		return
+	}
+
+	tf, err := s.z.GetTwoFactorByUser(ctx, jwtClaims.Id)
+	if err != nil || !tf.DaKichHoat {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tài khoản chưa bật xác thực hai bước"})
+		return
+	}
+
+	valid, err := s.verifySecondFactor(ctx, tf, req.Ma, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Lỗi hệ thống"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Mã xác thực không đúng hoặc đã được sử dụng"})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo mã khôi phục"})
+		return
+	}
+	if err := s.z.ReplaceRecoveryCodes(ctx, jwtClaims.Id, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo mã khôi phục", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Đã cấp lại mã khôi phục",
+		"ma_khoi_phuc": codes,
+	})
+}
+
+// GetTwoFactorPolicies godoc
+// @Summary Get 2FA policies
+// @Description Danh sách chính sách bắt buộc 2FA theo vai trò
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/2fa/policies [get]
+func (s *Server) GetTwoFactorPolicies(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	policies, err := s.z.GetTwoFactorPolicies(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get 2FA policies", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": policies})
+}
+
+// UpdateTwoFactorPolicy godoc
+// @Summary Update 2FA policy
+// @Description Bật/tắt bắt buộc 2FA cho một vai trò (quan_tri, nha_cung_cap). Tài khoản chưa bật 2FA sẽ phải thiết lập ở lần đăng nhập kế tiếp
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param vai_tro path string true "Vai trò"
+// @Param request body models.TwoFactorPolicyRequest true "Bắt buộc"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/2fa/policies/{vai_tro} [put]
+func (s *Server) UpdateTwoFactorPolicy(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	adminClaims, ok := getAdminClaims(c)
+	if !ok {
+		return
+	}
+
+	role := db.VaiTroNguoiDung(c.Param("vai_tro"))
+	if !twoFactorRoles[role] {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "Vai trò không hỗ trợ xác thực hai bước",
+			"valid_vai_tro": []string{string(db.VaiTroNguoiDungQuanTri), string(db.VaiTroNguoiDungNhaCungCap)},
+		})
+		return
+	}
+
+	var req models.TwoFactorPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := s.z.UpsertTwoFactorPolicy(ctx, db.UpsertTwoFactorPolicyParams{
+		VaiTro:       role,
+		BatBuoc:      *req.BatBuoc,
+		NguoiCapNhat: adminClaims.Id,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update 2FA policy", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cập nhật chính sách xác thực hai bước thành công",
+		"data":    policy,
+	})
+}
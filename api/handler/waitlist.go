@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"travia.backend/api/helpers"
+	"travia.backend/api/models"
+	"travia.backend/api/utils"
+	db "travia.backend/db/sqlc"
+)
+
+// WaitlistOfferDuration là thời gian giữ chỗ dành cho khách được mời từ danh sách chờ
+const WaitlistOfferDuration = 2 * time.Hour
+
+// processWaitlists đồng bộ trạng thái các lời mời và mời tiếp các lượt chờ ở những khởi hành còn chỗ.
+// Chạy sau mỗi lần sweeper trả lại chỗ của giữ chỗ hết hạn
+func (s *Server) processWaitlists(ctx context.Context) {
+	if _, err := s.z.SyncWaitlistOffers(ctx); err != nil {
+		log.Printf("[Waitlist] sync offers failed: %v", err)
+	}
+
+	departureIDs, err := s.z.GetDeparturesWithPromotableWaitlist(ctx)
+	if err != nil {
+		log.Printf("[Waitlist] get promotable departures failed: %v", err)
+		return
+	}
+	for _, id := range departureIDs {
+		s.promoteWaitlist(ctx, id)
+	}
+}
+
+// promoteWaitlist mời các lượt chờ của một khởi hành vừa có chỗ trống:
+// tạo giữ chỗ có thời hạn, gửi thông báo và email cho từng khách được mời
+func (s *Server) promoteWaitlist(ctx context.Context, khoiHanhID int32) {
+	offers, err := s.z.PromoteWaitlist(ctx, db.PromoteWaitlistParams{
+		KhoiHanhID: khoiHanhID,
+		SoPhut:     int32(WaitlistOfferDuration / time.Minute),
+	})
+	if err != nil {
+		log.Printf("[Waitlist] promote khoi_hanh_id=%d failed: %v", khoiHanhID, err)
+		return
+	}
+
+	for _, offer := range offers {
+		soKhach := int(offer.SoNguoiLon + offer.SoTreEm)
+		ngayKhoiHanh := offer.NgayKhoiHanh.Time.Format("02/01/2006")
+		hetHanLuc := offer.HetHanLuc.Time.Format("15:04 02/01/2006")
+		maGiuCho := offer.MaGiuCho.String()
+
+		tieuDe := "Đã có chỗ cho lịch khởi hành bạn đăng ký chờ"
+		noiDung := fmt.Sprintf("Tour %s khởi hành %s đã có %d chỗ cho bạn. Vui lòng đặt tour với mã giữ chỗ %s trước %s.",
+			offer.TenTour, ngayKhoiHanh, soKhach, maGiuCho, hetHanLuc)
+		loai := "booking"
+		lienKet := fmt.Sprintf("/booking/hold/%s", maGiuCho)
+		if _, err := s.z.CreateNotification(ctx, db.CreateNotificationParams{
+			NguoiDungID: offer.NguoiDungID,
+			TieuDe:      &tieuDe,
+			NoiDung:     &noiDung,
+			Loai:        &loai,
+			LienKet:     &lienKet,
+		}); err != nil {
+			log.Printf("[Waitlist] create notification for danh_sach_cho_id=%d failed: %v", offer.DanhSachChoID, err)
+		}
+
+		helpers.SendWaitlistOfferAsync(offer.Email, offer.HoTen, offer.TenTour, ngayKhoiHanh, soKhach, maGiuCho, hetHanLuc, s.config.EmailConfig)
+		log.Printf("[Waitlist] khoi_hanh_id=%d offered %d seats to danh_sach_cho_id=%d", khoiHanhID, soKhach, offer.DanhSachChoID)
+	}
+}
+
+// promoteWaitlistAsync mời danh sách chờ ở nền sau khi một thao tác của người dùng trả lại chỗ
+func (s *Server) promoteWaitlistAsync(khoiHanhID int32) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		s.promoteWaitlist(ctx, khoiHanhID)
+	}()
+}
+
+// JoinWaitlist godoc
+// @Summary Join departure waitlist
+// @Description Đăng ký danh sách chờ cho lịch khởi hành đã hết chỗ. Khi có chỗ trống, khách được mời theo thứ tự đăng ký bằng một giữ chỗ có thời hạn (thông báo + email)
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param khoi_hanh_id path int true "Khoi Hanh ID"
+// @Param request body models.JoinWaitlistRequest true "Số khách"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /booking/waitlist/{khoi_hanh_id} [post]
+func (s *Server) JoinWaitlist(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
+	khoiHanhID, err := strconv.Atoi(c.Param("khoi_hanh_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid khoi_hanh_id"})
+		return
+	}
+
+	var req models.JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	soKhach := req.SoNguoiLon + req.SoTreEm
+	if soKhach <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tổng số người phải lớn hơn 0"})
+		return
+	}
+
+	departure, err := s.z.GetDepartureByID(ctx, int32(khoiHanhID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy lịch khởi hành"})
+		return
+	}
+	if departure.TrangThai.Valid && (departure.TrangThai.TrangThaiKhoiHanh == db.TrangThaiKhoiHanhHuy ||
+		departure.TrangThai.TrangThaiKhoiHanh == db.TrangThaiKhoiHanhHoanThanh) ||
+		!departure.NgayKhoiHanh.Time.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Lịch khởi hành không còn nhận đặt chỗ"})
+		return
+	}
+
+	rules, err := s.z.GetDepartureGroupRules(ctx, int32(khoiHanhID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get group rules", "details": err.Error()})
+		return
+	}
+	if soKhach < rules.SoNhoNhat || (rules.SoLonNhat != nil && soKhach > *rules.SoLonNhat) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":       "Số khách nằm ngoài giới hạn nhóm của tour",
+			"so_nho_nhat": rules.SoNhoNhat,
+			"so_lon_nhat": rules.SoLonNhat,
+		})
+		return
+	}
+
+	// Còn đủ chỗ thì khách giữ chỗ trực tiếp, không cần xếp hàng
+	var soChoDaDat int32
+	if departure.SoChoDaDat != nil {
+		soChoDaDat = *departure.SoChoDaDat
+	}
+	if soChoTrong := departure.SucChua - soChoDaDat; soKhach <= soChoTrong {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":        "Lịch khởi hành vẫn còn đủ chỗ, vui lòng giữ chỗ trực tiếp",
+			"so_cho_trong": soChoTrong,
+		})
+		return
+	}
+
+	entry, err := s.z.JoinWaitlist(ctx, db.JoinWaitlistParams{
+		KhoiHanhID:  int32(khoiHanhID),
+		NguoiDungID: jwtClaims.Id,
+		SoNguoiLon:  req.SoNguoiLon,
+		SoTreEm:     req.SoTreEm,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "uq_danh_sach_cho_dang_hieu_luc") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bạn đã có trong danh sách chờ của lịch khởi hành này"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join waitlist", "details": err.Error()})
+		return
+	}
+
+	stats, err := s.z.CountWaitlistByDeparture(ctx, int32(khoiHanhID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count waitlist", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đăng ký danh sách chờ thành công",
+		"data":    entry,
+		"vi_tri":  stats.SoLuotCho,
+	})
+}
+
+// GetMyWaitlist godoc
+// @Summary Get my waitlist
+// @Description Danh sách chờ của người dùng hiện tại kèm vị trí trong hàng đợi và mã giữ chỗ được mời (nếu có)
+// @Tags Booking
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /booking/waitlist [get]
+func (s *Server) GetMyWaitlist(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := s.z.GetMyWaitlist(ctx, db.GetMyWaitlistParams{
+		NguoiDungID: jwtClaims.Id,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get waitlist", "details": err.Error()})
+		return
+	}
+	total, err := s.z.CountMyWaitlist(ctx, jwtClaims.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count waitlist", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Waitlist retrieved successfully",
+		"data":    entries,
+		"pagination": gin.H{
+			"limit":    limit,
+			"offset":   offset,
+			"total":    total,
+			"has_more": offset+len(entries) < int(total),
+		},
+	})
+}
+
+// LeaveWaitlist godoc
+// @Summary Leave waitlist
+// @Description Rời danh sách chờ (chỉ khi chưa được mời; lượt đã được mời thì hủy giữ chỗ qua /booking/hold/{ma_giu_cho})
+// @Tags Booking
+// @Produce json
+// @Param id path int true "Waitlist entry ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /booking/waitlist/{id} [delete]
+func (s *Server) LeaveWaitlist(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	jwtClaims, ok := claims.(*utils.JwtClams)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication claims"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waitlist entry ID"})
+		return
+	}
+
+	entry, err := s.z.LeaveWaitlist(ctx, db.LeaveWaitlistParams{
+		ID:          int32(id),
+		NguoiDungID: jwtClaims.Id,
+	})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy lượt chờ đang hiệu lực"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã rời danh sách chờ",
+		"data":    entry,
+	})
+}
@@ -0,0 +1,177 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BankStatementLine là một dòng tiền vào đọc từ sao kê ngân hàng
+type BankStatementLine struct {
+	MaGiaoDich   string     // Mã giao dịch ngân hàng (hoặc mã sinh từ nội dung nếu sao kê không có)
+	NgayGiaoDich *time.Time // nil nếu không đọc được ngày
+	SoTien       float64
+	NoiDung      string
+}
+
+// Tên cột được chấp nhận (đã chuẩn hóa bằng NormalizeTransferContent)
+var (
+	statementRefColumns     = []string{"MAGIAODICH", "MAGD", "SOTHAMCHIEU", "THAMCHIEU", "SOBUTTOAN", "REFERENCE", "REFERENCENUMBER", "REFNO", "TRANSACTIONID"}
+	statementDateColumns    = []string{"NGAYGIAODICH", "NGAYGD", "NGAY", "THOIGIAN", "DATE", "TRANSACTIONDATE", "POSTINGDATE"}
+	statementAmountColumns  = []string{"SOTIENGHICO", "GHICO", "SOTIEN", "CREDIT", "CREDITAMOUNT", "AMOUNT"}
+	statementContentColumns = []string{"NOIDUNG", "NOIDUNGCHUYENKHOAN", "DIENGIAI", "MOTA", "DESCRIPTION", "CONTENT", "REMARK", "NARRATIVE"}
+)
+
+var statementDateLayouts = []string{
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ParseBankStatementCSV đọc file sao kê CSV (phân tách bằng dấu phẩy hoặc chấm phẩy, có dòng tiêu đề).
+// Bắt buộc có cột số tiền và nội dung; các dòng tiền ra (số tiền <= 0) bị bỏ qua.
+func ParseBankStatementCSV(r io.Reader) ([]BankStatementLine, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statement: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM (Excel)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statement header: %w", err)
+	}
+	refCol := findStatementColumn(header, statementRefColumns)
+	dateCol := findStatementColumn(header, statementDateColumns)
+	amountCol := findStatementColumn(header, statementAmountColumns)
+	contentCol := findStatementColumn(header, statementContentColumns)
+	if amountCol < 0 || contentCol < 0 {
+		return nil, fmt.Errorf("statement must have amount and content columns")
+	}
+
+	var lines []BankStatementLine
+	seen := make(map[string]int)
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		if amountCol >= len(record) || contentCol >= len(record) || strings.TrimSpace(record[amountCol]) == "" {
+			continue // Dòng tiền ra hoặc dòng trống
+		}
+
+		amount, err := parseStatementAmount(record[amountCol])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid amount %q", row, record[amountCol])
+		}
+		if amount <= 0 {
+			continue
+		}
+
+		line := BankStatementLine{
+			SoTien:  amount,
+			NoiDung: strings.TrimSpace(record[contentCol]),
+		}
+		var rawDate string
+		if dateCol >= 0 && dateCol < len(record) {
+			rawDate = strings.TrimSpace(record[dateCol])
+			line.NgayGiaoDich = parseStatementDate(rawDate)
+		}
+		if refCol >= 0 && refCol < len(record) {
+			line.MaGiaoDich = strings.TrimSpace(record[refCol])
+		}
+		if line.MaGiaoDich == "" {
+			// Sinh mã ổn định để tải lại cùng sao kê không tạo dòng trùng
+			key := fmt.Sprintf("%s|%.2f|%s", rawDate, amount, line.NoiDung)
+			seen[key]++
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, seen[key])))
+			line.MaGiaoDich = "AUTO-" + hex.EncodeToString(sum[:])[:32]
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func findStatementColumn(header []string, names []string) int {
+	for _, name := range names {
+		for i, h := range header {
+			if NormalizeTransferContent(h) == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseStatementAmount đọc số tiền dạng "1,500,000", "1.500.000", "1500000.00" hoặc "+1,500,000 VND"
+func parseStatementAmount(raw string) (float64, error) {
+	var b strings.Builder
+	for _, r := range raw {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	s := b.String()
+	if s == "" {
+		return 0, fmt.Errorf("empty amount")
+	}
+
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Ký tự xuất hiện sau cùng là dấu thập phân
+		if lastDot > lastComma {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	case lastComma >= 0:
+		s = normalizeSingleSeparator(s, ",")
+	case lastDot >= 0:
+		s = normalizeSingleSeparator(s, ".")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// normalizeSingleSeparator xử lý số chỉ có một loại dấu phân cách:
+// xuất hiện nhiều lần hoặc theo sau đúng 3 chữ số thì là phân cách hàng nghìn
+func normalizeSingleSeparator(s, sep string) string {
+	if strings.Count(s, sep) > 1 || len(s)-strings.LastIndex(s, sep)-1 == 3 {
+		return strings.ReplaceAll(s, sep, "")
+	}
+	return strings.Replace(s, sep, ".", 1)
+}
+
+func parseStatementDate(raw string) *time.Time {
+	if raw == "" {
+		return nil
+	}
+	for _, layout := range statementDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
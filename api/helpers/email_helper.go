@@ -482,3 +482,300 @@ func SendPaymentSuccess(toEmail, customerName, bookingID, amount, currency strin
 func newbool(b bool) *bool {
 	return &b
 }
+
+// SendWaitlistOfferAsync sends waitlist seat offer email in background (non-blocking)
+func SendWaitlistOfferAsync(toEmail, customerName, tourName, departureDate string, totalPassengers int, holdCode, expiresAt string, e *config.EmailConfig) {
+	go func() {
+		err := SendWaitlistOffer(toEmail, customerName, tourName, departureDate, totalPassengers, holdCode, expiresAt, e)
+		if err != nil {
+			log.Printf("❌ Failed to send waitlist offer to %s: %v", toEmail, err)
+		} else {
+			log.Printf("✅ Waitlist offer sent to %s (Hold %s)", toEmail, holdCode)
+		}
+	}()
+}
+
+// SendWaitlistOffer sends waitlist seat offer email (synchronous)
+func SendWaitlistOffer(toEmail, customerName, tourName, departureDate string, totalPassengers int, holdCode, expiresAt string, e *config.EmailConfig) error {
+	if e.SMTPUsername == "" || e.SMTPPassword == "" {
+		log.Println("⚠️  Email not configured, skipping waitlist offer")
+		return nil
+	}
+
+	subject := fmt.Sprintf("Đã có chỗ cho tour %s - Khởi hành %s", tourName, departureDate)
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #667eea; color: white; padding: 20px; text-align: center; border-radius: 5px; }
+        .content { background: #f9f9f9; padding: 20px; margin-top: 20px; border-radius: 5px; }
+        .code { font-size: 18px; font-weight: bold; color: #667eea; }
+        .warning { color: #d9534f; font-weight: bold; }
+        .footer { margin-top: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🎫 Đã có chỗ cho bạn!</h1>
+        </div>
+        <div class="content">
+            <p>Xin chào <strong>%s</strong>,</p>
+            <p>Lịch khởi hành bạn đăng ký chờ đã có chỗ trống. Chúng tôi đã giữ chỗ cho bạn:</p>
+            <p><strong>Tour:</strong> %s</p>
+            <p><strong>Ngày khởi hành:</strong> %s</p>
+            <p><strong>Số hành khách:</strong> %d người</p>
+            <p><strong>Mã giữ chỗ:</strong> <span class="code">%s</span></p>
+            <p class="warning">Giữ chỗ có hiệu lực đến %s. Sau thời điểm này chỗ sẽ được mời cho khách tiếp theo.</p>
+            <p>Cảm ơn bạn đã tin tưởng Travia!</p>
+        </div>
+        <div class="footer">
+            <p>Email tự động, vui lòng không trả lời</p>
+            <p>© 2024 Travia. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, customerName, tourName, departureDate, totalPassengers, holdCode, expiresAt)
+
+	return sendEmail(toEmail, subject, "", htmlBody, e)
+}
+
+// SendSuspiciousLoginAlertAsync sends new-country login alert in background (non-blocking)
+func SendSuspiciousLoginAlertAsync(toEmail, customerName, location, ipAddress, device, loginTime string, e *config.EmailConfig) {
+	go func() {
+		err := SendSuspiciousLoginAlert(toEmail, customerName, location, ipAddress, device, loginTime, e)
+		if err != nil {
+			log.Printf("❌ Failed to send suspicious login alert to %s: %v", toEmail, err)
+		} else {
+			log.Printf("✅ Suspicious login alert sent to %s (%s)", toEmail, location)
+		}
+	}()
+}
+
+// SendSuspiciousLoginAlert sends new-country login alert email (synchronous)
+func SendSuspiciousLoginAlert(toEmail, customerName, location, ipAddress, device, loginTime string, e *config.EmailConfig) error {
+	if e.SMTPUsername == "" || e.SMTPPassword == "" {
+		log.Println("⚠️  Email not configured, skipping suspicious login alert")
+		return nil
+	}
+
+	subject := "Travia - Cảnh báo đăng nhập từ vị trí mới"
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #d9534f; color: white; padding: 20px; text-align: center; border-radius: 5px; }
+        .content { background: #f9f9f9; padding: 20px; margin-top: 20px; border-radius: 5px; }
+        .warning { background: #fff3cd; border: 1px solid #ffeaa7; padding: 15px; border-radius: 5px; margin: 20px 0; }
+        .footer { margin-top: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⚠️ Đăng nhập từ quốc gia mới</h1>
+        </div>
+        <div class="content">
+            <p>Xin chào <strong>%s</strong>,</p>
+            <p>Tài khoản Travia của bạn vừa được đăng nhập từ một quốc gia chưa từng xuất hiện trước đây:</p>
+            <p><strong>Vị trí:</strong> %s</p>
+            <p><strong>Địa chỉ IP:</strong> %s</p>
+            <p><strong>Thiết bị:</strong> %s</p>
+            <p><strong>Thời gian:</strong> %s</p>
+            <div class="warning">
+                <strong>Nếu đây không phải là bạn:</strong>
+                <ul>
+                    <li>Đổi mật khẩu ngay lập tức</li>
+                    <li>Đăng xuất tất cả thiết bị khác trong phần quản lý phiên đăng nhập</li>
+                    <li>Liên hệ <a href="mailto:support@travia.com">support@travia.com</a> để được hỗ trợ</li>
+                </ul>
+            </div>
+            <p>Nếu đây là bạn, bạn có thể bỏ qua email này.</p>
+        </div>
+        <div class="footer">
+            <p>Email tự động, vui lòng không trả lời</p>
+            <p>© 2024 Travia. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, customerName, location, ipAddress, device, loginTime)
+
+	return sendEmail(toEmail, subject, "", htmlBody, e)
+}
+
+// SendSupplierStaffInvitationAsync sends supplier staff invitation in background (non-blocking)
+func SendSupplierStaffInvitationAsync(toEmail, staffName, supplierName, inviteCode, expiresAt string, e *config.EmailConfig) {
+	go func() {
+		err := SendSupplierStaffInvitation(toEmail, staffName, supplierName, inviteCode, expiresAt, e)
+		if err != nil {
+			log.Printf("❌ Failed to send supplier staff invitation to %s: %v", toEmail, err)
+		} else {
+			log.Printf("✅ Supplier staff invitation sent to %s (%s)", toEmail, supplierName)
+		}
+	}()
+}
+
+// SendSupplierStaffInvitation sends supplier staff invitation email with the invite code (synchronous)
+func SendSupplierStaffInvitation(toEmail, staffName, supplierName, inviteCode, expiresAt string, e *config.EmailConfig) error {
+	if e.SMTPUsername == "" || e.SMTPPassword == "" {
+		log.Println("⚠️  Email not configured, skipping supplier staff invitation")
+		return nil
+	}
+
+	subject := fmt.Sprintf("Travia - Lời mời tham gia nhà cung cấp %s", supplierName)
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #4CAF50; color: white; padding: 20px; text-align: center; border-radius: 5px; }
+        .content { background: #f9f9f9; padding: 20px; margin-top: 20px; border-radius: 5px; }
+        .code { font-size: 18px; font-weight: bold; background: #fff; border: 1px dashed #4CAF50; padding: 12px; text-align: center; word-break: break-all; }
+        .footer { margin-top: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Lời mời tham gia Travia</h1>
+        </div>
+        <div class="content">
+            <p>Xin chào <strong>%s</strong>,</p>
+            <p>Bạn được mời làm nhân viên của nhà cung cấp <strong>%s</strong> trên Travia.</p>
+            <p>Dùng mã mời dưới đây để đặt mật khẩu và kích hoạt tài khoản:</p>
+            <div class="code">%s</div>
+            <p>Mã mời có hiệu lực đến <strong>%s</strong>. Sau khi kích hoạt, đăng nhập bằng email này tại trang dành cho nhà cung cấp.</p>
+            <p>Nếu bạn không biết về lời mời này, hãy bỏ qua email.</p>
+        </div>
+        <div class="footer">
+            <p>Email tự động, vui lòng không trả lời</p>
+            <p>© 2024 Travia. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, staffName, supplierName, inviteCode, expiresAt)
+
+	return sendEmail(toEmail, subject, "", htmlBody, e)
+}
+
+// SendAccountDeletionScheduledAsync sends account deletion confirmation in background (non-blocking)
+func SendAccountDeletionScheduledAsync(toEmail, userName, deleteAt string, e *config.EmailConfig) {
+	go func() {
+		err := SendAccountDeletionScheduled(toEmail, userName, deleteAt, e)
+		if err != nil {
+			log.Printf("❌ Failed to send account deletion notice to %s: %v", toEmail, err)
+		} else {
+			log.Printf("✅ Account deletion notice sent to %s", toEmail)
+		}
+	}()
+}
+
+// SendAccountDeletionScheduled notifies the user that their account will be deleted after the grace period (synchronous)
+func SendAccountDeletionScheduled(toEmail, userName, deleteAt string, e *config.EmailConfig) error {
+	if e.SMTPUsername == "" || e.SMTPPassword == "" {
+		log.Println("⚠️  Email not configured, skipping account deletion notice")
+		return nil
+	}
+
+	subject := "Travia - Xác nhận yêu cầu xóa tài khoản"
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #f44336; color: white; padding: 20px; text-align: center; border-radius: 5px; }
+        .content { background: #f9f9f9; padding: 20px; margin-top: 20px; border-radius: 5px; }
+        .footer { margin-top: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Yêu cầu xóa tài khoản</h1>
+        </div>
+        <div class="content">
+            <p>Xin chào <strong>%s</strong>,</p>
+            <p>Chúng tôi đã nhận được yêu cầu xóa tài khoản Travia của bạn. Tài khoản sẽ bị xóa vào <strong>%s</strong>.</p>
+            <p>Trước thời điểm đó bạn vẫn có thể đăng nhập và hủy yêu cầu. Sau khi xóa, hồ sơ, đánh giá, danh sách yêu thích và lịch sử của bạn sẽ bị xóa vĩnh viễn; thông tin đặt chỗ và giao dịch được ẩn danh và chỉ lưu cho mục đích kế toán.</p>
+            <p>Nếu bạn không thực hiện yêu cầu này, hãy đăng nhập và hủy yêu cầu ngay, sau đó đổi mật khẩu.</p>
+        </div>
+        <div class="footer">
+            <p>Email tự động, vui lòng không trả lời</p>
+            <p>© 2024 Travia. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, userName, deleteAt)
+
+	return sendEmail(toEmail, subject, "", htmlBody, e)
+}
+
+// SendOAuthLinkCodeAsync sends the social account linking code in background (non-blocking)
+func SendOAuthLinkCodeAsync(toEmail, userName, provider, code string, e *config.EmailConfig) {
+	go func() {
+		err := SendOAuthLinkCode(toEmail, userName, provider, code, e)
+		if err != nil {
+			log.Printf("❌ Failed to send OAuth link code to %s: %v", toEmail, err)
+		} else {
+			log.Printf("✅ OAuth link code sent to %s", toEmail)
+		}
+	}()
+}
+
+// SendOAuthLinkCode sends the code confirming a social login should be linked to an existing account (synchronous)
+func SendOAuthLinkCode(toEmail, userName, provider, code string, e *config.EmailConfig) error {
+	if e.SMTPUsername == "" || e.SMTPPassword == "" {
+		log.Println("⚠️  Email not configured, skipping OAuth link code")
+		return nil
+	}
+
+	subject := "Travia - Xác nhận liên kết tài khoản " + provider
+
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #2196F3; color: white; padding: 20px; text-align: center; border-radius: 5px; }
+        .content { background: #f9f9f9; padding: 20px; margin-top: 20px; border-radius: 5px; }
+        .code { font-size: 32px; font-weight: bold; letter-spacing: 8px; text-align: center; color: #2196F3; padding: 10px; }
+        .footer { margin-top: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Liên kết tài khoản</h1>
+        </div>
+        <div class="content">
+            <p>Xin chào <strong>%s</strong>,</p>
+            <p>Có người vừa đăng nhập bằng <strong>%s</strong> với email của bạn. Để liên kết tài khoản %s với tài khoản Travia hiện có, hãy nhập mã sau:</p>
+            <div class="code">%s</div>
+            <p>Mã có hiệu lực trong 15 phút. Nếu bạn không thực hiện đăng nhập này, hãy bỏ qua email.</p>
+        </div>
+        <div class="footer">
+            <p>Email tự động, vui lòng không trả lời</p>
+            <p>© 2024 Travia. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, userName, provider, provider, code)
+
+	return sendEmail(toEmail, subject, "", htmlBody, e)
+}
@@ -13,4 +13,11 @@ func Float64Ptr(f float64) *float64 {
 }
 func NewBool(x bool) *bool {
 	return &x
-}
\ No newline at end of file
+}
+// StringValue trả về giá trị của con trỏ chuỗi, chuỗi rỗng nếu nil
+func StringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
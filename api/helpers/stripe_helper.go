@@ -0,0 +1,209 @@
+package helpers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StripeDefaultAPIBaseURL là địa chỉ API thật của Stripe.
+// Có thể thay bằng stripe-mock hoặc server giả lập khi test (STRIPE_API_BASE_URL).
+const StripeDefaultAPIBaseURL = "https://api.stripe.com"
+
+// StripeWebhookTolerance là độ lệch thời gian tối đa cho phép của chữ ký webhook
+const StripeWebhookTolerance = 5 * time.Minute
+
+// StripeClient gọi Stripe REST API (form-encoded) bằng net/http
+type StripeClient struct {
+	SecretKey  string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewStripeClient(secretKey, baseURL string) *StripeClient {
+	if baseURL == "" {
+		baseURL = StripeDefaultAPIBaseURL
+	}
+	return &StripeClient{
+		SecretKey:  secretKey,
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// StripePaymentIntent chứa các trường PaymentIntent mà hệ thống sử dụng
+type StripePaymentIntent struct {
+	ID             string            `json:"id"`
+	Object         string            `json:"object"`
+	Amount         int64             `json:"amount"`
+	AmountReceived int64             `json:"amount_received"`
+	Currency       string            `json:"currency"`
+	Status         string            `json:"status"`
+	ClientSecret   string            `json:"client_secret"`
+	Metadata       map[string]string `json:"metadata"`
+}
+
+// StripeEvent là payload webhook của Stripe
+type StripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+type stripeErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CreatePaymentIntent tạo PaymentIntent. idempotencyKey giúp Stripe không tạo trùng khi retry
+func (sc *StripeClient) CreatePaymentIntent(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (*StripePaymentIntent, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(amount, 10))
+	form.Set("currency", strings.ToLower(currency))
+	form.Set("automatic_payment_methods[enabled]", "true")
+	for k, v := range metadata {
+		form.Set(fmt.Sprintf("metadata[%s]", k), v)
+	}
+
+	var intent StripePaymentIntent
+	if err := sc.do(ctx, http.MethodPost, "/v1/payment_intents", form, idempotencyKey, &intent); err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+// GetPaymentIntent lấy PaymentIntent theo ID
+func (sc *StripeClient) GetPaymentIntent(ctx context.Context, id string) (*StripePaymentIntent, error) {
+	var intent StripePaymentIntent
+	if err := sc.do(ctx, http.MethodGet, "/v1/payment_intents/"+url.PathEscape(id), nil, "", &intent); err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+func (sc *StripeClient) do(ctx context.Context, method, path string, form url.Values, idempotencyKey string, out any) error {
+	if sc.SecretKey == "" {
+		return fmt.Errorf("STRIPE_SECRET_KEY is not configured")
+	}
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, sc.BaseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create stripe request: %w", err)
+	}
+	req.SetBasicAuth(sc.SecretKey, "")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := sc.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call stripe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read stripe response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var stripeErr stripeErrorResponse
+		if json.Unmarshal(respBody, &stripeErr) == nil && stripeErr.Error.Message != "" {
+			return fmt.Errorf("stripe error (%d %s): %s", resp.StatusCode, stripeErr.Error.Type, stripeErr.Error.Message)
+		}
+		return fmt.Errorf("stripe error (%d): %s", resp.StatusCode, string(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+	return nil
+}
+
+// VerifyStripeWebhookSignature xác thực header Stripe-Signature ("t=...,v1=...")
+// bằng HMAC-SHA256 của "<timestamp>.<payload>" với webhook secret
+func VerifyStripeWebhookSignature(payload []byte, sigHeader, secret string, tolerance time.Duration, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("STRIPE_WEBHOOK_SECRET is not configured")
+	}
+	if sigHeader == "" {
+		return fmt.Errorf("missing Stripe-Signature header")
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(sigHeader, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			timestamp = kv[1]
+		case "v1":
+			signatures = append(signatures, kv[1])
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("invalid Stripe-Signature header")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid Stripe-Signature timestamp")
+	}
+	if tolerance > 0 && now.Sub(time.Unix(ts, 0)).Abs() > tolerance {
+		return fmt.Errorf("Stripe-Signature timestamp outside tolerance")
+	}
+
+	expected := ComputeStripeSignature(payload, timestamp, secret)
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no matching Stripe signature")
+}
+
+// ComputeStripeSignature tính chữ ký v1 (hex) cho payload webhook
+func ComputeStripeSignature(payload []byte, timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// stripeZeroDecimalCurrencies là các loại tiền không có đơn vị lẻ trên Stripe (VND, JPY, ...)
+var stripeZeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true, "kmf": true,
+	"krw": true, "mga": true, "pyg": true, "rwf": true, "ugx": true, "vnd": true,
+	"vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+// ToStripeAmount đổi số tiền sang đơn vị nhỏ nhất mà Stripe yêu cầu
+func ToStripeAmount(amount float64, currency string) int64 {
+	if stripeZeroDecimalCurrencies[strings.ToLower(currency)] {
+		return int64(amount + 0.5)
+	}
+	return int64(amount*100 + 0.5)
+}
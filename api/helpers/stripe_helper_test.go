@@ -0,0 +1,158 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "whsec_test"
+
+func stripeSignatureHeader(payload []byte, ts time.Time, secret string) string {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + ComputeStripeSignature(payload, timestamp, secret)
+}
+
+func TestVerifyStripeWebhookSignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded"}`)
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name    string
+		payload []byte
+		header  string
+		secret  string
+		wantErr bool
+	}{
+		{
+			name:    "chữ ký hợp lệ",
+			payload: payload,
+			header:  stripeSignatureHeader(payload, now, testWebhookSecret),
+			secret:  testWebhookSecret,
+		},
+		{
+			name:    "một trong nhiều chữ ký v1 hợp lệ (xoay vòng secret)",
+			payload: payload,
+			header:  stripeSignatureHeader(payload, now, "whsec_old") + ",v1=" + ComputeStripeSignature(payload, strconv.FormatInt(now.Unix(), 10), testWebhookSecret),
+			secret:  testWebhookSecret,
+		},
+		{
+			name:    "payload bị sửa",
+			payload: []byte(`{"id":"evt_1","type":"payment_intent.succeeded","amount":1}`),
+			header:  stripeSignatureHeader(payload, now, testWebhookSecret),
+			secret:  testWebhookSecret,
+			wantErr: true,
+		},
+		{
+			name:    "sai secret",
+			payload: payload,
+			header:  stripeSignatureHeader(payload, now, "whsec_other"),
+			secret:  testWebhookSecret,
+			wantErr: true,
+		},
+		{
+			name:    "timestamp quá hạn",
+			payload: payload,
+			header:  stripeSignatureHeader(payload, now.Add(-StripeWebhookTolerance-time.Second), testWebhookSecret),
+			secret:  testWebhookSecret,
+			wantErr: true,
+		},
+		{
+			name:    "timestamp ở tương lai",
+			payload: payload,
+			header:  stripeSignatureHeader(payload, now.Add(StripeWebhookTolerance+time.Second), testWebhookSecret),
+			secret:  testWebhookSecret,
+			wantErr: true,
+		},
+		{
+			name:    "thiếu header",
+			payload: payload,
+			header:  "",
+			secret:  testWebhookSecret,
+			wantErr: true,
+		},
+		{
+			name:    "header không có v1",
+			payload: payload,
+			header:  "t=" + strconv.FormatInt(now.Unix(), 10),
+			secret:  testWebhookSecret,
+			wantErr: true,
+		},
+		{
+			name:    "chưa cấu hình secret",
+			payload: payload,
+			header:  stripeSignatureHeader(payload, now, testWebhookSecret),
+			secret:  "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyStripeWebhookSignature(tt.payload, tt.header, tt.secret, StripeWebhookTolerance, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyStripeWebhookSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToStripeAmount(t *testing.T) {
+	if got := ToStripeAmount(1500000, "VND"); got != 1500000 {
+		t.Errorf("VND: got %d, want 1500000", got)
+	}
+	if got := ToStripeAmount(12.34, "usd"); got != 1234 {
+		t.Errorf("USD: got %d, want 1234", got)
+	}
+}
+
+func TestStripeClientCreatePaymentIntent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/payment_intents" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if user, _, ok := r.BasicAuth(); !ok || user != "sk_test" {
+			t.Errorf("missing basic auth with secret key")
+		}
+		if got := r.Header.Get("Idempotency-Key"); got != "txn-1" {
+			t.Errorf("Idempotency-Key = %q, want txn-1", got)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("amount") != "250000" || r.PostForm.Get("currency") != "vnd" ||
+			r.PostForm.Get("metadata[transaction_code]") != "TXN1" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"pi_1","object":"payment_intent","amount":250000,"currency":"vnd","status":"requires_payment_method","client_secret":"pi_1_secret"}`))
+	}))
+	defer srv.Close()
+
+	client := NewStripeClient("sk_test", srv.URL+"/")
+	intent, err := client.CreatePaymentIntent(context.Background(), 250000, "VND", map[string]string{"transaction_code": "TXN1"}, "txn-1")
+	if err != nil {
+		t.Fatalf("CreatePaymentIntent() error = %v", err)
+	}
+	if intent.ID != "pi_1" || intent.ClientSecret != "pi_1_secret" || intent.Amount != 250000 {
+		t.Errorf("unexpected intent %+v", intent)
+	}
+}
+
+func TestStripeClientErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		w.Write([]byte(`{"error":{"type":"card_error","code":"card_declined","message":"Your card was declined."}}`))
+	}))
+	defer srv.Close()
+
+	client := NewStripeClient("sk_test", srv.URL)
+	_, err := client.GetPaymentIntent(context.Background(), "pi_1")
+	if err == nil || !strings.Contains(err.Error(), "Your card was declined.") {
+		t.Fatalf("GetPaymentIntent() error = %v, want card_error message", err)
+	}
+}
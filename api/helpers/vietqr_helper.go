@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// VietQRNapasGUID là định danh NAPAS trong mã VietQR (EMVCo Merchant Account Information)
+const VietQRNapasGUID = "A000000727"
+
+// VietQRServiceTransferToAccount là mã dịch vụ chuyển nhanh 24/7 tới số tài khoản
+const VietQRServiceTransferToAccount = "QRIBFTTA"
+
+// VietQRMaxMemoLength là độ dài tối đa của nội dung chuyển khoản trong mã VietQR
+const VietQRMaxMemoLength = 25
+
+// BuildVietQRPayload tạo chuỗi VietQR (chuẩn EMVCo của NAPAS) cho chuyển khoản tới một tài khoản.
+// amount = 0 tạo mã tĩnh (khách tự nhập số tiền).
+func BuildVietQRPayload(bankBin, accountNumber string, amount int64, memo string) (string, error) {
+	if bankBin == "" || accountNumber == "" {
+		return "", fmt.Errorf("bank BIN and account number are required")
+	}
+	if len(memo) > VietQRMaxMemoLength {
+		return "", fmt.Errorf("transfer memo must be at most %d characters", VietQRMaxMemoLength)
+	}
+
+	beneficiary := emvField("00", bankBin) + emvField("01", accountNumber)
+	merchantAccount := emvField("00", VietQRNapasGUID) +
+		emvField("01", beneficiary) +
+		emvField("02", VietQRServiceTransferToAccount)
+
+	var b strings.Builder
+	b.WriteString(emvField("00", "01"))
+	if amount > 0 {
+		b.WriteString(emvField("01", "12")) // Mã động (có số tiền)
+	} else {
+		b.WriteString(emvField("01", "11")) // Mã tĩnh
+	}
+	b.WriteString(emvField("38", merchantAccount))
+	b.WriteString(emvField("53", "704")) // VND
+	if amount > 0 {
+		b.WriteString(emvField("54", strconv.FormatInt(amount, 10)))
+	}
+	b.WriteString(emvField("58", "VN"))
+	if memo != "" {
+		b.WriteString(emvField("62", emvField("08", memo)))
+	}
+	b.WriteString("6304")
+	payload := b.String()
+	return payload + fmt.Sprintf("%04X", crc16CCITT([]byte(payload))), nil
+}
+
+// VietQRPNG mã hóa chuỗi VietQR thành ảnh PNG
+func VietQRPNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+// GenerateTransferMemo tạo nội dung chuyển khoản duy nhất cho booking (chỉ gồm chữ in hoa và số
+// để không bị ngân hàng lược bỏ ký tự), vd: TRAVIA123CK7QX2MB
+func GenerateTransferMemo(bookingID int32) (string, error) {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = alphabet[int(buf[i])%len(alphabet)]
+	}
+	return fmt.Sprintf("TRAVIA%dCK%s", bookingID, buf), nil
+}
+
+// NormalizeTransferContent chuẩn hóa nội dung sao kê để so khớp nội dung chuyển khoản:
+// bỏ dấu tiếng Việt, chuyển in hoa và loại bỏ mọi ký tự không phải chữ/số
+func NormalizeTransferContent(content string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, _ := transform.String(t, content)
+
+	var b strings.Builder
+	for _, r := range strings.ToUpper(normalized) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func emvField(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// crc16CCITT tính CRC-16/CCITT-FALSE (poly 0x1021, init 0xFFFF) theo EMVCo
+func crc16CCITT(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
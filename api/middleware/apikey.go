@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"travia.backend/api/utils"
+)
+
+// ApiKeyHeader là header đối tác gửi khóa API
+const ApiKeyHeader = "X-API-Key"
+
+// Phạm vi (scope) của khóa API đối tác
+const (
+	ScopeToursRead       = "tours:read"
+	ScopeDeparturesRead  = "departures:read"
+	ScopeBookingsRead    = "bookings:read"
+	ScopeBookingsWrite   = "bookings:write"
+	ScopePassengersWrite = "passengers:write"
+)
+
+// ApiKeyScopes là các phạm vi hợp lệ khi cấp khóa API
+var ApiKeyScopes = []string{
+	ScopeToursRead,
+	ScopeDeparturesRead,
+	ScopeBookingsRead,
+	ScopeBookingsWrite,
+	ScopePassengersWrite,
+}
+
+// ApiKeyPrincipal là đối tác đã xác thực bằng khóa API
+type ApiKeyPrincipal struct {
+	KeyID     int32
+	PartnerID int32
+	UserID    pgtype.UUID // Tài khoản dịch vụ của đối tác, đứng tên các booking
+	Email     string
+	Scopes    []string
+	RateLimit int // Số request tối đa mỗi phút
+}
+
+// HasScope kiểm tra khóa có phạm vi được yêu cầu
+func (p *ApiKeyPrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ApiKeyValidator tra khóa API gốc, trả về nil nếu khóa không tồn tại / đã thu hồi / hết hạn
+type ApiKeyValidator func(ctx context.Context, rawKey string) (*ApiKeyPrincipal, error)
+
+// ApiKeyUsageRecorder ghi nhận một lần gọi API thành công qua rate limit
+type ApiKeyUsageRecorder func(keyID int32)
+
+var (
+	apiKeyValidator     ApiKeyValidator
+	apiKeyUsageRecorder ApiKeyUsageRecorder
+)
+
+// SetApiKeyValidator đăng ký hàm xác thực khóa API và ghi nhận lượt dùng (gọi một lần khi khởi tạo server)
+func SetApiKeyValidator(v ApiKeyValidator, r ApiKeyUsageRecorder) {
+	apiKeyValidator = v
+	apiKeyUsageRecorder = r
+}
+
+// ApiKeyMiddleware xác thực đối tác bằng header X-API-Key và áp giới hạn request theo từng khóa.
+// Đặt trước AuthMiddleware: request không có X-API-Key được chuyển tiếp để AuthMiddleware kiểm tra JWT,
+// request có khóa hợp lệ được gắn claims của tài khoản dịch vụ đối tác nên các handler hiện có dùng lại được
+func ApiKeyMiddleware(redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := strings.TrimSpace(c.GetHeader(ApiKeyHeader))
+		if rawKey == "" {
+			c.Next()
+			return
+		}
+		if apiKeyValidator == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Chưa cấu hình xác thực khóa API"})
+			c.Abort()
+			return
+		}
+
+		principal, err := apiKeyValidator(c.Request.Context(), rawKey)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Không thể xác thực khóa API",
+				"details": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Khóa API không hợp lệ, đã bị thu hồi hoặc hết hạn"})
+			c.Abort()
+			return
+		}
+
+		// Giới hạn theo cửa sổ cố định 1 phút cho từng khóa
+		window := time.Now().Unix() / 60
+		key := fmt.Sprintf("ratelimit:apikey:%d:%d", principal.KeyID, window)
+		count, err := redisClient.Incr(c.Request.Context(), key).Result()
+		if err != nil {
+			// Không đếm được thì không cho qua, tránh đối tác vượt giới hạn khi Redis lỗi
+			log.Printf("[ApiKey] rate limit check for key %d failed: %v", principal.KeyID, err)
+			c.Header("Retry-After", "5")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Không thể kiểm tra giới hạn request, vui lòng thử lại sau"})
+			c.Abort()
+			return
+		}
+		if count == 1 {
+			redisClient.Expire(c.Request.Context(), key, 2*time.Minute)
+		}
+		remaining := int64(principal.RateLimit) - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", principal.RateLimit))
+		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
+		if count > int64(principal.RateLimit) {
+			c.Header("Retry-After", fmt.Sprintf("%d", 60-time.Now().Unix()%60))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			c.Abort()
+			return
+		}
+
+		if apiKeyUsageRecorder != nil {
+			apiKeyUsageRecorder(principal.KeyID)
+		}
+
+		c.Set("api_key", principal)
+		c.Set("claims", &utils.JwtClams{
+			Id:        principal.UserID,
+			Email:     principal.Email,
+			Vaitro:    "khach_hang",
+			TokenType: utils.TokenTypeAccess,
+		})
+		c.Next()
+	}
+}
+
+// GetApiKeyPrincipal trả về đối tác nếu request được xác thực bằng khóa API
+func GetApiKeyPrincipal(c *gin.Context) (*ApiKeyPrincipal, bool) {
+	v, exists := c.Get("api_key")
+	if !exists {
+		return nil, false
+	}
+	principal, ok := v.(*ApiKeyPrincipal)
+	return principal, ok
+}
+
+// RequireScope yêu cầu khóa API có đủ các phạm vi được chỉ định.
+// Request xác thực bằng JWT của người dùng không bị giới hạn bởi phạm vi
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetApiKeyPrincipal(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		for _, s := range scopes {
+			if !principal.HasScope(s) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Khóa API không có phạm vi cần thiết",
+					"scope": s,
+				})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
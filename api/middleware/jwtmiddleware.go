@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -9,12 +10,28 @@ import (
 	"travia.backend/api/utils"
 )
 
+// SessionValidator kiểm tra phiên đăng nhập (sid trong access token) còn hiệu lực hay đã bị thu hồi
+type SessionValidator func(ctx context.Context, sessionID int32) bool
+
+var sessionValidator SessionValidator
+
+// SetSessionValidator đăng ký hàm kiểm tra phiên cho AuthMiddleware (gọi một lần khi khởi tạo server)
+func SetSessionValidator(v SessionValidator) {
+	sessionValidator = v
+}
+
 // AuthMiddleware chỉ hỗ trợ Bearer token (giữ nguyên để backward compatibility)
 func AuthMiddleware(secretKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Debug: Log incoming request path
 		fmt.Printf("AuthMiddleware - Path: %s, Method: %s\n", c.Request.URL.Path, c.Request.Method)
 
+		// Đã xác thực bằng khóa API đối tác (ApiKeyMiddleware đứng trước)
+		if _, ok := c.Get("api_key"); ok {
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			fmt.Printf("AuthMiddleware - Missing Authorization header for path: %s\n", c.Request.URL.Path)
@@ -55,6 +72,25 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 			return
 		}
 
+		// Refresh token chỉ dùng cho /auth/refresh, challenge token 2FA chỉ dùng cho /auth/2fa/*.
+		// Token không có typ (cấp trước khi phân loại token, kể cả refresh token cũ) cũng bị từ chối
+		if claims.TokenType != utils.TokenTypeAccess {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Không thể dùng token này để truy cập API",
+			})
+			c.Abort()
+			return
+		}
+
+		// Phiên đã đăng xuất / bị thu hồi thì access token không còn giá trị dù chưa hết hạn
+		if claims.SessionID != 0 && sessionValidator != nil && !sessionValidator(c.Request.Context(), claims.SessionID) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Phiên đăng nhập đã bị thu hồi, vui lòng đăng nhập lại",
+			})
+			c.Abort()
+			return
+		}
+
 		// Đưa claims vào context
 		c.Set("claims", claims)
 
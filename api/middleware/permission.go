@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"travia.backend/api/utils"
+)
+
+// Mã quyền (bảng quyen) dùng với RequirePermission
+const (
+	PermDashboardView     = "dashboard.view"
+	PermRevenueView       = "revenue.view"
+	PermSupplierManage    = "supplier.manage"
+	PermPayoutManage      = "payout.manage"
+	PermBankStatement     = "bank_statement.manage"
+	PermCustomerView      = "customer.view"
+	PermUserSecurity      = "user.security"
+	PermBookingView       = "booking.view"
+	PermRefundView        = "refund.view"
+	PermRefundManage      = "refund.manage"
+	PermContactManage     = "contact.manage"
+	PermDestinationManage = "destination.manage"
+	PermBlogManage        = "blog.manage"
+	PermRoleManage        = "role.manage"
+	PermAuditView         = "audit.view"
+	PermPartnerManage     = "partner.manage"
+	PermJwtKeyManage      = "jwt_key.manage"
+	PermTourModerate      = "tour.moderate"
+
+	PermSupplierProfileView   = "supplier.profile.view"
+	PermSupplierDashboardView = "supplier.dashboard.view"
+	PermSupplierRevenueView   = "supplier.revenue.view"
+	PermSupplierBookingView   = "supplier.booking.view"
+	PermSupplierTourManage    = "supplier.tour.manage"
+	PermSupplierBankAccount   = "supplier.bank_account.manage"
+	PermSupplierReviewReply   = "supplier.review.reply"
+	PermSupplierStaffManage   = "supplier.staff.manage"
+)
+
+// PermissionResolver trả về tập quyền hiệu lực của người dùng đang đăng nhập
+type PermissionResolver func(ctx context.Context, claims *utils.JwtClams) (map[string]bool, error)
+
+var permissionResolver PermissionResolver
+
+// SetPermissionResolver đăng ký hàm tra quyền cho RequirePermission (gọi một lần khi khởi tạo server)
+func SetPermissionResolver(r PermissionResolver) {
+	permissionResolver = r
+}
+
+// RequirePermission cho phép truy cập nếu người dùng có tất cả các quyền được chỉ định.
+// Tập quyền được đưa vào context ("permissions") để handler kiểm tra thêm nếu cần
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, exists := c.Get("claims")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Thiếu thông tin xác thực"})
+			c.Abort()
+			return
+		}
+		claims, ok := v.(*utils.JwtClams)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Thông tin xác thực không hợp lệ"})
+			c.Abort()
+			return
+		}
+		if permissionResolver == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Chưa cấu hình kiểm tra quyền"})
+			c.Abort()
+			return
+		}
+
+		granted, err := permissionResolver(c.Request.Context(), claims)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Không thể kiểm tra quyền truy cập",
+				"details": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		for _, p := range permissions {
+			if !granted[p] {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":     "Không có quyền truy cập",
+					"can_quyen": p,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set("permissions", granted)
+		c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+// Partner Models
+type CreatePartnerRequest struct {
+	Ten         string  `json:"ten" binding:"required,max=255"`
+	EmailLienHe string  `json:"email_lien_he" binding:"required,email"`
+	SoDienThoai *string `json:"so_dien_thoai"`
+	TiLeHoaHong float64 `json:"ti_le_hoa_hong" binding:"min=0,max=100"`
+}
+
+type UpdatePartnerRequest struct {
+	Ten          *string  `json:"ten" binding:"omitempty,max=255"`
+	EmailLienHe  *string  `json:"email_lien_he" binding:"omitempty,email"`
+	SoDienThoai  *string  `json:"so_dien_thoai"`
+	TiLeHoaHong  *float64 `json:"ti_le_hoa_hong" binding:"omitempty,min=0,max=100"`
+	DangHoatDong *bool    `json:"dang_hoat_dong"`
+}
+
+type CreateApiKeyRequest struct {
+	Ten            string   `json:"ten" binding:"required,max=100"`
+	PhamVi         []string `json:"pham_vi" binding:"required,min=1"`
+	GioiHanMoiPhut int32    `json:"gioi_han_moi_phut" binding:"omitempty,min=1,max=6000"`
+	HetHan         string   `json:"het_han"` // Format: "YYYY-MM-DD", bỏ trống nếu không hết hạn
+}
@@ -21,3 +21,41 @@ type CreateSupplierRequest struct {
 		NamThanhLap     *string `json:"nam_thanh_lap"`
 	} `json:"thong_tin_nha_cung_cap"`
 }
+
+type CreateBankAccountRequest struct {
+	TenNganHang    string  `json:"ten_ngan_hang" binding:"required"`
+	SoTaiKhoan     string  `json:"so_tai_khoan" binding:"required"`
+	TenChuTaiKhoan string  `json:"ten_chu_tai_khoan" binding:"required"`
+	ChiNhanh       *string `json:"chi_nhanh"`
+	LaMacDinh      *bool   `json:"la_mac_dinh"`
+}
+
+// BatchPayoutRequest thao tác hàng loạt trên các khoản chi trả nhà cung cấp
+// hanh_dong: approve | hold | release | mark_paid | mark_failed
+type BatchPayoutRequest struct {
+	Ids               []int32 `json:"ids" binding:"required,min=1"`
+	HanhDong          string  `json:"hanh_dong" binding:"required,oneof=approve hold release mark_paid mark_failed"`
+	GhiChu            *string `json:"ghi_chu"`
+	MaThamChieuChiTra *string `json:"ma_tham_chieu_chi_tra"`
+}
+
+// CancellationPolicyTierRequest là một mốc hoàn tiền: hủy trước ít nhất so_ngay_toi_thieu ngày thì hoàn phan_tram_hoan %
+type CancellationPolicyTierRequest struct {
+	SoNgayToiThieu int32   `json:"so_ngay_toi_thieu" binding:"min=0"`
+	PhanTramHoan   float64 `json:"phan_tram_hoan" binding:"min=0,max=100"`
+}
+
+// CancellationPolicyRequest tạo / cập nhật chính sách hủy của nhà cung cấp.
+// khong_hoan_tien = true thì bỏ qua cac_bac; ngược lại phải có ít nhất một mốc
+type CancellationPolicyRequest struct {
+	Ten           string                          `json:"ten" binding:"required,max=255"`
+	MoTa          *string                         `json:"mo_ta"`
+	KhongHoanTien bool                            `json:"khong_hoan_tien"`
+	PhiCoDinh     float64                         `json:"phi_co_dinh" binding:"min=0"`
+	CacBac        []CancellationPolicyTierRequest `json:"cac_bac" binding:"dive"`
+}
+
+// AttachCancellationPolicyRequest gắn chính sách hủy cho tour / khởi hành (null để dùng chính sách mặc định)
+type AttachCancellationPolicyRequest struct {
+	ChinhSachHuyID *int32 `json:"chinh_sach_huy_id"`
+}
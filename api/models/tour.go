@@ -51,8 +51,10 @@ type CreateTourFullRequest struct {
 
 	// Cấu hình nhóm (optional)
 	GroupConfig *struct {
-		SoNhoNhat int32 `json:"so_nho_nhat"`
-		SoLonNhat int32 `json:"so_lon_nhat"`
+		SoNhoNhat         int32  `json:"so_nho_nhat"`
+		SoLonNhat         int32  `json:"so_lon_nhat"`
+		TuoiTreEmToiThieu *int32 `json:"tuoi_tre_em_toi_thieu"` // Mặc định 2
+		TuoiTreEmToiDa    *int32 `json:"tuoi_tre_em_toi_da"`    // Mặc định 11
 	} `json:"cau_hinh_nhom_tours"`
 
 	// Lịch khởi hành (optional)
@@ -115,8 +117,10 @@ type CreateTourRequest struct {
 
 	// Cấu hình nhóm (optional)
 	CauHinhNhomTours *struct {
-		SoNhoNhat int32 `json:"so_nho_nhat"`
-		SoLonNhat int32 `json:"so_lon_nhat"`
+		SoNhoNhat         int32  `json:"so_nho_nhat"`
+		SoLonNhat         int32  `json:"so_lon_nhat"`
+		TuoiTreEmToiThieu *int32 `json:"tuoi_tre_em_toi_thieu"` // Mặc định 2
+		TuoiTreEmToiDa    *int32 `json:"tuoi_tre_em_toi_da"`    // Mặc định 11
 	} `json:"cau_hinh_nhom_tours"`
 
 	// Lịch khởi hành (optional)
@@ -167,17 +171,16 @@ type UpdateKhoiHanhTourRequest struct {
 	GhiChu       string `json:"ghi_chu"`                           // Optional notes
 }
 type UpdateTourRequest struct {
-	TieuDe       string  `json:"tieu_de" binding:"required"`
-	MoTa         string  `json:"mo_ta"`
-	DanhMucID    int32   `json:"danh_muc_id"`
-	SoNgay       int32   `json:"so_ngay" binding:"required,min=1"`
-	SoDem        int32   `json:"so_dem" binding:"min=0"`
-	GiaNguoiLon  float64 `json:"gia_nguoi_lon" binding:"required,gt=0"`
-	GiaTreEm     float64 `json:"gia_tre_em" binding:"required,gt=0"`
-	DonViTienTe  string  `json:"don_vi_tien_te"`
-	TrangThai    string  `json:"trang_thai"`
-	NoiBat       bool    `json:"noi_bat"`
-	NhaCungCapID string  `json:"nha_cung_cap_id" binding:"required"`
+	TieuDe      string  `json:"tieu_de" binding:"required"`
+	MoTa        string  `json:"mo_ta"`
+	DanhMucID   int32   `json:"danh_muc_id"`
+	SoNgay      int32   `json:"so_ngay" binding:"required,min=1"`
+	SoDem       int32   `json:"so_dem" binding:"min=0"`
+	GiaNguoiLon float64 `json:"gia_nguoi_lon" binding:"required,gt=0"`
+	GiaTreEm    float64 `json:"gia_tre_em" binding:"required,gt=0"`
+	DonViTienTe string  `json:"don_vi_tien_te"`
+	TrangThai   string  `json:"trang_thai"`
+	NoiBat      bool    `json:"noi_bat"`
 }
 type AddHinhAnhTourRequest struct {
 	DuongDan string `json:"duong_dan" binding:"required"`
@@ -188,4 +191,20 @@ type AddHinhAnhTourRequest struct {
 type AddTourDestinationRequest struct {
 	DiemDenID     int32 `json:"diem_den_id" binding:"required"`
 	ThuTuThamQuan int32 `json:"thu_tu_tham_quan"`
-}
\ No newline at end of file
+}
+// AutocompleteSelectRequest ghi nhận gợi ý người dùng đã chọn (tu_khoa: chọn một câu tìm kiếm phổ biến)
+type AutocompleteSelectRequest struct {
+	Q    string `json:"q" binding:"required,max=100"`
+	Loai string `json:"loai" binding:"required,oneof=tour diem_den danh_muc tu_khoa"`
+	ID   int32  `json:"id" binding:"min=0"`
+}
+
+// SubmitTourReviewRequest gửi bản nháp tour chờ quản trị viên duyệt
+type SubmitTourReviewRequest struct {
+	GhiChu string `json:"ghi_chu" binding:"max=1000"`
+}
+
+// ReviewTourRevisionRequest là nhận xét của quản trị viên khi duyệt / từ chối (bắt buộc khi từ chối)
+type ReviewTourRevisionRequest struct {
+	NhanXet string `json:"nhan_xet" binding:"max=2000"`
+}
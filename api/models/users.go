@@ -43,6 +43,16 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
+type CreateBookingRequest struct {
+	MaGiuCho            string  `json:"ma_giu_cho" binding:"required"`
+	PhuongThucThanhToan *string `json:"phuong_thuc_thanh_toan"`
+}
+
+type JoinWaitlistRequest struct {
+	SoNguoiLon int32 `json:"so_nguoi_lon" binding:"min=0"`
+	SoTreEm    int32 `json:"so_tre_em" binding:"min=0"`
+}
+
 type AddPassengersParams struct {
 	DatChoID         int32   `json:"dat_cho_id"`
 	HoTen            string  `json:"ho_ten"`
@@ -69,3 +79,78 @@ type ResetPasswordRequest struct {
 	OTP         string `json:"otp" binding:"required,len=6"`
 	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
+
+// Two-factor authentication Models
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Ma             string `json:"ma"`           // Mã TOTP 6 số
+	MaKhoiPhuc     string `json:"ma_khoi_phuc"` // Hoặc một mã khôi phục
+}
+
+type TwoFactorCodeRequest struct {
+	Ma         string `json:"ma"`
+	MaKhoiPhuc string `json:"ma_khoi_phuc"`
+}
+
+type TwoFactorPolicyRequest struct {
+	BatBuoc *bool `json:"bat_buoc" binding:"required"`
+}
+
+// Permission Models
+type CreateRoleRequest struct {
+	Ma    string   `json:"ma" binding:"required,max=50"`
+	Ten   string   `json:"ten" binding:"required,max=100"`
+	MoTa  *string  `json:"mo_ta"`
+	Quyen []string `json:"quyen"`
+}
+
+type UpdateRoleRequest struct {
+	Ten  *string `json:"ten" binding:"omitempty,max=100"`
+	MoTa *string `json:"mo_ta"`
+}
+
+type SetRolePermissionsRequest struct {
+	Quyen []string `json:"quyen" binding:"required"`
+}
+
+type SetUserRolesRequest struct {
+	VaiTro []string `json:"vai_tro" binding:"required"`
+}
+
+// Supplier Staff Models
+type InviteSupplierStaffRequest struct {
+	HoTen       string   `json:"ho_ten" binding:"required,max=255"`
+	Email       string   `json:"email" binding:"required,email"`
+	SoDienThoai *string  `json:"so_dien_thoai"`
+	Quyen       []string `json:"quyen" binding:"required"`
+}
+
+type UpdateSupplierStaffRequest struct {
+	Quyen []string `json:"quyen" binding:"required"`
+}
+
+type AcceptSupplierStaffInvitationRequest struct {
+	MaLoiMoi string `json:"ma_loi_moi" binding:"required"`
+	MatKhau  string `json:"mat_khau" binding:"required,min=8"`
+}
+
+type ReplyReviewRequest struct {
+	NoiDung string `json:"noi_dung" form:"noi_dung" binding:"required,max=2000"`
+}
+
+// Account Data Models
+type RequestAccountDeletionRequest struct {
+	MatKhau string  `json:"mat_khau" binding:"required"`
+	LyDo    *string `json:"ly_do" binding:"omitempty,max=1000"`
+}
+
+// OAuth Models
+type ConfirmOAuthLinkRequest struct {
+	MaLienKet string  `json:"ma_lien_ket" binding:"required"`
+	MatKhau   *string `json:"mat_khau"`
+	MaXacNhan *string `json:"ma_xac_nhan" binding:"omitempty,len=6"`
+}
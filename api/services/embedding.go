@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// EmbeddingDimensions là số chiều của cột tour_embeddings.embedding (vector(1536))
+const EmbeddingDimensions = 1536
+
+const (
+	EmbeddingProviderOpenAI = "openai"
+	EmbeddingProviderLocal  = "local"
+)
+
+var ErrEmptyEmbeddingText = errors.New("text cannot be empty")
+
+// Embedder tạo vector embedding cho văn bản (nội dung tour, câu tìm kiếm).
+// Model() được lưu cùng vector: chỉ so sánh các vector do cùng một mô hình tạo ra
+type Embedder interface {
+	Model() string
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// NewEmbedder chọn Embedder theo cấu hình: "openai" cần API key, "local" chạy không cần mạng.
+// Để trống provider thì dùng OpenAI khi có API key, ngược lại dùng bản local
+func NewEmbedder(provider, openAIKey string) (Embedder, error) {
+	switch provider {
+	case EmbeddingProviderOpenAI:
+		if openAIKey == "" {
+			return nil, fmt.Errorf("OpenAI API key is not configured")
+		}
+		return NewOpenAIEmbedder(openAIKey), nil
+	case EmbeddingProviderLocal:
+		return NewLocalEmbedder(), nil
+	case "":
+		if openAIKey != "" {
+			return NewOpenAIEmbedder(openAIKey), nil
+		}
+		return NewLocalEmbedder(), nil
+	default:
+		return nil, fmt.Errorf("embedding provider không hỗ trợ: %s", provider)
+	}
+}
+
+// OpenAIEmbedder gọi OpenAI text-embedding-3-small (1536 chiều)
+type OpenAIEmbedder struct {
+	client *openai.Client
+}
+
+func NewOpenAIEmbedder(apiKey string) *OpenAIEmbedder {
+	return &OpenAIEmbedder{client: openai.NewClient(apiKey)}
+}
+
+func (e *OpenAIEmbedder) Model() string {
+	return string(openai.SmallEmbedding3)
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyEmbeddingText
+	}
+	resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: []string{text},
+		Model: openai.SmallEmbedding3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding: %w", err)
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("no embedding data returned")
+	}
+	return resp.Data[0].Embedding, nil
+}
+
+// LocalEmbedder tạo embedding tất định, không cần mạng, bằng feature hashing:
+// mỗi từ (đã bỏ dấu, chữ thường) và mỗi cặp từ liền nhau được băm vào một chiều với dấu +/-,
+// sau đó chuẩn hóa độ dài về 1. Dùng cho môi trường dev / test hoặc khi không có OpenAI API key;
+// chỉ bắt được độ trùng từ ngữ, không hiểu nghĩa như mô hình thật
+type LocalEmbedder struct{}
+
+func NewLocalEmbedder() *LocalEmbedder {
+	return &LocalEmbedder{}
+}
+
+func (e *LocalEmbedder) Model() string {
+	return "local-hash-v1"
+}
+
+func (e *LocalEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	tokens := embeddingTokens(text)
+	if len(tokens) == 0 {
+		return nil, ErrEmptyEmbeddingText
+	}
+
+	vec := make([]float64, EmbeddingDimensions)
+	add := func(feature string, weight float64) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		idx := int(sum % EmbeddingDimensions)
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vec[idx] += weight
+	}
+	for i, tok := range tokens {
+		add(tok, 1)
+		if i > 0 {
+			add(tokens[i-1]+" "+tok, 0.5)
+		}
+	}
+
+	var norm2 float64
+	for _, v := range vec {
+		norm2 += v * v
+	}
+	out := make([]float32, EmbeddingDimensions)
+	if norm2 == 0 {
+		return out, nil
+	}
+	scale := 1 / math.Sqrt(norm2)
+	for i, v := range vec {
+		out[i] = float32(v * scale)
+	}
+	return out, nil
+}
+
+// embeddingTokens tách văn bản thành các từ chữ thường, bỏ dấu tiếng Việt (kể cả đ)
+func embeddingTokens(text string) []string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, _ := transform.String(t, strings.ToLower(text))
+	normalized = strings.ReplaceAll(normalized, "đ", "d")
+
+	return strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"testing"
+)
+
+func cosineSimilarity(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func vectorNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
+
+func mustEmbed(t *testing.T, e Embedder, text string) []float32 {
+	t.Helper()
+	vec, err := e.Embed(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Embed(%q) error = %v", text, err)
+	}
+	return vec
+}
+
+func TestLocalEmbedderDeterministic(t *testing.T) {
+	e := NewLocalEmbedder()
+	text := "Tour Hạ Long 3 ngày 2 đêm, ngủ đêm trên du thuyền"
+
+	first := mustEmbed(t, e, text)
+	second := mustEmbed(t, NewLocalEmbedder(), text)
+
+	if len(first) != EmbeddingDimensions {
+		t.Fatalf("len = %d, want %d", len(first), EmbeddingDimensions)
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("vector differs at %d: %v != %v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestLocalEmbedderNormalized(t *testing.T) {
+	e := NewLocalEmbedder()
+	for _, text := range []string{
+		"Đà Lạt",
+		"Tour Phú Quốc nghỉ dưỡng, lặn ngắm san hô và câu cá",
+		"sapa sapa sapa trekking ruộng bậc thang",
+	} {
+		if n := vectorNorm(mustEmbed(t, e, text)); math.Abs(n-1) > 1e-5 {
+			t.Errorf("norm(%q) = %v, want 1", text, n)
+		}
+	}
+}
+
+func TestLocalEmbedderIgnoresAccentsAndCase(t *testing.T) {
+	e := NewLocalEmbedder()
+	a := mustEmbed(t, e, "Đà Nẵng Hội An")
+	b := mustEmbed(t, e, "da nang HOI AN")
+
+	if sim := cosineSimilarity(a, b); math.Abs(sim-1) > 1e-5 {
+		t.Fatalf("similarity = %v, want 1", sim)
+	}
+}
+
+func TestLocalEmbedderEmptyText(t *testing.T) {
+	e := NewLocalEmbedder()
+	for _, text := range []string{"", "   ", "!!! ---"} {
+		if _, err := e.Embed(context.Background(), text); !errors.Is(err, ErrEmptyEmbeddingText) {
+			t.Errorf("Embed(%q) error = %v, want ErrEmptyEmbeddingText", text, err)
+		}
+	}
+}
+
+// Tìm kiếm ngữ nghĩa xếp hạng theo độ tương đồng cosine (giống toán tử <=> của pgvector trên vector đã chuẩn hóa)
+func TestLocalEmbedderSemanticRanking(t *testing.T) {
+	e := NewLocalEmbedder()
+	tours := map[string]string{
+		"ha_long":  "Du thuyền vịnh Hạ Long 2 ngày 1 đêm, chèo kayak và thăm hang Sửng Sốt",
+		"sapa":     "Trekking Sapa, bản Cát Cát, ruộng bậc thang và chinh phục đỉnh Fansipan",
+		"phu_quoc": "Nghỉ dưỡng Phú Quốc, lặn ngắm san hô, câu cá và tắm biển Bãi Sao",
+		"hue":      "Tham quan cố đô Huế, Đại Nội, lăng Tự Đức và nghe ca Huế trên sông Hương",
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "du thuyen ha long", want: "ha_long"},
+		{query: "leo núi Fansipan Sapa", want: "sapa"},
+		{query: "lặn biển ngắm san hô", want: "phu_quoc"},
+		{query: "cố đô Huế", want: "hue"},
+	}
+
+	vectors := make(map[string][]float32, len(tours))
+	for id, text := range tours {
+		vectors[id] = mustEmbed(t, e, text)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q := mustEmbed(t, e, tt.query)
+			ids := make([]string, 0, len(vectors))
+			for id := range vectors {
+				ids = append(ids, id)
+			}
+			sort.Slice(ids, func(i, j int) bool {
+				return cosineSimilarity(q, vectors[ids[i]]) > cosineSimilarity(q, vectors[ids[j]])
+			})
+			if ids[0] != tt.want {
+				t.Fatalf("top result = %s, want %s (ranking %v)", ids[0], tt.want, ids)
+			}
+		})
+	}
+}
+
+func TestNewEmbedder(t *testing.T) {
+	tests := []struct {
+		provider  string
+		key       string
+		wantModel string
+		wantErr   bool
+	}{
+		{provider: "", key: "", wantModel: "local-hash-v1"},
+		{provider: "", key: "sk-test", wantModel: "text-embedding-3-small"},
+		{provider: EmbeddingProviderLocal, key: "sk-test", wantModel: "local-hash-v1"},
+		{provider: EmbeddingProviderOpenAI, key: "", wantErr: true},
+		{provider: "unknown", key: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		e, err := NewEmbedder(tt.provider, tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("NewEmbedder(%q, %q) error = %v, wantErr %v", tt.provider, tt.key, err, tt.wantErr)
+		}
+		if err == nil && e.Model() != tt.wantModel {
+			t.Errorf("NewEmbedder(%q, %q) model = %s, want %s", tt.provider, tt.key, e.Model(), tt.wantModel)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"travia.backend/config"
+)
+
+// MoMoResultSuccess là resultCode khi giao dịch MoMo thành công
+const MoMoResultSuccess = 0
+
+// MoMoGateway triển khai PaymentGateway cho ví MoMo (API v2, chữ ký HMAC-SHA256)
+type MoMoGateway struct {
+	Config     *config.MoMoConfig
+	HTTPClient *http.Client
+	Now        func() time.Time
+}
+
+func NewMoMoGateway(cfg *config.MoMoConfig) *MoMoGateway {
+	return &MoMoGateway{
+		Config:     cfg,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Now:        time.Now,
+	}
+}
+
+func (g *MoMoGateway) ID() string {
+	return "momo"
+}
+
+func (g *MoMoGateway) ReturnURL() string {
+	return g.Config.ReturnURL
+}
+
+// momoCreateResponse là phản hồi của /v2/gateway/api/create
+type momoCreateResponse struct {
+	PartnerCode  string `json:"partnerCode"`
+	OrderID      string `json:"orderId"`
+	RequestID    string `json:"requestId"`
+	Amount       int64  `json:"amount"`
+	ResponseTime int64  `json:"responseTime"`
+	Message      string `json:"message"`
+	ResultCode   int    `json:"resultCode"`
+	PayURL       string `json:"payUrl"`
+	Deeplink     string `json:"deeplink"`
+	QRCodeURL    string `json:"qrCodeUrl"`
+}
+
+// momoNotification là dữ liệu MoMo gửi về qua redirectUrl (query) và ipnUrl (JSON)
+type momoNotification struct {
+	PartnerCode  string `json:"partnerCode"`
+	OrderID      string `json:"orderId"`
+	RequestID    string `json:"requestId"`
+	Amount       int64  `json:"amount"`
+	OrderInfo    string `json:"orderInfo"`
+	OrderType    string `json:"orderType"`
+	TransID      int64  `json:"transId"`
+	ResultCode   int    `json:"resultCode"`
+	Message      string `json:"message"`
+	PayType      string `json:"payType"`
+	ResponseTime int64  `json:"responseTime"`
+	ExtraData    string `json:"extraData"`
+	Signature    string `json:"signature"`
+}
+
+// CreatePayment tạo đơn thanh toán MoMo, trả về payUrl/deeplink/QR
+func (g *MoMoGateway) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*CreatePaymentResult, error) {
+	if g.Config.PartnerCode == "" || g.Config.AccessKey == "" || g.Config.SecretKey == "" {
+		return nil, fmt.Errorf("MoMo is not configured. Please set MOMO_PARTNER_CODE, MOMO_ACCESS_KEY and MOMO_SECRET_KEY in environment variables")
+	}
+	if req.Currency != "" && !strings.EqualFold(req.Currency, "VND") {
+		return nil, fmt.Errorf("MoMo chỉ hỗ trợ thanh toán bằng VND")
+	}
+
+	redirectURL := req.ReturnURL
+	if redirectURL == "" || redirectURL == "string" {
+		redirectURL = g.Config.ReturnURL
+	}
+	orderInfo := req.OrderInfo
+	if orderInfo == "" {
+		orderInfo = fmt.Sprintf("Thanh toan don dat cho #%d", req.BookingID)
+	}
+
+	amount := int64(req.Amount)
+	// requestId phải duy nhất cho mỗi lần gọi, orderId chính là ma_giao_dich_noi_bo
+	requestID := fmt.Sprintf("%s-%d", req.TransactionCode, g.Now().UnixMilli())
+	extraData := ""
+
+	rawSignature := fmt.Sprintf(
+		"accessKey=%s&amount=%d&extraData=%s&ipnUrl=%s&orderId=%s&orderInfo=%s&partnerCode=%s&redirectUrl=%s&requestId=%s&requestType=%s",
+		g.Config.AccessKey, amount, extraData, g.Config.IPNURL, req.TransactionCode, orderInfo,
+		g.Config.PartnerCode, redirectURL, requestID, g.Config.RequestType,
+	)
+	body := map[string]any{
+		"partnerCode": g.Config.PartnerCode,
+		"requestId":   requestID,
+		"amount":      amount,
+		"orderId":     req.TransactionCode,
+		"orderInfo":   orderInfo,
+		"redirectUrl": redirectURL,
+		"ipnUrl":      g.Config.IPNURL,
+		"requestType": g.Config.RequestType,
+		"extraData":   extraData,
+		"lang":        g.Config.Lang,
+		"signature":   g.sign(rawSignature),
+	}
+
+	var result momoCreateResponse
+	if err := g.post(ctx, "/v2/gateway/api/create", body, &result); err != nil {
+		return nil, err
+	}
+	if result.ResultCode != MoMoResultSuccess || result.PayURL == "" {
+		return nil, fmt.Errorf("MoMo create order failed (%d): %s", result.ResultCode, result.Message)
+	}
+
+	extra := map[string]any{}
+	if result.Deeplink != "" {
+		extra["deeplink"] = result.Deeplink
+	}
+	if result.QRCodeURL != "" {
+		extra["qr_code_url"] = result.QRCodeURL
+	}
+	return &CreatePaymentResult{PaymentURL: result.PayURL, Extra: extra}, nil
+}
+
+// VerifyCallback xác thực query params khi MoMo chuyển khách hàng về redirectUrl
+func (g *MoMoGateway) VerifyCallback(r *http.Request) (*PaymentResult, error) {
+	query := r.URL.Query()
+	if query.Get("orderId") == "" {
+		return nil, fmt.Errorf("missing orderId")
+	}
+
+	notification := momoNotification{
+		PartnerCode: query.Get("partnerCode"),
+		OrderID:     query.Get("orderId"),
+		RequestID:   query.Get("requestId"),
+		OrderInfo:   query.Get("orderInfo"),
+		OrderType:   query.Get("orderType"),
+		Message:     query.Get("message"),
+		PayType:     query.Get("payType"),
+		ExtraData:   query.Get("extraData"),
+		Signature:   query.Get("signature"),
+	}
+	var err error
+	if notification.Amount, err = strconv.ParseInt(query.Get("amount"), 10, 64); err != nil {
+		return nil, ErrInvalidAmount
+	}
+	if notification.ResultCode, err = strconv.Atoi(query.Get("resultCode")); err != nil {
+		return nil, fmt.Errorf("invalid resultCode: %w", err)
+	}
+	notification.TransID, _ = strconv.ParseInt(query.Get("transId"), 10, 64)
+	notification.ResponseTime, _ = strconv.ParseInt(query.Get("responseTime"), 10, 64)
+
+	return g.parseNotification(notification)
+}
+
+// ParseIPN đọc IPN của MoMo (POST JSON)
+func (g *MoMoGateway) ParseIPN(r *http.Request) (*PaymentResult, error) {
+	var notification momoNotification
+	if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if notification.OrderID == "" {
+		return nil, fmt.Errorf("missing orderId")
+	}
+	return g.parseNotification(notification)
+}
+
+func (g *MoMoGateway) parseNotification(n momoNotification) (*PaymentResult, error) {
+	if n.PartnerCode != g.Config.PartnerCode {
+		return nil, ErrInvalidSignature
+	}
+	rawSignature := fmt.Sprintf(
+		"accessKey=%s&amount=%d&extraData=%s&message=%s&orderId=%s&orderInfo=%s&orderType=%s&partnerCode=%s&payType=%s&requestId=%s&responseTime=%d&resultCode=%d&transId=%d",
+		g.Config.AccessKey, n.Amount, n.ExtraData, n.Message, n.OrderID, n.OrderInfo, n.OrderType,
+		n.PartnerCode, n.PayType, n.RequestID, n.ResponseTime, n.ResultCode, n.TransID,
+	)
+	if !hmac.Equal([]byte(strings.ToLower(n.Signature)), []byte(g.sign(rawSignature))) {
+		return nil, ErrInvalidSignature
+	}
+	if n.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	var gatewayRef string
+	if n.TransID != 0 {
+		gatewayRef = strconv.FormatInt(n.TransID, 10)
+	}
+	return &PaymentResult{
+		TransactionCode: n.OrderID,
+		GatewayRef:      gatewayRef,
+		Amount:          float64(n.Amount),
+		Success:         n.ResultCode == MoMoResultSuccess,
+		ResponseCode:    strconv.Itoa(n.ResultCode),
+	}, nil
+}
+
+// IPNResponse trả về HTTP 204 để MoMo ngừng gửi lại IPN.
+// Chỉ khi lỗi cập nhật DB mới trả 500 để MoMo gửi lại.
+func (g *MoMoGateway) IPNResponse(outcome PaymentOutcome) (int, any) {
+	if outcome == OutcomeUpdateFailed {
+		return http.StatusInternalServerError, map[string]string{"message": string(outcome)}
+	}
+	return http.StatusNoContent, nil
+}
+
+// momoRefundResponse là phản hồi của /v2/gateway/api/refund
+type momoRefundResponse struct {
+	PartnerCode  string `json:"partnerCode"`
+	OrderID      string `json:"orderId"`
+	RequestID    string `json:"requestId"`
+	Amount       int64  `json:"amount"`
+	TransID      int64  `json:"transId"`
+	ResultCode   int    `json:"resultCode"`
+	Message      string `json:"message"`
+	ResponseTime int64  `json:"responseTime"`
+}
+
+// Refund gọi API hoàn tiền của MoMo (hỗ trợ hoàn một phần)
+func (g *MoMoGateway) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	if g.Config.PartnerCode == "" || g.Config.AccessKey == "" || g.Config.SecretKey == "" {
+		return nil, fmt.Errorf("MoMo is not configured")
+	}
+	transID, err := strconv.ParseInt(req.GatewayRef, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MoMo transId: %q", req.GatewayRef)
+	}
+
+	amount := int64(req.Amount)
+	description := req.Reason
+	if description == "" {
+		description = fmt.Sprintf("Hoan tien giao dich %s", req.TransactionCode)
+	}
+	// orderId của yêu cầu hoàn tiền phải khác orderId gốc
+	orderID := req.RefundCode
+	requestID := fmt.Sprintf("%s-%d", orderID, g.Now().UnixMilli())
+
+	rawSignature := fmt.Sprintf(
+		"accessKey=%s&amount=%d&description=%s&orderId=%s&partnerCode=%s&requestId=%s&transId=%d",
+		g.Config.AccessKey, amount, description, orderID, g.Config.PartnerCode, requestID, transID,
+	)
+	body := map[string]any{
+		"partnerCode": g.Config.PartnerCode,
+		"orderId":     orderID,
+		"requestId":   requestID,
+		"amount":      amount,
+		"transId":     transID,
+		"lang":        g.Config.Lang,
+		"description": description,
+		"signature":   g.sign(rawSignature),
+	}
+
+	var result momoRefundResponse
+	if err := g.post(ctx, "/v2/gateway/api/refund", body, &result); err != nil {
+		return nil, err
+	}
+
+	var gatewayRef string
+	if result.TransID != 0 {
+		gatewayRef = strconv.FormatInt(result.TransID, 10)
+	}
+	return &RefundResult{
+		GatewayRef:   gatewayRef,
+		Success:      result.ResultCode == MoMoResultSuccess,
+		ResponseCode: strconv.Itoa(result.ResultCode),
+		Message:      result.Message,
+	}, nil
+}
+
+// post gửi JSON tới API MoMo và đọc phản hồi
+func (g *MoMoGateway) post(ctx context.Context, path string, body any, out any) error {
+	endpoint, err := url.JoinPath(g.Config.Endpoint, path)
+	if err != nil {
+		return fmt.Errorf("invalid MoMo endpoint: %w", err)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create MoMo request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	resp, err := g.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to call MoMo API: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read MoMo response: %w", err)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("invalid MoMo response (%d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// sign ký dữ liệu bằng HMAC-SHA256 với secret key
+func (g *MoMoGateway) sign(data string) string {
+	h := hmac.New(sha256.New, []byte(g.Config.SecretKey))
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"travia.backend/config"
+)
+
+const (
+	testMoMoPartnerCode = "MOMOTEST"
+	testMoMoAccessKey   = "access-key"
+	testMoMoSecretKey   = "secret-key"
+)
+
+func momoTestSign(data string) string {
+	h := hmac.New(sha256.New, []byte(testMoMoSecretKey))
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newTestMoMoGateway(endpoint string) *MoMoGateway {
+	g := NewMoMoGateway(&config.MoMoConfig{
+		PartnerCode: testMoMoPartnerCode,
+		AccessKey:   testMoMoAccessKey,
+		SecretKey:   testMoMoSecretKey,
+		Endpoint:    endpoint,
+		ReturnURL:   "http://localhost:5173/payment/momo/return",
+		IPNURL:      "http://localhost:3000/api/payment/momo/ipn",
+		RequestType: "captureWallet",
+		Lang:        "vi",
+	})
+	g.Now = func() time.Time { return time.UnixMilli(1_700_000_000_000) }
+	return g
+}
+
+// mockMoMoServer giả lập API MoMo: kiểm tra chữ ký yêu cầu rồi trả về phản hồi cấu hình sẵn
+type mockMoMoServer struct {
+	t          *testing.T
+	resultCode int
+	requests   map[string]map[string]any
+}
+
+func (m *mockMoMoServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		m.t.Fatalf("invalid request body: %v", err)
+	}
+	m.requests[r.URL.Path] = body
+
+	str := func(k string) string { return fmt.Sprint(body[k]) }
+	num := func(k string) int64 { return int64(body[k].(float64)) }
+
+	var raw string
+	var resp map[string]any
+	switch r.URL.Path {
+	case "/v2/gateway/api/create":
+		raw = fmt.Sprintf(
+			"accessKey=%s&amount=%d&extraData=%s&ipnUrl=%s&orderId=%s&orderInfo=%s&partnerCode=%s&redirectUrl=%s&requestId=%s&requestType=%s",
+			testMoMoAccessKey, num("amount"), str("extraData"), str("ipnUrl"), str("orderId"), str("orderInfo"),
+			str("partnerCode"), str("redirectUrl"), str("requestId"), str("requestType"),
+		)
+		resp = map[string]any{
+			"partnerCode": str("partnerCode"),
+			"orderId":     str("orderId"),
+			"requestId":   str("requestId"),
+			"amount":      num("amount"),
+			"resultCode":  m.resultCode,
+			"message":     "Thành công.",
+		}
+		if m.resultCode == MoMoResultSuccess {
+			resp["payUrl"] = "https://test-payment.momo.vn/pay/" + str("orderId")
+			resp["deeplink"] = "momo://pay/" + str("orderId")
+		}
+	case "/v2/gateway/api/refund":
+		raw = fmt.Sprintf(
+			"accessKey=%s&amount=%d&description=%s&orderId=%s&partnerCode=%s&requestId=%s&transId=%d",
+			testMoMoAccessKey, num("amount"), str("description"), str("orderId"), str("partnerCode"),
+			str("requestId"), num("transId"),
+		)
+		resp = map[string]any{
+			"partnerCode": str("partnerCode"),
+			"orderId":     str("orderId"),
+			"requestId":   str("requestId"),
+			"amount":      num("amount"),
+			"transId":     int64(4100000001),
+			"resultCode":  m.resultCode,
+			"message":     "Refund message",
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if body["signature"] != momoTestSign(raw) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"resultCode": 11, "message": "Signature mismatch"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func startMockMoMo(t *testing.T, resultCode int) (*mockMoMoServer, *httptest.Server) {
+	mock := &mockMoMoServer{t: t, resultCode: resultCode, requests: make(map[string]map[string]any)}
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+	return mock, srv
+}
+
+func TestMoMoCreatePayment(t *testing.T) {
+	mock, srv := startMockMoMo(t, MoMoResultSuccess)
+	g := newTestMoMoGateway(srv.URL)
+
+	result, err := g.CreatePayment(context.Background(), CreatePaymentRequest{
+		BookingID:       7,
+		TransactionCode: "TXN7",
+		Amount:          1500000,
+		Currency:        "VND",
+	})
+	if err != nil {
+		t.Fatalf("CreatePayment() error = %v", err)
+	}
+	if result.PaymentURL != "https://test-payment.momo.vn/pay/TXN7" {
+		t.Errorf("PaymentURL = %q", result.PaymentURL)
+	}
+	if result.Extra["deeplink"] != "momo://pay/TXN7" {
+		t.Errorf("deeplink = %v", result.Extra["deeplink"])
+	}
+	req := mock.requests["/v2/gateway/api/create"]
+	if req["orderId"] != "TXN7" || req["amount"].(float64) != 1500000 || req["redirectUrl"] != g.Config.ReturnURL {
+		t.Errorf("unexpected create request %v", req)
+	}
+}
+
+func TestMoMoCreatePaymentFailure(t *testing.T) {
+	_, srv := startMockMoMo(t, 1001)
+	g := newTestMoMoGateway(srv.URL)
+
+	if _, err := g.CreatePayment(context.Background(), CreatePaymentRequest{TransactionCode: "TXN8", Amount: 10000}); err == nil {
+		t.Fatal("CreatePayment() expected error for non-zero resultCode")
+	}
+	if _, err := g.CreatePayment(context.Background(), CreatePaymentRequest{TransactionCode: "TXN8", Amount: 10, Currency: "USD"}); err == nil {
+		t.Fatal("CreatePayment() expected error for non-VND currency")
+	}
+}
+
+func momoTestNotification(amount int64, resultCode int) url.Values {
+	n := url.Values{
+		"partnerCode":  {testMoMoPartnerCode},
+		"orderId":      {"TXN7"},
+		"requestId":    {"TXN7-1700000000000"},
+		"amount":       {strconv.FormatInt(amount, 10)},
+		"orderInfo":    {"Thanh toan don dat cho #7"},
+		"orderType":    {"momo_wallet"},
+		"transId":      {"4088878653"},
+		"resultCode":   {strconv.Itoa(resultCode)},
+		"message":      {"Successful."},
+		"payType":      {"qr"},
+		"responseTime": {"1700000005000"},
+		"extraData":    {""},
+	}
+	raw := fmt.Sprintf(
+		"accessKey=%s&amount=%s&extraData=%s&message=%s&orderId=%s&orderInfo=%s&orderType=%s&partnerCode=%s&payType=%s&requestId=%s&responseTime=%s&resultCode=%s&transId=%s",
+		testMoMoAccessKey, n.Get("amount"), n.Get("extraData"), n.Get("message"), n.Get("orderId"), n.Get("orderInfo"),
+		n.Get("orderType"), n.Get("partnerCode"), n.Get("payType"), n.Get("requestId"), n.Get("responseTime"),
+		n.Get("resultCode"), n.Get("transId"),
+	)
+	n.Set("signature", momoTestSign(raw))
+	return n
+}
+
+func TestMoMoVerifyCallback(t *testing.T) {
+	g := newTestMoMoGateway("http://unused")
+
+	query := momoTestNotification(1500000, MoMoResultSuccess)
+	r := httptest.NewRequest(http.MethodGet, "/payment/momo/return?"+query.Encode(), nil)
+	result, err := g.VerifyCallback(r)
+	if err != nil {
+		t.Fatalf("VerifyCallback() error = %v", err)
+	}
+	if !result.Success || result.TransactionCode != "TXN7" || result.GatewayRef != "4088878653" || result.Amount != 1500000 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	tampered := momoTestNotification(1500000, MoMoResultSuccess)
+	tampered.Set("amount", "1000")
+	r = httptest.NewRequest(http.MethodGet, "/payment/momo/return?"+tampered.Encode(), nil)
+	if _, err := g.VerifyCallback(r); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("VerifyCallback() tampered error = %v, want ErrInvalidSignature", err)
+	}
+
+	otherPartner := momoTestNotification(1500000, MoMoResultSuccess)
+	otherPartner.Set("partnerCode", "OTHER")
+	r = httptest.NewRequest(http.MethodGet, "/payment/momo/return?"+otherPartner.Encode(), nil)
+	if _, err := g.VerifyCallback(r); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("VerifyCallback() partner error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func momoTestIPNBody(t *testing.T, n url.Values) []byte {
+	t.Helper()
+	amount, _ := strconv.ParseInt(n.Get("amount"), 10, 64)
+	transID, _ := strconv.ParseInt(n.Get("transId"), 10, 64)
+	resultCode, _ := strconv.Atoi(n.Get("resultCode"))
+	responseTime, _ := strconv.ParseInt(n.Get("responseTime"), 10, 64)
+	body, err := json.Marshal(map[string]any{
+		"partnerCode":  n.Get("partnerCode"),
+		"orderId":      n.Get("orderId"),
+		"requestId":    n.Get("requestId"),
+		"amount":       amount,
+		"orderInfo":    n.Get("orderInfo"),
+		"orderType":    n.Get("orderType"),
+		"transId":      transID,
+		"resultCode":   resultCode,
+		"message":      n.Get("message"),
+		"payType":      n.Get("payType"),
+		"responseTime": responseTime,
+		"extraData":    n.Get("extraData"),
+		"signature":    n.Get("signature"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestMoMoParseIPN(t *testing.T) {
+	g := newTestMoMoGateway("http://unused")
+
+	body := momoTestIPNBody(t, momoTestNotification(1500000, 1006))
+	r := httptest.NewRequest(http.MethodPost, "/payment/momo/ipn", bytes.NewReader(body))
+	result, err := g.ParseIPN(r)
+	if err != nil {
+		t.Fatalf("ParseIPN() error = %v", err)
+	}
+	if result.Success || result.ResponseCode != "1006" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	forged := momoTestNotification(1500000, MoMoResultSuccess)
+	forged.Set("signature", momoTestSign("forged"))
+	r = httptest.NewRequest(http.MethodPost, "/payment/momo/ipn", bytes.NewReader(momoTestIPNBody(t, forged)))
+	if _, err := g.ParseIPN(r); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("ParseIPN() forged error = %v, want ErrInvalidSignature", err)
+	}
+
+	if status, _ := g.IPNResponse(OutcomeUpdateFailed); status != http.StatusInternalServerError {
+		t.Errorf("IPNResponse(update failed) = %d, want 500", status)
+	}
+}
+
+func TestMoMoRefund(t *testing.T) {
+	mock, srv := startMockMoMo(t, MoMoResultSuccess)
+	g := newTestMoMoGateway(srv.URL)
+
+	result, err := g.Refund(context.Background(), RefundRequest{
+		TransactionCode: "TXN7",
+		RefundCode:      "RF7",
+		GatewayRef:      "4088878653",
+		Amount:          500000,
+		OriginalAmount:  1500000,
+	})
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if !result.Success || result.GatewayRef != "4100000001" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	req := mock.requests["/v2/gateway/api/refund"]
+	if req["orderId"] != "RF7" || req["transId"].(float64) != 4088878653 || req["amount"].(float64) != 500000 {
+		t.Errorf("unexpected refund request %v", req)
+	}
+
+	mock.resultCode = 1080
+	result, err = g.Refund(context.Background(), RefundRequest{TransactionCode: "TXN7", RefundCode: "RF8", GatewayRef: "4088878653", Amount: 500000})
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if result.Success || result.ResponseCode != "1080" {
+		t.Errorf("unexpected failed refund result %+v", result)
+	}
+
+	if _, err := g.Refund(context.Background(), RefundRequest{RefundCode: "RF9", GatewayRef: "not-a-number", Amount: 1}); err == nil {
+		t.Fatal("Refund() expected error for invalid transId")
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Lỗi chung khi đọc callback/IPN từ cổng thanh toán
+var (
+	ErrInvalidSignature  = errors.New("invalid signature")
+	ErrInvalidAmount     = errors.New("invalid amount")
+	ErrRefundUnsupported = errors.New("refund is not supported by this gateway")
+)
+
+// PaymentOutcome là kết quả xử lý một thông báo thanh toán, dùng để
+// mỗi cổng thanh toán trả về mã phản hồi theo định dạng riêng (vd: RspCode của VNPay)
+type PaymentOutcome string
+
+const (
+	OutcomeSuccess              PaymentOutcome = "success"
+	OutcomeAlreadyConfirmed     PaymentOutcome = "already_confirmed"
+	OutcomeInvalidRequest       PaymentOutcome = "invalid_request"
+	OutcomeInvalidSignature     PaymentOutcome = "invalid_signature"
+	OutcomeTransactionNotFound  PaymentOutcome = "transaction_not_found"
+	OutcomeInvalidAmount        PaymentOutcome = "invalid_amount"
+	OutcomeInvalidTransaction   PaymentOutcome = "invalid_transaction"
+	OutcomeBookingNotFound      PaymentOutcome = "booking_not_found"
+	OutcomeInvalidBookingStatus PaymentOutcome = "invalid_booking_status"
+	OutcomeUpdateFailed         PaymentOutcome = "update_failed"
+	OutcomePaymentFailed        PaymentOutcome = "payment_failed"
+)
+
+// CreatePaymentRequest là thông tin tạo thanh toán cho một giao dịch (lich_su_giao_dich)
+type CreatePaymentRequest struct {
+	BookingID       int32
+	TransactionCode string // ma_giao_dich_noi_bo
+	Amount          float64
+	Currency        string
+	OrderInfo       string
+	ReturnURL       string // Ghi đè return URL mặc định của cổng (nếu có)
+	ClientIP        string
+}
+
+// CreatePaymentResult là kết quả tạo thanh toán
+type CreatePaymentResult struct {
+	PaymentURL string         // URL chuyển hướng khách hàng sang cổng thanh toán
+	GatewayRef string         // Mã tham chiếu phía cổng (nếu cổng trả về ngay khi tạo)
+	Extra      map[string]any // Dữ liệu riêng của cổng trả về cho frontend (deeplink, QR, ...)
+}
+
+// PaymentResult là kết quả thanh toán đã được xác thực chữ ký từ callback/IPN
+type PaymentResult struct {
+	TransactionCode string  // ma_giao_dich_noi_bo
+	GatewayRef      string  // Mã giao dịch phía cổng thanh toán
+	Amount          float64 // Số tiền theo đơn vị của giao dịch
+	Success         bool
+	ResponseCode    string // Mã phản hồi gốc của cổng
+}
+
+// RefundRequest là yêu cầu hoàn tiền cho một giao dịch đã thanh toán
+type RefundRequest struct {
+	TransactionCode string    // ma_giao_dich_noi_bo của giao dịch thanh toán gốc
+	RefundCode      string    // Mã yêu cầu hoàn tiền (duy nhất)
+	GatewayRef      string    // Mã giao dịch phía cổng của giao dịch gốc
+	Amount          float64   // Số tiền hoàn
+	OriginalAmount  float64   // Số tiền giao dịch gốc (để phân biệt hoàn toàn phần / một phần)
+	TransactionDate time.Time // Thời điểm thanh toán của giao dịch gốc
+	Reason          string
+	CreatedBy       string
+	ClientIP        string
+}
+
+// RefundResult là kết quả gọi API hoàn tiền của cổng
+type RefundResult struct {
+	GatewayRef   string
+	Success      bool
+	ResponseCode string
+	Message      string
+}
+
+// PaymentGateway là cổng thanh toán, định danh bởi cong_thanh_toan.id
+type PaymentGateway interface {
+	// ID trả về cong_thanh_toan.id (vd: "vnpay", "momo")
+	ID() string
+	// ReturnURL là URL frontend nhận kết quả sau khi khách hàng thanh toán xong
+	ReturnURL() string
+	// CreatePayment tạo yêu cầu thanh toán cho giao dịch
+	CreatePayment(ctx context.Context, req CreatePaymentRequest) (*CreatePaymentResult, error)
+	// VerifyCallback xác thực dữ liệu khi khách hàng được chuyển về (return URL)
+	VerifyCallback(r *http.Request) (*PaymentResult, error)
+	// ParseIPN xác thực và đọc thông báo server-to-server từ cổng
+	ParseIPN(r *http.Request) (*PaymentResult, error)
+	// IPNResponse trả về HTTP status và body phản hồi cho cổng theo kết quả xử lý IPN
+	IPNResponse(outcome PaymentOutcome) (int, any)
+	// Refund gọi API hoàn tiền của cổng
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+}
+
+// PaymentGatewayRegistry quản lý các cổng thanh toán theo cong_thanh_toan.id
+type PaymentGatewayRegistry struct {
+	mu       sync.RWMutex
+	gateways map[string]PaymentGateway
+}
+
+func NewPaymentGatewayRegistry() *PaymentGatewayRegistry {
+	return &PaymentGatewayRegistry{gateways: make(map[string]PaymentGateway)}
+}
+
+// Register đăng ký (hoặc thay thế) một cổng thanh toán
+func (r *PaymentGatewayRegistry) Register(gateway PaymentGateway) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gateways[gateway.ID()] = gateway
+}
+
+// Get lấy cổng thanh toán theo cong_thanh_toan.id
+func (r *PaymentGatewayRegistry) Get(id string) (PaymentGateway, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	gateway, ok := r.gateways[id]
+	return gateway, ok
+}
+
+// IDs trả về danh sách cổng đã đăng ký (đã sắp xếp)
+func (r *PaymentGatewayRegistry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.gateways))
+	for id := range r.gateways {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
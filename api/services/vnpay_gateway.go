@@ -0,0 +1,321 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"travia.backend/config"
+)
+
+const (
+	// VNPayPaymentExpirationTime là thời gian hết hạn của payment URL VNPay (vnp_ExpireDate)
+	VNPayPaymentExpirationTime = 15 * time.Minute
+	// VNPayDefaultAPIURL là endpoint API truy vấn/hoàn tiền (sandbox)
+	VNPayDefaultAPIURL = "https://sandbox.vnpayment.vn/merchant_webapi/api/transaction"
+)
+
+// vnpayLocation cố định múi giờ Việt Nam để không phụ thuộc vào OS/Docker timezone
+var vnpayLocation = time.FixedZone("ICT", 7*3600)
+
+// VNPayGateway triển khai PaymentGateway cho VNPay
+type VNPayGateway struct {
+	Config     *config.VNPayConfig
+	HTTPClient *http.Client
+	Now        func() time.Time
+}
+
+func NewVNPayGateway(cfg *config.VNPayConfig) *VNPayGateway {
+	return &VNPayGateway{
+		Config:     cfg,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Now:        time.Now,
+	}
+}
+
+func (g *VNPayGateway) ID() string {
+	return "vnpay"
+}
+
+func (g *VNPayGateway) ReturnURL() string {
+	return g.Config.ReturnURL
+}
+
+// CreatePayment tạo URL thanh toán VNPay có chữ ký HMAC-SHA512
+func (g *VNPayGateway) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*CreatePaymentResult, error) {
+	if g.Config.TMNCode == "" {
+		return nil, fmt.Errorf("VNPAY_TMN_CODE is not configured. Please set VNPAY_TMN_CODE in environment variables")
+	}
+	if g.Config.HashSecret == "" {
+		return nil, fmt.Errorf("VNPAY_HASH_SECRET is not configured. Please set VNPAY_HASH_SECRET in environment variables")
+	}
+	if g.Config.PaymentURL == "" {
+		return nil, fmt.Errorf("VNPAY_PAYMENT_URL is not configured. Please set VNPAY_PAYMENT_URL in environment variables (e.g., https://sandbox.vnpayment.vn/paymentv2/vpcpay.html)")
+	}
+
+	// Sử dụng returnURL từ request hoặc config
+	returnURL := req.ReturnURL
+	if returnURL == "" || returnURL == "string" {
+		returnURL = g.Config.ReturnURL
+	}
+	if returnURL == "" || returnURL == "string" {
+		return nil, fmt.Errorf("Return URL is not configured. Please set VNPAY_RETURN_URL in .env or provide return_url in request")
+	}
+
+	clientIP := req.ClientIP
+	if clientIP == "" || clientIP == "::1" {
+		clientIP = "127.0.0.1"
+	}
+
+	if strings.Contains(g.Config.IPNURL, "localhost") || strings.Contains(g.Config.IPNURL, "127.0.0.1") {
+		fmt.Printf("⚠️  WARNING: IPN URL is localhost! VNPay cannot call localhost. Use ngrok or deploy to production.\n")
+	}
+
+	now := g.Now().In(vnpayLocation)
+	orderInfo := req.OrderInfo
+	if orderInfo == "" {
+		orderInfo = fmt.Sprintf("Thanh toan don dat cho #%d", req.BookingID)
+	}
+
+	params := url.Values{}
+	params.Add("vnp_Version", "2.1.0")
+	params.Add("vnp_Command", g.Config.Command)
+	params.Add("vnp_TmnCode", g.Config.TMNCode)
+	params.Add("vnp_Amount", strconv.FormatInt(int64(req.Amount)*100, 10)) // VND * 100
+	params.Add("vnp_CurrCode", g.Config.CurrCode)
+	params.Add("vnp_TxnRef", req.TransactionCode)
+	params.Add("vnp_OrderInfo", orderInfo)
+	params.Add("vnp_OrderType", "other")
+	params.Add("vnp_Locale", g.Config.Locale)
+	params.Add("vnp_ReturnUrl", returnURL)
+	params.Add("vnp_IpAddr", clientIP)
+	params.Add("vnp_CreateDate", now.Format("20060102150405"))
+	params.Add("vnp_ExpireDate", now.Add(VNPayPaymentExpirationTime).Format("20060102150405"))
+
+	queryString := vnpayQueryString(params)
+	secureHash := g.sign(queryString)
+	finalURL := fmt.Sprintf("%s?%s&vnp_SecureHash=%s", g.Config.PaymentURL, queryString, secureHash)
+
+	if !strings.HasPrefix(finalURL, "https://") && !strings.HasPrefix(finalURL, "http://") {
+		return nil, fmt.Errorf("Invalid payment URL format: %s", finalURL)
+	}
+	return &CreatePaymentResult{PaymentURL: finalURL}, nil
+}
+
+// VerifyCallback xác thực query params khi VNPay chuyển khách hàng về Return URL
+func (g *VNPayGateway) VerifyCallback(r *http.Request) (*PaymentResult, error) {
+	return g.parseResult(r.URL.Query())
+}
+
+// ParseIPN đọc IPN của VNPay (VNPay gửi GET query string; hỗ trợ cả form POST)
+func (g *VNPayGateway) ParseIPN(r *http.Request) (*PaymentResult, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	return g.parseResult(r.Form)
+}
+
+func (g *VNPayGateway) parseResult(params url.Values) (*PaymentResult, error) {
+	if len(params) == 0 || params.Get("vnp_TxnRef") == "" {
+		return nil, fmt.Errorf("missing vnp_TxnRef")
+	}
+	if !g.verifySignature(params) {
+		return nil, ErrInvalidSignature
+	}
+
+	amount, err := strconv.ParseInt(params.Get("vnp_Amount"), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidAmount
+	}
+
+	responseCode := params.Get("vnp_ResponseCode")
+	transactionStatus := params.Get("vnp_TransactionStatus")
+	// vnp_TransactionStatus có thể không có trong một số phiên bản IPN cũ
+	success := responseCode == "00" && (transactionStatus == "" || transactionStatus == "00")
+
+	return &PaymentResult{
+		TransactionCode: params.Get("vnp_TxnRef"),
+		GatewayRef:      params.Get("vnp_TransactionNo"),
+		Amount:          float64(amount / 100), // VNPay gửi amount * 100
+		Success:         success,
+		ResponseCode:    responseCode,
+	}, nil
+}
+
+// IPNResponse trả về RspCode theo đặc tả IPN của VNPay
+func (g *VNPayGateway) IPNResponse(outcome PaymentOutcome) (int, any) {
+	codes := map[PaymentOutcome][2]string{
+		OutcomeSuccess:              {"00", "Confirm Success"},
+		OutcomeTransactionNotFound:  {"01", "Transaction not found"},
+		OutcomeAlreadyConfirmed:     {"02", "Order Already Update"},
+		OutcomeInvalidAmount:        {"04", "invalid amount"},
+		OutcomeInvalidTransaction:   {"05", "Invalid transaction"},
+		OutcomeBookingNotFound:      {"06", "Booking not found"},
+		OutcomeInvalidBookingStatus: {"07", "Invalid booking status"},
+		OutcomeUpdateFailed:         {"08", "Failed to update transaction"},
+		OutcomePaymentFailed:        {"10", "Payment Error"},
+		OutcomeInvalidSignature:     {"97", "Invalid Signature"},
+		OutcomeInvalidRequest:       {"99", "Invalid request"},
+	}
+	code, ok := codes[outcome]
+	if !ok {
+		code = codes[OutcomeInvalidRequest]
+	}
+	// VNPay luôn yêu cầu HTTP 200, kết quả nằm trong RspCode
+	return http.StatusOK, map[string]string{"RspCode": code[0], "Message": code[1]}
+}
+
+// Refund gọi API hoàn tiền của VNPay (vnp_Command=refund)
+func (g *VNPayGateway) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	if g.Config.TMNCode == "" || g.Config.HashSecret == "" {
+		return nil, fmt.Errorf("VNPay is not configured")
+	}
+	apiURL := g.Config.APIURL
+	if apiURL == "" {
+		apiURL = VNPayDefaultAPIURL
+	}
+
+	now := g.Now().In(vnpayLocation)
+	// 02: hoàn toàn phần, 03: hoàn một phần
+	transactionType := "02"
+	if req.OriginalAmount > 0 && int64(req.Amount) < int64(req.OriginalAmount) {
+		transactionType = "03"
+	}
+	createdBy := req.CreatedBy
+	if createdBy == "" {
+		createdBy = "travia"
+	}
+	clientIP := req.ClientIP
+	if clientIP == "" || clientIP == "::1" {
+		clientIP = "127.0.0.1"
+	}
+	orderInfo := req.Reason
+	if orderInfo == "" {
+		orderInfo = fmt.Sprintf("Hoan tien giao dich %s", req.TransactionCode)
+	}
+
+	body := map[string]string{
+		"vnp_RequestId":       req.RefundCode,
+		"vnp_Version":         "2.1.0",
+		"vnp_Command":         "refund",
+		"vnp_TmnCode":         g.Config.TMNCode,
+		"vnp_TransactionType": transactionType,
+		"vnp_TxnRef":          req.TransactionCode,
+		"vnp_Amount":          strconv.FormatInt(int64(req.Amount)*100, 10),
+		"vnp_OrderInfo":       orderInfo,
+		"vnp_TransactionNo":   req.GatewayRef,
+		"vnp_TransactionDate": req.TransactionDate.In(vnpayLocation).Format("20060102150405"),
+		"vnp_CreateBy":        createdBy,
+		"vnp_CreateDate":      now.Format("20060102150405"),
+		"vnp_IpAddr":          clientIP,
+	}
+	body["vnp_SecureHash"] = g.sign(strings.Join([]string{
+		body["vnp_RequestId"], body["vnp_Version"], body["vnp_Command"], body["vnp_TmnCode"],
+		body["vnp_TransactionType"], body["vnp_TxnRef"], body["vnp_Amount"], body["vnp_TransactionNo"],
+		body["vnp_TransactionDate"], body["vnp_CreateBy"], body["vnp_CreateDate"], body["vnp_IpAddr"],
+		body["vnp_OrderInfo"],
+	}, "|"))
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VNPay refund request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call VNPay refund API: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VNPay refund response: %w", err)
+	}
+
+	var result map[string]string
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("invalid VNPay refund response (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	// Xác thực chữ ký phản hồi; phản hồi không có chữ ký bị coi là không hợp lệ như callback/IPN
+	receivedHash := result["vnp_SecureHash"]
+	if receivedHash == "" {
+		return nil, ErrInvalidSignature
+	}
+	expectedHash := g.sign(strings.Join([]string{
+		result["vnp_ResponseId"], result["vnp_Command"], result["vnp_ResponseCode"], result["vnp_Message"],
+		result["vnp_TmnCode"], result["vnp_TxnRef"], result["vnp_Amount"], result["vnp_BankCode"],
+		result["vnp_PayDate"], result["vnp_TransactionNo"], result["vnp_TransactionType"],
+		result["vnp_TransactionStatus"], result["vnp_OrderInfo"],
+	}, "|"))
+	if !hmac.Equal([]byte(strings.ToLower(receivedHash)), []byte(expectedHash)) {
+		return nil, ErrInvalidSignature
+	}
+
+	return &RefundResult{
+		GatewayRef:   result["vnp_TransactionNo"],
+		Success:      result["vnp_ResponseCode"] == "00",
+		ResponseCode: result["vnp_ResponseCode"],
+		Message:      result["vnp_Message"],
+	}, nil
+}
+
+// verifySignature xác thực vnp_SecureHash của callback/IPN
+func (g *VNPayGateway) verifySignature(params url.Values) bool {
+	receivedHash := params.Get("vnp_SecureHash")
+	if receivedHash == "" {
+		return false
+	}
+
+	// Tạo bản sao của params và loại bỏ SecureHash
+	verifyParams := make(url.Values)
+	for k, v := range params {
+		if strings.HasPrefix(k, "vnp_") && k != "vnp_SecureHash" && k != "vnp_SecureHashType" {
+			verifyParams[k] = v
+		}
+	}
+	calculatedHash := g.sign(vnpayQueryString(verifyParams))
+	return hmac.Equal([]byte(strings.ToLower(receivedHash)), []byte(calculatedHash))
+}
+
+// sign tính HMAC-SHA512 (hex) với vnp_HashSecret
+func (g *VNPayGateway) sign(data string) string {
+	mac := hmac.New(sha512.New, []byte(g.Config.HashSecret))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// vnpayQueryString tạo query string với key sắp xếp theo alphabet (bắt buộc để đúng chữ ký)
+func vnpayQueryString(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var queryBuilder strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			queryBuilder.WriteString("&")
+		}
+		queryBuilder.WriteString(k)
+		queryBuilder.WriteString("=")
+		queryBuilder.WriteString(url.QueryEscape(params.Get(k)))
+	}
+	return queryBuilder.String()
+}
@@ -0,0 +1,279 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	SigningAlgHS256 = "HS256"
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+// SigningKey là một khóa ký JWT bất đối xứng. Khóa nằm trong JWKS từ lúc được tạo,
+// dùng để ký từ ActiveFrom tới khi có khóa mới hơn kích hoạt
+type SigningKey struct {
+	Kid        string
+	Alg        string
+	Private    crypto.Signer
+	Public     crypto.PublicKey
+	ActiveFrom time.Time
+}
+
+// JWK là khóa công khai theo RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet là nội dung /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// tokenSettings là cấu hình ký / xác thực token dùng chung cho cả server
+type tokenSettings struct {
+	mu           sync.RWMutex
+	issuer       string
+	audience     []string
+	keys         []SigningKey // sắp xếp theo ActiveFrom tăng dần
+	acceptLegacy bool         // vẫn nhận token HS256 khi đã có khóa bất đối xứng
+}
+
+var tokens = &tokenSettings{issuer: "localhost:3000/travia"}
+
+// ConfigureTokens đặt issuer / audience cho token (gọi một lần khi khởi tạo server).
+// Phần tử đầu tiên của audience là audience bắt buộc khi xác thực token tại API này
+func ConfigureTokens(issuer string, audience []string, acceptLegacyHS256 bool) {
+	tokens.mu.Lock()
+	defer tokens.mu.Unlock()
+	if issuer != "" {
+		tokens.issuer = issuer
+	}
+	tokens.audience = audience
+	tokens.acceptLegacy = acceptLegacyHS256
+}
+
+// SetSigningKeys thay toàn bộ khóa ký bất đối xứng đang dùng (sau mỗi lần tải lại từ DB)
+func SetSigningKeys(keys []SigningKey) {
+	sorted := make([]SigningKey, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom) })
+
+	tokens.mu.Lock()
+	defer tokens.mu.Unlock()
+	tokens.keys = sorted
+}
+
+// currentSigningKey trả về khóa kích hoạt gần nhất, nil nếu chưa có khóa nào có hiệu lực
+func (t *tokenSettings) currentSigningKey(now time.Time) *SigningKey {
+	for i := len(t.keys) - 1; i >= 0; i-- {
+		if !t.keys[i].ActiveFrom.After(now) {
+			return &t.keys[i]
+		}
+	}
+	return nil
+}
+
+func (t *tokenSettings) publicKey(kid string) *SigningKey {
+	for i := range t.keys {
+		if t.keys[i].Kid == kid {
+			return &t.keys[i]
+		}
+	}
+	return nil
+}
+
+// registeredClaims tạo các claim chuẩn (iss, aud, sub, jti, iat, exp) theo cấu hình hiện tại
+func registeredClaims(subject, jti string, ttl time.Duration) jwt.RegisteredClaims {
+	tokens.mu.RLock()
+	defer tokens.mu.RUnlock()
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    tokens.issuer,
+		Subject:   subject,
+		ID:        jti,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)), // thời gian hết hạn, sau thời gian này jwt không còn giá trị
+	}
+	if len(tokens.audience) > 0 {
+		claims.Audience = jwt.ClaimStrings(tokens.audience)
+	}
+	return claims
+}
+
+// signToken ký bằng khóa bất đối xứng đang hiệu lực (kèm kid trong header), chưa có khóa thì ký HS256 bằng secretkey
+func signToken(claims jwt.Claims, secretkey string) (string, error) {
+	tokens.mu.RLock()
+	key := tokens.currentSigningKey(time.Now())
+	tokens.mu.RUnlock()
+
+	if key == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretkey))
+	}
+	token := jwt.NewWithClaims(signingMethod(key.Alg), claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.Private)
+}
+
+// verificationKey chọn khóa xác thực theo thuật toán và kid trong header token
+func verificationKey(token *jwt.Token, secretkey string) (any, error) {
+	tokens.mu.RLock()
+	defer tokens.mu.RUnlock()
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if len(tokens.keys) > 0 && !tokens.acceptLegacy {
+			return nil, errors.New("phương thức ký không hợp lệ")
+		}
+		return []byte(secretkey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key := tokens.publicKey(kid)
+	if key == nil {
+		return nil, fmt.Errorf("không tìm thấy khóa ký %q", kid)
+	}
+	if token.Method.Alg() != key.Alg {
+		return nil, errors.New("phương thức ký không khớp với khóa")
+	}
+	return key.Public, nil
+}
+
+func validationOptions() []jwt.ParserOption {
+	tokens.mu.RLock()
+	defer tokens.mu.RUnlock()
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{SigningAlgHS256, SigningAlgRS256, SigningAlgEdDSA}),
+		jwt.WithIssuer(tokens.issuer),
+	}
+	if len(tokens.audience) > 0 {
+		opts = append(opts, jwt.WithAudience(tokens.audience[0]))
+	}
+	return opts
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	if alg == SigningAlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// CurrentJWKS trả về khóa công khai của mọi khóa ký đã công bố (kể cả khóa chưa tới lúc kích hoạt)
+func CurrentJWKS() JWKSet {
+	tokens.mu.RLock()
+	defer tokens.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(tokens.keys))}
+	for _, k := range tokens.keys {
+		jwk := JWK{Use: "sig", Alg: k.Alg, Kid: k.Kid}
+		switch pub := k.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// GenerateSigningKeyPEM tạo cặp khóa mới, trả về khóa riêng (PKCS8 PEM) và khóa công khai (PKIX PEM)
+func GenerateSigningKeyPEM(alg string) (string, string, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+	switch alg {
+	case SigningAlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case SigningAlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", "", fmt.Errorf("thuật toán ký không hỗ trợ: %s", alg)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", "", err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return "", "", err
+	}
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	return string(privPEM), string(pubPEM), nil
+}
+
+// ParseSigningKey đọc khóa riêng PKCS8 PEM và kiểm tra khớp thuật toán
+func ParseSigningKey(kid, alg, privatePEM string, activeFrom time.Time) (SigningKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return SigningKey{}, errors.New("khóa riêng không đúng định dạng PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	var private crypto.Signer
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if alg != SigningAlgRS256 {
+			return SigningKey{}, fmt.Errorf("khóa RSA không dùng được cho %s", alg)
+		}
+		private = k
+	case ed25519.PrivateKey:
+		if alg != SigningAlgEdDSA {
+			return SigningKey{}, fmt.Errorf("khóa Ed25519 không dùng được cho %s", alg)
+		}
+		private = k
+	default:
+		return SigningKey{}, errors.New("loại khóa không hỗ trợ")
+	}
+
+	return SigningKey{
+		Kid:        kid,
+		Alg:        alg,
+		Private:    private,
+		Public:     private.Public(),
+		ActiveFrom: activeFrom,
+	}, nil
+}
+
+// NewKeyID tạo kid ngẫu nhiên cho khóa ký mới
+func NewKeyID() string {
+	return newTokenID()
+}
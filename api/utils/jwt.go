@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -9,10 +11,26 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const (
+	// AccessTokenDuration là thời hạn của access token
+	AccessTokenDuration = 1 * time.Hour
+	// RefreshTokenDuration là thời hạn của refresh token (cũng là thời hạn phiên đăng nhập)
+	RefreshTokenDuration = 24 * time.Hour
+
+	// TwoFactorChallengeDuration là thời hạn của challenge token giữa hai bước đăng nhập
+	TwoFactorChallengeDuration = 5 * time.Minute
+
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeTwoFactor = "2fa" // challenge token: đã qua bước mật khẩu, chờ mã TOTP
+)
+
 type JwtClams struct {
-	Id     pgtype.UUID `json:"id"`
-	Email  string      `json:"email"`
-	Vaitro string      `json:"vaitro"`
+	Id        pgtype.UUID `json:"id"`
+	Email     string      `json:"email"`
+	Vaitro    string      `json:"vaitro"`
+	SessionID int32       `json:"sid,omitempty"` // id phiên trong phien_dang_nhap (0: token không gắn phiên)
+	TokenType string      `json:"typ,omitempty"` // access | refresh
 	jwt.RegisteredClaims
 }
 type TokenPair struct {
@@ -22,7 +40,12 @@ type TokenPair struct {
 type s map[string]string
 
 func GenerateToken(id pgtype.UUID, email, vaitro, secretkey string) (*TokenPair, error) {
-	accessToken, err := generateAccessToken(id, email, vaitro, secretkey)
+	return GenerateSessionToken(id, email, vaitro, 0, secretkey)
+}
+
+// GenerateSessionToken tạo cặp token gắn với phiên đăng nhập sessionID
+func GenerateSessionToken(id pgtype.UUID, email, vaitro string, sessionID int32, secretkey string) (*TokenPair, error) {
+	accessToken, err := generateAccessToken(id, email, vaitro, sessionID, secretkey)
 	if err != nil {
 		fmt.Println(map[string]string{
 			"message": "lỗi khi tạo accessToken",
@@ -30,7 +53,7 @@ func GenerateToken(id pgtype.UUID, email, vaitro, secretkey string) (*TokenPair,
 		})
 		return nil, err
 	}
-	refreshToken, err := generateRefreshToken(id, email, vaitro, secretkey)
+	refreshToken, err := generateRefreshToken(id, email, vaitro, sessionID, secretkey)
 	if err != nil {
 		fmt.Println(map[string]string{
 			"message": "Lỗi khi tạo refreshToken",
@@ -47,11 +70,8 @@ func GenerateToken(id pgtype.UUID, email, vaitro, secretkey string) (*TokenPair,
 
 func ValidateToken(tokenStr, secretkey string) (*JwtClams, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &JwtClams{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("phương thức ký không hợp lệ")
-		}
-		return []byte(secretkey), nil
-	})
+		return verificationKey(token, secretkey)
+	}, validationOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,31 +81,48 @@ func ValidateToken(tokenStr, secretkey string) (*JwtClams, error) {
 	return nil, errors.New("token không hợp lệ")
 }
 
-func generateAccessToken(id pgtype.UUID, email, vaitro, secretkey string) (string, error) {
+func generateAccessToken(id pgtype.UUID, email, vaitro string, sessionID int32, secretkey string) (string, error) {
 	jwtclams := JwtClams{
-		Id:     id,
-		Email:  email,
-		Vaitro: vaitro,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "localhost:3000/travia",
-			Subject:   id.String(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)), // thời gian hết hạn, sau thời gian này jwt không còn giấ trị
-		},
+		Id:               id,
+		Email:            email,
+		Vaitro:           vaitro,
+		SessionID:        sessionID,
+		TokenType:        TokenTypeAccess,
+		RegisteredClaims: registeredClaims(id.String(), newTokenID(), AccessTokenDuration),
 	}
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtclams)
-	return accessToken.SignedString([]byte(secretkey))
+	return signToken(jwtclams, secretkey)
 }
-func generateRefreshToken(id pgtype.UUID, email, vaitro, secretkey string) (string, error) {
+func generateRefreshToken(id pgtype.UUID, email, vaitro string, sessionID int32, secretkey string) (string, error) {
 	jwtclams := JwtClams{
-		Id:     id,
-		Email:  email,
-		Vaitro: vaitro,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "localhost:3000/travia",
-			Subject:   id.String(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-		},
+		Id:               id,
+		Email:            email,
+		Vaitro:           vaitro,
+		SessionID:        sessionID,
+		TokenType:        TokenTypeRefresh,
+		RegisteredClaims: registeredClaims(id.String(), newTokenID(), RefreshTokenDuration),
+	}
+	return signToken(jwtclams, secretkey)
+}
+
+// GenerateTwoFactorChallenge tạo challenge token ngắn hạn sau bước mật khẩu, trả về token và jti của token
+func GenerateTwoFactorChallenge(id pgtype.UUID, email, vaitro, secretkey string) (string, string, error) {
+	jti := newTokenID()
+	jwtclams := JwtClams{
+		Id:               id,
+		Email:            email,
+		Vaitro:           vaitro,
+		TokenType:        TokenTypeTwoFactor,
+		RegisteredClaims: registeredClaims(id.String(), jti, TwoFactorChallengeDuration),
+	}
+	token, err := signToken(jwtclams, secretkey)
+	return token, jti, err
+}
+
+// newTokenID tạo jti ngẫu nhiên để hai token cấp cùng thời điểm không bao giờ trùng nhau
+func newTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
 	}
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtclams)
-	return accessToken.SignedString([]byte(secretkey))
+	return hex.EncodeToString(b)
 }
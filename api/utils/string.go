@@ -42,3 +42,16 @@ func GenerateSlug(title string) string {
 
 	return slug
 }
+
+// NormalizeSearchQuery chuẩn hóa câu tìm kiếm để đếm / so khớp: chữ thường, bỏ dấu tiếng Việt (kể cả đ),
+// chỉ giữ chữ và số, gộp khoảng trắng
+func NormalizeSearchQuery(query string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, _ := transform.String(t, strings.ToLower(query))
+	normalized = strings.ReplaceAll(normalized, "đ", "d")
+
+	words := strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, " ")
+}
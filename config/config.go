@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,30 +16,40 @@ func LoadEnv() {
 }
 
 type Config struct {
-	DatabaseConfig    *DatabaseConfig
-	ServerConfig      *ServerConfig
-	RedisConfig       *RedisConfig
-	EmailConfig       *EmailConfig
-	SSLConfig         *SSLConfig
-	GoogleCloudConfig *GoogleCloudConfig
-	SupabaseConfig    *SupabaseConfig
-	StripeConfig      *StripeConfig
-	VNPayConfig       *VNPayConfig
-	OpenAIConfig      *OpenAIConfig
+	DatabaseConfig     *DatabaseConfig
+	ServerConfig       *ServerConfig
+	RedisConfig        *RedisConfig
+	EmailConfig        *EmailConfig
+	SSLConfig          *SSLConfig
+	GoogleCloudConfig  *GoogleCloudConfig
+	OAuthConfig        *OAuthConfig
+	JwtConfig          *JwtConfig
+	SupabaseConfig     *SupabaseConfig
+	StripeConfig       *StripeConfig
+	VNPayConfig        *VNPayConfig
+	MoMoConfig         *MoMoConfig
+	BankTransferConfig *BankTransferConfig
+	OpenAIConfig       *OpenAIConfig
+	PayoutConfig       *PayoutConfig
 }
 
 func NewConfig() *Config {
 	return &Config{
-		DatabaseConfig:    NewDatabaseConfig(),
-		ServerConfig:      NewServerConfig(),
-		RedisConfig:       NewRedisConfig(),
-		EmailConfig:       NewEmailConfig(),
-		SSLConfig:         NewSSLConfig(),
-		GoogleCloudConfig: NewGoogleCloudConfig(),
-		SupabaseConfig:    NewSupabaseConfig(),
-		StripeConfig:      NewStripeConfig(),
-		VNPayConfig:       NewVNPayConfig(),
-		OpenAIConfig:      NewOpenAIConfig(),
+		DatabaseConfig:     NewDatabaseConfig(),
+		ServerConfig:       NewServerConfig(),
+		RedisConfig:        NewRedisConfig(),
+		EmailConfig:        NewEmailConfig(),
+		SSLConfig:          NewSSLConfig(),
+		GoogleCloudConfig:  NewGoogleCloudConfig(),
+		OAuthConfig:        NewOAuthConfig(),
+		JwtConfig:          NewJwtConfig(),
+		SupabaseConfig:     NewSupabaseConfig(),
+		StripeConfig:       NewStripeConfig(),
+		VNPayConfig:        NewVNPayConfig(),
+		MoMoConfig:         NewMoMoConfig(),
+		BankTransferConfig: NewBankTransferConfig(),
+		OpenAIConfig:       NewOpenAIConfig(),
+		PayoutConfig:       NewPayoutConfig(),
 	}
 }
 
@@ -86,6 +97,54 @@ func NewServerConfig() *ServerConfig {
 	}
 }
 
+// JwtConfig cấu hình ký JWT. HS256 dùng API_SECRET như trước; RS256/EdDSA dùng cặp khóa lưu trong DB,
+// xoay vòng định kỳ và công bố khóa công khai tại /.well-known/jwks.json
+type JwtConfig struct {
+	Algorithm string   // HS256 | RS256 | EdDSA
+	Issuer    string   // Claim iss
+	Audience  []string // Claim aud; phần tử đầu tiên là audience của chính API này
+	// RotationInterval là thời gian dùng một khóa ký trước khi thay bằng khóa mới
+	RotationInterval time.Duration
+	// AcceptLegacyHS256 cho phép token HS256 cũ còn hạn khi vừa chuyển sang khóa bất đối xứng
+	AcceptLegacyHS256 bool
+}
+
+func NewJwtConfig() *JwtConfig {
+	algorithm := os.Getenv("JWT_ALGORITHM")
+	switch algorithm {
+	case "":
+		algorithm = "HS256"
+	case "HS256", "RS256", "EdDSA":
+	default:
+		log.Fatalf("Invalid JWT_ALGORITHM: %s", algorithm)
+	}
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = "localhost:3000/travia"
+	}
+	var audience []string
+	for _, a := range strings.Split(os.Getenv("JWT_AUDIENCE"), ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			audience = append(audience, a)
+		}
+	}
+	rotation := 30 * 24 * time.Hour
+	if v := os.Getenv("JWT_ROTATION_INTERVAL"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < time.Hour {
+			log.Fatalf("Invalid JWT_ROTATION_INTERVAL: %s", v)
+		}
+		rotation = parsed
+	}
+	return &JwtConfig{
+		Algorithm:         algorithm,
+		Issuer:            issuer,
+		Audience:          audience,
+		RotationInterval:  rotation,
+		AcceptLegacyHS256: os.Getenv("JWT_ACCEPT_LEGACY_HS256") == "true",
+	}
+}
+
 type SSLConfig struct {
 	SSLEnabled bool
 	CertFile   string
@@ -157,6 +216,33 @@ func NewGoogleCloudConfig() *GoogleCloudConfig {
 	}
 }
 
+// OAuthConfig cấu hình đăng nhập Facebook và Apple (Google dùng GoogleCloudConfig).
+// Provider chỉ được bật khi có client id
+type OAuthConfig struct {
+	FacebookClientId     string
+	FacebookClientSecret string
+	FacebookRedirectUri  string
+	AppleClientId        string // Services ID
+	AppleTeamId          string
+	AppleKeyId           string
+	ApplePrivateKey      string // Khóa .p8 (PEM, PKCS8) dùng ký client secret
+	AppleRedirectUri     string
+}
+
+func NewOAuthConfig() *OAuthConfig {
+	return &OAuthConfig{
+		FacebookClientId:     os.Getenv("FACEBOOK_CLIENT_ID"),
+		FacebookClientSecret: os.Getenv("FACEBOOK_CLIENT_SECRET"),
+		FacebookRedirectUri:  os.Getenv("FACEBOOK_REDIRECT_URI"),
+		AppleClientId:        os.Getenv("APPLE_CLIENT_ID"),
+		AppleTeamId:          os.Getenv("APPLE_TEAM_ID"),
+		AppleKeyId:           os.Getenv("APPLE_KEY_ID"),
+		// Cho phép khai báo khóa trên một dòng với \n
+		ApplePrivateKey:  strings.ReplaceAll(os.Getenv("APPLE_PRIVATE_KEY"), `\n`, "\n"),
+		AppleRedirectUri: os.Getenv("APPLE_REDIRECT_URI"),
+	}
+}
+
 type SupabaseConfig struct {
 	URL    string
 	Key    string
@@ -176,6 +262,7 @@ type StripeConfig struct {
 	PublishableKey string
 	WebhookSecret  string
 	Currency       string
+	APIBaseURL     string // Mặc định https://api.stripe.com; đổi sang stripe-mock khi test
 }
 
 func NewStripeConfig() *StripeConfig {
@@ -188,6 +275,7 @@ func NewStripeConfig() *StripeConfig {
 		PublishableKey: os.Getenv("STRIPE_PUBLISHABLE_KEY"),
 		WebhookSecret:  os.Getenv("STRIPE_WEBHOOK_SECRET"),
 		Currency:       currency,
+		APIBaseURL:     os.Getenv("STRIPE_API_BASE_URL"),
 	}
 }
 
@@ -195,6 +283,7 @@ type VNPayConfig struct {
 	TMNCode    string
 	HashSecret string
 	PaymentURL string
+	APIURL     string // API truy vấn/hoàn tiền (merchant_webapi)
 	ReturnURL  string
 	IPNURL     string
 	Command    string
@@ -221,6 +310,7 @@ func NewVNPayConfig() *VNPayConfig {
 		TMNCode:    tmnCode,
 		HashSecret: hashSecret,
 		PaymentURL: os.Getenv("VNPAY_PAYMENT_URL"),
+		APIURL:     os.Getenv("VNPAY_API_URL"),
 		ReturnURL:  returnURL,
 		IPNURL:     ipnURL,
 		Command:    "pay",
@@ -229,12 +319,93 @@ func NewVNPayConfig() *VNPayConfig {
 	}
 }
 
+type MoMoConfig struct {
+	PartnerCode string
+	AccessKey   string
+	SecretKey   string
+	Endpoint    string // Gốc API MoMo (sandbox: https://test-payment.momo.vn), có thể trỏ tới mock server
+	ReturnURL   string // redirectUrl: nơi MoMo chuyển khách hàng về sau khi thanh toán
+	IPNURL      string
+	RequestType string // captureWallet, payWithATM, payWithCC, ...
+	Lang        string
+}
+
+func NewMoMoConfig() *MoMoConfig {
+	endpoint := os.Getenv("MOMO_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://test-payment.momo.vn"
+	}
+
+	returnURL := os.Getenv("MOMO_RETURN_URL")
+	if returnURL == "" {
+		returnURL = "http://localhost:5173/payment/momo/return"
+	}
+
+	ipnURL := os.Getenv("MOMO_IPN_URL")
+	if ipnURL == "" {
+		ipnURL = "http://localhost:3000/api/payment/momo/ipn"
+	}
+
+	requestType := os.Getenv("MOMO_REQUEST_TYPE")
+	if requestType == "" {
+		requestType = "captureWallet"
+	}
+
+	return &MoMoConfig{
+		PartnerCode: strings.TrimSpace(os.Getenv("MOMO_PARTNER_CODE")),
+		AccessKey:   strings.TrimSpace(os.Getenv("MOMO_ACCESS_KEY")),
+		SecretKey:   strings.TrimSpace(os.Getenv("MOMO_SECRET_KEY")),
+		Endpoint:    strings.TrimRight(endpoint, "/"),
+		ReturnURL:   returnURL,
+		IPNURL:      ipnURL,
+		RequestType: requestType,
+		Lang:        "vi",
+	}
+}
+
+// BankTransferConfig là tài khoản nhận chuyển khoản của nền tảng (hiển thị trong mã VietQR)
+type BankTransferConfig struct {
+	BankBin       string // Mã BIN ngân hàng theo NAPAS (vd: 970436 - Vietcombank)
+	BankName      string
+	AccountNumber string
+	AccountName   string
+}
+
+func NewBankTransferConfig() *BankTransferConfig {
+	return &BankTransferConfig{
+		BankBin:       strings.TrimSpace(os.Getenv("BANK_TRANSFER_BANK_BIN")),
+		BankName:      os.Getenv("BANK_TRANSFER_BANK_NAME"),
+		AccountNumber: strings.TrimSpace(os.Getenv("BANK_TRANSFER_ACCOUNT_NUMBER")),
+		AccountName:   os.Getenv("BANK_TRANSFER_ACCOUNT_NAME"),
+	}
+}
+
 type OpenAIConfig struct {
-	APIKey string
+	APIKey            string
+	EmbeddingProvider string // openai | local; để trống: openai nếu có API key, ngược lại local
 }
 
 func NewOpenAIConfig() *OpenAIConfig {
 	return &OpenAIConfig{
-		APIKey: os.Getenv("OPENAI_API_KEY"),
+		APIKey:            os.Getenv("OPENAI_API_KEY"),
+		EmbeddingProvider: strings.ToLower(strings.TrimSpace(os.Getenv("EMBEDDING_PROVIDER"))),
+	}
+}
+
+type PayoutConfig struct {
+	CommissionPercent float64 // Hoa hồng nền tảng (%) trừ vào doanh thu của nhà cung cấp
+}
+
+func NewPayoutConfig() *PayoutConfig {
+	commission := 5.0
+	if v := os.Getenv("PLATFORM_COMMISSION_PERCENT"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed < 0 || parsed > 100 {
+			log.Fatalf("Invalid PLATFORM_COMMISSION_PERCENT: %s", v)
+		}
+		commission = parsed
+	}
+	return &PayoutConfig{
+		CommissionPercent: commission,
 	}
 }
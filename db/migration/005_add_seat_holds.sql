@@ -0,0 +1,24 @@
+-- Migration: Giữ chỗ có thời hạn (seat hold)
+-- Mỗi lần giữ chỗ là một bản ghi có chủ sở hữu, mã giữ chỗ và thời điểm hết hạn.
+-- Chỗ đã giữ được cộng vào khoi_hanh_tour.so_cho_da_dat và sẽ được trả lại
+-- khi giữ chỗ hết hạn hoặc bị hủy. CreateBooking tiêu thụ mã giữ chỗ.
+
+CREATE TABLE giu_cho (
+    id SERIAL PRIMARY KEY,
+    ma_giu_cho UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    khoi_hanh_id INT NOT NULL REFERENCES khoi_hanh_tour(id) ON DELETE CASCADE,
+    nguoi_dung_id UUID NOT NULL REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    so_nguoi_lon INT NOT NULL DEFAULT 0 CHECK (so_nguoi_lon >= 0),
+    so_tre_em INT NOT NULL DEFAULT 0 CHECK (so_tre_em >= 0),
+    trang_thai VARCHAR(20) NOT NULL DEFAULT 'dang_giu' CHECK (trang_thai IN ('dang_giu', 'da_su_dung', 'het_han', 'da_huy')),
+    het_han_luc TIMESTAMP NOT NULL,
+    dat_cho_id INT REFERENCES dat_cho(id) ON DELETE SET NULL,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (so_nguoi_lon + so_tre_em > 0)
+);
+
+-- Indexes cho bảng giu_cho
+CREATE INDEX idx_giu_cho_nguoi_dung_id ON giu_cho(nguoi_dung_id);
+CREATE INDEX idx_giu_cho_khoi_hanh_id ON giu_cho(khoi_hanh_id);
+CREATE INDEX idx_giu_cho_dang_giu_het_han ON giu_cho(het_han_luc) WHERE trang_thai = 'dang_giu';
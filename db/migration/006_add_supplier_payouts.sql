@@ -0,0 +1,41 @@
+-- Migration: Sổ chi trả cho nhà cung cấp (supplier payout ledger)
+-- Mỗi lịch khởi hành có booking đã thanh toán sinh ra một khoản chi trả cho nhà cung cấp.
+-- Số tiền thực nhận = doanh thu - phí cổng thanh toán (cong_thanh_toan.phi_giao_dich_phan_tram)
+--                               - hoa hồng nền tảng (ty_le_hoa_hong).
+-- Trạng thái đi theo enum trang_thai_chi_tra:
+--   cho_chi_tra -> san_sang (khởi hành hoàn thành) -> dang_xu_ly (admin duyệt) -> da_chi_tra / that_bai
+--   da_giu: admin giữ lại khi có tranh chấp; da_huy: khởi hành bị hủy trước khi chi trả
+
+CREATE TABLE chi_tra_nha_cung_cap (
+    id SERIAL PRIMARY KEY,
+    nha_cung_cap_id UUID NOT NULL REFERENCES nha_cung_cap(id) ON DELETE CASCADE,
+    khoi_hanh_id INT NOT NULL UNIQUE REFERENCES khoi_hanh_tour(id) ON DELETE CASCADE,
+    tai_khoan_ngan_hang_id INT REFERENCES tai_khoan_ngan_hang(id) ON DELETE SET NULL,
+
+    so_dat_cho INT NOT NULL DEFAULT 0,
+    tong_doanh_thu DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    phi_cong_thanh_toan DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    ty_le_hoa_hong DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    phi_hoa_hong DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    so_tien_thuc_nhan DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    don_vi_tien_te VARCHAR(3) DEFAULT 'VND',
+
+    trang_thai trang_thai_chi_tra NOT NULL DEFAULT 'cho_chi_tra',
+    ma_tham_chieu_chi_tra VARCHAR(255), -- Mã giao dịch chuyển khoản của ngân hàng
+    ghi_chu TEXT,
+    nguoi_duyet_id UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    ngay_duyet TIMESTAMP,
+    ngay_chi_tra TIMESTAMP,
+
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes cho bảng chi_tra_nha_cung_cap
+CREATE INDEX idx_chi_tra_nha_cung_cap_id ON chi_tra_nha_cung_cap(nha_cung_cap_id);
+CREATE INDEX idx_chi_tra_trang_thai ON chi_tra_nha_cung_cap(trang_thai);
+
+-- Mỗi nhà cung cấp chỉ có một tài khoản ngân hàng mặc định
+CREATE UNIQUE INDEX idx_tai_khoan_ngan_hang_mac_dinh
+    ON tai_khoan_ngan_hang(nha_cung_cap_id)
+    WHERE la_mac_dinh = TRUE;
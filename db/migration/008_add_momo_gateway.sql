@@ -0,0 +1,6 @@
+-- Migration: Cổng thanh toán ví MoMo
+-- lich_su_giao_dich.cong_thanh_toan_id tham chiếu cong_thanh_toan(id) nên cần có bản ghi 'momo'
+
+INSERT INTO cong_thanh_toan (id, ten_hien_thi, hoat_dong, phi_giao_dich_phan_tram)
+VALUES ('momo', 'Ví MoMo', TRUE, 2.00)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,39 @@
+-- Migration: Thanh toán chuyển khoản ngân hàng (VietQR) và đối soát sao kê
+-- Mỗi booking có một nội dung chuyển khoản duy nhất (lich_su_giao_dich.ma_giao_dich_noi_bo = noi_dung_chuyen_khoan).
+-- Admin tải lên sao kê ngân hàng (CSV), từng dòng được đối soát theo nội dung và số tiền:
+--   chua_doi_soat -> da_doi_soat (khớp, giao dịch thanh_cong)
+--                 -> sai_so_tien (khớp nội dung nhưng lệch số tiền, cần xử lý tay)
+--                 -> khong_khop (không tìm thấy giao dịch đang chờ)
+
+INSERT INTO cong_thanh_toan (id, ten_hien_thi, hoat_dong, phi_giao_dich_phan_tram)
+VALUES ('bank_transfer', 'Chuyển khoản ngân hàng', TRUE, 0)
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TYPE trang_thai_doi_soat AS ENUM (
+    'chua_doi_soat',
+    'da_doi_soat',
+    'sai_so_tien',
+    'khong_khop'
+);
+
+CREATE TABLE sao_ke_ngan_hang (
+    id SERIAL PRIMARY KEY,
+    -- Mã giao dịch của ngân hàng; nếu sao kê không có thì sinh từ ngày + số tiền + nội dung để tải lại không bị trùng
+    ma_giao_dich_ngan_hang VARCHAR(100) NOT NULL UNIQUE,
+    ngay_giao_dich TIMESTAMP,
+    so_tien DECIMAL(15, 2) NOT NULL,
+    noi_dung TEXT NOT NULL,
+    ten_tep VARCHAR(255),
+
+    trang_thai trang_thai_doi_soat NOT NULL DEFAULT 'chua_doi_soat',
+    giao_dich_id INT REFERENCES lich_su_giao_dich(id) ON DELETE SET NULL,
+    ghi_chu TEXT,
+    nguoi_tai_len_id UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    ngay_doi_soat TIMESTAMP,
+
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes cho bảng sao_ke_ngan_hang
+CREATE INDEX idx_sao_ke_ngan_hang_trang_thai ON sao_ke_ngan_hang(trang_thai);
+CREATE INDEX idx_sao_ke_ngan_hang_giao_dich_id ON sao_ke_ngan_hang(giao_dich_id);
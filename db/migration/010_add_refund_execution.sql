@@ -0,0 +1,22 @@
+-- Migration: Thực hiện hoàn tiền cho booking đã hủy
+-- Mỗi khoản hoàn tiền là một dòng lich_su_giao_dich với loai_giao_dich = 'hoan_tien',
+-- tham chiếu giao dịch thanh toán gốc (giao_dich_goc_id). Trạng thái:
+--   dang_xuly (chờ admin duyệt / đang gọi cổng thanh toán)
+--     -> da_hoan_tien (hoàn toàn bộ số đã thanh toán) / hoan_mot_phan (hoàn một phần)
+--     -> that_bai (cổng thanh toán từ chối, admin có thể thử lại)
+--     -> da_huy (admin từ chối hoàn tiền)
+
+ALTER TABLE lich_su_giao_dich
+    ADD COLUMN giao_dich_goc_id INT REFERENCES lich_su_giao_dich(id) ON DELETE SET NULL,
+    ADD COLUMN ly_do TEXT,
+    ADD COLUMN ghi_chu_xu_ly TEXT, -- Ghi chú của admin hoặc phản hồi của cổng thanh toán
+    ADD COLUMN nguoi_duyet_id UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    ADD COLUMN ngay_duyet TIMESTAMP,
+    ADD COLUMN so_lan_thu INT NOT NULL DEFAULT 0;
+
+-- Mỗi giao dịch thanh toán chỉ có một khoản hoàn tiền còn hiệu lực
+CREATE UNIQUE INDEX idx_lich_su_giao_dich_hoan_tien_goc
+    ON lich_su_giao_dich(giao_dich_goc_id)
+    WHERE loai_giao_dich = 'hoan_tien' AND trang_thai <> 'da_huy';
+
+CREATE INDEX idx_lich_su_giao_dich_loai_trang_thai ON lich_su_giao_dich(loai_giao_dich, trang_thai);
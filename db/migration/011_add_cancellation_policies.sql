@@ -0,0 +1,41 @@
+-- Migration: Chính sách hủy tour do nhà cung cấp cấu hình
+-- Thay cho các mốc hoàn tiền cố định (15/7/3/1 ngày - 100/90/70/50%) trong tinh_tien_hoan_lai.
+-- Chính sách gắn vào tour (mặc định cho mọi khởi hành) hoặc vào từng khởi hành (ưu tiên hơn, vd: mùa cao điểm).
+-- Tour/khởi hành không gắn chính sách vẫn dùng các mốc mặc định như trước.
+--   khong_hoan_tien = TRUE: không hoàn tiền trong mọi trường hợp (tour khuyến mãi)
+--   bac_chinh_sach_huy: hủy trước ít nhất so_ngay_toi_thieu ngày thì hoàn phan_tram_hoan % (lấy mốc lớn nhất thỏa mãn)
+--   phi_co_dinh: phí hủy trừ vào số tiền hoàn (không vượt quá số tiền hoàn)
+
+CREATE TABLE chinh_sach_huy (
+    id SERIAL PRIMARY KEY,
+    nha_cung_cap_id UUID NOT NULL REFERENCES nha_cung_cap(id) ON DELETE CASCADE,
+    ten VARCHAR(255) NOT NULL,
+    mo_ta TEXT,
+    khong_hoan_tien BOOLEAN NOT NULL DEFAULT FALSE,
+    phi_co_dinh DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (phi_co_dinh >= 0),
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE bac_chinh_sach_huy (
+    id SERIAL PRIMARY KEY,
+    chinh_sach_huy_id INT NOT NULL REFERENCES chinh_sach_huy(id) ON DELETE CASCADE,
+    so_ngay_toi_thieu INT NOT NULL CHECK (so_ngay_toi_thieu >= 0),
+    phan_tram_hoan DECIMAL(5, 2) NOT NULL CHECK (phan_tram_hoan >= 0 AND phan_tram_hoan <= 100),
+    UNIQUE (chinh_sach_huy_id, so_ngay_toi_thieu)
+);
+
+ALTER TABLE tour
+    ADD COLUMN chinh_sach_huy_id INT REFERENCES chinh_sach_huy(id) ON DELETE SET NULL;
+
+ALTER TABLE khoi_hanh_tour
+    ADD COLUMN chinh_sach_huy_id INT REFERENCES chinh_sach_huy(id) ON DELETE SET NULL;
+
+-- Indexes
+CREATE INDEX idx_chinh_sach_huy_nha_cung_cap_id ON chinh_sach_huy(nha_cung_cap_id);
+CREATE INDEX idx_tour_chinh_sach_huy_id ON tour(chinh_sach_huy_id);
+CREATE INDEX idx_khoi_hanh_tour_chinh_sach_huy_id ON khoi_hanh_tour(chinh_sach_huy_id);
+
+-- tinh_tien_hoan_lai trả thêm phí hủy và chính sách áp dụng nên phải tạo lại
+-- (định nghĩa mới nằm trong db/query/booking.sql)
+DROP FUNCTION IF EXISTS tinh_tien_hoan_lai(INT);
@@ -0,0 +1,10 @@
+-- Migration: Quy tắc nhóm khách theo tour (cau_hinh_nhom_tour)
+-- so_nho_nhat / so_lon_nhat được kiểm tra khi giữ chỗ và tạo booking (kiem_tra_gioi_han_nhom trong db/query/booking.sql).
+-- Độ tuổi trẻ em (tính tại ngày khởi hành) dùng để kiểm tra ngay_sinh của hành khách loại tre_em.
+-- Tour không có cấu hình nhóm: tối thiểu 1 khách, không giới hạn tối đa, trẻ em từ 2 đến 11 tuổi.
+
+ALTER TABLE cau_hinh_nhom_tour
+    ADD COLUMN tuoi_tre_em_toi_thieu INT NOT NULL DEFAULT 2 CHECK (tuoi_tre_em_toi_thieu >= 0),
+    ADD COLUMN tuoi_tre_em_toi_da INT NOT NULL DEFAULT 11,
+    ADD CONSTRAINT chk_cau_hinh_nhom_tuoi_tre_em CHECK (tuoi_tre_em_toi_da >= tuoi_tre_em_toi_thieu);
+
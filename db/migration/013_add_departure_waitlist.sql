@@ -0,0 +1,30 @@
+-- Migration: Danh sách chờ theo lịch khởi hành (waitlist)
+-- Khách đăng ký chờ với số người lớn / trẻ em khi khởi hành hết chỗ.
+-- Khi có chỗ trống (hủy booking, giữ chỗ hết hạn) hệ thống mời lần lượt theo thứ tự đăng ký:
+-- tạo giữ chỗ có thời hạn cho khách được mời, gửi thông báo (thong_bao) và email.
+--   dang_cho: đang xếp hàng
+--   da_moi:   đã được mời, giữ chỗ giu_cho_id đang chờ khách tạo booking
+--   da_dat:   khách đã dùng giữ chỗ để tạo booking
+--   het_han:  lời mời hết hạn mà khách không đặt
+--   da_huy:   khách rời danh sách chờ hoặc hủy giữ chỗ được mời
+
+CREATE TABLE danh_sach_cho (
+    id SERIAL PRIMARY KEY,
+    khoi_hanh_id INT NOT NULL REFERENCES khoi_hanh_tour(id) ON DELETE CASCADE,
+    nguoi_dung_id UUID NOT NULL REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    so_nguoi_lon INT NOT NULL DEFAULT 0 CHECK (so_nguoi_lon >= 0),
+    so_tre_em INT NOT NULL DEFAULT 0 CHECK (so_tre_em >= 0),
+    trang_thai VARCHAR(20) NOT NULL DEFAULT 'dang_cho' CHECK (trang_thai IN ('dang_cho', 'da_moi', 'da_dat', 'het_han', 'da_huy')),
+    giu_cho_id INT REFERENCES giu_cho(id) ON DELETE SET NULL,
+    moi_luc TIMESTAMP,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (so_nguoi_lon + so_tre_em > 0)
+);
+
+-- Mỗi khách chỉ có một lượt chờ đang hiệu lực cho mỗi khởi hành
+CREATE UNIQUE INDEX uq_danh_sach_cho_dang_hieu_luc ON danh_sach_cho(khoi_hanh_id, nguoi_dung_id)
+    WHERE trang_thai IN ('dang_cho', 'da_moi');
+CREATE INDEX idx_danh_sach_cho_khoi_hanh_dang_cho ON danh_sach_cho(khoi_hanh_id, ngay_tao) WHERE trang_thai = 'dang_cho';
+CREATE INDEX idx_danh_sach_cho_nguoi_dung_id ON danh_sach_cho(nguoi_dung_id);
+CREATE INDEX idx_danh_sach_cho_giu_cho_id ON danh_sach_cho(giu_cho_id);
@@ -0,0 +1,10 @@
+-- Migration: Phiên đăng nhập phía server
+-- Mỗi lần đăng nhập tạo một phiên trong phien_dang_nhap; access/refresh token mang id phiên (sid).
+-- ma_token_truy_cap / ma_token_lam_moi lưu SHA-256 của token hiện hành (không lưu token gốc).
+-- Refresh token được xoay vòng mỗi lần /auth/refresh; dùng lại refresh token cũ sẽ thu hồi cả phiên.
+
+ALTER TABLE phien_dang_nhap
+    ADD COLUMN dia_chi_ip VARCHAR(45),
+    ADD COLUMN lan_lam_moi_cuoi TIMESTAMP,
+    ADD COLUMN thu_hoi_luc TIMESTAMP,
+    ADD COLUMN ly_do_thu_hoi VARCHAR(50);
@@ -0,0 +1,37 @@
+-- Migration: Xác thực hai bước (TOTP) cho quản trị viên và nhà cung cấp
+-- bi_mat_ma_hoa: khóa TOTP (base32) đã mã hóa AES-GCM, chỉ giải mã được ở server.
+-- buoc_thoi_gian_cuoi: bước thời gian (30s) của mã TOTP dùng gần nhất, chống dùng lại cùng một mã.
+-- Mã khôi phục chỉ lưu SHA-256, mỗi mã dùng được một lần.
+-- chinh_sach_2fa: admin bật bắt buộc 2FA theo vai trò; tài khoản chưa bật sẽ phải thiết lập ngay khi đăng nhập.
+
+CREATE TABLE xac_thuc_hai_buoc (
+    nguoi_dung_id UUID PRIMARY KEY REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    bi_mat_ma_hoa TEXT NOT NULL,
+    da_kich_hoat BOOLEAN NOT NULL DEFAULT FALSE,
+    buoc_thoi_gian_cuoi BIGINT,
+    ngay_kich_hoat TIMESTAMP,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE ma_khoi_phuc_2fa (
+    id SERIAL PRIMARY KEY,
+    nguoi_dung_id UUID NOT NULL REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    ma_bam VARCHAR(64) NOT NULL,
+    da_dung_luc TIMESTAMP,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE chinh_sach_2fa (
+    vai_tro vai_tro_nguoi_dung PRIMARY KEY,
+    bat_buoc BOOLEAN NOT NULL DEFAULT FALSE,
+    nguoi_cap_nhat UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO chinh_sach_2fa (vai_tro, bat_buoc) VALUES
+    ('quan_tri', FALSE),
+    ('nha_cung_cap', FALSE);
+
+-- Indexes
+CREATE INDEX idx_ma_khoi_phuc_2fa_nguoi_dung_id ON ma_khoi_phuc_2fa(nguoi_dung_id) WHERE da_dung_luc IS NULL;
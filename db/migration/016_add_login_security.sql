@@ -0,0 +1,24 @@
+-- Migration: Lịch sử đăng nhập và phát hiện đăng nhập bất thường
+-- Mỗi lần đăng nhập (thành công hoặc thất bại) lưu IP, thiết bị (user agent) và quốc gia tra từ IP.
+-- dang_ngo = TRUE khi đăng nhập thành công từ quốc gia chưa từng xuất hiện trong lịch sử của tài khoản.
+-- Số lần đăng nhập sai theo tài khoản và thời gian khóa tạm được giữ trong Redis (login:fail:*, login:lock:*).
+
+CREATE TABLE lich_su_dang_nhap (
+    id SERIAL PRIMARY KEY,
+    nguoi_dung_id UUID REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    dia_chi_ip VARCHAR(45),
+    thiet_bi TEXT,
+    quoc_gia VARCHAR(100),
+    ma_quoc_gia VARCHAR(2),
+    thanh_pho VARCHAR(100),
+    thanh_cong BOOLEAN NOT NULL,
+    ly_do_that_bai VARCHAR(50)
+        CHECK (ly_do_that_bai IN ('sai_mat_khau', 'khong_ton_tai', 'dang_bi_khoa')),
+    dang_ngo BOOLEAN NOT NULL DEFAULT FALSE,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_lich_su_dang_nhap_nguoi_dung ON lich_su_dang_nhap(nguoi_dung_id, ngay_tao DESC);
+CREATE INDEX idx_lich_su_dang_nhap_quoc_gia ON lich_su_dang_nhap(nguoi_dung_id, ma_quoc_gia) WHERE thanh_cong;
+CREATE INDEX idx_lich_su_dang_nhap_email ON lich_su_dang_nhap(email, ngay_tao DESC);
@@ -0,0 +1,84 @@
+-- Migration: Phân quyền chi tiết theo quyền (permission) gom nhóm thành vai trò
+-- quyen: danh mục quyền mà API kiểm tra qua middleware.RequirePermission.
+-- vai_tro: vai trò cấu hình được trong DB. Ba vai trò hệ thống trùng tên enum vai_tro_nguoi_dung
+-- (quan_tri, nha_cung_cap, khach_hang) tự áp dụng cho người dùng có vai trò tương ứng và không xóa được.
+-- nguoi_dung_vai_tro: gán thêm vai trò cho người dùng (vd: nhân viên hỗ trợ, kế toán).
+-- Quyền hiệu lực của người dùng = quyền của vai trò hệ thống theo enum + quyền của các vai trò được gán.
+
+CREATE TABLE quyen (
+    ma VARCHAR(100) PRIMARY KEY,
+    nhom VARCHAR(50) NOT NULL,
+    mo_ta TEXT NOT NULL
+);
+
+CREATE TABLE vai_tro (
+    ma VARCHAR(50) PRIMARY KEY CHECK (ma ~ '^[a-z][a-z0-9_]*$'),
+    ten VARCHAR(100) NOT NULL,
+    mo_ta TEXT,
+    he_thong BOOLEAN NOT NULL DEFAULT FALSE,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE vai_tro_quyen (
+    vai_tro_ma VARCHAR(50) NOT NULL REFERENCES vai_tro(ma) ON DELETE CASCADE,
+    quyen_ma VARCHAR(100) NOT NULL REFERENCES quyen(ma) ON DELETE CASCADE,
+    PRIMARY KEY (vai_tro_ma, quyen_ma)
+);
+
+CREATE TABLE nguoi_dung_vai_tro (
+    nguoi_dung_id UUID NOT NULL REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    vai_tro_ma VARCHAR(50) NOT NULL REFERENCES vai_tro(ma) ON DELETE CASCADE,
+    nguoi_gan UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (nguoi_dung_id, vai_tro_ma)
+);
+
+CREATE INDEX idx_nguoi_dung_vai_tro_vai_tro ON nguoi_dung_vai_tro(vai_tro_ma);
+
+INSERT INTO quyen (ma, nhom, mo_ta) VALUES
+    ('dashboard.view', 'quan_tri', 'Xem tổng quan, thống kê tour và booking trên dashboard quản trị'),
+    ('revenue.view', 'quan_tri', 'Xem doanh thu, giao dịch và xếp hạng doanh thu nhà cung cấp'),
+    ('supplier.manage', 'quan_tri', 'Tạo, duyệt, từ chối, xóa và khôi phục nhà cung cấp'),
+    ('payout.manage', 'quan_tri', 'Xem và cập nhật sổ chi trả nhà cung cấp'),
+    ('bank_statement.manage', 'quan_tri', 'Tải sao kê và đối soát chuyển khoản ngân hàng'),
+    ('customer.view', 'quan_tri', 'Xem thống kê khách hàng và lịch sử đăng nhập'),
+    ('user.security', 'quan_tri', 'Thu hồi phiên, gỡ khóa đăng nhập, cấu hình chính sách 2FA'),
+    ('booking.view', 'quan_tri', 'Xem toàn bộ booking và thống kê booking'),
+    ('refund.view', 'quan_tri', 'Xem yêu cầu hoàn tiền và giao dịch hoàn tiền'),
+    ('refund.manage', 'quan_tri', 'Duyệt, thử lại và từ chối hoàn tiền'),
+    ('contact.manage', 'quan_tri', 'Xem và trả lời liên hệ của khách hàng'),
+    ('destination.manage', 'quan_tri', 'Cập nhật thông tin điểm đến'),
+    ('blog.manage', 'quan_tri', 'Viết, sửa, xóa bài blog và dùng AI hỗ trợ viết bài'),
+    ('role.manage', 'quan_tri', 'Quản lý vai trò, quyền và gán vai trò cho người dùng'),
+    ('supplier.profile.view', 'nha_cung_cap', 'Xem thông tin nhà cung cấp và dữ liệu tham chiếu'),
+    ('supplier.dashboard.view', 'nha_cung_cap', 'Xem dashboard, đánh giá và lịch khởi hành sắp tới của nhà cung cấp'),
+    ('supplier.revenue.view', 'nha_cung_cap', 'Xem doanh thu, giao dịch, chi trả và hoàn tiền của nhà cung cấp'),
+    ('supplier.booking.view', 'nha_cung_cap', 'Xem booking của nhà cung cấp'),
+    ('supplier.tour.manage', 'nha_cung_cap', 'Quản lý tour, lịch khởi hành, lịch trình, hình ảnh và chính sách hủy'),
+    ('supplier.bank_account.manage', 'nha_cung_cap', 'Quản lý tài khoản ngân hàng nhận chi trả');
+
+INSERT INTO vai_tro (ma, ten, mo_ta, he_thong) VALUES
+    ('quan_tri', 'Quản trị viên', 'Vai trò hệ thống: toàn quyền quản trị', TRUE),
+    ('nha_cung_cap', 'Nhà cung cấp', 'Vai trò hệ thống: chủ tài khoản nhà cung cấp', TRUE),
+    ('khach_hang', 'Khách hàng', 'Vai trò hệ thống: khách đặt tour', TRUE),
+    ('ho_tro', 'Nhân viên hỗ trợ', 'Trả lời liên hệ, tra cứu booking và khách hàng; không xem doanh thu', FALSE),
+    ('ke_toan', 'Kế toán', 'Doanh thu, hoàn tiền, chi trả và đối soát; không quản lý tour', FALSE);
+
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma)
+SELECT 'quan_tri', ma FROM quyen WHERE nhom = 'quan_tri';
+
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma)
+SELECT 'nha_cung_cap', ma FROM quyen WHERE nhom = 'nha_cung_cap';
+
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma) VALUES
+    ('ho_tro', 'contact.manage'),
+    ('ho_tro', 'booking.view'),
+    ('ho_tro', 'customer.view'),
+    ('ke_toan', 'dashboard.view'),
+    ('ke_toan', 'revenue.view'),
+    ('ke_toan', 'booking.view'),
+    ('ke_toan', 'refund.view'),
+    ('ke_toan', 'refund.manage'),
+    ('ke_toan', 'payout.manage'),
+    ('ke_toan', 'bank_statement.manage');
@@ -0,0 +1,37 @@
+-- Migration: Tài khoản nhân viên của nhà cung cấp
+-- Chủ sở hữu nhà cung cấp là người dùng có id = nha_cung_cap.id (không nằm trong bảng này).
+-- nhan_vien_nha_cung_cap: nhân viên được chủ sở hữu mời, mỗi người dùng thuộc tối đa một nhà cung cấp.
+-- quyen: các quyền nhóm nha_cung_cap được cấp cho nhân viên. Khi người dùng là nhân viên,
+-- quyền hiệu lực lấy từ cột này thay cho quyền mặc định của vai trò hệ thống nha_cung_cap.
+
+CREATE TYPE trang_thai_nhan_vien_ncc AS ENUM (
+    'cho_kich_hoat',
+    'hoat_dong',
+    'vo_hieu'
+);
+
+CREATE TABLE nhan_vien_nha_cung_cap (
+    id SERIAL PRIMARY KEY,
+    nha_cung_cap_id UUID NOT NULL REFERENCES nha_cung_cap(id) ON DELETE CASCADE,
+    nguoi_dung_id UUID NOT NULL UNIQUE REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    quyen TEXT[] NOT NULL DEFAULT '{}',
+    trang_thai trang_thai_nhan_vien_ncc NOT NULL DEFAULT 'cho_kich_hoat',
+    ma_loi_moi_bam VARCHAR(64),           -- SHA-256 của mã mời, xóa khi đã kích hoạt
+    han_loi_moi TIMESTAMP,
+    nguoi_moi UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    ngay_tham_gia TIMESTAMP,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_nhan_vien_ncc_nha_cung_cap ON nhan_vien_nha_cung_cap(nha_cung_cap_id);
+CREATE UNIQUE INDEX idx_nhan_vien_ncc_ma_loi_moi ON nhan_vien_nha_cung_cap(ma_loi_moi_bam)
+    WHERE ma_loi_moi_bam IS NOT NULL;
+
+INSERT INTO quyen (ma, nhom, mo_ta) VALUES
+    ('supplier.review.reply', 'nha_cung_cap', 'Trả lời đánh giá tour của nhà cung cấp'),
+    ('supplier.staff.manage', 'nha_cung_cap', 'Mời, phân quyền và vô hiệu hóa nhân viên nhà cung cấp');
+
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma) VALUES
+    ('nha_cung_cap', 'supplier.review.reply'),
+    ('nha_cung_cap', 'supplier.staff.manage');
@@ -0,0 +1,48 @@
+-- Migration: Nhật ký kiểm toán các thao tác đặc quyền (duyệt/từ chối/xóa nhà cung cấp, đổi trạng thái tour,
+-- hủy lịch khởi hành, sửa người dùng, xóa blog, đổi trạng thái liên hệ...).
+-- Bảng chỉ cho phép INSERT: trigger chặn UPDATE/DELETE/TRUNCATE để giữ nguyên bằng chứng khi xử lý tranh chấp.
+-- nguoi_thuc_hien không khóa ngoại để bản ghi còn nguyên khi tài khoản bị xóa; email được lưu kèm tại thời điểm ghi.
+
+CREATE TABLE nhat_ky_kiem_toan (
+    id BIGSERIAL PRIMARY KEY,
+    nguoi_thuc_hien UUID,
+    email_nguoi_thuc_hien VARCHAR(255),
+    vai_tro VARCHAR(50),
+    hanh_dong VARCHAR(100) NOT NULL,      -- vd: supplier.approve, tour.update_status
+    loai_doi_tuong VARCHAR(50) NOT NULL,  -- vd: nha_cung_cap, tour, khoi_hanh_tour
+    ma_doi_tuong VARCHAR(100) NOT NULL,
+    du_lieu_truoc JSONB,
+    du_lieu_sau JSONB,
+    thay_doi JSONB,                       -- {"truong": {"truoc": ..., "sau": ...}}
+    dia_chi_ip VARCHAR(45),
+    ma_yeu_cau VARCHAR(64),               -- X-Request-ID của request thực hiện thao tác
+    ngay_tao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_nhat_ky_kiem_toan_ngay_tao ON nhat_ky_kiem_toan(ngay_tao DESC);
+CREATE INDEX idx_nhat_ky_kiem_toan_nguoi_thuc_hien ON nhat_ky_kiem_toan(nguoi_thuc_hien, ngay_tao DESC);
+CREATE INDEX idx_nhat_ky_kiem_toan_doi_tuong ON nhat_ky_kiem_toan(loai_doi_tuong, ma_doi_tuong, ngay_tao DESC);
+CREATE INDEX idx_nhat_ky_kiem_toan_hanh_dong ON nhat_ky_kiem_toan(hanh_dong, ngay_tao DESC);
+
+CREATE OR REPLACE FUNCTION chan_sua_nhat_ky_kiem_toan()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'nhat_ky_kiem_toan chỉ cho phép thêm mới (append-only)';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_chan_sua_nhat_ky_kiem_toan
+BEFORE UPDATE OR DELETE ON nhat_ky_kiem_toan
+FOR EACH ROW
+EXECUTE FUNCTION chan_sua_nhat_ky_kiem_toan();
+
+CREATE TRIGGER trigger_chan_truncate_nhat_ky_kiem_toan
+BEFORE TRUNCATE ON nhat_ky_kiem_toan
+FOR EACH STATEMENT
+EXECUTE FUNCTION chan_sua_nhat_ky_kiem_toan();
+
+INSERT INTO quyen (ma, nhom, mo_ta) VALUES
+    ('audit.view', 'quan_tri', 'Xem và xuất nhật ký kiểm toán thao tác đặc quyền');
+
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma) VALUES
+    ('quan_tri', 'audit.view');
@@ -0,0 +1,25 @@
+-- Migration: Yêu cầu xóa tài khoản có thời gian ân hạn (quyền xóa dữ liệu cá nhân theo Nghị định 13/2023/NĐ-CP)
+-- Khách hàng gửi yêu cầu xóa, tài khoản chỉ bị xóa sau han_xoa; trong thời gian này khách có thể hủy yêu cầu.
+-- Khi đến hạn, tiến trình nền:
+--   - Ẩn danh dữ liệu phải lưu cho kế toán: dat_cho giữ nguyên nhưng gắn với bản ghi nguoi_dung đã ẩn danh,
+--     hanh_khach bị xóa thông tin định danh, lich_su_giao_dich bỏ liên kết nguoi_dung_id.
+--   - Xóa hẳn phần còn lại (đánh giá, yêu thích, lịch sử chat, lịch sử xem, sở thích, phiên đăng nhập, thông báo...).
+-- nhat_ky_kiem_toan được giữ nguyên (chỉ thêm mới) làm bằng chứng pháp lý.
+--   cho_xoa: đang trong thời gian ân hạn
+--   da_huy:  khách đã hủy yêu cầu
+--   da_xoa:  đã ẩn danh/xóa dữ liệu
+
+CREATE TABLE yeu_cau_xoa_tai_khoan (
+    id SERIAL PRIMARY KEY,
+    nguoi_dung_id UUID NOT NULL REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    trang_thai VARCHAR(20) NOT NULL DEFAULT 'cho_xoa' CHECK (trang_thai IN ('cho_xoa', 'da_huy', 'da_xoa')),
+    ly_do TEXT,
+    han_xoa TIMESTAMP NOT NULL,
+    ngay_huy TIMESTAMP,
+    ngay_xoa TIMESTAMP,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Mỗi tài khoản chỉ có một yêu cầu đang chờ xóa
+CREATE UNIQUE INDEX idx_yeu_cau_xoa_tai_khoan_cho_xoa ON yeu_cau_xoa_tai_khoan(nguoi_dung_id) WHERE trang_thai = 'cho_xoa';
+CREATE INDEX idx_yeu_cau_xoa_tai_khoan_han_xoa ON yeu_cau_xoa_tai_khoan(han_xoa) WHERE trang_thai = 'cho_xoa';
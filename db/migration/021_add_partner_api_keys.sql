@@ -0,0 +1,56 @@
+-- Migration: Đối tác (đại lý, đơn vị bán lại) truy cập API bằng khóa API
+-- Mỗi đối tác có một tài khoản dịch vụ (nguoi_dung_id) đứng tên các booking tạo qua API,
+-- nhờ đó các API đặt chỗ hiện có dùng lại được mà không cần JWT.
+-- Khóa API chỉ lưu SHA-256 (ma_bam) và vài ký tự đầu (tien_to) để nhận diện; khóa gốc chỉ hiển thị một lần khi cấp.
+-- pham_vi giới hạn nhóm API được gọi, gioi_han_moi_phut là số request tối đa mỗi phút của từng khóa.
+-- su_dung_khoa_api đếm số lần gọi theo ngày; dat_cho.doi_tac_id dùng để tính hoa hồng đối tác.
+
+CREATE TABLE doi_tac (
+    id SERIAL PRIMARY KEY,
+    ten VARCHAR(255) NOT NULL,
+    email_lien_he VARCHAR(255) NOT NULL,
+    so_dien_thoai VARCHAR(50),
+    ti_le_hoa_hong DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (ti_le_hoa_hong BETWEEN 0 AND 100),
+    nguoi_dung_id UUID NOT NULL UNIQUE REFERENCES nguoi_dung(id),
+    dang_hoat_dong BOOLEAN NOT NULL DEFAULT TRUE,
+    nguoi_tao UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ngay_cap_nhat TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE khoa_api (
+    id SERIAL PRIMARY KEY,
+    doi_tac_id INT NOT NULL REFERENCES doi_tac(id) ON DELETE CASCADE,
+    ten VARCHAR(100) NOT NULL,
+    tien_to VARCHAR(16) NOT NULL,
+    ma_bam VARCHAR(64) NOT NULL UNIQUE,
+    pham_vi TEXT[] NOT NULL DEFAULT '{}',
+    gioi_han_moi_phut INT NOT NULL DEFAULT 60 CHECK (gioi_han_moi_phut > 0),
+    so_lan_goi BIGINT NOT NULL DEFAULT 0,
+    lan_dung_cuoi TIMESTAMP,
+    het_han TIMESTAMP,
+    da_thu_hoi_luc TIMESTAMP,
+    nguoi_tao UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_khoa_api_doi_tac ON khoa_api(doi_tac_id);
+
+CREATE TABLE su_dung_khoa_api (
+    khoa_api_id INT NOT NULL REFERENCES khoa_api(id) ON DELETE CASCADE,
+    ngay DATE NOT NULL,
+    so_lan_goi BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (khoa_api_id, ngay)
+);
+
+ALTER TABLE dat_cho
+    ADD COLUMN doi_tac_id INT REFERENCES doi_tac(id) ON DELETE SET NULL,
+    ADD COLUMN khoa_api_id INT REFERENCES khoa_api(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_dat_cho_doi_tac ON dat_cho(doi_tac_id, ngay_dat) WHERE doi_tac_id IS NOT NULL;
+
+INSERT INTO quyen (ma, nhom, mo_ta) VALUES
+    ('partner.manage', 'quan_tri', 'Quản lý đối tác, cấp/thu hồi khóa API và xem hoa hồng đối tác');
+
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma) VALUES
+    ('quan_tri', 'partner.manage');
@@ -0,0 +1,26 @@
+-- Migration: Tài khoản đăng nhập mạng xã hội (Google, Facebook, Apple) liên kết với nguoi_dung
+-- schema.sql chỉ khai báo index cho tai_khoan_oauth mà chưa tạo bảng; bảng được tạo tại đây.
+--   nha_cung_cap:    tên provider của goth ('google', 'facebook', 'apple')
+--   nha_cung_cap_id: mã định danh người dùng phía provider (sub / user id)
+-- Mỗi tài khoản provider chỉ gắn với một người dùng, mỗi người dùng có tối đa một tài khoản cho mỗi provider.
+-- nguoi_dung.co_mat_khau = FALSE với tài khoản tạo từ đăng nhập mạng xã hội (mật khẩu ngẫu nhiên, người dùng không biết);
+-- tài khoản như vậy không được gỡ liên kết cuối cùng cho đến khi đặt mật khẩu qua quên mật khẩu.
+
+CREATE TABLE tai_khoan_oauth (
+    id SERIAL PRIMARY KEY,
+    nguoi_dung_id UUID NOT NULL REFERENCES nguoi_dung(id) ON DELETE CASCADE,
+    nha_cung_cap VARCHAR(20) NOT NULL CHECK (nha_cung_cap IN ('google', 'facebook', 'apple')),
+    nha_cung_cap_id VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    ten_hien_thi VARCHAR(255),
+    anh_dai_dien TEXT,
+    lan_dang_nhap_cuoi TIMESTAMP,
+    ngay_tao TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (nha_cung_cap, nha_cung_cap_id),
+    UNIQUE (nguoi_dung_id, nha_cung_cap)
+);
+
+CREATE INDEX idx_tai_khoan_oauth_nguoi_dung_id ON tai_khoan_oauth(nguoi_dung_id);
+CREATE INDEX idx_tai_khoan_oauth_nha_cung_cap_id ON tai_khoan_oauth(nha_cung_cap_id);
+
+ALTER TABLE nguoi_dung ADD COLUMN co_mat_khau BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,21 @@
+-- Khóa ký JWT bất đối xứng (RS256 / EdDSA) có xoay vòng
+-- Khóa mới được công bố trong JWKS trước thời điểm kích hoạt để các dịch vụ xác thực kịp tải về;
+-- khóa cũ ngừng ký khi khóa mới kích hoạt nhưng vẫn nằm trong JWKS tới khi mọi token nó ký đã hết hạn
+CREATE TABLE IF NOT EXISTS khoa_ky_jwt (
+    kid VARCHAR(64) PRIMARY KEY,
+    thuat_toan VARCHAR(10) NOT NULL CHECK (thuat_toan IN ('RS256', 'EdDSA')),
+    khoa_rieng_ma_hoa TEXT NOT NULL, -- PKCS8 PEM, mã hóa AES-GCM bằng API_SECRET
+    khoa_cong_khai TEXT NOT NULL,    -- PKIX PEM
+    kich_hoat_luc TIMESTAMP NOT NULL,
+    ngung_ky_luc TIMESTAMP,
+    het_han_luc TIMESTAMP,
+    ngay_tao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_khoa_ky_jwt_het_han ON khoa_ky_jwt(het_han_luc);
+
+INSERT INTO quyen (ma, nhom, mo_ta) VALUES
+    ('jwt_key.manage', 'quan_tri', 'Xem và xoay vòng khóa ký JWT');
+
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma) VALUES
+    ('quan_tri', 'jwt_key.manage');
@@ -0,0 +1,3 @@
+-- Tìm tour / điểm đến theo vị trí: lọc trước theo khung tọa độ (vi_do, kinh_do) rồi mới tính khoảng cách haversine
+CREATE INDEX IF NOT EXISTS idx_diem_den_toa_do ON diem_den(vi_do, kinh_do)
+    WHERE vi_do IS NOT NULL AND kinh_do IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Đồng bộ tour_embeddings với nội dung tour: lưu mô hình đã tạo vector và hash của văn bản nguồn,
+-- embedding được tạo lại khi nội dung (tiêu đề, mô tả, điểm đến, lịch trình, hoạt động) thay đổi hoặc khi đổi mô hình
+ALTER TABLE tour_embeddings
+    ADD COLUMN IF NOT EXISTS mo_hinh VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS noi_dung_hash CHAR(32);
+
+CREATE INDEX IF NOT EXISTS idx_tour_embeddings_mo_hinh ON tour_embeddings(mo_hinh);
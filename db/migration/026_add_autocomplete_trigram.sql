@@ -0,0 +1,15 @@
+-- Gợi ý tìm kiếm (autocomplete) chịu lỗi chính tả: so khớp trigram trên văn bản đã bỏ dấu
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- khong_dau chuẩn hóa văn bản để so khớp: chữ thường, bỏ dấu tiếng Việt (kể cả đ -> d).
+-- unaccent() chỉ là STABLE nên cần hàm bọc IMMUTABLE (chỉ định rõ từ điển) để dùng trong index
+CREATE OR REPLACE FUNCTION khong_dau(txt TEXT)
+RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+AS $$
+    SELECT lower(public.unaccent('public.unaccent'::regdictionary, txt));
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_tour_tieu_de_trgm ON tour USING gin (khong_dau(tieu_de) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_diem_den_ten_trgm ON diem_den USING gin (khong_dau(ten) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_danh_muc_tour_ten_trgm ON danh_muc_tour USING gin (khong_dau(ten) gin_trgm_ops);
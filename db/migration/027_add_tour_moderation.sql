@@ -0,0 +1,114 @@
+-- Migration: Kiểm duyệt tour trước khi công bố
+-- Nhà cung cấp không tự công bố tour: bản nháp được gửi duyệt (tour.trang_thai = 'cho_duyet'),
+-- quản trị viên duyệt (-> 'cong_bo') hoặc từ chối kèm nhận xét (-> 'tu_choi', sửa rồi gửi lại).
+-- Chỉnh sửa tour đang công bố tạo một phiên bản chờ duyệt; nội dung đang hiển thị giữ nguyên đến khi phiên bản được duyệt.
+-- phien_ban_tour lưu ảnh chụp nội dung (tour, lich_trinh, hoat_dong_trong_ngay, anh_tour, điểm đến, cấu hình nhóm)
+-- của mỗi lần gửi duyệt; các phiên bản da_duyet là lịch sử nội dung đã công bố.
+
+ALTER TABLE tour DROP CONSTRAINT IF EXISTS tour_trang_thai_check;
+ALTER TABLE tour ADD CONSTRAINT tour_trang_thai_check
+    CHECK (trang_thai IN ('nhap', 'cho_duyet', 'cong_bo', 'tu_choi', 'luu_tru'));
+
+-- tour_noi_dung chụp nội dung hiện tại của tour theo đúng định dạng body của POST /tour (models.CreateTourRequest),
+-- để phiên bản được duyệt áp dụng lại bằng cùng luồng cập nhật tour đầy đủ
+CREATE OR REPLACE FUNCTION tour_noi_dung(p_tour_id INT)
+RETURNS JSONB
+LANGUAGE sql STABLE
+AS $$
+    SELECT jsonb_build_object(
+        'tieu_de', t.tieu_de,
+        'mo_ta', COALESCE(t.mo_ta, ''),
+        'danh_muc_id', COALESCE(t.danh_muc_id, 0),
+        'so_ngay', t.so_ngay,
+        'so_dem', t.so_dem,
+        'gia_nguoi_lon', t.gia_nguoi_lon,
+        'gia_tre_em', t.gia_tre_em,
+        'don_vi_tien_te', COALESCE(t.don_vi_tien_te, 'VND'),
+        'noi_bat', COALESCE(t.noi_bat, FALSE),
+        'hinh_anh_tours', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object(
+                'link', a.duong_dan,
+                'mo_ta_alt', COALESCE(a.mo_ta, ''),
+                'la_anh_chinh', COALESCE(a.la_anh_chinh, FALSE),
+                'thu_tu_hien_thi', COALESCE(a.thu_tu_hien_thi, 0)
+            ) ORDER BY a.thu_tu_hien_thi, a.id)
+            FROM anh_tour a WHERE a.tour_id = t.id
+        ), '[]'::JSONB),
+        'dia_diem_tours', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object(
+                'diem_den_id', tdd.diem_den_id,
+                'thu_tu_tham_quan', COALESCE(tdd.thu_tu_tham_quan, 0)
+            ) ORDER BY tdd.thu_tu_tham_quan, tdd.diem_den_id)
+            FROM tour_diem_den tdd WHERE tdd.tour_id = t.id
+        ), '[]'::JSONB),
+        'lich_trinh_tours', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object(
+                'ngay_thu', lt.ngay_thu,
+                'tieu_de', lt.tieu_de,
+                'mo_ta', COALESCE(lt.mo_ta, ''),
+                'gio_bat_dau', COALESCE(to_char(lt.gio_bat_dau, 'HH24:MI:SS'), ''),
+                'gio_ket_thuc', COALESCE(to_char(lt.gio_ket_thuc, 'HH24:MI:SS'), ''),
+                'dia_diem', COALESCE(lt.dia_diem, ''),
+                'thong_tin_luu_tru', COALESCE(lt.thong_tin_luu_tru, ''),
+                'hoat_dong_lich_trinh_tours', COALESCE((
+                    SELECT jsonb_agg(jsonb_build_object(
+                        'ten', hd.ten,
+                        'gio_bat_dau', COALESCE(to_char(hd.gio_bat_dau, 'HH24:MI:SS'), ''),
+                        'gio_ket_thuc', COALESCE(to_char(hd.gio_ket_thuc, 'HH24:MI:SS'), ''),
+                        'mo_ta', COALESCE(hd.mo_ta, ''),
+                        'thu_tu', COALESCE(hd.thu_tu, 0)
+                    ) ORDER BY hd.thu_tu, hd.id)
+                    FROM hoat_dong_trong_ngay hd WHERE hd.lich_trinh_id = lt.id
+                ), '[]'::JSONB)
+            ) ORDER BY lt.ngay_thu)
+            FROM lich_trinh lt WHERE lt.tour_id = t.id
+        ), '[]'::JSONB),
+        'cau_hinh_nhom_tours', (
+            SELECT jsonb_build_object(
+                'so_nho_nhat', ch.so_nho_nhat,
+                'so_lon_nhat', ch.so_lon_nhat,
+                'tuoi_tre_em_toi_thieu', ch.tuoi_tre_em_toi_thieu,
+                'tuoi_tre_em_toi_da', ch.tuoi_tre_em_toi_da
+            )
+            FROM cau_hinh_nhom_tour ch WHERE ch.tour_id = t.id
+            ORDER BY ch.id
+            LIMIT 1
+        )
+    )
+    FROM tour t
+    WHERE t.id = p_tour_id;
+$$;
+
+CREATE TABLE IF NOT EXISTS phien_ban_tour (
+    id SERIAL PRIMARY KEY,
+    tour_id INT NOT NULL REFERENCES tour(id) ON DELETE CASCADE,
+    so_phien_ban INT NOT NULL,
+    -- gui_duyet: bản nháp gửi duyệt lần đầu; chinh_sua: chỉnh sửa tour đang công bố
+    loai VARCHAR(20) NOT NULL CHECK (loai IN ('gui_duyet', 'chinh_sua')),
+    trang_thai VARCHAR(20) NOT NULL DEFAULT 'cho_duyet' CHECK (trang_thai IN ('cho_duyet', 'da_duyet', 'tu_choi', 'da_huy')),
+    noi_dung JSONB NOT NULL,
+    ghi_chu TEXT, -- ghi chú của nhà cung cấp khi gửi
+    nguoi_gui_id UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    nguoi_duyet_id UUID REFERENCES nguoi_dung(id) ON DELETE SET NULL,
+    nhan_xet_duyet TEXT,
+    ngay_tao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ngay_duyet TIMESTAMP,
+    UNIQUE (tour_id, so_phien_ban)
+);
+
+-- Mỗi tour có tối đa một phiên bản chờ duyệt; gửi phiên bản mới sẽ hủy phiên bản đang chờ
+CREATE UNIQUE INDEX IF NOT EXISTS idx_phien_ban_tour_cho_duyet ON phien_ban_tour(tour_id) WHERE trang_thai = 'cho_duyet';
+CREATE INDEX IF NOT EXISTS idx_phien_ban_tour_trang_thai ON phien_ban_tour(trang_thai, ngay_tao);
+
+-- Tour đã công bố trước khi có kiểm duyệt: lưu nội dung hiện tại làm phiên bản 1 (mốc lịch sử)
+INSERT INTO phien_ban_tour (tour_id, so_phien_ban, loai, trang_thai, noi_dung, nhan_xet_duyet, ngay_duyet)
+SELECT t.id, 1, 'gui_duyet', 'da_duyet', tour_noi_dung(t.id), 'Công bố trước khi áp dụng kiểm duyệt', CURRENT_TIMESTAMP
+FROM tour t
+WHERE t.trang_thai = 'cong_bo'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO quyen (ma, nhom, mo_ta) VALUES
+    ('tour.moderate', 'quan_tri', 'Duyệt hoặc từ chối tour và chỉnh sửa tour do nhà cung cấp gửi');
+
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma) VALUES
+    ('quan_tri', 'tour.moderate');
@@ -0,0 +1,145 @@
+-- Migration: Lưu chính sách hủy áp dụng vào booking tại thời điểm đặt
+-- Trước đây tinh_tien_hoan_lai đọc chính sách hiện tại của khởi hành / tour lúc hủy,
+-- nên nhà cung cấp sửa chính sách sau đó sẽ ảnh hưởng ngược lên các booking đã đặt.
+-- dat_cho.chinh_sach_huy_ap_dung là bản chụp chính sách (tên, không hoàn tiền, phí cố định, các mốc) lúc đặt;
+-- NULL nghĩa là khởi hành / tour không gắn chính sách, áp dụng các mốc mặc định 15/7/3/1 ngày - 100/90/70/50%.
+-- Định nghĩa tinh_tien_hoan_lai nằm ở migration này (không còn trong db/query/booking.sql).
+
+ALTER TABLE dat_cho
+    ADD COLUMN chinh_sach_huy_ap_dung JSONB;
+
+-- Bản chụp chính sách hủy đang áp dụng cho khởi hành (của khởi hành, nếu không có thì của tour)
+CREATE OR REPLACE FUNCTION chinh_sach_huy_cua_khoi_hanh(p_khoi_hanh_id INT)
+RETURNS JSONB
+LANGUAGE sql STABLE
+AS $$
+    SELECT jsonb_build_object(
+        'id', csh.id,
+        'ten', csh.ten,
+        'khong_hoan_tien', csh.khong_hoan_tien,
+        'phi_co_dinh', csh.phi_co_dinh,
+        'bac', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object(
+                'so_ngay_toi_thieu', b.so_ngay_toi_thieu,
+                'phan_tram_hoan', b.phan_tram_hoan
+            ) ORDER BY b.so_ngay_toi_thieu DESC)
+            FROM bac_chinh_sach_huy b
+            WHERE b.chinh_sach_huy_id = csh.id
+        ), '[]'::JSONB)
+    )
+    FROM khoi_hanh_tour kh
+    JOIN tour t ON t.id = kh.tour_id
+    JOIN chinh_sach_huy csh ON csh.id = COALESCE(kh.chinh_sach_huy_id, t.chinh_sach_huy_id)
+    WHERE kh.id = p_khoi_hanh_id;
+$$;
+
+-- Booking đã có: chụp chính sách đang áp dụng tại thời điểm chạy migration (thông tin tốt nhất còn lại)
+UPDATE dat_cho dc
+SET chinh_sach_huy_ap_dung = chinh_sach_huy_cua_khoi_hanh(dc.khoi_hanh_id)
+WHERE dc.chinh_sach_huy_ap_dung IS NULL;
+
+-- Function tính số tiền hoàn lại theo chính sách hủy đã lưu trên booking.
+-- Booking không có chính sách thì áp dụng các mốc mặc định 15/7/3/1 ngày - 100/90/70/50%.
+CREATE OR REPLACE FUNCTION tinh_tien_hoan_lai(
+    p_booking_id INT
+) RETURNS TABLE (
+    tong_tien DECIMAL(12,2),
+    so_tien_hoan DECIMAL(12,2),
+    phan_tram_hoan DECIMAL(5,2),
+    so_ngay_truoc_khoi_hanh INT,
+    ly_do TEXT,
+    phi_huy DECIMAL(12,2),
+    chinh_sach_huy_id INT
+) AS $$
+DECLARE
+    v_tong_tien DECIMAL(12,2);
+    v_ngay_khoi_hanh DATE;
+    v_so_ngay_truoc_khoi_hanh INT;
+    v_phan_tram_hoan DECIMAL(5,2);
+    v_so_tien_hoan DECIMAL(12,2);
+    v_ly_do TEXT;
+    v_phi_huy DECIMAL(12,2) := 0;
+    v_chinh_sach JSONB;
+    v_ten TEXT;
+    v_phi_co_dinh DECIMAL(12,2);
+    v_bac RECORD;
+BEGIN
+    -- Lấy thông tin booking, ngày khởi hành và chính sách hủy đã lưu lúc đặt
+    SELECT dc.tong_tien, kh.ngay_khoi_hanh, dc.chinh_sach_huy_ap_dung
+    INTO v_tong_tien, v_ngay_khoi_hanh, v_chinh_sach
+    FROM dat_cho dc
+    JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
+    WHERE dc.id = p_booking_id;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'Booking ID % không tồn tại.', p_booking_id;
+    END IF;
+
+    -- Tính số ngày trước ngày khởi hành
+    v_so_ngay_truoc_khoi_hanh := v_ngay_khoi_hanh - CURRENT_DATE;
+
+    IF v_chinh_sach IS NULL THEN
+        -- Chính sách hoàn tiền mặc định
+        IF v_so_ngay_truoc_khoi_hanh >= 15 THEN
+            -- Trước 15 ngày: hoàn 100%
+            v_phan_tram_hoan := 100.00;
+            v_ly_do := 'Hủy trước 15 ngày - hoàn 100%';
+        ELSIF v_so_ngay_truoc_khoi_hanh >= 7 THEN
+            -- Trước 7 ngày: hoàn 90%
+            v_phan_tram_hoan := 90.00;
+            v_ly_do := 'Hủy trước 7 ngày - hoàn 90%';
+        ELSIF v_so_ngay_truoc_khoi_hanh >= 3 THEN
+            -- Trước 3 ngày: hoàn 70%
+            v_phan_tram_hoan := 70.00;
+            v_ly_do := 'Hủy trước 3 ngày - hoàn 70%';
+        ELSIF v_so_ngay_truoc_khoi_hanh >= 1 THEN
+            -- Trước 24 giờ (1 ngày): hoàn 50%
+            v_phan_tram_hoan := 50.00;
+            v_ly_do := 'Hủy trước 24 giờ - hoàn 50%';
+        ELSE
+            -- Trong 24 giờ: không hoàn
+            v_phan_tram_hoan := 0.00;
+            v_ly_do := 'Hủy trong 24 giờ - không hoàn tiền';
+        END IF;
+    ELSE
+        v_ten := v_chinh_sach->>'ten';
+        v_phi_co_dinh := COALESCE((v_chinh_sach->>'phi_co_dinh')::DECIMAL(12,2), 0);
+
+        IF COALESCE((v_chinh_sach->>'khong_hoan_tien')::BOOLEAN, FALSE) THEN
+            -- Tour không hoàn tiền (khuyến mãi)
+            v_phan_tram_hoan := 0.00;
+            v_ly_do := v_ten || ': không hoàn tiền';
+        ELSE
+            -- Lấy mốc lớn nhất mà thời điểm hủy thỏa mãn
+            SELECT (b->>'so_ngay_toi_thieu')::INT AS so_ngay_toi_thieu,
+                   (b->>'phan_tram_hoan')::DECIMAL(5,2) AS phan_tram_hoan
+            INTO v_bac
+            FROM jsonb_array_elements(COALESCE(v_chinh_sach->'bac', '[]'::JSONB)) b
+            WHERE (b->>'so_ngay_toi_thieu')::INT <= v_so_ngay_truoc_khoi_hanh
+            ORDER BY (b->>'so_ngay_toi_thieu')::INT DESC
+            LIMIT 1;
+
+            IF FOUND THEN
+                v_phan_tram_hoan := v_bac.phan_tram_hoan;
+                v_ly_do := format('%s: hủy trước %s ngày - hoàn %s%%',
+                    v_ten, v_bac.so_ngay_toi_thieu, v_bac.phan_tram_hoan::FLOAT8);
+            ELSE
+                v_phan_tram_hoan := 0.00;
+                v_ly_do := v_ten || ': hủy quá sát ngày khởi hành - không hoàn tiền';
+            END IF;
+
+            -- Phí hủy cố định trừ vào số tiền hoàn (không vượt quá số tiền hoàn)
+            IF v_phan_tram_hoan > 0 AND v_phi_co_dinh > 0 THEN
+                v_phi_huy := LEAST(v_phi_co_dinh, (v_tong_tien * v_phan_tram_hoan) / 100.00);
+                v_ly_do := format('%s, phí hủy %s', v_ly_do, v_phi_huy);
+            END IF;
+        END IF;
+    END IF;
+
+    -- Tính số tiền hoàn lại
+    v_so_tien_hoan := (v_tong_tien * v_phan_tram_hoan) / 100.00 - v_phi_huy;
+
+    RETURN QUERY SELECT v_tong_tien, v_so_tien_hoan, v_phan_tram_hoan, v_so_ngay_truoc_khoi_hanh, v_ly_do, v_phi_huy,
+        (v_chinh_sach->>'id')::INT;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,10 @@
+-- Migration: Ghi nhận nhập sai mã xác thực hai bước vào lịch sử đăng nhập
+-- Mã 2FA sai được tính vào cùng bộ đếm khóa tạm với sai mật khẩu (login:fail:*),
+-- nên lich_su_dang_nhap cần thêm lý do 'sai_ma_2fa'.
+
+ALTER TABLE lich_su_dang_nhap
+    DROP CONSTRAINT IF EXISTS lich_su_dang_nhap_ly_do_that_bai_check;
+
+ALTER TABLE lich_su_dang_nhap
+    ADD CONSTRAINT lich_su_dang_nhap_ly_do_that_bai_check
+        CHECK (ly_do_that_bai IN ('sai_mat_khau', 'khong_ton_tai', 'dang_bi_khoa', 'sai_ma_2fa'));
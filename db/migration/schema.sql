@@ -79,9 +79,7 @@ CREATE INDEX idx_nguoi_dung_active_email ON nguoi_dung(email) WHERE dang_hoat_do
 CREATE INDEX idx_nguoi_dung_active_vai_tro ON nguoi_dung(vai_tro) WHERE dang_hoat_dong = TRUE;
 
 
--- Indexes cho bảng tai_khoan_oauth
-CREATE INDEX idx_tai_khoan_oauth_nguoi_dung_id ON tai_khoan_oauth(nguoi_dung_id);
-CREATE INDEX idx_tai_khoan_oauth_nha_cung_cap_id ON tai_khoan_oauth(nha_cung_cap_id);
+-- Bảng tai_khoan_oauth và indexes được tạo trong 022_add_oauth_accounts.sql
 
 -- Phiên đăng nhập
 CREATE TABLE phien_dang_nhap (
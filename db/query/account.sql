@@ -0,0 +1,204 @@
+-- ===========================================
+-- XUẤT DỮ LIỆU CÁ NHÂN
+-- ===========================================
+
+-- name: ExportUserProfile :one
+-- Hồ sơ người dùng, không kèm mật khẩu đã mã hóa
+SELECT id, ho_ten, email, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+FROM nguoi_dung
+WHERE id = $1;
+
+-- name: ExportUserBookings :many
+SELECT
+    dc.id,
+    dc.khoi_hanh_id,
+    t.tieu_de AS tour_tieu_de,
+    kh.ngay_khoi_hanh,
+    kh.ngay_ket_thuc,
+    dc.so_nguoi_lon,
+    dc.so_tre_em,
+    dc.tong_tien,
+    dc.don_vi_tien_te,
+    dc.trang_thai,
+    dc.phuong_thuc_thanh_toan,
+    dc.ngay_dat,
+    dc.ngay_cap_nhat
+FROM dat_cho dc
+JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
+JOIN tour t ON t.id = kh.tour_id
+WHERE dc.nguoi_dung_id = $1
+ORDER BY dc.ngay_dat DESC, dc.id DESC;
+
+-- name: ExportUserPassengers :many
+SELECT hk.*
+FROM hanh_khach hk
+JOIN dat_cho dc ON dc.id = hk.dat_cho_id
+WHERE dc.nguoi_dung_id = $1
+ORDER BY hk.dat_cho_id DESC, hk.id;
+
+-- name: ExportUserReviews :many
+SELECT
+    dg.id,
+    dg.tour_id,
+    t.tieu_de AS tour_tieu_de,
+    dg.dat_cho_id,
+    dg.diem_danh_gia,
+    dg.tieu_de,
+    dg.noi_dung,
+    dg.hinh_anh_dinh_kem,
+    dg.dang_hoat_dong,
+    dg.ngay_tao,
+    dg.ngay_cap_nhat
+FROM danh_gia dg
+JOIN tour t ON t.id = dg.tour_id
+WHERE dg.nguoi_dung_id = $1
+ORDER BY dg.ngay_tao DESC;
+
+-- name: ExportUserFavorites :many
+SELECT yt.tour_id, t.tieu_de AS tour_tieu_de, yt.ngay_tao
+FROM tour_yeu_thich yt
+JOIN tour t ON t.id = yt.tour_id
+WHERE yt.nguoi_dung_id = $1
+ORDER BY yt.ngay_tao DESC;
+
+-- name: ExportUserChatHistory :many
+SELECT id, ma_phien, cau_hoi, cau_tra_loi, ngay_tao
+FROM lich_su_chat
+WHERE nguoi_dung_id = $1
+ORDER BY ngay_tao DESC, id DESC;
+
+-- name: ExportUserViewHistory :many
+SELECT ls.id, ls.tour_id, t.tieu_de AS tour_tieu_de, ls.thoi_gian_xem, ls.thoi_luong_xem_giay, ls.ip_address, ls.user_agent
+FROM lich_su_xem_tour ls
+JOIN tour t ON t.id = ls.tour_id
+WHERE ls.nguoi_dung_id = $1
+ORDER BY ls.thoi_gian_xem DESC, ls.id DESC;
+
+-- ===========================================
+-- YÊU CẦU XÓA TÀI KHOẢN
+-- ===========================================
+
+-- name: CreateAccountDeletionRequest :one
+INSERT INTO yeu_cau_xoa_tai_khoan (
+    nguoi_dung_id,
+    ly_do,
+    han_xoa
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetPendingAccountDeletion :one
+SELECT * FROM yeu_cau_xoa_tai_khoan
+WHERE nguoi_dung_id = $1 AND trang_thai = 'cho_xoa';
+
+-- name: GetLatestAccountDeletion :one
+SELECT * FROM yeu_cau_xoa_tai_khoan
+WHERE nguoi_dung_id = $1
+ORDER BY ngay_tao DESC, id DESC
+LIMIT 1;
+
+-- name: CancelAccountDeletion :one
+UPDATE yeu_cau_xoa_tai_khoan
+SET trang_thai = 'da_huy',
+    ngay_huy = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = $1 AND trang_thai = 'cho_xoa'
+RETURNING *;
+
+-- name: GetDueAccountDeletions :many
+-- Các yêu cầu đã hết thời gian ân hạn
+SELECT * FROM yeu_cau_xoa_tai_khoan
+WHERE trang_thai = 'cho_xoa' AND han_xoa <= CURRENT_TIMESTAMP
+ORDER BY han_xoa
+LIMIT $1;
+
+-- name: CountActiveBookingsByUser :one
+-- Booking chưa hủy của các khởi hành chưa kết thúc; còn booking này thì chưa thể xóa tài khoản
+SELECT COUNT(*)::int
+FROM dat_cho dc
+JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
+WHERE dc.nguoi_dung_id = $1
+    AND dc.trang_thai IN ('cho_xac_nhan', 'da_xac_nhan', 'da_thanh_toan')
+    AND kh.ngay_ket_thuc >= CURRENT_DATE;
+
+-- name: CompleteAccountDeletion :exec
+UPDATE yeu_cau_xoa_tai_khoan
+SET trang_thai = 'da_xoa',
+    ngay_xoa = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- ===========================================
+-- ẨN DANH / XÓA DỮ LIỆU KHI XÓA TÀI KHOẢN
+-- ===========================================
+
+-- name: AnonymizeUserPassengers :exec
+UPDATE hanh_khach hk
+SET ho_ten = 'Ẩn danh',
+    ngay_sinh = NULL,
+    gioi_tinh = NULL,
+    so_giay_to_tuy_thanh = NULL,
+    quoc_tich = NULL,
+    ghi_chu = NULL
+FROM dat_cho dc
+WHERE dc.id = hk.dat_cho_id AND dc.nguoi_dung_id = $1;
+
+-- name: AnonymizeUserTransactions :exec
+-- Giao dịch vẫn liên kết với booking qua dat_cho_id, chỉ bỏ liên kết trực tiếp tới người dùng
+UPDATE lich_su_giao_dich
+SET nguoi_dung_id = NULL
+WHERE nguoi_dung_id = $1;
+
+-- name: AnonymizeUser :exec
+-- Giữ lại bản ghi nguoi_dung (dat_cho cần khóa ngoại) nhưng xóa toàn bộ thông tin định danh
+UPDATE nguoi_dung
+SET ho_ten = 'Người dùng đã xóa',
+    email = sqlc.arg(email),
+    mat_khau_ma_hoa = sqlc.arg(mat_khau_ma_hoa),
+    so_dien_thoai = NULL,
+    dang_hoat_dong = FALSE,
+    xac_thuc = FALSE,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteUserReviews :exec
+DELETE FROM danh_gia WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserFavorites :exec
+DELETE FROM tour_yeu_thich WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserViewHistory :exec
+DELETE FROM lich_su_xem_tour WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserPreferences :exec
+DELETE FROM so_thich_nguoi_dung WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserNotifications :exec
+DELETE FROM thong_bao WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM phien_dang_nhap WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserLoginHistory :exec
+DELETE FROM lich_su_dang_nhap WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserPasswordResetOtps :exec
+DELETE FROM otp_dat_lai_mat_khau WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM ma_khoi_phuc_2fa WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserTwoFactor :exec
+DELETE FROM xac_thuc_hai_buoc WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserWaitlistEntries :exec
+DELETE FROM danh_sach_cho WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserFinishedSeatHolds :exec
+-- Giữ chỗ đang hiệu lực để tiến trình giữ chỗ tự trả lại khi hết hạn
+DELETE FROM giu_cho WHERE nguoi_dung_id = $1 AND trang_thai <> 'dang_giu';
+
+-- name: DeleteUserContacts :exec
+DELETE FROM lien_he WHERE nguoi_dung_id = $1;
+
+-- name: DeleteUserBlogComments :exec
+DELETE FROM binh_luan_blog WHERE nguoi_dung_id = $1;
@@ -0,0 +1,39 @@
+-- ===========================================
+-- NHẬT KÝ KIỂM TOÁN
+-- ===========================================
+
+-- name: CreateAuditLog :exec
+INSERT INTO nhat_ky_kiem_toan (
+    nguoi_thuc_hien, email_nguoi_thuc_hien, vai_tro, hanh_dong, loai_doi_tuong, ma_doi_tuong,
+    du_lieu_truoc, du_lieu_sau, thay_doi, dia_chi_ip, ma_yeu_cau
+) VALUES (
+    sqlc.narg('nguoi_thuc_hien'), sqlc.narg('email_nguoi_thuc_hien'), sqlc.narg('vai_tro'),
+    sqlc.arg('hanh_dong'), sqlc.arg('loai_doi_tuong'), sqlc.arg('ma_doi_tuong'),
+    sqlc.narg('du_lieu_truoc'), sqlc.narg('du_lieu_sau'), sqlc.narg('thay_doi'),
+    sqlc.narg('dia_chi_ip'), sqlc.narg('ma_yeu_cau')
+);
+
+-- name: ListAuditLogs :many
+-- Lọc theo người thực hiện, hành động, đối tượng, mã request và khoảng thời gian (bỏ trống = không lọc)
+SELECT * FROM nhat_ky_kiem_toan
+WHERE (sqlc.narg('nguoi_thuc_hien')::uuid IS NULL OR nguoi_thuc_hien = sqlc.narg('nguoi_thuc_hien')::uuid)
+    AND (sqlc.narg('vai_tro')::text IS NULL OR vai_tro = sqlc.narg('vai_tro')::text)
+    AND (sqlc.narg('hanh_dong')::text IS NULL OR hanh_dong = sqlc.narg('hanh_dong')::text)
+    AND (sqlc.narg('loai_doi_tuong')::text IS NULL OR loai_doi_tuong = sqlc.narg('loai_doi_tuong')::text)
+    AND (sqlc.narg('ma_doi_tuong')::text IS NULL OR ma_doi_tuong = sqlc.narg('ma_doi_tuong')::text)
+    AND (sqlc.narg('ma_yeu_cau')::text IS NULL OR ma_yeu_cau = sqlc.narg('ma_yeu_cau')::text)
+    AND (sqlc.narg('tu_ngay')::timestamp IS NULL OR ngay_tao >= sqlc.narg('tu_ngay')::timestamp)
+    AND (sqlc.narg('den_ngay')::timestamp IS NULL OR ngay_tao < sqlc.narg('den_ngay')::timestamp)
+ORDER BY ngay_tao DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountAuditLogs :one
+SELECT COUNT(*) FROM nhat_ky_kiem_toan
+WHERE (sqlc.narg('nguoi_thuc_hien')::uuid IS NULL OR nguoi_thuc_hien = sqlc.narg('nguoi_thuc_hien')::uuid)
+    AND (sqlc.narg('vai_tro')::text IS NULL OR vai_tro = sqlc.narg('vai_tro')::text)
+    AND (sqlc.narg('hanh_dong')::text IS NULL OR hanh_dong = sqlc.narg('hanh_dong')::text)
+    AND (sqlc.narg('loai_doi_tuong')::text IS NULL OR loai_doi_tuong = sqlc.narg('loai_doi_tuong')::text)
+    AND (sqlc.narg('ma_doi_tuong')::text IS NULL OR ma_doi_tuong = sqlc.narg('ma_doi_tuong')::text)
+    AND (sqlc.narg('ma_yeu_cau')::text IS NULL OR ma_yeu_cau = sqlc.narg('ma_yeu_cau')::text)
+    AND (sqlc.narg('tu_ngay')::timestamp IS NULL OR ngay_tao >= sqlc.narg('tu_ngay')::timestamp)
+    AND (sqlc.narg('den_ngay')::timestamp IS NULL OR ngay_tao < sqlc.narg('den_ngay')::timestamp);
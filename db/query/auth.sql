@@ -5,6 +5,8 @@ values
     returning *;
 -- name: GetUserByEmail :one
 select * from nguoi_dung where email = $1;
+-- name: GetUserAccountByID :one
+select * from nguoi_dung where id = $1;
 -- name: GetUserById :one
 SELECT 
     nguoi_dung.*, 
@@ -0,0 +1,84 @@
+-- Gợi ý tìm kiếm hỗn hợp (tour, điểm đến, danh mục) theo tiền tố và độ giống trigram trên văn bản đã bỏ dấu.
+-- Điểm = max(similarity, word_similarity) + thưởng khi khớp đầu chuỗi (0.5) hoặc đầu một từ (0.3);
+-- "Ha Lng" vẫn khớp "Hạ Long" nhờ word_similarity (toán tử <% dùng được index gin_trgm_ops)
+
+-- name: GetAutocompleteSuggestions :many
+WITH q AS (
+  SELECT
+    khong_dau(sqlc.arg('q')::TEXT) AS v,
+    replace(replace(replace(khong_dau(sqlc.arg('q')::TEXT), '\', '\\'), '%', '\%'), '_', '\_') AS v_like
+),
+tours AS (
+  SELECT
+    'tour'::TEXT AS loai,
+    t.id,
+    t.tieu_de::TEXT AS nhan,
+    COALESCE(dm.ten, '')::TEXT AS mo_ta_phu,
+    (
+      GREATEST(similarity(khong_dau(t.tieu_de), q.v), word_similarity(q.v, khong_dau(t.tieu_de)))
+      + CASE
+          WHEN khong_dau(t.tieu_de) LIKE q.v_like || '%' THEN 0.5
+          WHEN ' ' || khong_dau(t.tieu_de) LIKE '% ' || q.v_like || '%' THEN 0.3
+          ELSE 0
+        END
+    )::FLOAT AS diem
+  FROM tour t
+  CROSS JOIN q
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+    AND (khong_dau(t.tieu_de) LIKE '%' || q.v_like || '%' OR q.v <% khong_dau(t.tieu_de))
+  ORDER BY diem DESC, t.noi_bat DESC, t.id DESC
+  LIMIT sqlc.arg('limit')::INT
+),
+diem_dens AS (
+  SELECT
+    'diem_den'::TEXT AS loai,
+    d.id,
+    d.ten::TEXT AS nhan,
+    concat_ws(', ', d.tinh, d.quoc_gia)::TEXT AS mo_ta_phu,
+    (
+      GREATEST(similarity(khong_dau(d.ten), q.v), word_similarity(q.v, khong_dau(d.ten)))
+      + CASE
+          WHEN khong_dau(d.ten) LIKE q.v_like || '%' THEN 0.5
+          WHEN ' ' || khong_dau(d.ten) LIKE '% ' || q.v_like || '%' THEN 0.3
+          ELSE 0
+        END
+      -- Ưu tiên nhẹ điểm đến có nhiều tour đang mở bán
+      + LEAST(0.1, 0.02 * (
+          SELECT COUNT(*) FROM tour_diem_den tdd
+          JOIN tour t ON t.id = tdd.tour_id
+          WHERE tdd.diem_den_id = d.id AND t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+        ))
+    )::FLOAT AS diem
+  FROM diem_den d
+  CROSS JOIN q
+  WHERE khong_dau(d.ten) LIKE '%' || q.v_like || '%' OR q.v <% khong_dau(d.ten)
+  ORDER BY diem DESC, d.id ASC
+  LIMIT sqlc.arg('limit')::INT
+),
+danh_mucs AS (
+  SELECT
+    'danh_muc'::TEXT AS loai,
+    dm.id,
+    dm.ten::TEXT AS nhan,
+    ''::TEXT AS mo_ta_phu,
+    (
+      GREATEST(similarity(khong_dau(dm.ten), q.v), word_similarity(q.v, khong_dau(dm.ten)))
+      + CASE
+          WHEN khong_dau(dm.ten) LIKE q.v_like || '%' THEN 0.5
+          WHEN ' ' || khong_dau(dm.ten) LIKE '% ' || q.v_like || '%' THEN 0.3
+          ELSE 0
+        END
+    )::FLOAT AS diem
+  FROM danh_muc_tour dm
+  CROSS JOIN q
+  WHERE COALESCE(dm.dang_hoat_dong, TRUE) = TRUE
+    AND (khong_dau(dm.ten) LIKE '%' || q.v_like || '%' OR q.v <% khong_dau(dm.ten))
+  ORDER BY diem DESC, dm.id ASC
+  LIMIT sqlc.arg('limit')::INT
+)
+SELECT loai, id, nhan, mo_ta_phu, diem FROM tours
+UNION ALL
+SELECT loai, id, nhan, mo_ta_phu, diem FROM diem_dens
+UNION ALL
+SELECT loai, id, nhan, mo_ta_phu, diem FROM danh_mucs;
@@ -0,0 +1,70 @@
+-- ===========================================
+-- CHUYỂN KHOẢN NGÂN HÀNG & ĐỐI SOÁT SAO KÊ
+-- ===========================================
+
+-- name: GetPendingBankTransferByBooking :one
+-- Lấy giao dịch chuyển khoản đang chờ gần nhất của booking (để tái sử dụng nội dung chuyển khoản)
+SELECT * FROM lich_su_giao_dich
+WHERE dat_cho_id = $1
+    AND cong_thanh_toan_id = 'bank_transfer'
+    AND trang_thai = 'dang_cho_thanh_toan'
+ORDER BY ngay_tao DESC
+LIMIT 1;
+
+-- name: CreateBankStatementLine :one
+-- Ghi nhận một dòng sao kê; dòng đã tải lên trước đó (trùng mã giao dịch ngân hàng) được bỏ qua (pgx.ErrNoRows)
+INSERT INTO sao_ke_ngan_hang (
+    ma_giao_dich_ngan_hang,
+    ngay_giao_dich,
+    so_tien,
+    noi_dung,
+    ten_tep,
+    nguoi_tai_len_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (ma_giao_dich_ngan_hang) DO NOTHING
+RETURNING *;
+
+-- name: GetUnreconciledBankStatementLines :many
+-- Lấy các dòng sao kê tiền vào chưa đối soát
+SELECT * FROM sao_ke_ngan_hang
+WHERE trang_thai = 'chua_doi_soat'
+    AND so_tien > 0
+ORDER BY ngay_giao_dich ASC NULLS LAST, id ASC;
+
+-- name: FindPendingBankTransferByContent :one
+-- Tìm giao dịch chuyển khoản đang chờ có nội dung chuyển khoản nằm trong nội dung sao kê (đã chuẩn hóa)
+SELECT * FROM lich_su_giao_dich
+WHERE cong_thanh_toan_id = 'bank_transfer'
+    AND trang_thai IN ('dang_cho_thanh_toan', 'dang_xuly')
+    AND strpos(sqlc.arg('noi_dung')::text, UPPER(ma_giao_dich_noi_bo)) > 0
+ORDER BY LENGTH(ma_giao_dich_noi_bo) DESC, ngay_tao DESC
+LIMIT 1;
+
+-- name: UpdateBankStatementLineStatus :one
+-- Cập nhật kết quả đối soát của dòng sao kê (chỉ dòng chưa đối soát)
+UPDATE sao_ke_ngan_hang
+SET trang_thai = sqlc.arg('trang_thai')::trang_thai_doi_soat,
+    giao_dich_id = sqlc.narg('giao_dich_id')::int,
+    ghi_chu = sqlc.narg('ghi_chu')::text,
+    ngay_doi_soat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')::int
+    AND trang_thai = 'chua_doi_soat'
+RETURNING *;
+
+-- name: GetBankStatementLines :many
+-- Danh sách dòng sao kê (Admin), lọc theo trạng thái đối soát
+SELECT
+    sk.*,
+    lsgd.ma_giao_dich_noi_bo,
+    lsgd.dat_cho_id
+FROM sao_ke_ngan_hang sk
+LEFT JOIN lich_su_giao_dich lsgd ON lsgd.id = sk.giao_dich_id
+WHERE (sqlc.narg('trang_thai')::trang_thai_doi_soat IS NULL OR sk.trang_thai = sqlc.narg('trang_thai')::trang_thai_doi_soat)
+ORDER BY sk.ngay_tao DESC, sk.id DESC
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountBankStatementLines :one
+SELECT COUNT(*) FROM sao_ke_ngan_hang
+WHERE (sqlc.narg('trang_thai')::trang_thai_doi_soat IS NULL OR trang_thai = sqlc.narg('trang_thai')::trang_thai_doi_soat);
@@ -46,6 +46,36 @@ WHERE id = $1
 -- BƯỚC 2: TẠO ĐẶT CHỖ (BOOKING)
 -- ===========================================
 
+-- Function kiểm tra số khách theo giới hạn nhóm của tour (cau_hinh_nhom_tour)
+CREATE OR REPLACE FUNCTION kiem_tra_gioi_han_nhom(
+    p_khoi_hanh_id INT,
+    p_so_khach INT
+) RETURNS VOID AS $$
+DECLARE
+    v_so_nho_nhat INT;
+    v_so_lon_nhat INT;
+BEGIN
+    -- Lấy cấu hình cố định theo id (cùng thứ tự với GetDepartureGroupRules) khi tour có nhiều dòng cấu hình
+    SELECT ch.so_nho_nhat, ch.so_lon_nhat
+    INTO v_so_nho_nhat, v_so_lon_nhat
+    FROM khoi_hanh_tour kh
+    JOIN cau_hinh_nhom_tour ch ON ch.tour_id = kh.tour_id
+    WHERE kh.id = p_khoi_hanh_id
+    ORDER BY ch.id
+    LIMIT 1;
+
+    -- Tour không có cấu hình nhóm thì không giới hạn
+    IF NOT FOUND THEN
+        RETURN;
+    END IF;
+
+    IF p_so_khach < COALESCE(v_so_nho_nhat, 1) OR p_so_khach > COALESCE(v_so_lon_nhat, p_so_khach) THEN
+        RAISE EXCEPTION 'Số khách % nằm ngoài giới hạn nhóm của tour (% - % khách).',
+            p_so_khach, COALESCE(v_so_nho_nhat, 1), COALESCE(v_so_lon_nhat::TEXT, 'không giới hạn');
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
 -- Function giữ chỗ
 CREATE OR REPLACE FUNCTION hold_seat(
     p_khoi_hanh_id INT,
@@ -65,6 +95,9 @@ BEGIN
         RAISE EXCEPTION 'Số lượng người lớn và trẻ em phải không âm và tổng số người phải lớn hơn 0.';
     END IF;
 
+    -- Kiểm tra giới hạn nhóm của tour
+    PERFORM kiem_tra_gioi_han_nhom(p_khoi_hanh_id, v_tong_so_ghe_dat);
+
     SELECT suc_chua, COALESCE(so_cho_da_dat, 0)
     INTO STRICT v_suc_chua, v_so_cho_da_dat
     FROM khoi_hanh_tour
@@ -185,6 +218,9 @@ DECLARE
     v_don_vi_tien_te VARCHAR(3);
     v_booking dat_cho;
 BEGIN
+    -- 0. Kiểm tra giới hạn nhóm của tour (cấu hình có thể thay đổi sau khi giữ chỗ)
+    PERFORM kiem_tra_gioi_han_nhom(p_khoi_hanh_id, p_so_nguoi_lon + p_so_tre_em);
+
     -- 1. Tính giá tour (lấy row đầu tiên từ TABLE result)
     SELECT tgt.tong_tien, tgt.don_vi_tien_te
     INTO STRICT v_tong_tien, v_don_vi_tien_te
@@ -210,7 +246,8 @@ BEGIN
         tong_tien,
         don_vi_tien_te,
         trang_thai,
-        phuong_thuc_thanh_toan
+        phuong_thuc_thanh_toan,
+        chinh_sach_huy_ap_dung
     ) VALUES (
         p_nguoi_dung_id,
         p_khoi_hanh_id,
@@ -219,7 +256,9 @@ BEGIN
         v_tong_tien,
         v_don_vi_tien_te,
         'cho_xac_nhan',
-        p_phuong_thuc_thanh_toan
+        p_phuong_thuc_thanh_toan,
+        -- Chụp chính sách hủy lúc đặt: nhà cung cấp sửa chính sách sau đó không ảnh hưởng booking này
+        chinh_sach_huy_cua_khoi_hanh(p_khoi_hanh_id)
     ) RETURNING * INTO v_booking;
 
     RETURN v_booking;
@@ -358,6 +397,51 @@ SELECT * FROM hanh_khach
 WHERE dat_cho_id = $1
 ORDER BY loai_khach DESC, id ASC;
 
+-- name: LockEditableBookingForPassengers :one
+-- Khóa booking khi thay danh sách hành khách; chỉ booking chưa thanh toán (cho_xac_nhan, da_xac_nhan) được sửa
+SELECT id FROM dat_cho
+WHERE id = $1 AND trang_thai IN ('cho_xac_nhan', 'da_xac_nhan')
+FOR UPDATE;
+
+-- name: DeletePassengersByBooking :exec
+-- Xóa toàn bộ hành khách của booking (trước khi ghi lại danh sách mới)
+DELETE FROM hanh_khach
+WHERE dat_cho_id = $1;
+
+-- name: GetDepartureGroupRules :one
+-- Quy tắc nhóm khách của tour theo khởi hành (mặc định khi tour chưa cấu hình)
+SELECT
+    kh.id AS khoi_hanh_id,
+    kh.tour_id,
+    kh.ngay_khoi_hanh,
+    COALESCE(ch.so_nho_nhat, 1)::INT AS so_nho_nhat,
+    ch.so_lon_nhat,
+    COALESCE(ch.tuoi_tre_em_toi_thieu, 2)::INT AS tuoi_tre_em_toi_thieu,
+    COALESCE(ch.tuoi_tre_em_toi_da, 11)::INT AS tuoi_tre_em_toi_da
+FROM khoi_hanh_tour kh
+LEFT JOIN cau_hinh_nhom_tour ch ON ch.tour_id = kh.tour_id
+WHERE kh.id = $1
+ORDER BY ch.id
+LIMIT 1;
+
+-- name: UpdateBookingPassengerMix :one
+-- Cập nhật số người lớn / trẻ em theo danh sách hành khách và tính lại tổng tiền.
+-- Chỉ áp dụng cho booking chưa thanh toán và không đổi tổng số khách (số chỗ đã giữ)
+UPDATE dat_cho dc
+SET so_nguoi_lon = sqlc.arg('so_nguoi_lon')::int,
+    so_tre_em = sqlc.arg('so_tre_em')::int,
+    tong_tien = gia.tong_tien,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM tinh_gia_tour(
+    (SELECT khoi_hanh_id FROM dat_cho WHERE id = sqlc.arg('id')::int),
+    sqlc.arg('so_nguoi_lon')::int,
+    sqlc.arg('so_tre_em')::int
+) gia
+WHERE dc.id = sqlc.arg('id')::int
+    AND dc.trang_thai = 'cho_xac_nhan'
+    AND COALESCE(dc.so_nguoi_lon, 0) + COALESCE(dc.so_tre_em, 0) = sqlc.arg('so_nguoi_lon')::int + sqlc.arg('so_tre_em')::int
+RETURNING dc.*;
+
 -- name: UpdatePassenger :one
 -- Cập nhật thông tin hành khách
 UPDATE hanh_khach
@@ -495,67 +579,8 @@ WHERE dc.khoi_hanh_id = kh.id
 -- HỦY BOOKING & HOÀN TIỀN
 -- ===========================================
 
--- Function tính số tiền hoàn lại dựa trên chính sách hoàn tiền
-CREATE OR REPLACE FUNCTION tinh_tien_hoan_lai(
-    p_booking_id INT
-) RETURNS TABLE (
-    tong_tien DECIMAL(12,2),
-    so_tien_hoan DECIMAL(12,2),
-    phan_tram_hoan DECIMAL(5,2),
-    so_ngay_truoc_khoi_hanh INT,
-    ly_do TEXT
-) AS $$
-DECLARE
-    v_tong_tien DECIMAL(12,2);
-    v_ngay_khoi_hanh DATE;
-    v_so_ngay_truoc_khoi_hanh INT;
-    v_phan_tram_hoan DECIMAL(5,2);
-    v_so_tien_hoan DECIMAL(12,2);
-    v_ly_do TEXT;
-BEGIN
-    -- Lấy thông tin booking và ngày khởi hành
-    SELECT dc.tong_tien, kh.ngay_khoi_hanh
-    INTO v_tong_tien, v_ngay_khoi_hanh
-    FROM dat_cho dc
-    JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
-    WHERE dc.id = p_booking_id;
-
-    IF NOT FOUND THEN
-        RAISE EXCEPTION 'Booking ID % không tồn tại.', p_booking_id;
-    END IF;
-
-    -- Tính số ngày trước ngày khởi hành
-    v_so_ngay_truoc_khoi_hanh := v_ngay_khoi_hanh - CURRENT_DATE;
-
-    -- Áp dụng chính sách hoàn tiền
-    IF v_so_ngay_truoc_khoi_hanh >= 15 THEN
-        -- Trước 15 ngày: hoàn 100%
-        v_phan_tram_hoan := 100.00;
-        v_ly_do := 'Hủy trước 15 ngày - hoàn 100%';
-    ELSIF v_so_ngay_truoc_khoi_hanh >= 7 THEN
-        -- Trước 7 ngày: hoàn 90%
-        v_phan_tram_hoan := 90.00;
-        v_ly_do := 'Hủy trước 7 ngày - hoàn 90%';
-    ELSIF v_so_ngay_truoc_khoi_hanh >= 3 THEN
-        -- Trước 3 ngày: hoàn 70%
-        v_phan_tram_hoan := 70.00;
-        v_ly_do := 'Hủy trước 3 ngày - hoàn 70%';
-    ELSIF v_so_ngay_truoc_khoi_hanh >= 1 THEN
-        -- Trước 24 giờ (1 ngày): hoàn 50%
-        v_phan_tram_hoan := 50.00;
-        v_ly_do := 'Hủy trước 24 giờ - hoàn 50%';
-    ELSE
-        -- Trong 24 giờ: không hoàn
-        v_phan_tram_hoan := 0.00;
-        v_ly_do := 'Hủy trong 24 giờ - không hoàn tiền';
-    END IF;
-
-    -- Tính số tiền hoàn lại
-    v_so_tien_hoan := (v_tong_tien * v_phan_tram_hoan) / 100.00;
-
-    RETURN QUERY SELECT v_tong_tien, v_so_tien_hoan, v_phan_tram_hoan, v_so_ngay_truoc_khoi_hanh, v_ly_do;
-END;
-$$ LANGUAGE plpgsql;
+-- tinh_tien_hoan_lai (tính số tiền hoàn theo chính sách hủy đã lưu trên booking)
+-- được định nghĩa trong db/migration/028_add_booking_cancellation_policy_snapshot.sql
 
 -- Function hủy booking và trả lại chỗ
 CREATE OR REPLACE FUNCTION cancel_booking(
@@ -602,6 +627,7 @@ BEGIN
     WHERE id = p_booking_id;
 
     -- Tính lại số chỗ đã đặt từ tổng số booking thực tế (không bao gồm booking đã hủy)
+    -- cộng với các giữ chỗ còn hiệu lực chưa chuyển thành booking
     -- Điều này đảm bảo dữ liệu luôn đồng bộ và không bị lệch
     UPDATE khoi_hanh_tour
     SET so_cho_da_dat = COALESCE((
@@ -609,6 +635,11 @@ BEGIN
         FROM dat_cho
         WHERE khoi_hanh_id = v_khoi_hanh_id
             AND trang_thai != 'da_huy'
+    ), 0) + COALESCE((
+        SELECT SUM(so_nguoi_lon + so_tre_em)
+        FROM giu_cho
+        WHERE khoi_hanh_id = v_khoi_hanh_id
+            AND trang_thai = 'dang_giu'
     ), 0),
         ngay_cap_nhat = CURRENT_TIMESTAMP
     WHERE id = v_khoi_hanh_id;
@@ -623,42 +654,18 @@ END;
 $$ LANGUAGE plpgsql;
 
 -- name: CalculateRefundAmount :one
-WITH booking_info AS (
-    SELECT 
-        dc.tong_tien,
-        kh.ngay_khoi_hanh,
-        (kh.ngay_khoi_hanh - CURRENT_DATE)::INT AS so_ngay_truoc_khoi_hanh
-    FROM dat_cho dc
-    JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
-    WHERE dc.id = sqlc.arg('booking_id')::int
-),
-refund_calc AS (
-    SELECT 
-        bi.tong_tien,
-        bi.so_ngay_truoc_khoi_hanh,
-        CASE 
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 15 THEN 100.00
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 7 THEN 90.00
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 3 THEN 70.00
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 1 THEN 50.00
-            ELSE 0.00
-        END::DECIMAL(5,2) AS phan_tram_hoan,
-        CASE 
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 15 THEN 'Hủy trước 15 ngày - hoàn 100%'
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 7 THEN 'Hủy trước 7 ngày - hoàn 90%'
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 3 THEN 'Hủy trước 3 ngày - hoàn 70%'
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 1 THEN 'Hủy trước 24 giờ - hoàn 50%'
-            ELSE 'Hủy trong 24 giờ - không hoàn tiền'
-        END::TEXT AS ly_do
-    FROM booking_info bi
-)
-SELECT 
-    rc.tong_tien,
-    (rc.tong_tien * rc.phan_tram_hoan / 100.00)::DECIMAL(12,2) AS so_tien_hoan,
-    rc.phan_tram_hoan,
-    rc.so_ngay_truoc_khoi_hanh,
-    rc.ly_do
-FROM refund_calc rc;
+-- Xem trước số tiền hoàn lại (cùng chính sách với cancel_booking)
+SELECT
+    hl.tong_tien::DECIMAL(12,2) AS tong_tien,
+    hl.so_tien_hoan::DECIMAL(12,2) AS so_tien_hoan,
+    hl.phan_tram_hoan::DECIMAL(5,2) AS phan_tram_hoan,
+    hl.so_ngay_truoc_khoi_hanh::INT AS so_ngay_truoc_khoi_hanh,
+    hl.ly_do::TEXT AS ly_do,
+    hl.phi_huy::DECIMAL(12,2) AS phi_huy,
+    -- Bản chụp chính sách hủy lúc đặt (NULL khi áp dụng chính sách mặc định)
+    dc.chinh_sach_huy_ap_dung
+FROM tinh_tien_hoan_lai(sqlc.arg('booking_id')::int) hl
+JOIN dat_cho dc ON dc.id = sqlc.arg('booking_id')::int;
 
 -- name: CancelBooking :one
 SELECT 
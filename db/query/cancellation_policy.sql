@@ -0,0 +1,142 @@
+-- ===========================================
+-- CHÍNH SÁCH HỦY TOUR (CANCELLATION POLICY)
+-- ===========================================
+
+-- name: CreateCancellationPolicy :one
+INSERT INTO chinh_sach_huy (
+    nha_cung_cap_id,
+    ten,
+    mo_ta,
+    khong_hoan_tien,
+    phi_co_dinh
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: UpdateCancellationPolicy :one
+UPDATE chinh_sach_huy
+SET ten = sqlc.arg('ten'),
+    mo_ta = sqlc.narg('mo_ta'),
+    khong_hoan_tien = sqlc.arg('khong_hoan_tien'),
+    phi_co_dinh = sqlc.arg('phi_co_dinh'),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+    AND nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')
+RETURNING *;
+
+-- name: DeleteCancellationPolicy :execrows
+-- Tour/khởi hành đang dùng chính sách sẽ quay về chính sách mặc định (ON DELETE SET NULL)
+DELETE FROM chinh_sach_huy
+WHERE id = $1 AND nha_cung_cap_id = $2;
+
+-- name: CreateCancellationPolicyTier :one
+INSERT INTO bac_chinh_sach_huy (
+    chinh_sach_huy_id,
+    so_ngay_toi_thieu,
+    phan_tram_hoan
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: DeleteCancellationPolicyTiers :exec
+DELETE FROM bac_chinh_sach_huy
+WHERE chinh_sach_huy_id = $1;
+
+-- name: GetCancellationPoliciesBySupplier :many
+-- Danh sách chính sách hủy của nhà cung cấp kèm các mốc hoàn tiền và số tour/khởi hành đang dùng
+SELECT
+    csh.*,
+    COALESCE((
+        SELECT json_agg(json_build_object(
+            'so_ngay_toi_thieu', b.so_ngay_toi_thieu,
+            'phan_tram_hoan', b.phan_tram_hoan
+        ) ORDER BY b.so_ngay_toi_thieu DESC)
+        FROM bac_chinh_sach_huy b
+        WHERE b.chinh_sach_huy_id = csh.id
+    ), '[]')::json AS cac_bac,
+    (SELECT COUNT(*) FROM tour t WHERE t.chinh_sach_huy_id = csh.id)::INT AS so_tour,
+    (SELECT COUNT(*) FROM khoi_hanh_tour kh WHERE kh.chinh_sach_huy_id = csh.id)::INT AS so_khoi_hanh
+FROM chinh_sach_huy csh
+WHERE csh.nha_cung_cap_id = $1
+ORDER BY csh.ngay_tao DESC;
+
+-- name: SetTourCancellationPolicy :one
+-- Gắn (hoặc gỡ khi chinh_sach_huy_id NULL) chính sách hủy cho tour; chính sách phải thuộc cùng nhà cung cấp
+UPDATE tour t
+SET chinh_sach_huy_id = sqlc.narg('chinh_sach_huy_id'),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE t.id = sqlc.arg('tour_id')
+    AND t.nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')
+    AND (
+        sqlc.narg('chinh_sach_huy_id')::int IS NULL
+        OR EXISTS (
+            SELECT 1 FROM chinh_sach_huy csh
+            WHERE csh.id = sqlc.narg('chinh_sach_huy_id')::int
+                AND csh.nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')
+        )
+    )
+RETURNING t.id, t.chinh_sach_huy_id;
+
+-- name: SetDepartureCancellationPolicy :one
+-- Gắn (hoặc gỡ) chính sách hủy riêng cho một khởi hành, ưu tiên hơn chính sách của tour
+UPDATE khoi_hanh_tour kh
+SET chinh_sach_huy_id = sqlc.narg('chinh_sach_huy_id'),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM tour t
+WHERE kh.tour_id = t.id
+    AND kh.id = sqlc.arg('khoi_hanh_id')
+    AND t.nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')
+    AND (
+        sqlc.narg('chinh_sach_huy_id')::int IS NULL
+        OR EXISTS (
+            SELECT 1 FROM chinh_sach_huy csh
+            WHERE csh.id = sqlc.narg('chinh_sach_huy_id')::int
+                AND csh.nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')
+        )
+    )
+RETURNING kh.id, kh.tour_id, kh.chinh_sach_huy_id;
+
+-- name: GetTourCancellationPolicies :many
+-- Chính sách hủy của tour (khoi_hanh_id NULL) và của các khởi hành sắp tới có chính sách riêng
+SELECT
+    NULL::int AS khoi_hanh_id,
+    NULL::date AS ngay_khoi_hanh,
+    csh.id,
+    csh.ten,
+    csh.mo_ta,
+    csh.khong_hoan_tien,
+    csh.phi_co_dinh,
+    COALESCE((
+        SELECT json_agg(json_build_object(
+            'so_ngay_toi_thieu', b.so_ngay_toi_thieu,
+            'phan_tram_hoan', b.phan_tram_hoan
+        ) ORDER BY b.so_ngay_toi_thieu DESC)
+        FROM bac_chinh_sach_huy b
+        WHERE b.chinh_sach_huy_id = csh.id
+    ), '[]')::json AS cac_bac
+FROM tour t
+JOIN chinh_sach_huy csh ON csh.id = t.chinh_sach_huy_id
+WHERE t.id = sqlc.arg('tour_id')::int
+UNION ALL
+SELECT
+    kh.id AS khoi_hanh_id,
+    kh.ngay_khoi_hanh,
+    csh.id,
+    csh.ten,
+    csh.mo_ta,
+    csh.khong_hoan_tien,
+    csh.phi_co_dinh,
+    COALESCE((
+        SELECT json_agg(json_build_object(
+            'so_ngay_toi_thieu', b.so_ngay_toi_thieu,
+            'phan_tram_hoan', b.phan_tram_hoan
+        ) ORDER BY b.so_ngay_toi_thieu DESC)
+        FROM bac_chinh_sach_huy b
+        WHERE b.chinh_sach_huy_id = csh.id
+    ), '[]')::json AS cac_bac
+FROM khoi_hanh_tour kh
+JOIN chinh_sach_huy csh ON csh.id = kh.chinh_sach_huy_id
+WHERE kh.tour_id = sqlc.arg('tour_id')::int
+    AND kh.ngay_khoi_hanh >= CURRENT_DATE;
@@ -0,0 +1,90 @@
+-- Tìm theo vị trí dựa trên tọa độ diem_den (vi_do, kinh_do).
+-- Khung tọa độ (min/max) luôn được truyền vào để dùng index; với tìm theo bán kính, khung là hình chữ nhật bao quanh vòng tròn
+-- và ban_kinh_km loại tiếp các điểm nằm ngoài vòng tròn. min_lng > max_lng nghĩa là khung vắt qua kinh tuyến 180.
+-- Khoảng cách (km) tính theo công thức haversine từ điểm (lat, lng).
+
+-- name: GetToursNearPoint :many
+-- Mỗi tour lấy điểm đến gần nhất nằm trong vùng tìm kiếm làm vị trí ghim trên bản đồ
+WITH dd AS (
+  SELECT
+    d.id, d.ten, d.tinh, d.quoc_gia,
+    d.vi_do::FLOAT AS vi_do,
+    d.kinh_do::FLOAT AS kinh_do,
+    (6371 * 2 * ASIN(LEAST(1, SQRT(
+      POWER(SIN(RADIANS(d.vi_do::FLOAT - sqlc.arg('lat')::FLOAT) / 2), 2)
+      + COS(RADIANS(sqlc.arg('lat')::FLOAT)) * COS(RADIANS(d.vi_do::FLOAT))
+        * POWER(SIN(RADIANS(d.kinh_do::FLOAT - sqlc.arg('lng')::FLOAT) / 2), 2)
+    ))))::FLOAT AS khoang_cach_km
+  FROM diem_den d
+  WHERE d.vi_do IS NOT NULL AND d.kinh_do IS NOT NULL
+    AND d.vi_do BETWEEN sqlc.arg('min_lat')::NUMERIC AND sqlc.arg('max_lat')::NUMERIC
+    AND (
+      (sqlc.arg('min_lng')::NUMERIC <= sqlc.arg('max_lng')::NUMERIC AND d.kinh_do BETWEEN sqlc.arg('min_lng')::NUMERIC AND sqlc.arg('max_lng')::NUMERIC)
+      OR (sqlc.arg('min_lng')::NUMERIC > sqlc.arg('max_lng')::NUMERIC AND (d.kinh_do >= sqlc.arg('min_lng')::NUMERIC OR d.kinh_do <= sqlc.arg('max_lng')::NUMERIC))
+    )
+),
+gan_nhat AS (
+  SELECT DISTINCT ON (t.id)
+    t.id, t.tieu_de, t.so_ngay, t.so_dem, t.gia_nguoi_lon, t.don_vi_tien_te, t.noi_bat,
+    dd.id AS diem_den_id, dd.ten AS diem_den_ten, dd.tinh, dd.quoc_gia,
+    dd.vi_do, dd.kinh_do, dd.khoang_cach_km
+  FROM tour t
+  JOIN tour_diem_den tdd ON tdd.tour_id = t.id
+  JOIN dd ON dd.id = tdd.diem_den_id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+    AND (sqlc.narg('ban_kinh_km')::FLOAT IS NULL OR dd.khoang_cach_km <= sqlc.narg('ban_kinh_km')::FLOAT)
+  ORDER BY t.id, dd.khoang_cach_km ASC
+)
+SELECT
+  g.id, g.tieu_de, g.so_ngay, g.so_dem, g.gia_nguoi_lon, g.don_vi_tien_te, g.noi_bat,
+  g.diem_den_id, g.diem_den_ten, g.tinh, g.quoc_gia,
+  g.vi_do, g.kinh_do, g.khoang_cach_km,
+  COALESCE(anh.duong_dan, '')::TEXT AS anh_chinh,
+  COUNT(*) OVER ()::INT AS tong
+FROM gan_nhat g
+LEFT JOIN LATERAL (
+  SELECT a.duong_dan FROM anh_tour a
+  WHERE a.tour_id = g.id
+  ORDER BY COALESCE(a.la_anh_chinh, FALSE) DESC, COALESCE(a.thu_tu_hien_thi, 0) ASC
+  LIMIT 1
+) anh ON TRUE
+ORDER BY g.khoang_cach_km ASC, g.noi_bat DESC, g.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetDestinationsNearPoint :many
+WITH dd AS (
+  SELECT
+    d.id, d.ten, d.tinh, d.quoc_gia, d.khu_vuc, d.anh,
+    d.vi_do::FLOAT AS vi_do,
+    d.kinh_do::FLOAT AS kinh_do,
+    (6371 * 2 * ASIN(LEAST(1, SQRT(
+      POWER(SIN(RADIANS(d.vi_do::FLOAT - sqlc.arg('lat')::FLOAT) / 2), 2)
+      + COS(RADIANS(sqlc.arg('lat')::FLOAT)) * COS(RADIANS(d.vi_do::FLOAT))
+        * POWER(SIN(RADIANS(d.kinh_do::FLOAT - sqlc.arg('lng')::FLOAT) / 2), 2)
+    ))))::FLOAT AS khoang_cach_km
+  FROM diem_den d
+  WHERE d.vi_do IS NOT NULL AND d.kinh_do IS NOT NULL
+    AND d.vi_do BETWEEN sqlc.arg('min_lat')::NUMERIC AND sqlc.arg('max_lat')::NUMERIC
+    AND (
+      (sqlc.arg('min_lng')::NUMERIC <= sqlc.arg('max_lng')::NUMERIC AND d.kinh_do BETWEEN sqlc.arg('min_lng')::NUMERIC AND sqlc.arg('max_lng')::NUMERIC)
+      OR (sqlc.arg('min_lng')::NUMERIC > sqlc.arg('max_lng')::NUMERIC AND (d.kinh_do >= sqlc.arg('min_lng')::NUMERIC OR d.kinh_do <= sqlc.arg('max_lng')::NUMERIC))
+    )
+),
+so_tour AS (
+  SELECT tdd.diem_den_id, COUNT(DISTINCT t.id)::INT AS so_luong_tour
+  FROM tour_diem_den tdd
+  JOIN tour t ON t.id = tdd.tour_id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+  GROUP BY tdd.diem_den_id
+)
+SELECT
+  dd.id, dd.ten, dd.tinh, dd.quoc_gia, dd.khu_vuc, dd.anh,
+  dd.vi_do, dd.kinh_do, dd.khoang_cach_km,
+  COALESCE(st.so_luong_tour, 0)::INT AS so_luong_tour,
+  COUNT(*) OVER ()::INT AS tong
+FROM dd
+LEFT JOIN so_tour st ON st.diem_den_id = dd.id
+WHERE (sqlc.narg('ban_kinh_km')::FLOAT IS NULL OR dd.khoang_cach_km <= sqlc.narg('ban_kinh_km')::FLOAT)
+  AND (sqlc.arg('chi_co_tour')::BOOLEAN = FALSE OR COALESCE(st.so_luong_tour, 0) > 0)
+ORDER BY dd.khoang_cach_km ASC, so_luong_tour DESC, dd.id ASC
+LIMIT sqlc.arg('limit');
@@ -118,9 +118,11 @@ ORDER BY lt.ngay_thu ASC, hd.thu_tu ASC NULLS LAST, hd.id ASC;
 INSERT INTO cau_hinh_nhom_tour (
     tour_id,
     so_nho_nhat,
-    so_lon_nhat
+    so_lon_nhat,
+    tuoi_tre_em_toi_thieu,
+    tuoi_tre_em_toi_da
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, COALESCE(sqlc.narg('tuoi_tre_em_toi_thieu')::int, 2), COALESCE(sqlc.narg('tuoi_tre_em_toi_da')::int, 11)
 )
 RETURNING *;
 
@@ -133,6 +135,8 @@ UPDATE cau_hinh_nhom_tour
 SET 
     so_nho_nhat = COALESCE(sqlc.narg('so_nho_nhat'), so_nho_nhat),
     so_lon_nhat = COALESCE(sqlc.narg('so_lon_nhat'), so_lon_nhat),
+    tuoi_tre_em_toi_thieu = COALESCE(sqlc.narg('tuoi_tre_em_toi_thieu'), tuoi_tre_em_toi_thieu),
+    tuoi_tre_em_toi_da = COALESCE(sqlc.narg('tuoi_tre_em_toi_da'), tuoi_tre_em_toi_da),
     ngay_cap_nhat = NOW()
 WHERE tour_id = $1
 RETURNING *;
@@ -0,0 +1,30 @@
+-- name: CreateJwtSigningKey :one
+INSERT INTO khoa_ky_jwt (kid, thuat_toan, khoa_rieng_ma_hoa, khoa_cong_khai, kich_hoat_luc)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetPublishedJwtSigningKeys :many
+-- Các khóa còn trong JWKS (chưa hết hạn), cũ trước
+SELECT * FROM khoa_ky_jwt
+WHERE het_han_luc IS NULL OR het_han_luc > NOW()
+ORDER BY kich_hoat_luc;
+
+-- name: RetireJwtSigningKeys :execrows
+-- Ngừng ký bằng các khóa kích hoạt trước khóa mới nhất đã có hiệu lực
+UPDATE khoa_ky_jwt
+SET ngung_ky_luc = sqlc.arg(ngung_ky_luc),
+    het_han_luc = sqlc.arg(het_han_luc)
+WHERE ngung_ky_luc IS NULL
+  AND kich_hoat_luc < sqlc.arg(ngung_ky_luc);
+
+-- name: DeleteExpiredJwtSigningKeys :execrows
+DELETE FROM khoa_ky_jwt
+WHERE het_han_luc IS NOT NULL AND het_han_luc < NOW() - INTERVAL '30 days';
+
+-- name: ExpireJwtSigningKeysExcept :execrows
+-- Xoay khóa khẩn cấp: gỡ ngay mọi khóa khác khỏi JWKS, token do chúng ký mất hiệu lực
+UPDATE khoa_ky_jwt
+SET ngung_ky_luc = COALESCE(ngung_ky_luc, NOW()),
+    het_han_luc = NOW()
+WHERE kid <> $1
+  AND (het_han_luc IS NULL OR het_han_luc > NOW());
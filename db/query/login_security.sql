@@ -0,0 +1,45 @@
+-- ===========================================
+-- LỊCH SỬ ĐĂNG NHẬP
+-- ===========================================
+
+-- name: CreateLoginAttempt :one
+-- Ghi nhận một lần đăng nhập (quốc gia được cập nhật sau khi tra IP)
+INSERT INTO lich_su_dang_nhap (
+    nguoi_dung_id,
+    email,
+    dia_chi_ip,
+    thiet_bi,
+    thanh_cong,
+    ly_do_that_bai
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: UpdateLoginAttemptLocation :exec
+UPDATE lich_su_dang_nhap
+SET quoc_gia = $2,
+    ma_quoc_gia = $3,
+    thanh_pho = $4,
+    dang_ngo = $5
+WHERE id = $1;
+
+-- name: GetLoginCountryHistory :one
+-- Số lần đăng nhập thành công trước đó đã xác định được quốc gia, và số lần từ quốc gia đang xét
+SELECT
+    COUNT(*) FILTER (WHERE ma_quoc_gia IS NOT NULL)::int AS so_lan_co_quoc_gia,
+    COUNT(*) FILTER (WHERE ma_quoc_gia = sqlc.arg('ma_quoc_gia'))::int AS so_lan_cung_quoc_gia
+FROM lich_su_dang_nhap
+WHERE nguoi_dung_id = sqlc.arg('nguoi_dung_id')
+    AND thanh_cong
+    AND id <> sqlc.arg('ngoai_tru_id');
+
+-- name: GetLoginHistoryByUser :many
+SELECT * FROM lich_su_dang_nhap
+WHERE nguoi_dung_id = $1
+ORDER BY ngay_tao DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountLoginHistoryByUser :one
+SELECT COUNT(*)::int FROM lich_su_dang_nhap
+WHERE nguoi_dung_id = $1;
@@ -0,0 +1,53 @@
+-- ===========================================
+-- TÀI KHOẢN MẠNG XÃ HỘI (OAUTH)
+-- ===========================================
+
+-- name: GetOAuthAccount :one
+SELECT * FROM tai_khoan_oauth
+WHERE nha_cung_cap = $1 AND nha_cung_cap_id = $2;
+
+-- name: GetOAuthAccountsByUser :many
+SELECT id, nha_cung_cap, email, ten_hien_thi, anh_dai_dien, lan_dang_nhap_cuoi, ngay_tao
+FROM tai_khoan_oauth
+WHERE nguoi_dung_id = $1
+ORDER BY ngay_tao;
+
+-- name: CountOAuthAccountsByUser :one
+SELECT COUNT(*)::int FROM tai_khoan_oauth
+WHERE nguoi_dung_id = $1;
+
+-- name: CreateOAuthAccount :one
+INSERT INTO tai_khoan_oauth (
+    nguoi_dung_id,
+    nha_cung_cap,
+    nha_cung_cap_id,
+    email,
+    ten_hien_thi,
+    anh_dai_dien,
+    lan_dang_nhap_cuoi
+) VALUES (
+    $1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP
+)
+RETURNING *;
+
+-- name: TouchOAuthAccount :exec
+-- Cập nhật hồ sơ provider mới nhất mỗi lần đăng nhập
+UPDATE tai_khoan_oauth
+SET email = COALESCE(sqlc.narg(email), email),
+    ten_hien_thi = COALESCE(sqlc.narg(ten_hien_thi), ten_hien_thi),
+    anh_dai_dien = COALESCE(sqlc.narg(anh_dai_dien), anh_dai_dien),
+    lan_dang_nhap_cuoi = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteOAuthAccount :execrows
+DELETE FROM tai_khoan_oauth
+WHERE nguoi_dung_id = $1 AND nha_cung_cap = $2;
+
+-- name: DeleteUserOAuthAccounts :exec
+DELETE FROM tai_khoan_oauth WHERE nguoi_dung_id = $1;
+
+-- name: MarkUserWithoutPassword :exec
+-- Tài khoản tạo từ đăng nhập mạng xã hội chỉ có mật khẩu ngẫu nhiên
+UPDATE nguoi_dung
+SET co_mat_khau = FALSE
+WHERE id = $1;
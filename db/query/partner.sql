@@ -0,0 +1,133 @@
+-- ===========================================
+-- ĐỐI TÁC (PARTNER) & KHÓA API
+-- ===========================================
+
+-- name: CreatePartner :one
+INSERT INTO doi_tac (
+    ten,
+    email_lien_he,
+    so_dien_thoai,
+    ti_le_hoa_hong,
+    nguoi_dung_id,
+    nguoi_tao
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetPartnerByID :one
+SELECT * FROM doi_tac
+WHERE id = $1;
+
+-- name: ListPartners :many
+SELECT
+    dt.*,
+    (SELECT COUNT(*) FROM khoa_api k WHERE k.doi_tac_id = dt.id AND k.da_thu_hoi_luc IS NULL)::int AS so_khoa_hieu_luc
+FROM doi_tac dt
+ORDER BY dt.ngay_tao DESC, dt.id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountPartners :one
+SELECT COUNT(*)::int FROM doi_tac;
+
+-- name: UpdatePartner :one
+UPDATE doi_tac
+SET ten = COALESCE(sqlc.narg(ten), ten),
+    email_lien_he = COALESCE(sqlc.narg(email_lien_he), email_lien_he),
+    so_dien_thoai = COALESCE(sqlc.narg(so_dien_thoai), so_dien_thoai),
+    ti_le_hoa_hong = COALESCE(sqlc.narg(ti_le_hoa_hong), ti_le_hoa_hong),
+    dang_hoat_dong = COALESCE(sqlc.narg(dang_hoat_dong), dang_hoat_dong),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateApiKey :one
+INSERT INTO khoa_api (
+    doi_tac_id,
+    ten,
+    tien_to,
+    ma_bam,
+    pham_vi,
+    gioi_han_moi_phut,
+    het_han,
+    nguoi_tao
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetApiKeysByPartner :many
+-- Không trả về ma_bam
+SELECT id, doi_tac_id, ten, tien_to, pham_vi, gioi_han_moi_phut, so_lan_goi, lan_dung_cuoi, het_han, da_thu_hoi_luc, nguoi_tao, ngay_tao
+FROM khoa_api
+WHERE doi_tac_id = $1
+ORDER BY ngay_tao DESC, id DESC;
+
+-- name: GetApiKeyForAuth :one
+-- Tra khóa API theo mã băm khi xác thực request; chỉ khóa chưa thu hồi, chưa hết hạn của đối tác đang hoạt động
+SELECT
+    k.id,
+    k.doi_tac_id,
+    k.pham_vi,
+    k.gioi_han_moi_phut,
+    dt.nguoi_dung_id,
+    nd.email
+FROM khoa_api k
+JOIN doi_tac dt ON dt.id = k.doi_tac_id
+JOIN nguoi_dung nd ON nd.id = dt.nguoi_dung_id
+WHERE k.ma_bam = $1
+    AND k.da_thu_hoi_luc IS NULL
+    AND (k.het_han IS NULL OR k.het_han > CURRENT_TIMESTAMP)
+    AND dt.dang_hoat_dong = TRUE;
+
+-- name: RevokeApiKey :one
+UPDATE khoa_api
+SET da_thu_hoi_luc = CURRENT_TIMESTAMP
+WHERE id = $1 AND doi_tac_id = $2 AND da_thu_hoi_luc IS NULL
+RETURNING id, doi_tac_id, ten, tien_to, pham_vi, gioi_han_moi_phut, so_lan_goi, lan_dung_cuoi, het_han, da_thu_hoi_luc, nguoi_tao, ngay_tao;
+
+-- name: TouchApiKey :exec
+-- Cộng dồn số lượt gọi đã gom theo lô
+UPDATE khoa_api
+SET so_lan_goi = so_lan_goi + sqlc.arg(so_lan_goi)::int,
+    lan_dung_cuoi = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: IncrementApiKeyDailyUsage :exec
+INSERT INTO su_dung_khoa_api (khoa_api_id, ngay, so_lan_goi)
+VALUES (sqlc.arg(khoa_api_id), CURRENT_DATE, sqlc.arg(so_lan_goi)::int)
+ON CONFLICT (khoa_api_id, ngay)
+DO UPDATE SET so_lan_goi = su_dung_khoa_api.so_lan_goi + EXCLUDED.so_lan_goi;
+
+-- name: GetApiKeyDailyUsage :many
+SELECT sd.ngay, sd.so_lan_goi
+FROM su_dung_khoa_api sd
+JOIN khoa_api k ON k.id = sd.khoa_api_id
+WHERE sd.khoa_api_id = sqlc.arg(khoa_api_id)
+    AND k.doi_tac_id = sqlc.arg(doi_tac_id)
+    AND sd.ngay >= sqlc.arg(tu_ngay)::date
+ORDER BY sd.ngay DESC;
+
+-- name: AttributeBookingToPartner :exec
+UPDATE dat_cho
+SET doi_tac_id = $2,
+    khoa_api_id = $3
+WHERE id = $1;
+
+-- name: GetPartnerCommissionReport :many
+-- Doanh thu và hoa hồng của đối tác từ các booking đã thanh toán / hoàn thành trong khoảng thời gian
+SELECT
+    dt.id AS doi_tac_id,
+    dt.ten,
+    dt.ti_le_hoa_hong,
+    COUNT(dc.id)::int AS so_booking,
+    COALESCE(SUM(dc.tong_tien), 0)::numeric AS doanh_thu,
+    ROUND(COALESCE(SUM(dc.tong_tien), 0) * dt.ti_le_hoa_hong / 100, 2)::numeric AS hoa_hong
+FROM doi_tac dt
+LEFT JOIN dat_cho dc ON dc.doi_tac_id = dt.id
+    AND dc.trang_thai IN ('da_thanh_toan', 'hoan_thanh')
+    AND dc.ngay_dat >= sqlc.arg(tu_ngay)
+    AND dc.ngay_dat < sqlc.arg(den_ngay)
+WHERE (sqlc.narg(doi_tac_id)::int IS NULL OR dt.id = sqlc.narg(doi_tac_id))
+GROUP BY dt.id
+ORDER BY doanh_thu DESC, dt.id;
@@ -100,3 +100,55 @@ WHERE lsgd.cong_thanh_toan_id = $1
 ORDER BY lsgd.ngay_tao DESC
 LIMIT $2 OFFSET $3;
 
+
+-- name: ConfirmTransactionPayment :one
+-- Xác nhận giao dịch thanh toán thành công và chuyển booking sang da_thanh_toan trong cùng một câu lệnh
+-- Idempotent: giao dịch đã thanh_cong sẽ không trả về dòng nào (pgx.ErrNoRows)
+WITH gd AS (
+    UPDATE lich_su_giao_dich
+    SET trang_thai = 'thanh_cong',
+        ma_tham_chieu_cong_thanh_toan = COALESCE(sqlc.narg('ma_tham_chieu')::varchar, ma_tham_chieu_cong_thanh_toan),
+        ngay_hoan_thanh = CURRENT_TIMESTAMP
+    WHERE id = sqlc.arg('id')::int
+        AND trang_thai IS DISTINCT FROM 'thanh_cong'
+    RETURNING *
+), dc AS (
+    UPDATE dat_cho
+    SET trang_thai = 'da_thanh_toan',
+        phuong_thuc_thanh_toan = sqlc.arg('phuong_thuc_thanh_toan')::varchar,
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    FROM gd
+    WHERE dat_cho.id = gd.dat_cho_id
+        AND dat_cho.trang_thai IN ('cho_xac_nhan', 'da_xac_nhan')
+    RETURNING dat_cho.id
+)
+SELECT
+    gd.id,
+    gd.dat_cho_id,
+    gd.ma_giao_dich_noi_bo,
+    EXISTS (SELECT 1 FROM dc) AS da_cap_nhat_dat_cho
+FROM gd;
+
+-- name: FailPendingTransaction :one
+-- Đánh dấu giao dịch thất bại nếu chưa thành công (không ghi đè giao dịch đã thanh_cong)
+UPDATE lich_su_giao_dich
+SET trang_thai = 'that_bai',
+    ma_tham_chieu_cong_thanh_toan = COALESCE(sqlc.narg('ma_tham_chieu')::varchar, ma_tham_chieu_cong_thanh_toan)
+WHERE id = sqlc.arg('id')::int
+    AND trang_thai IN ('dang_cho_thanh_toan', 'dang_xuly')
+RETURNING *;
+
+-- name: SetTransactionGatewayReference :one
+-- Lưu mã tham chiếu của cổng thanh toán (vd: Stripe PaymentIntent ID) khi giao dịch còn chờ
+UPDATE lich_su_giao_dich
+SET ma_tham_chieu_cong_thanh_toan = sqlc.arg('ma_tham_chieu')::varchar
+WHERE id = sqlc.arg('id')::int
+RETURNING *;
+
+-- name: CancelPendingBookingTransactions :execrows
+-- Hủy các giao dịch thanh toán đang chờ của booking (vd: tổng tiền booking thay đổi)
+UPDATE lich_su_giao_dich
+SET trang_thai = 'da_huy'
+WHERE dat_cho_id = $1
+    AND trang_thai = 'dang_cho_thanh_toan'
+    AND COALESCE(loai_giao_dich, 'thanh_toan') = 'thanh_toan';
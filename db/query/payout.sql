@@ -0,0 +1,286 @@
+-- ===========================================
+-- CHI TRẢ NHÀ CUNG CẤP (SUPPLIER PAYOUT)
+-- ===========================================
+
+-- name: UpsertSupplierPayouts :many
+-- Tính lại khoản chi trả cho từng lịch khởi hành có booking đã thanh toán
+-- Phí cổng thanh toán lấy theo cổng của giao dịch thành công (hoặc phương thức thanh toán của booking)
+-- Chỉ cập nhật các khoản chưa được duyệt (cho_chi_tra, san_sang)
+WITH thanh_toan AS (
+    SELECT
+        dc.id,
+        dc.khoi_hanh_id,
+        dc.tong_tien,
+        dc.tong_tien * COALESCE(ctt.phi_giao_dich_phan_tram, 0) / 100 AS phi_cong
+    FROM dat_cho dc
+    LEFT JOIN LATERAL (
+        SELECT lsgd.cong_thanh_toan_id
+        FROM lich_su_giao_dich lsgd
+        WHERE lsgd.dat_cho_id = dc.id
+            AND lsgd.trang_thai = 'thanh_cong'
+            AND COALESCE(lsgd.loai_giao_dich, 'thanh_toan') = 'thanh_toan'
+        ORDER BY lsgd.ngay_hoan_thanh DESC NULLS LAST, lsgd.id DESC
+        LIMIT 1
+    ) gd ON TRUE
+    LEFT JOIN cong_thanh_toan ctt ON ctt.id = COALESCE(gd.cong_thanh_toan_id, dc.phuong_thuc_thanh_toan)
+    WHERE dc.trang_thai IN ('da_thanh_toan', 'hoan_thanh')
+), tong_hop AS (
+    SELECT
+        kh.id AS khoi_hanh_id,
+        t.nha_cung_cap_id,
+        COUNT(tt.id)::int AS so_dat_cho,
+        SUM(tt.tong_tien) AS tong_doanh_thu,
+        SUM(tt.phi_cong) AS phi_cong_thanh_toan,
+        BOOL_OR(kh.trang_thai = 'hoan_thanh' OR kh.ngay_ket_thuc < CURRENT_DATE) AS da_hoan_thanh
+    FROM thanh_toan tt
+    JOIN khoi_hanh_tour kh ON kh.id = tt.khoi_hanh_id
+    JOIN tour t ON t.id = kh.tour_id
+    WHERE kh.trang_thai <> 'huy'
+        AND t.nha_cung_cap_id IS NOT NULL
+    GROUP BY kh.id, t.nha_cung_cap_id
+)
+INSERT INTO chi_tra_nha_cung_cap (
+    nha_cung_cap_id,
+    khoi_hanh_id,
+    so_dat_cho,
+    tong_doanh_thu,
+    phi_cong_thanh_toan,
+    ty_le_hoa_hong,
+    phi_hoa_hong,
+    so_tien_thuc_nhan,
+    trang_thai
+)
+SELECT
+    th.nha_cung_cap_id,
+    th.khoi_hanh_id,
+    th.so_dat_cho,
+    ROUND(th.tong_doanh_thu, 2),
+    ROUND(th.phi_cong_thanh_toan, 2),
+    sqlc.arg('ty_le_hoa_hong')::numeric,
+    ROUND(th.tong_doanh_thu * sqlc.arg('ty_le_hoa_hong')::numeric / 100, 2),
+    ROUND(th.tong_doanh_thu - th.phi_cong_thanh_toan - th.tong_doanh_thu * sqlc.arg('ty_le_hoa_hong')::numeric / 100, 2),
+    (CASE WHEN th.da_hoan_thanh THEN 'san_sang' ELSE 'cho_chi_tra' END)::trang_thai_chi_tra
+FROM tong_hop th
+ON CONFLICT (khoi_hanh_id) DO UPDATE SET
+    so_dat_cho = EXCLUDED.so_dat_cho,
+    tong_doanh_thu = EXCLUDED.tong_doanh_thu,
+    phi_cong_thanh_toan = EXCLUDED.phi_cong_thanh_toan,
+    ty_le_hoa_hong = EXCLUDED.ty_le_hoa_hong,
+    phi_hoa_hong = EXCLUDED.phi_hoa_hong,
+    so_tien_thuc_nhan = EXCLUDED.so_tien_thuc_nhan,
+    trang_thai = EXCLUDED.trang_thai,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE chi_tra_nha_cung_cap.trang_thai IN ('cho_chi_tra', 'san_sang')
+    AND (
+        chi_tra_nha_cung_cap.so_dat_cho,
+        chi_tra_nha_cung_cap.tong_doanh_thu,
+        chi_tra_nha_cung_cap.phi_cong_thanh_toan,
+        chi_tra_nha_cung_cap.ty_le_hoa_hong,
+        chi_tra_nha_cung_cap.trang_thai
+    ) IS DISTINCT FROM (
+        EXCLUDED.so_dat_cho,
+        EXCLUDED.tong_doanh_thu,
+        EXCLUDED.phi_cong_thanh_toan,
+        EXCLUDED.ty_le_hoa_hong,
+        EXCLUDED.trang_thai
+    )
+RETURNING *;
+
+-- name: CancelStaleSupplierPayouts :many
+-- Hủy các khoản chi trả chưa duyệt khi khởi hành bị hủy hoặc không còn booking đã thanh toán
+UPDATE chi_tra_nha_cung_cap ct
+SET trang_thai = 'da_huy',
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM khoi_hanh_tour kh
+WHERE kh.id = ct.khoi_hanh_id
+    AND ct.trang_thai IN ('cho_chi_tra', 'san_sang')
+    AND (
+        kh.trang_thai = 'huy'
+        OR NOT EXISTS (
+            SELECT 1 FROM dat_cho dc
+            WHERE dc.khoi_hanh_id = kh.id
+                AND dc.trang_thai IN ('da_thanh_toan', 'hoan_thanh')
+        )
+    )
+RETURNING ct.*;
+
+-- name: GetPayouts :many
+-- Lấy danh sách khoản chi trả (dành cho Admin) với filter trạng thái và nhà cung cấp
+SELECT
+    ct.*,
+    ncc.ten AS ten_nha_cung_cap,
+    t.id AS tour_id,
+    t.tieu_de AS ten_tour,
+    kh.ngay_khoi_hanh,
+    kh.ngay_ket_thuc,
+    tk.ten_ngan_hang,
+    tk.so_tai_khoan,
+    tk.ten_chu_tai_khoan
+FROM chi_tra_nha_cung_cap ct
+JOIN nha_cung_cap ncc ON ncc.id = ct.nha_cung_cap_id
+JOIN khoi_hanh_tour kh ON kh.id = ct.khoi_hanh_id
+JOIN tour t ON t.id = kh.tour_id
+LEFT JOIN tai_khoan_ngan_hang tk ON tk.id = COALESCE(
+    ct.tai_khoan_ngan_hang_id,
+    (SELECT tkm.id FROM tai_khoan_ngan_hang tkm WHERE tkm.nha_cung_cap_id = ct.nha_cung_cap_id AND tkm.la_mac_dinh = TRUE LIMIT 1)
+)
+WHERE (sqlc.narg('trang_thai')::trang_thai_chi_tra IS NULL OR ct.trang_thai = sqlc.narg('trang_thai')::trang_thai_chi_tra)
+    AND (sqlc.narg('nha_cung_cap_id')::uuid IS NULL OR ct.nha_cung_cap_id = sqlc.narg('nha_cung_cap_id')::uuid)
+ORDER BY kh.ngay_ket_thuc DESC, ct.id DESC
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountPayouts :one
+-- Đếm tổng số khoản chi trả theo filter
+SELECT COUNT(*)
+FROM chi_tra_nha_cung_cap ct
+WHERE (sqlc.narg('trang_thai')::trang_thai_chi_tra IS NULL OR ct.trang_thai = sqlc.narg('trang_thai')::trang_thai_chi_tra)
+    AND (sqlc.narg('nha_cung_cap_id')::uuid IS NULL OR ct.nha_cung_cap_id = sqlc.narg('nha_cung_cap_id')::uuid);
+
+-- name: ApprovePayouts :many
+-- Duyệt chi trả hàng loạt: san_sang/that_bai -> dang_xu_ly
+-- Chỉ duyệt các nhà cung cấp đã có tài khoản ngân hàng mặc định (lưu lại tài khoản tại thời điểm duyệt)
+UPDATE chi_tra_nha_cung_cap ct
+SET trang_thai = 'dang_xu_ly',
+    tai_khoan_ngan_hang_id = tk.id,
+    nguoi_duyet_id = sqlc.arg('nguoi_duyet_id')::uuid,
+    ngay_duyet = CURRENT_TIMESTAMP,
+    ghi_chu = COALESCE(sqlc.narg('ghi_chu')::text, ct.ghi_chu),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM tai_khoan_ngan_hang tk
+WHERE tk.nha_cung_cap_id = ct.nha_cung_cap_id
+    AND tk.la_mac_dinh = TRUE
+    AND ct.id = ANY(sqlc.arg('ids')::int[])
+    AND ct.trang_thai IN ('san_sang', 'that_bai')
+RETURNING ct.*;
+
+-- name: HoldPayouts :many
+-- Giữ lại chi trả hàng loạt (tranh chấp): các khoản chưa chi trả -> da_giu
+UPDATE chi_tra_nha_cung_cap
+SET trang_thai = 'da_giu',
+    nguoi_duyet_id = sqlc.arg('nguoi_duyet_id')::uuid,
+    ghi_chu = COALESCE(sqlc.narg('ghi_chu')::text, ghi_chu),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = ANY(sqlc.arg('ids')::int[])
+    AND trang_thai IN ('cho_chi_tra', 'san_sang', 'dang_xu_ly', 'that_bai')
+RETURNING *;
+
+-- name: ReleasePayouts :many
+-- Bỏ giữ chi trả: da_giu -> san_sang (nếu khởi hành đã hoàn thành) hoặc cho_chi_tra
+UPDATE chi_tra_nha_cung_cap ct
+SET trang_thai = (CASE
+        WHEN kh.trang_thai = 'hoan_thanh' OR kh.ngay_ket_thuc < CURRENT_DATE THEN 'san_sang'
+        ELSE 'cho_chi_tra'
+    END)::trang_thai_chi_tra,
+    nguoi_duyet_id = sqlc.arg('nguoi_duyet_id')::uuid,
+    ghi_chu = COALESCE(sqlc.narg('ghi_chu')::text, ct.ghi_chu),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM khoi_hanh_tour kh
+WHERE kh.id = ct.khoi_hanh_id
+    AND ct.id = ANY(sqlc.arg('ids')::int[])
+    AND ct.trang_thai = 'da_giu'
+RETURNING ct.*;
+
+-- name: MarkPayoutsPaid :many
+-- Đánh dấu đã chi trả: dang_xu_ly -> da_chi_tra
+UPDATE chi_tra_nha_cung_cap
+SET trang_thai = 'da_chi_tra',
+    ma_tham_chieu_chi_tra = COALESCE(sqlc.narg('ma_tham_chieu_chi_tra')::varchar, ma_tham_chieu_chi_tra),
+    ghi_chu = COALESCE(sqlc.narg('ghi_chu')::text, ghi_chu),
+    ngay_chi_tra = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = ANY(sqlc.arg('ids')::int[])
+    AND trang_thai = 'dang_xu_ly'
+RETURNING *;
+
+-- name: MarkPayoutsFailed :many
+-- Đánh dấu chi trả thất bại: dang_xu_ly -> that_bai (có thể duyệt lại)
+UPDATE chi_tra_nha_cung_cap
+SET trang_thai = 'that_bai',
+    ghi_chu = COALESCE(sqlc.narg('ghi_chu')::text, ghi_chu),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = ANY(sqlc.arg('ids')::int[])
+    AND trang_thai = 'dang_xu_ly'
+RETURNING *;
+
+-- name: GetSupplierPayouts :many
+-- Lịch sử chi trả của nhà cung cấp
+SELECT
+    ct.*,
+    t.id AS tour_id,
+    t.tieu_de AS ten_tour,
+    kh.ngay_khoi_hanh,
+    kh.ngay_ket_thuc,
+    tk.ten_ngan_hang,
+    tk.so_tai_khoan
+FROM chi_tra_nha_cung_cap ct
+JOIN khoi_hanh_tour kh ON kh.id = ct.khoi_hanh_id
+JOIN tour t ON t.id = kh.tour_id
+LEFT JOIN tai_khoan_ngan_hang tk ON tk.id = ct.tai_khoan_ngan_hang_id
+WHERE ct.nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')::uuid
+    AND (sqlc.narg('trang_thai')::trang_thai_chi_tra IS NULL OR ct.trang_thai = sqlc.narg('trang_thai')::trang_thai_chi_tra)
+ORDER BY kh.ngay_ket_thuc DESC, ct.id DESC
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountSupplierPayouts :one
+-- Đếm số khoản chi trả của nhà cung cấp
+SELECT COUNT(*)
+FROM chi_tra_nha_cung_cap
+WHERE nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')::uuid
+    AND (sqlc.narg('trang_thai')::trang_thai_chi_tra IS NULL OR trang_thai = sqlc.narg('trang_thai')::trang_thai_chi_tra);
+
+-- name: GetSupplierPayoutSummary :one
+-- Tổng hợp số tiền chi trả của nhà cung cấp theo trạng thái
+SELECT
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'cho_chi_tra'), 0)::numeric AS cho_chi_tra,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'san_sang'), 0)::numeric AS san_sang,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'dang_xu_ly'), 0)::numeric AS dang_xu_ly,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'da_chi_tra'), 0)::numeric AS da_chi_tra,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'da_giu'), 0)::numeric AS da_giu,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'that_bai'), 0)::numeric AS that_bai,
+    COALESCE(SUM(tong_doanh_thu) FILTER (WHERE trang_thai <> 'da_huy'), 0)::numeric AS tong_doanh_thu,
+    COALESCE(SUM(phi_cong_thanh_toan) FILTER (WHERE trang_thai <> 'da_huy'), 0)::numeric AS tong_phi_cong_thanh_toan,
+    COALESCE(SUM(phi_hoa_hong) FILTER (WHERE trang_thai <> 'da_huy'), 0)::numeric AS tong_phi_hoa_hong
+FROM chi_tra_nha_cung_cap
+WHERE nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')::uuid;
+
+-- ===========================================
+-- TÀI KHOẢN NGÂN HÀNG NHÀ CUNG CẤP
+-- ===========================================
+
+-- name: GetSupplierBankAccounts :many
+-- Lấy danh sách tài khoản ngân hàng của nhà cung cấp (tài khoản mặc định lên đầu)
+SELECT * FROM tai_khoan_ngan_hang
+WHERE nha_cung_cap_id = $1
+ORDER BY la_mac_dinh DESC, id ASC;
+
+-- name: CreateBankAccount :one
+-- Thêm tài khoản ngân hàng cho nhà cung cấp
+INSERT INTO tai_khoan_ngan_hang (
+    nha_cung_cap_id,
+    ten_ngan_hang,
+    so_tai_khoan,
+    ten_chu_tai_khoan,
+    chi_nhanh,
+    la_mac_dinh
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ClearDefaultBankAccount :exec
+-- Bỏ đánh dấu mặc định cho tất cả tài khoản của nhà cung cấp
+UPDATE tai_khoan_ngan_hang
+SET la_mac_dinh = FALSE
+WHERE nha_cung_cap_id = $1 AND la_mac_dinh = TRUE;
+
+-- name: SetDefaultBankAccount :one
+-- Đặt tài khoản làm mặc định (gọi sau ClearDefaultBankAccount trong cùng transaction)
+UPDATE tai_khoan_ngan_hang
+SET la_mac_dinh = TRUE
+WHERE id = $1 AND nha_cung_cap_id = $2
+RETURNING *;
+
+-- name: DeleteBankAccount :one
+-- Xóa tài khoản ngân hàng của nhà cung cấp
+DELETE FROM tai_khoan_ngan_hang
+WHERE id = $1 AND nha_cung_cap_id = $2
+RETURNING *;
@@ -0,0 +1,110 @@
+-- ===========================================
+-- PHÂN QUYỀN: QUYỀN, VAI TRÒ, GÁN VAI TRÒ
+-- ===========================================
+
+-- name: GetAllPermissions :many
+SELECT * FROM quyen
+ORDER BY nhom, ma;
+
+-- name: GetUnknownPermissions :many
+-- Các mã quyền trong danh sách không tồn tại trong danh mục
+SELECT p::text AS ma
+FROM unnest(sqlc.arg('quyen')::text[]) AS p
+WHERE NOT EXISTS (SELECT 1 FROM quyen q WHERE q.ma = p);
+
+-- name: GetRolesWithPermissions :many
+SELECT
+    v.*,
+    COALESCE(
+        array_agg(vq.quyen_ma ORDER BY vq.quyen_ma) FILTER (WHERE vq.quyen_ma IS NOT NULL),
+        '{}'
+    )::text[] AS quyen,
+    (SELECT COUNT(*) FROM nguoi_dung_vai_tro nv WHERE nv.vai_tro_ma = v.ma)::int AS so_nguoi_dung
+FROM vai_tro v
+LEFT JOIN vai_tro_quyen vq ON vq.vai_tro_ma = v.ma
+GROUP BY v.ma
+ORDER BY v.he_thong DESC, v.ma;
+
+-- name: GetRoleByCode :one
+SELECT * FROM vai_tro
+WHERE ma = $1;
+
+-- name: CreateRole :one
+INSERT INTO vai_tro (ma, ten, mo_ta)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UpdateRole :one
+UPDATE vai_tro
+SET ten = COALESCE(sqlc.narg('ten'), ten),
+    mo_ta = COALESCE(sqlc.narg('mo_ta'), mo_ta),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE ma = sqlc.arg('ma')
+RETURNING *;
+
+-- name: DeleteRole :execrows
+-- Chỉ xóa được vai trò tự tạo, vai trò hệ thống giữ nguyên
+DELETE FROM vai_tro
+WHERE ma = $1 AND NOT he_thong;
+
+-- name: GetRolePermissionCodes :many
+SELECT quyen_ma FROM vai_tro_quyen
+WHERE vai_tro_ma = $1
+ORDER BY quyen_ma;
+
+-- name: DeleteRolePermissions :exec
+DELETE FROM vai_tro_quyen
+WHERE vai_tro_ma = $1;
+
+-- name: AddRolePermissions :exec
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma)
+SELECT sqlc.arg('vai_tro_ma'), unnest(sqlc.arg('quyen')::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetUserPermissions :many
+-- Quyền hiệu lực: vai trò hệ thống theo enum vai_tro của người dùng + các vai trò được gán thêm.
+-- Nhân viên nhà cung cấp không nhận quyền của vai trò hệ thống mà dùng quyền được chủ sở hữu cấp
+SELECT DISTINCT p.quyen_ma::text AS quyen_ma
+FROM (
+    SELECT vq.quyen_ma::text AS quyen_ma
+    FROM vai_tro_quyen vq
+    WHERE (
+            vq.vai_tro_ma = sqlc.arg('vai_tro')::text
+            AND NOT EXISTS (
+                SELECT 1 FROM nhan_vien_nha_cung_cap nv
+                WHERE nv.nguoi_dung_id = sqlc.arg('nguoi_dung_id')
+            )
+        )
+        OR vq.vai_tro_ma IN (
+            SELECT nv.vai_tro_ma FROM nguoi_dung_vai_tro nv
+            WHERE nv.nguoi_dung_id = sqlc.arg('nguoi_dung_id')
+        )
+    UNION
+    SELECT unnest(nv.quyen)::text AS quyen_ma
+    FROM nhan_vien_nha_cung_cap nv
+    WHERE nv.nguoi_dung_id = sqlc.arg('nguoi_dung_id')
+        AND nv.trang_thai = 'hoat_dong'
+) p
+ORDER BY quyen_ma;
+
+-- name: GetUserRoles :many
+-- Các vai trò được gán thêm cho người dùng
+SELECT v.*, nv.nguoi_gan, nv.ngay_tao AS ngay_gan
+FROM nguoi_dung_vai_tro nv
+JOIN vai_tro v ON v.ma = nv.vai_tro_ma
+WHERE nv.nguoi_dung_id = $1
+ORDER BY v.ma;
+
+-- name: GetUnknownRoles :many
+SELECT r::text AS ma
+FROM unnest(sqlc.arg('vai_tro')::text[]) AS r
+WHERE NOT EXISTS (SELECT 1 FROM vai_tro v WHERE v.ma = r);
+
+-- name: DeleteUserRoles :exec
+DELETE FROM nguoi_dung_vai_tro
+WHERE nguoi_dung_id = $1;
+
+-- name: AddUserRoles :exec
+INSERT INTO nguoi_dung_vai_tro (nguoi_dung_id, vai_tro_ma, nguoi_gan)
+SELECT sqlc.arg('nguoi_dung_id'), unnest(sqlc.arg('vai_tro')::text[]), sqlc.narg('nguoi_gan')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,163 @@
+-- ===========================================
+-- THỰC HIỆN HOÀN TIỀN (REFUND EXECUTION)
+-- ===========================================
+
+-- name: GetPaidTransactionByBooking :one
+-- Lấy giao dịch thanh toán thành công gần nhất của booking (giao dịch gốc để hoàn tiền)
+SELECT * FROM lich_su_giao_dich
+WHERE dat_cho_id = $1
+    AND trang_thai = 'thanh_cong'
+    AND COALESCE(loai_giao_dich, 'thanh_toan') = 'thanh_toan'
+ORDER BY ngay_hoan_thanh DESC NULLS LAST, id DESC
+LIMIT 1;
+
+-- name: CreateRefundTransaction :one
+-- Tạo yêu cầu hoàn tiền (chờ admin duyệt) cho giao dịch thanh toán gốc
+-- Giao dịch gốc đã có khoản hoàn tiền còn hiệu lực thì bỏ qua (pgx.ErrNoRows)
+INSERT INTO lich_su_giao_dich (
+    dat_cho_id,
+    nguoi_dung_id,
+    ma_giao_dich_noi_bo,
+    cong_thanh_toan_id,
+    so_tien,
+    loai_giao_dich,
+    trang_thai,
+    giao_dich_goc_id,
+    ly_do
+)
+SELECT
+    g.dat_cho_id,
+    g.nguoi_dung_id,
+    sqlc.arg('ma_giao_dich_noi_bo')::varchar,
+    g.cong_thanh_toan_id,
+    LEAST(sqlc.arg('so_tien')::numeric, g.so_tien),
+    'hoan_tien',
+    'dang_xuly',
+    g.id,
+    sqlc.narg('ly_do')::text
+FROM lich_su_giao_dich g
+WHERE g.id = sqlc.arg('giao_dich_goc_id')::int
+ON CONFLICT (giao_dich_goc_id) WHERE loai_giao_dich = 'hoan_tien' AND trang_thai <> 'da_huy' DO NOTHING
+RETURNING *;
+
+-- name: GetRefundTransactionByID :one
+-- Lấy khoản hoàn tiền kèm thông tin giao dịch thanh toán gốc
+SELECT
+    ht.*,
+    g.ma_giao_dich_noi_bo AS ma_giao_dich_goc,
+    g.ma_tham_chieu_cong_thanh_toan AS ma_tham_chieu_goc,
+    g.so_tien AS so_tien_goc,
+    g.ngay_hoan_thanh AS ngay_thanh_toan_goc
+FROM lich_su_giao_dich ht
+JOIN lich_su_giao_dich g ON g.id = ht.giao_dich_goc_id
+WHERE ht.id = $1
+    AND ht.loai_giao_dich = 'hoan_tien';
+
+-- name: GetRefundTransactions :many
+-- Danh sách khoản hoàn tiền (Admin), lọc theo trạng thái
+SELECT
+    ht.id,
+    ht.dat_cho_id,
+    ht.ma_giao_dich_noi_bo,
+    ht.ma_tham_chieu_cong_thanh_toan,
+    ht.cong_thanh_toan_id,
+    ht.so_tien,
+    ht.trang_thai,
+    ht.giao_dich_goc_id,
+    ht.ly_do,
+    ht.ghi_chu_xu_ly,
+    ht.nguoi_duyet_id,
+    ht.ngay_duyet,
+    ht.so_lan_thu,
+    ht.ngay_tao,
+    ht.ngay_hoan_thanh,
+    g.ma_giao_dich_noi_bo AS ma_giao_dich_goc,
+    g.so_tien AS so_tien_goc,
+    nd.ho_ten AS ten_nguoi_dung,
+    nd.email AS email_nguoi_dung,
+    t.id AS tour_id,
+    t.tieu_de AS ten_tour
+FROM lich_su_giao_dich ht
+LEFT JOIN lich_su_giao_dich g ON g.id = ht.giao_dich_goc_id
+LEFT JOIN nguoi_dung nd ON nd.id = ht.nguoi_dung_id
+LEFT JOIN dat_cho dc ON dc.id = ht.dat_cho_id
+LEFT JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
+LEFT JOIN tour t ON t.id = kh.tour_id
+WHERE ht.loai_giao_dich = 'hoan_tien'
+    AND (sqlc.narg('trang_thai')::trang_thai_thanh_toan IS NULL OR ht.trang_thai = sqlc.narg('trang_thai')::trang_thai_thanh_toan)
+ORDER BY ht.ngay_tao DESC, ht.id DESC
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountRefundTransactions :one
+SELECT COUNT(*) FROM lich_su_giao_dich
+WHERE loai_giao_dich = 'hoan_tien'
+    AND (sqlc.narg('trang_thai')::trang_thai_thanh_toan IS NULL OR trang_thai = sqlc.narg('trang_thai')::trang_thai_thanh_toan);
+
+-- name: ClaimRefundForApproval :one
+-- Admin duyệt khoản hoàn tiền đang chờ; chỉ một người duyệt được (nguoi_duyet_id còn trống)
+UPDATE lich_su_giao_dich
+SET nguoi_duyet_id = sqlc.arg('nguoi_duyet_id')::uuid,
+    ngay_duyet = CURRENT_TIMESTAMP,
+    so_lan_thu = so_lan_thu + 1,
+    ghi_chu_xu_ly = sqlc.narg('ghi_chu_xu_ly')::text
+WHERE id = sqlc.arg('id')::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND trang_thai = 'dang_xuly'
+    AND nguoi_duyet_id IS NULL
+RETURNING *;
+
+-- name: ClaimRefundForRetry :one
+-- Thử lại khoản hoàn tiền thất bại. Khoản đã duyệt nhưng bị treo quá 10 phút (vd: server dừng giữa chừng,
+-- hoặc cổng đã hoàn tiền nhưng cập nhật trạng thái lỗi) có thể đã được hoàn phía cổng,
+-- nên chỉ được nhận lại để đối soát thủ công (thu_cong = true), không gọi lại API hoàn tiền
+UPDATE lich_su_giao_dich
+SET trang_thai = 'dang_xuly',
+    nguoi_duyet_id = sqlc.arg('nguoi_duyet_id')::uuid,
+    ngay_duyet = CURRENT_TIMESTAMP,
+    so_lan_thu = so_lan_thu + 1
+WHERE id = sqlc.arg('id')::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND (
+        trang_thai = 'that_bai'
+        OR (
+            sqlc.arg('thu_cong')::boolean
+            AND trang_thai = 'dang_xuly'
+            AND nguoi_duyet_id IS NOT NULL
+            AND ngay_duyet < CURRENT_TIMESTAMP - INTERVAL '10 minutes'
+        )
+    )
+RETURNING *;
+
+-- name: CompleteRefund :one
+-- Ghi nhận hoàn tiền thành công (da_hoan_tien hoặc hoan_mot_phan)
+UPDATE lich_su_giao_dich
+SET trang_thai = sqlc.arg('trang_thai')::trang_thai_thanh_toan,
+    ma_tham_chieu_cong_thanh_toan = COALESCE(sqlc.narg('ma_tham_chieu')::varchar, ma_tham_chieu_cong_thanh_toan),
+    ghi_chu_xu_ly = COALESCE(sqlc.narg('ghi_chu_xu_ly')::text, ghi_chu_xu_ly),
+    ngay_hoan_thanh = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND trang_thai = 'dang_xuly'
+RETURNING *;
+
+-- name: FailRefund :one
+-- Ghi nhận cổng thanh toán từ chối/lỗi khi hoàn tiền
+UPDATE lich_su_giao_dich
+SET trang_thai = 'that_bai',
+    ghi_chu_xu_ly = sqlc.narg('ghi_chu_xu_ly')::text
+WHERE id = sqlc.arg('id')::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND trang_thai = 'dang_xuly'
+RETURNING *;
+
+-- name: RejectRefund :one
+-- Admin từ chối hoàn tiền (không áp dụng cho khoản đang được cổng thanh toán xử lý)
+UPDATE lich_su_giao_dich
+SET trang_thai = 'da_huy',
+    nguoi_duyet_id = sqlc.arg('nguoi_duyet_id')::uuid,
+    ngay_duyet = CURRENT_TIMESTAMP,
+    ghi_chu_xu_ly = sqlc.arg('ghi_chu_xu_ly')::text
+WHERE id = sqlc.arg('id')::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND (trang_thai = 'that_bai' OR (trang_thai = 'dang_xuly' AND nguoi_duyet_id IS NULL))
+RETURNING *;
@@ -0,0 +1,127 @@
+-- ===========================================
+-- GIỮ CHỖ CÓ THỜI HẠN (SEAT HOLD)
+-- ===========================================
+
+-- name: CreateSeatHold :one
+-- Giữ chỗ (tăng so_cho_da_dat qua hold_seat) và ghi nhận bản ghi giữ chỗ có thời hạn
+INSERT INTO giu_cho (
+    khoi_hanh_id,
+    nguoi_dung_id,
+    so_nguoi_lon,
+    so_tre_em,
+    het_han_luc
+)
+SELECT
+    sqlc.arg('khoi_hanh_id')::int,
+    sqlc.arg('nguoi_dung_id')::uuid,
+    sqlc.arg('so_nguoi_lon')::int,
+    sqlc.arg('so_tre_em')::int,
+    CURRENT_TIMESTAMP + (sqlc.arg('so_phut')::int * INTERVAL '1 minute')
+FROM hold_seat(sqlc.arg('khoi_hanh_id')::int, sqlc.arg('so_nguoi_lon')::int, sqlc.arg('so_tre_em')::int)
+RETURNING *;
+
+-- name: GetSeatHoldByCode :one
+-- Lấy thông tin giữ chỗ theo mã giữ chỗ
+SELECT * FROM giu_cho
+WHERE ma_giu_cho = $1;
+
+-- name: CancelSeatHold :one
+-- Người dùng tự hủy giữ chỗ đang hiệu lực và trả lại chỗ cho khởi hành
+WITH huy AS (
+    UPDATE giu_cho
+    SET trang_thai = 'da_huy',
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    WHERE ma_giu_cho = $1
+        AND nguoi_dung_id = $2
+        AND trang_thai = 'dang_giu'
+    RETURNING *
+), tra_cho AS (
+    UPDATE khoi_hanh_tour kh
+    SET so_cho_da_dat = GREATEST(kh.so_cho_da_dat - (huy.so_nguoi_lon + huy.so_tre_em), 0),
+        trang_thai = CASE WHEN kh.trang_thai = 'het_cho' THEN 'con_cho'::trang_thai_khoi_hanh ELSE kh.trang_thai END,
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    FROM huy
+    WHERE kh.id = huy.khoi_hanh_id
+)
+SELECT * FROM huy;
+
+-- name: ReleaseExpiredSeatHolds :many
+-- Trả lại chỗ của các giữ chỗ đã hết hạn (chạy định kỳ bởi sweeper)
+-- Trả về số chỗ được giải phóng theo từng khởi hành
+WITH het_han AS (
+    UPDATE giu_cho
+    SET trang_thai = 'het_han',
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    WHERE trang_thai = 'dang_giu'
+        AND het_han_luc <= CURRENT_TIMESTAMP
+    RETURNING khoi_hanh_id, so_nguoi_lon, so_tre_em
+), tong_theo_khoi_hanh AS (
+    SELECT khoi_hanh_id, SUM(so_nguoi_lon + so_tre_em)::int AS so_cho
+    FROM het_han
+    GROUP BY khoi_hanh_id
+)
+UPDATE khoi_hanh_tour kh
+SET so_cho_da_dat = GREATEST(kh.so_cho_da_dat - t.so_cho, 0),
+    trang_thai = CASE WHEN kh.trang_thai = 'het_cho' THEN 'con_cho'::trang_thai_khoi_hanh ELSE kh.trang_thai END,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM tong_theo_khoi_hanh t
+WHERE kh.id = t.khoi_hanh_id
+RETURNING kh.id AS khoi_hanh_id, t.so_cho AS so_cho_giai_phong;
+
+-- Function tạo booking từ mã giữ chỗ
+-- Chỗ đã được cộng khi giữ chỗ nên không kiểm tra lại số chỗ trống
+CREATE OR REPLACE FUNCTION su_dung_giu_cho(
+    p_nguoi_dung_id UUID,
+    p_ma_giu_cho UUID,
+    p_phuong_thuc_thanh_toan VARCHAR(50) DEFAULT NULL
+) RETURNS INT AS $$
+DECLARE
+    v_giu_cho giu_cho;
+    v_booking dat_cho;
+BEGIN
+    SELECT * INTO v_giu_cho
+    FROM giu_cho
+    WHERE ma_giu_cho = p_ma_giu_cho
+    FOR UPDATE;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'Mã giữ chỗ % không tồn tại.', p_ma_giu_cho;
+    END IF;
+
+    IF v_giu_cho.nguoi_dung_id <> p_nguoi_dung_id THEN
+        RAISE EXCEPTION 'Mã giữ chỗ không thuộc về người dùng này.';
+    END IF;
+
+    IF v_giu_cho.trang_thai = 'da_su_dung' THEN
+        RAISE EXCEPTION 'Mã giữ chỗ đã được sử dụng.';
+    END IF;
+
+    IF v_giu_cho.trang_thai <> 'dang_giu' OR v_giu_cho.het_han_luc <= CURRENT_TIMESTAMP THEN
+        RAISE EXCEPTION 'Mã giữ chỗ đã hết hạn hoặc đã bị hủy.';
+    END IF;
+
+    v_booking := create_booking(
+        p_nguoi_dung_id,
+        v_giu_cho.khoi_hanh_id,
+        v_giu_cho.so_nguoi_lon,
+        v_giu_cho.so_tre_em,
+        p_phuong_thuc_thanh_toan
+    );
+
+    UPDATE giu_cho
+    SET trang_thai = 'da_su_dung',
+        dat_cho_id = v_booking.id,
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    WHERE id = v_giu_cho.id;
+
+    RETURN v_booking.id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- name: CreateBookingFromHold :one
+-- Tạo đặt chỗ bằng cách tiêu thụ mã giữ chỗ, trả về ID booking
+SELECT su_dung_giu_cho(
+    sqlc.arg('nguoi_dung_id')::uuid,
+    sqlc.arg('ma_giu_cho')::uuid,
+    sqlc.narg('phuong_thuc_thanh_toan')::varchar
+)::int AS dat_cho_id;
@@ -0,0 +1,80 @@
+-- ===========================================
+-- PHIÊN ĐĂNG NHẬP (LOGIN SESSIONS)
+-- ===========================================
+
+-- name: NextSessionID :one
+-- Cấp trước id phiên để nhúng vào token trước khi lưu phiên
+SELECT nextval(pg_get_serial_sequence('phien_dang_nhap', 'id'))::int AS id;
+
+-- name: CreateSession :one
+INSERT INTO phien_dang_nhap (
+    id,
+    nguoi_dung_id,
+    ma_token_truy_cap,
+    ma_token_lam_moi,
+    thoi_han_token,
+    thong_tin_thiet_bi,
+    dia_chi_ip
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: RotateSessionTokens :one
+-- Xoay vòng token của phiên; chỉ thành công khi refresh token gửi lên là token hiện hành của phiên còn hiệu lực
+UPDATE phien_dang_nhap
+SET ma_token_truy_cap = sqlc.arg('ma_token_truy_cap'),
+    ma_token_lam_moi = sqlc.arg('ma_token_lam_moi_moi'),
+    thoi_han_token = sqlc.arg('thoi_han_token'),
+    lan_lam_moi_cuoi = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+    AND ma_token_lam_moi = sqlc.arg('ma_token_lam_moi_cu')
+    AND dang_hoat_dong = TRUE
+    AND thoi_han_token > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetSessionByID :one
+SELECT * FROM phien_dang_nhap
+WHERE id = $1;
+
+-- name: IsSessionActive :one
+SELECT EXISTS (
+    SELECT 1 FROM phien_dang_nhap
+    WHERE id = $1
+        AND dang_hoat_dong = TRUE
+        AND thoi_han_token > CURRENT_TIMESTAMP
+)::bool AS active;
+
+-- name: GetActiveSessionsByUser :many
+-- Các thiết bị đang đăng nhập của người dùng
+SELECT id, thong_tin_thiet_bi, dia_chi_ip, thoi_han_token, lan_lam_moi_cuoi, ngay_tao, ngay_cap_nhat
+FROM phien_dang_nhap
+WHERE nguoi_dung_id = $1
+    AND dang_hoat_dong = TRUE
+    AND thoi_han_token > CURRENT_TIMESTAMP
+ORDER BY COALESCE(lan_lam_moi_cuoi, ngay_tao) DESC;
+
+-- name: RevokeSession :execrows
+-- Thu hồi một phiên của người dùng
+UPDATE phien_dang_nhap
+SET dang_hoat_dong = FALSE,
+    thu_hoi_luc = CURRENT_TIMESTAMP,
+    ly_do_thu_hoi = sqlc.arg('ly_do_thu_hoi'),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+    AND nguoi_dung_id = sqlc.arg('nguoi_dung_id')
+    AND dang_hoat_dong = TRUE;
+
+-- name: RevokeUserSessions :many
+-- Thu hồi mọi phiên đang hoạt động của người dùng, trừ phiên ngoai_tru_id (nếu có)
+UPDATE phien_dang_nhap
+SET dang_hoat_dong = FALSE,
+    thu_hoi_luc = CURRENT_TIMESTAMP,
+    ly_do_thu_hoi = sqlc.arg('ly_do_thu_hoi'),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = sqlc.arg('nguoi_dung_id')
+    AND dang_hoat_dong = TRUE
+    AND (sqlc.narg('ngoai_tru_id')::int IS NULL OR id <> sqlc.narg('ngoai_tru_id')::int)
+RETURNING id;
+
@@ -135,7 +135,7 @@ UPDATE tour
 SET
     trang_thai = sqlc.narg(trang_thai),
     ngay_cap_nhat = CURRENT_TIMESTAMP
-WHERE id = $1 AND dang_hoat_dong = TRUE
+WHERE id = sqlc.arg(id) AND nha_cung_cap_id = sqlc.arg(nha_cung_cap_id) AND dang_hoat_dong = TRUE
 RETURNING *;
 
 -- name: GetSupplierById :one
@@ -620,11 +620,6 @@ WHERE t.nha_cung_cap_id = $1
     AND t.dang_hoat_dong = TRUE
 ORDER BY t.ngay_tao DESC;
 
--- name: FeedbackReview :one
--- Phản hồi đánh giá
-INSERT INTO phan_hoi_danh_gia (danh_gia_id, nguoi_dung_id, noi_dung)
-VALUES ($1, $2, $3)
-RETURNING id;
 -- name: GetFeedbackReview :many
 -- Lấy danh sách phản hồi đánh giá
 SELECT * FROM phan_hoi_danh_gia
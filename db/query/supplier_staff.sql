@@ -0,0 +1,112 @@
+-- ===========================================
+-- NHÂN VIÊN NHÀ CUNG CẤP
+-- ===========================================
+
+-- name: GetSupplierContext :one
+-- Nhà cung cấp mà người dùng thuộc về: chủ sở hữu (id = nha_cung_cap.id) hoặc nhân viên đang hoạt động
+SELECT ncc.id AS nha_cung_cap_id, TRUE AS chu_so_huu
+FROM nha_cung_cap ncc
+WHERE ncc.id = sqlc.arg('nguoi_dung_id')
+UNION ALL
+SELECT nv.nha_cung_cap_id, FALSE AS chu_so_huu
+FROM nhan_vien_nha_cung_cap nv
+WHERE nv.nguoi_dung_id = sqlc.arg('nguoi_dung_id')
+    AND nv.trang_thai = 'hoat_dong'
+LIMIT 1;
+
+-- name: GetSupplierStaff :many
+SELECT nv.id, nv.nha_cung_cap_id, nv.nguoi_dung_id, nv.quyen, nv.trang_thai, nv.han_loi_moi,
+    nv.nguoi_moi, nv.ngay_tham_gia, nv.ngay_tao, nv.ngay_cap_nhat,
+    nd.ho_ten, nd.email, nd.so_dien_thoai
+FROM nhan_vien_nha_cung_cap nv
+JOIN nguoi_dung nd ON nd.id = nv.nguoi_dung_id
+WHERE nv.nha_cung_cap_id = $1
+ORDER BY nv.ngay_tao DESC;
+
+-- name: GetSupplierStaffByID :one
+SELECT nv.id, nv.nha_cung_cap_id, nv.nguoi_dung_id, nv.quyen, nv.trang_thai, nv.han_loi_moi,
+    nv.nguoi_moi, nv.ngay_tham_gia, nv.ngay_tao, nv.ngay_cap_nhat,
+    nd.ho_ten, nd.email, nd.so_dien_thoai
+FROM nhan_vien_nha_cung_cap nv
+JOIN nguoi_dung nd ON nd.id = nv.nguoi_dung_id
+WHERE nv.id = $1 AND nv.nha_cung_cap_id = $2;
+
+-- name: CreateSupplierStaff :one
+INSERT INTO nhan_vien_nha_cung_cap (
+    nha_cung_cap_id, nguoi_dung_id, quyen, ma_loi_moi_bam, han_loi_moi, nguoi_moi
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: UpdateSupplierStaffPermissions :one
+UPDATE nhan_vien_nha_cung_cap
+SET quyen = sqlc.arg('quyen'),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+    AND nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')
+    AND trang_thai <> 'vo_hieu'
+RETURNING *;
+
+-- name: DeactivateSupplierStaff :one
+UPDATE nhan_vien_nha_cung_cap
+SET trang_thai = 'vo_hieu',
+    ma_loi_moi_bam = NULL,
+    han_loi_moi = NULL,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1
+    AND nha_cung_cap_id = $2
+    AND trang_thai <> 'vo_hieu'
+RETURNING *;
+
+-- name: DeactivateUser :exec
+UPDATE nguoi_dung
+SET dang_hoat_dong = FALSE,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: RenewSupplierStaffInvitation :one
+-- Tạo lại mã mời cho nhân viên chưa kích hoạt (mã cũ hết hiệu lực)
+UPDATE nhan_vien_nha_cung_cap
+SET ma_loi_moi_bam = sqlc.arg('ma_loi_moi_bam'),
+    han_loi_moi = sqlc.arg('han_loi_moi'),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+    AND nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')
+    AND trang_thai = 'cho_kich_hoat'
+RETURNING *;
+
+-- name: GetSupplierStaffByInvitation :one
+-- Lời mời còn hiệu lực theo mã đã băm
+SELECT nv.*, nd.email
+FROM nhan_vien_nha_cung_cap nv
+JOIN nguoi_dung nd ON nd.id = nv.nguoi_dung_id
+WHERE nv.ma_loi_moi_bam = $1
+    AND nv.trang_thai = 'cho_kich_hoat'
+    AND nv.han_loi_moi > CURRENT_TIMESTAMP;
+
+-- name: ActivateSupplierStaff :exec
+UPDATE nhan_vien_nha_cung_cap
+SET trang_thai = 'hoat_dong',
+    ma_loi_moi_bam = NULL,
+    han_loi_moi = NULL,
+    ngay_tham_gia = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: ActivateInvitedUser :exec
+-- Đặt mật khẩu do nhân viên chọn và kích hoạt tài khoản khi nhận lời mời
+UPDATE nguoi_dung
+SET mat_khau_ma_hoa = $2,
+    dang_hoat_dong = TRUE,
+    xac_thuc = TRUE,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: ReplySupplierReview :one
+-- Trả lời đánh giá, chỉ khi đánh giá thuộc tour của nhà cung cấp
+INSERT INTO phan_hoi_danh_gia (danh_gia_id, nguoi_dung_id, noi_dung)
+SELECT dg.id, sqlc.arg('nguoi_dung_id'), sqlc.arg('noi_dung')
+FROM danh_gia dg
+JOIN tour t ON t.id = dg.tour_id
+WHERE dg.id = sqlc.arg('danh_gia_id')
+    AND t.nha_cung_cap_id = sqlc.arg('nha_cung_cap_id')
+RETURNING id;
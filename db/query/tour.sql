@@ -219,7 +219,9 @@ tour_config AS (
     SELECT 
         tour_id,
         so_nho_nhat,
-        so_lon_nhat
+        so_lon_nhat,
+        tuoi_tre_em_toi_thieu,
+        tuoi_tre_em_toi_da
     FROM cau_hinh_nhom_tour
     WHERE tour_id = $1
 )
@@ -233,7 +235,9 @@ SELECT
     tdisc.ngay_bat_dau as giam_gia_tu,
     tdisc.ngay_ket_thuc as giam_gia_den,
     tc.so_nho_nhat,
-    tc.so_lon_nhat
+    tc.so_lon_nhat,
+    tc.tuoi_tre_em_toi_thieu,
+    tc.tuoi_tre_em_toi_da
 FROM tour_info ti
 LEFT JOIN tour_images timg ON ti.id = timg.tour_id
 LEFT JOIN tour_destinations td ON ti.id = td.tour_id
@@ -615,6 +619,10 @@ RETURNING *;
 DELETE FROM anh_tour
 WHERE id = $1 AND tour_id = $2;
 
+-- name: DeleteTourImagesByTour :exec
+DELETE FROM anh_tour
+WHERE tour_id = $1;
+
 -- name: SetPrimaryTourImage :exec
 UPDATE anh_tour
 SET la_anh_chinh = (id = $2)
@@ -629,6 +637,10 @@ JOIN diem_den dd ON td.diem_den_id = dd.id
 WHERE td.tour_id = $1
 ORDER BY td.thu_tu_tham_quan ASC NULLS LAST;
 
+-- name: DeleteTourDestinationsByTour :exec
+DELETE FROM tour_diem_den
+WHERE tour_id = $1;
+
 
 
 -- name: GetDiscountsByTourID :many
@@ -636,6 +648,10 @@ SELECT id, tour_id, phan_tram, ngay_bat_dau, ngay_ket_thuc, ngay_tao, ngay_cap_n
 WHERE tour_id = $1
 ORDER BY ngay_bat_dau DESC;
 
+-- name: GetDiscountTourByID :one
+SELECT id, tour_id, phan_tram, ngay_bat_dau, ngay_ket_thuc, ngay_tao, ngay_cap_nhat FROM giam_gia_tour
+WHERE id = $1;
+
 -- name: CreateDiscountTour :one
 INSERT INTO giam_gia_tour (
     tour_id,
@@ -727,3 +743,7 @@ ORDER BY so_booking DESC, t.noi_bat DESC, t.ngay_tao DESC
 LIMIT $3 OFFSET $4;
 
 
+
+-- name: GetTourByID :one
+SELECT * FROM tour
+WHERE id = $1;
@@ -0,0 +1,60 @@
+-- Văn bản nguồn để tạo embedding cho tour: tiêu đề, mô tả, danh mục, thời lượng, điểm đến, lịch trình và hoạt động.
+-- noi_dung_hash = md5(noi_dung) dùng để biết embedding đã lỗi thời hay chưa
+
+-- name: GetStaleTourEmbeddingSources :many
+-- Lấy các tour chưa có embedding, có nội dung đã thay đổi hoặc được tạo bằng mô hình khác.
+-- tour_id = NULL: quét toàn bộ tour đang hoạt động (đồng bộ định kỳ)
+WITH src AS (
+  SELECT
+    t.id,
+    LEFT(concat_ws(E'\n',
+      t.tieu_de,
+      t.mo_ta,
+      'Danh mục: ' || dm.ten,
+      'Thời lượng: ' || t.so_ngay || ' ngày ' || t.so_dem || ' đêm',
+      'Điểm đến: ' || (
+        SELECT string_agg(concat_ws(', ', d.ten, d.tinh, d.quoc_gia), '; ' ORDER BY tdd.thu_tu_tham_quan, d.id)
+        FROM tour_diem_den tdd
+        JOIN diem_den d ON d.id = tdd.diem_den_id
+        WHERE tdd.tour_id = t.id
+      ),
+      (
+        SELECT string_agg(
+          concat_ws(E'\n',
+            'Ngày ' || lt.ngay_thu || ': ' || lt.tieu_de,
+            lt.mo_ta,
+            lt.dia_diem,
+            (
+              SELECT string_agg(concat_ws(': ', hd.ten, hd.mo_ta), '; ' ORDER BY hd.thu_tu, hd.id)
+              FROM hoat_dong_trong_ngay hd
+              WHERE hd.lich_trinh_id = lt.id
+            )
+          ), E'\n' ORDER BY lt.ngay_thu, lt.id)
+        FROM lich_trinh lt
+        WHERE lt.tour_id = t.id
+      )
+    ), 8000)::TEXT AS noi_dung
+  FROM tour t
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  WHERE t.dang_hoat_dong = TRUE
+    AND (sqlc.narg('tour_id')::INT IS NULL OR t.id = sqlc.narg('tour_id')::INT)
+)
+SELECT src.id, src.noi_dung, md5(src.noi_dung)::TEXT AS noi_dung_hash
+FROM src
+LEFT JOIN tour_embeddings e ON e.tour_id = src.id
+WHERE e.tour_id IS NULL
+  OR e.embedding IS NULL
+  OR e.mo_hinh IS DISTINCT FROM sqlc.arg('mo_hinh')::TEXT
+  OR e.noi_dung_hash IS DISTINCT FROM md5(src.noi_dung)
+ORDER BY src.id
+LIMIT sqlc.arg('limit');
+
+-- name: UpsertTourEmbedding :exec
+INSERT INTO tour_embeddings (tour_id, embedding, mo_hinh, noi_dung_hash)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (tour_id)
+DO UPDATE SET
+    embedding = EXCLUDED.embedding,
+    mo_hinh = EXCLUDED.mo_hinh,
+    noi_dung_hash = EXCLUDED.noi_dung_hash,
+    ngay_cap_nhat = NOW();
@@ -0,0 +1,102 @@
+-- ===========================================
+-- KIỂM DUYỆT TOUR & PHIÊN BẢN NỘI DUNG
+-- ===========================================
+
+-- name: GetTourForUpdate :one
+-- Khóa dòng tour trong transaction gửi / duyệt phiên bản
+SELECT * FROM tour
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetTourContentSnapshot :one
+-- Nội dung hiện tại của tour theo định dạng models.CreateTourRequest
+SELECT tour_noi_dung(sqlc.arg('tour_id')::INT)::JSONB AS noi_dung;
+
+-- name: SetTourModerationStatus :one
+UPDATE tour
+SET trang_thai = sqlc.arg('trang_thai')::TEXT,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: CreateTourRevision :one
+INSERT INTO phien_ban_tour (tour_id, so_phien_ban, loai, noi_dung, ghi_chu, nguoi_gui_id)
+VALUES (
+    sqlc.arg('tour_id'),
+    (SELECT COALESCE(MAX(pb.so_phien_ban), 0) + 1 FROM phien_ban_tour pb WHERE pb.tour_id = sqlc.arg('tour_id')),
+    sqlc.arg('loai'),
+    sqlc.arg('noi_dung'),
+    sqlc.narg('ghi_chu'),
+    sqlc.arg('nguoi_gui_id')
+)
+RETURNING *;
+
+-- name: CancelPendingTourRevisions :execrows
+UPDATE phien_ban_tour
+SET trang_thai = 'da_huy'
+WHERE tour_id = $1 AND trang_thai = 'cho_duyet';
+
+-- name: ReviewTourRevision :one
+-- Chỉ phiên bản đang chờ duyệt mới được duyệt / từ chối (pgx.ErrNoRows nếu đã xử lý hoặc đã hủy)
+UPDATE phien_ban_tour
+SET trang_thai = sqlc.arg('trang_thai'),
+    nguoi_duyet_id = sqlc.arg('nguoi_duyet_id'),
+    nhan_xet_duyet = sqlc.narg('nhan_xet_duyet'),
+    ngay_duyet = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id') AND trang_thai = 'cho_duyet'
+RETURNING *;
+
+-- name: GetTourRevisionByID :one
+SELECT
+    pb.*,
+    t.tieu_de AS tieu_de_tour,
+    COALESCE(t.trang_thai, 'nhap')::TEXT AS trang_thai_tour,
+    t.nha_cung_cap_id,
+    ncc.ten AS ten_nha_cung_cap
+FROM phien_ban_tour pb
+JOIN tour t ON t.id = pb.tour_id
+LEFT JOIN nha_cung_cap ncc ON ncc.id = t.nha_cung_cap_id
+WHERE pb.id = $1;
+
+-- name: GetPendingTourRevision :one
+SELECT * FROM phien_ban_tour
+WHERE tour_id = $1 AND trang_thai = 'cho_duyet';
+
+-- name: ListTourRevisions :many
+-- Hàng đợi kiểm duyệt (mặc định các phiên bản chờ duyệt, cũ nhất trước); không kèm nội dung
+SELECT
+    pb.id,
+    pb.tour_id,
+    pb.so_phien_ban,
+    pb.loai,
+    pb.trang_thai,
+    pb.ghi_chu,
+    pb.nguoi_gui_id,
+    pb.nguoi_duyet_id,
+    pb.nhan_xet_duyet,
+    pb.ngay_tao,
+    pb.ngay_duyet,
+    t.tieu_de AS tieu_de_tour,
+    COALESCE(t.trang_thai, 'nhap')::TEXT AS trang_thai_tour,
+    t.nha_cung_cap_id,
+    ncc.ten AS ten_nha_cung_cap,
+    COUNT(*) OVER()::INT AS tong
+FROM phien_ban_tour pb
+JOIN tour t ON t.id = pb.tour_id
+LEFT JOIN nha_cung_cap ncc ON ncc.id = t.nha_cung_cap_id
+WHERE pb.trang_thai = COALESCE(sqlc.narg('trang_thai')::TEXT, 'cho_duyet')
+  AND (sqlc.narg('loai')::TEXT IS NULL OR pb.loai = sqlc.narg('loai')::TEXT)
+  AND (sqlc.narg('tour_id')::INT IS NULL OR pb.tour_id = sqlc.narg('tour_id')::INT)
+  AND (sqlc.narg('nha_cung_cap_id')::UUID IS NULL OR t.nha_cung_cap_id = sqlc.narg('nha_cung_cap_id')::UUID)
+ORDER BY pb.ngay_tao ASC, pb.id ASC
+LIMIT sqlc.arg('limit')::INT OFFSET sqlc.arg('offset')::INT;
+
+-- name: ListTourRevisionsByTour :many
+-- Lịch sử phiên bản của một tour, mới nhất trước
+SELECT
+    pb.*,
+    COUNT(*) OVER()::INT AS tong
+FROM phien_ban_tour pb
+WHERE pb.tour_id = sqlc.arg('tour_id')
+ORDER BY pb.so_phien_ban DESC
+LIMIT sqlc.arg('limit')::INT OFFSET sqlc.arg('offset')::INT;
@@ -0,0 +1,331 @@
+-- Tìm kiếm tour có facet: cả hai truy vấn dùng chung bộ lọc.
+-- Mỗi điều kiện lọc được tính thành một cột boolean (m_*) để đếm facet của một nhóm
+-- theo mọi bộ lọc còn lại (bỏ qua chính nhóm đó), nhờ vậy thanh lọc hiển thị được số lượng của từng lựa chọn.
+-- Khoảng giá (VND, theo giá sau giảm): duoi_2tr | 2tr_5tr | 5tr_10tr | 10tr_20tr | tren_20tr
+-- Khoảng thời lượng: 1_ngay | 2_3_ngay | 4_7_ngay | tren_7_ngay
+
+-- name: SearchToursFaceted :many
+WITH ggt AS (
+  SELECT DISTINCT ON (tour_id) tour_id, phan_tram
+  FROM giam_gia_tour
+  WHERE CURRENT_DATE BETWEEN ngay_bat_dau AND ngay_ket_thuc
+  ORDER BY tour_id, phan_tram DESC
+),
+dg AS (
+  SELECT tour_id, AVG(diem_danh_gia)::FLOAT AS avg_rating, COUNT(*)::INT AS total_reviews
+  FROM danh_gia
+  WHERE dang_hoat_dong = TRUE
+  GROUP BY tour_id
+),
+kh AS (
+  SELECT tour_id, MIN(ngay_khoi_hanh)::DATE AS next_departure_date
+  FROM khoi_hanh_tour
+  WHERE ngay_khoi_hanh >= CURRENT_DATE AND trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+  GROUP BY tour_id
+),
+base AS (
+  SELECT
+    t.id, t.tieu_de, t.mo_ta, t.danh_muc_id, dm.ten AS danh_muc_ten,
+    t.so_ngay, t.so_dem, t.gia_nguoi_lon, t.don_vi_tien_te, t.noi_bat, t.ngay_tao,
+    ggt.phan_tram AS giam_gia_phan_tram,
+    CASE
+      WHEN ggt.phan_tram IS NOT NULL THEN ROUND(t.gia_nguoi_lon * (1 - ggt.phan_tram / 100), 2)
+      ELSE t.gia_nguoi_lon
+    END::NUMERIC AS gia_sau_giam,
+    COALESCE(dg.avg_rating, 0)::FLOAT AS avg_rating,
+    COALESCE(dg.total_reviews, 0)::INT AS total_reviews,
+    kh.next_departure_date
+  FROM tour t
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  LEFT JOIN ggt ON ggt.tour_id = t.id
+  LEFT JOIN dg ON dg.tour_id = t.id
+  LEFT JOIN kh ON kh.tour_id = t.id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+),
+f AS (
+  SELECT b.*,
+    (
+      sqlc.narg('keyword')::TEXT IS NULL
+      OR to_tsvector('vietnamese', b.tieu_de || ' ' || COALESCE(b.mo_ta, '')) @@ plainto_tsquery('vietnamese', sqlc.narg('keyword'))
+      OR EXISTS (
+        SELECT 1 FROM lich_trinh lt
+        JOIN hoat_dong_trong_ngay hd ON hd.lich_trinh_id = lt.id
+        WHERE lt.tour_id = b.id AND to_tsvector('vietnamese', hd.ten) @@ plainto_tsquery('vietnamese', sqlc.narg('keyword'))
+      )
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        JOIN diem_den d ON d.id = tdd.diem_den_id
+        WHERE tdd.tour_id = b.id
+          AND unaccent(lower(d.ten || ' ' || COALESCE(d.tinh, '') || ' ' || COALESCE(d.quoc_gia, ''))) LIKE '%' || unaccent(lower(sqlc.narg('keyword'))) || '%'
+      )
+    ) AS m_tu_khoa,
+    (sqlc.narg('danh_muc_ids')::INT[] IS NULL OR b.danh_muc_id = ANY(sqlc.narg('danh_muc_ids')::INT[])) AS m_danh_muc,
+    (
+      sqlc.narg('diem_den_ids')::INT[] IS NULL
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        WHERE tdd.tour_id = b.id AND tdd.diem_den_id = ANY(sqlc.narg('diem_den_ids')::INT[])
+      )
+    ) AS m_diem_den,
+    (
+      (sqlc.narg('gia_min')::NUMERIC IS NULL OR b.gia_sau_giam >= sqlc.narg('gia_min')::NUMERIC)
+      AND (sqlc.narg('gia_max')::NUMERIC IS NULL OR b.gia_sau_giam <= sqlc.narg('gia_max')::NUMERIC)
+    ) AS m_gia,
+    (
+      (sqlc.narg('so_ngay_min')::INT IS NULL OR b.so_ngay >= sqlc.narg('so_ngay_min')::INT)
+      AND (sqlc.narg('so_ngay_max')::INT IS NULL OR b.so_ngay <= sqlc.narg('so_ngay_max')::INT)
+    ) AS m_thoi_luong,
+    (sqlc.narg('rating_min')::FLOAT IS NULL OR b.avg_rating >= sqlc.narg('rating_min')::FLOAT) AS m_danh_gia,
+    (
+      (sqlc.narg('khoi_hanh_tu')::DATE IS NULL AND sqlc.narg('khoi_hanh_den')::DATE IS NULL)
+      OR EXISTS (
+        SELECT 1 FROM khoi_hanh_tour k
+        WHERE k.tour_id = b.id
+          AND k.trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+          AND k.ngay_khoi_hanh >= GREATEST(COALESCE(sqlc.narg('khoi_hanh_tu')::DATE, CURRENT_DATE), CURRENT_DATE)
+          AND (sqlc.narg('khoi_hanh_den')::DATE IS NULL OR k.ngay_khoi_hanh <= sqlc.narg('khoi_hanh_den')::DATE)
+      )
+    ) AS m_khoi_hanh,
+    (sqlc.narg('co_giam_gia')::BOOLEAN IS NULL OR (b.giam_gia_phan_tram IS NOT NULL) = sqlc.narg('co_giam_gia')::BOOLEAN) AS m_giam_gia
+  FROM base b
+)
+SELECT
+  f.id, f.tieu_de, f.mo_ta, f.danh_muc_id, f.danh_muc_ten, f.so_ngay, f.so_dem,
+  f.gia_nguoi_lon, f.gia_sau_giam, f.giam_gia_phan_tram, f.don_vi_tien_te, f.noi_bat,
+  f.avg_rating, f.total_reviews, f.next_departure_date,
+  COALESCE(anh.duong_dan, '')::TEXT AS anh_chinh,
+  COALESCE((
+    SELECT array_agg(d.ten ORDER BY d.ten)
+    FROM tour_diem_den tdd
+    JOIN diem_den d ON d.id = tdd.diem_den_id
+    WHERE tdd.tour_id = f.id
+  ), '{}')::TEXT[] AS diem_den
+FROM f
+LEFT JOIN LATERAL (
+  SELECT a.duong_dan FROM anh_tour a
+  WHERE a.tour_id = f.id
+  ORDER BY COALESCE(a.la_anh_chinh, FALSE) DESC, COALESCE(a.thu_tu_hien_thi, 0) ASC
+  LIMIT 1
+) anh ON TRUE
+WHERE f.m_tu_khoa AND f.m_danh_muc AND f.m_diem_den AND f.m_gia
+  AND f.m_thoi_luong AND f.m_danh_gia AND f.m_khoi_hanh AND f.m_giam_gia
+ORDER BY
+  CASE WHEN sqlc.arg('sort_by')::TEXT = 'price_asc' THEN f.gia_sau_giam END ASC,
+  CASE WHEN sqlc.arg('sort_by')::TEXT = 'price_desc' THEN f.gia_sau_giam END DESC,
+  CASE WHEN sqlc.arg('sort_by')::TEXT = 'rating' THEN f.avg_rating END DESC,
+  CASE WHEN sqlc.arg('sort_by')::TEXT = 'departure' THEN f.next_departure_date END ASC NULLS LAST,
+  CASE WHEN sqlc.arg('sort_by')::TEXT = 'newest' THEN f.ngay_tao END DESC,
+  f.noi_bat DESC,
+  f.ngay_tao DESC,
+  f.id DESC
+LIMIT sqlc.arg('limit')::INT OFFSET sqlc.arg('offset')::INT;
+
+-- name: GetTourSearchFacets :many
+-- nhom: tong | danh_muc | diem_den | khoang_gia | thoi_luong
+WITH ggt AS (
+  SELECT DISTINCT ON (tour_id) tour_id, phan_tram
+  FROM giam_gia_tour
+  WHERE CURRENT_DATE BETWEEN ngay_bat_dau AND ngay_ket_thuc
+  ORDER BY tour_id, phan_tram DESC
+),
+dg AS (
+  SELECT tour_id, AVG(diem_danh_gia)::FLOAT AS avg_rating
+  FROM danh_gia
+  WHERE dang_hoat_dong = TRUE
+  GROUP BY tour_id
+),
+base AS (
+  SELECT
+    t.id, t.tieu_de, t.mo_ta, t.danh_muc_id, dm.ten AS danh_muc_ten, t.so_ngay,
+    ggt.phan_tram AS giam_gia_phan_tram,
+    CASE
+      WHEN ggt.phan_tram IS NOT NULL THEN ROUND(t.gia_nguoi_lon * (1 - ggt.phan_tram / 100), 2)
+      ELSE t.gia_nguoi_lon
+    END::NUMERIC AS gia_sau_giam,
+    COALESCE(dg.avg_rating, 0)::FLOAT AS avg_rating
+  FROM tour t
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  LEFT JOIN ggt ON ggt.tour_id = t.id
+  LEFT JOIN dg ON dg.tour_id = t.id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+),
+f AS (
+  SELECT b.id, b.danh_muc_id, b.danh_muc_ten,
+    CASE
+      WHEN b.gia_sau_giam < 2000000 THEN 'duoi_2tr'
+      WHEN b.gia_sau_giam < 5000000 THEN '2tr_5tr'
+      WHEN b.gia_sau_giam < 10000000 THEN '5tr_10tr'
+      WHEN b.gia_sau_giam < 20000000 THEN '10tr_20tr'
+      ELSE 'tren_20tr'
+    END AS khoang_gia,
+    CASE
+      WHEN b.so_ngay <= 1 THEN '1_ngay'
+      WHEN b.so_ngay <= 3 THEN '2_3_ngay'
+      WHEN b.so_ngay <= 7 THEN '4_7_ngay'
+      ELSE 'tren_7_ngay'
+    END AS thoi_luong,
+    (
+      sqlc.narg('keyword')::TEXT IS NULL
+      OR to_tsvector('vietnamese', b.tieu_de || ' ' || COALESCE(b.mo_ta, '')) @@ plainto_tsquery('vietnamese', sqlc.narg('keyword'))
+      OR EXISTS (
+        SELECT 1 FROM lich_trinh lt
+        JOIN hoat_dong_trong_ngay hd ON hd.lich_trinh_id = lt.id
+        WHERE lt.tour_id = b.id AND to_tsvector('vietnamese', hd.ten) @@ plainto_tsquery('vietnamese', sqlc.narg('keyword'))
+      )
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        JOIN diem_den d ON d.id = tdd.diem_den_id
+        WHERE tdd.tour_id = b.id
+          AND unaccent(lower(d.ten || ' ' || COALESCE(d.tinh, '') || ' ' || COALESCE(d.quoc_gia, ''))) LIKE '%' || unaccent(lower(sqlc.narg('keyword'))) || '%'
+      )
+    ) AS m_tu_khoa,
+    (sqlc.narg('danh_muc_ids')::INT[] IS NULL OR b.danh_muc_id = ANY(sqlc.narg('danh_muc_ids')::INT[])) AS m_danh_muc,
+    (
+      sqlc.narg('diem_den_ids')::INT[] IS NULL
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        WHERE tdd.tour_id = b.id AND tdd.diem_den_id = ANY(sqlc.narg('diem_den_ids')::INT[])
+      )
+    ) AS m_diem_den,
+    (
+      (sqlc.narg('gia_min')::NUMERIC IS NULL OR b.gia_sau_giam >= sqlc.narg('gia_min')::NUMERIC)
+      AND (sqlc.narg('gia_max')::NUMERIC IS NULL OR b.gia_sau_giam <= sqlc.narg('gia_max')::NUMERIC)
+    ) AS m_gia,
+    (
+      (sqlc.narg('so_ngay_min')::INT IS NULL OR b.so_ngay >= sqlc.narg('so_ngay_min')::INT)
+      AND (sqlc.narg('so_ngay_max')::INT IS NULL OR b.so_ngay <= sqlc.narg('so_ngay_max')::INT)
+    ) AS m_thoi_luong,
+    (sqlc.narg('rating_min')::FLOAT IS NULL OR b.avg_rating >= sqlc.narg('rating_min')::FLOAT) AS m_danh_gia,
+    (
+      (sqlc.narg('khoi_hanh_tu')::DATE IS NULL AND sqlc.narg('khoi_hanh_den')::DATE IS NULL)
+      OR EXISTS (
+        SELECT 1 FROM khoi_hanh_tour k
+        WHERE k.tour_id = b.id
+          AND k.trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+          AND k.ngay_khoi_hanh >= GREATEST(COALESCE(sqlc.narg('khoi_hanh_tu')::DATE, CURRENT_DATE), CURRENT_DATE)
+          AND (sqlc.narg('khoi_hanh_den')::DATE IS NULL OR k.ngay_khoi_hanh <= sqlc.narg('khoi_hanh_den')::DATE)
+      )
+    ) AS m_khoi_hanh,
+    (sqlc.narg('co_giam_gia')::BOOLEAN IS NULL OR (b.giam_gia_phan_tram IS NOT NULL) = sqlc.narg('co_giam_gia')::BOOLEAN) AS m_giam_gia
+  FROM base b
+)
+SELECT 'tong'::TEXT AS nhom, ''::TEXT AS khoa, ''::TEXT AS nhan, COUNT(*)::INT AS so_luong
+FROM f
+WHERE m_tu_khoa AND m_danh_muc AND m_diem_den AND m_gia AND m_thoi_luong AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+UNION ALL
+SELECT 'danh_muc'::TEXT, f.danh_muc_id::TEXT, COALESCE(MAX(f.danh_muc_ten), '')::TEXT, COUNT(*)::INT
+FROM f
+WHERE f.danh_muc_id IS NOT NULL
+  AND m_tu_khoa AND m_diem_den AND m_gia AND m_thoi_luong AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+GROUP BY f.danh_muc_id
+UNION ALL
+SELECT 'diem_den'::TEXT, d.id::TEXT, d.ten::TEXT, COUNT(DISTINCT f.id)::INT
+FROM f
+JOIN tour_diem_den tdd ON tdd.tour_id = f.id
+JOIN diem_den d ON d.id = tdd.diem_den_id
+WHERE m_tu_khoa AND m_danh_muc AND m_gia AND m_thoi_luong AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+GROUP BY d.id, d.ten
+UNION ALL
+SELECT 'khoang_gia'::TEXT, f.khoang_gia::TEXT, ''::TEXT, COUNT(*)::INT
+FROM f
+WHERE m_tu_khoa AND m_danh_muc AND m_diem_den AND m_thoi_luong AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+GROUP BY f.khoang_gia
+UNION ALL
+SELECT 'thoi_luong'::TEXT, f.thoi_luong::TEXT, ''::TEXT, COUNT(*)::INT
+FROM f
+WHERE m_tu_khoa AND m_danh_muc AND m_diem_den AND m_gia AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+GROUP BY f.thoi_luong;
+
+-- name: SearchToursSemantic :many
+-- Tìm kiếm ngữ nghĩa: xếp hạng theo độ tương đồng cosine giữa embedding câu truy vấn và embedding tour
+-- (chỉ so với embedding cùng mô hình), các bộ lọc có cấu trúc là điều kiện bắt buộc như SearchToursFaceted
+WITH ggt AS (
+  SELECT DISTINCT ON (tour_id) tour_id, phan_tram
+  FROM giam_gia_tour
+  WHERE CURRENT_DATE BETWEEN ngay_bat_dau AND ngay_ket_thuc
+  ORDER BY tour_id, phan_tram DESC
+),
+dg AS (
+  SELECT tour_id, AVG(diem_danh_gia)::FLOAT AS avg_rating, COUNT(*)::INT AS total_reviews
+  FROM danh_gia
+  WHERE dang_hoat_dong = TRUE
+  GROUP BY tour_id
+),
+kh AS (
+  SELECT tour_id, MIN(ngay_khoi_hanh)::DATE AS next_departure_date
+  FROM khoi_hanh_tour
+  WHERE ngay_khoi_hanh >= CURRENT_DATE AND trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+  GROUP BY tour_id
+),
+base AS (
+  SELECT
+    t.id, t.tieu_de, t.mo_ta, t.danh_muc_id, dm.ten AS danh_muc_ten,
+    t.so_ngay, t.so_dem, t.gia_nguoi_lon, t.don_vi_tien_te, t.noi_bat,
+    ggt.phan_tram AS giam_gia_phan_tram,
+    CASE
+      WHEN ggt.phan_tram IS NOT NULL THEN ROUND(t.gia_nguoi_lon * (1 - ggt.phan_tram / 100), 2)
+      ELSE t.gia_nguoi_lon
+    END::NUMERIC AS gia_sau_giam,
+    COALESCE(dg.avg_rating, 0)::FLOAT AS avg_rating,
+    COALESCE(dg.total_reviews, 0)::INT AS total_reviews,
+    kh.next_departure_date,
+    (e.embedding <=> sqlc.arg('embedding')::vector)::FLOAT AS khoang_cach
+  FROM tour t
+  JOIN tour_embeddings e ON e.tour_id = t.id AND e.mo_hinh = sqlc.arg('mo_hinh')::TEXT
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  LEFT JOIN ggt ON ggt.tour_id = t.id
+  LEFT JOIN dg ON dg.tour_id = t.id
+  LEFT JOIN kh ON kh.tour_id = t.id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo' AND e.embedding IS NOT NULL
+),
+f AS (
+  SELECT b.*
+  FROM base b
+  WHERE (sqlc.narg('danh_muc_ids')::INT[] IS NULL OR b.danh_muc_id = ANY(sqlc.narg('danh_muc_ids')::INT[]))
+    AND (
+      sqlc.narg('diem_den_ids')::INT[] IS NULL
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        WHERE tdd.tour_id = b.id AND tdd.diem_den_id = ANY(sqlc.narg('diem_den_ids')::INT[])
+      )
+    )
+    AND (sqlc.narg('gia_min')::NUMERIC IS NULL OR b.gia_sau_giam >= sqlc.narg('gia_min')::NUMERIC)
+    AND (sqlc.narg('gia_max')::NUMERIC IS NULL OR b.gia_sau_giam <= sqlc.narg('gia_max')::NUMERIC)
+    AND (sqlc.narg('so_ngay_min')::INT IS NULL OR b.so_ngay >= sqlc.narg('so_ngay_min')::INT)
+    AND (sqlc.narg('so_ngay_max')::INT IS NULL OR b.so_ngay <= sqlc.narg('so_ngay_max')::INT)
+    AND (sqlc.narg('rating_min')::FLOAT IS NULL OR b.avg_rating >= sqlc.narg('rating_min')::FLOAT)
+    AND (
+      (sqlc.narg('khoi_hanh_tu')::DATE IS NULL AND sqlc.narg('khoi_hanh_den')::DATE IS NULL)
+      OR EXISTS (
+        SELECT 1 FROM khoi_hanh_tour k
+        WHERE k.tour_id = b.id
+          AND k.trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+          AND k.ngay_khoi_hanh >= GREATEST(COALESCE(sqlc.narg('khoi_hanh_tu')::DATE, CURRENT_DATE), CURRENT_DATE)
+          AND (sqlc.narg('khoi_hanh_den')::DATE IS NULL OR k.ngay_khoi_hanh <= sqlc.narg('khoi_hanh_den')::DATE)
+      )
+    )
+    AND (sqlc.narg('co_giam_gia')::BOOLEAN IS NULL OR (b.giam_gia_phan_tram IS NOT NULL) = sqlc.narg('co_giam_gia')::BOOLEAN)
+    AND (sqlc.narg('do_tuong_dong_min')::FLOAT IS NULL OR 1 - b.khoang_cach >= sqlc.narg('do_tuong_dong_min')::FLOAT)
+)
+SELECT
+  f.id, f.tieu_de, f.mo_ta, f.danh_muc_id, f.danh_muc_ten, f.so_ngay, f.so_dem,
+  f.gia_nguoi_lon, f.gia_sau_giam, f.giam_gia_phan_tram, f.don_vi_tien_te, f.noi_bat,
+  f.avg_rating, f.total_reviews, f.next_departure_date,
+  (1 - f.khoang_cach)::FLOAT AS do_tuong_dong,
+  COALESCE(anh.duong_dan, '')::TEXT AS anh_chinh,
+  COALESCE((
+    SELECT array_agg(d.ten ORDER BY d.ten)
+    FROM tour_diem_den tdd
+    JOIN diem_den d ON d.id = tdd.diem_den_id
+    WHERE tdd.tour_id = f.id
+  ), '{}')::TEXT[] AS diem_den,
+  COUNT(*) OVER ()::INT AS tong
+FROM f
+LEFT JOIN LATERAL (
+  SELECT a.duong_dan FROM anh_tour a
+  WHERE a.tour_id = f.id
+  ORDER BY COALESCE(a.la_anh_chinh, FALSE) DESC, COALESCE(a.thu_tu_hien_thi, 0) ASC
+  LIMIT 1
+) anh ON TRUE
+ORDER BY f.khoang_cach ASC, f.noi_bat DESC, f.id DESC
+LIMIT sqlc.arg('limit')::INT OFFSET sqlc.arg('offset')::INT;
@@ -0,0 +1,76 @@
+-- ===========================================
+-- XÁC THỰC HAI BƯỚC (TOTP 2FA)
+-- ===========================================
+
+-- name: GetTwoFactorByUser :one
+SELECT * FROM xac_thuc_hai_buoc
+WHERE nguoi_dung_id = $1;
+
+-- name: UpsertPendingTwoFactor :one
+-- Tạo (hoặc tạo lại) khóa TOTP chờ kích hoạt; không ghi đè khi 2FA đang bật
+INSERT INTO xac_thuc_hai_buoc (nguoi_dung_id, bi_mat_ma_hoa)
+VALUES ($1, $2)
+ON CONFLICT (nguoi_dung_id) DO UPDATE
+SET bi_mat_ma_hoa = EXCLUDED.bi_mat_ma_hoa,
+    buoc_thoi_gian_cuoi = NULL,
+    ngay_tao = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE xac_thuc_hai_buoc.da_kich_hoat = FALSE
+RETURNING *;
+
+-- name: ConsumeTwoFactorStep :execrows
+-- Ghi nhận bước thời gian của mã TOTP vừa dùng; 0 dòng nghĩa là mã đã được dùng trước đó
+UPDATE xac_thuc_hai_buoc
+SET buoc_thoi_gian_cuoi = sqlc.arg('buoc_thoi_gian')::bigint,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = sqlc.arg('nguoi_dung_id')
+    AND (buoc_thoi_gian_cuoi IS NULL OR buoc_thoi_gian_cuoi < sqlc.arg('buoc_thoi_gian')::bigint);
+
+-- name: ActivateTwoFactor :exec
+UPDATE xac_thuc_hai_buoc
+SET da_kich_hoat = TRUE,
+    ngay_kich_hoat = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = $1;
+
+-- name: DeleteTwoFactor :exec
+DELETE FROM xac_thuc_hai_buoc
+WHERE nguoi_dung_id = $1;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM ma_khoi_phuc_2fa
+WHERE nguoi_dung_id = $1;
+
+-- name: CreateRecoveryCodes :copyfrom
+INSERT INTO ma_khoi_phuc_2fa (nguoi_dung_id, ma_bam)
+VALUES ($1, $2);
+
+-- name: UseRecoveryCode :execrows
+-- Dùng một mã khôi phục (mỗi mã chỉ dùng được một lần)
+UPDATE ma_khoi_phuc_2fa
+SET da_dung_luc = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = $1
+    AND ma_bam = $2
+    AND da_dung_luc IS NULL;
+
+-- name: CountRemainingRecoveryCodes :one
+SELECT COUNT(*)::int FROM ma_khoi_phuc_2fa
+WHERE nguoi_dung_id = $1 AND da_dung_luc IS NULL;
+
+-- name: GetTwoFactorPolicies :many
+SELECT * FROM chinh_sach_2fa
+ORDER BY vai_tro;
+
+-- name: IsTwoFactorRequired :one
+SELECT COALESCE((
+    SELECT bat_buoc FROM chinh_sach_2fa WHERE vai_tro = $1
+), FALSE)::bool AS bat_buoc;
+
+-- name: UpsertTwoFactorPolicy :one
+INSERT INTO chinh_sach_2fa (vai_tro, bat_buoc, nguoi_cap_nhat)
+VALUES ($1, $2, $3)
+ON CONFLICT (vai_tro) DO UPDATE
+SET bat_buoc = EXCLUDED.bat_buoc,
+    nguoi_cap_nhat = EXCLUDED.nguoi_cap_nhat,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+RETURNING *;
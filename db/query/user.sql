@@ -1,17 +1,17 @@
 -- name: ChangePassword :exec
 UPDATE nguoi_dung
-SET mat_khau_ma_hoa = $1
+SET mat_khau_ma_hoa = $1, co_mat_khau = TRUE
 WHERE id = $2;
 
 -- name: ForgotPassword :exec
 UPDATE nguoi_dung
-SET mat_khau_ma_hoa = $1
+SET mat_khau_ma_hoa = $1, co_mat_khau = TRUE
 WHERE email = $2
 RETURNING *;
 
 -- name: ResetPassword :one
 UPDATE nguoi_dung
-SET mat_khau_ma_hoa = $1
+SET mat_khau_ma_hoa = $1, co_mat_khau = TRUE
 WHERE email = $2
 RETURNING *;
 
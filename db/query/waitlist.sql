@@ -0,0 +1,201 @@
+-- ===========================================
+-- DANH SÁCH CHỜ KHỞI HÀNH (WAITLIST)
+-- ===========================================
+
+-- name: JoinWaitlist :one
+-- Đăng ký vào danh sách chờ của khởi hành
+INSERT INTO danh_sach_cho (
+    khoi_hanh_id,
+    nguoi_dung_id,
+    so_nguoi_lon,
+    so_tre_em
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: LeaveWaitlist :one
+-- Khách rời danh sách chờ (chỉ khi chưa được mời)
+UPDATE danh_sach_cho
+SET trang_thai = 'da_huy',
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1
+    AND nguoi_dung_id = $2
+    AND trang_thai = 'dang_cho'
+RETURNING *;
+
+-- name: GetMyWaitlist :many
+-- Danh sách chờ của người dùng kèm vị trí trong hàng đợi và giữ chỗ được mời (nếu có)
+SELECT
+    d.*,
+    kh.tour_id,
+    t.tieu_de AS ten_tour,
+    kh.ngay_khoi_hanh,
+    g.ma_giu_cho,
+    g.het_han_luc,
+    (
+        SELECT COUNT(*)
+        FROM danh_sach_cho d2
+        WHERE d2.khoi_hanh_id = d.khoi_hanh_id
+            AND d2.trang_thai = 'dang_cho'
+            AND (d2.ngay_tao, d2.id) < (d.ngay_tao, d.id)
+    )::int + 1 AS vi_tri
+FROM danh_sach_cho d
+JOIN khoi_hanh_tour kh ON kh.id = d.khoi_hanh_id
+JOIN tour t ON t.id = kh.tour_id
+LEFT JOIN giu_cho g ON g.id = d.giu_cho_id
+WHERE d.nguoi_dung_id = $1
+ORDER BY d.ngay_tao DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountMyWaitlist :one
+SELECT COUNT(*)::int FROM danh_sach_cho
+WHERE nguoi_dung_id = $1;
+
+-- name: CountWaitlistByDeparture :one
+-- Số lượt và số khách đang chờ của một khởi hành
+SELECT
+    COUNT(*)::int AS so_luot_cho,
+    COALESCE(SUM(so_nguoi_lon + so_tre_em), 0)::int AS so_khach_cho
+FROM danh_sach_cho
+WHERE khoi_hanh_id = $1 AND trang_thai = 'dang_cho';
+
+-- name: SyncWaitlistOffers :execrows
+-- Cập nhật lượt chờ đã được mời theo trạng thái giữ chỗ: đã dùng -> da_dat, hết hạn -> het_han, bị hủy -> da_huy
+UPDATE danh_sach_cho d
+SET trang_thai = CASE g.trang_thai
+        WHEN 'da_su_dung' THEN 'da_dat'
+        WHEN 'het_han' THEN 'het_han'
+        ELSE 'da_huy'
+    END,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM giu_cho g
+WHERE g.id = d.giu_cho_id
+    AND d.trang_thai = 'da_moi'
+    AND g.trang_thai <> 'dang_giu';
+
+-- name: GetDeparturesWithPromotableWaitlist :many
+-- Các khởi hành còn chỗ trống đủ cho ít nhất một lượt đang chờ
+SELECT kh.id
+FROM khoi_hanh_tour kh
+WHERE kh.trang_thai NOT IN ('huy', 'hoan_thanh')
+    AND kh.ngay_khoi_hanh > CURRENT_DATE
+    AND EXISTS (
+        SELECT 1 FROM danh_sach_cho d
+        WHERE d.khoi_hanh_id = kh.id
+            AND d.trang_thai = 'dang_cho'
+            AND d.so_nguoi_lon + d.so_tre_em <= kh.suc_chua - COALESCE(kh.so_cho_da_dat, 0)
+    );
+
+-- Function mời các lượt chờ khi khởi hành có chỗ trống
+-- Duyệt theo thứ tự đăng ký, lượt nào vừa số chỗ trống thì được giữ chỗ p_so_phut phút
+-- (lượt đông hơn số chỗ còn lại được bỏ qua và giữ nguyên vị trí).
+-- Trả về thông tin từng lượt được mời để gửi thông báo và email
+CREATE OR REPLACE FUNCTION moi_danh_sach_cho(
+    p_khoi_hanh_id INT,
+    p_so_phut INT
+) RETURNS TABLE (
+    danh_sach_cho_id INT,
+    khoi_hanh_id INT,
+    nguoi_dung_id UUID,
+    so_nguoi_lon INT,
+    so_tre_em INT,
+    ma_giu_cho UUID,
+    het_han_luc TIMESTAMP,
+    email VARCHAR(255),
+    ho_ten VARCHAR(255),
+    ten_tour VARCHAR(200),
+    ngay_khoi_hanh DATE
+) AS $$
+#variable_conflict use_column
+DECLARE
+    v_khoi_hanh khoi_hanh_tour;
+    v_ten_tour VARCHAR(200);
+    v_so_cho_trong INT;
+    v_so_khach INT;
+    v_giu_cho giu_cho;
+    v_luot danh_sach_cho;
+BEGIN
+    SELECT * INTO v_khoi_hanh
+    FROM khoi_hanh_tour kh
+    WHERE kh.id = p_khoi_hanh_id
+    FOR UPDATE;
+
+    IF NOT FOUND
+        OR v_khoi_hanh.trang_thai IN ('huy', 'hoan_thanh')
+        OR v_khoi_hanh.ngay_khoi_hanh <= CURRENT_DATE THEN
+        RETURN;
+    END IF;
+
+    SELECT t.tieu_de INTO v_ten_tour FROM tour t WHERE t.id = v_khoi_hanh.tour_id;
+    v_so_cho_trong := v_khoi_hanh.suc_chua - COALESCE(v_khoi_hanh.so_cho_da_dat, 0);
+
+    FOR v_luot IN
+        SELECT * FROM danh_sach_cho d
+        WHERE d.khoi_hanh_id = p_khoi_hanh_id
+            AND d.trang_thai = 'dang_cho'
+        ORDER BY d.ngay_tao, d.id
+        FOR UPDATE SKIP LOCKED
+    LOOP
+        EXIT WHEN v_so_cho_trong <= 0;
+
+        v_so_khach := v_luot.so_nguoi_lon + v_luot.so_tre_em;
+        CONTINUE WHEN v_so_khach > v_so_cho_trong;
+
+        UPDATE khoi_hanh_tour kh
+        SET so_cho_da_dat = COALESCE(kh.so_cho_da_dat, 0) + v_so_khach,
+            ngay_cap_nhat = CURRENT_TIMESTAMP
+        WHERE kh.id = p_khoi_hanh_id;
+
+        INSERT INTO giu_cho (khoi_hanh_id, nguoi_dung_id, so_nguoi_lon, so_tre_em, het_han_luc)
+        VALUES (
+            p_khoi_hanh_id,
+            v_luot.nguoi_dung_id,
+            v_luot.so_nguoi_lon,
+            v_luot.so_tre_em,
+            CURRENT_TIMESTAMP + (p_so_phut * INTERVAL '1 minute')
+        )
+        RETURNING * INTO v_giu_cho;
+
+        UPDATE danh_sach_cho d
+        SET trang_thai = 'da_moi',
+            giu_cho_id = v_giu_cho.id,
+            moi_luc = CURRENT_TIMESTAMP,
+            ngay_cap_nhat = CURRENT_TIMESTAMP
+        WHERE d.id = v_luot.id;
+
+        v_so_cho_trong := v_so_cho_trong - v_so_khach;
+
+        danh_sach_cho_id := v_luot.id;
+        khoi_hanh_id := p_khoi_hanh_id;
+        nguoi_dung_id := v_luot.nguoi_dung_id;
+        so_nguoi_lon := v_luot.so_nguoi_lon;
+        so_tre_em := v_luot.so_tre_em;
+        ma_giu_cho := v_giu_cho.ma_giu_cho;
+        het_han_luc := v_giu_cho.het_han_luc;
+        ngay_khoi_hanh := v_khoi_hanh.ngay_khoi_hanh;
+        ten_tour := v_ten_tour;
+        SELECT nd.email, nd.ho_ten INTO email, ho_ten
+        FROM nguoi_dung nd
+        WHERE nd.id = v_luot.nguoi_dung_id;
+
+        RETURN NEXT;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- name: PromoteWaitlist :many
+-- Mời các lượt chờ của khởi hành (tạo giữ chỗ có thời hạn), trả về thông tin để gửi thông báo và email
+SELECT
+    m.danh_sach_cho_id::int AS danh_sach_cho_id,
+    m.khoi_hanh_id::int AS khoi_hanh_id,
+    m.nguoi_dung_id::uuid AS nguoi_dung_id,
+    m.so_nguoi_lon::int AS so_nguoi_lon,
+    m.so_tre_em::int AS so_tre_em,
+    m.ma_giu_cho::uuid AS ma_giu_cho,
+    m.het_han_luc::timestamp AS het_han_luc,
+    m.email::text AS email,
+    m.ho_ten::text AS ho_ten,
+    m.ten_tour::text AS ten_tour,
+    m.ngay_khoi_hanh::date AS ngay_khoi_hanh
+FROM moi_danh_sach_cho(sqlc.arg('khoi_hanh_id')::int, sqlc.arg('so_phut')::int) m;
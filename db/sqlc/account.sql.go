@@ -0,0 +1,703 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: account.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const anonymizeUser = `-- name: AnonymizeUser :exec
+UPDATE nguoi_dung
+SET ho_ten = 'Người dùng đã xóa',
+    email = $1,
+    mat_khau_ma_hoa = $2,
+    so_dien_thoai = NULL,
+    dang_hoat_dong = FALSE,
+    xac_thuc = FALSE,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $3
+`
+
+type AnonymizeUserParams struct {
+	Email        string      `json:"email"`
+	MatKhauMaHoa string      `json:"mat_khau_ma_hoa"`
+	ID           pgtype.UUID `json:"id"`
+}
+
+// Giữ lại bản ghi nguoi_dung (dat_cho cần khóa ngoại) nhưng xóa toàn bộ thông tin định danh
+func (q *Queries) AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) error {
+	_, err := q.db.Exec(ctx, anonymizeUser, arg.Email, arg.MatKhauMaHoa, arg.ID)
+	return err
+}
+
+const anonymizeUserPassengers = `-- name: AnonymizeUserPassengers :exec
+
+UPDATE hanh_khach hk
+SET ho_ten = 'Ẩn danh',
+    ngay_sinh = NULL,
+    gioi_tinh = NULL,
+    so_giay_to_tuy_thanh = NULL,
+    quoc_tich = NULL,
+    ghi_chu = NULL
+FROM dat_cho dc
+WHERE dc.id = hk.dat_cho_id AND dc.nguoi_dung_id = $1
+`
+
+// ===========================================
+// ẨN DANH / XÓA DỮ LIỆU KHI XÓA TÀI KHOẢN
+// ===========================================
+func (q *Queries) AnonymizeUserPassengers(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, anonymizeUserPassengers, nguoiDungID)
+	return err
+}
+
+const anonymizeUserTransactions = `-- name: AnonymizeUserTransactions :exec
+UPDATE lich_su_giao_dich
+SET nguoi_dung_id = NULL
+WHERE nguoi_dung_id = $1
+`
+
+// Giao dịch vẫn liên kết với booking qua dat_cho_id, chỉ bỏ liên kết trực tiếp tới người dùng
+func (q *Queries) AnonymizeUserTransactions(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, anonymizeUserTransactions, nguoiDungID)
+	return err
+}
+
+const cancelAccountDeletion = `-- name: CancelAccountDeletion :one
+UPDATE yeu_cau_xoa_tai_khoan
+SET trang_thai = 'da_huy',
+    ngay_huy = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = $1 AND trang_thai = 'cho_xoa'
+RETURNING id, nguoi_dung_id, trang_thai, ly_do, han_xoa, ngay_huy, ngay_xoa, ngay_tao
+`
+
+func (q *Queries) CancelAccountDeletion(ctx context.Context, nguoiDungID pgtype.UUID) (YeuCauXoaTaiKhoan, error) {
+	row := q.db.QueryRow(ctx, cancelAccountDeletion, nguoiDungID)
+	var i YeuCauXoaTaiKhoan
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.TrangThai,
+		&i.LyDo,
+		&i.HanXoa,
+		&i.NgayHuy,
+		&i.NgayXoa,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const completeAccountDeletion = `-- name: CompleteAccountDeletion :exec
+UPDATE yeu_cau_xoa_tai_khoan
+SET trang_thai = 'da_xoa',
+    ngay_xoa = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) CompleteAccountDeletion(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, completeAccountDeletion, id)
+	return err
+}
+
+const countActiveBookingsByUser = `-- name: CountActiveBookingsByUser :one
+SELECT COUNT(*)::int
+FROM dat_cho dc
+JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
+WHERE dc.nguoi_dung_id = $1
+    AND dc.trang_thai IN ('cho_xac_nhan', 'da_xac_nhan', 'da_thanh_toan')
+    AND kh.ngay_ket_thuc >= CURRENT_DATE
+`
+
+// Booking chưa hủy của các khởi hành chưa kết thúc; còn booking này thì chưa thể xóa tài khoản
+func (q *Queries) CountActiveBookingsByUser(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, countActiveBookingsByUser, nguoiDungID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createAccountDeletionRequest = `-- name: CreateAccountDeletionRequest :one
+
+INSERT INTO yeu_cau_xoa_tai_khoan (
+    nguoi_dung_id,
+    ly_do,
+    han_xoa
+) VALUES (
+    $1, $2, $3
+)
+RETURNING id, nguoi_dung_id, trang_thai, ly_do, han_xoa, ngay_huy, ngay_xoa, ngay_tao
+`
+
+type CreateAccountDeletionRequestParams struct {
+	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
+	LyDo        *string          `json:"ly_do"`
+	HanXoa      pgtype.Timestamp `json:"han_xoa"`
+}
+
+// ===========================================
+// YÊU CẦU XÓA TÀI KHOẢN
+// ===========================================
+func (q *Queries) CreateAccountDeletionRequest(ctx context.Context, arg CreateAccountDeletionRequestParams) (YeuCauXoaTaiKhoan, error) {
+	row := q.db.QueryRow(ctx, createAccountDeletionRequest, arg.NguoiDungID, arg.LyDo, arg.HanXoa)
+	var i YeuCauXoaTaiKhoan
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.TrangThai,
+		&i.LyDo,
+		&i.HanXoa,
+		&i.NgayHuy,
+		&i.NgayXoa,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const deleteUserBlogComments = `-- name: DeleteUserBlogComments :exec
+DELETE FROM binh_luan_blog WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserBlogComments(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserBlogComments, nguoiDungID)
+	return err
+}
+
+const deleteUserContacts = `-- name: DeleteUserContacts :exec
+DELETE FROM lien_he WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserContacts(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserContacts, nguoiDungID)
+	return err
+}
+
+const deleteUserFavorites = `-- name: DeleteUserFavorites :exec
+DELETE FROM tour_yeu_thich WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserFavorites(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserFavorites, nguoiDungID)
+	return err
+}
+
+const deleteUserFinishedSeatHolds = `-- name: DeleteUserFinishedSeatHolds :exec
+DELETE FROM giu_cho WHERE nguoi_dung_id = $1 AND trang_thai <> 'dang_giu'
+`
+
+// Giữ chỗ đang hiệu lực để tiến trình giữ chỗ tự trả lại khi hết hạn
+func (q *Queries) DeleteUserFinishedSeatHolds(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserFinishedSeatHolds, nguoiDungID)
+	return err
+}
+
+const deleteUserLoginHistory = `-- name: DeleteUserLoginHistory :exec
+DELETE FROM lich_su_dang_nhap WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserLoginHistory(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserLoginHistory, nguoiDungID)
+	return err
+}
+
+const deleteUserNotifications = `-- name: DeleteUserNotifications :exec
+DELETE FROM thong_bao WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserNotifications(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserNotifications, nguoiDungID)
+	return err
+}
+
+const deleteUserPasswordResetOtps = `-- name: DeleteUserPasswordResetOtps :exec
+DELETE FROM otp_dat_lai_mat_khau WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserPasswordResetOtps(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserPasswordResetOtps, nguoiDungID)
+	return err
+}
+
+const deleteUserPreferences = `-- name: DeleteUserPreferences :exec
+DELETE FROM so_thich_nguoi_dung WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserPreferences(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserPreferences, nguoiDungID)
+	return err
+}
+
+const deleteUserRecoveryCodes = `-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM ma_khoi_phuc_2fa WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserRecoveryCodes(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserRecoveryCodes, nguoiDungID)
+	return err
+}
+
+const deleteUserReviews = `-- name: DeleteUserReviews :exec
+DELETE FROM danh_gia WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserReviews(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserReviews, nguoiDungID)
+	return err
+}
+
+const deleteUserSessions = `-- name: DeleteUserSessions :exec
+DELETE FROM phien_dang_nhap WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserSessions, nguoiDungID)
+	return err
+}
+
+const deleteUserTwoFactor = `-- name: DeleteUserTwoFactor :exec
+DELETE FROM xac_thuc_hai_buoc WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserTwoFactor(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserTwoFactor, nguoiDungID)
+	return err
+}
+
+const deleteUserViewHistory = `-- name: DeleteUserViewHistory :exec
+DELETE FROM lich_su_xem_tour WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserViewHistory(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserViewHistory, nguoiDungID)
+	return err
+}
+
+const deleteUserWaitlistEntries = `-- name: DeleteUserWaitlistEntries :exec
+DELETE FROM danh_sach_cho WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserWaitlistEntries(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserWaitlistEntries, nguoiDungID)
+	return err
+}
+
+const exportUserBookings = `-- name: ExportUserBookings :many
+SELECT
+    dc.id,
+    dc.khoi_hanh_id,
+    t.tieu_de AS tour_tieu_de,
+    kh.ngay_khoi_hanh,
+    kh.ngay_ket_thuc,
+    dc.so_nguoi_lon,
+    dc.so_tre_em,
+    dc.tong_tien,
+    dc.don_vi_tien_te,
+    dc.trang_thai,
+    dc.phuong_thuc_thanh_toan,
+    dc.ngay_dat,
+    dc.ngay_cap_nhat
+FROM dat_cho dc
+JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
+JOIN tour t ON t.id = kh.tour_id
+WHERE dc.nguoi_dung_id = $1
+ORDER BY dc.ngay_dat DESC, dc.id DESC
+`
+
+type ExportUserBookingsRow struct {
+	ID                  int32               `json:"id"`
+	KhoiHanhID          int32               `json:"khoi_hanh_id"`
+	TourTieuDe          string              `json:"tour_tieu_de"`
+	NgayKhoiHanh        pgtype.Date         `json:"ngay_khoi_hanh"`
+	NgayKetThuc         pgtype.Date         `json:"ngay_ket_thuc"`
+	SoNguoiLon          *int32              `json:"so_nguoi_lon"`
+	SoTreEm             *int32              `json:"so_tre_em"`
+	TongTien            pgtype.Numeric      `json:"tong_tien"`
+	DonViTienTe         *string             `json:"don_vi_tien_te"`
+	TrangThai           NullTrangThaiDatCho `json:"trang_thai"`
+	PhuongThucThanhToan *string             `json:"phuong_thuc_thanh_toan"`
+	NgayDat             pgtype.Timestamp    `json:"ngay_dat"`
+	NgayCapNhat         pgtype.Timestamp    `json:"ngay_cap_nhat"`
+}
+
+func (q *Queries) ExportUserBookings(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserBookingsRow, error) {
+	rows, err := q.db.Query(ctx, exportUserBookings, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserBookingsRow
+	for rows.Next() {
+		var i ExportUserBookingsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.KhoiHanhID,
+			&i.TourTieuDe,
+			&i.NgayKhoiHanh,
+			&i.NgayKetThuc,
+			&i.SoNguoiLon,
+			&i.SoTreEm,
+			&i.TongTien,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.PhuongThucThanhToan,
+			&i.NgayDat,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserChatHistory = `-- name: ExportUserChatHistory :many
+SELECT id, ma_phien, cau_hoi, cau_tra_loi, ngay_tao
+FROM lich_su_chat
+WHERE nguoi_dung_id = $1
+ORDER BY ngay_tao DESC, id DESC
+`
+
+type ExportUserChatHistoryRow struct {
+	ID        int32            `json:"id"`
+	MaPhien   string           `json:"ma_phien"`
+	CauHoi    string           `json:"cau_hoi"`
+	CauTraLoi string           `json:"cau_tra_loi"`
+	NgayTao   pgtype.Timestamp `json:"ngay_tao"`
+}
+
+func (q *Queries) ExportUserChatHistory(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserChatHistoryRow, error) {
+	rows, err := q.db.Query(ctx, exportUserChatHistory, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserChatHistoryRow
+	for rows.Next() {
+		var i ExportUserChatHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MaPhien,
+			&i.CauHoi,
+			&i.CauTraLoi,
+			&i.NgayTao,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserFavorites = `-- name: ExportUserFavorites :many
+SELECT yt.tour_id, t.tieu_de AS tour_tieu_de, yt.ngay_tao
+FROM tour_yeu_thich yt
+JOIN tour t ON t.id = yt.tour_id
+WHERE yt.nguoi_dung_id = $1
+ORDER BY yt.ngay_tao DESC
+`
+
+type ExportUserFavoritesRow struct {
+	TourID     int32            `json:"tour_id"`
+	TourTieuDe string           `json:"tour_tieu_de"`
+	NgayTao    pgtype.Timestamp `json:"ngay_tao"`
+}
+
+func (q *Queries) ExportUserFavorites(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserFavoritesRow, error) {
+	rows, err := q.db.Query(ctx, exportUserFavorites, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserFavoritesRow
+	for rows.Next() {
+		var i ExportUserFavoritesRow
+		if err := rows.Scan(&i.TourID, &i.TourTieuDe, &i.NgayTao); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserPassengers = `-- name: ExportUserPassengers :many
+SELECT hk.id, hk.dat_cho_id, hk.ho_ten, hk.ngay_sinh, hk.loai_khach, hk.gioi_tinh, hk.so_giay_to_tuy_thanh, hk.quoc_tich, hk.ghi_chu
+FROM hanh_khach hk
+JOIN dat_cho dc ON dc.id = hk.dat_cho_id
+WHERE dc.nguoi_dung_id = $1
+ORDER BY hk.dat_cho_id DESC, hk.id
+`
+
+func (q *Queries) ExportUserPassengers(ctx context.Context, nguoiDungID pgtype.UUID) ([]HanhKhach, error) {
+	rows, err := q.db.Query(ctx, exportUserPassengers, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HanhKhach
+	for rows.Next() {
+		var i HanhKhach
+		if err := rows.Scan(
+			&i.ID,
+			&i.DatChoID,
+			&i.HoTen,
+			&i.NgaySinh,
+			&i.LoaiKhach,
+			&i.GioiTinh,
+			&i.SoGiayToTuyThanh,
+			&i.QuocTich,
+			&i.GhiChu,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserProfile = `-- name: ExportUserProfile :one
+
+SELECT id, ho_ten, email, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+FROM nguoi_dung
+WHERE id = $1
+`
+
+type ExportUserProfileRow struct {
+	ID           pgtype.UUID         `json:"id"`
+	HoTen        string              `json:"ho_ten"`
+	Email        string              `json:"email"`
+	SoDienThoai  *string             `json:"so_dien_thoai"`
+	VaiTro       NullVaiTroNguoiDung `json:"vai_tro"`
+	DangHoatDong *bool               `json:"dang_hoat_dong"`
+	XacThuc      *bool               `json:"xac_thuc"`
+	NgayTao      pgtype.Timestamp    `json:"ngay_tao"`
+	NgayCapNhat  pgtype.Timestamp    `json:"ngay_cap_nhat"`
+}
+
+// ===========================================
+// XUẤT DỮ LIỆU CÁ NHÂN
+// ===========================================
+// Hồ sơ người dùng, không kèm mật khẩu đã mã hóa
+func (q *Queries) ExportUserProfile(ctx context.Context, id pgtype.UUID) (ExportUserProfileRow, error) {
+	row := q.db.QueryRow(ctx, exportUserProfile, id)
+	var i ExportUserProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.HoTen,
+		&i.Email,
+		&i.SoDienThoai,
+		&i.VaiTro,
+		&i.DangHoatDong,
+		&i.XacThuc,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const exportUserReviews = `-- name: ExportUserReviews :many
+SELECT
+    dg.id,
+    dg.tour_id,
+    t.tieu_de AS tour_tieu_de,
+    dg.dat_cho_id,
+    dg.diem_danh_gia,
+    dg.tieu_de,
+    dg.noi_dung,
+    dg.hinh_anh_dinh_kem,
+    dg.dang_hoat_dong,
+    dg.ngay_tao,
+    dg.ngay_cap_nhat
+FROM danh_gia dg
+JOIN tour t ON t.id = dg.tour_id
+WHERE dg.nguoi_dung_id = $1
+ORDER BY dg.ngay_tao DESC
+`
+
+type ExportUserReviewsRow struct {
+	ID             int32            `json:"id"`
+	TourID         int32            `json:"tour_id"`
+	TourTieuDe     string           `json:"tour_tieu_de"`
+	DatChoID       int32            `json:"dat_cho_id"`
+	DiemDanhGia    int32            `json:"diem_danh_gia"`
+	TieuDe         *string          `json:"tieu_de"`
+	NoiDung        *string          `json:"noi_dung"`
+	HinhAnhDinhKem []string         `json:"hinh_anh_dinh_kem"`
+	DangHoatDong   *bool            `json:"dang_hoat_dong"`
+	NgayTao        pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
+func (q *Queries) ExportUserReviews(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserReviewsRow, error) {
+	rows, err := q.db.Query(ctx, exportUserReviews, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserReviewsRow
+	for rows.Next() {
+		var i ExportUserReviewsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TourID,
+			&i.TourTieuDe,
+			&i.DatChoID,
+			&i.DiemDanhGia,
+			&i.TieuDe,
+			&i.NoiDung,
+			&i.HinhAnhDinhKem,
+			&i.DangHoatDong,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserViewHistory = `-- name: ExportUserViewHistory :many
+SELECT ls.id, ls.tour_id, t.tieu_de AS tour_tieu_de, ls.thoi_gian_xem, ls.thoi_luong_xem_giay, ls.ip_address, ls.user_agent
+FROM lich_su_xem_tour ls
+JOIN tour t ON t.id = ls.tour_id
+WHERE ls.nguoi_dung_id = $1
+ORDER BY ls.thoi_gian_xem DESC, ls.id DESC
+`
+
+type ExportUserViewHistoryRow struct {
+	ID               int32            `json:"id"`
+	TourID           *int32           `json:"tour_id"`
+	TourTieuDe       string           `json:"tour_tieu_de"`
+	ThoiGianXem      pgtype.Timestamp `json:"thoi_gian_xem"`
+	ThoiLuongXemGiay *int32           `json:"thoi_luong_xem_giay"`
+	IpAddress        *string          `json:"ip_address"`
+	UserAgent        *string          `json:"user_agent"`
+}
+
+func (q *Queries) ExportUserViewHistory(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserViewHistoryRow, error) {
+	rows, err := q.db.Query(ctx, exportUserViewHistory, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserViewHistoryRow
+	for rows.Next() {
+		var i ExportUserViewHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TourID,
+			&i.TourTieuDe,
+			&i.ThoiGianXem,
+			&i.ThoiLuongXemGiay,
+			&i.IpAddress,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueAccountDeletions = `-- name: GetDueAccountDeletions :many
+SELECT id, nguoi_dung_id, trang_thai, ly_do, han_xoa, ngay_huy, ngay_xoa, ngay_tao FROM yeu_cau_xoa_tai_khoan
+WHERE trang_thai = 'cho_xoa' AND han_xoa <= CURRENT_TIMESTAMP
+ORDER BY han_xoa
+LIMIT $1
+`
+
+// Các yêu cầu đã hết thời gian ân hạn
+func (q *Queries) GetDueAccountDeletions(ctx context.Context, limit int32) ([]YeuCauXoaTaiKhoan, error) {
+	rows, err := q.db.Query(ctx, getDueAccountDeletions, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []YeuCauXoaTaiKhoan
+	for rows.Next() {
+		var i YeuCauXoaTaiKhoan
+		if err := rows.Scan(
+			&i.ID,
+			&i.NguoiDungID,
+			&i.TrangThai,
+			&i.LyDo,
+			&i.HanXoa,
+			&i.NgayHuy,
+			&i.NgayXoa,
+			&i.NgayTao,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestAccountDeletion = `-- name: GetLatestAccountDeletion :one
+SELECT id, nguoi_dung_id, trang_thai, ly_do, han_xoa, ngay_huy, ngay_xoa, ngay_tao FROM yeu_cau_xoa_tai_khoan
+WHERE nguoi_dung_id = $1
+ORDER BY ngay_tao DESC, id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestAccountDeletion(ctx context.Context, nguoiDungID pgtype.UUID) (YeuCauXoaTaiKhoan, error) {
+	row := q.db.QueryRow(ctx, getLatestAccountDeletion, nguoiDungID)
+	var i YeuCauXoaTaiKhoan
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.TrangThai,
+		&i.LyDo,
+		&i.HanXoa,
+		&i.NgayHuy,
+		&i.NgayXoa,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const getPendingAccountDeletion = `-- name: GetPendingAccountDeletion :one
+SELECT id, nguoi_dung_id, trang_thai, ly_do, han_xoa, ngay_huy, ngay_xoa, ngay_tao FROM yeu_cau_xoa_tai_khoan
+WHERE nguoi_dung_id = $1 AND trang_thai = 'cho_xoa'
+`
+
+func (q *Queries) GetPendingAccountDeletion(ctx context.Context, nguoiDungID pgtype.UUID) (YeuCauXoaTaiKhoan, error) {
+	row := q.db.QueryRow(ctx, getPendingAccountDeletion, nguoiDungID)
+	var i YeuCauXoaTaiKhoan
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.TrangThai,
+		&i.LyDo,
+		&i.HanXoa,
+		&i.NgayHuy,
+		&i.NgayXoa,
+		&i.NgayTao,
+	)
+	return i, err
+}
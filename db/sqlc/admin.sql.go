@@ -278,7 +278,7 @@ SET
 WHERE id = $1 
     AND vai_tro = 'nha_cung_cap'
     AND dang_hoat_dong = TRUE
-RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau
 `
 
 // phê duyệt nhà cung cấp
@@ -296,6 +296,7 @@ func (q *Queries) ApproveSupplier(ctx context.Context, id pgtype.UUID) (NguoiDun
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
 	)
 	return i, err
 }
@@ -1768,7 +1769,7 @@ SET
     ngay_cap_nhat = CURRENT_TIMESTAMP
 WHERE id = $1 
     AND vai_tro = 'nha_cung_cap'
-RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau
 `
 
 // từ chối nhà cung cấp
@@ -1786,6 +1787,7 @@ func (q *Queries) RejectSupplier(ctx context.Context, id pgtype.UUID) (NguoiDung
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
 	)
 	return i, err
 }
@@ -1796,7 +1798,7 @@ SET
     dang_hoat_dong = TRUE,
     ngay_cap_nhat = CURRENT_TIMESTAMP
 WHERE id = $1 AND vai_tro = 'nha_cung_cap'
-RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau
 `
 
 func (q *Queries) RestoreSupplier(ctx context.Context, id pgtype.UUID) (NguoiDung, error) {
@@ -1813,6 +1815,7 @@ func (q *Queries) RestoreSupplier(ctx context.Context, id pgtype.UUID) (NguoiDun
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
 	)
 	return i, err
 }
@@ -0,0 +1,171 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: audit.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countAuditLogs = `-- name: CountAuditLogs :one
+SELECT COUNT(*) FROM nhat_ky_kiem_toan
+WHERE ($1::uuid IS NULL OR nguoi_thuc_hien = $1::uuid)
+    AND ($2::text IS NULL OR vai_tro = $2::text)
+    AND ($3::text IS NULL OR hanh_dong = $3::text)
+    AND ($4::text IS NULL OR loai_doi_tuong = $4::text)
+    AND ($5::text IS NULL OR ma_doi_tuong = $5::text)
+    AND ($6::text IS NULL OR ma_yeu_cau = $6::text)
+    AND ($7::timestamp IS NULL OR ngay_tao >= $7::timestamp)
+    AND ($8::timestamp IS NULL OR ngay_tao < $8::timestamp)
+`
+
+type CountAuditLogsParams struct {
+	NguoiThucHien pgtype.UUID      `json:"nguoi_thuc_hien"`
+	VaiTro        *string          `json:"vai_tro"`
+	HanhDong      *string          `json:"hanh_dong"`
+	LoaiDoiTuong  *string          `json:"loai_doi_tuong"`
+	MaDoiTuong    *string          `json:"ma_doi_tuong"`
+	MaYeuCau      *string          `json:"ma_yeu_cau"`
+	TuNgay        pgtype.Timestamp `json:"tu_ngay"`
+	DenNgay       pgtype.Timestamp `json:"den_ngay"`
+}
+
+func (q *Queries) CountAuditLogs(ctx context.Context, arg CountAuditLogsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAuditLogs,
+		arg.NguoiThucHien,
+		arg.VaiTro,
+		arg.HanhDong,
+		arg.LoaiDoiTuong,
+		arg.MaDoiTuong,
+		arg.MaYeuCau,
+		arg.TuNgay,
+		arg.DenNgay,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAuditLog = `-- name: CreateAuditLog :exec
+
+INSERT INTO nhat_ky_kiem_toan (
+    nguoi_thuc_hien, email_nguoi_thuc_hien, vai_tro, hanh_dong, loai_doi_tuong, ma_doi_tuong,
+    du_lieu_truoc, du_lieu_sau, thay_doi, dia_chi_ip, ma_yeu_cau
+) VALUES (
+    $1, $2, $3,
+    $4, $5, $6,
+    $7, $8, $9,
+    $10, $11
+)
+`
+
+type CreateAuditLogParams struct {
+	NguoiThucHien      pgtype.UUID `json:"nguoi_thuc_hien"`
+	EmailNguoiThucHien *string     `json:"email_nguoi_thuc_hien"`
+	VaiTro             *string     `json:"vai_tro"`
+	HanhDong           string      `json:"hanh_dong"`
+	LoaiDoiTuong       string      `json:"loai_doi_tuong"`
+	MaDoiTuong         string      `json:"ma_doi_tuong"`
+	DuLieuTruoc        []byte      `json:"du_lieu_truoc"`
+	DuLieuSau          []byte      `json:"du_lieu_sau"`
+	ThayDoi            []byte      `json:"thay_doi"`
+	DiaChiIp           *string     `json:"dia_chi_ip"`
+	MaYeuCau           *string     `json:"ma_yeu_cau"`
+}
+
+// ===========================================
+// NHẬT KÝ KIỂM TOÁN
+// ===========================================
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error {
+	_, err := q.db.Exec(ctx, createAuditLog,
+		arg.NguoiThucHien,
+		arg.EmailNguoiThucHien,
+		arg.VaiTro,
+		arg.HanhDong,
+		arg.LoaiDoiTuong,
+		arg.MaDoiTuong,
+		arg.DuLieuTruoc,
+		arg.DuLieuSau,
+		arg.ThayDoi,
+		arg.DiaChiIp,
+		arg.MaYeuCau,
+	)
+	return err
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+SELECT id, nguoi_thuc_hien, email_nguoi_thuc_hien, vai_tro, hanh_dong, loai_doi_tuong, ma_doi_tuong, du_lieu_truoc, du_lieu_sau, thay_doi, dia_chi_ip, ma_yeu_cau, ngay_tao FROM nhat_ky_kiem_toan
+WHERE ($1::uuid IS NULL OR nguoi_thuc_hien = $1::uuid)
+    AND ($2::text IS NULL OR vai_tro = $2::text)
+    AND ($3::text IS NULL OR hanh_dong = $3::text)
+    AND ($4::text IS NULL OR loai_doi_tuong = $4::text)
+    AND ($5::text IS NULL OR ma_doi_tuong = $5::text)
+    AND ($6::text IS NULL OR ma_yeu_cau = $6::text)
+    AND ($7::timestamp IS NULL OR ngay_tao >= $7::timestamp)
+    AND ($8::timestamp IS NULL OR ngay_tao < $8::timestamp)
+ORDER BY ngay_tao DESC, id DESC
+LIMIT $10 OFFSET $9
+`
+
+type ListAuditLogsParams struct {
+	NguoiThucHien pgtype.UUID      `json:"nguoi_thuc_hien"`
+	VaiTro        *string          `json:"vai_tro"`
+	HanhDong      *string          `json:"hanh_dong"`
+	LoaiDoiTuong  *string          `json:"loai_doi_tuong"`
+	MaDoiTuong    *string          `json:"ma_doi_tuong"`
+	MaYeuCau      *string          `json:"ma_yeu_cau"`
+	TuNgay        pgtype.Timestamp `json:"tu_ngay"`
+	DenNgay       pgtype.Timestamp `json:"den_ngay"`
+	Offset        int32            `json:"offset"`
+	Limit         int32            `json:"limit"`
+}
+
+// Lọc theo người thực hiện, hành động, đối tượng, mã request và khoảng thời gian (bỏ trống = không lọc)
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]NhatKyKiemToan, error) {
+	rows, err := q.db.Query(ctx, listAuditLogs,
+		arg.NguoiThucHien,
+		arg.VaiTro,
+		arg.HanhDong,
+		arg.LoaiDoiTuong,
+		arg.MaDoiTuong,
+		arg.MaYeuCau,
+		arg.TuNgay,
+		arg.DenNgay,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NhatKyKiemToan
+	for rows.Next() {
+		var i NhatKyKiemToan
+		if err := rows.Scan(
+			&i.ID,
+			&i.NguoiThucHien,
+			&i.EmailNguoiThucHien,
+			&i.VaiTro,
+			&i.HanhDong,
+			&i.LoaiDoiTuong,
+			&i.MaDoiTuong,
+			&i.DuLieuTruoc,
+			&i.DuLieuSau,
+			&i.ThayDoi,
+			&i.DiaChiIp,
+			&i.MaYeuCau,
+			&i.NgayTao,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -15,7 +15,7 @@ const createUser = `-- name: CreateUser :one
 insert into nguoi_dung(ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat)
 values
     ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-    returning id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+    returning id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau
 `
 
 type CreateUserParams struct {
@@ -54,12 +54,36 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (NguoiDu
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
+	)
+	return i, err
+}
+
+const getUserAccountByID = `-- name: GetUserAccountByID :one
+select id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau from nguoi_dung where id = $1
+`
+
+func (q *Queries) GetUserAccountByID(ctx context.Context, id pgtype.UUID) (NguoiDung, error) {
+	row := q.db.QueryRow(ctx, getUserAccountByID, id)
+	var i NguoiDung
+	err := row.Scan(
+		&i.ID,
+		&i.HoTen,
+		&i.Email,
+		&i.MatKhauMaHoa,
+		&i.SoDienThoai,
+		&i.VaiTro,
+		&i.DangHoatDong,
+		&i.XacThuc,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.CoMatKhau,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-select id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat from nguoi_dung where email = $1
+select id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau from nguoi_dung where email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (NguoiDung, error) {
@@ -76,13 +100,14 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (NguoiDung,
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
 SELECT 
-    nguoi_dung.id, nguoi_dung.ho_ten, nguoi_dung.email, nguoi_dung.mat_khau_ma_hoa, nguoi_dung.so_dien_thoai, nguoi_dung.vai_tro, nguoi_dung.dang_hoat_dong, nguoi_dung.xac_thuc, nguoi_dung.ngay_tao, nguoi_dung.ngay_cap_nhat, 
+    nguoi_dung.id, nguoi_dung.ho_ten, nguoi_dung.email, nguoi_dung.mat_khau_ma_hoa, nguoi_dung.so_dien_thoai, nguoi_dung.vai_tro, nguoi_dung.dang_hoat_dong, nguoi_dung.xac_thuc, nguoi_dung.ngay_tao, nguoi_dung.ngay_cap_nhat, nguoi_dung.co_mat_khau, 
     COUNT(dat_cho.id) AS tong_dat_cho, 
     COUNT(dat_cho.id) FILTER (WHERE dat_cho.trang_thai = 'da_thanh_toan') AS tong_dat_cho_da_thanh_toan, 
     COUNT(dat_cho.id) FILTER (WHERE dat_cho.trang_thai = 'cho_xac_nhan') AS tong_dat_cho_dang_cho_xac_nhan
@@ -103,6 +128,7 @@ type GetUserByIdRow struct {
 	XacThuc                  *bool               `json:"xac_thuc"`
 	NgayTao                  pgtype.Timestamp    `json:"ngay_tao"`
 	NgayCapNhat              pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	CoMatKhau                bool                `json:"co_mat_khau"`
 	TongDatCho               int64               `json:"tong_dat_cho"`
 	TongDatChoDaThanhToan    int64               `json:"tong_dat_cho_da_thanh_toan"`
 	TongDatChoDangChoXacNhan int64               `json:"tong_dat_cho_dang_cho_xac_nhan"`
@@ -122,6 +148,7 @@ func (q *Queries) GetUserById(ctx context.Context, id pgtype.UUID) (GetUserByIdR
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
 		&i.TongDatCho,
 		&i.TongDatChoDaThanhToan,
 		&i.TongDatChoDangChoXacNhan,
@@ -133,7 +160,7 @@ const updateUserById = `-- name: UpdateUserById :one
 update nguoi_dung
 set ho_ten = $1, email = $2, so_dien_thoai = $3, ngay_cap_nhat = $4
 where id = $5
-returning id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+returning id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau
 `
 
 type UpdateUserByIdParams struct {
@@ -165,6 +192,7 @@ func (q *Queries) UpdateUserById(ctx context.Context, arg UpdateUserByIdParams)
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
 	)
 	return i, err
 }
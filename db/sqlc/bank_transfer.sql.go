@@ -0,0 +1,308 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: bank_transfer.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countBankStatementLines = `-- name: CountBankStatementLines :one
+SELECT COUNT(*) FROM sao_ke_ngan_hang
+WHERE ($1::trang_thai_doi_soat IS NULL OR trang_thai = $1::trang_thai_doi_soat)
+`
+
+func (q *Queries) CountBankStatementLines(ctx context.Context, trangThai NullTrangThaiDoiSoat) (int64, error) {
+	row := q.db.QueryRow(ctx, countBankStatementLines, trangThai)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBankStatementLine = `-- name: CreateBankStatementLine :one
+INSERT INTO sao_ke_ngan_hang (
+    ma_giao_dich_ngan_hang,
+    ngay_giao_dich,
+    so_tien,
+    noi_dung,
+    ten_tep,
+    nguoi_tai_len_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (ma_giao_dich_ngan_hang) DO NOTHING
+RETURNING id, ma_giao_dich_ngan_hang, ngay_giao_dich, so_tien, noi_dung, ten_tep, trang_thai, giao_dich_id, ghi_chu, nguoi_tai_len_id, ngay_doi_soat, ngay_tao
+`
+
+type CreateBankStatementLineParams struct {
+	MaGiaoDichNganHang string           `json:"ma_giao_dich_ngan_hang"`
+	NgayGiaoDich       pgtype.Timestamp `json:"ngay_giao_dich"`
+	SoTien             pgtype.Numeric   `json:"so_tien"`
+	NoiDung            string           `json:"noi_dung"`
+	TenTep             *string          `json:"ten_tep"`
+	NguoiTaiLenID      pgtype.UUID      `json:"nguoi_tai_len_id"`
+}
+
+// Ghi nhận một dòng sao kê; dòng đã tải lên trước đó (trùng mã giao dịch ngân hàng) được bỏ qua (pgx.ErrNoRows)
+func (q *Queries) CreateBankStatementLine(ctx context.Context, arg CreateBankStatementLineParams) (SaoKeNganHang, error) {
+	row := q.db.QueryRow(ctx, createBankStatementLine,
+		arg.MaGiaoDichNganHang,
+		arg.NgayGiaoDich,
+		arg.SoTien,
+		arg.NoiDung,
+		arg.TenTep,
+		arg.NguoiTaiLenID,
+	)
+	var i SaoKeNganHang
+	err := row.Scan(
+		&i.ID,
+		&i.MaGiaoDichNganHang,
+		&i.NgayGiaoDich,
+		&i.SoTien,
+		&i.NoiDung,
+		&i.TenTep,
+		&i.TrangThai,
+		&i.GiaoDichID,
+		&i.GhiChu,
+		&i.NguoiTaiLenID,
+		&i.NgayDoiSoat,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const findPendingBankTransferByContent = `-- name: FindPendingBankTransferByContent :one
+SELECT id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu FROM lich_su_giao_dich
+WHERE cong_thanh_toan_id = 'bank_transfer'
+    AND trang_thai IN ('dang_cho_thanh_toan', 'dang_xuly')
+    AND strpos($1::text, UPPER(ma_giao_dich_noi_bo)) > 0
+ORDER BY LENGTH(ma_giao_dich_noi_bo) DESC, ngay_tao DESC
+LIMIT 1
+`
+
+// Tìm giao dịch chuyển khoản đang chờ có nội dung chuyển khoản nằm trong nội dung sao kê (đã chuẩn hóa)
+func (q *Queries) FindPendingBankTransferByContent(ctx context.Context, noiDung string) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, findPendingBankTransferByContent, noiDung)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
+const getBankStatementLines = `-- name: GetBankStatementLines :many
+SELECT
+    sk.id, sk.ma_giao_dich_ngan_hang, sk.ngay_giao_dich, sk.so_tien, sk.noi_dung, sk.ten_tep, sk.trang_thai, sk.giao_dich_id, sk.ghi_chu, sk.nguoi_tai_len_id, sk.ngay_doi_soat, sk.ngay_tao,
+    lsgd.ma_giao_dich_noi_bo,
+    lsgd.dat_cho_id
+FROM sao_ke_ngan_hang sk
+LEFT JOIN lich_su_giao_dich lsgd ON lsgd.id = sk.giao_dich_id
+WHERE ($1::trang_thai_doi_soat IS NULL OR sk.trang_thai = $1::trang_thai_doi_soat)
+ORDER BY sk.ngay_tao DESC, sk.id DESC
+LIMIT $3::int OFFSET $2::int
+`
+
+type GetBankStatementLinesParams struct {
+	TrangThai NullTrangThaiDoiSoat `json:"trang_thai"`
+	Offset    int32                `json:"offset"`
+	Limit     int32                `json:"limit"`
+}
+
+type GetBankStatementLinesRow struct {
+	ID                 int32            `json:"id"`
+	MaGiaoDichNganHang string           `json:"ma_giao_dich_ngan_hang"`
+	NgayGiaoDich       pgtype.Timestamp `json:"ngay_giao_dich"`
+	SoTien             pgtype.Numeric   `json:"so_tien"`
+	NoiDung            string           `json:"noi_dung"`
+	TenTep             *string          `json:"ten_tep"`
+	TrangThai          TrangThaiDoiSoat `json:"trang_thai"`
+	GiaoDichID         *int32           `json:"giao_dich_id"`
+	GhiChu             *string          `json:"ghi_chu"`
+	NguoiTaiLenID      pgtype.UUID      `json:"nguoi_tai_len_id"`
+	NgayDoiSoat        pgtype.Timestamp `json:"ngay_doi_soat"`
+	NgayTao            pgtype.Timestamp `json:"ngay_tao"`
+	MaGiaoDichNoiBo    *string          `json:"ma_giao_dich_noi_bo"`
+	DatChoID           *int32           `json:"dat_cho_id"`
+}
+
+// Danh sách dòng sao kê (Admin), lọc theo trạng thái đối soát
+func (q *Queries) GetBankStatementLines(ctx context.Context, arg GetBankStatementLinesParams) ([]GetBankStatementLinesRow, error) {
+	rows, err := q.db.Query(ctx, getBankStatementLines, arg.TrangThai, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBankStatementLinesRow
+	for rows.Next() {
+		var i GetBankStatementLinesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MaGiaoDichNganHang,
+			&i.NgayGiaoDich,
+			&i.SoTien,
+			&i.NoiDung,
+			&i.TenTep,
+			&i.TrangThai,
+			&i.GiaoDichID,
+			&i.GhiChu,
+			&i.NguoiTaiLenID,
+			&i.NgayDoiSoat,
+			&i.NgayTao,
+			&i.MaGiaoDichNoiBo,
+			&i.DatChoID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingBankTransferByBooking = `-- name: GetPendingBankTransferByBooking :one
+
+SELECT id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu FROM lich_su_giao_dich
+WHERE dat_cho_id = $1
+    AND cong_thanh_toan_id = 'bank_transfer'
+    AND trang_thai = 'dang_cho_thanh_toan'
+ORDER BY ngay_tao DESC
+LIMIT 1
+`
+
+// ===========================================
+// CHUYỂN KHOẢN NGÂN HÀNG & ĐỐI SOÁT SAO KÊ
+// ===========================================
+// Lấy giao dịch chuyển khoản đang chờ gần nhất của booking (để tái sử dụng nội dung chuyển khoản)
+func (q *Queries) GetPendingBankTransferByBooking(ctx context.Context, datChoID *int32) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, getPendingBankTransferByBooking, datChoID)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
+const getUnreconciledBankStatementLines = `-- name: GetUnreconciledBankStatementLines :many
+SELECT id, ma_giao_dich_ngan_hang, ngay_giao_dich, so_tien, noi_dung, ten_tep, trang_thai, giao_dich_id, ghi_chu, nguoi_tai_len_id, ngay_doi_soat, ngay_tao FROM sao_ke_ngan_hang
+WHERE trang_thai = 'chua_doi_soat'
+    AND so_tien > 0
+ORDER BY ngay_giao_dich ASC NULLS LAST, id ASC
+`
+
+// Lấy các dòng sao kê tiền vào chưa đối soát
+func (q *Queries) GetUnreconciledBankStatementLines(ctx context.Context) ([]SaoKeNganHang, error) {
+	rows, err := q.db.Query(ctx, getUnreconciledBankStatementLines)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SaoKeNganHang
+	for rows.Next() {
+		var i SaoKeNganHang
+		if err := rows.Scan(
+			&i.ID,
+			&i.MaGiaoDichNganHang,
+			&i.NgayGiaoDich,
+			&i.SoTien,
+			&i.NoiDung,
+			&i.TenTep,
+			&i.TrangThai,
+			&i.GiaoDichID,
+			&i.GhiChu,
+			&i.NguoiTaiLenID,
+			&i.NgayDoiSoat,
+			&i.NgayTao,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateBankStatementLineStatus = `-- name: UpdateBankStatementLineStatus :one
+UPDATE sao_ke_ngan_hang
+SET trang_thai = $1::trang_thai_doi_soat,
+    giao_dich_id = $2::int,
+    ghi_chu = $3::text,
+    ngay_doi_soat = CURRENT_TIMESTAMP
+WHERE id = $4::int
+    AND trang_thai = 'chua_doi_soat'
+RETURNING id, ma_giao_dich_ngan_hang, ngay_giao_dich, so_tien, noi_dung, ten_tep, trang_thai, giao_dich_id, ghi_chu, nguoi_tai_len_id, ngay_doi_soat, ngay_tao
+`
+
+type UpdateBankStatementLineStatusParams struct {
+	TrangThai  TrangThaiDoiSoat `json:"trang_thai"`
+	GiaoDichID *int32           `json:"giao_dich_id"`
+	GhiChu     *string          `json:"ghi_chu"`
+	ID         int32            `json:"id"`
+}
+
+// Cập nhật kết quả đối soát của dòng sao kê (chỉ dòng chưa đối soát)
+func (q *Queries) UpdateBankStatementLineStatus(ctx context.Context, arg UpdateBankStatementLineStatusParams) (SaoKeNganHang, error) {
+	row := q.db.QueryRow(ctx, updateBankStatementLineStatus,
+		arg.TrangThai,
+		arg.GiaoDichID,
+		arg.GhiChu,
+		arg.ID,
+	)
+	var i SaoKeNganHang
+	err := row.Scan(
+		&i.ID,
+		&i.MaGiaoDichNganHang,
+		&i.NgayGiaoDich,
+		&i.SoTien,
+		&i.NoiDung,
+		&i.TenTep,
+		&i.TrangThai,
+		&i.GiaoDichID,
+		&i.GhiChu,
+		&i.NguoiTaiLenID,
+		&i.NgayDoiSoat,
+		&i.NgayTao,
+	)
+	return i, err
+}
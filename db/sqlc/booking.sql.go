@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -97,52 +98,30 @@ func (q *Queries) AutoCompleteBookings(ctx context.Context) error {
 }
 
 const calculateRefundAmount = `-- name: CalculateRefundAmount :one
-WITH booking_info AS (
-    SELECT 
-        dc.tong_tien,
-        kh.ngay_khoi_hanh,
-        (kh.ngay_khoi_hanh - CURRENT_DATE)::INT AS so_ngay_truoc_khoi_hanh
-    FROM dat_cho dc
-    JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
-    WHERE dc.id = $1::int
-),
-refund_calc AS (
-    SELECT 
-        bi.tong_tien,
-        bi.so_ngay_truoc_khoi_hanh,
-        CASE 
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 15 THEN 100.00
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 7 THEN 90.00
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 3 THEN 70.00
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 1 THEN 50.00
-            ELSE 0.00
-        END::DECIMAL(5,2) AS phan_tram_hoan,
-        CASE 
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 15 THEN 'Hủy trước 15 ngày - hoàn 100%'
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 7 THEN 'Hủy trước 7 ngày - hoàn 90%'
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 3 THEN 'Hủy trước 3 ngày - hoàn 70%'
-            WHEN bi.so_ngay_truoc_khoi_hanh >= 1 THEN 'Hủy trước 24 giờ - hoàn 50%'
-            ELSE 'Hủy trong 24 giờ - không hoàn tiền'
-        END::TEXT AS ly_do
-    FROM booking_info bi
-)
-SELECT 
-    rc.tong_tien,
-    (rc.tong_tien * rc.phan_tram_hoan / 100.00)::DECIMAL(12,2) AS so_tien_hoan,
-    rc.phan_tram_hoan,
-    rc.so_ngay_truoc_khoi_hanh,
-    rc.ly_do
-FROM refund_calc rc
+SELECT
+    hl.tong_tien::DECIMAL(12,2) AS tong_tien,
+    hl.so_tien_hoan::DECIMAL(12,2) AS so_tien_hoan,
+    hl.phan_tram_hoan::DECIMAL(5,2) AS phan_tram_hoan,
+    hl.so_ngay_truoc_khoi_hanh::INT AS so_ngay_truoc_khoi_hanh,
+    hl.ly_do::TEXT AS ly_do,
+    hl.phi_huy::DECIMAL(12,2) AS phi_huy,
+    -- Bản chụp chính sách hủy lúc đặt (NULL khi áp dụng chính sách mặc định)
+    dc.chinh_sach_huy_ap_dung
+FROM tinh_tien_hoan_lai($1::int) hl
+JOIN dat_cho dc ON dc.id = $1::int
 `
 
 type CalculateRefundAmountRow struct {
-	TongTien            pgtype.Numeric `json:"tong_tien"`
-	SoTienHoan          pgtype.Numeric `json:"so_tien_hoan"`
-	PhanTramHoan        pgtype.Numeric `json:"phan_tram_hoan"`
-	SoNgayTruocKhoiHanh int32          `json:"so_ngay_truoc_khoi_hanh"`
-	LyDo                string         `json:"ly_do"`
+	TongTien            pgtype.Numeric  `json:"tong_tien"`
+	SoTienHoan          pgtype.Numeric  `json:"so_tien_hoan"`
+	PhanTramHoan        pgtype.Numeric  `json:"phan_tram_hoan"`
+	SoNgayTruocKhoiHanh int32           `json:"so_ngay_truoc_khoi_hanh"`
+	LyDo                string          `json:"ly_do"`
+	PhiHuy              pgtype.Numeric  `json:"phi_huy"`
+	ChinhSachHuyApDung  json.RawMessage `json:"chinh_sach_huy_ap_dung"`
 }
 
+// Xem trước số tiền hoàn lại (cùng chính sách với cancel_booking)
 func (q *Queries) CalculateRefundAmount(ctx context.Context, bookingID int32) (CalculateRefundAmountRow, error) {
 	row := q.db.QueryRow(ctx, calculateRefundAmount, bookingID)
 	var i CalculateRefundAmountRow
@@ -152,6 +131,8 @@ func (q *Queries) CalculateRefundAmount(ctx context.Context, bookingID int32) (C
 		&i.PhanTramHoan,
 		&i.SoNgayTruocKhoiHanh,
 		&i.LyDo,
+		&i.PhiHuy,
+		&i.ChinhSachHuyApDung,
 	)
 	return i, err
 }
@@ -244,7 +225,7 @@ SET
     trang_thai = 'hoan_thanh',
     ngay_cap_nhat = CURRENT_TIMESTAMP
 WHERE id = $1 AND trang_thai = 'da_thanh_toan'
-RETURNING id, nguoi_dung_id, khoi_hanh_id, so_nguoi_lon, so_tre_em, tong_tien, don_vi_tien_te, trang_thai, phuong_thuc_thanh_toan, ngay_dat, ngay_cap_nhat
+RETURNING id, nguoi_dung_id, khoi_hanh_id, so_nguoi_lon, so_tre_em, tong_tien, don_vi_tien_te, trang_thai, phuong_thuc_thanh_toan, ngay_dat, ngay_cap_nhat, doi_tac_id, khoa_api_id, chinh_sach_huy_ap_dung
 `
 
 // ===========================================
@@ -266,6 +247,9 @@ func (q *Queries) CompleteBooking(ctx context.Context, id int32) (DatCho, error)
 		&i.PhuongThucThanhToan,
 		&i.NgayDat,
 		&i.NgayCapNhat,
+		&i.DoiTacID,
+		&i.KhoaApiID,
+		&i.ChinhSachHuyApDung,
 	)
 	return i, err
 }
@@ -277,7 +261,7 @@ SET
     trang_thai = 'da_xac_nhan',
     ngay_cap_nhat = CURRENT_TIMESTAMP
 WHERE id = $1 AND trang_thai = 'cho_xac_nhan'
-RETURNING id, nguoi_dung_id, khoi_hanh_id, so_nguoi_lon, so_tre_em, tong_tien, don_vi_tien_te, trang_thai, phuong_thuc_thanh_toan, ngay_dat, ngay_cap_nhat
+RETURNING id, nguoi_dung_id, khoi_hanh_id, so_nguoi_lon, so_tre_em, tong_tien, don_vi_tien_te, trang_thai, phuong_thuc_thanh_toan, ngay_dat, ngay_cap_nhat, doi_tac_id, khoa_api_id, chinh_sach_huy_ap_dung
 `
 
 // ===========================================
@@ -299,6 +283,9 @@ func (q *Queries) ConfirmBooking(ctx context.Context, id int32) (DatCho, error)
 		&i.PhuongThucThanhToan,
 		&i.NgayDat,
 		&i.NgayCapNhat,
+		&i.DoiTacID,
+		&i.KhoaApiID,
+		&i.ChinhSachHuyApDung,
 	)
 	return i, err
 }
@@ -388,7 +375,7 @@ INSERT INTO lich_su_giao_dich (
     noi_dung_chuyen_khoan
 ) VALUES (
     $1, $2, $3, $4, $5, 'thanh_toan', 'dang_cho_thanh_toan', $6
-) RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh
+) RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
 `
 
 type CreateTransactionParams struct {
@@ -427,6 +414,12 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.NoiDungChuyenKhoan,
 		&i.NgayTao,
 		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
 	)
 	return i, err
 }
@@ -461,6 +454,17 @@ func (q *Queries) DeletePassenger(ctx context.Context, id int32) error {
 	return err
 }
 
+const deletePassengersByBooking = `-- name: DeletePassengersByBooking :exec
+DELETE FROM hanh_khach
+WHERE dat_cho_id = $1
+`
+
+// Xóa toàn bộ hành khách của booking (trước khi ghi lại danh sách mới)
+func (q *Queries) DeletePassengersByBooking(ctx context.Context, datChoID int32) error {
+	_, err := q.db.Exec(ctx, deletePassengersByBooking, datChoID)
+	return err
+}
+
 const getAllRefunds = `-- name: GetAllRefunds :many
 
 WITH refund_info AS (
@@ -701,7 +705,7 @@ func (q *Queries) GetAvailableDepartures(ctx context.Context, tourID int32) ([]G
 
 const getBookingById = `-- name: GetBookingById :one
 SELECT 
-    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat,
+    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat, dc.doi_tac_id, dc.khoa_api_id, dc.chinh_sach_huy_ap_dung,
     nd.ho_ten AS ten_nguoi_dat,
     nd.email,
     nd.so_dien_thoai,
@@ -728,6 +732,9 @@ type GetBookingByIdRow struct {
 	PhuongThucThanhToan *string             `json:"phuong_thuc_thanh_toan"`
 	NgayDat             pgtype.Timestamp    `json:"ngay_dat"`
 	NgayCapNhat         pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	DoiTacID            *int32              `json:"doi_tac_id"`
+	KhoaApiID           *int32              `json:"khoa_api_id"`
+	ChinhSachHuyApDung  json.RawMessage     `json:"chinh_sach_huy_ap_dung"`
 	TenNguoiDat         string              `json:"ten_nguoi_dat"`
 	Email               string              `json:"email"`
 	SoDienThoai         *string             `json:"so_dien_thoai"`
@@ -753,6 +760,9 @@ func (q *Queries) GetBookingById(ctx context.Context, id int32) (GetBookingByIdR
 		&i.PhuongThucThanhToan,
 		&i.NgayDat,
 		&i.NgayCapNhat,
+		&i.DoiTacID,
+		&i.KhoaApiID,
+		&i.ChinhSachHuyApDung,
 		&i.TenNguoiDat,
 		&i.Email,
 		&i.SoDienThoai,
@@ -816,7 +826,7 @@ func (q *Queries) GetBookingStats(ctx context.Context, arg GetBookingStatsParams
 
 const getBookingsByStatus = `-- name: GetBookingsByStatus :many
 SELECT 
-    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat,
+    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat, dc.doi_tac_id, dc.khoa_api_id, dc.chinh_sach_huy_ap_dung,
     nd.ho_ten AS ten_nguoi_dat,
     nd.email,
     kh.ngay_khoi_hanh,
@@ -851,6 +861,9 @@ type GetBookingsByStatusRow struct {
 	PhuongThucThanhToan *string             `json:"phuong_thuc_thanh_toan"`
 	NgayDat             pgtype.Timestamp    `json:"ngay_dat"`
 	NgayCapNhat         pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	DoiTacID            *int32              `json:"doi_tac_id"`
+	KhoaApiID           *int32              `json:"khoa_api_id"`
+	ChinhSachHuyApDung  json.RawMessage     `json:"chinh_sach_huy_ap_dung"`
 	TenNguoiDat         string              `json:"ten_nguoi_dat"`
 	Email               string              `json:"email"`
 	NgayKhoiHanh        pgtype.Date         `json:"ngay_khoi_hanh"`
@@ -885,6 +898,9 @@ func (q *Queries) GetBookingsByStatus(ctx context.Context, arg GetBookingsByStat
 			&i.PhuongThucThanhToan,
 			&i.NgayDat,
 			&i.NgayCapNhat,
+			&i.DoiTacID,
+			&i.KhoaApiID,
+			&i.ChinhSachHuyApDung,
 			&i.TenNguoiDat,
 			&i.Email,
 			&i.NgayKhoiHanh,
@@ -903,7 +919,7 @@ func (q *Queries) GetBookingsByStatus(ctx context.Context, arg GetBookingsByStat
 
 const getBookingsByUser = `-- name: GetBookingsByUser :many
 SELECT 
-    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat,
+    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat, dc.doi_tac_id, dc.khoa_api_id, dc.chinh_sach_huy_ap_dung,
     kh.ngay_khoi_hanh,
     kh.ngay_ket_thuc,
     kh.trang_thai AS trang_thai_khoi_hanh, -- Trạng thái thực tế của chuyến đi
@@ -954,6 +970,9 @@ type GetBookingsByUserRow struct {
 	PhuongThucThanhToan *string               `json:"phuong_thuc_thanh_toan"`
 	NgayDat             pgtype.Timestamp      `json:"ngay_dat"`
 	NgayCapNhat         pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	DoiTacID            *int32                `json:"doi_tac_id"`
+	KhoaApiID           *int32                `json:"khoa_api_id"`
+	ChinhSachHuyApDung  json.RawMessage       `json:"chinh_sach_huy_ap_dung"`
 	NgayKhoiHanh        pgtype.Date           `json:"ngay_khoi_hanh"`
 	NgayKetThuc         pgtype.Date           `json:"ngay_ket_thuc"`
 	TrangThaiKhoiHanh   NullTrangThaiKhoiHanh `json:"trang_thai_khoi_hanh"`
@@ -989,6 +1008,9 @@ func (q *Queries) GetBookingsByUser(ctx context.Context, arg GetBookingsByUserPa
 			&i.PhuongThucThanhToan,
 			&i.NgayDat,
 			&i.NgayCapNhat,
+			&i.DoiTacID,
+			&i.KhoaApiID,
+			&i.ChinhSachHuyApDung,
 			&i.NgayKhoiHanh,
 			&i.NgayKetThuc,
 			&i.TrangThaiKhoiHanh,
@@ -1007,7 +1029,7 @@ func (q *Queries) GetBookingsByUser(ctx context.Context, arg GetBookingsByUserPa
 
 const getBookingsByUserId = `-- name: GetBookingsByUserId :many
 SELECT 
-    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat,
+    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat, dc.doi_tac_id, dc.khoa_api_id, dc.chinh_sach_huy_ap_dung,
     nd.ho_ten AS ten_nguoi_dat,
     kh.ngay_khoi_hanh,
     kh.ngay_ket_thuc,
@@ -1031,6 +1053,9 @@ type GetBookingsByUserIdRow struct {
 	PhuongThucThanhToan *string             `json:"phuong_thuc_thanh_toan"`
 	NgayDat             pgtype.Timestamp    `json:"ngay_dat"`
 	NgayCapNhat         pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	DoiTacID            *int32              `json:"doi_tac_id"`
+	KhoaApiID           *int32              `json:"khoa_api_id"`
+	ChinhSachHuyApDung  json.RawMessage     `json:"chinh_sach_huy_ap_dung"`
 	TenNguoiDat         string              `json:"ten_nguoi_dat"`
 	NgayKhoiHanh        pgtype.Date         `json:"ngay_khoi_hanh"`
 	NgayKetThuc         pgtype.Date         `json:"ngay_ket_thuc"`
@@ -1058,6 +1083,9 @@ func (q *Queries) GetBookingsByUserId(ctx context.Context, nguoiDungID pgtype.UU
 			&i.PhuongThucThanhToan,
 			&i.NgayDat,
 			&i.NgayCapNhat,
+			&i.DoiTacID,
+			&i.KhoaApiID,
+			&i.ChinhSachHuyApDung,
 			&i.TenNguoiDat,
 			&i.NgayKhoiHanh,
 			&i.NgayKetThuc,
@@ -1075,7 +1103,7 @@ func (q *Queries) GetBookingsByUserId(ctx context.Context, nguoiDungID pgtype.UU
 
 const getCancelledBookings = `-- name: GetCancelledBookings :many
 SELECT 
-    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat,
+    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat, dc.doi_tac_id, dc.khoa_api_id, dc.chinh_sach_huy_ap_dung,
     nd.ho_ten AS ten_nguoi_dat,
     kh.ngay_khoi_hanh,
     t.tieu_de AS ten_tour
@@ -1107,6 +1135,9 @@ type GetCancelledBookingsRow struct {
 	PhuongThucThanhToan *string             `json:"phuong_thuc_thanh_toan"`
 	NgayDat             pgtype.Timestamp    `json:"ngay_dat"`
 	NgayCapNhat         pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	DoiTacID            *int32              `json:"doi_tac_id"`
+	KhoaApiID           *int32              `json:"khoa_api_id"`
+	ChinhSachHuyApDung  json.RawMessage     `json:"chinh_sach_huy_ap_dung"`
 	TenNguoiDat         string              `json:"ten_nguoi_dat"`
 	NgayKhoiHanh        pgtype.Date         `json:"ngay_khoi_hanh"`
 	TenTour             string              `json:"ten_tour"`
@@ -1134,6 +1165,9 @@ func (q *Queries) GetCancelledBookings(ctx context.Context, arg GetCancelledBook
 			&i.PhuongThucThanhToan,
 			&i.NgayDat,
 			&i.NgayCapNhat,
+			&i.DoiTacID,
+			&i.KhoaApiID,
+			&i.ChinhSachHuyApDung,
 			&i.TenNguoiDat,
 			&i.NgayKhoiHanh,
 			&i.TenTour,
@@ -1150,7 +1184,7 @@ func (q *Queries) GetCancelledBookings(ctx context.Context, arg GetCancelledBook
 
 const getDepartureById = `-- name: GetDepartureById :one
 SELECT 
-    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat,
+    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat, kh.chinh_sach_huy_id,
     t.tieu_de AS ten_tour,
     t.gia_nguoi_lon,
     t.gia_tre_em,
@@ -1161,20 +1195,21 @@ WHERE kh.id = $1
 `
 
 type GetDepartureByIdRow struct {
-	ID           int32                 `json:"id"`
-	TourID       int32                 `json:"tour_id"`
-	NgayKhoiHanh pgtype.Date           `json:"ngay_khoi_hanh"`
-	NgayKetThuc  pgtype.Date           `json:"ngay_ket_thuc"`
-	SucChua      int32                 `json:"suc_chua"`
-	SoChoDaDat   *int32                `json:"so_cho_da_dat"`
-	TrangThai    NullTrangThaiKhoiHanh `json:"trang_thai"`
-	GhiChu       *string               `json:"ghi_chu"`
-	NgayTao      pgtype.Timestamp      `json:"ngay_tao"`
-	NgayCapNhat  pgtype.Timestamp      `json:"ngay_cap_nhat"`
-	TenTour      string                `json:"ten_tour"`
-	GiaNguoiLon  pgtype.Numeric        `json:"gia_nguoi_lon"`
-	GiaTreEm     pgtype.Numeric        `json:"gia_tre_em"`
-	DonViTienTe  *string               `json:"don_vi_tien_te"`
+	ID             int32                 `json:"id"`
+	TourID         int32                 `json:"tour_id"`
+	NgayKhoiHanh   pgtype.Date           `json:"ngay_khoi_hanh"`
+	NgayKetThuc    pgtype.Date           `json:"ngay_ket_thuc"`
+	SucChua        int32                 `json:"suc_chua"`
+	SoChoDaDat     *int32                `json:"so_cho_da_dat"`
+	TrangThai      NullTrangThaiKhoiHanh `json:"trang_thai"`
+	GhiChu         *string               `json:"ghi_chu"`
+	NgayTao        pgtype.Timestamp      `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32                `json:"chinh_sach_huy_id"`
+	TenTour        string                `json:"ten_tour"`
+	GiaNguoiLon    pgtype.Numeric        `json:"gia_nguoi_lon"`
+	GiaTreEm       pgtype.Numeric        `json:"gia_tre_em"`
+	DonViTienTe    *string               `json:"don_vi_tien_te"`
 }
 
 // Lấy thông tin chi tiết một ngày khởi hành
@@ -1192,6 +1227,7 @@ func (q *Queries) GetDepartureById(ctx context.Context, id int32) (GetDepartureB
 		&i.GhiChu,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 		&i.TenTour,
 		&i.GiaNguoiLon,
 		&i.GiaTreEm,
@@ -1200,6 +1236,48 @@ func (q *Queries) GetDepartureById(ctx context.Context, id int32) (GetDepartureB
 	return i, err
 }
 
+const getDepartureGroupRules = `-- name: GetDepartureGroupRules :one
+SELECT
+    kh.id AS khoi_hanh_id,
+    kh.tour_id,
+    kh.ngay_khoi_hanh,
+    COALESCE(ch.so_nho_nhat, 1)::INT AS so_nho_nhat,
+    ch.so_lon_nhat,
+    COALESCE(ch.tuoi_tre_em_toi_thieu, 2)::INT AS tuoi_tre_em_toi_thieu,
+    COALESCE(ch.tuoi_tre_em_toi_da, 11)::INT AS tuoi_tre_em_toi_da
+FROM khoi_hanh_tour kh
+LEFT JOIN cau_hinh_nhom_tour ch ON ch.tour_id = kh.tour_id
+WHERE kh.id = $1
+ORDER BY ch.id
+LIMIT 1
+`
+
+type GetDepartureGroupRulesRow struct {
+	KhoiHanhID        int32       `json:"khoi_hanh_id"`
+	TourID            int32       `json:"tour_id"`
+	NgayKhoiHanh      pgtype.Date `json:"ngay_khoi_hanh"`
+	SoNhoNhat         int32       `json:"so_nho_nhat"`
+	SoLonNhat         *int32      `json:"so_lon_nhat"`
+	TuoiTreEmToiThieu int32       `json:"tuoi_tre_em_toi_thieu"`
+	TuoiTreEmToiDa    int32       `json:"tuoi_tre_em_toi_da"`
+}
+
+// Quy tắc nhóm khách của tour theo khởi hành (mặc định khi tour chưa cấu hình)
+func (q *Queries) GetDepartureGroupRules(ctx context.Context, id int32) (GetDepartureGroupRulesRow, error) {
+	row := q.db.QueryRow(ctx, getDepartureGroupRules, id)
+	var i GetDepartureGroupRulesRow
+	err := row.Scan(
+		&i.KhoiHanhID,
+		&i.TourID,
+		&i.NgayKhoiHanh,
+		&i.SoNhoNhat,
+		&i.SoLonNhat,
+		&i.TuoiTreEmToiThieu,
+		&i.TuoiTreEmToiDa,
+	)
+	return i, err
+}
+
 const getPassengersByBooking = `-- name: GetPassengersByBooking :many
 SELECT id, dat_cho_id, ho_ten, ngay_sinh, loai_khach, gioi_tinh, so_giay_to_tuy_thanh, quoc_tich, ghi_chu FROM hanh_khach
 WHERE dat_cho_id = $1
@@ -1270,7 +1348,7 @@ func (q *Queries) GetPaymentGateways(ctx context.Context) ([]CongThanhToan, erro
 
 const getPendingBookings = `-- name: GetPendingBookings :many
 SELECT 
-    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat,
+    dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat, dc.doi_tac_id, dc.khoa_api_id, dc.chinh_sach_huy_ap_dung,
     nd.ho_ten AS ten_nguoi_dat,
     nd.email,
     nd.so_dien_thoai,
@@ -1305,6 +1383,9 @@ type GetPendingBookingsRow struct {
 	PhuongThucThanhToan *string             `json:"phuong_thuc_thanh_toan"`
 	NgayDat             pgtype.Timestamp    `json:"ngay_dat"`
 	NgayCapNhat         pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	DoiTacID            *int32              `json:"doi_tac_id"`
+	KhoaApiID           *int32              `json:"khoa_api_id"`
+	ChinhSachHuyApDung  json.RawMessage     `json:"chinh_sach_huy_ap_dung"`
 	TenNguoiDat         string              `json:"ten_nguoi_dat"`
 	Email               string              `json:"email"`
 	SoDienThoai         *string             `json:"so_dien_thoai"`
@@ -1335,6 +1416,9 @@ func (q *Queries) GetPendingBookings(ctx context.Context, arg GetPendingBookings
 			&i.PhuongThucThanhToan,
 			&i.NgayDat,
 			&i.NgayCapNhat,
+			&i.DoiTacID,
+			&i.KhoaApiID,
+			&i.ChinhSachHuyApDung,
 			&i.TenNguoiDat,
 			&i.Email,
 			&i.SoDienThoai,
@@ -1685,7 +1769,7 @@ func (q *Queries) GetSupplierRefunds(ctx context.Context, arg GetSupplierRefunds
 }
 
 const getTransactionByCode = `-- name: GetTransactionByCode :one
-SELECT id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh FROM lich_su_giao_dich
+SELECT id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu FROM lich_su_giao_dich
 WHERE ma_giao_dich_noi_bo = $1
 `
 
@@ -1706,13 +1790,19 @@ func (q *Queries) GetTransactionByCode(ctx context.Context, maGiaoDichNoiBo stri
 		&i.NoiDungChuyenKhoan,
 		&i.NgayTao,
 		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
 	)
 	return i, err
 }
 
 const getTransactionsByBooking = `-- name: GetTransactionsByBooking :many
 SELECT 
-    lsgd.id, lsgd.dat_cho_id, lsgd.nguoi_dung_id, lsgd.ma_giao_dich_noi_bo, lsgd.ma_tham_chieu_cong_thanh_toan, lsgd.cong_thanh_toan_id, lsgd.so_tien, lsgd.loai_giao_dich, lsgd.trang_thai, lsgd.noi_dung_chuyen_khoan, lsgd.ngay_tao, lsgd.ngay_hoan_thanh,
+    lsgd.id, lsgd.dat_cho_id, lsgd.nguoi_dung_id, lsgd.ma_giao_dich_noi_bo, lsgd.ma_tham_chieu_cong_thanh_toan, lsgd.cong_thanh_toan_id, lsgd.so_tien, lsgd.loai_giao_dich, lsgd.trang_thai, lsgd.noi_dung_chuyen_khoan, lsgd.ngay_tao, lsgd.ngay_hoan_thanh, lsgd.giao_dich_goc_id, lsgd.ly_do, lsgd.ghi_chu_xu_ly, lsgd.nguoi_duyet_id, lsgd.ngay_duyet, lsgd.so_lan_thu,
     ctt.ten_hien_thi AS ten_cong_thanh_toan
 FROM lich_su_giao_dich lsgd
 LEFT JOIN cong_thanh_toan ctt ON ctt.id = lsgd.cong_thanh_toan_id
@@ -1733,6 +1823,12 @@ type GetTransactionsByBookingRow struct {
 	NoiDungChuyenKhoan       *string                `json:"noi_dung_chuyen_khoan"`
 	NgayTao                  pgtype.Timestamp       `json:"ngay_tao"`
 	NgayHoanThanh            pgtype.Timestamp       `json:"ngay_hoan_thanh"`
+	GiaoDichGocID            *int32                 `json:"giao_dich_goc_id"`
+	LyDo                     *string                `json:"ly_do"`
+	GhiChuXuLy               *string                `json:"ghi_chu_xu_ly"`
+	NguoiDuyetID             pgtype.UUID            `json:"nguoi_duyet_id"`
+	NgayDuyet                pgtype.Timestamp       `json:"ngay_duyet"`
+	SoLanThu                 int32                  `json:"so_lan_thu"`
 	TenCongThanhToan         *string                `json:"ten_cong_thanh_toan"`
 }
 
@@ -1759,6 +1855,12 @@ func (q *Queries) GetTransactionsByBooking(ctx context.Context, datChoID *int32)
 			&i.NoiDungChuyenKhoan,
 			&i.NgayTao,
 			&i.NgayHoanThanh,
+			&i.GiaoDichGocID,
+			&i.LyDo,
+			&i.GhiChuXuLy,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.SoLanThu,
 			&i.TenCongThanhToan,
 		); err != nil {
 			return nil, err
@@ -1786,6 +1888,66 @@ func (q *Queries) HoldSeat(ctx context.Context, arg HoldSeatParams) error {
 	return err
 }
 
+const lockEditableBookingForPassengers = `-- name: LockEditableBookingForPassengers :one
+SELECT id FROM dat_cho
+WHERE id = $1 AND trang_thai IN ('cho_xac_nhan', 'da_xac_nhan')
+FOR UPDATE
+`
+
+// Khóa booking khi thay danh sách hành khách; chỉ booking chưa thanh toán (cho_xac_nhan, da_xac_nhan) được sửa
+func (q *Queries) LockEditableBookingForPassengers(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, lockEditableBookingForPassengers, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateBookingPassengerMix = `-- name: UpdateBookingPassengerMix :one
+UPDATE dat_cho dc
+SET so_nguoi_lon = $1::int,
+    so_tre_em = $2::int,
+    tong_tien = gia.tong_tien,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM tinh_gia_tour(
+    (SELECT khoi_hanh_id FROM dat_cho WHERE id = $3::int),
+    $1::int,
+    $2::int
+) gia
+WHERE dc.id = $3::int
+    AND dc.trang_thai = 'cho_xac_nhan'
+    AND COALESCE(dc.so_nguoi_lon, 0) + COALESCE(dc.so_tre_em, 0) = $1::int + $2::int
+RETURNING dc.id, dc.nguoi_dung_id, dc.khoi_hanh_id, dc.so_nguoi_lon, dc.so_tre_em, dc.tong_tien, dc.don_vi_tien_te, dc.trang_thai, dc.phuong_thuc_thanh_toan, dc.ngay_dat, dc.ngay_cap_nhat, dc.doi_tac_id, dc.khoa_api_id, dc.chinh_sach_huy_ap_dung
+`
+
+type UpdateBookingPassengerMixParams struct {
+	SoNguoiLon int32 `json:"so_nguoi_lon"`
+	SoTreEm    int32 `json:"so_tre_em"`
+	ID         int32 `json:"id"`
+}
+
+// Cập nhật số người lớn / trẻ em theo danh sách hành khách và tính lại tổng tiền.
+// Chỉ áp dụng cho booking chưa thanh toán và không đổi tổng số khách (số chỗ đã giữ)
+func (q *Queries) UpdateBookingPassengerMix(ctx context.Context, arg UpdateBookingPassengerMixParams) (DatCho, error) {
+	row := q.db.QueryRow(ctx, updateBookingPassengerMix, arg.SoNguoiLon, arg.SoTreEm, arg.ID)
+	var i DatCho
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.KhoiHanhID,
+		&i.SoNguoiLon,
+		&i.SoTreEm,
+		&i.TongTien,
+		&i.DonViTienTe,
+		&i.TrangThai,
+		&i.PhuongThucThanhToan,
+		&i.NgayDat,
+		&i.NgayCapNhat,
+		&i.DoiTacID,
+		&i.KhoaApiID,
+		&i.ChinhSachHuyApDung,
+	)
+	return i, err
+}
+
 const updateBookingPaymentStatus = `-- name: UpdateBookingPaymentStatus :one
 UPDATE dat_cho
 SET 
@@ -1793,7 +1955,7 @@ SET
     phuong_thuc_thanh_toan = $2,
     ngay_cap_nhat = CURRENT_TIMESTAMP
 WHERE id = $1 AND trang_thai IN ('cho_xac_nhan', 'da_xac_nhan')
-RETURNING id, nguoi_dung_id, khoi_hanh_id, so_nguoi_lon, so_tre_em, tong_tien, don_vi_tien_te, trang_thai, phuong_thuc_thanh_toan, ngay_dat, ngay_cap_nhat
+RETURNING id, nguoi_dung_id, khoi_hanh_id, so_nguoi_lon, so_tre_em, tong_tien, don_vi_tien_te, trang_thai, phuong_thuc_thanh_toan, ngay_dat, ngay_cap_nhat, doi_tac_id, khoa_api_id, chinh_sach_huy_ap_dung
 `
 
 type UpdateBookingPaymentStatusParams struct {
@@ -1817,6 +1979,9 @@ func (q *Queries) UpdateBookingPaymentStatus(ctx context.Context, arg UpdateBook
 		&i.PhuongThucThanhToan,
 		&i.NgayDat,
 		&i.NgayCapNhat,
+		&i.DoiTacID,
+		&i.KhoaApiID,
+		&i.ChinhSachHuyApDung,
 	)
 	return i, err
 }
@@ -1880,7 +2045,7 @@ SET
     ma_tham_chieu_cong_thanh_toan = $3,
     ngay_hoan_thanh = CASE WHEN $2 = 'thanh_cong' THEN CURRENT_TIMESTAMP ELSE NULL END
 WHERE id = $1
-RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
 `
 
 type UpdateTransactionStatusParams struct {
@@ -1906,6 +2071,12 @@ func (q *Queries) UpdateTransactionStatus(ctx context.Context, arg UpdateTransac
 		&i.NoiDungChuyenKhoan,
 		&i.NgayTao,
 		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
 	)
 	return i, err
 }
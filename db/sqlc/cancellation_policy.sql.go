@@ -0,0 +1,385 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: cancellation_policy.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createCancellationPolicy = `-- name: CreateCancellationPolicy :one
+
+INSERT INTO chinh_sach_huy (
+    nha_cung_cap_id,
+    ten,
+    mo_ta,
+    khong_hoan_tien,
+    phi_co_dinh
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING id, nha_cung_cap_id, ten, mo_ta, khong_hoan_tien, phi_co_dinh, ngay_tao, ngay_cap_nhat
+`
+
+type CreateCancellationPolicyParams struct {
+	NhaCungCapID  pgtype.UUID    `json:"nha_cung_cap_id"`
+	Ten           string         `json:"ten"`
+	MoTa          *string        `json:"mo_ta"`
+	KhongHoanTien bool           `json:"khong_hoan_tien"`
+	PhiCoDinh     pgtype.Numeric `json:"phi_co_dinh"`
+}
+
+// ===========================================
+// CHÍNH SÁCH HỦY TOUR (CANCELLATION POLICY)
+// ===========================================
+func (q *Queries) CreateCancellationPolicy(ctx context.Context, arg CreateCancellationPolicyParams) (ChinhSachHuy, error) {
+	row := q.db.QueryRow(ctx, createCancellationPolicy,
+		arg.NhaCungCapID,
+		arg.Ten,
+		arg.MoTa,
+		arg.KhongHoanTien,
+		arg.PhiCoDinh,
+	)
+	var i ChinhSachHuy
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.Ten,
+		&i.MoTa,
+		&i.KhongHoanTien,
+		&i.PhiCoDinh,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const createCancellationPolicyTier = `-- name: CreateCancellationPolicyTier :one
+INSERT INTO bac_chinh_sach_huy (
+    chinh_sach_huy_id,
+    so_ngay_toi_thieu,
+    phan_tram_hoan
+) VALUES (
+    $1, $2, $3
+)
+RETURNING id, chinh_sach_huy_id, so_ngay_toi_thieu, phan_tram_hoan
+`
+
+type CreateCancellationPolicyTierParams struct {
+	ChinhSachHuyID int32          `json:"chinh_sach_huy_id"`
+	SoNgayToiThieu int32          `json:"so_ngay_toi_thieu"`
+	PhanTramHoan   pgtype.Numeric `json:"phan_tram_hoan"`
+}
+
+func (q *Queries) CreateCancellationPolicyTier(ctx context.Context, arg CreateCancellationPolicyTierParams) (BacChinhSachHuy, error) {
+	row := q.db.QueryRow(ctx, createCancellationPolicyTier, arg.ChinhSachHuyID, arg.SoNgayToiThieu, arg.PhanTramHoan)
+	var i BacChinhSachHuy
+	err := row.Scan(
+		&i.ID,
+		&i.ChinhSachHuyID,
+		&i.SoNgayToiThieu,
+		&i.PhanTramHoan,
+	)
+	return i, err
+}
+
+const deleteCancellationPolicy = `-- name: DeleteCancellationPolicy :execrows
+DELETE FROM chinh_sach_huy
+WHERE id = $1 AND nha_cung_cap_id = $2
+`
+
+type DeleteCancellationPolicyParams struct {
+	ID           int32       `json:"id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+// Tour/khởi hành đang dùng chính sách sẽ quay về chính sách mặc định (ON DELETE SET NULL)
+func (q *Queries) DeleteCancellationPolicy(ctx context.Context, arg DeleteCancellationPolicyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCancellationPolicy, arg.ID, arg.NhaCungCapID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteCancellationPolicyTiers = `-- name: DeleteCancellationPolicyTiers :exec
+DELETE FROM bac_chinh_sach_huy
+WHERE chinh_sach_huy_id = $1
+`
+
+func (q *Queries) DeleteCancellationPolicyTiers(ctx context.Context, chinhSachHuyID int32) error {
+	_, err := q.db.Exec(ctx, deleteCancellationPolicyTiers, chinhSachHuyID)
+	return err
+}
+
+const getCancellationPoliciesBySupplier = `-- name: GetCancellationPoliciesBySupplier :many
+SELECT
+    csh.id, csh.nha_cung_cap_id, csh.ten, csh.mo_ta, csh.khong_hoan_tien, csh.phi_co_dinh, csh.ngay_tao, csh.ngay_cap_nhat,
+    COALESCE((
+        SELECT json_agg(json_build_object(
+            'so_ngay_toi_thieu', b.so_ngay_toi_thieu,
+            'phan_tram_hoan', b.phan_tram_hoan
+        ) ORDER BY b.so_ngay_toi_thieu DESC)
+        FROM bac_chinh_sach_huy b
+        WHERE b.chinh_sach_huy_id = csh.id
+    ), '[]')::json AS cac_bac,
+    (SELECT COUNT(*) FROM tour t WHERE t.chinh_sach_huy_id = csh.id)::INT AS so_tour,
+    (SELECT COUNT(*) FROM khoi_hanh_tour kh WHERE kh.chinh_sach_huy_id = csh.id)::INT AS so_khoi_hanh
+FROM chinh_sach_huy csh
+WHERE csh.nha_cung_cap_id = $1
+ORDER BY csh.ngay_tao DESC
+`
+
+type GetCancellationPoliciesBySupplierRow struct {
+	ID            int32            `json:"id"`
+	NhaCungCapID  pgtype.UUID      `json:"nha_cung_cap_id"`
+	Ten           string           `json:"ten"`
+	MoTa          *string          `json:"mo_ta"`
+	KhongHoanTien bool             `json:"khong_hoan_tien"`
+	PhiCoDinh     pgtype.Numeric   `json:"phi_co_dinh"`
+	NgayTao       pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat   pgtype.Timestamp `json:"ngay_cap_nhat"`
+	CacBac        []byte           `json:"cac_bac"`
+	SoTour        int32            `json:"so_tour"`
+	SoKhoiHanh    int32            `json:"so_khoi_hanh"`
+}
+
+// Danh sách chính sách hủy của nhà cung cấp kèm các mốc hoàn tiền và số tour/khởi hành đang dùng
+func (q *Queries) GetCancellationPoliciesBySupplier(ctx context.Context, nhaCungCapID pgtype.UUID) ([]GetCancellationPoliciesBySupplierRow, error) {
+	rows, err := q.db.Query(ctx, getCancellationPoliciesBySupplier, nhaCungCapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCancellationPoliciesBySupplierRow
+	for rows.Next() {
+		var i GetCancellationPoliciesBySupplierRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.Ten,
+			&i.MoTa,
+			&i.KhongHoanTien,
+			&i.PhiCoDinh,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+			&i.CacBac,
+			&i.SoTour,
+			&i.SoKhoiHanh,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTourCancellationPolicies = `-- name: GetTourCancellationPolicies :many
+SELECT
+    NULL::int AS khoi_hanh_id,
+    NULL::date AS ngay_khoi_hanh,
+    csh.id,
+    csh.ten,
+    csh.mo_ta,
+    csh.khong_hoan_tien,
+    csh.phi_co_dinh,
+    COALESCE((
+        SELECT json_agg(json_build_object(
+            'so_ngay_toi_thieu', b.so_ngay_toi_thieu,
+            'phan_tram_hoan', b.phan_tram_hoan
+        ) ORDER BY b.so_ngay_toi_thieu DESC)
+        FROM bac_chinh_sach_huy b
+        WHERE b.chinh_sach_huy_id = csh.id
+    ), '[]')::json AS cac_bac
+FROM tour t
+JOIN chinh_sach_huy csh ON csh.id = t.chinh_sach_huy_id
+WHERE t.id = $1::int
+UNION ALL
+SELECT
+    kh.id AS khoi_hanh_id,
+    kh.ngay_khoi_hanh,
+    csh.id,
+    csh.ten,
+    csh.mo_ta,
+    csh.khong_hoan_tien,
+    csh.phi_co_dinh,
+    COALESCE((
+        SELECT json_agg(json_build_object(
+            'so_ngay_toi_thieu', b.so_ngay_toi_thieu,
+            'phan_tram_hoan', b.phan_tram_hoan
+        ) ORDER BY b.so_ngay_toi_thieu DESC)
+        FROM bac_chinh_sach_huy b
+        WHERE b.chinh_sach_huy_id = csh.id
+    ), '[]')::json AS cac_bac
+FROM khoi_hanh_tour kh
+JOIN chinh_sach_huy csh ON csh.id = kh.chinh_sach_huy_id
+WHERE kh.tour_id = $1::int
+    AND kh.ngay_khoi_hanh >= CURRENT_DATE
+`
+
+type GetTourCancellationPoliciesRow struct {
+	KhoiHanhID    *int32         `json:"khoi_hanh_id"`
+	NgayKhoiHanh  pgtype.Date    `json:"ngay_khoi_hanh"`
+	ID            int32          `json:"id"`
+	Ten           string         `json:"ten"`
+	MoTa          *string        `json:"mo_ta"`
+	KhongHoanTien bool           `json:"khong_hoan_tien"`
+	PhiCoDinh     pgtype.Numeric `json:"phi_co_dinh"`
+	CacBac        []byte         `json:"cac_bac"`
+}
+
+// Chính sách hủy của tour (khoi_hanh_id NULL) và của các khởi hành sắp tới có chính sách riêng
+func (q *Queries) GetTourCancellationPolicies(ctx context.Context, tourID int32) ([]GetTourCancellationPoliciesRow, error) {
+	rows, err := q.db.Query(ctx, getTourCancellationPolicies, tourID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTourCancellationPoliciesRow
+	for rows.Next() {
+		var i GetTourCancellationPoliciesRow
+		if err := rows.Scan(
+			&i.KhoiHanhID,
+			&i.NgayKhoiHanh,
+			&i.ID,
+			&i.Ten,
+			&i.MoTa,
+			&i.KhongHoanTien,
+			&i.PhiCoDinh,
+			&i.CacBac,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setDepartureCancellationPolicy = `-- name: SetDepartureCancellationPolicy :one
+UPDATE khoi_hanh_tour kh
+SET chinh_sach_huy_id = $1,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM tour t
+WHERE kh.tour_id = t.id
+    AND kh.id = $2
+    AND t.nha_cung_cap_id = $3
+    AND (
+        $1::int IS NULL
+        OR EXISTS (
+            SELECT 1 FROM chinh_sach_huy csh
+            WHERE csh.id = $1::int
+                AND csh.nha_cung_cap_id = $3
+        )
+    )
+RETURNING kh.id, kh.tour_id, kh.chinh_sach_huy_id
+`
+
+type SetDepartureCancellationPolicyParams struct {
+	ChinhSachHuyID *int32      `json:"chinh_sach_huy_id"`
+	KhoiHanhID     int32       `json:"khoi_hanh_id"`
+	NhaCungCapID   pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+type SetDepartureCancellationPolicyRow struct {
+	ID             int32  `json:"id"`
+	TourID         int32  `json:"tour_id"`
+	ChinhSachHuyID *int32 `json:"chinh_sach_huy_id"`
+}
+
+// Gắn (hoặc gỡ) chính sách hủy riêng cho một khởi hành, ưu tiên hơn chính sách của tour
+func (q *Queries) SetDepartureCancellationPolicy(ctx context.Context, arg SetDepartureCancellationPolicyParams) (SetDepartureCancellationPolicyRow, error) {
+	row := q.db.QueryRow(ctx, setDepartureCancellationPolicy, arg.ChinhSachHuyID, arg.KhoiHanhID, arg.NhaCungCapID)
+	var i SetDepartureCancellationPolicyRow
+	err := row.Scan(&i.ID, &i.TourID, &i.ChinhSachHuyID)
+	return i, err
+}
+
+const setTourCancellationPolicy = `-- name: SetTourCancellationPolicy :one
+UPDATE tour t
+SET chinh_sach_huy_id = $1,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE t.id = $2
+    AND t.nha_cung_cap_id = $3
+    AND (
+        $1::int IS NULL
+        OR EXISTS (
+            SELECT 1 FROM chinh_sach_huy csh
+            WHERE csh.id = $1::int
+                AND csh.nha_cung_cap_id = $3
+        )
+    )
+RETURNING t.id, t.chinh_sach_huy_id
+`
+
+type SetTourCancellationPolicyParams struct {
+	ChinhSachHuyID *int32      `json:"chinh_sach_huy_id"`
+	TourID         int32       `json:"tour_id"`
+	NhaCungCapID   pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+type SetTourCancellationPolicyRow struct {
+	ID             int32  `json:"id"`
+	ChinhSachHuyID *int32 `json:"chinh_sach_huy_id"`
+}
+
+// Gắn (hoặc gỡ khi chinh_sach_huy_id NULL) chính sách hủy cho tour; chính sách phải thuộc cùng nhà cung cấp
+func (q *Queries) SetTourCancellationPolicy(ctx context.Context, arg SetTourCancellationPolicyParams) (SetTourCancellationPolicyRow, error) {
+	row := q.db.QueryRow(ctx, setTourCancellationPolicy, arg.ChinhSachHuyID, arg.TourID, arg.NhaCungCapID)
+	var i SetTourCancellationPolicyRow
+	err := row.Scan(&i.ID, &i.ChinhSachHuyID)
+	return i, err
+}
+
+const updateCancellationPolicy = `-- name: UpdateCancellationPolicy :one
+UPDATE chinh_sach_huy
+SET ten = $1,
+    mo_ta = $2,
+    khong_hoan_tien = $3,
+    phi_co_dinh = $4,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $5
+    AND nha_cung_cap_id = $6
+RETURNING id, nha_cung_cap_id, ten, mo_ta, khong_hoan_tien, phi_co_dinh, ngay_tao, ngay_cap_nhat
+`
+
+type UpdateCancellationPolicyParams struct {
+	Ten           string         `json:"ten"`
+	MoTa          *string        `json:"mo_ta"`
+	KhongHoanTien bool           `json:"khong_hoan_tien"`
+	PhiCoDinh     pgtype.Numeric `json:"phi_co_dinh"`
+	ID            int32          `json:"id"`
+	NhaCungCapID  pgtype.UUID    `json:"nha_cung_cap_id"`
+}
+
+func (q *Queries) UpdateCancellationPolicy(ctx context.Context, arg UpdateCancellationPolicyParams) (ChinhSachHuy, error) {
+	row := q.db.QueryRow(ctx, updateCancellationPolicy,
+		arg.Ten,
+		arg.MoTa,
+		arg.KhongHoanTien,
+		arg.PhiCoDinh,
+		arg.ID,
+		arg.NhaCungCapID,
+	)
+	var i ChinhSachHuy
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.Ten,
+		&i.MoTa,
+		&i.KhongHoanTien,
+		&i.PhiCoDinh,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
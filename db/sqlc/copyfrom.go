@@ -48,3 +48,36 @@ func (r iteratorForAddPassengers) Err() error {
 func (q *Queries) AddPassengers(ctx context.Context, arg []AddPassengersParams) (int64, error) {
 	return q.db.CopyFrom(ctx, []string{"hanh_khach"}, []string{"dat_cho_id", "ho_ten", "ngay_sinh", "loai_khach", "gioi_tinh", "so_giay_to_tuy_thanh", "quoc_tich", "ghi_chu"}, &iteratorForAddPassengers{rows: arg})
 }
+
+// iteratorForCreateRecoveryCodes implements pgx.CopyFromSource.
+type iteratorForCreateRecoveryCodes struct {
+	rows                 []CreateRecoveryCodesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCreateRecoveryCodes) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCreateRecoveryCodes) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].NguoiDungID,
+		r.rows[0].MaBam,
+	}, nil
+}
+
+func (r iteratorForCreateRecoveryCodes) Err() error {
+	return nil
+}
+
+func (q *Queries) CreateRecoveryCodes(ctx context.Context, arg []CreateRecoveryCodesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"ma_khoi_phuc_2fa"}, []string{"nguoi_dung_id", "ma_bam"}, &iteratorForCreateRecoveryCodes{rows: arg})
+}
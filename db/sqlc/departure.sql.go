@@ -71,7 +71,7 @@ SET
     trang_thai = 'huy',
     ngay_cap_nhat = NOW()
 WHERE id = $1
-RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat
+RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 func (q *Queries) CancelDeparture(ctx context.Context, id int32) (KhoiHanhTour, error) {
@@ -88,6 +88,7 @@ func (q *Queries) CancelDeparture(ctx context.Context, id int32) (KhoiHanhTour,
 		&i.GhiChu,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -103,7 +104,7 @@ INSERT INTO khoi_hanh_tour (
 ) VALUES (
     $1, $2, $3, $4, $5, $6
 )
-RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat
+RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 type CreateDepartureParams struct {
@@ -138,6 +139,7 @@ func (q *Queries) CreateDeparture(ctx context.Context, arg CreateDepartureParams
 		&i.GhiChu,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -183,7 +185,7 @@ func (q *Queries) DeleteTourDestination(ctx context.Context, arg DeleteTourDesti
 
 const getAllDepartures = `-- name: GetAllDepartures :many
 SELECT 
-    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat,
+    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat, kh.chinh_sach_huy_id,
     t.tieu_de as ten_tour,
     COALESCE(SUM(dc.so_nguoi_lon + dc.so_tre_em), 0) as so_cho_da_dat
 FROM khoi_hanh_tour kh
@@ -201,18 +203,19 @@ type GetAllDeparturesParams struct {
 }
 
 type GetAllDeparturesRow struct {
-	ID           int32                 `json:"id"`
-	TourID       int32                 `json:"tour_id"`
-	NgayKhoiHanh pgtype.Date           `json:"ngay_khoi_hanh"`
-	NgayKetThuc  pgtype.Date           `json:"ngay_ket_thuc"`
-	SucChua      int32                 `json:"suc_chua"`
-	SoChoDaDat   *int32                `json:"so_cho_da_dat"`
-	TrangThai    NullTrangThaiKhoiHanh `json:"trang_thai"`
-	GhiChu       *string               `json:"ghi_chu"`
-	NgayTao      pgtype.Timestamp      `json:"ngay_tao"`
-	NgayCapNhat  pgtype.Timestamp      `json:"ngay_cap_nhat"`
-	TenTour      string                `json:"ten_tour"`
-	SoChoDaDat_2 interface{}           `json:"so_cho_da_dat_2"`
+	ID             int32                 `json:"id"`
+	TourID         int32                 `json:"tour_id"`
+	NgayKhoiHanh   pgtype.Date           `json:"ngay_khoi_hanh"`
+	NgayKetThuc    pgtype.Date           `json:"ngay_ket_thuc"`
+	SucChua        int32                 `json:"suc_chua"`
+	SoChoDaDat     *int32                `json:"so_cho_da_dat"`
+	TrangThai      NullTrangThaiKhoiHanh `json:"trang_thai"`
+	GhiChu         *string               `json:"ghi_chu"`
+	NgayTao        pgtype.Timestamp      `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32                `json:"chinh_sach_huy_id"`
+	TenTour        string                `json:"ten_tour"`
+	SoChoDaDat_2   interface{}           `json:"so_cho_da_dat_2"`
 }
 
 func (q *Queries) GetAllDepartures(ctx context.Context, arg GetAllDeparturesParams) ([]GetAllDeparturesRow, error) {
@@ -235,6 +238,7 @@ func (q *Queries) GetAllDepartures(ctx context.Context, arg GetAllDeparturesPara
 			&i.GhiChu,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.ChinhSachHuyID,
 			&i.TenTour,
 			&i.SoChoDaDat_2,
 		); err != nil {
@@ -250,7 +254,7 @@ func (q *Queries) GetAllDepartures(ctx context.Context, arg GetAllDeparturesPara
 
 const getDepartureByID = `-- name: GetDepartureByID :one
 SELECT 
-    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat,
+    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat, kh.chinh_sach_huy_id,
     t.tieu_de as ten_tour,
     t.gia_nguoi_lon as gia_nguoi_lon,
     t.gia_tre_em as gia_tre_em,
@@ -266,22 +270,23 @@ GROUP BY kh.id, t.tieu_de, t.gia_nguoi_lon, t.gia_tre_em, t.don_vi_tien_te
 `
 
 type GetDepartureByIDRow struct {
-	ID            int32                 `json:"id"`
-	TourID        int32                 `json:"tour_id"`
-	NgayKhoiHanh  pgtype.Date           `json:"ngay_khoi_hanh"`
-	NgayKetThuc   pgtype.Date           `json:"ngay_ket_thuc"`
-	SucChua       int32                 `json:"suc_chua"`
-	SoChoDaDat    *int32                `json:"so_cho_da_dat"`
-	TrangThai     NullTrangThaiKhoiHanh `json:"trang_thai"`
-	GhiChu        *string               `json:"ghi_chu"`
-	NgayTao       pgtype.Timestamp      `json:"ngay_tao"`
-	NgayCapNhat   pgtype.Timestamp      `json:"ngay_cap_nhat"`
-	TenTour       string                `json:"ten_tour"`
-	GiaNguoiLon   pgtype.Numeric        `json:"gia_nguoi_lon"`
-	GiaTreEm      pgtype.Numeric        `json:"gia_tre_em"`
-	DonViTienTe   *string               `json:"don_vi_tien_te"`
-	SoChoDaDat_2  interface{}           `json:"so_cho_da_dat_2"`
-	SoChoConTrong int32                 `json:"so_cho_con_trong"`
+	ID             int32                 `json:"id"`
+	TourID         int32                 `json:"tour_id"`
+	NgayKhoiHanh   pgtype.Date           `json:"ngay_khoi_hanh"`
+	NgayKetThuc    pgtype.Date           `json:"ngay_ket_thuc"`
+	SucChua        int32                 `json:"suc_chua"`
+	SoChoDaDat     *int32                `json:"so_cho_da_dat"`
+	TrangThai      NullTrangThaiKhoiHanh `json:"trang_thai"`
+	GhiChu         *string               `json:"ghi_chu"`
+	NgayTao        pgtype.Timestamp      `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32                `json:"chinh_sach_huy_id"`
+	TenTour        string                `json:"ten_tour"`
+	GiaNguoiLon    pgtype.Numeric        `json:"gia_nguoi_lon"`
+	GiaTreEm       pgtype.Numeric        `json:"gia_tre_em"`
+	DonViTienTe    *string               `json:"don_vi_tien_te"`
+	SoChoDaDat_2   interface{}           `json:"so_cho_da_dat_2"`
+	SoChoConTrong  int32                 `json:"so_cho_con_trong"`
 }
 
 // lấy thông tin chi tiết của một lịch khởi hành
@@ -299,6 +304,7 @@ func (q *Queries) GetDepartureByID(ctx context.Context, id int32) (GetDepartureB
 		&i.GhiChu,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 		&i.TenTour,
 		&i.GiaNguoiLon,
 		&i.GiaTreEm,
@@ -311,7 +317,7 @@ func (q *Queries) GetDepartureByID(ctx context.Context, id int32) (GetDepartureB
 
 const getDeparturesByDateRange = `-- name: GetDeparturesByDateRange :many
 SELECT 
-    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat,
+    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat, kh.chinh_sach_huy_id,
     t.tieu_de as ten_tour,
     COALESCE(SUM(dc.so_nguoi_lon + dc.so_tre_em), 0) as so_cho_da_dat
 FROM khoi_hanh_tour kh
@@ -329,18 +335,19 @@ type GetDeparturesByDateRangeParams struct {
 }
 
 type GetDeparturesByDateRangeRow struct {
-	ID           int32                 `json:"id"`
-	TourID       int32                 `json:"tour_id"`
-	NgayKhoiHanh pgtype.Date           `json:"ngay_khoi_hanh"`
-	NgayKetThuc  pgtype.Date           `json:"ngay_ket_thuc"`
-	SucChua      int32                 `json:"suc_chua"`
-	SoChoDaDat   *int32                `json:"so_cho_da_dat"`
-	TrangThai    NullTrangThaiKhoiHanh `json:"trang_thai"`
-	GhiChu       *string               `json:"ghi_chu"`
-	NgayTao      pgtype.Timestamp      `json:"ngay_tao"`
-	NgayCapNhat  pgtype.Timestamp      `json:"ngay_cap_nhat"`
-	TenTour      string                `json:"ten_tour"`
-	SoChoDaDat_2 interface{}           `json:"so_cho_da_dat_2"`
+	ID             int32                 `json:"id"`
+	TourID         int32                 `json:"tour_id"`
+	NgayKhoiHanh   pgtype.Date           `json:"ngay_khoi_hanh"`
+	NgayKetThuc    pgtype.Date           `json:"ngay_ket_thuc"`
+	SucChua        int32                 `json:"suc_chua"`
+	SoChoDaDat     *int32                `json:"so_cho_da_dat"`
+	TrangThai      NullTrangThaiKhoiHanh `json:"trang_thai"`
+	GhiChu         *string               `json:"ghi_chu"`
+	NgayTao        pgtype.Timestamp      `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32                `json:"chinh_sach_huy_id"`
+	TenTour        string                `json:"ten_tour"`
+	SoChoDaDat_2   interface{}           `json:"so_cho_da_dat_2"`
 }
 
 func (q *Queries) GetDeparturesByDateRange(ctx context.Context, arg GetDeparturesByDateRangeParams) ([]GetDeparturesByDateRangeRow, error) {
@@ -363,6 +370,7 @@ func (q *Queries) GetDeparturesByDateRange(ctx context.Context, arg GetDeparture
 			&i.GhiChu,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.ChinhSachHuyID,
 			&i.TenTour,
 			&i.SoChoDaDat_2,
 		); err != nil {
@@ -378,7 +386,7 @@ func (q *Queries) GetDeparturesByDateRange(ctx context.Context, arg GetDeparture
 
 const getDeparturesByStatus = `-- name: GetDeparturesByStatus :many
 SELECT 
-    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat,
+    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat, kh.chinh_sach_huy_id,
     t.tieu_de as ten_tour,
     COALESCE(SUM(dc.so_nguoi_lon + dc.so_tre_em), 0) as so_cho_da_dat
 FROM khoi_hanh_tour kh
@@ -398,18 +406,19 @@ type GetDeparturesByStatusParams struct {
 }
 
 type GetDeparturesByStatusRow struct {
-	ID           int32                 `json:"id"`
-	TourID       int32                 `json:"tour_id"`
-	NgayKhoiHanh pgtype.Date           `json:"ngay_khoi_hanh"`
-	NgayKetThuc  pgtype.Date           `json:"ngay_ket_thuc"`
-	SucChua      int32                 `json:"suc_chua"`
-	SoChoDaDat   *int32                `json:"so_cho_da_dat"`
-	TrangThai    NullTrangThaiKhoiHanh `json:"trang_thai"`
-	GhiChu       *string               `json:"ghi_chu"`
-	NgayTao      pgtype.Timestamp      `json:"ngay_tao"`
-	NgayCapNhat  pgtype.Timestamp      `json:"ngay_cap_nhat"`
-	TenTour      string                `json:"ten_tour"`
-	SoChoDaDat_2 interface{}           `json:"so_cho_da_dat_2"`
+	ID             int32                 `json:"id"`
+	TourID         int32                 `json:"tour_id"`
+	NgayKhoiHanh   pgtype.Date           `json:"ngay_khoi_hanh"`
+	NgayKetThuc    pgtype.Date           `json:"ngay_ket_thuc"`
+	SucChua        int32                 `json:"suc_chua"`
+	SoChoDaDat     *int32                `json:"so_cho_da_dat"`
+	TrangThai      NullTrangThaiKhoiHanh `json:"trang_thai"`
+	GhiChu         *string               `json:"ghi_chu"`
+	NgayTao        pgtype.Timestamp      `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32                `json:"chinh_sach_huy_id"`
+	TenTour        string                `json:"ten_tour"`
+	SoChoDaDat_2   interface{}           `json:"so_cho_da_dat_2"`
 }
 
 func (q *Queries) GetDeparturesByStatus(ctx context.Context, arg GetDeparturesByStatusParams) ([]GetDeparturesByStatusRow, error) {
@@ -432,6 +441,7 @@ func (q *Queries) GetDeparturesByStatus(ctx context.Context, arg GetDeparturesBy
 			&i.GhiChu,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.ChinhSachHuyID,
 			&i.TenTour,
 			&i.SoChoDaDat_2,
 		); err != nil {
@@ -447,7 +457,7 @@ func (q *Queries) GetDeparturesByStatus(ctx context.Context, arg GetDeparturesBy
 
 const getDeparturesByTour = `-- name: GetDeparturesByTour :many
 SELECT 
-    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat,
+    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat, kh.chinh_sach_huy_id,
     COALESCE(SUM(dc.so_nguoi_lon + dc.so_tre_em), 0) as so_cho_da_dat,
     kh.suc_chua - COALESCE(SUM(dc.so_nguoi_lon + dc.so_tre_em), 0) as so_cho_con_trong
 FROM khoi_hanh_tour kh
@@ -459,18 +469,19 @@ ORDER BY kh.ngay_khoi_hanh ASC
 `
 
 type GetDeparturesByTourRow struct {
-	ID            int32                 `json:"id"`
-	TourID        int32                 `json:"tour_id"`
-	NgayKhoiHanh  pgtype.Date           `json:"ngay_khoi_hanh"`
-	NgayKetThuc   pgtype.Date           `json:"ngay_ket_thuc"`
-	SucChua       int32                 `json:"suc_chua"`
-	SoChoDaDat    *int32                `json:"so_cho_da_dat"`
-	TrangThai     NullTrangThaiKhoiHanh `json:"trang_thai"`
-	GhiChu        *string               `json:"ghi_chu"`
-	NgayTao       pgtype.Timestamp      `json:"ngay_tao"`
-	NgayCapNhat   pgtype.Timestamp      `json:"ngay_cap_nhat"`
-	SoChoDaDat_2  interface{}           `json:"so_cho_da_dat_2"`
-	SoChoConTrong int32                 `json:"so_cho_con_trong"`
+	ID             int32                 `json:"id"`
+	TourID         int32                 `json:"tour_id"`
+	NgayKhoiHanh   pgtype.Date           `json:"ngay_khoi_hanh"`
+	NgayKetThuc    pgtype.Date           `json:"ngay_ket_thuc"`
+	SucChua        int32                 `json:"suc_chua"`
+	SoChoDaDat     *int32                `json:"so_cho_da_dat"`
+	TrangThai      NullTrangThaiKhoiHanh `json:"trang_thai"`
+	GhiChu         *string               `json:"ghi_chu"`
+	NgayTao        pgtype.Timestamp      `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32                `json:"chinh_sach_huy_id"`
+	SoChoDaDat_2   interface{}           `json:"so_cho_da_dat_2"`
+	SoChoConTrong  int32                 `json:"so_cho_con_trong"`
 }
 
 // lấy danh sách lịch khởi hành của một tour
@@ -494,6 +505,7 @@ func (q *Queries) GetDeparturesByTour(ctx context.Context, tourID int32) ([]GetD
 			&i.GhiChu,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.ChinhSachHuyID,
 			&i.SoChoDaDat_2,
 			&i.SoChoConTrong,
 		); err != nil {
@@ -530,7 +542,7 @@ func (q *Queries) GetHinhAnhTourByID(ctx context.Context, id int32) (AnhTour, er
 
 const getUpcomingDeparturesList = `-- name: GetUpcomingDeparturesList :many
 SELECT 
-    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat,
+    kh.id, kh.tour_id, kh.ngay_khoi_hanh, kh.ngay_ket_thuc, kh.suc_chua, kh.so_cho_da_dat, kh.trang_thai, kh.ghi_chu, kh.ngay_tao, kh.ngay_cap_nhat, kh.chinh_sach_huy_id,
     t.tieu_de as ten_tour,
     t.gia_nguoi_lon,
     t.gia_tre_em,
@@ -547,20 +559,21 @@ LIMIT $1
 `
 
 type GetUpcomingDeparturesListRow struct {
-	ID           int32                 `json:"id"`
-	TourID       int32                 `json:"tour_id"`
-	NgayKhoiHanh pgtype.Date           `json:"ngay_khoi_hanh"`
-	NgayKetThuc  pgtype.Date           `json:"ngay_ket_thuc"`
-	SucChua      int32                 `json:"suc_chua"`
-	SoChoDaDat   *int32                `json:"so_cho_da_dat"`
-	TrangThai    NullTrangThaiKhoiHanh `json:"trang_thai"`
-	GhiChu       *string               `json:"ghi_chu"`
-	NgayTao      pgtype.Timestamp      `json:"ngay_tao"`
-	NgayCapNhat  pgtype.Timestamp      `json:"ngay_cap_nhat"`
-	TenTour      string                `json:"ten_tour"`
-	GiaNguoiLon  pgtype.Numeric        `json:"gia_nguoi_lon"`
-	GiaTreEm     pgtype.Numeric        `json:"gia_tre_em"`
-	SoChoDaDat_2 interface{}           `json:"so_cho_da_dat_2"`
+	ID             int32                 `json:"id"`
+	TourID         int32                 `json:"tour_id"`
+	NgayKhoiHanh   pgtype.Date           `json:"ngay_khoi_hanh"`
+	NgayKetThuc    pgtype.Date           `json:"ngay_ket_thuc"`
+	SucChua        int32                 `json:"suc_chua"`
+	SoChoDaDat     *int32                `json:"so_cho_da_dat"`
+	TrangThai      NullTrangThaiKhoiHanh `json:"trang_thai"`
+	GhiChu         *string               `json:"ghi_chu"`
+	NgayTao        pgtype.Timestamp      `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32                `json:"chinh_sach_huy_id"`
+	TenTour        string                `json:"ten_tour"`
+	GiaNguoiLon    pgtype.Numeric        `json:"gia_nguoi_lon"`
+	GiaTreEm       pgtype.Numeric        `json:"gia_tre_em"`
+	SoChoDaDat_2   interface{}           `json:"so_cho_da_dat_2"`
 }
 
 func (q *Queries) GetUpcomingDeparturesList(ctx context.Context, limit int32) ([]GetUpcomingDeparturesListRow, error) {
@@ -583,6 +596,7 @@ func (q *Queries) GetUpcomingDeparturesList(ctx context.Context, limit int32) ([
 			&i.GhiChu,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.ChinhSachHuyID,
 			&i.TenTour,
 			&i.GiaNguoiLon,
 			&i.GiaTreEm,
@@ -608,7 +622,7 @@ SET
     ghi_chu = COALESCE($6, ghi_chu),
     ngay_cap_nhat = NOW()
 WHERE id = $1
-RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat
+RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 type UpdateDepartureParams struct {
@@ -641,6 +655,7 @@ func (q *Queries) UpdateDeparture(ctx context.Context, arg UpdateDepartureParams
 		&i.GhiChu,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -651,7 +666,7 @@ SET
     suc_chua = $2,
     ngay_cap_nhat = NOW()
 WHERE id = $1
-RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat
+RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 type UpdateDepartureCapacityParams struct {
@@ -673,6 +688,7 @@ func (q *Queries) UpdateDepartureCapacity(ctx context.Context, arg UpdateDepartu
 		&i.GhiChu,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -682,7 +698,7 @@ UPDATE khoi_hanh_tour
 SET 
     ngay_cap_nhat = NOW()
 WHERE id = $1
-RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat
+RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 func (q *Queries) UpdateDepartureStat(ctx context.Context, id int32) (KhoiHanhTour, error) {
@@ -699,6 +715,7 @@ func (q *Queries) UpdateDepartureStat(ctx context.Context, id int32) (KhoiHanhTo
 		&i.GhiChu,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -758,7 +775,7 @@ SET
     so_cho_da_dat = COALESCE($8, so_cho_da_dat),
     ngay_cap_nhat = NOW()
 WHERE id = $1 AND tour_id = $2
-RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat
+RETURNING id, tour_id, ngay_khoi_hanh, ngay_ket_thuc, suc_chua, so_cho_da_dat, trang_thai, ghi_chu, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 type UpdateKhoiHanhTourParams struct {
@@ -795,6 +812,7 @@ func (q *Queries) UpdateKhoiHanhTour(ctx context.Context, arg UpdateKhoiHanhTour
 		&i.GhiChu,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -0,0 +1,249 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: geo.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getDestinationsNearPoint = `-- name: GetDestinationsNearPoint :many
+WITH dd AS (
+  SELECT
+    d.id, d.ten, d.tinh, d.quoc_gia, d.khu_vuc, d.anh,
+    d.vi_do::FLOAT AS vi_do,
+    d.kinh_do::FLOAT AS kinh_do,
+    (6371 * 2 * ASIN(LEAST(1, SQRT(
+      POWER(SIN(RADIANS(d.vi_do::FLOAT - $4::FLOAT) / 2), 2)
+      + COS(RADIANS($4::FLOAT)) * COS(RADIANS(d.vi_do::FLOAT))
+        * POWER(SIN(RADIANS(d.kinh_do::FLOAT - $5::FLOAT) / 2), 2)
+    ))))::FLOAT AS khoang_cach_km
+  FROM diem_den d
+  WHERE d.vi_do IS NOT NULL AND d.kinh_do IS NOT NULL
+    AND d.vi_do BETWEEN $6::NUMERIC AND $7::NUMERIC
+    AND (
+      ($8::NUMERIC <= $9::NUMERIC AND d.kinh_do BETWEEN $8::NUMERIC AND $9::NUMERIC)
+      OR ($8::NUMERIC > $9::NUMERIC AND (d.kinh_do >= $8::NUMERIC OR d.kinh_do <= $9::NUMERIC))
+    )
+),
+so_tour AS (
+  SELECT tdd.diem_den_id, COUNT(DISTINCT t.id)::INT AS so_luong_tour
+  FROM tour_diem_den tdd
+  JOIN tour t ON t.id = tdd.tour_id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+  GROUP BY tdd.diem_den_id
+)
+SELECT
+  dd.id, dd.ten, dd.tinh, dd.quoc_gia, dd.khu_vuc, dd.anh,
+  dd.vi_do, dd.kinh_do, dd.khoang_cach_km,
+  COALESCE(st.so_luong_tour, 0)::INT AS so_luong_tour,
+  COUNT(*) OVER ()::INT AS tong
+FROM dd
+LEFT JOIN so_tour st ON st.diem_den_id = dd.id
+WHERE ($1::FLOAT IS NULL OR dd.khoang_cach_km <= $1::FLOAT)
+  AND ($2::BOOLEAN = FALSE OR COALESCE(st.so_luong_tour, 0) > 0)
+ORDER BY dd.khoang_cach_km ASC, so_luong_tour DESC, dd.id ASC
+LIMIT $3
+`
+
+type GetDestinationsNearPointParams struct {
+	BanKinhKm *float64       `json:"ban_kinh_km"`
+	ChiCoTour bool           `json:"chi_co_tour"`
+	Limit     int32          `json:"limit"`
+	Lat       float64        `json:"lat"`
+	Lng       float64        `json:"lng"`
+	MinLat    pgtype.Numeric `json:"min_lat"`
+	MaxLat    pgtype.Numeric `json:"max_lat"`
+	MinLng    pgtype.Numeric `json:"min_lng"`
+	MaxLng    pgtype.Numeric `json:"max_lng"`
+}
+
+type GetDestinationsNearPointRow struct {
+	ID           int32   `json:"id"`
+	Ten          string  `json:"ten"`
+	Tinh         *string `json:"tinh"`
+	QuocGia      *string `json:"quoc_gia"`
+	KhuVuc       *string `json:"khu_vuc"`
+	Anh          *string `json:"anh"`
+	ViDo         float64 `json:"vi_do"`
+	KinhDo       float64 `json:"kinh_do"`
+	KhoangCachKm float64 `json:"khoang_cach_km"`
+	SoLuongTour  int32   `json:"so_luong_tour"`
+	Tong         int32   `json:"tong"`
+}
+
+func (q *Queries) GetDestinationsNearPoint(ctx context.Context, arg GetDestinationsNearPointParams) ([]GetDestinationsNearPointRow, error) {
+	rows, err := q.db.Query(ctx, getDestinationsNearPoint,
+		arg.BanKinhKm,
+		arg.ChiCoTour,
+		arg.Limit,
+		arg.Lat,
+		arg.Lng,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLng,
+		arg.MaxLng,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDestinationsNearPointRow
+	for rows.Next() {
+		var i GetDestinationsNearPointRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ten,
+			&i.Tinh,
+			&i.QuocGia,
+			&i.KhuVuc,
+			&i.Anh,
+			&i.ViDo,
+			&i.KinhDo,
+			&i.KhoangCachKm,
+			&i.SoLuongTour,
+			&i.Tong,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getToursNearPoint = `-- name: GetToursNearPoint :many
+
+WITH dd AS (
+  SELECT
+    d.id, d.ten, d.tinh, d.quoc_gia,
+    d.vi_do::FLOAT AS vi_do,
+    d.kinh_do::FLOAT AS kinh_do,
+    (6371 * 2 * ASIN(LEAST(1, SQRT(
+      POWER(SIN(RADIANS(d.vi_do::FLOAT - $2::FLOAT) / 2), 2)
+      + COS(RADIANS($2::FLOAT)) * COS(RADIANS(d.vi_do::FLOAT))
+        * POWER(SIN(RADIANS(d.kinh_do::FLOAT - $3::FLOAT) / 2), 2)
+    ))))::FLOAT AS khoang_cach_km
+  FROM diem_den d
+  WHERE d.vi_do IS NOT NULL AND d.kinh_do IS NOT NULL
+    AND d.vi_do BETWEEN $4::NUMERIC AND $5::NUMERIC
+    AND (
+      ($6::NUMERIC <= $7::NUMERIC AND d.kinh_do BETWEEN $6::NUMERIC AND $7::NUMERIC)
+      OR ($6::NUMERIC > $7::NUMERIC AND (d.kinh_do >= $6::NUMERIC OR d.kinh_do <= $7::NUMERIC))
+    )
+),
+gan_nhat AS (
+  SELECT DISTINCT ON (t.id)
+    t.id, t.tieu_de, t.so_ngay, t.so_dem, t.gia_nguoi_lon, t.don_vi_tien_te, t.noi_bat,
+    dd.id AS diem_den_id, dd.ten AS diem_den_ten, dd.tinh, dd.quoc_gia,
+    dd.vi_do, dd.kinh_do, dd.khoang_cach_km
+  FROM tour t
+  JOIN tour_diem_den tdd ON tdd.tour_id = t.id
+  JOIN dd ON dd.id = tdd.diem_den_id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+    AND ($8::FLOAT IS NULL OR dd.khoang_cach_km <= $8::FLOAT)
+  ORDER BY t.id, dd.khoang_cach_km ASC
+)
+SELECT
+  g.id, g.tieu_de, g.so_ngay, g.so_dem, g.gia_nguoi_lon, g.don_vi_tien_te, g.noi_bat,
+  g.diem_den_id, g.diem_den_ten, g.tinh, g.quoc_gia,
+  g.vi_do, g.kinh_do, g.khoang_cach_km,
+  COALESCE(anh.duong_dan, '')::TEXT AS anh_chinh,
+  COUNT(*) OVER ()::INT AS tong
+FROM gan_nhat g
+LEFT JOIN LATERAL (
+  SELECT a.duong_dan FROM anh_tour a
+  WHERE a.tour_id = g.id
+  ORDER BY COALESCE(a.la_anh_chinh, FALSE) DESC, COALESCE(a.thu_tu_hien_thi, 0) ASC
+  LIMIT 1
+) anh ON TRUE
+ORDER BY g.khoang_cach_km ASC, g.noi_bat DESC, g.id ASC
+LIMIT $1
+`
+
+type GetToursNearPointParams struct {
+	Limit     int32          `json:"limit"`
+	Lat       float64        `json:"lat"`
+	Lng       float64        `json:"lng"`
+	MinLat    pgtype.Numeric `json:"min_lat"`
+	MaxLat    pgtype.Numeric `json:"max_lat"`
+	MinLng    pgtype.Numeric `json:"min_lng"`
+	MaxLng    pgtype.Numeric `json:"max_lng"`
+	BanKinhKm *float64       `json:"ban_kinh_km"`
+}
+
+type GetToursNearPointRow struct {
+	ID           int32          `json:"id"`
+	TieuDe       string         `json:"tieu_de"`
+	SoNgay       int32          `json:"so_ngay"`
+	SoDem        int32          `json:"so_dem"`
+	GiaNguoiLon  pgtype.Numeric `json:"gia_nguoi_lon"`
+	DonViTienTe  *string        `json:"don_vi_tien_te"`
+	NoiBat       *bool          `json:"noi_bat"`
+	DiemDenID    int32          `json:"diem_den_id"`
+	DiemDenTen   string         `json:"diem_den_ten"`
+	Tinh         *string        `json:"tinh"`
+	QuocGia      *string        `json:"quoc_gia"`
+	ViDo         float64        `json:"vi_do"`
+	KinhDo       float64        `json:"kinh_do"`
+	KhoangCachKm float64        `json:"khoang_cach_km"`
+	AnhChinh     string         `json:"anh_chinh"`
+	Tong         int32          `json:"tong"`
+}
+
+// Tìm theo vị trí dựa trên tọa độ diem_den (vi_do, kinh_do).
+// Khung tọa độ (min/max) luôn được truyền vào để dùng index; với tìm theo bán kính, khung là hình chữ nhật bao quanh vòng tròn
+// và ban_kinh_km loại tiếp các điểm nằm ngoài vòng tròn. min_lng > max_lng nghĩa là khung vắt qua kinh tuyến 180.
+// Khoảng cách (km) tính theo công thức haversine từ điểm (lat, lng).
+// Mỗi tour lấy điểm đến gần nhất nằm trong vùng tìm kiếm làm vị trí ghim trên bản đồ
+func (q *Queries) GetToursNearPoint(ctx context.Context, arg GetToursNearPointParams) ([]GetToursNearPointRow, error) {
+	rows, err := q.db.Query(ctx, getToursNearPoint,
+		arg.Limit,
+		arg.Lat,
+		arg.Lng,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLng,
+		arg.MaxLng,
+		arg.BanKinhKm,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetToursNearPointRow
+	for rows.Next() {
+		var i GetToursNearPointRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TieuDe,
+			&i.SoNgay,
+			&i.SoDem,
+			&i.GiaNguoiLon,
+			&i.DonViTienTe,
+			&i.NoiBat,
+			&i.DiemDenID,
+			&i.DiemDenTen,
+			&i.Tinh,
+			&i.QuocGia,
+			&i.ViDo,
+			&i.KinhDo,
+			&i.KhoangCachKm,
+			&i.AnhChinh,
+			&i.Tong,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -64,28 +64,40 @@ const createGroupConfig = `-- name: CreateGroupConfig :one
 INSERT INTO cau_hinh_nhom_tour (
     tour_id,
     so_nho_nhat,
-    so_lon_nhat
+    so_lon_nhat,
+    tuoi_tre_em_toi_thieu,
+    tuoi_tre_em_toi_da
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, COALESCE($4::int, 2), COALESCE($5::int, 11)
 )
-RETURNING id, tour_id, so_nho_nhat, so_lon_nhat
+RETURNING id, tour_id, so_nho_nhat, so_lon_nhat, tuoi_tre_em_toi_thieu, tuoi_tre_em_toi_da
 `
 
 type CreateGroupConfigParams struct {
-	TourID    int32  `json:"tour_id"`
-	SoNhoNhat *int32 `json:"so_nho_nhat"`
-	SoLonNhat *int32 `json:"so_lon_nhat"`
+	TourID            int32  `json:"tour_id"`
+	SoNhoNhat         *int32 `json:"so_nho_nhat"`
+	SoLonNhat         *int32 `json:"so_lon_nhat"`
+	TuoiTreEmToiThieu *int32 `json:"tuoi_tre_em_toi_thieu"`
+	TuoiTreEmToiDa    *int32 `json:"tuoi_tre_em_toi_da"`
 }
 
 // ==================== GROUP CONFIG QUERIES ====================
 func (q *Queries) CreateGroupConfig(ctx context.Context, arg CreateGroupConfigParams) (CauHinhNhomTour, error) {
-	row := q.db.QueryRow(ctx, createGroupConfig, arg.TourID, arg.SoNhoNhat, arg.SoLonNhat)
+	row := q.db.QueryRow(ctx, createGroupConfig,
+		arg.TourID,
+		arg.SoNhoNhat,
+		arg.SoLonNhat,
+		arg.TuoiTreEmToiThieu,
+		arg.TuoiTreEmToiDa,
+	)
 	var i CauHinhNhomTour
 	err := row.Scan(
 		&i.ID,
 		&i.TourID,
 		&i.SoNhoNhat,
 		&i.SoLonNhat,
+		&i.TuoiTreEmToiThieu,
+		&i.TuoiTreEmToiDa,
 	)
 	return i, err
 }
@@ -343,7 +355,7 @@ func (q *Queries) GetFullItineraryByTour(ctx context.Context, tourID int32) ([]G
 }
 
 const getGroupConfigByTour = `-- name: GetGroupConfigByTour :one
-SELECT id, tour_id, so_nho_nhat, so_lon_nhat FROM cau_hinh_nhom_tour
+SELECT id, tour_id, so_nho_nhat, so_lon_nhat, tuoi_tre_em_toi_thieu, tuoi_tre_em_toi_da FROM cau_hinh_nhom_tour
 WHERE tour_id = $1
 `
 
@@ -355,6 +367,8 @@ func (q *Queries) GetGroupConfigByTour(ctx context.Context, tourID int32) (CauHi
 		&i.TourID,
 		&i.SoNhoNhat,
 		&i.SoLonNhat,
+		&i.TuoiTreEmToiThieu,
+		&i.TuoiTreEmToiDa,
 	)
 	return i, err
 }
@@ -472,25 +486,37 @@ UPDATE cau_hinh_nhom_tour
 SET 
     so_nho_nhat = COALESCE($2, so_nho_nhat),
     so_lon_nhat = COALESCE($3, so_lon_nhat),
+    tuoi_tre_em_toi_thieu = COALESCE($4, tuoi_tre_em_toi_thieu),
+    tuoi_tre_em_toi_da = COALESCE($5, tuoi_tre_em_toi_da),
     ngay_cap_nhat = NOW()
 WHERE tour_id = $1
-RETURNING id, tour_id, so_nho_nhat, so_lon_nhat
+RETURNING id, tour_id, so_nho_nhat, so_lon_nhat, tuoi_tre_em_toi_thieu, tuoi_tre_em_toi_da
 `
 
 type UpdateGroupConfigParams struct {
-	TourID    int32  `json:"tour_id"`
-	SoNhoNhat *int32 `json:"so_nho_nhat"`
-	SoLonNhat *int32 `json:"so_lon_nhat"`
+	TourID            int32  `json:"tour_id"`
+	SoNhoNhat         *int32 `json:"so_nho_nhat"`
+	SoLonNhat         *int32 `json:"so_lon_nhat"`
+	TuoiTreEmToiThieu *int32 `json:"tuoi_tre_em_toi_thieu"`
+	TuoiTreEmToiDa    *int32 `json:"tuoi_tre_em_toi_da"`
 }
 
 func (q *Queries) UpdateGroupConfig(ctx context.Context, arg UpdateGroupConfigParams) (CauHinhNhomTour, error) {
-	row := q.db.QueryRow(ctx, updateGroupConfig, arg.TourID, arg.SoNhoNhat, arg.SoLonNhat)
+	row := q.db.QueryRow(ctx, updateGroupConfig,
+		arg.TourID,
+		arg.SoNhoNhat,
+		arg.SoLonNhat,
+		arg.TuoiTreEmToiThieu,
+		arg.TuoiTreEmToiDa,
+	)
 	var i CauHinhNhomTour
 	err := row.Scan(
 		&i.ID,
 		&i.TourID,
 		&i.SoNhoNhat,
 		&i.SoLonNhat,
+		&i.TuoiTreEmToiThieu,
+		&i.TuoiTreEmToiDa,
 	)
 	return i, err
 }
@@ -0,0 +1,136 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: jwt_key.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createJwtSigningKey = `-- name: CreateJwtSigningKey :one
+INSERT INTO khoa_ky_jwt (kid, thuat_toan, khoa_rieng_ma_hoa, khoa_cong_khai, kich_hoat_luc)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING kid, thuat_toan, khoa_rieng_ma_hoa, khoa_cong_khai, kich_hoat_luc, ngung_ky_luc, het_han_luc, ngay_tao
+`
+
+type CreateJwtSigningKeyParams struct {
+	Kid            string           `json:"kid"`
+	ThuatToan      string           `json:"thuat_toan"`
+	KhoaRiengMaHoa string           `json:"khoa_rieng_ma_hoa"`
+	KhoaCongKhai   string           `json:"khoa_cong_khai"`
+	KichHoatLuc    pgtype.Timestamp `json:"kich_hoat_luc"`
+}
+
+func (q *Queries) CreateJwtSigningKey(ctx context.Context, arg CreateJwtSigningKeyParams) (KhoaKyJwt, error) {
+	row := q.db.QueryRow(ctx, createJwtSigningKey,
+		arg.Kid,
+		arg.ThuatToan,
+		arg.KhoaRiengMaHoa,
+		arg.KhoaCongKhai,
+		arg.KichHoatLuc,
+	)
+	var i KhoaKyJwt
+	err := row.Scan(
+		&i.Kid,
+		&i.ThuatToan,
+		&i.KhoaRiengMaHoa,
+		&i.KhoaCongKhai,
+		&i.KichHoatLuc,
+		&i.NgungKyLuc,
+		&i.HetHanLuc,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const deleteExpiredJwtSigningKeys = `-- name: DeleteExpiredJwtSigningKeys :execrows
+DELETE FROM khoa_ky_jwt
+WHERE het_han_luc IS NOT NULL AND het_han_luc < NOW() - INTERVAL '30 days'
+`
+
+func (q *Queries) DeleteExpiredJwtSigningKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredJwtSigningKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const expireJwtSigningKeysExcept = `-- name: ExpireJwtSigningKeysExcept :execrows
+UPDATE khoa_ky_jwt
+SET ngung_ky_luc = COALESCE(ngung_ky_luc, NOW()),
+    het_han_luc = NOW()
+WHERE kid <> $1
+  AND (het_han_luc IS NULL OR het_han_luc > NOW())
+`
+
+// Xoay khóa khẩn cấp: gỡ ngay mọi khóa khác khỏi JWKS, token do chúng ký mất hiệu lực
+func (q *Queries) ExpireJwtSigningKeysExcept(ctx context.Context, kid string) (int64, error) {
+	result, err := q.db.Exec(ctx, expireJwtSigningKeysExcept, kid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getPublishedJwtSigningKeys = `-- name: GetPublishedJwtSigningKeys :many
+SELECT kid, thuat_toan, khoa_rieng_ma_hoa, khoa_cong_khai, kich_hoat_luc, ngung_ky_luc, het_han_luc, ngay_tao FROM khoa_ky_jwt
+WHERE het_han_luc IS NULL OR het_han_luc > NOW()
+ORDER BY kich_hoat_luc
+`
+
+// Các khóa còn trong JWKS (chưa hết hạn), cũ trước
+func (q *Queries) GetPublishedJwtSigningKeys(ctx context.Context) ([]KhoaKyJwt, error) {
+	rows, err := q.db.Query(ctx, getPublishedJwtSigningKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KhoaKyJwt
+	for rows.Next() {
+		var i KhoaKyJwt
+		if err := rows.Scan(
+			&i.Kid,
+			&i.ThuatToan,
+			&i.KhoaRiengMaHoa,
+			&i.KhoaCongKhai,
+			&i.KichHoatLuc,
+			&i.NgungKyLuc,
+			&i.HetHanLuc,
+			&i.NgayTao,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const retireJwtSigningKeys = `-- name: RetireJwtSigningKeys :execrows
+UPDATE khoa_ky_jwt
+SET ngung_ky_luc = $1,
+    het_han_luc = $2
+WHERE ngung_ky_luc IS NULL
+  AND kich_hoat_luc < $1
+`
+
+type RetireJwtSigningKeysParams struct {
+	NgungKyLuc pgtype.Timestamp `json:"ngung_ky_luc"`
+	HetHanLuc  pgtype.Timestamp `json:"het_han_luc"`
+}
+
+// Ngừng ký bằng các khóa kích hoạt trước khóa mới nhất đã có hiệu lực
+func (q *Queries) RetireJwtSigningKeys(ctx context.Context, arg RetireJwtSigningKeysParams) (int64, error) {
+	result, err := q.db.Exec(ctx, retireJwtSigningKeys, arg.NgungKyLuc, arg.HetHanLuc)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
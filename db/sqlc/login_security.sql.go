@@ -0,0 +1,182 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: login_security.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countLoginHistoryByUser = `-- name: CountLoginHistoryByUser :one
+SELECT COUNT(*)::int FROM lich_su_dang_nhap
+WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) CountLoginHistoryByUser(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, countLoginHistoryByUser, nguoiDungID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createLoginAttempt = `-- name: CreateLoginAttempt :one
+
+INSERT INTO lich_su_dang_nhap (
+    nguoi_dung_id,
+    email,
+    dia_chi_ip,
+    thiet_bi,
+    thanh_cong,
+    ly_do_that_bai
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING id, nguoi_dung_id, email, dia_chi_ip, thiet_bi, quoc_gia, ma_quoc_gia, thanh_pho, thanh_cong, ly_do_that_bai, dang_ngo, ngay_tao
+`
+
+type CreateLoginAttemptParams struct {
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	Email       string      `json:"email"`
+	DiaChiIp    *string     `json:"dia_chi_ip"`
+	ThietBi     *string     `json:"thiet_bi"`
+	ThanhCong   bool        `json:"thanh_cong"`
+	LyDoThatBai *string     `json:"ly_do_that_bai"`
+}
+
+// ===========================================
+// LỊCH SỬ ĐĂNG NHẬP
+// ===========================================
+// Ghi nhận một lần đăng nhập (quốc gia được cập nhật sau khi tra IP)
+func (q *Queries) CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) (LichSuDangNhap, error) {
+	row := q.db.QueryRow(ctx, createLoginAttempt,
+		arg.NguoiDungID,
+		arg.Email,
+		arg.DiaChiIp,
+		arg.ThietBi,
+		arg.ThanhCong,
+		arg.LyDoThatBai,
+	)
+	var i LichSuDangNhap
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.Email,
+		&i.DiaChiIp,
+		&i.ThietBi,
+		&i.QuocGia,
+		&i.MaQuocGia,
+		&i.ThanhPho,
+		&i.ThanhCong,
+		&i.LyDoThatBai,
+		&i.DangNgo,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const getLoginCountryHistory = `-- name: GetLoginCountryHistory :one
+SELECT
+    COUNT(*) FILTER (WHERE ma_quoc_gia IS NOT NULL)::int AS so_lan_co_quoc_gia,
+    COUNT(*) FILTER (WHERE ma_quoc_gia = $1)::int AS so_lan_cung_quoc_gia
+FROM lich_su_dang_nhap
+WHERE nguoi_dung_id = $2
+    AND thanh_cong
+    AND id <> $3
+`
+
+type GetLoginCountryHistoryParams struct {
+	MaQuocGia   *string     `json:"ma_quoc_gia"`
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	NgoaiTruID  int32       `json:"ngoai_tru_id"`
+}
+
+type GetLoginCountryHistoryRow struct {
+	SoLanCoQuocGia   int32 `json:"so_lan_co_quoc_gia"`
+	SoLanCungQuocGia int32 `json:"so_lan_cung_quoc_gia"`
+}
+
+// Số lần đăng nhập thành công trước đó đã xác định được quốc gia, và số lần từ quốc gia đang xét
+func (q *Queries) GetLoginCountryHistory(ctx context.Context, arg GetLoginCountryHistoryParams) (GetLoginCountryHistoryRow, error) {
+	row := q.db.QueryRow(ctx, getLoginCountryHistory, arg.MaQuocGia, arg.NguoiDungID, arg.NgoaiTruID)
+	var i GetLoginCountryHistoryRow
+	err := row.Scan(&i.SoLanCoQuocGia, &i.SoLanCungQuocGia)
+	return i, err
+}
+
+const getLoginHistoryByUser = `-- name: GetLoginHistoryByUser :many
+SELECT id, nguoi_dung_id, email, dia_chi_ip, thiet_bi, quoc_gia, ma_quoc_gia, thanh_pho, thanh_cong, ly_do_that_bai, dang_ngo, ngay_tao FROM lich_su_dang_nhap
+WHERE nguoi_dung_id = $1
+ORDER BY ngay_tao DESC, id DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetLoginHistoryByUserParams struct {
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	Limit       int32       `json:"limit"`
+	Offset      int32       `json:"offset"`
+}
+
+func (q *Queries) GetLoginHistoryByUser(ctx context.Context, arg GetLoginHistoryByUserParams) ([]LichSuDangNhap, error) {
+	rows, err := q.db.Query(ctx, getLoginHistoryByUser, arg.NguoiDungID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LichSuDangNhap
+	for rows.Next() {
+		var i LichSuDangNhap
+		if err := rows.Scan(
+			&i.ID,
+			&i.NguoiDungID,
+			&i.Email,
+			&i.DiaChiIp,
+			&i.ThietBi,
+			&i.QuocGia,
+			&i.MaQuocGia,
+			&i.ThanhPho,
+			&i.ThanhCong,
+			&i.LyDoThatBai,
+			&i.DangNgo,
+			&i.NgayTao,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateLoginAttemptLocation = `-- name: UpdateLoginAttemptLocation :exec
+UPDATE lich_su_dang_nhap
+SET quoc_gia = $2,
+    ma_quoc_gia = $3,
+    thanh_pho = $4,
+    dang_ngo = $5
+WHERE id = $1
+`
+
+type UpdateLoginAttemptLocationParams struct {
+	ID        int32   `json:"id"`
+	QuocGia   *string `json:"quoc_gia"`
+	MaQuocGia *string `json:"ma_quoc_gia"`
+	ThanhPho  *string `json:"thanh_pho"`
+	DangNgo   bool    `json:"dang_ngo"`
+}
+
+func (q *Queries) UpdateLoginAttemptLocation(ctx context.Context, arg UpdateLoginAttemptLocationParams) error {
+	_, err := q.db.Exec(ctx, updateLoginAttemptLocation,
+		arg.ID,
+		arg.QuocGia,
+		arg.MaQuocGia,
+		arg.ThanhPho,
+		arg.DangNgo,
+	)
+	return err
+}
@@ -6,6 +6,7 @@ package db
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -150,6 +151,50 @@ func (ns NullTrangThaiDatCho) Value() (driver.Value, error) {
 	return string(ns.TrangThaiDatCho), nil
 }
 
+type TrangThaiDoiSoat string
+
+const (
+	TrangThaiDoiSoatChuaDoiSoat TrangThaiDoiSoat = "chua_doi_soat"
+	TrangThaiDoiSoatDaDoiSoat   TrangThaiDoiSoat = "da_doi_soat"
+	TrangThaiDoiSoatSaiSoTien   TrangThaiDoiSoat = "sai_so_tien"
+	TrangThaiDoiSoatKhongKhop   TrangThaiDoiSoat = "khong_khop"
+)
+
+func (e *TrangThaiDoiSoat) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TrangThaiDoiSoat(s)
+	case string:
+		*e = TrangThaiDoiSoat(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TrangThaiDoiSoat: %T", src)
+	}
+	return nil
+}
+
+type NullTrangThaiDoiSoat struct {
+	TrangThaiDoiSoat TrangThaiDoiSoat `json:"trang_thai_doi_soat"`
+	Valid            bool             `json:"valid"` // Valid is true if TrangThaiDoiSoat is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTrangThaiDoiSoat) Scan(value interface{}) error {
+	if value == nil {
+		ns.TrangThaiDoiSoat, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TrangThaiDoiSoat.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTrangThaiDoiSoat) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TrangThaiDoiSoat), nil
+}
+
 type TrangThaiKhoiHanh string
 
 const (
@@ -196,6 +241,49 @@ func (ns NullTrangThaiKhoiHanh) Value() (driver.Value, error) {
 	return string(ns.TrangThaiKhoiHanh), nil
 }
 
+type TrangThaiNhanVienNcc string
+
+const (
+	TrangThaiNhanVienNccChoKichHoat TrangThaiNhanVienNcc = "cho_kich_hoat"
+	TrangThaiNhanVienNccHoatDong    TrangThaiNhanVienNcc = "hoat_dong"
+	TrangThaiNhanVienNccVoHieu      TrangThaiNhanVienNcc = "vo_hieu"
+)
+
+func (e *TrangThaiNhanVienNcc) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TrangThaiNhanVienNcc(s)
+	case string:
+		*e = TrangThaiNhanVienNcc(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TrangThaiNhanVienNcc: %T", src)
+	}
+	return nil
+}
+
+type NullTrangThaiNhanVienNcc struct {
+	TrangThaiNhanVienNcc TrangThaiNhanVienNcc `json:"trang_thai_nhan_vien_ncc"`
+	Valid                bool                 `json:"valid"` // Valid is true if TrangThaiNhanVienNcc is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTrangThaiNhanVienNcc) Scan(value interface{}) error {
+	if value == nil {
+		ns.TrangThaiNhanVienNcc, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TrangThaiNhanVienNcc.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTrangThaiNhanVienNcc) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TrangThaiNhanVienNcc), nil
+}
+
 type TrangThaiThanhToan string
 
 const (
@@ -296,6 +384,13 @@ type AnhTour struct {
 	NgayTao      pgtype.Timestamp `json:"ngay_tao"`
 }
 
+type BacChinhSachHuy struct {
+	ID             int32          `json:"id"`
+	ChinhSachHuyID int32          `json:"chinh_sach_huy_id"`
+	SoNgayToiThieu int32          `json:"so_ngay_toi_thieu"`
+	PhanTramHoan   pgtype.Numeric `json:"phan_tram_hoan"`
+}
+
 type BinhLuanBlog struct {
 	ID            int32            `json:"id"`
 	BlogID        int32            `json:"blog_id"`
@@ -327,10 +422,52 @@ type Blog struct {
 }
 
 type CauHinhNhomTour struct {
-	ID        int32  `json:"id"`
-	TourID    int32  `json:"tour_id"`
-	SoNhoNhat *int32 `json:"so_nho_nhat"`
-	SoLonNhat *int32 `json:"so_lon_nhat"`
+	ID                int32  `json:"id"`
+	TourID            int32  `json:"tour_id"`
+	SoNhoNhat         *int32 `json:"so_nho_nhat"`
+	SoLonNhat         *int32 `json:"so_lon_nhat"`
+	TuoiTreEmToiThieu int32  `json:"tuoi_tre_em_toi_thieu"`
+	TuoiTreEmToiDa    int32  `json:"tuoi_tre_em_toi_da"`
+}
+
+type ChiTraNhaCungCap struct {
+	ID                 int32            `json:"id"`
+	NhaCungCapID       pgtype.UUID      `json:"nha_cung_cap_id"`
+	KhoiHanhID         int32            `json:"khoi_hanh_id"`
+	TaiKhoanNganHangID *int32           `json:"tai_khoan_ngan_hang_id"`
+	SoDatCho           int32            `json:"so_dat_cho"`
+	TongDoanhThu       pgtype.Numeric   `json:"tong_doanh_thu"`
+	PhiCongThanhToan   pgtype.Numeric   `json:"phi_cong_thanh_toan"`
+	TyLeHoaHong        pgtype.Numeric   `json:"ty_le_hoa_hong"`
+	PhiHoaHong         pgtype.Numeric   `json:"phi_hoa_hong"`
+	SoTienThucNhan     pgtype.Numeric   `json:"so_tien_thuc_nhan"`
+	DonViTienTe        *string          `json:"don_vi_tien_te"`
+	TrangThai          TrangThaiChiTra  `json:"trang_thai"`
+	MaThamChieuChiTra  *string          `json:"ma_tham_chieu_chi_tra"`
+	GhiChu             *string          `json:"ghi_chu"`
+	NguoiDuyetID       pgtype.UUID      `json:"nguoi_duyet_id"`
+	NgayDuyet          pgtype.Timestamp `json:"ngay_duyet"`
+	NgayChiTra         pgtype.Timestamp `json:"ngay_chi_tra"`
+	NgayTao            pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat        pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
+type ChinhSach2fa struct {
+	VaiTro       VaiTroNguoiDung  `json:"vai_tro"`
+	BatBuoc      bool             `json:"bat_buoc"`
+	NguoiCapNhat pgtype.UUID      `json:"nguoi_cap_nhat"`
+	NgayCapNhat  pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
+type ChinhSachHuy struct {
+	ID            int32            `json:"id"`
+	NhaCungCapID  pgtype.UUID      `json:"nha_cung_cap_id"`
+	Ten           string           `json:"ten"`
+	MoTa          *string          `json:"mo_ta"`
+	KhongHoanTien bool             `json:"khong_hoan_tien"`
+	PhiCoDinh     pgtype.Numeric   `json:"phi_co_dinh"`
+	NgayTao       pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat   pgtype.Timestamp `json:"ngay_cap_nhat"`
 }
 
 type CongThanhToan struct {
@@ -363,6 +500,19 @@ type DanhMucTour struct {
 	NgayTao      pgtype.Timestamp `json:"ngay_tao"`
 }
 
+type DanhSachCho struct {
+	ID          int32            `json:"id"`
+	KhoiHanhID  int32            `json:"khoi_hanh_id"`
+	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
+	SoNguoiLon  int32            `json:"so_nguoi_lon"`
+	SoTreEm     int32            `json:"so_tre_em"`
+	TrangThai   string           `json:"trang_thai"`
+	GiuChoID    *int32           `json:"giu_cho_id"`
+	MoiLuc      pgtype.Timestamp `json:"moi_luc"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
 type DatCho struct {
 	ID                  int32               `json:"id"`
 	NguoiDungID         pgtype.UUID         `json:"nguoi_dung_id"`
@@ -375,6 +525,9 @@ type DatCho struct {
 	PhuongThucThanhToan *string             `json:"phuong_thuc_thanh_toan"`
 	NgayDat             pgtype.Timestamp    `json:"ngay_dat"`
 	NgayCapNhat         pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	DoiTacID            *int32              `json:"doi_tac_id"`
+	KhoaApiID           *int32              `json:"khoa_api_id"`
+	ChinhSachHuyApDung  json.RawMessage     `json:"chinh_sach_huy_ap_dung"`
 }
 
 type DiemDen struct {
@@ -393,6 +546,19 @@ type DiemDen struct {
 	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
 }
 
+type DoiTac struct {
+	ID           int32            `json:"id"`
+	Ten          string           `json:"ten"`
+	EmailLienHe  string           `json:"email_lien_he"`
+	SoDienThoai  *string          `json:"so_dien_thoai"`
+	TiLeHoaHong  pgtype.Numeric   `json:"ti_le_hoa_hong"`
+	NguoiDungID  pgtype.UUID      `json:"nguoi_dung_id"`
+	DangHoatDong bool             `json:"dang_hoat_dong"`
+	NguoiTao     pgtype.UUID      `json:"nguoi_tao"`
+	NgayTao      pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat  pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
 type GiamGiaTour struct {
 	ID          int32            `json:"id"`
 	TourID      int32            `json:"tour_id"`
@@ -403,6 +569,20 @@ type GiamGiaTour struct {
 	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
 }
 
+type GiuCho struct {
+	ID          int32            `json:"id"`
+	MaGiuCho    pgtype.UUID      `json:"ma_giu_cho"`
+	KhoiHanhID  int32            `json:"khoi_hanh_id"`
+	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
+	SoNguoiLon  int32            `json:"so_nguoi_lon"`
+	SoTreEm     int32            `json:"so_tre_em"`
+	TrangThai   string           `json:"trang_thai"`
+	HetHanLuc   pgtype.Timestamp `json:"het_han_luc"`
+	DatChoID    *int32           `json:"dat_cho_id"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
 type HanhKhach struct {
 	ID               int32       `json:"id"`
 	DatChoID         int32       `json:"dat_cho_id"`
@@ -426,17 +606,45 @@ type HoatDongTrongNgay struct {
 	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
 }
 
+type KhoaApi struct {
+	ID             int32            `json:"id"`
+	DoiTacID       int32            `json:"doi_tac_id"`
+	Ten            string           `json:"ten"`
+	TienTo         string           `json:"tien_to"`
+	MaBam          string           `json:"ma_bam"`
+	PhamVi         []string         `json:"pham_vi"`
+	GioiHanMoiPhut int32            `json:"gioi_han_moi_phut"`
+	SoLanGoi       int64            `json:"so_lan_goi"`
+	LanDungCuoi    pgtype.Timestamp `json:"lan_dung_cuoi"`
+	HetHan         pgtype.Timestamp `json:"het_han"`
+	DaThuHoiLuc    pgtype.Timestamp `json:"da_thu_hoi_luc"`
+	NguoiTao       pgtype.UUID      `json:"nguoi_tao"`
+	NgayTao        pgtype.Timestamp `json:"ngay_tao"`
+}
+
+type KhoaKyJwt struct {
+	Kid            string           `json:"kid"`
+	ThuatToan      string           `json:"thuat_toan"`
+	KhoaRiengMaHoa string           `json:"khoa_rieng_ma_hoa"`
+	KhoaCongKhai   string           `json:"khoa_cong_khai"`
+	KichHoatLuc    pgtype.Timestamp `json:"kich_hoat_luc"`
+	NgungKyLuc     pgtype.Timestamp `json:"ngung_ky_luc"`
+	HetHanLuc      pgtype.Timestamp `json:"het_han_luc"`
+	NgayTao        pgtype.Timestamp `json:"ngay_tao"`
+}
+
 type KhoiHanhTour struct {
-	ID           int32                 `json:"id"`
-	TourID       int32                 `json:"tour_id"`
-	NgayKhoiHanh pgtype.Date           `json:"ngay_khoi_hanh"`
-	NgayKetThuc  pgtype.Date           `json:"ngay_ket_thuc"`
-	SucChua      int32                 `json:"suc_chua"`
-	SoChoDaDat   *int32                `json:"so_cho_da_dat"`
-	TrangThai    NullTrangThaiKhoiHanh `json:"trang_thai"`
-	GhiChu       *string               `json:"ghi_chu"`
-	NgayTao      pgtype.Timestamp      `json:"ngay_tao"`
-	NgayCapNhat  pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ID             int32                 `json:"id"`
+	TourID         int32                 `json:"tour_id"`
+	NgayKhoiHanh   pgtype.Date           `json:"ngay_khoi_hanh"`
+	NgayKetThuc    pgtype.Date           `json:"ngay_ket_thuc"`
+	SucChua        int32                 `json:"suc_chua"`
+	SoChoDaDat     *int32                `json:"so_cho_da_dat"`
+	TrangThai      NullTrangThaiKhoiHanh `json:"trang_thai"`
+	GhiChu         *string               `json:"ghi_chu"`
+	NgayTao        pgtype.Timestamp      `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp      `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32                `json:"chinh_sach_huy_id"`
 }
 
 type LichSuAiBlog struct {
@@ -458,6 +666,21 @@ type LichSuChat struct {
 	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
 }
 
+type LichSuDangNhap struct {
+	ID          int32            `json:"id"`
+	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
+	Email       string           `json:"email"`
+	DiaChiIp    *string          `json:"dia_chi_ip"`
+	ThietBi     *string          `json:"thiet_bi"`
+	QuocGia     *string          `json:"quoc_gia"`
+	MaQuocGia   *string          `json:"ma_quoc_gia"`
+	ThanhPho    *string          `json:"thanh_pho"`
+	ThanhCong   bool             `json:"thanh_cong"`
+	LyDoThatBai *string          `json:"ly_do_that_bai"`
+	DangNgo     bool             `json:"dang_ngo"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+}
+
 type LichSuGiaoDich struct {
 	ID                       int32                  `json:"id"`
 	DatChoID                 *int32                 `json:"dat_cho_id"`
@@ -471,6 +694,12 @@ type LichSuGiaoDich struct {
 	NoiDungChuyenKhoan       *string                `json:"noi_dung_chuyen_khoan"`
 	NgayTao                  pgtype.Timestamp       `json:"ngay_tao"`
 	NgayHoanThanh            pgtype.Timestamp       `json:"ngay_hoan_thanh"`
+	GiaoDichGocID            *int32                 `json:"giao_dich_goc_id"`
+	LyDo                     *string                `json:"ly_do"`
+	GhiChuXuLy               *string                `json:"ghi_chu_xu_ly"`
+	NguoiDuyetID             pgtype.UUID            `json:"nguoi_duyet_id"`
+	NgayDuyet                pgtype.Timestamp       `json:"ngay_duyet"`
+	SoLanThu                 int32                  `json:"so_lan_thu"`
 }
 
 type LichSuXemTour struct {
@@ -511,6 +740,14 @@ type LienHe struct {
 	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
 }
 
+type MaKhoiPhuc2fa struct {
+	ID          int32            `json:"id"`
+	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
+	MaBam       string           `json:"ma_bam"`
+	DaDungLuc   pgtype.Timestamp `json:"da_dung_luc"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+}
+
 type NguoiDung struct {
 	ID           pgtype.UUID         `json:"id"`
 	HoTen        string              `json:"ho_ten"`
@@ -522,6 +759,14 @@ type NguoiDung struct {
 	XacThuc      *bool               `json:"xac_thuc"`
 	NgayTao      pgtype.Timestamp    `json:"ngay_tao"`
 	NgayCapNhat  pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	CoMatKhau    bool                `json:"co_mat_khau"`
+}
+
+type NguoiDungVaiTro struct {
+	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
+	VaiTroMa    string           `json:"vai_tro_ma"`
+	NguoiGan    pgtype.UUID      `json:"nguoi_gan"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
 }
 
 type NhaCungCap struct {
@@ -539,6 +784,36 @@ type NhaCungCap struct {
 	GiayToKinhDoanh *string     `json:"giay_to_kinh_doanh"`
 }
 
+type NhanVienNhaCungCap struct {
+	ID           int32                `json:"id"`
+	NhaCungCapID pgtype.UUID          `json:"nha_cung_cap_id"`
+	NguoiDungID  pgtype.UUID          `json:"nguoi_dung_id"`
+	Quyen        []string             `json:"quyen"`
+	TrangThai    TrangThaiNhanVienNcc `json:"trang_thai"`
+	MaLoiMoiBam  *string              `json:"ma_loi_moi_bam"`
+	HanLoiMoi    pgtype.Timestamp     `json:"han_loi_moi"`
+	NguoiMoi     pgtype.UUID          `json:"nguoi_moi"`
+	NgayThamGia  pgtype.Timestamp     `json:"ngay_tham_gia"`
+	NgayTao      pgtype.Timestamp     `json:"ngay_tao"`
+	NgayCapNhat  pgtype.Timestamp     `json:"ngay_cap_nhat"`
+}
+
+type NhatKyKiemToan struct {
+	ID                 int64            `json:"id"`
+	NguoiThucHien      pgtype.UUID      `json:"nguoi_thuc_hien"`
+	EmailNguoiThucHien *string          `json:"email_nguoi_thuc_hien"`
+	VaiTro             *string          `json:"vai_tro"`
+	HanhDong           string           `json:"hanh_dong"`
+	LoaiDoiTuong       string           `json:"loai_doi_tuong"`
+	MaDoiTuong         string           `json:"ma_doi_tuong"`
+	DuLieuTruoc        []byte           `json:"du_lieu_truoc"`
+	DuLieuSau          []byte           `json:"du_lieu_sau"`
+	ThayDoi            []byte           `json:"thay_doi"`
+	DiaChiIp           *string          `json:"dia_chi_ip"`
+	MaYeuCau           *string          `json:"ma_yeu_cau"`
+	NgayTao            pgtype.Timestamp `json:"ngay_tao"`
+}
+
 type OtpDatLaiMatKhau struct {
 	ID          int32            `json:"id"`
 	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
@@ -567,6 +842,21 @@ type PhanHoiLienHe struct {
 	NgayCapNhat    pgtype.Timestamp `json:"ngay_cap_nhat"`
 }
 
+type PhienBanTour struct {
+	ID           int32            `json:"id"`
+	TourID       int32            `json:"tour_id"`
+	SoPhienBan   int32            `json:"so_phien_ban"`
+	Loai         string           `json:"loai"`
+	TrangThai    string           `json:"trang_thai"`
+	NoiDung      []byte           `json:"noi_dung"`
+	GhiChu       *string          `json:"ghi_chu"`
+	NguoiGuiID   pgtype.UUID      `json:"nguoi_gui_id"`
+	NguoiDuyetID pgtype.UUID      `json:"nguoi_duyet_id"`
+	NhanXetDuyet *string          `json:"nhan_xet_duyet"`
+	NgayTao      pgtype.Timestamp `json:"ngay_tao"`
+	NgayDuyet    pgtype.Timestamp `json:"ngay_duyet"`
+}
+
 type PhienDangNhap struct {
 	ID              int32            `json:"id"`
 	NguoiDungID     pgtype.UUID      `json:"nguoi_dung_id"`
@@ -577,6 +867,31 @@ type PhienDangNhap struct {
 	DangHoatDong    *bool            `json:"dang_hoat_dong"`
 	NgayTao         pgtype.Timestamp `json:"ngay_tao"`
 	NgayCapNhat     pgtype.Timestamp `json:"ngay_cap_nhat"`
+	DiaChiIp        *string          `json:"dia_chi_ip"`
+	LanLamMoiCuoi   pgtype.Timestamp `json:"lan_lam_moi_cuoi"`
+	ThuHoiLuc       pgtype.Timestamp `json:"thu_hoi_luc"`
+	LyDoThuHoi      *string          `json:"ly_do_thu_hoi"`
+}
+
+type Quyen struct {
+	Ma   string `json:"ma"`
+	Nhom string `json:"nhom"`
+	MoTa string `json:"mo_ta"`
+}
+
+type SaoKeNganHang struct {
+	ID                 int32            `json:"id"`
+	MaGiaoDichNganHang string           `json:"ma_giao_dich_ngan_hang"`
+	NgayGiaoDich       pgtype.Timestamp `json:"ngay_giao_dich"`
+	SoTien             pgtype.Numeric   `json:"so_tien"`
+	NoiDung            string           `json:"noi_dung"`
+	TenTep             *string          `json:"ten_tep"`
+	TrangThai          TrangThaiDoiSoat `json:"trang_thai"`
+	GiaoDichID         *int32           `json:"giao_dich_id"`
+	GhiChu             *string          `json:"ghi_chu"`
+	NguoiTaiLenID      pgtype.UUID      `json:"nguoi_tai_len_id"`
+	NgayDoiSoat        pgtype.Timestamp `json:"ngay_doi_soat"`
+	NgayTao            pgtype.Timestamp `json:"ngay_tao"`
 }
 
 type SoThichNguoiDung struct {
@@ -587,6 +902,12 @@ type SoThichNguoiDung struct {
 	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
 }
 
+type SuDungKhoaApi struct {
+	KhoaApiID int32       `json:"khoa_api_id"`
+	Ngay      pgtype.Date `json:"ngay"`
+	SoLanGoi  int64       `json:"so_lan_goi"`
+}
+
 type TaiKhoanNganHang struct {
 	ID             int32       `json:"id"`
 	NhaCungCapID   pgtype.UUID `json:"nha_cung_cap_id"`
@@ -597,6 +918,18 @@ type TaiKhoanNganHang struct {
 	LaMacDinh      *bool       `json:"la_mac_dinh"`
 }
 
+type TaiKhoanOauth struct {
+	ID              int32            `json:"id"`
+	NguoiDungID     pgtype.UUID      `json:"nguoi_dung_id"`
+	NhaCungCap      string           `json:"nha_cung_cap"`
+	NhaCungCapID    string           `json:"nha_cung_cap_id"`
+	Email           *string          `json:"email"`
+	TenHienThi      *string          `json:"ten_hien_thi"`
+	AnhDaiDien      *string          `json:"anh_dai_dien"`
+	LanDangNhapCuoi pgtype.Timestamp `json:"lan_dang_nhap_cuoi"`
+	NgayTao         pgtype.Timestamp `json:"ngay_tao"`
+}
+
 type ThongBao struct {
 	ID          int32            `json:"id"`
 	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
@@ -609,21 +942,22 @@ type ThongBao struct {
 }
 
 type Tour struct {
-	ID           int32            `json:"id"`
-	TieuDe       string           `json:"tieu_de"`
-	MoTa         *string          `json:"mo_ta"`
-	DanhMucID    *int32           `json:"danh_muc_id"`
-	SoNgay       int32            `json:"so_ngay"`
-	SoDem        int32            `json:"so_dem"`
-	GiaNguoiLon  pgtype.Numeric   `json:"gia_nguoi_lon"`
-	GiaTreEm     pgtype.Numeric   `json:"gia_tre_em"`
-	DonViTienTe  *string          `json:"don_vi_tien_te"`
-	TrangThai    *string          `json:"trang_thai"`
-	NoiBat       *bool            `json:"noi_bat"`
-	NhaCungCapID pgtype.UUID      `json:"nha_cung_cap_id"`
-	DangHoatDong *bool            `json:"dang_hoat_dong"`
-	NgayTao      pgtype.Timestamp `json:"ngay_tao"`
-	NgayCapNhat  pgtype.Timestamp `json:"ngay_cap_nhat"`
+	ID             int32            `json:"id"`
+	TieuDe         string           `json:"tieu_de"`
+	MoTa           *string          `json:"mo_ta"`
+	DanhMucID      *int32           `json:"danh_muc_id"`
+	SoNgay         int32            `json:"so_ngay"`
+	SoDem          int32            `json:"so_dem"`
+	GiaNguoiLon    pgtype.Numeric   `json:"gia_nguoi_lon"`
+	GiaTreEm       pgtype.Numeric   `json:"gia_tre_em"`
+	DonViTienTe    *string          `json:"don_vi_tien_te"`
+	TrangThai      *string          `json:"trang_thai"`
+	NoiBat         *bool            `json:"noi_bat"`
+	NhaCungCapID   pgtype.UUID      `json:"nha_cung_cap_id"`
+	DangHoatDong   *bool            `json:"dang_hoat_dong"`
+	NgayTao        pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat    pgtype.Timestamp `json:"ngay_cap_nhat"`
+	ChinhSachHuyID *int32           `json:"chinh_sach_huy_id"`
 }
 
 type TourDiemDen struct {
@@ -637,6 +971,8 @@ type TourEmbedding struct {
 	Embedding   *pgvector.Vector `json:"embedding"`
 	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
 	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
+	MoHinh      *string          `json:"mo_hinh"`
+	NoiDungHash *string          `json:"noi_dung_hash"`
 }
 
 type TourYeuThich struct {
@@ -645,3 +981,38 @@ type TourYeuThich struct {
 	TourID      int32            `json:"tour_id"`
 	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
 }
+
+type VaiTro struct {
+	Ma          string           `json:"ma"`
+	Ten         string           `json:"ten"`
+	MoTa        *string          `json:"mo_ta"`
+	HeThong     bool             `json:"he_thong"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
+type VaiTroQuyen struct {
+	VaiTroMa string `json:"vai_tro_ma"`
+	QuyenMa  string `json:"quyen_ma"`
+}
+
+type XacThucHaiBuoc struct {
+	NguoiDungID      pgtype.UUID      `json:"nguoi_dung_id"`
+	BiMatMaHoa       string           `json:"bi_mat_ma_hoa"`
+	DaKichHoat       bool             `json:"da_kich_hoat"`
+	BuocThoiGianCuoi *int64           `json:"buoc_thoi_gian_cuoi"`
+	NgayKichHoat     pgtype.Timestamp `json:"ngay_kich_hoat"`
+	NgayTao          pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat      pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
+type YeuCauXoaTaiKhoan struct {
+	ID          int32            `json:"id"`
+	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
+	TrangThai   string           `json:"trang_thai"`
+	LyDo        *string          `json:"ly_do"`
+	HanXoa      pgtype.Timestamp `json:"han_xoa"`
+	NgayHuy     pgtype.Timestamp `json:"ngay_huy"`
+	NgayXoa     pgtype.Timestamp `json:"ngay_xoa"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+}
@@ -0,0 +1,214 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: oauth.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countOAuthAccountsByUser = `-- name: CountOAuthAccountsByUser :one
+SELECT COUNT(*)::int FROM tai_khoan_oauth
+WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) CountOAuthAccountsByUser(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, countOAuthAccountsByUser, nguoiDungID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createOAuthAccount = `-- name: CreateOAuthAccount :one
+INSERT INTO tai_khoan_oauth (
+    nguoi_dung_id,
+    nha_cung_cap,
+    nha_cung_cap_id,
+    email,
+    ten_hien_thi,
+    anh_dai_dien,
+    lan_dang_nhap_cuoi
+) VALUES (
+    $1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP
+)
+RETURNING id, nguoi_dung_id, nha_cung_cap, nha_cung_cap_id, email, ten_hien_thi, anh_dai_dien, lan_dang_nhap_cuoi, ngay_tao
+`
+
+type CreateOAuthAccountParams struct {
+	NguoiDungID  pgtype.UUID `json:"nguoi_dung_id"`
+	NhaCungCap   string      `json:"nha_cung_cap"`
+	NhaCungCapID string      `json:"nha_cung_cap_id"`
+	Email        *string     `json:"email"`
+	TenHienThi   *string     `json:"ten_hien_thi"`
+	AnhDaiDien   *string     `json:"anh_dai_dien"`
+}
+
+func (q *Queries) CreateOAuthAccount(ctx context.Context, arg CreateOAuthAccountParams) (TaiKhoanOauth, error) {
+	row := q.db.QueryRow(ctx, createOAuthAccount,
+		arg.NguoiDungID,
+		arg.NhaCungCap,
+		arg.NhaCungCapID,
+		arg.Email,
+		arg.TenHienThi,
+		arg.AnhDaiDien,
+	)
+	var i TaiKhoanOauth
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.NhaCungCap,
+		&i.NhaCungCapID,
+		&i.Email,
+		&i.TenHienThi,
+		&i.AnhDaiDien,
+		&i.LanDangNhapCuoi,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const deleteOAuthAccount = `-- name: DeleteOAuthAccount :execrows
+DELETE FROM tai_khoan_oauth
+WHERE nguoi_dung_id = $1 AND nha_cung_cap = $2
+`
+
+type DeleteOAuthAccountParams struct {
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	NhaCungCap  string      `json:"nha_cung_cap"`
+}
+
+func (q *Queries) DeleteOAuthAccount(ctx context.Context, arg DeleteOAuthAccountParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOAuthAccount, arg.NguoiDungID, arg.NhaCungCap)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserOAuthAccounts = `-- name: DeleteUserOAuthAccounts :exec
+DELETE FROM tai_khoan_oauth WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserOAuthAccounts(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserOAuthAccounts, nguoiDungID)
+	return err
+}
+
+const getOAuthAccount = `-- name: GetOAuthAccount :one
+
+SELECT id, nguoi_dung_id, nha_cung_cap, nha_cung_cap_id, email, ten_hien_thi, anh_dai_dien, lan_dang_nhap_cuoi, ngay_tao FROM tai_khoan_oauth
+WHERE nha_cung_cap = $1 AND nha_cung_cap_id = $2
+`
+
+type GetOAuthAccountParams struct {
+	NhaCungCap   string `json:"nha_cung_cap"`
+	NhaCungCapID string `json:"nha_cung_cap_id"`
+}
+
+// ===========================================
+// TÀI KHOẢN MẠNG XÃ HỘI (OAUTH)
+// ===========================================
+func (q *Queries) GetOAuthAccount(ctx context.Context, arg GetOAuthAccountParams) (TaiKhoanOauth, error) {
+	row := q.db.QueryRow(ctx, getOAuthAccount, arg.NhaCungCap, arg.NhaCungCapID)
+	var i TaiKhoanOauth
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.NhaCungCap,
+		&i.NhaCungCapID,
+		&i.Email,
+		&i.TenHienThi,
+		&i.AnhDaiDien,
+		&i.LanDangNhapCuoi,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const getOAuthAccountsByUser = `-- name: GetOAuthAccountsByUser :many
+SELECT id, nha_cung_cap, email, ten_hien_thi, anh_dai_dien, lan_dang_nhap_cuoi, ngay_tao
+FROM tai_khoan_oauth
+WHERE nguoi_dung_id = $1
+ORDER BY ngay_tao
+`
+
+type GetOAuthAccountsByUserRow struct {
+	ID              int32            `json:"id"`
+	NhaCungCap      string           `json:"nha_cung_cap"`
+	Email           *string          `json:"email"`
+	TenHienThi      *string          `json:"ten_hien_thi"`
+	AnhDaiDien      *string          `json:"anh_dai_dien"`
+	LanDangNhapCuoi pgtype.Timestamp `json:"lan_dang_nhap_cuoi"`
+	NgayTao         pgtype.Timestamp `json:"ngay_tao"`
+}
+
+func (q *Queries) GetOAuthAccountsByUser(ctx context.Context, nguoiDungID pgtype.UUID) ([]GetOAuthAccountsByUserRow, error) {
+	rows, err := q.db.Query(ctx, getOAuthAccountsByUser, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOAuthAccountsByUserRow
+	for rows.Next() {
+		var i GetOAuthAccountsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCap,
+			&i.Email,
+			&i.TenHienThi,
+			&i.AnhDaiDien,
+			&i.LanDangNhapCuoi,
+			&i.NgayTao,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markUserWithoutPassword = `-- name: MarkUserWithoutPassword :exec
+UPDATE nguoi_dung
+SET co_mat_khau = FALSE
+WHERE id = $1
+`
+
+// Tài khoản tạo từ đăng nhập mạng xã hội chỉ có mật khẩu ngẫu nhiên
+func (q *Queries) MarkUserWithoutPassword(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markUserWithoutPassword, id)
+	return err
+}
+
+const touchOAuthAccount = `-- name: TouchOAuthAccount :exec
+UPDATE tai_khoan_oauth
+SET email = COALESCE($1, email),
+    ten_hien_thi = COALESCE($2, ten_hien_thi),
+    anh_dai_dien = COALESCE($3, anh_dai_dien),
+    lan_dang_nhap_cuoi = CURRENT_TIMESTAMP
+WHERE id = $4
+`
+
+type TouchOAuthAccountParams struct {
+	Email      *string `json:"email"`
+	TenHienThi *string `json:"ten_hien_thi"`
+	AnhDaiDien *string `json:"anh_dai_dien"`
+	ID         int32   `json:"id"`
+}
+
+// Cập nhật hồ sơ provider mới nhất mỗi lần đăng nhập
+func (q *Queries) TouchOAuthAccount(ctx context.Context, arg TouchOAuthAccountParams) error {
+	_, err := q.db.Exec(ctx, touchOAuthAccount,
+		arg.Email,
+		arg.TenHienThi,
+		arg.AnhDaiDien,
+		arg.ID,
+	)
+	return err
+}
@@ -0,0 +1,560 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: partner.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const attributeBookingToPartner = `-- name: AttributeBookingToPartner :exec
+UPDATE dat_cho
+SET doi_tac_id = $2,
+    khoa_api_id = $3
+WHERE id = $1
+`
+
+type AttributeBookingToPartnerParams struct {
+	ID        int32  `json:"id"`
+	DoiTacID  *int32 `json:"doi_tac_id"`
+	KhoaApiID *int32 `json:"khoa_api_id"`
+}
+
+func (q *Queries) AttributeBookingToPartner(ctx context.Context, arg AttributeBookingToPartnerParams) error {
+	_, err := q.db.Exec(ctx, attributeBookingToPartner, arg.ID, arg.DoiTacID, arg.KhoaApiID)
+	return err
+}
+
+const countPartners = `-- name: CountPartners :one
+SELECT COUNT(*)::int FROM doi_tac
+`
+
+func (q *Queries) CountPartners(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, countPartners)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createApiKey = `-- name: CreateApiKey :one
+INSERT INTO khoa_api (
+    doi_tac_id,
+    ten,
+    tien_to,
+    ma_bam,
+    pham_vi,
+    gioi_han_moi_phut,
+    het_han,
+    nguoi_tao
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING id, doi_tac_id, ten, tien_to, ma_bam, pham_vi, gioi_han_moi_phut, so_lan_goi, lan_dung_cuoi, het_han, da_thu_hoi_luc, nguoi_tao, ngay_tao
+`
+
+type CreateApiKeyParams struct {
+	DoiTacID       int32            `json:"doi_tac_id"`
+	Ten            string           `json:"ten"`
+	TienTo         string           `json:"tien_to"`
+	MaBam          string           `json:"ma_bam"`
+	PhamVi         []string         `json:"pham_vi"`
+	GioiHanMoiPhut int32            `json:"gioi_han_moi_phut"`
+	HetHan         pgtype.Timestamp `json:"het_han"`
+	NguoiTao       pgtype.UUID      `json:"nguoi_tao"`
+}
+
+func (q *Queries) CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (KhoaApi, error) {
+	row := q.db.QueryRow(ctx, createApiKey,
+		arg.DoiTacID,
+		arg.Ten,
+		arg.TienTo,
+		arg.MaBam,
+		arg.PhamVi,
+		arg.GioiHanMoiPhut,
+		arg.HetHan,
+		arg.NguoiTao,
+	)
+	var i KhoaApi
+	err := row.Scan(
+		&i.ID,
+		&i.DoiTacID,
+		&i.Ten,
+		&i.TienTo,
+		&i.MaBam,
+		&i.PhamVi,
+		&i.GioiHanMoiPhut,
+		&i.SoLanGoi,
+		&i.LanDungCuoi,
+		&i.HetHan,
+		&i.DaThuHoiLuc,
+		&i.NguoiTao,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const createPartner = `-- name: CreatePartner :one
+
+INSERT INTO doi_tac (
+    ten,
+    email_lien_he,
+    so_dien_thoai,
+    ti_le_hoa_hong,
+    nguoi_dung_id,
+    nguoi_tao
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING id, ten, email_lien_he, so_dien_thoai, ti_le_hoa_hong, nguoi_dung_id, dang_hoat_dong, nguoi_tao, ngay_tao, ngay_cap_nhat
+`
+
+type CreatePartnerParams struct {
+	Ten         string         `json:"ten"`
+	EmailLienHe string         `json:"email_lien_he"`
+	SoDienThoai *string        `json:"so_dien_thoai"`
+	TiLeHoaHong pgtype.Numeric `json:"ti_le_hoa_hong"`
+	NguoiDungID pgtype.UUID    `json:"nguoi_dung_id"`
+	NguoiTao    pgtype.UUID    `json:"nguoi_tao"`
+}
+
+// ===========================================
+// ĐỐI TÁC (PARTNER) & KHÓA API
+// ===========================================
+func (q *Queries) CreatePartner(ctx context.Context, arg CreatePartnerParams) (DoiTac, error) {
+	row := q.db.QueryRow(ctx, createPartner,
+		arg.Ten,
+		arg.EmailLienHe,
+		arg.SoDienThoai,
+		arg.TiLeHoaHong,
+		arg.NguoiDungID,
+		arg.NguoiTao,
+	)
+	var i DoiTac
+	err := row.Scan(
+		&i.ID,
+		&i.Ten,
+		&i.EmailLienHe,
+		&i.SoDienThoai,
+		&i.TiLeHoaHong,
+		&i.NguoiDungID,
+		&i.DangHoatDong,
+		&i.NguoiTao,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const getApiKeyDailyUsage = `-- name: GetApiKeyDailyUsage :many
+SELECT sd.ngay, sd.so_lan_goi
+FROM su_dung_khoa_api sd
+JOIN khoa_api k ON k.id = sd.khoa_api_id
+WHERE sd.khoa_api_id = $1
+    AND k.doi_tac_id = $2
+    AND sd.ngay >= $3::date
+ORDER BY sd.ngay DESC
+`
+
+type GetApiKeyDailyUsageParams struct {
+	KhoaApiID int32       `json:"khoa_api_id"`
+	DoiTacID  int32       `json:"doi_tac_id"`
+	TuNgay    pgtype.Date `json:"tu_ngay"`
+}
+
+type GetApiKeyDailyUsageRow struct {
+	Ngay     pgtype.Date `json:"ngay"`
+	SoLanGoi int64       `json:"so_lan_goi"`
+}
+
+func (q *Queries) GetApiKeyDailyUsage(ctx context.Context, arg GetApiKeyDailyUsageParams) ([]GetApiKeyDailyUsageRow, error) {
+	rows, err := q.db.Query(ctx, getApiKeyDailyUsage, arg.KhoaApiID, arg.DoiTacID, arg.TuNgay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetApiKeyDailyUsageRow
+	for rows.Next() {
+		var i GetApiKeyDailyUsageRow
+		if err := rows.Scan(&i.Ngay, &i.SoLanGoi); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getApiKeyForAuth = `-- name: GetApiKeyForAuth :one
+SELECT
+    k.id,
+    k.doi_tac_id,
+    k.pham_vi,
+    k.gioi_han_moi_phut,
+    dt.nguoi_dung_id,
+    nd.email
+FROM khoa_api k
+JOIN doi_tac dt ON dt.id = k.doi_tac_id
+JOIN nguoi_dung nd ON nd.id = dt.nguoi_dung_id
+WHERE k.ma_bam = $1
+    AND k.da_thu_hoi_luc IS NULL
+    AND (k.het_han IS NULL OR k.het_han > CURRENT_TIMESTAMP)
+    AND dt.dang_hoat_dong = TRUE
+`
+
+type GetApiKeyForAuthRow struct {
+	ID             int32       `json:"id"`
+	DoiTacID       int32       `json:"doi_tac_id"`
+	PhamVi         []string    `json:"pham_vi"`
+	GioiHanMoiPhut int32       `json:"gioi_han_moi_phut"`
+	NguoiDungID    pgtype.UUID `json:"nguoi_dung_id"`
+	Email          string      `json:"email"`
+}
+
+// Tra khóa API theo mã băm khi xác thực request; chỉ khóa chưa thu hồi, chưa hết hạn của đối tác đang hoạt động
+func (q *Queries) GetApiKeyForAuth(ctx context.Context, maBam string) (GetApiKeyForAuthRow, error) {
+	row := q.db.QueryRow(ctx, getApiKeyForAuth, maBam)
+	var i GetApiKeyForAuthRow
+	err := row.Scan(
+		&i.ID,
+		&i.DoiTacID,
+		&i.PhamVi,
+		&i.GioiHanMoiPhut,
+		&i.NguoiDungID,
+		&i.Email,
+	)
+	return i, err
+}
+
+const getApiKeysByPartner = `-- name: GetApiKeysByPartner :many
+SELECT id, doi_tac_id, ten, tien_to, pham_vi, gioi_han_moi_phut, so_lan_goi, lan_dung_cuoi, het_han, da_thu_hoi_luc, nguoi_tao, ngay_tao
+FROM khoa_api
+WHERE doi_tac_id = $1
+ORDER BY ngay_tao DESC, id DESC
+`
+
+type GetApiKeysByPartnerRow struct {
+	ID             int32            `json:"id"`
+	DoiTacID       int32            `json:"doi_tac_id"`
+	Ten            string           `json:"ten"`
+	TienTo         string           `json:"tien_to"`
+	PhamVi         []string         `json:"pham_vi"`
+	GioiHanMoiPhut int32            `json:"gioi_han_moi_phut"`
+	SoLanGoi       int64            `json:"so_lan_goi"`
+	LanDungCuoi    pgtype.Timestamp `json:"lan_dung_cuoi"`
+	HetHan         pgtype.Timestamp `json:"het_han"`
+	DaThuHoiLuc    pgtype.Timestamp `json:"da_thu_hoi_luc"`
+	NguoiTao       pgtype.UUID      `json:"nguoi_tao"`
+	NgayTao        pgtype.Timestamp `json:"ngay_tao"`
+}
+
+// Không trả về ma_bam
+func (q *Queries) GetApiKeysByPartner(ctx context.Context, doiTacID int32) ([]GetApiKeysByPartnerRow, error) {
+	rows, err := q.db.Query(ctx, getApiKeysByPartner, doiTacID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetApiKeysByPartnerRow
+	for rows.Next() {
+		var i GetApiKeysByPartnerRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DoiTacID,
+			&i.Ten,
+			&i.TienTo,
+			&i.PhamVi,
+			&i.GioiHanMoiPhut,
+			&i.SoLanGoi,
+			&i.LanDungCuoi,
+			&i.HetHan,
+			&i.DaThuHoiLuc,
+			&i.NguoiTao,
+			&i.NgayTao,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPartnerByID = `-- name: GetPartnerByID :one
+SELECT id, ten, email_lien_he, so_dien_thoai, ti_le_hoa_hong, nguoi_dung_id, dang_hoat_dong, nguoi_tao, ngay_tao, ngay_cap_nhat FROM doi_tac
+WHERE id = $1
+`
+
+func (q *Queries) GetPartnerByID(ctx context.Context, id int32) (DoiTac, error) {
+	row := q.db.QueryRow(ctx, getPartnerByID, id)
+	var i DoiTac
+	err := row.Scan(
+		&i.ID,
+		&i.Ten,
+		&i.EmailLienHe,
+		&i.SoDienThoai,
+		&i.TiLeHoaHong,
+		&i.NguoiDungID,
+		&i.DangHoatDong,
+		&i.NguoiTao,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const getPartnerCommissionReport = `-- name: GetPartnerCommissionReport :many
+SELECT
+    dt.id AS doi_tac_id,
+    dt.ten,
+    dt.ti_le_hoa_hong,
+    COUNT(dc.id)::int AS so_booking,
+    COALESCE(SUM(dc.tong_tien), 0)::numeric AS doanh_thu,
+    ROUND(COALESCE(SUM(dc.tong_tien), 0) * dt.ti_le_hoa_hong / 100, 2)::numeric AS hoa_hong
+FROM doi_tac dt
+LEFT JOIN dat_cho dc ON dc.doi_tac_id = dt.id
+    AND dc.trang_thai IN ('da_thanh_toan', 'hoan_thanh')
+    AND dc.ngay_dat >= $1
+    AND dc.ngay_dat < $2
+WHERE ($3::int IS NULL OR dt.id = $3)
+GROUP BY dt.id
+ORDER BY doanh_thu DESC, dt.id
+`
+
+type GetPartnerCommissionReportParams struct {
+	TuNgay   pgtype.Timestamp `json:"tu_ngay"`
+	DenNgay  pgtype.Timestamp `json:"den_ngay"`
+	DoiTacID *int32           `json:"doi_tac_id"`
+}
+
+type GetPartnerCommissionReportRow struct {
+	DoiTacID    int32          `json:"doi_tac_id"`
+	Ten         string         `json:"ten"`
+	TiLeHoaHong pgtype.Numeric `json:"ti_le_hoa_hong"`
+	SoBooking   int32          `json:"so_booking"`
+	DoanhThu    pgtype.Numeric `json:"doanh_thu"`
+	HoaHong     pgtype.Numeric `json:"hoa_hong"`
+}
+
+// Doanh thu và hoa hồng của đối tác từ các booking đã thanh toán / hoàn thành trong khoảng thời gian
+func (q *Queries) GetPartnerCommissionReport(ctx context.Context, arg GetPartnerCommissionReportParams) ([]GetPartnerCommissionReportRow, error) {
+	rows, err := q.db.Query(ctx, getPartnerCommissionReport, arg.TuNgay, arg.DenNgay, arg.DoiTacID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPartnerCommissionReportRow
+	for rows.Next() {
+		var i GetPartnerCommissionReportRow
+		if err := rows.Scan(
+			&i.DoiTacID,
+			&i.Ten,
+			&i.TiLeHoaHong,
+			&i.SoBooking,
+			&i.DoanhThu,
+			&i.HoaHong,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementApiKeyDailyUsage = `-- name: IncrementApiKeyDailyUsage :exec
+INSERT INTO su_dung_khoa_api (khoa_api_id, ngay, so_lan_goi)
+VALUES ($1, CURRENT_DATE, $2::int)
+ON CONFLICT (khoa_api_id, ngay)
+DO UPDATE SET so_lan_goi = su_dung_khoa_api.so_lan_goi + EXCLUDED.so_lan_goi
+`
+
+type IncrementApiKeyDailyUsageParams struct {
+	KhoaApiID int32 `json:"khoa_api_id"`
+	SoLanGoi  int32 `json:"so_lan_goi"`
+}
+
+func (q *Queries) IncrementApiKeyDailyUsage(ctx context.Context, arg IncrementApiKeyDailyUsageParams) error {
+	_, err := q.db.Exec(ctx, incrementApiKeyDailyUsage, arg.KhoaApiID, arg.SoLanGoi)
+	return err
+}
+
+const listPartners = `-- name: ListPartners :many
+SELECT
+    dt.id, dt.ten, dt.email_lien_he, dt.so_dien_thoai, dt.ti_le_hoa_hong, dt.nguoi_dung_id, dt.dang_hoat_dong, dt.nguoi_tao, dt.ngay_tao, dt.ngay_cap_nhat,
+    (SELECT COUNT(*) FROM khoa_api k WHERE k.doi_tac_id = dt.id AND k.da_thu_hoi_luc IS NULL)::int AS so_khoa_hieu_luc
+FROM doi_tac dt
+ORDER BY dt.ngay_tao DESC, dt.id DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListPartnersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListPartnersRow struct {
+	ID            int32            `json:"id"`
+	Ten           string           `json:"ten"`
+	EmailLienHe   string           `json:"email_lien_he"`
+	SoDienThoai   *string          `json:"so_dien_thoai"`
+	TiLeHoaHong   pgtype.Numeric   `json:"ti_le_hoa_hong"`
+	NguoiDungID   pgtype.UUID      `json:"nguoi_dung_id"`
+	DangHoatDong  bool             `json:"dang_hoat_dong"`
+	NguoiTao      pgtype.UUID      `json:"nguoi_tao"`
+	NgayTao       pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat   pgtype.Timestamp `json:"ngay_cap_nhat"`
+	SoKhoaHieuLuc int32            `json:"so_khoa_hieu_luc"`
+}
+
+func (q *Queries) ListPartners(ctx context.Context, arg ListPartnersParams) ([]ListPartnersRow, error) {
+	rows, err := q.db.Query(ctx, listPartners, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPartnersRow
+	for rows.Next() {
+		var i ListPartnersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ten,
+			&i.EmailLienHe,
+			&i.SoDienThoai,
+			&i.TiLeHoaHong,
+			&i.NguoiDungID,
+			&i.DangHoatDong,
+			&i.NguoiTao,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+			&i.SoKhoaHieuLuc,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeApiKey = `-- name: RevokeApiKey :one
+UPDATE khoa_api
+SET da_thu_hoi_luc = CURRENT_TIMESTAMP
+WHERE id = $1 AND doi_tac_id = $2 AND da_thu_hoi_luc IS NULL
+RETURNING id, doi_tac_id, ten, tien_to, pham_vi, gioi_han_moi_phut, so_lan_goi, lan_dung_cuoi, het_han, da_thu_hoi_luc, nguoi_tao, ngay_tao
+`
+
+type RevokeApiKeyParams struct {
+	ID       int32 `json:"id"`
+	DoiTacID int32 `json:"doi_tac_id"`
+}
+
+type RevokeApiKeyRow struct {
+	ID             int32            `json:"id"`
+	DoiTacID       int32            `json:"doi_tac_id"`
+	Ten            string           `json:"ten"`
+	TienTo         string           `json:"tien_to"`
+	PhamVi         []string         `json:"pham_vi"`
+	GioiHanMoiPhut int32            `json:"gioi_han_moi_phut"`
+	SoLanGoi       int64            `json:"so_lan_goi"`
+	LanDungCuoi    pgtype.Timestamp `json:"lan_dung_cuoi"`
+	HetHan         pgtype.Timestamp `json:"het_han"`
+	DaThuHoiLuc    pgtype.Timestamp `json:"da_thu_hoi_luc"`
+	NguoiTao       pgtype.UUID      `json:"nguoi_tao"`
+	NgayTao        pgtype.Timestamp `json:"ngay_tao"`
+}
+
+func (q *Queries) RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (RevokeApiKeyRow, error) {
+	row := q.db.QueryRow(ctx, revokeApiKey, arg.ID, arg.DoiTacID)
+	var i RevokeApiKeyRow
+	err := row.Scan(
+		&i.ID,
+		&i.DoiTacID,
+		&i.Ten,
+		&i.TienTo,
+		&i.PhamVi,
+		&i.GioiHanMoiPhut,
+		&i.SoLanGoi,
+		&i.LanDungCuoi,
+		&i.HetHan,
+		&i.DaThuHoiLuc,
+		&i.NguoiTao,
+		&i.NgayTao,
+	)
+	return i, err
+}
+
+const touchApiKey = `-- name: TouchApiKey :exec
+UPDATE khoa_api
+SET so_lan_goi = so_lan_goi + $1::int,
+    lan_dung_cuoi = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type TouchApiKeyParams struct {
+	SoLanGoi int32 `json:"so_lan_goi"`
+	ID       int32 `json:"id"`
+}
+
+// Cộng dồn số lượt gọi đã gom theo lô
+func (q *Queries) TouchApiKey(ctx context.Context, arg TouchApiKeyParams) error {
+	_, err := q.db.Exec(ctx, touchApiKey, arg.SoLanGoi, arg.ID)
+	return err
+}
+
+const updatePartner = `-- name: UpdatePartner :one
+UPDATE doi_tac
+SET ten = COALESCE($1, ten),
+    email_lien_he = COALESCE($2, email_lien_he),
+    so_dien_thoai = COALESCE($3, so_dien_thoai),
+    ti_le_hoa_hong = COALESCE($4, ti_le_hoa_hong),
+    dang_hoat_dong = COALESCE($5, dang_hoat_dong),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $6
+RETURNING id, ten, email_lien_he, so_dien_thoai, ti_le_hoa_hong, nguoi_dung_id, dang_hoat_dong, nguoi_tao, ngay_tao, ngay_cap_nhat
+`
+
+type UpdatePartnerParams struct {
+	Ten          *string        `json:"ten"`
+	EmailLienHe  *string        `json:"email_lien_he"`
+	SoDienThoai  *string        `json:"so_dien_thoai"`
+	TiLeHoaHong  pgtype.Numeric `json:"ti_le_hoa_hong"`
+	DangHoatDong *bool          `json:"dang_hoat_dong"`
+	ID           int32          `json:"id"`
+}
+
+func (q *Queries) UpdatePartner(ctx context.Context, arg UpdatePartnerParams) (DoiTac, error) {
+	row := q.db.QueryRow(ctx, updatePartner,
+		arg.Ten,
+		arg.EmailLienHe,
+		arg.SoDienThoai,
+		arg.TiLeHoaHong,
+		arg.DangHoatDong,
+		arg.ID,
+	)
+	var i DoiTac
+	err := row.Scan(
+		&i.ID,
+		&i.Ten,
+		&i.EmailLienHe,
+		&i.SoDienThoai,
+		&i.TiLeHoaHong,
+		&i.NguoiDungID,
+		&i.DangHoatDong,
+		&i.NguoiTao,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
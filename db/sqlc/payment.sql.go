@@ -11,6 +11,77 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelPendingBookingTransactions = `-- name: CancelPendingBookingTransactions :execrows
+UPDATE lich_su_giao_dich
+SET trang_thai = 'da_huy'
+WHERE dat_cho_id = $1
+    AND trang_thai = 'dang_cho_thanh_toan'
+    AND COALESCE(loai_giao_dich, 'thanh_toan') = 'thanh_toan'
+`
+
+// Hủy các giao dịch thanh toán đang chờ của booking (vd: tổng tiền booking thay đổi)
+func (q *Queries) CancelPendingBookingTransactions(ctx context.Context, datChoID *int32) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelPendingBookingTransactions, datChoID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const confirmTransactionPayment = `-- name: ConfirmTransactionPayment :one
+WITH gd AS (
+    UPDATE lich_su_giao_dich
+    SET trang_thai = 'thanh_cong',
+        ma_tham_chieu_cong_thanh_toan = COALESCE($1::varchar, ma_tham_chieu_cong_thanh_toan),
+        ngay_hoan_thanh = CURRENT_TIMESTAMP
+    WHERE id = $2::int
+        AND trang_thai IS DISTINCT FROM 'thanh_cong'
+    RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+), dc AS (
+    UPDATE dat_cho
+    SET trang_thai = 'da_thanh_toan',
+        phuong_thuc_thanh_toan = $3::varchar,
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    FROM gd
+    WHERE dat_cho.id = gd.dat_cho_id
+        AND dat_cho.trang_thai IN ('cho_xac_nhan', 'da_xac_nhan')
+    RETURNING dat_cho.id
+)
+SELECT
+    gd.id,
+    gd.dat_cho_id,
+    gd.ma_giao_dich_noi_bo,
+    EXISTS (SELECT 1 FROM dc) AS da_cap_nhat_dat_cho
+FROM gd
+`
+
+type ConfirmTransactionPaymentParams struct {
+	MaThamChieu         *string `json:"ma_tham_chieu"`
+	ID                  int32   `json:"id"`
+	PhuongThucThanhToan string  `json:"phuong_thuc_thanh_toan"`
+}
+
+type ConfirmTransactionPaymentRow struct {
+	ID              int32  `json:"id"`
+	DatChoID        *int32 `json:"dat_cho_id"`
+	MaGiaoDichNoiBo string `json:"ma_giao_dich_noi_bo"`
+	DaCapNhatDatCho bool   `json:"da_cap_nhat_dat_cho"`
+}
+
+// Xác nhận giao dịch thanh toán thành công và chuyển booking sang da_thanh_toan trong cùng một câu lệnh
+// Idempotent: giao dịch đã thanh_cong sẽ không trả về dòng nào (pgx.ErrNoRows)
+func (q *Queries) ConfirmTransactionPayment(ctx context.Context, arg ConfirmTransactionPaymentParams) (ConfirmTransactionPaymentRow, error) {
+	row := q.db.QueryRow(ctx, confirmTransactionPayment, arg.MaThamChieu, arg.ID, arg.PhuongThucThanhToan)
+	var i ConfirmTransactionPaymentRow
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.MaGiaoDichNoiBo,
+		&i.DaCapNhatDatCho,
+	)
+	return i, err
+}
+
 const countAllTransactions = `-- name: CountAllTransactions :one
 SELECT COUNT(*) FROM lich_su_giao_dich
 `
@@ -23,6 +94,47 @@ func (q *Queries) CountAllTransactions(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const failPendingTransaction = `-- name: FailPendingTransaction :one
+UPDATE lich_su_giao_dich
+SET trang_thai = 'that_bai',
+    ma_tham_chieu_cong_thanh_toan = COALESCE($1::varchar, ma_tham_chieu_cong_thanh_toan)
+WHERE id = $2::int
+    AND trang_thai IN ('dang_cho_thanh_toan', 'dang_xuly')
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+`
+
+type FailPendingTransactionParams struct {
+	MaThamChieu *string `json:"ma_tham_chieu"`
+	ID          int32   `json:"id"`
+}
+
+// Đánh dấu giao dịch thất bại nếu chưa thành công (không ghi đè giao dịch đã thanh_cong)
+func (q *Queries) FailPendingTransaction(ctx context.Context, arg FailPendingTransactionParams) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, failPendingTransaction, arg.MaThamChieu, arg.ID)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
 const getAllTransactions = `-- name: GetAllTransactions :many
 
 SELECT 
@@ -320,3 +432,42 @@ func (q *Queries) GetTransactionsByStatus(ctx context.Context, arg GetTransactio
 	}
 	return items, nil
 }
+
+const setTransactionGatewayReference = `-- name: SetTransactionGatewayReference :one
+UPDATE lich_su_giao_dich
+SET ma_tham_chieu_cong_thanh_toan = $1::varchar
+WHERE id = $2::int
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+`
+
+type SetTransactionGatewayReferenceParams struct {
+	MaThamChieu string `json:"ma_tham_chieu"`
+	ID          int32  `json:"id"`
+}
+
+// Lưu mã tham chiếu của cổng thanh toán (vd: Stripe PaymentIntent ID) khi giao dịch còn chờ
+func (q *Queries) SetTransactionGatewayReference(ctx context.Context, arg SetTransactionGatewayReferenceParams) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, setTransactionGatewayReference, arg.MaThamChieu, arg.ID)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
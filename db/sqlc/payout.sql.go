@@ -0,0 +1,958 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: payout.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const approvePayouts = `-- name: ApprovePayouts :many
+UPDATE chi_tra_nha_cung_cap ct
+SET trang_thai = 'dang_xu_ly',
+    tai_khoan_ngan_hang_id = tk.id,
+    nguoi_duyet_id = $1::uuid,
+    ngay_duyet = CURRENT_TIMESTAMP,
+    ghi_chu = COALESCE($2::text, ct.ghi_chu),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM tai_khoan_ngan_hang tk
+WHERE tk.nha_cung_cap_id = ct.nha_cung_cap_id
+    AND tk.la_mac_dinh = TRUE
+    AND ct.id = ANY($3::int[])
+    AND ct.trang_thai IN ('san_sang', 'that_bai')
+RETURNING ct.id, ct.nha_cung_cap_id, ct.khoi_hanh_id, ct.tai_khoan_ngan_hang_id, ct.so_dat_cho, ct.tong_doanh_thu, ct.phi_cong_thanh_toan, ct.ty_le_hoa_hong, ct.phi_hoa_hong, ct.so_tien_thuc_nhan, ct.don_vi_tien_te, ct.trang_thai, ct.ma_tham_chieu_chi_tra, ct.ghi_chu, ct.nguoi_duyet_id, ct.ngay_duyet, ct.ngay_chi_tra, ct.ngay_tao, ct.ngay_cap_nhat
+`
+
+type ApprovePayoutsParams struct {
+	NguoiDuyetID pgtype.UUID `json:"nguoi_duyet_id"`
+	GhiChu       *string     `json:"ghi_chu"`
+	Ids          []int32     `json:"ids"`
+}
+
+// Duyệt chi trả hàng loạt: san_sang/that_bai -> dang_xu_ly
+// Chỉ duyệt các nhà cung cấp đã có tài khoản ngân hàng mặc định (lưu lại tài khoản tại thời điểm duyệt)
+func (q *Queries) ApprovePayouts(ctx context.Context, arg ApprovePayoutsParams) ([]ChiTraNhaCungCap, error) {
+	rows, err := q.db.Query(ctx, approvePayouts, arg.NguoiDuyetID, arg.GhiChu, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChiTraNhaCungCap
+	for rows.Next() {
+		var i ChiTraNhaCungCap
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const cancelStaleSupplierPayouts = `-- name: CancelStaleSupplierPayouts :many
+UPDATE chi_tra_nha_cung_cap ct
+SET trang_thai = 'da_huy',
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM khoi_hanh_tour kh
+WHERE kh.id = ct.khoi_hanh_id
+    AND ct.trang_thai IN ('cho_chi_tra', 'san_sang')
+    AND (
+        kh.trang_thai = 'huy'
+        OR NOT EXISTS (
+            SELECT 1 FROM dat_cho dc
+            WHERE dc.khoi_hanh_id = kh.id
+                AND dc.trang_thai IN ('da_thanh_toan', 'hoan_thanh')
+        )
+    )
+RETURNING ct.id, ct.nha_cung_cap_id, ct.khoi_hanh_id, ct.tai_khoan_ngan_hang_id, ct.so_dat_cho, ct.tong_doanh_thu, ct.phi_cong_thanh_toan, ct.ty_le_hoa_hong, ct.phi_hoa_hong, ct.so_tien_thuc_nhan, ct.don_vi_tien_te, ct.trang_thai, ct.ma_tham_chieu_chi_tra, ct.ghi_chu, ct.nguoi_duyet_id, ct.ngay_duyet, ct.ngay_chi_tra, ct.ngay_tao, ct.ngay_cap_nhat
+`
+
+// Hủy các khoản chi trả chưa duyệt khi khởi hành bị hủy hoặc không còn booking đã thanh toán
+func (q *Queries) CancelStaleSupplierPayouts(ctx context.Context) ([]ChiTraNhaCungCap, error) {
+	rows, err := q.db.Query(ctx, cancelStaleSupplierPayouts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChiTraNhaCungCap
+	for rows.Next() {
+		var i ChiTraNhaCungCap
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clearDefaultBankAccount = `-- name: ClearDefaultBankAccount :exec
+UPDATE tai_khoan_ngan_hang
+SET la_mac_dinh = FALSE
+WHERE nha_cung_cap_id = $1 AND la_mac_dinh = TRUE
+`
+
+// Bỏ đánh dấu mặc định cho tất cả tài khoản của nhà cung cấp
+func (q *Queries) ClearDefaultBankAccount(ctx context.Context, nhaCungCapID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, clearDefaultBankAccount, nhaCungCapID)
+	return err
+}
+
+const countPayouts = `-- name: CountPayouts :one
+SELECT COUNT(*)
+FROM chi_tra_nha_cung_cap ct
+WHERE ($1::trang_thai_chi_tra IS NULL OR ct.trang_thai = $1::trang_thai_chi_tra)
+    AND ($2::uuid IS NULL OR ct.nha_cung_cap_id = $2::uuid)
+`
+
+type CountPayoutsParams struct {
+	TrangThai    NullTrangThaiChiTra `json:"trang_thai"`
+	NhaCungCapID pgtype.UUID         `json:"nha_cung_cap_id"`
+}
+
+// Đếm tổng số khoản chi trả theo filter
+func (q *Queries) CountPayouts(ctx context.Context, arg CountPayoutsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPayouts, arg.TrangThai, arg.NhaCungCapID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSupplierPayouts = `-- name: CountSupplierPayouts :one
+SELECT COUNT(*)
+FROM chi_tra_nha_cung_cap
+WHERE nha_cung_cap_id = $1::uuid
+    AND ($2::trang_thai_chi_tra IS NULL OR trang_thai = $2::trang_thai_chi_tra)
+`
+
+type CountSupplierPayoutsParams struct {
+	NhaCungCapID pgtype.UUID         `json:"nha_cung_cap_id"`
+	TrangThai    NullTrangThaiChiTra `json:"trang_thai"`
+}
+
+// Đếm số khoản chi trả của nhà cung cấp
+func (q *Queries) CountSupplierPayouts(ctx context.Context, arg CountSupplierPayoutsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSupplierPayouts, arg.NhaCungCapID, arg.TrangThai)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBankAccount = `-- name: CreateBankAccount :one
+INSERT INTO tai_khoan_ngan_hang (
+    nha_cung_cap_id,
+    ten_ngan_hang,
+    so_tai_khoan,
+    ten_chu_tai_khoan,
+    chi_nhanh,
+    la_mac_dinh
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, nha_cung_cap_id, ten_ngan_hang, so_tai_khoan, ten_chu_tai_khoan, chi_nhanh, la_mac_dinh
+`
+
+type CreateBankAccountParams struct {
+	NhaCungCapID   pgtype.UUID `json:"nha_cung_cap_id"`
+	TenNganHang    string      `json:"ten_ngan_hang"`
+	SoTaiKhoan     string      `json:"so_tai_khoan"`
+	TenChuTaiKhoan string      `json:"ten_chu_tai_khoan"`
+	ChiNhanh       *string     `json:"chi_nhanh"`
+	LaMacDinh      *bool       `json:"la_mac_dinh"`
+}
+
+// Thêm tài khoản ngân hàng cho nhà cung cấp
+func (q *Queries) CreateBankAccount(ctx context.Context, arg CreateBankAccountParams) (TaiKhoanNganHang, error) {
+	row := q.db.QueryRow(ctx, createBankAccount,
+		arg.NhaCungCapID,
+		arg.TenNganHang,
+		arg.SoTaiKhoan,
+		arg.TenChuTaiKhoan,
+		arg.ChiNhanh,
+		arg.LaMacDinh,
+	)
+	var i TaiKhoanNganHang
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.TenNganHang,
+		&i.SoTaiKhoan,
+		&i.TenChuTaiKhoan,
+		&i.ChiNhanh,
+		&i.LaMacDinh,
+	)
+	return i, err
+}
+
+const deleteBankAccount = `-- name: DeleteBankAccount :one
+DELETE FROM tai_khoan_ngan_hang
+WHERE id = $1 AND nha_cung_cap_id = $2
+RETURNING id, nha_cung_cap_id, ten_ngan_hang, so_tai_khoan, ten_chu_tai_khoan, chi_nhanh, la_mac_dinh
+`
+
+type DeleteBankAccountParams struct {
+	ID           int32       `json:"id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+// Xóa tài khoản ngân hàng của nhà cung cấp
+func (q *Queries) DeleteBankAccount(ctx context.Context, arg DeleteBankAccountParams) (TaiKhoanNganHang, error) {
+	row := q.db.QueryRow(ctx, deleteBankAccount, arg.ID, arg.NhaCungCapID)
+	var i TaiKhoanNganHang
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.TenNganHang,
+		&i.SoTaiKhoan,
+		&i.TenChuTaiKhoan,
+		&i.ChiNhanh,
+		&i.LaMacDinh,
+	)
+	return i, err
+}
+
+const getPayouts = `-- name: GetPayouts :many
+SELECT
+    ct.id, ct.nha_cung_cap_id, ct.khoi_hanh_id, ct.tai_khoan_ngan_hang_id, ct.so_dat_cho, ct.tong_doanh_thu, ct.phi_cong_thanh_toan, ct.ty_le_hoa_hong, ct.phi_hoa_hong, ct.so_tien_thuc_nhan, ct.don_vi_tien_te, ct.trang_thai, ct.ma_tham_chieu_chi_tra, ct.ghi_chu, ct.nguoi_duyet_id, ct.ngay_duyet, ct.ngay_chi_tra, ct.ngay_tao, ct.ngay_cap_nhat,
+    ncc.ten AS ten_nha_cung_cap,
+    t.id AS tour_id,
+    t.tieu_de AS ten_tour,
+    kh.ngay_khoi_hanh,
+    kh.ngay_ket_thuc,
+    tk.ten_ngan_hang,
+    tk.so_tai_khoan,
+    tk.ten_chu_tai_khoan
+FROM chi_tra_nha_cung_cap ct
+JOIN nha_cung_cap ncc ON ncc.id = ct.nha_cung_cap_id
+JOIN khoi_hanh_tour kh ON kh.id = ct.khoi_hanh_id
+JOIN tour t ON t.id = kh.tour_id
+LEFT JOIN tai_khoan_ngan_hang tk ON tk.id = COALESCE(
+    ct.tai_khoan_ngan_hang_id,
+    (SELECT tkm.id FROM tai_khoan_ngan_hang tkm WHERE tkm.nha_cung_cap_id = ct.nha_cung_cap_id AND tkm.la_mac_dinh = TRUE LIMIT 1)
+)
+WHERE ($1::trang_thai_chi_tra IS NULL OR ct.trang_thai = $1::trang_thai_chi_tra)
+    AND ($2::uuid IS NULL OR ct.nha_cung_cap_id = $2::uuid)
+ORDER BY kh.ngay_ket_thuc DESC, ct.id DESC
+LIMIT $4::int OFFSET $3::int
+`
+
+type GetPayoutsParams struct {
+	TrangThai    NullTrangThaiChiTra `json:"trang_thai"`
+	NhaCungCapID pgtype.UUID         `json:"nha_cung_cap_id"`
+	Offset       int32               `json:"offset"`
+	Limit        int32               `json:"limit"`
+}
+
+type GetPayoutsRow struct {
+	ID                 int32            `json:"id"`
+	NhaCungCapID       pgtype.UUID      `json:"nha_cung_cap_id"`
+	KhoiHanhID         int32            `json:"khoi_hanh_id"`
+	TaiKhoanNganHangID *int32           `json:"tai_khoan_ngan_hang_id"`
+	SoDatCho           int32            `json:"so_dat_cho"`
+	TongDoanhThu       pgtype.Numeric   `json:"tong_doanh_thu"`
+	PhiCongThanhToan   pgtype.Numeric   `json:"phi_cong_thanh_toan"`
+	TyLeHoaHong        pgtype.Numeric   `json:"ty_le_hoa_hong"`
+	PhiHoaHong         pgtype.Numeric   `json:"phi_hoa_hong"`
+	SoTienThucNhan     pgtype.Numeric   `json:"so_tien_thuc_nhan"`
+	DonViTienTe        *string          `json:"don_vi_tien_te"`
+	TrangThai          TrangThaiChiTra  `json:"trang_thai"`
+	MaThamChieuChiTra  *string          `json:"ma_tham_chieu_chi_tra"`
+	GhiChu             *string          `json:"ghi_chu"`
+	NguoiDuyetID       pgtype.UUID      `json:"nguoi_duyet_id"`
+	NgayDuyet          pgtype.Timestamp `json:"ngay_duyet"`
+	NgayChiTra         pgtype.Timestamp `json:"ngay_chi_tra"`
+	NgayTao            pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat        pgtype.Timestamp `json:"ngay_cap_nhat"`
+	TenNhaCungCap      string           `json:"ten_nha_cung_cap"`
+	TourID             int32            `json:"tour_id"`
+	TenTour            string           `json:"ten_tour"`
+	NgayKhoiHanh       pgtype.Date      `json:"ngay_khoi_hanh"`
+	NgayKetThuc        pgtype.Date      `json:"ngay_ket_thuc"`
+	TenNganHang        *string          `json:"ten_ngan_hang"`
+	SoTaiKhoan         *string          `json:"so_tai_khoan"`
+	TenChuTaiKhoan     *string          `json:"ten_chu_tai_khoan"`
+}
+
+// Lấy danh sách khoản chi trả (dành cho Admin) với filter trạng thái và nhà cung cấp
+func (q *Queries) GetPayouts(ctx context.Context, arg GetPayoutsParams) ([]GetPayoutsRow, error) {
+	rows, err := q.db.Query(ctx, getPayouts,
+		arg.TrangThai,
+		arg.NhaCungCapID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPayoutsRow
+	for rows.Next() {
+		var i GetPayoutsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+			&i.TenNhaCungCap,
+			&i.TourID,
+			&i.TenTour,
+			&i.NgayKhoiHanh,
+			&i.NgayKetThuc,
+			&i.TenNganHang,
+			&i.SoTaiKhoan,
+			&i.TenChuTaiKhoan,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSupplierBankAccounts = `-- name: GetSupplierBankAccounts :many
+
+SELECT id, nha_cung_cap_id, ten_ngan_hang, so_tai_khoan, ten_chu_tai_khoan, chi_nhanh, la_mac_dinh FROM tai_khoan_ngan_hang
+WHERE nha_cung_cap_id = $1
+ORDER BY la_mac_dinh DESC, id ASC
+`
+
+// ===========================================
+// TÀI KHOẢN NGÂN HÀNG NHÀ CUNG CẤP
+// ===========================================
+// Lấy danh sách tài khoản ngân hàng của nhà cung cấp (tài khoản mặc định lên đầu)
+func (q *Queries) GetSupplierBankAccounts(ctx context.Context, nhaCungCapID pgtype.UUID) ([]TaiKhoanNganHang, error) {
+	rows, err := q.db.Query(ctx, getSupplierBankAccounts, nhaCungCapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaiKhoanNganHang
+	for rows.Next() {
+		var i TaiKhoanNganHang
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.TenNganHang,
+			&i.SoTaiKhoan,
+			&i.TenChuTaiKhoan,
+			&i.ChiNhanh,
+			&i.LaMacDinh,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSupplierPayoutSummary = `-- name: GetSupplierPayoutSummary :one
+SELECT
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'cho_chi_tra'), 0)::numeric AS cho_chi_tra,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'san_sang'), 0)::numeric AS san_sang,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'dang_xu_ly'), 0)::numeric AS dang_xu_ly,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'da_chi_tra'), 0)::numeric AS da_chi_tra,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'da_giu'), 0)::numeric AS da_giu,
+    COALESCE(SUM(so_tien_thuc_nhan) FILTER (WHERE trang_thai = 'that_bai'), 0)::numeric AS that_bai,
+    COALESCE(SUM(tong_doanh_thu) FILTER (WHERE trang_thai <> 'da_huy'), 0)::numeric AS tong_doanh_thu,
+    COALESCE(SUM(phi_cong_thanh_toan) FILTER (WHERE trang_thai <> 'da_huy'), 0)::numeric AS tong_phi_cong_thanh_toan,
+    COALESCE(SUM(phi_hoa_hong) FILTER (WHERE trang_thai <> 'da_huy'), 0)::numeric AS tong_phi_hoa_hong
+FROM chi_tra_nha_cung_cap
+WHERE nha_cung_cap_id = $1::uuid
+`
+
+type GetSupplierPayoutSummaryRow struct {
+	ChoChiTra            pgtype.Numeric `json:"cho_chi_tra"`
+	SanSang              pgtype.Numeric `json:"san_sang"`
+	DangXuLy             pgtype.Numeric `json:"dang_xu_ly"`
+	DaChiTra             pgtype.Numeric `json:"da_chi_tra"`
+	DaGiu                pgtype.Numeric `json:"da_giu"`
+	ThatBai              pgtype.Numeric `json:"that_bai"`
+	TongDoanhThu         pgtype.Numeric `json:"tong_doanh_thu"`
+	TongPhiCongThanhToan pgtype.Numeric `json:"tong_phi_cong_thanh_toan"`
+	TongPhiHoaHong       pgtype.Numeric `json:"tong_phi_hoa_hong"`
+}
+
+// Tổng hợp số tiền chi trả của nhà cung cấp theo trạng thái
+func (q *Queries) GetSupplierPayoutSummary(ctx context.Context, nhaCungCapID pgtype.UUID) (GetSupplierPayoutSummaryRow, error) {
+	row := q.db.QueryRow(ctx, getSupplierPayoutSummary, nhaCungCapID)
+	var i GetSupplierPayoutSummaryRow
+	err := row.Scan(
+		&i.ChoChiTra,
+		&i.SanSang,
+		&i.DangXuLy,
+		&i.DaChiTra,
+		&i.DaGiu,
+		&i.ThatBai,
+		&i.TongDoanhThu,
+		&i.TongPhiCongThanhToan,
+		&i.TongPhiHoaHong,
+	)
+	return i, err
+}
+
+const getSupplierPayouts = `-- name: GetSupplierPayouts :many
+SELECT
+    ct.id, ct.nha_cung_cap_id, ct.khoi_hanh_id, ct.tai_khoan_ngan_hang_id, ct.so_dat_cho, ct.tong_doanh_thu, ct.phi_cong_thanh_toan, ct.ty_le_hoa_hong, ct.phi_hoa_hong, ct.so_tien_thuc_nhan, ct.don_vi_tien_te, ct.trang_thai, ct.ma_tham_chieu_chi_tra, ct.ghi_chu, ct.nguoi_duyet_id, ct.ngay_duyet, ct.ngay_chi_tra, ct.ngay_tao, ct.ngay_cap_nhat,
+    t.id AS tour_id,
+    t.tieu_de AS ten_tour,
+    kh.ngay_khoi_hanh,
+    kh.ngay_ket_thuc,
+    tk.ten_ngan_hang,
+    tk.so_tai_khoan
+FROM chi_tra_nha_cung_cap ct
+JOIN khoi_hanh_tour kh ON kh.id = ct.khoi_hanh_id
+JOIN tour t ON t.id = kh.tour_id
+LEFT JOIN tai_khoan_ngan_hang tk ON tk.id = ct.tai_khoan_ngan_hang_id
+WHERE ct.nha_cung_cap_id = $1::uuid
+    AND ($2::trang_thai_chi_tra IS NULL OR ct.trang_thai = $2::trang_thai_chi_tra)
+ORDER BY kh.ngay_ket_thuc DESC, ct.id DESC
+LIMIT $4::int OFFSET $3::int
+`
+
+type GetSupplierPayoutsParams struct {
+	NhaCungCapID pgtype.UUID         `json:"nha_cung_cap_id"`
+	TrangThai    NullTrangThaiChiTra `json:"trang_thai"`
+	Offset       int32               `json:"offset"`
+	Limit        int32               `json:"limit"`
+}
+
+type GetSupplierPayoutsRow struct {
+	ID                 int32            `json:"id"`
+	NhaCungCapID       pgtype.UUID      `json:"nha_cung_cap_id"`
+	KhoiHanhID         int32            `json:"khoi_hanh_id"`
+	TaiKhoanNganHangID *int32           `json:"tai_khoan_ngan_hang_id"`
+	SoDatCho           int32            `json:"so_dat_cho"`
+	TongDoanhThu       pgtype.Numeric   `json:"tong_doanh_thu"`
+	PhiCongThanhToan   pgtype.Numeric   `json:"phi_cong_thanh_toan"`
+	TyLeHoaHong        pgtype.Numeric   `json:"ty_le_hoa_hong"`
+	PhiHoaHong         pgtype.Numeric   `json:"phi_hoa_hong"`
+	SoTienThucNhan     pgtype.Numeric   `json:"so_tien_thuc_nhan"`
+	DonViTienTe        *string          `json:"don_vi_tien_te"`
+	TrangThai          TrangThaiChiTra  `json:"trang_thai"`
+	MaThamChieuChiTra  *string          `json:"ma_tham_chieu_chi_tra"`
+	GhiChu             *string          `json:"ghi_chu"`
+	NguoiDuyetID       pgtype.UUID      `json:"nguoi_duyet_id"`
+	NgayDuyet          pgtype.Timestamp `json:"ngay_duyet"`
+	NgayChiTra         pgtype.Timestamp `json:"ngay_chi_tra"`
+	NgayTao            pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat        pgtype.Timestamp `json:"ngay_cap_nhat"`
+	TourID             int32            `json:"tour_id"`
+	TenTour            string           `json:"ten_tour"`
+	NgayKhoiHanh       pgtype.Date      `json:"ngay_khoi_hanh"`
+	NgayKetThuc        pgtype.Date      `json:"ngay_ket_thuc"`
+	TenNganHang        *string          `json:"ten_ngan_hang"`
+	SoTaiKhoan         *string          `json:"so_tai_khoan"`
+}
+
+// Lịch sử chi trả của nhà cung cấp
+func (q *Queries) GetSupplierPayouts(ctx context.Context, arg GetSupplierPayoutsParams) ([]GetSupplierPayoutsRow, error) {
+	rows, err := q.db.Query(ctx, getSupplierPayouts,
+		arg.NhaCungCapID,
+		arg.TrangThai,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSupplierPayoutsRow
+	for rows.Next() {
+		var i GetSupplierPayoutsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+			&i.TourID,
+			&i.TenTour,
+			&i.NgayKhoiHanh,
+			&i.NgayKetThuc,
+			&i.TenNganHang,
+			&i.SoTaiKhoan,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const holdPayouts = `-- name: HoldPayouts :many
+UPDATE chi_tra_nha_cung_cap
+SET trang_thai = 'da_giu',
+    nguoi_duyet_id = $1::uuid,
+    ghi_chu = COALESCE($2::text, ghi_chu),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = ANY($3::int[])
+    AND trang_thai IN ('cho_chi_tra', 'san_sang', 'dang_xu_ly', 'that_bai')
+RETURNING id, nha_cung_cap_id, khoi_hanh_id, tai_khoan_ngan_hang_id, so_dat_cho, tong_doanh_thu, phi_cong_thanh_toan, ty_le_hoa_hong, phi_hoa_hong, so_tien_thuc_nhan, don_vi_tien_te, trang_thai, ma_tham_chieu_chi_tra, ghi_chu, nguoi_duyet_id, ngay_duyet, ngay_chi_tra, ngay_tao, ngay_cap_nhat
+`
+
+type HoldPayoutsParams struct {
+	NguoiDuyetID pgtype.UUID `json:"nguoi_duyet_id"`
+	GhiChu       *string     `json:"ghi_chu"`
+	Ids          []int32     `json:"ids"`
+}
+
+// Giữ lại chi trả hàng loạt (tranh chấp): các khoản chưa chi trả -> da_giu
+func (q *Queries) HoldPayouts(ctx context.Context, arg HoldPayoutsParams) ([]ChiTraNhaCungCap, error) {
+	rows, err := q.db.Query(ctx, holdPayouts, arg.NguoiDuyetID, arg.GhiChu, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChiTraNhaCungCap
+	for rows.Next() {
+		var i ChiTraNhaCungCap
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPayoutsFailed = `-- name: MarkPayoutsFailed :many
+UPDATE chi_tra_nha_cung_cap
+SET trang_thai = 'that_bai',
+    ghi_chu = COALESCE($1::text, ghi_chu),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = ANY($2::int[])
+    AND trang_thai = 'dang_xu_ly'
+RETURNING id, nha_cung_cap_id, khoi_hanh_id, tai_khoan_ngan_hang_id, so_dat_cho, tong_doanh_thu, phi_cong_thanh_toan, ty_le_hoa_hong, phi_hoa_hong, so_tien_thuc_nhan, don_vi_tien_te, trang_thai, ma_tham_chieu_chi_tra, ghi_chu, nguoi_duyet_id, ngay_duyet, ngay_chi_tra, ngay_tao, ngay_cap_nhat
+`
+
+type MarkPayoutsFailedParams struct {
+	GhiChu *string `json:"ghi_chu"`
+	Ids    []int32 `json:"ids"`
+}
+
+// Đánh dấu chi trả thất bại: dang_xu_ly -> that_bai (có thể duyệt lại)
+func (q *Queries) MarkPayoutsFailed(ctx context.Context, arg MarkPayoutsFailedParams) ([]ChiTraNhaCungCap, error) {
+	rows, err := q.db.Query(ctx, markPayoutsFailed, arg.GhiChu, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChiTraNhaCungCap
+	for rows.Next() {
+		var i ChiTraNhaCungCap
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPayoutsPaid = `-- name: MarkPayoutsPaid :many
+UPDATE chi_tra_nha_cung_cap
+SET trang_thai = 'da_chi_tra',
+    ma_tham_chieu_chi_tra = COALESCE($1::varchar, ma_tham_chieu_chi_tra),
+    ghi_chu = COALESCE($2::text, ghi_chu),
+    ngay_chi_tra = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = ANY($3::int[])
+    AND trang_thai = 'dang_xu_ly'
+RETURNING id, nha_cung_cap_id, khoi_hanh_id, tai_khoan_ngan_hang_id, so_dat_cho, tong_doanh_thu, phi_cong_thanh_toan, ty_le_hoa_hong, phi_hoa_hong, so_tien_thuc_nhan, don_vi_tien_te, trang_thai, ma_tham_chieu_chi_tra, ghi_chu, nguoi_duyet_id, ngay_duyet, ngay_chi_tra, ngay_tao, ngay_cap_nhat
+`
+
+type MarkPayoutsPaidParams struct {
+	MaThamChieuChiTra *string `json:"ma_tham_chieu_chi_tra"`
+	GhiChu            *string `json:"ghi_chu"`
+	Ids               []int32 `json:"ids"`
+}
+
+// Đánh dấu đã chi trả: dang_xu_ly -> da_chi_tra
+func (q *Queries) MarkPayoutsPaid(ctx context.Context, arg MarkPayoutsPaidParams) ([]ChiTraNhaCungCap, error) {
+	rows, err := q.db.Query(ctx, markPayoutsPaid, arg.MaThamChieuChiTra, arg.GhiChu, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChiTraNhaCungCap
+	for rows.Next() {
+		var i ChiTraNhaCungCap
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releasePayouts = `-- name: ReleasePayouts :many
+UPDATE chi_tra_nha_cung_cap ct
+SET trang_thai = (CASE
+        WHEN kh.trang_thai = 'hoan_thanh' OR kh.ngay_ket_thuc < CURRENT_DATE THEN 'san_sang'
+        ELSE 'cho_chi_tra'
+    END)::trang_thai_chi_tra,
+    nguoi_duyet_id = $1::uuid,
+    ghi_chu = COALESCE($2::text, ct.ghi_chu),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM khoi_hanh_tour kh
+WHERE kh.id = ct.khoi_hanh_id
+    AND ct.id = ANY($3::int[])
+    AND ct.trang_thai = 'da_giu'
+RETURNING ct.id, ct.nha_cung_cap_id, ct.khoi_hanh_id, ct.tai_khoan_ngan_hang_id, ct.so_dat_cho, ct.tong_doanh_thu, ct.phi_cong_thanh_toan, ct.ty_le_hoa_hong, ct.phi_hoa_hong, ct.so_tien_thuc_nhan, ct.don_vi_tien_te, ct.trang_thai, ct.ma_tham_chieu_chi_tra, ct.ghi_chu, ct.nguoi_duyet_id, ct.ngay_duyet, ct.ngay_chi_tra, ct.ngay_tao, ct.ngay_cap_nhat
+`
+
+type ReleasePayoutsParams struct {
+	NguoiDuyetID pgtype.UUID `json:"nguoi_duyet_id"`
+	GhiChu       *string     `json:"ghi_chu"`
+	Ids          []int32     `json:"ids"`
+}
+
+// Bỏ giữ chi trả: da_giu -> san_sang (nếu khởi hành đã hoàn thành) hoặc cho_chi_tra
+func (q *Queries) ReleasePayouts(ctx context.Context, arg ReleasePayoutsParams) ([]ChiTraNhaCungCap, error) {
+	rows, err := q.db.Query(ctx, releasePayouts, arg.NguoiDuyetID, arg.GhiChu, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChiTraNhaCungCap
+	for rows.Next() {
+		var i ChiTraNhaCungCap
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setDefaultBankAccount = `-- name: SetDefaultBankAccount :one
+UPDATE tai_khoan_ngan_hang
+SET la_mac_dinh = TRUE
+WHERE id = $1 AND nha_cung_cap_id = $2
+RETURNING id, nha_cung_cap_id, ten_ngan_hang, so_tai_khoan, ten_chu_tai_khoan, chi_nhanh, la_mac_dinh
+`
+
+type SetDefaultBankAccountParams struct {
+	ID           int32       `json:"id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+// Đặt tài khoản làm mặc định (gọi sau ClearDefaultBankAccount trong cùng transaction)
+func (q *Queries) SetDefaultBankAccount(ctx context.Context, arg SetDefaultBankAccountParams) (TaiKhoanNganHang, error) {
+	row := q.db.QueryRow(ctx, setDefaultBankAccount, arg.ID, arg.NhaCungCapID)
+	var i TaiKhoanNganHang
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.TenNganHang,
+		&i.SoTaiKhoan,
+		&i.TenChuTaiKhoan,
+		&i.ChiNhanh,
+		&i.LaMacDinh,
+	)
+	return i, err
+}
+
+const upsertSupplierPayouts = `-- name: UpsertSupplierPayouts :many
+
+WITH thanh_toan AS (
+    SELECT
+        dc.id,
+        dc.khoi_hanh_id,
+        dc.tong_tien,
+        dc.tong_tien * COALESCE(ctt.phi_giao_dich_phan_tram, 0) / 100 AS phi_cong
+    FROM dat_cho dc
+    LEFT JOIN LATERAL (
+        SELECT lsgd.cong_thanh_toan_id
+        FROM lich_su_giao_dich lsgd
+        WHERE lsgd.dat_cho_id = dc.id
+            AND lsgd.trang_thai = 'thanh_cong'
+            AND COALESCE(lsgd.loai_giao_dich, 'thanh_toan') = 'thanh_toan'
+        ORDER BY lsgd.ngay_hoan_thanh DESC NULLS LAST, lsgd.id DESC
+        LIMIT 1
+    ) gd ON TRUE
+    LEFT JOIN cong_thanh_toan ctt ON ctt.id = COALESCE(gd.cong_thanh_toan_id, dc.phuong_thuc_thanh_toan)
+    WHERE dc.trang_thai IN ('da_thanh_toan', 'hoan_thanh')
+), tong_hop AS (
+    SELECT
+        kh.id AS khoi_hanh_id,
+        t.nha_cung_cap_id,
+        COUNT(tt.id)::int AS so_dat_cho,
+        SUM(tt.tong_tien) AS tong_doanh_thu,
+        SUM(tt.phi_cong) AS phi_cong_thanh_toan,
+        BOOL_OR(kh.trang_thai = 'hoan_thanh' OR kh.ngay_ket_thuc < CURRENT_DATE) AS da_hoan_thanh
+    FROM thanh_toan tt
+    JOIN khoi_hanh_tour kh ON kh.id = tt.khoi_hanh_id
+    JOIN tour t ON t.id = kh.tour_id
+    WHERE kh.trang_thai <> 'huy'
+        AND t.nha_cung_cap_id IS NOT NULL
+    GROUP BY kh.id, t.nha_cung_cap_id
+)
+INSERT INTO chi_tra_nha_cung_cap (
+    nha_cung_cap_id,
+    khoi_hanh_id,
+    so_dat_cho,
+    tong_doanh_thu,
+    phi_cong_thanh_toan,
+    ty_le_hoa_hong,
+    phi_hoa_hong,
+    so_tien_thuc_nhan,
+    trang_thai
+)
+SELECT
+    th.nha_cung_cap_id,
+    th.khoi_hanh_id,
+    th.so_dat_cho,
+    ROUND(th.tong_doanh_thu, 2),
+    ROUND(th.phi_cong_thanh_toan, 2),
+    $1::numeric,
+    ROUND(th.tong_doanh_thu * $1::numeric / 100, 2),
+    ROUND(th.tong_doanh_thu - th.phi_cong_thanh_toan - th.tong_doanh_thu * $1::numeric / 100, 2),
+    (CASE WHEN th.da_hoan_thanh THEN 'san_sang' ELSE 'cho_chi_tra' END)::trang_thai_chi_tra
+FROM tong_hop th
+ON CONFLICT (khoi_hanh_id) DO UPDATE SET
+    so_dat_cho = EXCLUDED.so_dat_cho,
+    tong_doanh_thu = EXCLUDED.tong_doanh_thu,
+    phi_cong_thanh_toan = EXCLUDED.phi_cong_thanh_toan,
+    ty_le_hoa_hong = EXCLUDED.ty_le_hoa_hong,
+    phi_hoa_hong = EXCLUDED.phi_hoa_hong,
+    so_tien_thuc_nhan = EXCLUDED.so_tien_thuc_nhan,
+    trang_thai = EXCLUDED.trang_thai,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE chi_tra_nha_cung_cap.trang_thai IN ('cho_chi_tra', 'san_sang')
+    AND (
+        chi_tra_nha_cung_cap.so_dat_cho,
+        chi_tra_nha_cung_cap.tong_doanh_thu,
+        chi_tra_nha_cung_cap.phi_cong_thanh_toan,
+        chi_tra_nha_cung_cap.ty_le_hoa_hong,
+        chi_tra_nha_cung_cap.trang_thai
+    ) IS DISTINCT FROM (
+        EXCLUDED.so_dat_cho,
+        EXCLUDED.tong_doanh_thu,
+        EXCLUDED.phi_cong_thanh_toan,
+        EXCLUDED.ty_le_hoa_hong,
+        EXCLUDED.trang_thai
+    )
+RETURNING id, nha_cung_cap_id, khoi_hanh_id, tai_khoan_ngan_hang_id, so_dat_cho, tong_doanh_thu, phi_cong_thanh_toan, ty_le_hoa_hong, phi_hoa_hong, so_tien_thuc_nhan, don_vi_tien_te, trang_thai, ma_tham_chieu_chi_tra, ghi_chu, nguoi_duyet_id, ngay_duyet, ngay_chi_tra, ngay_tao, ngay_cap_nhat
+`
+
+// ===========================================
+// CHI TRẢ NHÀ CUNG CẤP (SUPPLIER PAYOUT)
+// ===========================================
+// Tính lại khoản chi trả cho từng lịch khởi hành có booking đã thanh toán
+// Phí cổng thanh toán lấy theo cổng của giao dịch thành công (hoặc phương thức thanh toán của booking)
+// Chỉ cập nhật các khoản chưa được duyệt (cho_chi_tra, san_sang)
+func (q *Queries) UpsertSupplierPayouts(ctx context.Context, tyLeHoaHong pgtype.Numeric) ([]ChiTraNhaCungCap, error) {
+	rows, err := q.db.Query(ctx, upsertSupplierPayouts, tyLeHoaHong)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChiTraNhaCungCap
+	for rows.Next() {
+		var i ChiTraNhaCungCap
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.KhoiHanhID,
+			&i.TaiKhoanNganHangID,
+			&i.SoDatCho,
+			&i.TongDoanhThu,
+			&i.PhiCongThanhToan,
+			&i.TyLeHoaHong,
+			&i.PhiHoaHong,
+			&i.SoTienThucNhan,
+			&i.DonViTienTe,
+			&i.TrangThai,
+			&i.MaThamChieuChiTra,
+			&i.GhiChu,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.NgayChiTra,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,416 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: permission.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addRolePermissions = `-- name: AddRolePermissions :exec
+INSERT INTO vai_tro_quyen (vai_tro_ma, quyen_ma)
+SELECT $1, unnest($2::text[])
+ON CONFLICT DO NOTHING
+`
+
+type AddRolePermissionsParams struct {
+	VaiTroMa string   `json:"vai_tro_ma"`
+	Quyen    []string `json:"quyen"`
+}
+
+func (q *Queries) AddRolePermissions(ctx context.Context, arg AddRolePermissionsParams) error {
+	_, err := q.db.Exec(ctx, addRolePermissions, arg.VaiTroMa, arg.Quyen)
+	return err
+}
+
+const addUserRoles = `-- name: AddUserRoles :exec
+INSERT INTO nguoi_dung_vai_tro (nguoi_dung_id, vai_tro_ma, nguoi_gan)
+SELECT $1, unnest($2::text[]), $3
+ON CONFLICT DO NOTHING
+`
+
+type AddUserRolesParams struct {
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	VaiTro      []string    `json:"vai_tro"`
+	NguoiGan    pgtype.UUID `json:"nguoi_gan"`
+}
+
+func (q *Queries) AddUserRoles(ctx context.Context, arg AddUserRolesParams) error {
+	_, err := q.db.Exec(ctx, addUserRoles, arg.NguoiDungID, arg.VaiTro, arg.NguoiGan)
+	return err
+}
+
+const createRole = `-- name: CreateRole :one
+INSERT INTO vai_tro (ma, ten, mo_ta)
+VALUES ($1, $2, $3)
+RETURNING ma, ten, mo_ta, he_thong, ngay_tao, ngay_cap_nhat
+`
+
+type CreateRoleParams struct {
+	Ma   string  `json:"ma"`
+	Ten  string  `json:"ten"`
+	MoTa *string `json:"mo_ta"`
+}
+
+func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (VaiTro, error) {
+	row := q.db.QueryRow(ctx, createRole, arg.Ma, arg.Ten, arg.MoTa)
+	var i VaiTro
+	err := row.Scan(
+		&i.Ma,
+		&i.Ten,
+		&i.MoTa,
+		&i.HeThong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const deleteRole = `-- name: DeleteRole :execrows
+DELETE FROM vai_tro
+WHERE ma = $1 AND NOT he_thong
+`
+
+// Chỉ xóa được vai trò tự tạo, vai trò hệ thống giữ nguyên
+func (q *Queries) DeleteRole(ctx context.Context, ma string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRole, ma)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRolePermissions = `-- name: DeleteRolePermissions :exec
+DELETE FROM vai_tro_quyen
+WHERE vai_tro_ma = $1
+`
+
+func (q *Queries) DeleteRolePermissions(ctx context.Context, vaiTroMa string) error {
+	_, err := q.db.Exec(ctx, deleteRolePermissions, vaiTroMa)
+	return err
+}
+
+const deleteUserRoles = `-- name: DeleteUserRoles :exec
+DELETE FROM nguoi_dung_vai_tro
+WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteUserRoles(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserRoles, nguoiDungID)
+	return err
+}
+
+const getAllPermissions = `-- name: GetAllPermissions :many
+
+SELECT ma, nhom, mo_ta FROM quyen
+ORDER BY nhom, ma
+`
+
+// ===========================================
+// PHÂN QUYỀN: QUYỀN, VAI TRÒ, GÁN VAI TRÒ
+// ===========================================
+func (q *Queries) GetAllPermissions(ctx context.Context) ([]Quyen, error) {
+	rows, err := q.db.Query(ctx, getAllPermissions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Quyen
+	for rows.Next() {
+		var i Quyen
+		if err := rows.Scan(&i.Ma, &i.Nhom, &i.MoTa); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoleByCode = `-- name: GetRoleByCode :one
+SELECT ma, ten, mo_ta, he_thong, ngay_tao, ngay_cap_nhat FROM vai_tro
+WHERE ma = $1
+`
+
+func (q *Queries) GetRoleByCode(ctx context.Context, ma string) (VaiTro, error) {
+	row := q.db.QueryRow(ctx, getRoleByCode, ma)
+	var i VaiTro
+	err := row.Scan(
+		&i.Ma,
+		&i.Ten,
+		&i.MoTa,
+		&i.HeThong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const getRolePermissionCodes = `-- name: GetRolePermissionCodes :many
+SELECT quyen_ma FROM vai_tro_quyen
+WHERE vai_tro_ma = $1
+ORDER BY quyen_ma
+`
+
+func (q *Queries) GetRolePermissionCodes(ctx context.Context, vaiTroMa string) ([]string, error) {
+	rows, err := q.db.Query(ctx, getRolePermissionCodes, vaiTroMa)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var quyen_ma string
+		if err := rows.Scan(&quyen_ma); err != nil {
+			return nil, err
+		}
+		items = append(items, quyen_ma)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRolesWithPermissions = `-- name: GetRolesWithPermissions :many
+SELECT
+    v.ma, v.ten, v.mo_ta, v.he_thong, v.ngay_tao, v.ngay_cap_nhat,
+    COALESCE(
+        array_agg(vq.quyen_ma ORDER BY vq.quyen_ma) FILTER (WHERE vq.quyen_ma IS NOT NULL),
+        '{}'
+    )::text[] AS quyen,
+    (SELECT COUNT(*) FROM nguoi_dung_vai_tro nv WHERE nv.vai_tro_ma = v.ma)::int AS so_nguoi_dung
+FROM vai_tro v
+LEFT JOIN vai_tro_quyen vq ON vq.vai_tro_ma = v.ma
+GROUP BY v.ma
+ORDER BY v.he_thong DESC, v.ma
+`
+
+type GetRolesWithPermissionsRow struct {
+	Ma          string           `json:"ma"`
+	Ten         string           `json:"ten"`
+	MoTa        *string          `json:"mo_ta"`
+	HeThong     bool             `json:"he_thong"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
+	Quyen       []string         `json:"quyen"`
+	SoNguoiDung int32            `json:"so_nguoi_dung"`
+}
+
+func (q *Queries) GetRolesWithPermissions(ctx context.Context) ([]GetRolesWithPermissionsRow, error) {
+	rows, err := q.db.Query(ctx, getRolesWithPermissions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRolesWithPermissionsRow
+	for rows.Next() {
+		var i GetRolesWithPermissionsRow
+		if err := rows.Scan(
+			&i.Ma,
+			&i.Ten,
+			&i.MoTa,
+			&i.HeThong,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+			&i.Quyen,
+			&i.SoNguoiDung,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnknownPermissions = `-- name: GetUnknownPermissions :many
+SELECT p::text AS ma
+FROM unnest($1::text[]) AS p
+WHERE NOT EXISTS (SELECT 1 FROM quyen q WHERE q.ma = p)
+`
+
+// Các mã quyền trong danh sách không tồn tại trong danh mục
+func (q *Queries) GetUnknownPermissions(ctx context.Context, quyen []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, getUnknownPermissions, quyen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var ma string
+		if err := rows.Scan(&ma); err != nil {
+			return nil, err
+		}
+		items = append(items, ma)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnknownRoles = `-- name: GetUnknownRoles :many
+SELECT r::text AS ma
+FROM unnest($1::text[]) AS r
+WHERE NOT EXISTS (SELECT 1 FROM vai_tro v WHERE v.ma = r)
+`
+
+func (q *Queries) GetUnknownRoles(ctx context.Context, vaiTro []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, getUnknownRoles, vaiTro)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var ma string
+		if err := rows.Scan(&ma); err != nil {
+			return nil, err
+		}
+		items = append(items, ma)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserPermissions = `-- name: GetUserPermissions :many
+SELECT DISTINCT p.quyen_ma::text AS quyen_ma
+FROM (
+    SELECT vq.quyen_ma::text AS quyen_ma
+    FROM vai_tro_quyen vq
+    WHERE (
+            vq.vai_tro_ma = $1::text
+            AND NOT EXISTS (
+                SELECT 1 FROM nhan_vien_nha_cung_cap nv
+                WHERE nv.nguoi_dung_id = $2
+            )
+        )
+        OR vq.vai_tro_ma IN (
+            SELECT nv.vai_tro_ma FROM nguoi_dung_vai_tro nv
+            WHERE nv.nguoi_dung_id = $2
+        )
+    UNION
+    SELECT unnest(nv.quyen)::text AS quyen_ma
+    FROM nhan_vien_nha_cung_cap nv
+    WHERE nv.nguoi_dung_id = $2
+        AND nv.trang_thai = 'hoat_dong'
+) p
+ORDER BY quyen_ma
+`
+
+type GetUserPermissionsParams struct {
+	VaiTro      string      `json:"vai_tro"`
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+}
+
+// Quyền hiệu lực: vai trò hệ thống theo enum vai_tro của người dùng + các vai trò được gán thêm.
+// Nhân viên nhà cung cấp không nhận quyền của vai trò hệ thống mà dùng quyền được chủ sở hữu cấp
+func (q *Queries) GetUserPermissions(ctx context.Context, arg GetUserPermissionsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, getUserPermissions, arg.VaiTro, arg.NguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var quyen_ma string
+		if err := rows.Scan(&quyen_ma); err != nil {
+			return nil, err
+		}
+		items = append(items, quyen_ma)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserRoles = `-- name: GetUserRoles :many
+SELECT v.ma, v.ten, v.mo_ta, v.he_thong, v.ngay_tao, v.ngay_cap_nhat, nv.nguoi_gan, nv.ngay_tao AS ngay_gan
+FROM nguoi_dung_vai_tro nv
+JOIN vai_tro v ON v.ma = nv.vai_tro_ma
+WHERE nv.nguoi_dung_id = $1
+ORDER BY v.ma
+`
+
+type GetUserRolesRow struct {
+	Ma          string           `json:"ma"`
+	Ten         string           `json:"ten"`
+	MoTa        *string          `json:"mo_ta"`
+	HeThong     bool             `json:"he_thong"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
+	NguoiGan    pgtype.UUID      `json:"nguoi_gan"`
+	NgayGan     pgtype.Timestamp `json:"ngay_gan"`
+}
+
+// Các vai trò được gán thêm cho người dùng
+func (q *Queries) GetUserRoles(ctx context.Context, nguoiDungID pgtype.UUID) ([]GetUserRolesRow, error) {
+	rows, err := q.db.Query(ctx, getUserRoles, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserRolesRow
+	for rows.Next() {
+		var i GetUserRolesRow
+		if err := rows.Scan(
+			&i.Ma,
+			&i.Ten,
+			&i.MoTa,
+			&i.HeThong,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+			&i.NguoiGan,
+			&i.NgayGan,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateRole = `-- name: UpdateRole :one
+UPDATE vai_tro
+SET ten = COALESCE($1, ten),
+    mo_ta = COALESCE($2, mo_ta),
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE ma = $3
+RETURNING ma, ten, mo_ta, he_thong, ngay_tao, ngay_cap_nhat
+`
+
+type UpdateRoleParams struct {
+	Ten  *string `json:"ten"`
+	MoTa *string `json:"mo_ta"`
+	Ma   string  `json:"ma"`
+}
+
+func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) (VaiTro, error) {
+	row := q.db.QueryRow(ctx, updateRole, arg.Ten, arg.MoTa, arg.Ma)
+	var i VaiTro
+	err := row.Scan(
+		&i.Ma,
+		&i.Ten,
+		&i.MoTa,
+		&i.HeThong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
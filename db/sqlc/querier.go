@@ -11,6 +11,10 @@ import (
 )
 
 type Querier interface {
+	// Đặt mật khẩu do nhân viên chọn và kích hoạt tài khoản khi nhận lời mời
+	ActivateInvitedUser(ctx context.Context, arg ActivateInvitedUserParams) error
+	ActivateSupplierStaff(ctx context.Context, id int32) error
+	ActivateTwoFactor(ctx context.Context, nguoiDungID pgtype.UUID) error
 	AddHinhAnhTour(ctx context.Context, arg AddHinhAnhTourParams) (AnhTour, error)
 	// ===========================================
 	// BƯỚC 3: NHẬP THÔNG TIN HÀNH KHÁCH
@@ -19,8 +23,10 @@ type Querier interface {
 	AddPassenger(ctx context.Context, arg AddPassengerParams) (HanhKhach, error)
 	// Thêm nhiều hành khách cùng lúc
 	AddPassengers(ctx context.Context, arg []AddPassengersParams) (int64, error)
+	AddRolePermissions(ctx context.Context, arg AddRolePermissionsParams) error
 	AddTourDestination(ctx context.Context, arg AddTourDestinationParams) error
 	AddTourImage(ctx context.Context, arg AddTourImageParams) (AnhTour, error)
+	AddUserRoles(ctx context.Context, arg AddUserRolesParams) error
 	//Trạng thái Đặt chỗ
 	AdminChartBookingStatusStats(ctx context.Context, arg AdminChartBookingStatusStatsParams) ([]AdminChartBookingStatusStatsRow, error)
 	//Cơ cấu Doanh thu theo Danh mục
@@ -30,18 +36,39 @@ type Querier interface {
 	//Top 5 Nhà cung cấp xuất sắc
 	AdminChartTopSuppliers(ctx context.Context, arg AdminChartTopSuppliersParams) ([]AdminChartTopSuppliersRow, error)
 	AdminCustomerGrowthMonthlyReport(ctx context.Context, nam int32) ([]AdminCustomerGrowthMonthlyReportRow, error)
+	// Giữ lại bản ghi nguoi_dung (dat_cho cần khóa ngoại) nhưng xóa toàn bộ thông tin định danh
+	AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) error
+	// ===========================================
+	// ẨN DANH / XÓA DỮ LIỆU KHI XÓA TÀI KHOẢN
+	// ===========================================
+	AnonymizeUserPassengers(ctx context.Context, nguoiDungID pgtype.UUID) error
+	// Giao dịch vẫn liên kết với booking qua dat_cho_id, chỉ bỏ liên kết trực tiếp tới người dùng
+	AnonymizeUserTransactions(ctx context.Context, nguoiDungID pgtype.UUID) error
 	ApproveComment(ctx context.Context, id int32) (BinhLuanBlog, error)
+	// Duyệt chi trả hàng loạt: san_sang/that_bai -> dang_xu_ly
+	// Chỉ duyệt các nhà cung cấp đã có tài khoản ngân hàng mặc định (lưu lại tài khoản tại thời điểm duyệt)
+	ApprovePayouts(ctx context.Context, arg ApprovePayoutsParams) ([]ChiTraNhaCungCap, error)
 	// phê duyệt nhà cung cấp
 	ApproveSupplier(ctx context.Context, id pgtype.UUID) (NguoiDung, error)
+	AttributeBookingToPartner(ctx context.Context, arg AttributeBookingToPartnerParams) error
 	// Tự động hoàn thành các booking sau khi tour kết thúc (chạy bằng cron job)
 	AutoCompleteBookings(ctx context.Context) error
 	// cập nhật trạng thái nhà cung cấp hàng loạt
 	BulkUpdateSupplierStatus(ctx context.Context, dollar_1 []int32) error
+	// Xem trước số tiền hoàn lại (cùng chính sách với cancel_booking)
 	CalculateRefundAmount(ctx context.Context, bookingID int32) (CalculateRefundAmountRow, error)
 	// Tính giá tour cho khách hàng xem trước khi đặt
 	CalculateTourPrice(ctx context.Context, arg CalculateTourPriceParams) (CalculateTourPriceRow, error)
+	CancelAccountDeletion(ctx context.Context, nguoiDungID pgtype.UUID) (YeuCauXoaTaiKhoan, error)
 	CancelBooking(ctx context.Context, bookingID int32) (CancelBookingRow, error)
 	CancelDeparture(ctx context.Context, id int32) (KhoiHanhTour, error)
+	// Hủy các giao dịch thanh toán đang chờ của booking (vd: tổng tiền booking thay đổi)
+	CancelPendingBookingTransactions(ctx context.Context, datChoID *int32) (int64, error)
+	CancelPendingTourRevisions(ctx context.Context, tourID int32) (int64, error)
+	// Người dùng tự hủy giữ chỗ đang hiệu lực và trả lại chỗ cho khởi hành
+	CancelSeatHold(ctx context.Context, arg CancelSeatHoldParams) (CancelSeatHoldRow, error)
+	// Hủy các khoản chi trả chưa duyệt khi khởi hành bị hủy hoặc không còn booking đã thanh toán
+	CancelStaleSupplierPayouts(ctx context.Context) ([]ChiTraNhaCungCap, error)
 	ChangePassword(ctx context.Context, arg ChangePasswordParams) error
 	// Kiểm tra booking có thể đánh giá: trang_thai_dat_cho = 'da_thanh_toan' VÀ trang_thai_khoi_hanh = 'hoan_thanh'
 	CheckBookingCompletedAndNotReviewed(ctx context.Context, arg CheckBookingCompletedAndNotReviewedParams) (CheckBookingCompletedAndNotReviewedRow, error)
@@ -49,39 +76,84 @@ type Querier interface {
 	CheckDepartureAvailability(ctx context.Context, arg CheckDepartureAvailabilityParams) (CheckDepartureAvailabilityRow, error)
 	// Kiểm tra đã có review cho booking này chưa
 	CheckReviewExists(ctx context.Context, arg CheckReviewExistsParams) (bool, error)
+	// Admin duyệt khoản hoàn tiền đang chờ; chỉ một người duyệt được (nguoi_duyet_id còn trống)
+	ClaimRefundForApproval(ctx context.Context, arg ClaimRefundForApprovalParams) (LichSuGiaoDich, error)
+	// Thử lại khoản hoàn tiền thất bại. Khoản đã duyệt nhưng bị treo quá 10 phút (vd: server dừng giữa chừng,
+	// hoặc cổng đã hoàn tiền nhưng cập nhật trạng thái lỗi) có thể đã được hoàn phía cổng,
+	// nên chỉ được nhận lại để đối soát thủ công (thu_cong = true), không gọi lại API hoàn tiền
+	ClaimRefundForRetry(ctx context.Context, arg ClaimRefundForRetryParams) (LichSuGiaoDich, error)
+	// Bỏ đánh dấu mặc định cho tất cả tài khoản của nhà cung cấp
+	ClearDefaultBankAccount(ctx context.Context, nhaCungCapID pgtype.UUID) error
+	CompleteAccountDeletion(ctx context.Context, id int32) error
 	// ===========================================
 	// BƯỚC 6: HOÀN THÀNH TOUR
 	// ===========================================
 	// Đánh dấu booking hoàn thành (sau khi tour kết thúc)
 	CompleteBooking(ctx context.Context, id int32) (DatCho, error)
+	// Ghi nhận hoàn tiền thành công (da_hoan_tien hoặc hoan_mot_phan)
+	CompleteRefund(ctx context.Context, arg CompleteRefundParams) (LichSuGiaoDich, error)
 	// ===========================================
 	// BƯỚC 4: XÁC NHẬN ĐẶT CHỖ
 	// ===========================================
 	// Admin/Hệ thống xác nhận đặt chỗ
 	ConfirmBooking(ctx context.Context, id int32) (DatCho, error)
+	// Xác nhận giao dịch thanh toán thành công và chuyển booking sang da_thanh_toan trong cùng một câu lệnh
+	// Idempotent: giao dịch đã thanh_cong sẽ không trả về dòng nào (pgx.ErrNoRows)
+	ConfirmTransactionPayment(ctx context.Context, arg ConfirmTransactionPaymentParams) (ConfirmTransactionPaymentRow, error)
+	// Ghi nhận bước thời gian của mã TOTP vừa dùng; 0 dòng nghĩa là mã đã được dùng trước đó
+	ConsumeTwoFactorStep(ctx context.Context, arg ConsumeTwoFactorStepParams) (int64, error)
+	// Booking chưa hủy của các khởi hành chưa kết thúc; còn booking này thì chưa thể xóa tài khoản
+	CountActiveBookingsByUser(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error)
 	// Đếm tổng số booking cho admin với filter
 	CountAllBookingsForAdmin(ctx context.Context, arg CountAllBookingsForAdminParams) (int32, error)
 	CountAllTours(ctx context.Context) (int64, error)
 	// Đếm tổng số giao dịch
 	CountAllTransactions(ctx context.Context) (int64, error)
+	CountAuditLogs(ctx context.Context, arg CountAuditLogsParams) (int64, error)
+	CountBankStatementLines(ctx context.Context, trangThai NullTrangThaiDoiSoat) (int64, error)
 	CountBlogComments(ctx context.Context, blogID int32) (int64, error)
 	CountBlogs(ctx context.Context, arg CountBlogsParams) (int64, error)
 	// Đếm tổng số đặt chỗ của người dùng (có filter)
 	CountBookingsByUser(ctx context.Context, arg CountBookingsByUserParams) (int32, error)
 	CountContacts(ctx context.Context) (int64, error)
 	CountContactsByStatus(ctx context.Context, trangThai *string) (int64, error)
+	CountLoginHistoryByUser(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error)
+	CountMyWaitlist(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error)
+	CountOAuthAccountsByUser(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error)
+	CountPartners(ctx context.Context) (int32, error)
+	// Đếm tổng số khoản chi trả theo filter
+	CountPayouts(ctx context.Context, arg CountPayoutsParams) (int64, error)
 	CountPublishedBlogs(ctx context.Context) (int64, error)
+	CountRefundTransactions(ctx context.Context, trangThai NullTrangThaiThanhToan) (int64, error)
+	CountRemainingRecoveryCodes(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error)
 	CountSearchTours(ctx context.Context, arg CountSearchToursParams) (int64, error)
 	// Đếm tổng số booking theo các filter nâng cao
 	CountSupplierBookingsByStatusAdvanced(ctx context.Context, arg CountSupplierBookingsByStatusAdvancedParams) (int32, error)
+	// Đếm số khoản chi trả của nhà cung cấp
+	CountSupplierPayouts(ctx context.Context, arg CountSupplierPayoutsParams) (int64, error)
 	CountSuppliers(ctx context.Context) (int32, error)
 	// đếm số lượng nhà cung cấp theo trạng thái
 	CountSuppliersByStatus(ctx context.Context, email string) ([]CountSuppliersByStatusRow, error)
 	CountToursBySupplier(ctx context.Context, nhaCungCapID pgtype.UUID) (int64, error)
 	// Đếm số thông báo chưa đọc
 	CountUnreadNotificationsByUser(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error)
+	// Số lượt và số khách đang chờ của một khởi hành
+	CountWaitlistByDeparture(ctx context.Context, khoiHanhID int32) (CountWaitlistByDepartureRow, error)
+	// ===========================================
+	// YÊU CẦU XÓA TÀI KHOẢN
+	// ===========================================
+	CreateAccountDeletionRequest(ctx context.Context, arg CreateAccountDeletionRequestParams) (YeuCauXoaTaiKhoan, error)
 	// ==================== ACTIVITY QUERIES ====================
 	CreateActivity(ctx context.Context, arg CreateActivityParams) (HoatDongTrongNgay, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (KhoaApi, error)
+	// ===========================================
+	// NHẬT KÝ KIỂM TOÁN
+	// ===========================================
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error
+	// Thêm tài khoản ngân hàng cho nhà cung cấp
+	CreateBankAccount(ctx context.Context, arg CreateBankAccountParams) (TaiKhoanNganHang, error)
+	// Ghi nhận một dòng sao kê; dòng đã tải lên trước đó (trùng mã giao dịch ngân hàng) được bỏ qua (pgx.ErrNoRows)
+	CreateBankStatementLine(ctx context.Context, arg CreateBankStatementLineParams) (SaoKeNganHang, error)
 	// ===========================================
 	// BLOG QUERIES
 	// ===========================================
@@ -96,6 +168,13 @@ type Querier interface {
 	CreateBlogComment(ctx context.Context, arg CreateBlogCommentParams) (BinhLuanBlog, error)
 	// Tạo đặt chỗ mới (tự động tính tổng tiền)
 	CreateBooking(ctx context.Context, arg CreateBookingParams) (CreateBookingRow, error)
+	// Tạo đặt chỗ bằng cách tiêu thụ mã giữ chỗ, trả về ID booking
+	CreateBookingFromHold(ctx context.Context, arg CreateBookingFromHoldParams) (int32, error)
+	// ===========================================
+	// CHÍNH SÁCH HỦY TOUR (CANCELLATION POLICY)
+	// ===========================================
+	CreateCancellationPolicy(ctx context.Context, arg CreateCancellationPolicyParams) (ChinhSachHuy, error)
+	CreateCancellationPolicyTier(ctx context.Context, arg CreateCancellationPolicyTierParams) (BacChinhSachHuy, error)
 	CreateCategoryTour(ctx context.Context, arg CreateCategoryTourParams) (DanhMucTour, error)
 	CreateChatHistory(ctx context.Context, arg CreateChatHistoryParams) (LichSuChat, error)
 	CreateContact(ctx context.Context, arg CreateContactParams) (LienHe, error)
@@ -114,6 +193,12 @@ type Querier interface {
 	CreateGroupConfig(ctx context.Context, arg CreateGroupConfigParams) (CauHinhNhomTour, error)
 	// ==================== ITINERARY QUERIES ====================
 	CreateItinerary(ctx context.Context, arg CreateItineraryParams) (LichTrinh, error)
+	CreateJwtSigningKey(ctx context.Context, arg CreateJwtSigningKeyParams) (KhoaKyJwt, error)
+	// ===========================================
+	// LỊCH SỬ ĐĂNG NHẬP
+	// ===========================================
+	// Ghi nhận một lần đăng nhập (quốc gia được cập nhật sau khi tra IP)
+	CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) (LichSuDangNhap, error)
 	// ===========================================
 	// THÔNG BÁO (NOTIFICATIONS)
 	// ===========================================
@@ -122,14 +207,32 @@ type Querier interface {
 	// Tạo thông báo khi admin phản hồi liên hệ
 	// Function này sẽ được gọi tự động khi có phản hồi
 	CreateNotificationForContactResponse(ctx context.Context, arg CreateNotificationForContactResponseParams) (ThongBao, error)
+	CreateOAuthAccount(ctx context.Context, arg CreateOAuthAccountParams) (TaiKhoanOauth, error)
+	// ===========================================
+	// ĐỐI TÁC (PARTNER) & KHÓA API
+	// ===========================================
+	CreatePartner(ctx context.Context, arg CreatePartnerParams) (DoiTac, error)
 	CreatePasswordResetOTP(ctx context.Context, arg CreatePasswordResetOTPParams) (OtpDatLaiMatKhau, error)
+	CreateRecoveryCodes(ctx context.Context, arg []CreateRecoveryCodesParams) (int64, error)
+	// Tạo yêu cầu hoàn tiền (chờ admin duyệt) cho giao dịch thanh toán gốc
+	// Giao dịch gốc đã có khoản hoàn tiền còn hiệu lực thì bỏ qua (pgx.ErrNoRows)
+	CreateRefundTransaction(ctx context.Context, arg CreateRefundTransactionParams) (LichSuGiaoDich, error)
 	// Tạo đánh giá tour mới (chỉ khi booking đã hoàn thành)
 	CreateReview(ctx context.Context, arg CreateReviewParams) (DanhGium, error)
+	CreateRole(ctx context.Context, arg CreateRoleParams) (VaiTro, error)
+	// ===========================================
+	// GIỮ CHỖ CÓ THỜI HẠN (SEAT HOLD)
+	// ===========================================
+	// Giữ chỗ (tăng so_cho_da_dat qua hold_seat) và ghi nhận bản ghi giữ chỗ có thời hạn
+	CreateSeatHold(ctx context.Context, arg CreateSeatHoldParams) (GiuCho, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (PhienDangNhap, error)
 	CreateSupplier(ctx context.Context, arg CreateSupplierParams) (NhaCungCap, error)
+	CreateSupplierStaff(ctx context.Context, arg CreateSupplierStaffParams) (NhanVienNhaCungCap, error)
 	// ==================== TOUR CRUD OPERATIONS ====================
 	CreateTour(ctx context.Context, arg CreateTourParams) (Tour, error)
 	// Tạo hoặc cập nhật embedding cho tour
 	CreateTourEmbedding(ctx context.Context, arg CreateTourEmbeddingParams) (TourEmbedding, error)
+	CreateTourRevision(ctx context.Context, arg CreateTourRevisionParams) (PhienBanTour, error)
 	// ===========================================
 	// QUERIES CHO AI GỢI Ý TOUR
 	// ===========================================
@@ -141,14 +244,21 @@ type Querier interface {
 	// Tạo giao dịch thanh toán mới
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (LichSuGiaoDich, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (NguoiDung, error)
+	DeactivateSupplierStaff(ctx context.Context, arg DeactivateSupplierStaffParams) (NhanVienNhaCungCap, error)
+	DeactivateUser(ctx context.Context, id pgtype.UUID) error
 	DecrementBlogLikes(ctx context.Context, id int32) error
 	DeleteActivitiesByItinerary(ctx context.Context, lichTrinhID int32) error
 	DeleteActivity(ctx context.Context, arg DeleteActivityParams) error
+	// Xóa tài khoản ngân hàng của nhà cung cấp
+	DeleteBankAccount(ctx context.Context, arg DeleteBankAccountParams) (TaiKhoanNganHang, error)
 	DeleteBlog(ctx context.Context, id int32) error
 	// Xóa đặt chỗ
 	DeleteBooking(ctx context.Context, id int32) error
 	// Xóa nhiều đặt chỗ
 	DeleteBookings(ctx context.Context, ids []int32) error
+	// Tour/khởi hành đang dùng chính sách sẽ quay về chính sách mặc định (ON DELETE SET NULL)
+	DeleteCancellationPolicy(ctx context.Context, arg DeleteCancellationPolicyParams) (int64, error)
+	DeleteCancellationPolicyTiers(ctx context.Context, chinhSachHuyID int32) error
 	DeleteChatHistoryBySessionID(ctx context.Context, maPhien string) error
 	DeleteChatHistoryByUserID(ctx context.Context, nguoiDungID pgtype.UUID) error
 	DeleteComment(ctx context.Context, id int32) error
@@ -156,6 +266,7 @@ type Querier interface {
 	DeleteContactResponse(ctx context.Context, id int32) error
 	DeleteDeparture(ctx context.Context, id int32) error
 	DeleteDiscountTour(ctx context.Context, arg DeleteDiscountTourParams) error
+	DeleteExpiredJwtSigningKeys(ctx context.Context) (int64, error)
 	DeleteExpiredOTPs(ctx context.Context) error
 	DeleteFavoriteTour(ctx context.Context, arg DeleteFavoriteTourParams) error
 	DeleteGroupConfig(ctx context.Context, tourID int32) error
@@ -164,16 +275,62 @@ type Querier interface {
 	DeleteItinerary(ctx context.Context, arg DeleteItineraryParams) error
 	// Xóa thông báo
 	DeleteNotification(ctx context.Context, id int32) error
+	DeleteOAuthAccount(ctx context.Context, arg DeleteOAuthAccountParams) (int64, error)
 	// Xóa hành khách
 	DeletePassenger(ctx context.Context, id int32) error
+	// Xóa toàn bộ hành khách của booking (trước khi ghi lại danh sách mới)
+	DeletePassengersByBooking(ctx context.Context, datChoID int32) error
+	DeleteRecoveryCodes(ctx context.Context, nguoiDungID pgtype.UUID) error
+	// Chỉ xóa được vai trò tự tạo, vai trò hệ thống giữ nguyên
+	DeleteRole(ctx context.Context, ma string) (int64, error)
+	DeleteRolePermissions(ctx context.Context, vaiTroMa string) error
 	DeleteSupplier(ctx context.Context, id pgtype.UUID) error
 	DeleteTour(ctx context.Context, id int32) error
 	DeleteTourDestination(ctx context.Context, arg DeleteTourDestinationParams) error
+	DeleteTourDestinationsByTour(ctx context.Context, tourID int32) error
 	DeleteTourImage(ctx context.Context, arg DeleteTourImageParams) error
-	// Phản hồi đánh giá
-	FeedbackReview(ctx context.Context, arg FeedbackReviewParams) (int32, error)
+	DeleteTourImagesByTour(ctx context.Context, tourID int32) error
+	DeleteTwoFactor(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserBlogComments(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserContacts(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserFavorites(ctx context.Context, nguoiDungID pgtype.UUID) error
+	// Giữ chỗ đang hiệu lực để tiến trình giữ chỗ tự trả lại khi hết hạn
+	DeleteUserFinishedSeatHolds(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserLoginHistory(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserNotifications(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserOAuthAccounts(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserPasswordResetOtps(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserPreferences(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserRecoveryCodes(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserReviews(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserRoles(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserSessions(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserTwoFactor(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserViewHistory(ctx context.Context, nguoiDungID pgtype.UUID) error
+	DeleteUserWaitlistEntries(ctx context.Context, nguoiDungID pgtype.UUID) error
+	// Xoay khóa khẩn cấp: gỡ ngay mọi khóa khác khỏi JWKS, token do chúng ký mất hiệu lực
+	ExpireJwtSigningKeysExcept(ctx context.Context, kid string) (int64, error)
+	ExportUserBookings(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserBookingsRow, error)
+	ExportUserChatHistory(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserChatHistoryRow, error)
+	ExportUserFavorites(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserFavoritesRow, error)
+	ExportUserPassengers(ctx context.Context, nguoiDungID pgtype.UUID) ([]HanhKhach, error)
+	// ===========================================
+	// XUẤT DỮ LIỆU CÁ NHÂN
+	// ===========================================
+	// Hồ sơ người dùng, không kèm mật khẩu đã mã hóa
+	ExportUserProfile(ctx context.Context, id pgtype.UUID) (ExportUserProfileRow, error)
+	ExportUserReviews(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserReviewsRow, error)
+	ExportUserViewHistory(ctx context.Context, nguoiDungID pgtype.UUID) ([]ExportUserViewHistoryRow, error)
+	// Đánh dấu giao dịch thất bại nếu chưa thành công (không ghi đè giao dịch đã thanh_cong)
+	FailPendingTransaction(ctx context.Context, arg FailPendingTransactionParams) (LichSuGiaoDich, error)
+	// Ghi nhận cổng thanh toán từ chối/lỗi khi hoàn tiền
+	FailRefund(ctx context.Context, arg FailRefundParams) (LichSuGiaoDich, error)
 	FilterTours(ctx context.Context, arg FilterToursParams) ([]FilterToursRow, error)
+	// Tìm giao dịch chuyển khoản đang chờ có nội dung chuyển khoản nằm trong nội dung sao kê (đã chuẩn hóa)
+	FindPendingBankTransferByContent(ctx context.Context, noiDung string) (LichSuGiaoDich, error)
 	ForgotPassword(ctx context.Context, arg ForgotPasswordParams) error
+	// Các thiết bị đang đăng nhập của người dùng
+	GetActiveSessionsByUser(ctx context.Context, nguoiDungID pgtype.UUID) ([]GetActiveSessionsByUserRow, error)
 	GetActiveSuppliers(ctx context.Context) ([]GetActiveSuppliersRow, error)
 	GetActivitiesByItinerary(ctx context.Context, lichTrinhID int32) ([]HoatDongTrongNgay, error)
 	GetActivityByID(ctx context.Context, id int32) (HoatDongTrongNgay, error)
@@ -187,6 +344,10 @@ type Querier interface {
 	GetAllContacts(ctx context.Context, arg GetAllContactsParams) ([]GetAllContactsRow, error)
 	GetAllDepartures(ctx context.Context, arg GetAllDeparturesParams) ([]GetAllDeparturesRow, error)
 	// ===========================================
+	// PHÂN QUYỀN: QUYỀN, VAI TRÒ, GÁN VAI TRÒ
+	// ===========================================
+	GetAllPermissions(ctx context.Context) ([]Quyen, error)
+	// ===========================================
 	// QUẢN LÝ HOÀN TIỀN (REFUND MANAGEMENT)
 	// ===========================================
 	// Lấy tất cả refund cho admin (tất cả booking đã hủy với thông tin refund)
@@ -199,11 +360,22 @@ type Querier interface {
 	// ==================== PAYMENT/TRANSACTION QUERIES ====================
 	// Lấy tất cả giao dịch với thông tin đầy đủ (dành cho Admin)
 	GetAllTransactions(ctx context.Context, arg GetAllTransactionsParams) ([]GetAllTransactionsRow, error)
+	GetApiKeyDailyUsage(ctx context.Context, arg GetApiKeyDailyUsageParams) ([]GetApiKeyDailyUsageRow, error)
+	// Tra khóa API theo mã băm khi xác thực request; chỉ khóa chưa thu hồi, chưa hết hạn của đối tác đang hoạt động
+	GetApiKeyForAuth(ctx context.Context, maBam string) (GetApiKeyForAuthRow, error)
+	// Không trả về ma_bam
+	GetApiKeysByPartner(ctx context.Context, doiTacID int32) ([]GetApiKeysByPartnerRow, error)
+	// Gợi ý tìm kiếm hỗn hợp (tour, điểm đến, danh mục) theo tiền tố và độ giống trigram trên văn bản đã bỏ dấu.
+	// Điểm = max(similarity, word_similarity) + thưởng khi khớp đầu chuỗi (0.5) hoặc đầu một từ (0.3);
+	// "Ha Lng" vẫn khớp "Hạ Long" nhờ word_similarity (toán tử <% dùng được index gin_trgm_ops)
+	GetAutocompleteSuggestions(ctx context.Context, arg GetAutocompleteSuggestionsParams) ([]GetAutocompleteSuggestionsRow, error)
 	// ===========================================
 	// BƯỚC 1: CHỌN TOUR & NGÀY KHỞI HÀNH
 	// ===========================================
 	// Lấy danh sách ngày khởi hành còn chỗ của một tour
 	GetAvailableDepartures(ctx context.Context, tourID int32) ([]GetAvailableDeparturesRow, error)
+	// Danh sách dòng sao kê (Admin), lọc theo trạng thái đối soát
+	GetBankStatementLines(ctx context.Context, arg GetBankStatementLinesParams) ([]GetBankStatementLinesRow, error)
 	GetBlogAIHistory(ctx context.Context, blogID *int32) ([]LichSuAiBlog, error)
 	GetBlogByID(ctx context.Context, id int32) (GetBlogByIDRow, error)
 	GetBlogBySlug(ctx context.Context, slug string) (GetBlogBySlugRow, error)
@@ -226,6 +398,8 @@ type Querier interface {
 	// Lấy danh sách đặt chỗ của người dùng
 	GetBookingsByUser(ctx context.Context, arg GetBookingsByUserParams) ([]GetBookingsByUserRow, error)
 	GetBookingsByUserId(ctx context.Context, nguoiDungID pgtype.UUID) ([]GetBookingsByUserIdRow, error)
+	// Danh sách chính sách hủy của nhà cung cấp kèm các mốc hoàn tiền và số tour/khởi hành đang dùng
+	GetCancellationPoliciesBySupplier(ctx context.Context, nhaCungCapID pgtype.UUID) ([]GetCancellationPoliciesBySupplierRow, error)
 	// Lấy danh sách booking đã hủy
 	GetCancelledBookings(ctx context.Context, arg GetCancelledBookingsParams) ([]GetCancelledBookingsRow, error)
 	GetChatHistoryBySessionID(ctx context.Context, arg GetChatHistoryBySessionIDParams) ([]LichSuChat, error)
@@ -246,15 +420,23 @@ type Querier interface {
 	GetDepartureByID(ctx context.Context, id int32) (GetDepartureByIDRow, error)
 	// Lấy thông tin chi tiết một ngày khởi hành
 	GetDepartureById(ctx context.Context, id int32) (GetDepartureByIdRow, error)
+	// Quy tắc nhóm khách của tour theo khởi hành (mặc định khi tour chưa cấu hình)
+	GetDepartureGroupRules(ctx context.Context, id int32) (GetDepartureGroupRulesRow, error)
 	GetDeparturesByDateRange(ctx context.Context, arg GetDeparturesByDateRangeParams) ([]GetDeparturesByDateRangeRow, error)
 	GetDeparturesByStatus(ctx context.Context, arg GetDeparturesByStatusParams) ([]GetDeparturesByStatusRow, error)
 	// lấy danh sách lịch khởi hành của một tour
 	GetDeparturesByTour(ctx context.Context, tourID int32) ([]GetDeparturesByTourRow, error)
+	// Các khởi hành còn chỗ trống đủ cho ít nhất một lượt đang chờ
+	GetDeparturesWithPromotableWaitlist(ctx context.Context) ([]int32, error)
 	// Lấy thông tin chi tiết điểm đến theo ID
 	GetDestinationByID(ctx context.Context, id int32) (DiemDen, error)
+	GetDestinationsNearPoint(ctx context.Context, arg GetDestinationsNearPointParams) ([]GetDestinationsNearPointRow, error)
 	// Lấy danh sách đánh giá chi tiết với các bộ lọc theo sao và tour
 	GetDetailedSupplierReviews(ctx context.Context, arg GetDetailedSupplierReviewsParams) ([]GetDetailedSupplierReviewsRow, error)
+	GetDiscountTourByID(ctx context.Context, id int32) (GiamGiaTour, error)
 	GetDiscountsByTourID(ctx context.Context, tourID int32) ([]GiamGiaTour, error)
+	// Các yêu cầu đã hết thời gian ân hạn
+	GetDueAccountDeletions(ctx context.Context, limit int32) ([]YeuCauXoaTaiKhoan, error)
 	GetFavoriteTours(ctx context.Context, nguoiDungID pgtype.UUID) ([]TourYeuThich, error)
 	GetFeaturedBlogs(ctx context.Context, limit int32) ([]GetFeaturedBlogsRow, error)
 	GetFeaturedTours(ctx context.Context, limit int32) ([]GetFeaturedToursRow, error)
@@ -266,6 +448,10 @@ type Querier interface {
 	GetHinhAnhTourByID(ctx context.Context, id int32) (AnhTour, error)
 	GetItinerariesByTour(ctx context.Context, tourID int32) ([]LichTrinh, error)
 	GetItineraryByID(ctx context.Context, id int32) (LichTrinh, error)
+	GetLatestAccountDeletion(ctx context.Context, nguoiDungID pgtype.UUID) (YeuCauXoaTaiKhoan, error)
+	// Số lần đăng nhập thành công trước đó đã xác định được quốc gia, và số lần từ quốc gia đang xét
+	GetLoginCountryHistory(ctx context.Context, arg GetLoginCountryHistoryParams) (GetLoginCountryHistoryRow, error)
+	GetLoginHistoryByUser(ctx context.Context, arg GetLoginHistoryByUserParams) ([]LichSuDangNhap, error)
 	// =====================
 	// 12. COMPREHENSIVE REPORTS
 	// =====================
@@ -277,22 +463,48 @@ type Querier interface {
 	GetMostViewedTours(ctx context.Context, limit int32) ([]GetMostViewedToursRow, error)
 	// lấy danh sách tour của nhà cung cấp
 	GetMyTours(ctx context.Context, arg GetMyToursParams) ([]Tour, error)
+	// Danh sách chờ của người dùng kèm vị trí trong hàng đợi và giữ chỗ được mời (nếu có)
+	GetMyWaitlist(ctx context.Context, arg GetMyWaitlistParams) ([]GetMyWaitlistRow, error)
 	// Lấy thông báo theo ID
 	GetNotificationByID(ctx context.Context, id int32) (ThongBao, error)
 	// Lấy thông báo của người dùng (có phân trang)
 	GetNotificationsByUser(ctx context.Context, arg GetNotificationsByUserParams) ([]ThongBao, error)
+	// ===========================================
+	// TÀI KHOẢN MẠNG XÃ HỘI (OAUTH)
+	// ===========================================
+	GetOAuthAccount(ctx context.Context, arg GetOAuthAccountParams) (TaiKhoanOauth, error)
+	GetOAuthAccountsByUser(ctx context.Context, nguoiDungID pgtype.UUID) ([]GetOAuthAccountsByUserRow, error)
+	// ===========================================
+	// THỰC HIỆN HOÀN TIỀN (REFUND EXECUTION)
+	// ===========================================
+	// Lấy giao dịch thanh toán thành công gần nhất của booking (giao dịch gốc để hoàn tiền)
+	GetPaidTransactionByBooking(ctx context.Context, datChoID *int32) (LichSuGiaoDich, error)
+	GetPartnerByID(ctx context.Context, id int32) (DoiTac, error)
+	// Doanh thu và hoa hồng của đối tác từ các booking đã thanh toán / hoàn thành trong khoảng thời gian
+	GetPartnerCommissionReport(ctx context.Context, arg GetPartnerCommissionReportParams) ([]GetPartnerCommissionReportRow, error)
 	// Lấy danh sách hành khách của một booking
 	GetPassengersByBooking(ctx context.Context, datChoID int32) ([]HanhKhach, error)
 	GetPasswordResetOTP(ctx context.Context, arg GetPasswordResetOTPParams) (OtpDatLaiMatKhau, error)
 	// Lấy danh sách cổng thanh toán đang hoạt động
 	GetPaymentGateways(ctx context.Context) ([]CongThanhToan, error)
+	// Lấy danh sách khoản chi trả (dành cho Admin) với filter trạng thái và nhà cung cấp
+	GetPayouts(ctx context.Context, arg GetPayoutsParams) ([]GetPayoutsRow, error)
+	GetPendingAccountDeletion(ctx context.Context, nguoiDungID pgtype.UUID) (YeuCauXoaTaiKhoan, error)
+	// ===========================================
+	// CHUYỂN KHOẢN NGÂN HÀNG & ĐỐI SOÁT SAO KÊ
+	// ===========================================
+	// Lấy giao dịch chuyển khoản đang chờ gần nhất của booking (để tái sử dụng nội dung chuyển khoản)
+	GetPendingBankTransferByBooking(ctx context.Context, datChoID *int32) (LichSuGiaoDich, error)
 	// Lấy danh sách booking chờ xác nhận (dành cho Admin/NCC)
 	GetPendingBookings(ctx context.Context, arg GetPendingBookingsParams) ([]GetPendingBookingsRow, error)
 	GetPendingComments(ctx context.Context, arg GetPendingCommentsParams) ([]GetPendingCommentsRow, error)
+	GetPendingTourRevision(ctx context.Context, tourID int32) (PhienBanTour, error)
 	// Lấy các điểm đến phổ biến nhất (được nhiều tour sử dụng nhất)
 	GetPopularDestinations(ctx context.Context, limit int32) ([]GetPopularDestinationsRow, error)
 	GetProvinceByCountry(ctx context.Context, quocGia *string) ([]GetProvinceByCountryRow, error)
 	GetPublishedBlogs(ctx context.Context, arg GetPublishedBlogsParams) ([]GetPublishedBlogsRow, error)
+	// Các khóa còn trong JWKS (chưa hết hạn), cũ trước
+	GetPublishedJwtSigningKeys(ctx context.Context) ([]KhoaKyJwt, error)
 	// Báo cáo theo quý
 	GetQuarterlyReport(ctx context.Context) ([]GetQuarterlyReportRow, error)
 	// Booking gần đây
@@ -306,18 +518,43 @@ type Querier interface {
 	GetRecommendedToursByViewHistory(ctx context.Context, arg GetRecommendedToursByViewHistoryParams) ([]GetRecommendedToursByViewHistoryRow, error)
 	// Thống kê refund cho admin
 	GetRefundStats(ctx context.Context, arg GetRefundStatsParams) (GetRefundStatsRow, error)
+	// Lấy khoản hoàn tiền kèm thông tin giao dịch thanh toán gốc
+	GetRefundTransactionByID(ctx context.Context, id int32) (GetRefundTransactionByIDRow, error)
+	// Danh sách khoản hoàn tiền (Admin), lọc theo trạng thái
+	GetRefundTransactions(ctx context.Context, arg GetRefundTransactionsParams) ([]GetRefundTransactionsRow, error)
 	GetRelatedBlogs(ctx context.Context, arg GetRelatedBlogsParams) ([]GetRelatedBlogsRow, error)
 	// Doanh thu theo năm và tháng
 	GetRevenueByDay(ctx context.Context, arg GetRevenueByDayParams) ([]GetRevenueByDayRow, error)
 	GetReviewByTourId(ctx context.Context, tourID int32) (GetReviewByTourIdRow, error)
+	GetRoleByCode(ctx context.Context, ma string) (VaiTro, error)
+	GetRolePermissionCodes(ctx context.Context, vaiTroMa string) ([]string, error)
+	GetRolesWithPermissions(ctx context.Context) ([]GetRolesWithPermissionsRow, error)
+	// Lấy thông tin giữ chỗ theo mã giữ chỗ
+	GetSeatHoldByCode(ctx context.Context, maGiuCho pgtype.UUID) (GiuCho, error)
+	GetSessionByID(ctx context.Context, id int32) (PhienDangNhap, error)
 	// Tìm các tour tương tự dựa trên embedding (semantic search)
 	GetSimilarToursByEmbedding(ctx context.Context, arg GetSimilarToursByEmbeddingParams) ([]GetSimilarToursByEmbeddingRow, error)
+	// Văn bản nguồn để tạo embedding cho tour: tiêu đề, mô tả, danh mục, thời lượng, điểm đến, lịch trình và hoạt động.
+	// noi_dung_hash = md5(noi_dung) dùng để biết embedding đã lỗi thời hay chưa
+	// Lấy các tour chưa có embedding, có nội dung đã thay đổi hoặc được tạo bằng mô hình khác.
+	// tour_id = NULL: quét toàn bộ tour đang hoạt động (đồng bộ định kỳ)
+	GetStaleTourEmbeddingSources(ctx context.Context, arg GetStaleTourEmbeddingSourcesParams) ([]GetStaleTourEmbeddingSourcesRow, error)
+	// ===========================================
+	// TÀI KHOẢN NGÂN HÀNG NHÀ CUNG CẤP
+	// ===========================================
+	// Lấy danh sách tài khoản ngân hàng của nhà cung cấp (tài khoản mặc định lên đầu)
+	GetSupplierBankAccounts(ctx context.Context, nhaCungCapID pgtype.UUID) ([]TaiKhoanNganHang, error)
 	// Thống kê booking theo trạng thái và thời gian
 	GetSupplierBookingStatsByStatus(ctx context.Context, arg GetSupplierBookingStatsByStatusParams) ([]GetSupplierBookingStatsByStatusRow, error)
 	// Lấy danh sách đặt chỗ theo trạng thái với nhiều filter nâng cao
 	GetSupplierBookingsByStatusAdvanced(ctx context.Context, arg GetSupplierBookingsByStatusAdvancedParams) ([]GetSupplierBookingsByStatusAdvancedRow, error)
 	GetSupplierByID(ctx context.Context, id pgtype.UUID) (GetSupplierByIDRow, error)
 	GetSupplierById(ctx context.Context, id pgtype.UUID) (GetSupplierByIdRow, error)
+	// ===========================================
+	// NHÂN VIÊN NHÀ CUNG CẤP
+	// ===========================================
+	// Nhà cung cấp mà người dùng thuộc về: chủ sở hữu (id = nha_cung_cap.id) hoặc nhân viên đang hoạt động
+	GetSupplierContext(ctx context.Context, nguoiDungID pgtype.UUID) (GetSupplierContextRow, error)
 	// Thống kê khách hàng: top khách hàng, số lần đặt, tổng tiền
 	GetSupplierCustomerStats(ctx context.Context, arg GetSupplierCustomerStatsParams) ([]GetSupplierCustomerStatsRow, error)
 	// ===========================================
@@ -327,6 +564,10 @@ type Querier interface {
 	GetSupplierDashboardOverview(ctx context.Context, id pgtype.UUID) (GetSupplierDashboardOverviewRow, error)
 	// Tăng trưởng nhà cung cấp theo tháng
 	GetSupplierGrowthByMonth(ctx context.Context) ([]GetSupplierGrowthByMonthRow, error)
+	// Tổng hợp số tiền chi trả của nhà cung cấp theo trạng thái
+	GetSupplierPayoutSummary(ctx context.Context, nhaCungCapID pgtype.UUID) (GetSupplierPayoutSummaryRow, error)
+	// Lịch sử chi trả của nhà cung cấp
+	GetSupplierPayouts(ctx context.Context, arg GetSupplierPayoutsParams) ([]GetSupplierPayoutsRow, error)
 	// Phân tích đánh giá tour
 	GetSupplierRatingAnalysis(ctx context.Context, id pgtype.UUID) (GetSupplierRatingAnalysisRow, error)
 	// Booking gần đây
@@ -344,6 +585,10 @@ type Querier interface {
 	// Thống kê chi tiết các chỉ số đánh giá của nhà cung cấp
 	// Thống kê đánh giá của nhà cung cấp, có thể lọc theo từng tour cụ thể
 	GetSupplierReviewStatistics(ctx context.Context, arg GetSupplierReviewStatisticsParams) (GetSupplierReviewStatisticsRow, error)
+	GetSupplierStaff(ctx context.Context, nhaCungCapID pgtype.UUID) ([]GetSupplierStaffRow, error)
+	GetSupplierStaffByID(ctx context.Context, arg GetSupplierStaffByIDParams) (GetSupplierStaffByIDRow, error)
+	// Lời mời còn hiệu lực theo mã đã băm
+	GetSupplierStaffByInvitation(ctx context.Context, maLoiMoiBam *string) (GetSupplierStaffByInvitationRow, error)
 	// Tổng quan nhà cung cấp
 	GetSupplierStats(ctx context.Context) (GetSupplierStatsRow, error)
 	// Top tours bán chạy nhất theo số lượng booking và doanh thu
@@ -372,14 +617,27 @@ type Querier interface {
 	GetTopSuppliersByRevenue(ctx context.Context, limit int32) ([]GetTopSuppliersByRevenueRow, error)
 	// Top nhà cung cấp theo số tour
 	GetTopSuppliersByTours(ctx context.Context, limit int32) ([]GetTopSuppliersByToursRow, error)
+	GetTourByID(ctx context.Context, id int32) (Tour, error)
+	// Chính sách hủy của tour (khoi_hanh_id NULL) và của các khởi hành sắp tới có chính sách riêng
+	GetTourCancellationPolicies(ctx context.Context, tourID int32) ([]GetTourCancellationPoliciesRow, error)
+	// Nội dung hiện tại của tour theo định dạng models.CreateTourRequest
+	GetTourContentSnapshot(ctx context.Context, tourID int32) ([]byte, error)
 	GetTourContextForAI(ctx context.Context, arg GetTourContextForAIParams) ([]GetTourContextForAIRow, error)
 	GetTourDestinations(ctx context.Context, tourID int32) ([]GetTourDestinationsRow, error)
 	GetTourDetailByID(ctx context.Context, id int32) (GetTourDetailByIDRow, error)
 	// Lấy embedding của một tour
 	GetTourEmbedding(ctx context.Context, tourID int32) (TourEmbedding, error)
+	// ===========================================
+	// KIỂM DUYỆT TOUR & PHIÊN BẢN NỘI DUNG
+	// ===========================================
+	// Khóa dòng tour trong transaction gửi / duyệt phiên bản
+	GetTourForUpdate(ctx context.Context, id int32) (Tour, error)
 	GetTourImages(ctx context.Context, tourID int32) ([]AnhTour, error)
 	// Phân bố giá tour
 	GetTourPriceDistribution(ctx context.Context) ([]GetTourPriceDistributionRow, error)
+	GetTourRevisionByID(ctx context.Context, id int32) (GetTourRevisionByIDRow, error)
+	// nhom: tong | danh_muc | diem_den | khoang_gia | thoi_luong
+	GetTourSearchFacets(ctx context.Context, arg GetTourSearchFacetsParams) ([]GetTourSearchFacetsRow, error)
 	// Lấy lịch sử xem của một tour cụ thể
 	GetTourViewHistoryByTour(ctx context.Context, arg GetTourViewHistoryByTourParams) ([]LichSuXemTour, error)
 	// Lấy lịch sử xem tour của người dùng
@@ -388,6 +646,12 @@ type Querier interface {
 	// Lấy tour quốc nội (iso2 = country_code) hoặc quốc tế (iso2 != country_code) sắp xếp theo số lượt đặt
 	GetToursByCountryCode(ctx context.Context, arg GetToursByCountryCodeParams) ([]GetToursByCountryCodeRow, error)
 	GetToursBySupplier(ctx context.Context, arg GetToursBySupplierParams) ([]Tour, error)
+	// Tìm theo vị trí dựa trên tọa độ diem_den (vi_do, kinh_do).
+	// Khung tọa độ (min/max) luôn được truyền vào để dùng index; với tìm theo bán kính, khung là hình chữ nhật bao quanh vòng tròn
+	// và ban_kinh_km loại tiếp các điểm nằm ngoài vòng tròn. min_lng > max_lng nghĩa là khung vắt qua kinh tuyến 180.
+	// Khoảng cách (km) tính theo công thức haversine từ điểm (lat, lng).
+	// Mỗi tour lấy điểm đến gần nhất nằm trong vùng tìm kiếm làm vị trí ghim trên bản đồ
+	GetToursNearPoint(ctx context.Context, arg GetToursNearPointParams) ([]GetToursNearPointRow, error)
 	// Tìm giao dịch theo mã nội bộ
 	GetTransactionByCode(ctx context.Context, maGiaoDichNoiBo string) (LichSuGiaoDich, error)
 	// Lấy lịch sử giao dịch của một booking
@@ -396,9 +660,19 @@ type Querier interface {
 	GetTransactionsByPaymentGateway(ctx context.Context, arg GetTransactionsByPaymentGatewayParams) ([]GetTransactionsByPaymentGatewayRow, error)
 	// Lấy giao dịch theo trạng thái
 	GetTransactionsByStatus(ctx context.Context, arg GetTransactionsByStatusParams) ([]GetTransactionsByStatusRow, error)
+	// ===========================================
+	// XÁC THỰC HAI BƯỚC (TOTP 2FA)
+	// ===========================================
+	GetTwoFactorByUser(ctx context.Context, nguoiDungID pgtype.UUID) (XacThucHaiBuoc, error)
+	GetTwoFactorPolicies(ctx context.Context) ([]ChinhSach2fa, error)
+	// Các mã quyền trong danh sách không tồn tại trong danh mục
+	GetUnknownPermissions(ctx context.Context, quyen []string) ([]string, error)
+	GetUnknownRoles(ctx context.Context, vaiTro []string) ([]string, error)
 	GetUnreadContacts(ctx context.Context, arg GetUnreadContactsParams) ([]GetUnreadContactsRow, error)
 	// Lấy thông báo chưa đọc của người dùng
 	GetUnreadNotificationsByUser(ctx context.Context, arg GetUnreadNotificationsByUserParams) ([]ThongBao, error)
+	// Lấy các dòng sao kê tiền vào chưa đối soát
+	GetUnreconciledBankStatementLines(ctx context.Context) ([]SaoKeNganHang, error)
 	GetUnverifiedPasswordResetOTP(ctx context.Context, arg GetUnverifiedPasswordResetOTPParams) (OtpDatLaiMatKhau, error)
 	// =====================
 	// 9. DEPARTURE STATISTICS
@@ -406,6 +680,7 @@ type Querier interface {
 	// Các khởi hành sắp tới
 	GetUpcomingDepartures(ctx context.Context, limit int32) ([]GetUpcomingDeparturesRow, error)
 	GetUpcomingDeparturesList(ctx context.Context, limit int32) ([]GetUpcomingDeparturesListRow, error)
+	GetUserAccountByID(ctx context.Context, id pgtype.UUID) (NguoiDung, error)
 	GetUserByEmail(ctx context.Context, email string) (NguoiDung, error)
 	GetUserById(ctx context.Context, id pgtype.UUID) (GetUserByIdRow, error)
 	// Note: yeu_thich table doesn't exist yet, will implement in Sprint 3-4
@@ -414,10 +689,15 @@ type Querier interface {
 	// WHERE nguoi_dung_id = $1;
 	// Function để xóa các OTP đã hết hạn
 	GetUserPaymentHistory(ctx context.Context) ([]GetUserPaymentHistoryRow, error)
+	// Quyền hiệu lực: vai trò hệ thống theo enum vai_tro của người dùng + các vai trò được gán thêm.
+	// Nhân viên nhà cung cấp không nhận quyền của vai trò hệ thống mà dùng quyền được chủ sở hữu cấp
+	GetUserPermissions(ctx context.Context, arg GetUserPermissionsParams) ([]string, error)
 	// Lấy sở thích của người dùng
 	GetUserPreferences(ctx context.Context, nguoiDungID pgtype.UUID) ([]SoThichNguoiDung, error)
 	// Lấy sở thích theo loại (danh_muc hoặc diem_den)
 	GetUserPreferencesByType(ctx context.Context, arg GetUserPreferencesByTypeParams) ([]SoThichNguoiDung, error)
+	// Các vai trò được gán thêm cho người dùng
+	GetUserRoles(ctx context.Context, nguoiDungID pgtype.UUID) ([]GetUserRolesRow, error)
 	// =====================
 	// 2. USER STATISTICS
 	// =====================
@@ -425,21 +705,77 @@ type Querier interface {
 	GetUserStatsByRole(ctx context.Context) ([]GetUserStatsByRoleRow, error)
 	// So sánh theo năm
 	GetYearlyComparisonReport(ctx context.Context) ([]GetYearlyComparisonReportRow, error)
+	// Giữ lại chi trả hàng loạt (tranh chấp): các khoản chưa chi trả -> da_giu
+	HoldPayouts(ctx context.Context, arg HoldPayoutsParams) ([]ChiTraNhaCungCap, error)
 	HoldSeat(ctx context.Context, arg HoldSeatParams) error
+	IncrementApiKeyDailyUsage(ctx context.Context, arg IncrementApiKeyDailyUsageParams) error
 	IncrementBlogLikes(ctx context.Context, id int32) error
 	IncrementBlogViews(ctx context.Context, id int32) error
 	InvalidateAllOTPsForEmail(ctx context.Context, email string) error
+	IsSessionActive(ctx context.Context, id int32) (bool, error)
+	IsTwoFactorRequired(ctx context.Context, vaiTro VaiTroNguoiDung) (bool, error)
+	// ===========================================
+	// DANH SÁCH CHỜ KHỞI HÀNH (WAITLIST)
+	// ===========================================
+	// Đăng ký vào danh sách chờ của khởi hành
+	JoinWaitlist(ctx context.Context, arg JoinWaitlistParams) (DanhSachCho, error)
+	// Khách rời danh sách chờ (chỉ khi chưa được mời)
+	LeaveWaitlist(ctx context.Context, arg LeaveWaitlistParams) (DanhSachCho, error)
+	// Lọc theo người thực hiện, hành động, đối tượng, mã request và khoảng thời gian (bỏ trống = không lọc)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]NhatKyKiemToan, error)
+	ListPartners(ctx context.Context, arg ListPartnersParams) ([]ListPartnersRow, error)
+	// Hàng đợi kiểm duyệt (mặc định các phiên bản chờ duyệt, cũ nhất trước); không kèm nội dung
+	ListTourRevisions(ctx context.Context, arg ListTourRevisionsParams) ([]ListTourRevisionsRow, error)
+	// Lịch sử phiên bản của một tour, mới nhất trước
+	ListTourRevisionsByTour(ctx context.Context, arg ListTourRevisionsByTourParams) ([]ListTourRevisionsByTourRow, error)
+	// Khóa booking khi thay danh sách hành khách; chỉ booking chưa thanh toán (cho_xac_nhan, da_xac_nhan) được sửa
+	LockEditableBookingForPassengers(ctx context.Context, id int32) (int32, error)
 	// Đánh dấu tất cả thông báo của user đã đọc
 	MarkAllNotificationsAsRead(ctx context.Context, nguoiDungID pgtype.UUID) error
 	MarkContactAsRead(ctx context.Context, id int32) (LienHe, error)
 	// Đánh dấu thông báo đã đọc
 	MarkNotificationAsRead(ctx context.Context, id int32) (ThongBao, error)
+	// Đánh dấu chi trả thất bại: dang_xu_ly -> that_bai (có thể duyệt lại)
+	MarkPayoutsFailed(ctx context.Context, arg MarkPayoutsFailedParams) ([]ChiTraNhaCungCap, error)
+	// Đánh dấu đã chi trả: dang_xu_ly -> da_chi_tra
+	MarkPayoutsPaid(ctx context.Context, arg MarkPayoutsPaidParams) ([]ChiTraNhaCungCap, error)
+	// Tài khoản tạo từ đăng nhập mạng xã hội chỉ có mật khẩu ngẫu nhiên
+	MarkUserWithoutPassword(ctx context.Context, id pgtype.UUID) error
+	// ===========================================
+	// PHIÊN ĐĂNG NHẬP (LOGIN SESSIONS)
+	// ===========================================
+	// Cấp trước id phiên để nhúng vào token trước khi lưu phiên
+	NextSessionID(ctx context.Context) (int32, error)
 	// Lấy danh sách tour của nhà cung cấp
 	OptionTour(ctx context.Context, nhaCungCapID pgtype.UUID) ([]OptionTourRow, error)
+	// Mời các lượt chờ của khởi hành (tạo giữ chỗ có thời hạn), trả về thông tin để gửi thông báo và email
+	PromoteWaitlist(ctx context.Context, arg PromoteWaitlistParams) ([]PromoteWaitlistRow, error)
+	// Admin từ chối hoàn tiền (không áp dụng cho khoản đang được cổng thanh toán xử lý)
+	RejectRefund(ctx context.Context, arg RejectRefundParams) (LichSuGiaoDich, error)
 	// từ chối nhà cung cấp
 	RejectSupplier(ctx context.Context, id pgtype.UUID) (NguoiDung, error)
+	// Trả lại chỗ của các giữ chỗ đã hết hạn (chạy định kỳ bởi sweeper)
+	// Trả về số chỗ được giải phóng theo từng khởi hành
+	ReleaseExpiredSeatHolds(ctx context.Context) ([]ReleaseExpiredSeatHoldsRow, error)
+	// Bỏ giữ chi trả: da_giu -> san_sang (nếu khởi hành đã hoàn thành) hoặc cho_chi_tra
+	ReleasePayouts(ctx context.Context, arg ReleasePayoutsParams) ([]ChiTraNhaCungCap, error)
+	// Tạo lại mã mời cho nhân viên chưa kích hoạt (mã cũ hết hiệu lực)
+	RenewSupplierStaffInvitation(ctx context.Context, arg RenewSupplierStaffInvitationParams) (NhanVienNhaCungCap, error)
+	// Trả lời đánh giá, chỉ khi đánh giá thuộc tour của nhà cung cấp
+	ReplySupplierReview(ctx context.Context, arg ReplySupplierReviewParams) (int32, error)
 	ResetPassword(ctx context.Context, arg ResetPasswordParams) (NguoiDung, error)
 	RestoreSupplier(ctx context.Context, id pgtype.UUID) (NguoiDung, error)
+	// Ngừng ký bằng các khóa kích hoạt trước khóa mới nhất đã có hiệu lực
+	RetireJwtSigningKeys(ctx context.Context, arg RetireJwtSigningKeysParams) (int64, error)
+	// Chỉ phiên bản đang chờ duyệt mới được duyệt / từ chối (pgx.ErrNoRows nếu đã xử lý hoặc đã hủy)
+	ReviewTourRevision(ctx context.Context, arg ReviewTourRevisionParams) (PhienBanTour, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (RevokeApiKeyRow, error)
+	// Thu hồi một phiên của người dùng
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error)
+	// Thu hồi mọi phiên đang hoạt động của người dùng, trừ phiên ngoai_tru_id (nếu có)
+	RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) ([]int32, error)
+	// Xoay vòng token của phiên; chỉ thành công khi refresh token gửi lên là token hiện hành của phiên còn hiệu lực
+	RotateSessionTokens(ctx context.Context, arg RotateSessionTokensParams) (PhienDangNhap, error)
 	SearchBlogs(ctx context.Context, arg SearchBlogsParams) ([]SearchBlogsRow, error)
 	SearchSuppliers(ctx context.Context, email string) ([]SearchSuppliersRow, error)
 	// Đảm bảo bạn đã định nghĩa các CTE cần thiết (dd, dg, kh, ggt) như trong các bước trước.
@@ -447,7 +783,25 @@ type Querier interface {
 	// Câu truy vấn Chính: Tổng hợp tất cả thông tin và áp dụng các bộ lọc
 	// LEFT JOIN các bộ lọc để áp dụng WHERE
 	SearchTours(ctx context.Context, arg SearchToursParams) ([]SearchToursRow, error)
+	// Tìm kiếm tour có facet: cả hai truy vấn dùng chung bộ lọc.
+	// Mỗi điều kiện lọc được tính thành một cột boolean (m_*) để đếm facet của một nhóm
+	// theo mọi bộ lọc còn lại (bỏ qua chính nhóm đó), nhờ vậy thanh lọc hiển thị được số lượng của từng lựa chọn.
+	// Khoảng giá (VND, theo giá sau giảm): duoi_2tr | 2tr_5tr | 5tr_10tr | 10tr_20tr | tren_20tr
+	// Khoảng thời lượng: 1_ngay | 2_3_ngay | 4_7_ngay | tren_7_ngay
+	SearchToursFaceted(ctx context.Context, arg SearchToursFacetedParams) ([]SearchToursFacetedRow, error)
+	// Tìm kiếm ngữ nghĩa: xếp hạng theo độ tương đồng cosine giữa embedding câu truy vấn và embedding tour
+	// (chỉ so với embedding cùng mô hình), các bộ lọc có cấu trúc là điều kiện bắt buộc như SearchToursFaceted
+	SearchToursSemantic(ctx context.Context, arg SearchToursSemanticParams) ([]SearchToursSemanticRow, error)
+	// Đặt tài khoản làm mặc định (gọi sau ClearDefaultBankAccount trong cùng transaction)
+	SetDefaultBankAccount(ctx context.Context, arg SetDefaultBankAccountParams) (TaiKhoanNganHang, error)
+	// Gắn (hoặc gỡ) chính sách hủy riêng cho một khởi hành, ưu tiên hơn chính sách của tour
+	SetDepartureCancellationPolicy(ctx context.Context, arg SetDepartureCancellationPolicyParams) (SetDepartureCancellationPolicyRow, error)
 	SetPrimaryTourImage(ctx context.Context, arg SetPrimaryTourImageParams) error
+	// Gắn (hoặc gỡ khi chinh_sach_huy_id NULL) chính sách hủy cho tour; chính sách phải thuộc cùng nhà cung cấp
+	SetTourCancellationPolicy(ctx context.Context, arg SetTourCancellationPolicyParams) (SetTourCancellationPolicyRow, error)
+	SetTourModerationStatus(ctx context.Context, arg SetTourModerationStatusParams) (Tour, error)
+	// Lưu mã tham chiếu của cổng thanh toán (vd: Stripe PaymentIntent ID) khi giao dịch còn chờ
+	SetTransactionGatewayReference(ctx context.Context, arg SetTransactionGatewayReferenceParams) (LichSuGiaoDich, error)
 	SoftDeleteSupplier(ctx context.Context, id pgtype.UUID) error
 	// ===========================================
 	// ADMIN STATISTICS QUERIES
@@ -456,11 +810,23 @@ type Querier interface {
 	// 1. DASHBOARD OVERVIEW
 	// =====================
 	SupplierOptions(ctx context.Context) ([]SupplierOptionsRow, error)
+	// Cập nhật lượt chờ đã được mời theo trạng thái giữ chỗ: đã dùng -> da_dat, hết hạn -> het_han, bị hủy -> da_huy
+	SyncWaitlistOffers(ctx context.Context) (int64, error)
 	ToggleTourActive(ctx context.Context, id int32) (Tour, error)
+	// Cộng dồn số lượt gọi đã gom theo lô
+	TouchApiKey(ctx context.Context, arg TouchApiKeyParams) error
+	// Cập nhật hồ sơ provider mới nhất mỗi lần đăng nhập
+	TouchOAuthAccount(ctx context.Context, arg TouchOAuthAccountParams) error
 	UpdateActivity(ctx context.Context, arg UpdateActivityParams) (HoatDongTrongNgay, error)
+	// Cập nhật kết quả đối soát của dòng sao kê (chỉ dòng chưa đối soát)
+	UpdateBankStatementLineStatus(ctx context.Context, arg UpdateBankStatementLineStatusParams) (SaoKeNganHang, error)
 	UpdateBlog(ctx context.Context, arg UpdateBlogParams) (Blog, error)
+	// Cập nhật số người lớn / trẻ em theo danh sách hành khách và tính lại tổng tiền.
+	// Chỉ áp dụng cho booking chưa thanh toán và không đổi tổng số khách (số chỗ đã giữ)
+	UpdateBookingPassengerMix(ctx context.Context, arg UpdateBookingPassengerMixParams) (DatCho, error)
 	// Cập nhật trạng thái booking sau khi thanh toán thành công
 	UpdateBookingPaymentStatus(ctx context.Context, arg UpdateBookingPaymentStatusParams) (DatCho, error)
+	UpdateCancellationPolicy(ctx context.Context, arg UpdateCancellationPolicyParams) (ChinhSachHuy, error)
 	// Cập nhật phản hồi
 	UpdateContactResponse(ctx context.Context, arg UpdateContactResponseParams) (PhanHoiLienHe, error)
 	UpdateContactStatus(ctx context.Context, arg UpdateContactStatusParams) (LienHe, error)
@@ -475,10 +841,14 @@ type Querier interface {
 	UpdateItinerary(ctx context.Context, arg UpdateItineraryParams) (LichTrinh, error)
 	UpdateKhoiHanhTour(ctx context.Context, arg UpdateKhoiHanhTourParams) (KhoiHanhTour, error)
 	UpdateLichTrinh(ctx context.Context, arg UpdateLichTrinhParams) (LichTrinh, error)
+	UpdateLoginAttemptLocation(ctx context.Context, arg UpdateLoginAttemptLocationParams) error
+	UpdatePartner(ctx context.Context, arg UpdatePartnerParams) (DoiTac, error)
 	// Cập nhật thông tin hành khách
 	UpdatePassenger(ctx context.Context, arg UpdatePassengerParams) (HanhKhach, error)
+	UpdateRole(ctx context.Context, arg UpdateRoleParams) (VaiTro, error)
 	UpdateSupplier(ctx context.Context, arg UpdateSupplierParams) (NhaCungCap, error)
 	UpdateSupplierAndUser(ctx context.Context) (UpdateSupplierAndUserRow, error)
+	UpdateSupplierStaffPermissions(ctx context.Context, arg UpdateSupplierStaffPermissionsParams) (NhanVienNhaCungCap, error)
 	UpdateSupplierStatus(ctx context.Context, id pgtype.UUID) (NhaCungCap, error)
 	UpdateTour(ctx context.Context, arg UpdateTourParams) (Tour, error)
 	UpdateTourImage(ctx context.Context, arg UpdateTourImageParams) (AnhTour, error)
@@ -490,6 +860,19 @@ type Querier interface {
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (NguoiDung, error)
 	// Thêm dòng này
 	UpdateUserById(ctx context.Context, arg UpdateUserByIdParams) (NguoiDung, error)
+	// Tạo (hoặc tạo lại) khóa TOTP chờ kích hoạt; không ghi đè khi 2FA đang bật
+	UpsertPendingTwoFactor(ctx context.Context, arg UpsertPendingTwoFactorParams) (XacThucHaiBuoc, error)
+	// ===========================================
+	// CHI TRẢ NHÀ CUNG CẤP (SUPPLIER PAYOUT)
+	// ===========================================
+	// Tính lại khoản chi trả cho từng lịch khởi hành có booking đã thanh toán
+	// Phí cổng thanh toán lấy theo cổng của giao dịch thành công (hoặc phương thức thanh toán của booking)
+	// Chỉ cập nhật các khoản chưa được duyệt (cho_chi_tra, san_sang)
+	UpsertSupplierPayouts(ctx context.Context, tyLeHoaHong pgtype.Numeric) ([]ChiTraNhaCungCap, error)
+	UpsertTourEmbedding(ctx context.Context, arg UpsertTourEmbeddingParams) error
+	UpsertTwoFactorPolicy(ctx context.Context, arg UpsertTwoFactorPolicyParams) (ChinhSach2fa, error)
+	// Dùng một mã khôi phục (mỗi mã chỉ dùng được một lần)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
 	VerifyPasswordResetOTP(ctx context.Context, arg VerifyPasswordResetOTPParams) error
 }
 
@@ -23,7 +23,7 @@ ON CONFLICT (tour_id)
 DO UPDATE SET 
     embedding = EXCLUDED.embedding,
     ngay_cap_nhat = NOW()
-RETURNING tour_id, embedding, ngay_tao, ngay_cap_nhat
+RETURNING tour_id, embedding, ngay_tao, ngay_cap_nhat, mo_hinh, noi_dung_hash
 `
 
 type CreateTourEmbeddingParams struct {
@@ -40,6 +40,8 @@ func (q *Queries) CreateTourEmbedding(ctx context.Context, arg CreateTourEmbeddi
 		&i.Embedding,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.MoHinh,
+		&i.NoiDungHash,
 	)
 	return i, err
 }
@@ -529,7 +531,7 @@ func (q *Queries) GetSimilarToursByEmbedding(ctx context.Context, arg GetSimilar
 }
 
 const getTourEmbedding = `-- name: GetTourEmbedding :one
-SELECT tour_id, embedding, ngay_tao, ngay_cap_nhat FROM tour_embeddings
+SELECT tour_id, embedding, ngay_tao, ngay_cap_nhat, mo_hinh, noi_dung_hash FROM tour_embeddings
 WHERE tour_id = $1
 `
 
@@ -542,6 +544,8 @@ func (q *Queries) GetTourEmbedding(ctx context.Context, tourID int32) (TourEmbed
 		&i.Embedding,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.MoHinh,
+		&i.NoiDungHash,
 	)
 	return i, err
 }
@@ -0,0 +1,549 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: refund.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimRefundForApproval = `-- name: ClaimRefundForApproval :one
+UPDATE lich_su_giao_dich
+SET nguoi_duyet_id = $1::uuid,
+    ngay_duyet = CURRENT_TIMESTAMP,
+    so_lan_thu = so_lan_thu + 1,
+    ghi_chu_xu_ly = $2::text
+WHERE id = $3::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND trang_thai = 'dang_xuly'
+    AND nguoi_duyet_id IS NULL
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+`
+
+type ClaimRefundForApprovalParams struct {
+	NguoiDuyetID pgtype.UUID `json:"nguoi_duyet_id"`
+	GhiChuXuLy   *string     `json:"ghi_chu_xu_ly"`
+	ID           int32       `json:"id"`
+}
+
+// Admin duyệt khoản hoàn tiền đang chờ; chỉ một người duyệt được (nguoi_duyet_id còn trống)
+func (q *Queries) ClaimRefundForApproval(ctx context.Context, arg ClaimRefundForApprovalParams) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, claimRefundForApproval, arg.NguoiDuyetID, arg.GhiChuXuLy, arg.ID)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
+const claimRefundForRetry = `-- name: ClaimRefundForRetry :one
+UPDATE lich_su_giao_dich
+SET trang_thai = 'dang_xuly',
+    nguoi_duyet_id = $1::uuid,
+    ngay_duyet = CURRENT_TIMESTAMP,
+    so_lan_thu = so_lan_thu + 1
+WHERE id = $2::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND (
+        trang_thai = 'that_bai'
+        OR (
+            $3::boolean
+            AND trang_thai = 'dang_xuly'
+            AND nguoi_duyet_id IS NOT NULL
+            AND ngay_duyet < CURRENT_TIMESTAMP - INTERVAL '10 minutes'
+        )
+    )
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+`
+
+type ClaimRefundForRetryParams struct {
+	NguoiDuyetID pgtype.UUID `json:"nguoi_duyet_id"`
+	ID           int32       `json:"id"`
+	ThuCong      bool        `json:"thu_cong"`
+}
+
+// Thử lại khoản hoàn tiền thất bại. Khoản đã duyệt nhưng bị treo quá 10 phút (vd: server dừng giữa chừng,
+// hoặc cổng đã hoàn tiền nhưng cập nhật trạng thái lỗi) có thể đã được hoàn phía cổng,
+// nên chỉ được nhận lại để đối soát thủ công (thu_cong = true), không gọi lại API hoàn tiền
+func (q *Queries) ClaimRefundForRetry(ctx context.Context, arg ClaimRefundForRetryParams) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, claimRefundForRetry, arg.NguoiDuyetID, arg.ID, arg.ThuCong)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
+const completeRefund = `-- name: CompleteRefund :one
+UPDATE lich_su_giao_dich
+SET trang_thai = $1::trang_thai_thanh_toan,
+    ma_tham_chieu_cong_thanh_toan = COALESCE($2::varchar, ma_tham_chieu_cong_thanh_toan),
+    ghi_chu_xu_ly = COALESCE($3::text, ghi_chu_xu_ly),
+    ngay_hoan_thanh = CURRENT_TIMESTAMP
+WHERE id = $4::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND trang_thai = 'dang_xuly'
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+`
+
+type CompleteRefundParams struct {
+	TrangThai   TrangThaiThanhToan `json:"trang_thai"`
+	MaThamChieu *string            `json:"ma_tham_chieu"`
+	GhiChuXuLy  *string            `json:"ghi_chu_xu_ly"`
+	ID          int32              `json:"id"`
+}
+
+// Ghi nhận hoàn tiền thành công (da_hoan_tien hoặc hoan_mot_phan)
+func (q *Queries) CompleteRefund(ctx context.Context, arg CompleteRefundParams) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, completeRefund,
+		arg.TrangThai,
+		arg.MaThamChieu,
+		arg.GhiChuXuLy,
+		arg.ID,
+	)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
+const countRefundTransactions = `-- name: CountRefundTransactions :one
+SELECT COUNT(*) FROM lich_su_giao_dich
+WHERE loai_giao_dich = 'hoan_tien'
+    AND ($1::trang_thai_thanh_toan IS NULL OR trang_thai = $1::trang_thai_thanh_toan)
+`
+
+func (q *Queries) CountRefundTransactions(ctx context.Context, trangThai NullTrangThaiThanhToan) (int64, error) {
+	row := q.db.QueryRow(ctx, countRefundTransactions, trangThai)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createRefundTransaction = `-- name: CreateRefundTransaction :one
+INSERT INTO lich_su_giao_dich (
+    dat_cho_id,
+    nguoi_dung_id,
+    ma_giao_dich_noi_bo,
+    cong_thanh_toan_id,
+    so_tien,
+    loai_giao_dich,
+    trang_thai,
+    giao_dich_goc_id,
+    ly_do
+)
+SELECT
+    g.dat_cho_id,
+    g.nguoi_dung_id,
+    $1::varchar,
+    g.cong_thanh_toan_id,
+    LEAST($2::numeric, g.so_tien),
+    'hoan_tien',
+    'dang_xuly',
+    g.id,
+    $3::text
+FROM lich_su_giao_dich g
+WHERE g.id = $4::int
+ON CONFLICT (giao_dich_goc_id) WHERE loai_giao_dich = 'hoan_tien' AND trang_thai <> 'da_huy' DO NOTHING
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+`
+
+type CreateRefundTransactionParams struct {
+	MaGiaoDichNoiBo string         `json:"ma_giao_dich_noi_bo"`
+	SoTien          pgtype.Numeric `json:"so_tien"`
+	LyDo            *string        `json:"ly_do"`
+	GiaoDichGocID   int32          `json:"giao_dich_goc_id"`
+}
+
+// Tạo yêu cầu hoàn tiền (chờ admin duyệt) cho giao dịch thanh toán gốc
+// Giao dịch gốc đã có khoản hoàn tiền còn hiệu lực thì bỏ qua (pgx.ErrNoRows)
+func (q *Queries) CreateRefundTransaction(ctx context.Context, arg CreateRefundTransactionParams) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, createRefundTransaction,
+		arg.MaGiaoDichNoiBo,
+		arg.SoTien,
+		arg.LyDo,
+		arg.GiaoDichGocID,
+	)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
+const failRefund = `-- name: FailRefund :one
+UPDATE lich_su_giao_dich
+SET trang_thai = 'that_bai',
+    ghi_chu_xu_ly = $1::text
+WHERE id = $2::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND trang_thai = 'dang_xuly'
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+`
+
+type FailRefundParams struct {
+	GhiChuXuLy *string `json:"ghi_chu_xu_ly"`
+	ID         int32   `json:"id"`
+}
+
+// Ghi nhận cổng thanh toán từ chối/lỗi khi hoàn tiền
+func (q *Queries) FailRefund(ctx context.Context, arg FailRefundParams) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, failRefund, arg.GhiChuXuLy, arg.ID)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
+const getPaidTransactionByBooking = `-- name: GetPaidTransactionByBooking :one
+
+SELECT id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu FROM lich_su_giao_dich
+WHERE dat_cho_id = $1
+    AND trang_thai = 'thanh_cong'
+    AND COALESCE(loai_giao_dich, 'thanh_toan') = 'thanh_toan'
+ORDER BY ngay_hoan_thanh DESC NULLS LAST, id DESC
+LIMIT 1
+`
+
+// ===========================================
+// THỰC HIỆN HOÀN TIỀN (REFUND EXECUTION)
+// ===========================================
+// Lấy giao dịch thanh toán thành công gần nhất của booking (giao dịch gốc để hoàn tiền)
+func (q *Queries) GetPaidTransactionByBooking(ctx context.Context, datChoID *int32) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, getPaidTransactionByBooking, datChoID)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
+
+const getRefundTransactionByID = `-- name: GetRefundTransactionByID :one
+SELECT
+    ht.id, ht.dat_cho_id, ht.nguoi_dung_id, ht.ma_giao_dich_noi_bo, ht.ma_tham_chieu_cong_thanh_toan, ht.cong_thanh_toan_id, ht.so_tien, ht.loai_giao_dich, ht.trang_thai, ht.noi_dung_chuyen_khoan, ht.ngay_tao, ht.ngay_hoan_thanh, ht.giao_dich_goc_id, ht.ly_do, ht.ghi_chu_xu_ly, ht.nguoi_duyet_id, ht.ngay_duyet, ht.so_lan_thu,
+    g.ma_giao_dich_noi_bo AS ma_giao_dich_goc,
+    g.ma_tham_chieu_cong_thanh_toan AS ma_tham_chieu_goc,
+    g.so_tien AS so_tien_goc,
+    g.ngay_hoan_thanh AS ngay_thanh_toan_goc
+FROM lich_su_giao_dich ht
+JOIN lich_su_giao_dich g ON g.id = ht.giao_dich_goc_id
+WHERE ht.id = $1
+    AND ht.loai_giao_dich = 'hoan_tien'
+`
+
+type GetRefundTransactionByIDRow struct {
+	ID                       int32                  `json:"id"`
+	DatChoID                 *int32                 `json:"dat_cho_id"`
+	NguoiDungID              pgtype.UUID            `json:"nguoi_dung_id"`
+	MaGiaoDichNoiBo          string                 `json:"ma_giao_dich_noi_bo"`
+	MaThamChieuCongThanhToan *string                `json:"ma_tham_chieu_cong_thanh_toan"`
+	CongThanhToanID          *string                `json:"cong_thanh_toan_id"`
+	SoTien                   pgtype.Numeric         `json:"so_tien"`
+	LoaiGiaoDich             *string                `json:"loai_giao_dich"`
+	TrangThai                NullTrangThaiThanhToan `json:"trang_thai"`
+	NoiDungChuyenKhoan       *string                `json:"noi_dung_chuyen_khoan"`
+	NgayTao                  pgtype.Timestamp       `json:"ngay_tao"`
+	NgayHoanThanh            pgtype.Timestamp       `json:"ngay_hoan_thanh"`
+	GiaoDichGocID            *int32                 `json:"giao_dich_goc_id"`
+	LyDo                     *string                `json:"ly_do"`
+	GhiChuXuLy               *string                `json:"ghi_chu_xu_ly"`
+	NguoiDuyetID             pgtype.UUID            `json:"nguoi_duyet_id"`
+	NgayDuyet                pgtype.Timestamp       `json:"ngay_duyet"`
+	SoLanThu                 int32                  `json:"so_lan_thu"`
+	MaGiaoDichGoc            string                 `json:"ma_giao_dich_goc"`
+	MaThamChieuGoc           *string                `json:"ma_tham_chieu_goc"`
+	SoTienGoc                pgtype.Numeric         `json:"so_tien_goc"`
+	NgayThanhToanGoc         pgtype.Timestamp       `json:"ngay_thanh_toan_goc"`
+}
+
+// Lấy khoản hoàn tiền kèm thông tin giao dịch thanh toán gốc
+func (q *Queries) GetRefundTransactionByID(ctx context.Context, id int32) (GetRefundTransactionByIDRow, error) {
+	row := q.db.QueryRow(ctx, getRefundTransactionByID, id)
+	var i GetRefundTransactionByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+		&i.MaGiaoDichGoc,
+		&i.MaThamChieuGoc,
+		&i.SoTienGoc,
+		&i.NgayThanhToanGoc,
+	)
+	return i, err
+}
+
+const getRefundTransactions = `-- name: GetRefundTransactions :many
+SELECT
+    ht.id,
+    ht.dat_cho_id,
+    ht.ma_giao_dich_noi_bo,
+    ht.ma_tham_chieu_cong_thanh_toan,
+    ht.cong_thanh_toan_id,
+    ht.so_tien,
+    ht.trang_thai,
+    ht.giao_dich_goc_id,
+    ht.ly_do,
+    ht.ghi_chu_xu_ly,
+    ht.nguoi_duyet_id,
+    ht.ngay_duyet,
+    ht.so_lan_thu,
+    ht.ngay_tao,
+    ht.ngay_hoan_thanh,
+    g.ma_giao_dich_noi_bo AS ma_giao_dich_goc,
+    g.so_tien AS so_tien_goc,
+    nd.ho_ten AS ten_nguoi_dung,
+    nd.email AS email_nguoi_dung,
+    t.id AS tour_id,
+    t.tieu_de AS ten_tour
+FROM lich_su_giao_dich ht
+LEFT JOIN lich_su_giao_dich g ON g.id = ht.giao_dich_goc_id
+LEFT JOIN nguoi_dung nd ON nd.id = ht.nguoi_dung_id
+LEFT JOIN dat_cho dc ON dc.id = ht.dat_cho_id
+LEFT JOIN khoi_hanh_tour kh ON kh.id = dc.khoi_hanh_id
+LEFT JOIN tour t ON t.id = kh.tour_id
+WHERE ht.loai_giao_dich = 'hoan_tien'
+    AND ($1::trang_thai_thanh_toan IS NULL OR ht.trang_thai = $1::trang_thai_thanh_toan)
+ORDER BY ht.ngay_tao DESC, ht.id DESC
+LIMIT $3::int OFFSET $2::int
+`
+
+type GetRefundTransactionsParams struct {
+	TrangThai NullTrangThaiThanhToan `json:"trang_thai"`
+	Offset    int32                  `json:"offset"`
+	Limit     int32                  `json:"limit"`
+}
+
+type GetRefundTransactionsRow struct {
+	ID                       int32                  `json:"id"`
+	DatChoID                 *int32                 `json:"dat_cho_id"`
+	MaGiaoDichNoiBo          string                 `json:"ma_giao_dich_noi_bo"`
+	MaThamChieuCongThanhToan *string                `json:"ma_tham_chieu_cong_thanh_toan"`
+	CongThanhToanID          *string                `json:"cong_thanh_toan_id"`
+	SoTien                   pgtype.Numeric         `json:"so_tien"`
+	TrangThai                NullTrangThaiThanhToan `json:"trang_thai"`
+	GiaoDichGocID            *int32                 `json:"giao_dich_goc_id"`
+	LyDo                     *string                `json:"ly_do"`
+	GhiChuXuLy               *string                `json:"ghi_chu_xu_ly"`
+	NguoiDuyetID             pgtype.UUID            `json:"nguoi_duyet_id"`
+	NgayDuyet                pgtype.Timestamp       `json:"ngay_duyet"`
+	SoLanThu                 int32                  `json:"so_lan_thu"`
+	NgayTao                  pgtype.Timestamp       `json:"ngay_tao"`
+	NgayHoanThanh            pgtype.Timestamp       `json:"ngay_hoan_thanh"`
+	MaGiaoDichGoc            *string                `json:"ma_giao_dich_goc"`
+	SoTienGoc                pgtype.Numeric         `json:"so_tien_goc"`
+	TenNguoiDung             *string                `json:"ten_nguoi_dung"`
+	EmailNguoiDung           *string                `json:"email_nguoi_dung"`
+	TourID                   *int32                 `json:"tour_id"`
+	TenTour                  *string                `json:"ten_tour"`
+}
+
+// Danh sách khoản hoàn tiền (Admin), lọc theo trạng thái
+func (q *Queries) GetRefundTransactions(ctx context.Context, arg GetRefundTransactionsParams) ([]GetRefundTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, getRefundTransactions, arg.TrangThai, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRefundTransactionsRow
+	for rows.Next() {
+		var i GetRefundTransactionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DatChoID,
+			&i.MaGiaoDichNoiBo,
+			&i.MaThamChieuCongThanhToan,
+			&i.CongThanhToanID,
+			&i.SoTien,
+			&i.TrangThai,
+			&i.GiaoDichGocID,
+			&i.LyDo,
+			&i.GhiChuXuLy,
+			&i.NguoiDuyetID,
+			&i.NgayDuyet,
+			&i.SoLanThu,
+			&i.NgayTao,
+			&i.NgayHoanThanh,
+			&i.MaGiaoDichGoc,
+			&i.SoTienGoc,
+			&i.TenNguoiDung,
+			&i.EmailNguoiDung,
+			&i.TourID,
+			&i.TenTour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rejectRefund = `-- name: RejectRefund :one
+UPDATE lich_su_giao_dich
+SET trang_thai = 'da_huy',
+    nguoi_duyet_id = $1::uuid,
+    ngay_duyet = CURRENT_TIMESTAMP,
+    ghi_chu_xu_ly = $2::text
+WHERE id = $3::int
+    AND loai_giao_dich = 'hoan_tien'
+    AND (trang_thai = 'that_bai' OR (trang_thai = 'dang_xuly' AND nguoi_duyet_id IS NULL))
+RETURNING id, dat_cho_id, nguoi_dung_id, ma_giao_dich_noi_bo, ma_tham_chieu_cong_thanh_toan, cong_thanh_toan_id, so_tien, loai_giao_dich, trang_thai, noi_dung_chuyen_khoan, ngay_tao, ngay_hoan_thanh, giao_dich_goc_id, ly_do, ghi_chu_xu_ly, nguoi_duyet_id, ngay_duyet, so_lan_thu
+`
+
+type RejectRefundParams struct {
+	NguoiDuyetID pgtype.UUID `json:"nguoi_duyet_id"`
+	GhiChuXuLy   string      `json:"ghi_chu_xu_ly"`
+	ID           int32       `json:"id"`
+}
+
+// Admin từ chối hoàn tiền (không áp dụng cho khoản đang được cổng thanh toán xử lý)
+func (q *Queries) RejectRefund(ctx context.Context, arg RejectRefundParams) (LichSuGiaoDich, error) {
+	row := q.db.QueryRow(ctx, rejectRefund, arg.NguoiDuyetID, arg.GhiChuXuLy, arg.ID)
+	var i LichSuGiaoDich
+	err := row.Scan(
+		&i.ID,
+		&i.DatChoID,
+		&i.NguoiDungID,
+		&i.MaGiaoDichNoiBo,
+		&i.MaThamChieuCongThanhToan,
+		&i.CongThanhToanID,
+		&i.SoTien,
+		&i.LoaiGiaoDich,
+		&i.TrangThai,
+		&i.NoiDungChuyenKhoan,
+		&i.NgayTao,
+		&i.NgayHoanThanh,
+		&i.GiaoDichGocID,
+		&i.LyDo,
+		&i.GhiChuXuLy,
+		&i.NguoiDuyetID,
+		&i.NgayDuyet,
+		&i.SoLanThu,
+	)
+	return i, err
+}
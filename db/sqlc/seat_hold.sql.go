@@ -0,0 +1,223 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: seat_hold.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const cancelSeatHold = `-- name: CancelSeatHold :one
+WITH huy AS (
+    UPDATE giu_cho
+    SET trang_thai = 'da_huy',
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    WHERE ma_giu_cho = $1
+        AND nguoi_dung_id = $2
+        AND trang_thai = 'dang_giu'
+    RETURNING id, ma_giu_cho, khoi_hanh_id, nguoi_dung_id, so_nguoi_lon, so_tre_em, trang_thai, het_han_luc, dat_cho_id, ngay_tao, ngay_cap_nhat
+), tra_cho AS (
+    UPDATE khoi_hanh_tour kh
+    SET so_cho_da_dat = GREATEST(kh.so_cho_da_dat - (huy.so_nguoi_lon + huy.so_tre_em), 0),
+        trang_thai = CASE WHEN kh.trang_thai = 'het_cho' THEN 'con_cho'::trang_thai_khoi_hanh ELSE kh.trang_thai END,
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    FROM huy
+    WHERE kh.id = huy.khoi_hanh_id
+)
+SELECT id, ma_giu_cho, khoi_hanh_id, nguoi_dung_id, so_nguoi_lon, so_tre_em, trang_thai, het_han_luc, dat_cho_id, ngay_tao, ngay_cap_nhat FROM huy
+`
+
+type CancelSeatHoldParams struct {
+	MaGiuCho    pgtype.UUID `json:"ma_giu_cho"`
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+}
+
+type CancelSeatHoldRow struct {
+	ID          int32            `json:"id"`
+	MaGiuCho    pgtype.UUID      `json:"ma_giu_cho"`
+	KhoiHanhID  int32            `json:"khoi_hanh_id"`
+	NguoiDungID pgtype.UUID      `json:"nguoi_dung_id"`
+	SoNguoiLon  int32            `json:"so_nguoi_lon"`
+	SoTreEm     int32            `json:"so_tre_em"`
+	TrangThai   string           `json:"trang_thai"`
+	HetHanLuc   pgtype.Timestamp `json:"het_han_luc"`
+	DatChoID    *int32           `json:"dat_cho_id"`
+	NgayTao     pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
+// Người dùng tự hủy giữ chỗ đang hiệu lực và trả lại chỗ cho khởi hành
+func (q *Queries) CancelSeatHold(ctx context.Context, arg CancelSeatHoldParams) (CancelSeatHoldRow, error) {
+	row := q.db.QueryRow(ctx, cancelSeatHold, arg.MaGiuCho, arg.NguoiDungID)
+	var i CancelSeatHoldRow
+	err := row.Scan(
+		&i.ID,
+		&i.MaGiuCho,
+		&i.KhoiHanhID,
+		&i.NguoiDungID,
+		&i.SoNguoiLon,
+		&i.SoTreEm,
+		&i.TrangThai,
+		&i.HetHanLuc,
+		&i.DatChoID,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const createBookingFromHold = `-- name: CreateBookingFromHold :one
+SELECT su_dung_giu_cho(
+    $1::uuid,
+    $2::uuid,
+    $3::varchar
+)::int AS dat_cho_id
+`
+
+type CreateBookingFromHoldParams struct {
+	NguoiDungID         pgtype.UUID `json:"nguoi_dung_id"`
+	MaGiuCho            pgtype.UUID `json:"ma_giu_cho"`
+	PhuongThucThanhToan *string     `json:"phuong_thuc_thanh_toan"`
+}
+
+// Tạo đặt chỗ bằng cách tiêu thụ mã giữ chỗ, trả về ID booking
+func (q *Queries) CreateBookingFromHold(ctx context.Context, arg CreateBookingFromHoldParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createBookingFromHold, arg.NguoiDungID, arg.MaGiuCho, arg.PhuongThucThanhToan)
+	var dat_cho_id int32
+	err := row.Scan(&dat_cho_id)
+	return dat_cho_id, err
+}
+
+const createSeatHold = `-- name: CreateSeatHold :one
+
+INSERT INTO giu_cho (
+    khoi_hanh_id,
+    nguoi_dung_id,
+    so_nguoi_lon,
+    so_tre_em,
+    het_han_luc
+)
+SELECT
+    $1::int,
+    $2::uuid,
+    $3::int,
+    $4::int,
+    CURRENT_TIMESTAMP + ($5::int * INTERVAL '1 minute')
+FROM hold_seat($1::int, $3::int, $4::int)
+RETURNING id, ma_giu_cho, khoi_hanh_id, nguoi_dung_id, so_nguoi_lon, so_tre_em, trang_thai, het_han_luc, dat_cho_id, ngay_tao, ngay_cap_nhat
+`
+
+type CreateSeatHoldParams struct {
+	KhoiHanhID  int32       `json:"khoi_hanh_id"`
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	SoNguoiLon  int32       `json:"so_nguoi_lon"`
+	SoTreEm     int32       `json:"so_tre_em"`
+	SoPhut      int32       `json:"so_phut"`
+}
+
+// ===========================================
+// GIỮ CHỖ CÓ THỜI HẠN (SEAT HOLD)
+// ===========================================
+// Giữ chỗ (tăng so_cho_da_dat qua hold_seat) và ghi nhận bản ghi giữ chỗ có thời hạn
+func (q *Queries) CreateSeatHold(ctx context.Context, arg CreateSeatHoldParams) (GiuCho, error) {
+	row := q.db.QueryRow(ctx, createSeatHold,
+		arg.KhoiHanhID,
+		arg.NguoiDungID,
+		arg.SoNguoiLon,
+		arg.SoTreEm,
+		arg.SoPhut,
+	)
+	var i GiuCho
+	err := row.Scan(
+		&i.ID,
+		&i.MaGiuCho,
+		&i.KhoiHanhID,
+		&i.NguoiDungID,
+		&i.SoNguoiLon,
+		&i.SoTreEm,
+		&i.TrangThai,
+		&i.HetHanLuc,
+		&i.DatChoID,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const getSeatHoldByCode = `-- name: GetSeatHoldByCode :one
+SELECT id, ma_giu_cho, khoi_hanh_id, nguoi_dung_id, so_nguoi_lon, so_tre_em, trang_thai, het_han_luc, dat_cho_id, ngay_tao, ngay_cap_nhat FROM giu_cho
+WHERE ma_giu_cho = $1
+`
+
+// Lấy thông tin giữ chỗ theo mã giữ chỗ
+func (q *Queries) GetSeatHoldByCode(ctx context.Context, maGiuCho pgtype.UUID) (GiuCho, error) {
+	row := q.db.QueryRow(ctx, getSeatHoldByCode, maGiuCho)
+	var i GiuCho
+	err := row.Scan(
+		&i.ID,
+		&i.MaGiuCho,
+		&i.KhoiHanhID,
+		&i.NguoiDungID,
+		&i.SoNguoiLon,
+		&i.SoTreEm,
+		&i.TrangThai,
+		&i.HetHanLuc,
+		&i.DatChoID,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const releaseExpiredSeatHolds = `-- name: ReleaseExpiredSeatHolds :many
+WITH het_han AS (
+    UPDATE giu_cho
+    SET trang_thai = 'het_han',
+        ngay_cap_nhat = CURRENT_TIMESTAMP
+    WHERE trang_thai = 'dang_giu'
+        AND het_han_luc <= CURRENT_TIMESTAMP
+    RETURNING khoi_hanh_id, so_nguoi_lon, so_tre_em
+), tong_theo_khoi_hanh AS (
+    SELECT khoi_hanh_id, SUM(so_nguoi_lon + so_tre_em)::int AS so_cho
+    FROM het_han
+    GROUP BY khoi_hanh_id
+)
+UPDATE khoi_hanh_tour kh
+SET so_cho_da_dat = GREATEST(kh.so_cho_da_dat - t.so_cho, 0),
+    trang_thai = CASE WHEN kh.trang_thai = 'het_cho' THEN 'con_cho'::trang_thai_khoi_hanh ELSE kh.trang_thai END,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM tong_theo_khoi_hanh t
+WHERE kh.id = t.khoi_hanh_id
+RETURNING kh.id AS khoi_hanh_id, t.so_cho AS so_cho_giai_phong
+`
+
+type ReleaseExpiredSeatHoldsRow struct {
+	KhoiHanhID     int32 `json:"khoi_hanh_id"`
+	SoChoGiaiPhong int32 `json:"so_cho_giai_phong"`
+}
+
+// Trả lại chỗ của các giữ chỗ đã hết hạn (chạy định kỳ bởi sweeper)
+// Trả về số chỗ được giải phóng theo từng khởi hành
+func (q *Queries) ReleaseExpiredSeatHolds(ctx context.Context) ([]ReleaseExpiredSeatHoldsRow, error) {
+	rows, err := q.db.Query(ctx, releaseExpiredSeatHolds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReleaseExpiredSeatHoldsRow
+	for rows.Next() {
+		var i ReleaseExpiredSeatHoldsRow
+		if err := rows.Scan(&i.KhoiHanhID, &i.SoChoGiaiPhong); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
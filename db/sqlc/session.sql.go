@@ -0,0 +1,287 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: session.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO phien_dang_nhap (
+    id,
+    nguoi_dung_id,
+    ma_token_truy_cap,
+    ma_token_lam_moi,
+    thoi_han_token,
+    thong_tin_thiet_bi,
+    dia_chi_ip
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id, nguoi_dung_id, ma_token_truy_cap, ma_token_lam_moi, thoi_han_token, thong_tin_thiet_bi, dang_hoat_dong, ngay_tao, ngay_cap_nhat, dia_chi_ip, lan_lam_moi_cuoi, thu_hoi_luc, ly_do_thu_hoi
+`
+
+type CreateSessionParams struct {
+	ID              int32            `json:"id"`
+	NguoiDungID     pgtype.UUID      `json:"nguoi_dung_id"`
+	MaTokenTruyCap  string           `json:"ma_token_truy_cap"`
+	MaTokenLamMoi   string           `json:"ma_token_lam_moi"`
+	ThoiHanToken    pgtype.Timestamp `json:"thoi_han_token"`
+	ThongTinThietBi *string          `json:"thong_tin_thiet_bi"`
+	DiaChiIp        *string          `json:"dia_chi_ip"`
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (PhienDangNhap, error) {
+	row := q.db.QueryRow(ctx, createSession,
+		arg.ID,
+		arg.NguoiDungID,
+		arg.MaTokenTruyCap,
+		arg.MaTokenLamMoi,
+		arg.ThoiHanToken,
+		arg.ThongTinThietBi,
+		arg.DiaChiIp,
+	)
+	var i PhienDangNhap
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.MaTokenTruyCap,
+		&i.MaTokenLamMoi,
+		&i.ThoiHanToken,
+		&i.ThongTinThietBi,
+		&i.DangHoatDong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.DiaChiIp,
+		&i.LanLamMoiCuoi,
+		&i.ThuHoiLuc,
+		&i.LyDoThuHoi,
+	)
+	return i, err
+}
+
+const getActiveSessionsByUser = `-- name: GetActiveSessionsByUser :many
+SELECT id, thong_tin_thiet_bi, dia_chi_ip, thoi_han_token, lan_lam_moi_cuoi, ngay_tao, ngay_cap_nhat
+FROM phien_dang_nhap
+WHERE nguoi_dung_id = $1
+    AND dang_hoat_dong = TRUE
+    AND thoi_han_token > CURRENT_TIMESTAMP
+ORDER BY COALESCE(lan_lam_moi_cuoi, ngay_tao) DESC
+`
+
+type GetActiveSessionsByUserRow struct {
+	ID              int32            `json:"id"`
+	ThongTinThietBi *string          `json:"thong_tin_thiet_bi"`
+	DiaChiIp        *string          `json:"dia_chi_ip"`
+	ThoiHanToken    pgtype.Timestamp `json:"thoi_han_token"`
+	LanLamMoiCuoi   pgtype.Timestamp `json:"lan_lam_moi_cuoi"`
+	NgayTao         pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat     pgtype.Timestamp `json:"ngay_cap_nhat"`
+}
+
+// Các thiết bị đang đăng nhập của người dùng
+func (q *Queries) GetActiveSessionsByUser(ctx context.Context, nguoiDungID pgtype.UUID) ([]GetActiveSessionsByUserRow, error) {
+	rows, err := q.db.Query(ctx, getActiveSessionsByUser, nguoiDungID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActiveSessionsByUserRow
+	for rows.Next() {
+		var i GetActiveSessionsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ThongTinThietBi,
+			&i.DiaChiIp,
+			&i.ThoiHanToken,
+			&i.LanLamMoiCuoi,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSessionByID = `-- name: GetSessionByID :one
+SELECT id, nguoi_dung_id, ma_token_truy_cap, ma_token_lam_moi, thoi_han_token, thong_tin_thiet_bi, dang_hoat_dong, ngay_tao, ngay_cap_nhat, dia_chi_ip, lan_lam_moi_cuoi, thu_hoi_luc, ly_do_thu_hoi FROM phien_dang_nhap
+WHERE id = $1
+`
+
+func (q *Queries) GetSessionByID(ctx context.Context, id int32) (PhienDangNhap, error) {
+	row := q.db.QueryRow(ctx, getSessionByID, id)
+	var i PhienDangNhap
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.MaTokenTruyCap,
+		&i.MaTokenLamMoi,
+		&i.ThoiHanToken,
+		&i.ThongTinThietBi,
+		&i.DangHoatDong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.DiaChiIp,
+		&i.LanLamMoiCuoi,
+		&i.ThuHoiLuc,
+		&i.LyDoThuHoi,
+	)
+	return i, err
+}
+
+const isSessionActive = `-- name: IsSessionActive :one
+SELECT EXISTS (
+    SELECT 1 FROM phien_dang_nhap
+    WHERE id = $1
+        AND dang_hoat_dong = TRUE
+        AND thoi_han_token > CURRENT_TIMESTAMP
+)::bool AS active
+`
+
+func (q *Queries) IsSessionActive(ctx context.Context, id int32) (bool, error) {
+	row := q.db.QueryRow(ctx, isSessionActive, id)
+	var active bool
+	err := row.Scan(&active)
+	return active, err
+}
+
+const nextSessionID = `-- name: NextSessionID :one
+
+SELECT nextval(pg_get_serial_sequence('phien_dang_nhap', 'id'))::int AS id
+`
+
+// ===========================================
+// PHIÊN ĐĂNG NHẬP (LOGIN SESSIONS)
+// ===========================================
+// Cấp trước id phiên để nhúng vào token trước khi lưu phiên
+func (q *Queries) NextSessionID(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, nextSessionID)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const revokeSession = `-- name: RevokeSession :execrows
+UPDATE phien_dang_nhap
+SET dang_hoat_dong = FALSE,
+    thu_hoi_luc = CURRENT_TIMESTAMP,
+    ly_do_thu_hoi = $1,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $2
+    AND nguoi_dung_id = $3
+    AND dang_hoat_dong = TRUE
+`
+
+type RevokeSessionParams struct {
+	LyDoThuHoi  *string     `json:"ly_do_thu_hoi"`
+	ID          int32       `json:"id"`
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+}
+
+// Thu hồi một phiên của người dùng
+func (q *Queries) RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeSession, arg.LyDoThuHoi, arg.ID, arg.NguoiDungID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeUserSessions = `-- name: RevokeUserSessions :many
+UPDATE phien_dang_nhap
+SET dang_hoat_dong = FALSE,
+    thu_hoi_luc = CURRENT_TIMESTAMP,
+    ly_do_thu_hoi = $1,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = $2
+    AND dang_hoat_dong = TRUE
+    AND ($3::int IS NULL OR id <> $3::int)
+RETURNING id
+`
+
+type RevokeUserSessionsParams struct {
+	LyDoThuHoi  *string     `json:"ly_do_thu_hoi"`
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	NgoaiTruID  *int32      `json:"ngoai_tru_id"`
+}
+
+// Thu hồi mọi phiên đang hoạt động của người dùng, trừ phiên ngoai_tru_id (nếu có)
+func (q *Queries) RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, revokeUserSessions, arg.LyDoThuHoi, arg.NguoiDungID, arg.NgoaiTruID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSessionTokens = `-- name: RotateSessionTokens :one
+UPDATE phien_dang_nhap
+SET ma_token_truy_cap = $1,
+    ma_token_lam_moi = $2,
+    thoi_han_token = $3,
+    lan_lam_moi_cuoi = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $4
+    AND ma_token_lam_moi = $5
+    AND dang_hoat_dong = TRUE
+    AND thoi_han_token > CURRENT_TIMESTAMP
+RETURNING id, nguoi_dung_id, ma_token_truy_cap, ma_token_lam_moi, thoi_han_token, thong_tin_thiet_bi, dang_hoat_dong, ngay_tao, ngay_cap_nhat, dia_chi_ip, lan_lam_moi_cuoi, thu_hoi_luc, ly_do_thu_hoi
+`
+
+type RotateSessionTokensParams struct {
+	MaTokenTruyCap   string           `json:"ma_token_truy_cap"`
+	MaTokenLamMoiMoi string           `json:"ma_token_lam_moi_moi"`
+	ThoiHanToken     pgtype.Timestamp `json:"thoi_han_token"`
+	ID               int32            `json:"id"`
+	MaTokenLamMoiCu  string           `json:"ma_token_lam_moi_cu"`
+}
+
+// Xoay vòng token của phiên; chỉ thành công khi refresh token gửi lên là token hiện hành của phiên còn hiệu lực
+func (q *Queries) RotateSessionTokens(ctx context.Context, arg RotateSessionTokensParams) (PhienDangNhap, error) {
+	row := q.db.QueryRow(ctx, rotateSessionTokens,
+		arg.MaTokenTruyCap,
+		arg.MaTokenLamMoiMoi,
+		arg.ThoiHanToken,
+		arg.ID,
+		arg.MaTokenLamMoiCu,
+	)
+	var i PhienDangNhap
+	err := row.Scan(
+		&i.ID,
+		&i.NguoiDungID,
+		&i.MaTokenTruyCap,
+		&i.MaTokenLamMoi,
+		&i.ThoiHanToken,
+		&i.ThongTinThietBi,
+		&i.DangHoatDong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.DiaChiIp,
+		&i.LanLamMoiCuoi,
+		&i.ThuHoiLuc,
+		&i.LyDoThuHoi,
+	)
+	return i, err
+}
@@ -202,28 +202,8 @@ func (q *Queries) CreateSupplier(ctx context.Context, arg CreateSupplierParams)
 	return i, err
 }
 
-const feedbackReview = `-- name: FeedbackReview :one
-INSERT INTO phan_hoi_danh_gia (danh_gia_id, nguoi_dung_id, noi_dung)
-VALUES ($1, $2, $3)
-RETURNING id
-`
-
-type FeedbackReviewParams struct {
-	DanhGiaID   int32       `json:"danh_gia_id"`
-	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
-	NoiDung     string      `json:"noi_dung"`
-}
-
-// Phản hồi đánh giá
-func (q *Queries) FeedbackReview(ctx context.Context, arg FeedbackReviewParams) (int32, error) {
-	row := q.db.QueryRow(ctx, feedbackReview, arg.DanhGiaID, arg.NguoiDungID, arg.NoiDung)
-	var id int32
-	err := row.Scan(&id)
-	return id, err
-}
-
 const getActiveSuppliers = `-- name: GetActiveSuppliers :many
-SELECT nha_cung_cap.id, ten, dia_chi, website, mo_ta, logo, nam_thanh_lap, thanh_pho, quoc_gia, ma_so_thue, so_nhan_vien, giay_to_kinh_doanh, nguoi_dung.id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat FROM nha_cung_cap
+SELECT nha_cung_cap.id, ten, dia_chi, website, mo_ta, logo, nam_thanh_lap, thanh_pho, quoc_gia, ma_so_thue, so_nhan_vien, giay_to_kinh_doanh, nguoi_dung.id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau FROM nha_cung_cap
 JOIN nguoi_dung ON nguoi_dung.id = nha_cung_cap.id
 WHERE nguoi_dung.dang_hoat_dong = TRUE
 ORDER BY nguoi_dung.ngay_tao DESC
@@ -252,6 +232,7 @@ type GetActiveSuppliersRow struct {
 	XacThuc         *bool               `json:"xac_thuc"`
 	NgayTao         pgtype.Timestamp    `json:"ngay_tao"`
 	NgayCapNhat     pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	CoMatKhau       bool                `json:"co_mat_khau"`
 }
 
 func (q *Queries) GetActiveSuppliers(ctx context.Context) ([]GetActiveSuppliersRow, error) {
@@ -286,6 +267,7 @@ func (q *Queries) GetActiveSuppliers(ctx context.Context) ([]GetActiveSuppliersR
 			&i.XacThuc,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.CoMatKhau,
 		); err != nil {
 			return nil, err
 		}
@@ -298,7 +280,7 @@ func (q *Queries) GetActiveSuppliers(ctx context.Context) ([]GetActiveSuppliersR
 }
 
 const getAllSuppliersIncludingDeleted = `-- name: GetAllSuppliersIncludingDeleted :many
-SELECT nha_cung_cap.id, ten, dia_chi, website, mo_ta, logo, nam_thanh_lap, thanh_pho, quoc_gia, ma_so_thue, so_nhan_vien, giay_to_kinh_doanh, nguoi_dung.id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat FROM nha_cung_cap
+SELECT nha_cung_cap.id, ten, dia_chi, website, mo_ta, logo, nam_thanh_lap, thanh_pho, quoc_gia, ma_so_thue, so_nhan_vien, giay_to_kinh_doanh, nguoi_dung.id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau FROM nha_cung_cap
 JOIN nguoi_dung ON nguoi_dung.id = nha_cung_cap.id
 ORDER BY nguoi_dung.ngay_tao DESC
 `
@@ -326,6 +308,7 @@ type GetAllSuppliersIncludingDeletedRow struct {
 	XacThuc         *bool               `json:"xac_thuc"`
 	NgayTao         pgtype.Timestamp    `json:"ngay_tao"`
 	NgayCapNhat     pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	CoMatKhau       bool                `json:"co_mat_khau"`
 }
 
 func (q *Queries) GetAllSuppliersIncludingDeleted(ctx context.Context) ([]GetAllSuppliersIncludingDeletedRow, error) {
@@ -360,6 +343,7 @@ func (q *Queries) GetAllSuppliersIncludingDeleted(ctx context.Context) ([]GetAll
 			&i.XacThuc,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.CoMatKhau,
 		); err != nil {
 			return nil, err
 		}
@@ -482,7 +466,7 @@ func (q *Queries) GetFeedbackReview(ctx context.Context, danhGiaID int32) ([]Pha
 }
 
 const getMyTours = `-- name: GetMyTours :many
-SELECT id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat FROM tour
+SELECT id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id FROM tour
 WHERE nha_cung_cap_id = $1 
   AND dang_hoat_dong = TRUE 
   AND ($4::TEXT IS NULL OR $4::TEXT = '' OR trang_thai = $4::TEXT)
@@ -528,6 +512,7 @@ func (q *Queries) GetMyTours(ctx context.Context, arg GetMyToursParams) ([]Tour,
 			&i.DangHoatDong,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.ChinhSachHuyID,
 		); err != nil {
 			return nil, err
 		}
@@ -1917,7 +1902,7 @@ func (q *Queries) GetSupplierWithTourCount(ctx context.Context, email string) ([
 }
 
 const getSuppliersByStatus = `-- name: GetSuppliersByStatus :many
-SELECT nha_cung_cap.id, ten, dia_chi, website, mo_ta, logo, nam_thanh_lap, thanh_pho, quoc_gia, ma_so_thue, so_nhan_vien, giay_to_kinh_doanh, nguoi_dung.id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat FROM nha_cung_cap
+SELECT nha_cung_cap.id, ten, dia_chi, website, mo_ta, logo, nam_thanh_lap, thanh_pho, quoc_gia, ma_so_thue, so_nhan_vien, giay_to_kinh_doanh, nguoi_dung.id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau FROM nha_cung_cap
 JOIN nguoi_dung ON nguoi_dung.id = nha_cung_cap.id
 WHERE nguoi_dung.dang_hoat_dong = TRUE
 ORDER BY nguoi_dung.ngay_tao DESC
@@ -1946,6 +1931,7 @@ type GetSuppliersByStatusRow struct {
 	XacThuc         *bool               `json:"xac_thuc"`
 	NgayTao         pgtype.Timestamp    `json:"ngay_tao"`
 	NgayCapNhat     pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	CoMatKhau       bool                `json:"co_mat_khau"`
 }
 
 func (q *Queries) GetSuppliersByStatus(ctx context.Context) ([]GetSuppliersByStatusRow, error) {
@@ -1980,6 +1966,7 @@ func (q *Queries) GetSuppliersByStatus(ctx context.Context) ([]GetSuppliersBySta
 			&i.XacThuc,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.CoMatKhau,
 		); err != nil {
 			return nil, err
 		}
@@ -2028,7 +2015,7 @@ func (q *Queries) OptionTour(ctx context.Context, nhaCungCapID pgtype.UUID) ([]O
 }
 
 const searchSuppliers = `-- name: SearchSuppliers :many
-SELECT nha_cung_cap.id, ten, dia_chi, website, mo_ta, logo, nam_thanh_lap, thanh_pho, quoc_gia, ma_so_thue, so_nhan_vien, giay_to_kinh_doanh, nguoi_dung.id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat FROM nha_cung_cap
+SELECT nha_cung_cap.id, ten, dia_chi, website, mo_ta, logo, nam_thanh_lap, thanh_pho, quoc_gia, ma_so_thue, so_nhan_vien, giay_to_kinh_doanh, nguoi_dung.id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau FROM nha_cung_cap
 JOIN nguoi_dung ON nguoi_dung.id = nha_cung_cap.id
 WHERE nguoi_dung.dang_hoat_dong = TRUE AND nguoi_dung.email = $1
     AND (
@@ -2063,6 +2050,7 @@ type SearchSuppliersRow struct {
 	XacThuc         *bool               `json:"xac_thuc"`
 	NgayTao         pgtype.Timestamp    `json:"ngay_tao"`
 	NgayCapNhat     pgtype.Timestamp    `json:"ngay_cap_nhat"`
+	CoMatKhau       bool                `json:"co_mat_khau"`
 }
 
 func (q *Queries) SearchSuppliers(ctx context.Context, email string) ([]SearchSuppliersRow, error) {
@@ -2097,6 +2085,7 @@ func (q *Queries) SearchSuppliers(ctx context.Context, email string) ([]SearchSu
 			&i.XacThuc,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.CoMatKhau,
 		); err != nil {
 			return nil, err
 		}
@@ -2202,19 +2191,20 @@ func (q *Queries) UpdateSupplierStatus(ctx context.Context, id pgtype.UUID) (Nha
 const updateTourStatus = `-- name: UpdateTourStatus :one
 UPDATE tour
 SET
-    trang_thai = $2,
+    trang_thai = $1,
     ngay_cap_nhat = CURRENT_TIMESTAMP
-WHERE id = $1 AND dang_hoat_dong = TRUE
-RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat
+WHERE id = $2 AND nha_cung_cap_id = $3 AND dang_hoat_dong = TRUE
+RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 type UpdateTourStatusParams struct {
-	ID        int32   `json:"id"`
-	TrangThai *string `json:"trang_thai"`
+	TrangThai    *string     `json:"trang_thai"`
+	ID           int32       `json:"id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
 }
 
 func (q *Queries) UpdateTourStatus(ctx context.Context, arg UpdateTourStatusParams) (Tour, error) {
-	row := q.db.QueryRow(ctx, updateTourStatus, arg.ID, arg.TrangThai)
+	row := q.db.QueryRow(ctx, updateTourStatus, arg.TrangThai, arg.ID, arg.NhaCungCapID)
 	var i Tour
 	err := row.Scan(
 		&i.ID,
@@ -2232,6 +2222,7 @@ func (q *Queries) UpdateTourStatus(ctx context.Context, arg UpdateTourStatusPara
 		&i.DangHoatDong,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
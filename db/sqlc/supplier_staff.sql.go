@@ -0,0 +1,430 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: supplier_staff.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const activateInvitedUser = `-- name: ActivateInvitedUser :exec
+UPDATE nguoi_dung
+SET mat_khau_ma_hoa = $2,
+    dang_hoat_dong = TRUE,
+    xac_thuc = TRUE,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type ActivateInvitedUserParams struct {
+	ID           pgtype.UUID `json:"id"`
+	MatKhauMaHoa string      `json:"mat_khau_ma_hoa"`
+}
+
+// Đặt mật khẩu do nhân viên chọn và kích hoạt tài khoản khi nhận lời mời
+func (q *Queries) ActivateInvitedUser(ctx context.Context, arg ActivateInvitedUserParams) error {
+	_, err := q.db.Exec(ctx, activateInvitedUser, arg.ID, arg.MatKhauMaHoa)
+	return err
+}
+
+const activateSupplierStaff = `-- name: ActivateSupplierStaff :exec
+UPDATE nhan_vien_nha_cung_cap
+SET trang_thai = 'hoat_dong',
+    ma_loi_moi_bam = NULL,
+    han_loi_moi = NULL,
+    ngay_tham_gia = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) ActivateSupplierStaff(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, activateSupplierStaff, id)
+	return err
+}
+
+const createSupplierStaff = `-- name: CreateSupplierStaff :one
+INSERT INTO nhan_vien_nha_cung_cap (
+    nha_cung_cap_id, nguoi_dung_id, quyen, ma_loi_moi_bam, han_loi_moi, nguoi_moi
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, nha_cung_cap_id, nguoi_dung_id, quyen, trang_thai, ma_loi_moi_bam, han_loi_moi, nguoi_moi, ngay_tham_gia, ngay_tao, ngay_cap_nhat
+`
+
+type CreateSupplierStaffParams struct {
+	NhaCungCapID pgtype.UUID      `json:"nha_cung_cap_id"`
+	NguoiDungID  pgtype.UUID      `json:"nguoi_dung_id"`
+	Quyen        []string         `json:"quyen"`
+	MaLoiMoiBam  *string          `json:"ma_loi_moi_bam"`
+	HanLoiMoi    pgtype.Timestamp `json:"han_loi_moi"`
+	NguoiMoi     pgtype.UUID      `json:"nguoi_moi"`
+}
+
+func (q *Queries) CreateSupplierStaff(ctx context.Context, arg CreateSupplierStaffParams) (NhanVienNhaCungCap, error) {
+	row := q.db.QueryRow(ctx, createSupplierStaff,
+		arg.NhaCungCapID,
+		arg.NguoiDungID,
+		arg.Quyen,
+		arg.MaLoiMoiBam,
+		arg.HanLoiMoi,
+		arg.NguoiMoi,
+	)
+	var i NhanVienNhaCungCap
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.NguoiDungID,
+		&i.Quyen,
+		&i.TrangThai,
+		&i.MaLoiMoiBam,
+		&i.HanLoiMoi,
+		&i.NguoiMoi,
+		&i.NgayThamGia,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const deactivateSupplierStaff = `-- name: DeactivateSupplierStaff :one
+UPDATE nhan_vien_nha_cung_cap
+SET trang_thai = 'vo_hieu',
+    ma_loi_moi_bam = NULL,
+    han_loi_moi = NULL,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1
+    AND nha_cung_cap_id = $2
+    AND trang_thai <> 'vo_hieu'
+RETURNING id, nha_cung_cap_id, nguoi_dung_id, quyen, trang_thai, ma_loi_moi_bam, han_loi_moi, nguoi_moi, ngay_tham_gia, ngay_tao, ngay_cap_nhat
+`
+
+type DeactivateSupplierStaffParams struct {
+	ID           int32       `json:"id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+func (q *Queries) DeactivateSupplierStaff(ctx context.Context, arg DeactivateSupplierStaffParams) (NhanVienNhaCungCap, error) {
+	row := q.db.QueryRow(ctx, deactivateSupplierStaff, arg.ID, arg.NhaCungCapID)
+	var i NhanVienNhaCungCap
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.NguoiDungID,
+		&i.Quyen,
+		&i.TrangThai,
+		&i.MaLoiMoiBam,
+		&i.HanLoiMoi,
+		&i.NguoiMoi,
+		&i.NgayThamGia,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const deactivateUser = `-- name: DeactivateUser :exec
+UPDATE nguoi_dung
+SET dang_hoat_dong = FALSE,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deactivateUser, id)
+	return err
+}
+
+const getSupplierContext = `-- name: GetSupplierContext :one
+
+SELECT ncc.id AS nha_cung_cap_id, TRUE AS chu_so_huu
+FROM nha_cung_cap ncc
+WHERE ncc.id = $1
+UNION ALL
+SELECT nv.nha_cung_cap_id, FALSE AS chu_so_huu
+FROM nhan_vien_nha_cung_cap nv
+WHERE nv.nguoi_dung_id = $1
+    AND nv.trang_thai = 'hoat_dong'
+LIMIT 1
+`
+
+type GetSupplierContextRow struct {
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+	ChuSoHuu     bool        `json:"chu_so_huu"`
+}
+
+// ===========================================
+// NHÂN VIÊN NHÀ CUNG CẤP
+// ===========================================
+// Nhà cung cấp mà người dùng thuộc về: chủ sở hữu (id = nha_cung_cap.id) hoặc nhân viên đang hoạt động
+func (q *Queries) GetSupplierContext(ctx context.Context, nguoiDungID pgtype.UUID) (GetSupplierContextRow, error) {
+	row := q.db.QueryRow(ctx, getSupplierContext, nguoiDungID)
+	var i GetSupplierContextRow
+	err := row.Scan(&i.NhaCungCapID, &i.ChuSoHuu)
+	return i, err
+}
+
+const getSupplierStaff = `-- name: GetSupplierStaff :many
+SELECT nv.id, nv.nha_cung_cap_id, nv.nguoi_dung_id, nv.quyen, nv.trang_thai, nv.han_loi_moi,
+    nv.nguoi_moi, nv.ngay_tham_gia, nv.ngay_tao, nv.ngay_cap_nhat,
+    nd.ho_ten, nd.email, nd.so_dien_thoai
+FROM nhan_vien_nha_cung_cap nv
+JOIN nguoi_dung nd ON nd.id = nv.nguoi_dung_id
+WHERE nv.nha_cung_cap_id = $1
+ORDER BY nv.ngay_tao DESC
+`
+
+type GetSupplierStaffRow struct {
+	ID           int32                `json:"id"`
+	NhaCungCapID pgtype.UUID          `json:"nha_cung_cap_id"`
+	NguoiDungID  pgtype.UUID          `json:"nguoi_dung_id"`
+	Quyen        []string             `json:"quyen"`
+	TrangThai    TrangThaiNhanVienNcc `json:"trang_thai"`
+	HanLoiMoi    pgtype.Timestamp     `json:"han_loi_moi"`
+	NguoiMoi     pgtype.UUID          `json:"nguoi_moi"`
+	NgayThamGia  pgtype.Timestamp     `json:"ngay_tham_gia"`
+	NgayTao      pgtype.Timestamp     `json:"ngay_tao"`
+	NgayCapNhat  pgtype.Timestamp     `json:"ngay_cap_nhat"`
+	HoTen        string               `json:"ho_ten"`
+	Email        string               `json:"email"`
+	SoDienThoai  *string              `json:"so_dien_thoai"`
+}
+
+func (q *Queries) GetSupplierStaff(ctx context.Context, nhaCungCapID pgtype.UUID) ([]GetSupplierStaffRow, error) {
+	rows, err := q.db.Query(ctx, getSupplierStaff, nhaCungCapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSupplierStaffRow
+	for rows.Next() {
+		var i GetSupplierStaffRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.NhaCungCapID,
+			&i.NguoiDungID,
+			&i.Quyen,
+			&i.TrangThai,
+			&i.HanLoiMoi,
+			&i.NguoiMoi,
+			&i.NgayThamGia,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+			&i.HoTen,
+			&i.Email,
+			&i.SoDienThoai,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSupplierStaffByID = `-- name: GetSupplierStaffByID :one
+SELECT nv.id, nv.nha_cung_cap_id, nv.nguoi_dung_id, nv.quyen, nv.trang_thai, nv.han_loi_moi,
+    nv.nguoi_moi, nv.ngay_tham_gia, nv.ngay_tao, nv.ngay_cap_nhat,
+    nd.ho_ten, nd.email, nd.so_dien_thoai
+FROM nhan_vien_nha_cung_cap nv
+JOIN nguoi_dung nd ON nd.id = nv.nguoi_dung_id
+WHERE nv.id = $1 AND nv.nha_cung_cap_id = $2
+`
+
+type GetSupplierStaffByIDParams struct {
+	ID           int32       `json:"id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+type GetSupplierStaffByIDRow struct {
+	ID           int32                `json:"id"`
+	NhaCungCapID pgtype.UUID          `json:"nha_cung_cap_id"`
+	NguoiDungID  pgtype.UUID          `json:"nguoi_dung_id"`
+	Quyen        []string             `json:"quyen"`
+	TrangThai    TrangThaiNhanVienNcc `json:"trang_thai"`
+	HanLoiMoi    pgtype.Timestamp     `json:"han_loi_moi"`
+	NguoiMoi     pgtype.UUID          `json:"nguoi_moi"`
+	NgayThamGia  pgtype.Timestamp     `json:"ngay_tham_gia"`
+	NgayTao      pgtype.Timestamp     `json:"ngay_tao"`
+	NgayCapNhat  pgtype.Timestamp     `json:"ngay_cap_nhat"`
+	HoTen        string               `json:"ho_ten"`
+	Email        string               `json:"email"`
+	SoDienThoai  *string              `json:"so_dien_thoai"`
+}
+
+func (q *Queries) GetSupplierStaffByID(ctx context.Context, arg GetSupplierStaffByIDParams) (GetSupplierStaffByIDRow, error) {
+	row := q.db.QueryRow(ctx, getSupplierStaffByID, arg.ID, arg.NhaCungCapID)
+	var i GetSupplierStaffByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.NguoiDungID,
+		&i.Quyen,
+		&i.TrangThai,
+		&i.HanLoiMoi,
+		&i.NguoiMoi,
+		&i.NgayThamGia,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.HoTen,
+		&i.Email,
+		&i.SoDienThoai,
+	)
+	return i, err
+}
+
+const getSupplierStaffByInvitation = `-- name: GetSupplierStaffByInvitation :one
+SELECT nv.id, nv.nha_cung_cap_id, nv.nguoi_dung_id, nv.quyen, nv.trang_thai, nv.ma_loi_moi_bam, nv.han_loi_moi, nv.nguoi_moi, nv.ngay_tham_gia, nv.ngay_tao, nv.ngay_cap_nhat, nd.email
+FROM nhan_vien_nha_cung_cap nv
+JOIN nguoi_dung nd ON nd.id = nv.nguoi_dung_id
+WHERE nv.ma_loi_moi_bam = $1
+    AND nv.trang_thai = 'cho_kich_hoat'
+    AND nv.han_loi_moi > CURRENT_TIMESTAMP
+`
+
+type GetSupplierStaffByInvitationRow struct {
+	ID           int32                `json:"id"`
+	NhaCungCapID pgtype.UUID          `json:"nha_cung_cap_id"`
+	NguoiDungID  pgtype.UUID          `json:"nguoi_dung_id"`
+	Quyen        []string             `json:"quyen"`
+	TrangThai    TrangThaiNhanVienNcc `json:"trang_thai"`
+	MaLoiMoiBam  *string              `json:"ma_loi_moi_bam"`
+	HanLoiMoi    pgtype.Timestamp     `json:"han_loi_moi"`
+	NguoiMoi     pgtype.UUID          `json:"nguoi_moi"`
+	NgayThamGia  pgtype.Timestamp     `json:"ngay_tham_gia"`
+	NgayTao      pgtype.Timestamp     `json:"ngay_tao"`
+	NgayCapNhat  pgtype.Timestamp     `json:"ngay_cap_nhat"`
+	Email        string               `json:"email"`
+}
+
+// Lời mời còn hiệu lực theo mã đã băm
+func (q *Queries) GetSupplierStaffByInvitation(ctx context.Context, maLoiMoiBam *string) (GetSupplierStaffByInvitationRow, error) {
+	row := q.db.QueryRow(ctx, getSupplierStaffByInvitation, maLoiMoiBam)
+	var i GetSupplierStaffByInvitationRow
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.NguoiDungID,
+		&i.Quyen,
+		&i.TrangThai,
+		&i.MaLoiMoiBam,
+		&i.HanLoiMoi,
+		&i.NguoiMoi,
+		&i.NgayThamGia,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.Email,
+	)
+	return i, err
+}
+
+const renewSupplierStaffInvitation = `-- name: RenewSupplierStaffInvitation :one
+UPDATE nhan_vien_nha_cung_cap
+SET ma_loi_moi_bam = $1,
+    han_loi_moi = $2,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $3
+    AND nha_cung_cap_id = $4
+    AND trang_thai = 'cho_kich_hoat'
+RETURNING id, nha_cung_cap_id, nguoi_dung_id, quyen, trang_thai, ma_loi_moi_bam, han_loi_moi, nguoi_moi, ngay_tham_gia, ngay_tao, ngay_cap_nhat
+`
+
+type RenewSupplierStaffInvitationParams struct {
+	MaLoiMoiBam  *string          `json:"ma_loi_moi_bam"`
+	HanLoiMoi    pgtype.Timestamp `json:"han_loi_moi"`
+	ID           int32            `json:"id"`
+	NhaCungCapID pgtype.UUID      `json:"nha_cung_cap_id"`
+}
+
+// Tạo lại mã mời cho nhân viên chưa kích hoạt (mã cũ hết hiệu lực)
+func (q *Queries) RenewSupplierStaffInvitation(ctx context.Context, arg RenewSupplierStaffInvitationParams) (NhanVienNhaCungCap, error) {
+	row := q.db.QueryRow(ctx, renewSupplierStaffInvitation,
+		arg.MaLoiMoiBam,
+		arg.HanLoiMoi,
+		arg.ID,
+		arg.NhaCungCapID,
+	)
+	var i NhanVienNhaCungCap
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.NguoiDungID,
+		&i.Quyen,
+		&i.TrangThai,
+		&i.MaLoiMoiBam,
+		&i.HanLoiMoi,
+		&i.NguoiMoi,
+		&i.NgayThamGia,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const replySupplierReview = `-- name: ReplySupplierReview :one
+INSERT INTO phan_hoi_danh_gia (danh_gia_id, nguoi_dung_id, noi_dung)
+SELECT dg.id, $1, $2
+FROM danh_gia dg
+JOIN tour t ON t.id = dg.tour_id
+WHERE dg.id = $3
+    AND t.nha_cung_cap_id = $4
+RETURNING id
+`
+
+type ReplySupplierReviewParams struct {
+	NguoiDungID  pgtype.UUID `json:"nguoi_dung_id"`
+	NoiDung      string      `json:"noi_dung"`
+	DanhGiaID    int32       `json:"danh_gia_id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+// Trả lời đánh giá, chỉ khi đánh giá thuộc tour của nhà cung cấp
+func (q *Queries) ReplySupplierReview(ctx context.Context, arg ReplySupplierReviewParams) (int32, error) {
+	row := q.db.QueryRow(ctx, replySupplierReview,
+		arg.NguoiDungID,
+		arg.NoiDung,
+		arg.DanhGiaID,
+		arg.NhaCungCapID,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateSupplierStaffPermissions = `-- name: UpdateSupplierStaffPermissions :one
+UPDATE nhan_vien_nha_cung_cap
+SET quyen = $1,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $2
+    AND nha_cung_cap_id = $3
+    AND trang_thai <> 'vo_hieu'
+RETURNING id, nha_cung_cap_id, nguoi_dung_id, quyen, trang_thai, ma_loi_moi_bam, han_loi_moi, nguoi_moi, ngay_tham_gia, ngay_tao, ngay_cap_nhat
+`
+
+type UpdateSupplierStaffPermissionsParams struct {
+	Quyen        []string    `json:"quyen"`
+	ID           int32       `json:"id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+}
+
+func (q *Queries) UpdateSupplierStaffPermissions(ctx context.Context, arg UpdateSupplierStaffPermissionsParams) (NhanVienNhaCungCap, error) {
+	row := q.db.QueryRow(ctx, updateSupplierStaffPermissions, arg.Quyen, arg.ID, arg.NhaCungCapID)
+	var i NhanVienNhaCungCap
+	err := row.Scan(
+		&i.ID,
+		&i.NhaCungCapID,
+		&i.NguoiDungID,
+		&i.Quyen,
+		&i.TrangThai,
+		&i.MaLoiMoiBam,
+		&i.HanLoiMoi,
+		&i.NguoiMoi,
+		&i.NgayThamGia,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
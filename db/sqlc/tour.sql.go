@@ -242,7 +242,7 @@ INSERT INTO tour (
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 )
-RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat
+RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 type CreateTourParams struct {
@@ -293,6 +293,7 @@ func (q *Queries) CreateTour(ctx context.Context, arg CreateTourParams) (Tour, e
 		&i.DangHoatDong,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -322,6 +323,16 @@ func (q *Queries) DeleteTour(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteTourDestinationsByTour = `-- name: DeleteTourDestinationsByTour :exec
+DELETE FROM tour_diem_den
+WHERE tour_id = $1
+`
+
+func (q *Queries) DeleteTourDestinationsByTour(ctx context.Context, tourID int32) error {
+	_, err := q.db.Exec(ctx, deleteTourDestinationsByTour, tourID)
+	return err
+}
+
 const deleteTourImage = `-- name: DeleteTourImage :exec
 DELETE FROM anh_tour
 WHERE id = $1 AND tour_id = $2
@@ -337,6 +348,16 @@ func (q *Queries) DeleteTourImage(ctx context.Context, arg DeleteTourImageParams
 	return err
 }
 
+const deleteTourImagesByTour = `-- name: DeleteTourImagesByTour :exec
+DELETE FROM anh_tour
+WHERE tour_id = $1
+`
+
+func (q *Queries) DeleteTourImagesByTour(ctx context.Context, tourID int32) error {
+	_, err := q.db.Exec(ctx, deleteTourImagesByTour, tourID)
+	return err
+}
+
 const filterTours = `-- name: FilterTours :many
 SELECT
   t.id,
@@ -638,6 +659,26 @@ func (q *Queries) GetAllTourCategory(ctx context.Context) ([]GetAllTourCategoryR
 	return items, nil
 }
 
+const getDiscountTourByID = `-- name: GetDiscountTourByID :one
+SELECT id, tour_id, phan_tram, ngay_bat_dau, ngay_ket_thuc, ngay_tao, ngay_cap_nhat FROM giam_gia_tour
+WHERE id = $1
+`
+
+func (q *Queries) GetDiscountTourByID(ctx context.Context, id int32) (GiamGiaTour, error) {
+	row := q.db.QueryRow(ctx, getDiscountTourByID, id)
+	var i GiamGiaTour
+	err := row.Scan(
+		&i.ID,
+		&i.TourID,
+		&i.PhanTram,
+		&i.NgayBatDau,
+		&i.NgayKetThuc,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
 const getDiscountsByTourID = `-- name: GetDiscountsByTourID :many
 SELECT id, tour_id, phan_tram, ngay_bat_dau, ngay_ket_thuc, ngay_tao, ngay_cap_nhat FROM giam_gia_tour
 WHERE tour_id = $1
@@ -748,6 +789,35 @@ func (q *Queries) GetFeaturedTours(ctx context.Context, limit int32) ([]GetFeatu
 	return items, nil
 }
 
+const getTourByID = `-- name: GetTourByID :one
+SELECT id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id FROM tour
+WHERE id = $1
+`
+
+func (q *Queries) GetTourByID(ctx context.Context, id int32) (Tour, error) {
+	row := q.db.QueryRow(ctx, getTourByID, id)
+	var i Tour
+	err := row.Scan(
+		&i.ID,
+		&i.TieuDe,
+		&i.MoTa,
+		&i.DanhMucID,
+		&i.SoNgay,
+		&i.SoDem,
+		&i.GiaNguoiLon,
+		&i.GiaTreEm,
+		&i.DonViTienTe,
+		&i.TrangThai,
+		&i.NoiBat,
+		&i.NhaCungCapID,
+		&i.DangHoatDong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
+	)
+	return i, err
+}
+
 const getTourDestinations = `-- name: GetTourDestinations :many
 SELECT 
     dd.id, dd.ten, dd.tinh, dd.quoc_gia, dd.khu_vuc, dd.iso2, dd.iso3, dd.mo_ta, dd.anh, dd.vi_do, dd.kinh_do, dd.ngay_tao, dd.ngay_cap_nhat,
@@ -949,7 +1019,9 @@ tour_config AS (
     SELECT 
         tour_id,
         so_nho_nhat,
-        so_lon_nhat
+        so_lon_nhat,
+        tuoi_tre_em_toi_thieu,
+        tuoi_tre_em_toi_da
     FROM cau_hinh_nhom_tour
     WHERE tour_id = $1
 )
@@ -963,7 +1035,9 @@ SELECT
     tdisc.ngay_bat_dau as giam_gia_tu,
     tdisc.ngay_ket_thuc as giam_gia_den,
     tc.so_nho_nhat,
-    tc.so_lon_nhat
+    tc.so_lon_nhat,
+    tc.tuoi_tre_em_toi_thieu,
+    tc.tuoi_tre_em_toi_da
 FROM tour_info ti
 LEFT JOIN tour_images timg ON ti.id = timg.tour_id
 LEFT JOIN tour_destinations td ON ti.id = td.tour_id
@@ -974,32 +1048,34 @@ LEFT JOIN tour_config tc ON ti.id = tc.tour_id
 `
 
 type GetTourDetailByIDRow struct {
-	ID              int32            `json:"id"`
-	TieuDe          string           `json:"tieu_de"`
-	MoTa            *string          `json:"mo_ta"`
-	DanhMucID       *int32           `json:"danh_muc_id"`
-	SoNgay          int32            `json:"so_ngay"`
-	SoDem           int32            `json:"so_dem"`
-	GiaNguoiLon     pgtype.Numeric   `json:"gia_nguoi_lon"`
-	GiaTreEm        pgtype.Numeric   `json:"gia_tre_em"`
-	DonViTienTe     *string          `json:"don_vi_tien_te"`
-	TrangThai       *string          `json:"trang_thai"`
-	NoiBat          *bool            `json:"noi_bat"`
-	NhaCungCapID    pgtype.UUID      `json:"nha_cung_cap_id"`
-	NgayTao         pgtype.Timestamp `json:"ngay_tao"`
-	NgayCapNhat     pgtype.Timestamp `json:"ngay_cap_nhat"`
-	TenDanhMuc      *string          `json:"ten_danh_muc"`
-	TenNhaCungCap   *string          `json:"ten_nha_cung_cap"`
-	LogoNcc         *string          `json:"logo_ncc"`
-	Images          []byte           `json:"images"`
-	Destinations    []byte           `json:"destinations"`
-	Itinerary       []byte           `json:"itinerary"`
-	Departures      []byte           `json:"departures"`
-	GiamGiaPhanTram pgtype.Numeric   `json:"giam_gia_phan_tram"`
-	GiamGiaTu       pgtype.Date      `json:"giam_gia_tu"`
-	GiamGiaDen      pgtype.Date      `json:"giam_gia_den"`
-	SoNhoNhat       *int32           `json:"so_nho_nhat"`
-	SoLonNhat       *int32           `json:"so_lon_nhat"`
+	ID                int32            `json:"id"`
+	TieuDe            string           `json:"tieu_de"`
+	MoTa              *string          `json:"mo_ta"`
+	DanhMucID         *int32           `json:"danh_muc_id"`
+	SoNgay            int32            `json:"so_ngay"`
+	SoDem             int32            `json:"so_dem"`
+	GiaNguoiLon       pgtype.Numeric   `json:"gia_nguoi_lon"`
+	GiaTreEm          pgtype.Numeric   `json:"gia_tre_em"`
+	DonViTienTe       *string          `json:"don_vi_tien_te"`
+	TrangThai         *string          `json:"trang_thai"`
+	NoiBat            *bool            `json:"noi_bat"`
+	NhaCungCapID      pgtype.UUID      `json:"nha_cung_cap_id"`
+	NgayTao           pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat       pgtype.Timestamp `json:"ngay_cap_nhat"`
+	TenDanhMuc        *string          `json:"ten_danh_muc"`
+	TenNhaCungCap     *string          `json:"ten_nha_cung_cap"`
+	LogoNcc           *string          `json:"logo_ncc"`
+	Images            []byte           `json:"images"`
+	Destinations      []byte           `json:"destinations"`
+	Itinerary         []byte           `json:"itinerary"`
+	Departures        []byte           `json:"departures"`
+	GiamGiaPhanTram   pgtype.Numeric   `json:"giam_gia_phan_tram"`
+	GiamGiaTu         pgtype.Date      `json:"giam_gia_tu"`
+	GiamGiaDen        pgtype.Date      `json:"giam_gia_den"`
+	SoNhoNhat         *int32           `json:"so_nho_nhat"`
+	SoLonNhat         *int32           `json:"so_lon_nhat"`
+	TuoiTreEmToiThieu *int32           `json:"tuoi_tre_em_toi_thieu"`
+	TuoiTreEmToiDa    *int32           `json:"tuoi_tre_em_toi_da"`
 }
 
 func (q *Queries) GetTourDetailByID(ctx context.Context, id int32) (GetTourDetailByIDRow, error) {
@@ -1032,6 +1108,8 @@ func (q *Queries) GetTourDetailByID(ctx context.Context, id int32) (GetTourDetai
 		&i.GiamGiaDen,
 		&i.SoNhoNhat,
 		&i.SoLonNhat,
+		&i.TuoiTreEmToiThieu,
+		&i.TuoiTreEmToiDa,
 	)
 	return i, err
 }
@@ -1279,7 +1357,7 @@ func (q *Queries) GetToursByCountryCode(ctx context.Context, arg GetToursByCount
 }
 
 const getToursBySupplier = `-- name: GetToursBySupplier :many
-SELECT id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat FROM tour
+SELECT id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id FROM tour
 WHERE nha_cung_cap_id = $1
 ORDER BY ngay_tao DESC
 LIMIT $2 OFFSET $3
@@ -1316,6 +1394,7 @@ func (q *Queries) GetToursBySupplier(ctx context.Context, arg GetToursBySupplier
 			&i.DangHoatDong,
 			&i.NgayTao,
 			&i.NgayCapNhat,
+			&i.ChinhSachHuyID,
 		); err != nil {
 			return nil, err
 		}
@@ -1580,7 +1659,7 @@ SET
     dang_hoat_dong = NOT dang_hoat_dong,
     ngay_cap_nhat = NOW()
 WHERE id = $1
-RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat
+RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 func (q *Queries) ToggleTourActive(ctx context.Context, id int32) (Tour, error) {
@@ -1602,6 +1681,7 @@ func (q *Queries) ToggleTourActive(ctx context.Context, id int32) (Tour, error)
 		&i.DangHoatDong,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -1664,7 +1744,7 @@ SET
     dang_hoat_dong = COALESCE($13, dang_hoat_dong),
     ngay_cap_nhat = NOW()
 WHERE id = $1
-RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat
+RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
 `
 
 type UpdateTourParams struct {
@@ -1716,6 +1796,7 @@ func (q *Queries) UpdateTour(ctx context.Context, arg UpdateTourParams) (Tour, e
 		&i.DangHoatDong,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
 	)
 	return i, err
 }
@@ -0,0 +1,124 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: tour_embedding.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+const getStaleTourEmbeddingSources = `-- name: GetStaleTourEmbeddingSources :many
+
+WITH src AS (
+  SELECT
+    t.id,
+    LEFT(concat_ws(E'\n',
+      t.tieu_de,
+      t.mo_ta,
+      'Danh mục: ' || dm.ten,
+      'Thời lượng: ' || t.so_ngay || ' ngày ' || t.so_dem || ' đêm',
+      'Điểm đến: ' || (
+        SELECT string_agg(concat_ws(', ', d.ten, d.tinh, d.quoc_gia), '; ' ORDER BY tdd.thu_tu_tham_quan, d.id)
+        FROM tour_diem_den tdd
+        JOIN diem_den d ON d.id = tdd.diem_den_id
+        WHERE tdd.tour_id = t.id
+      ),
+      (
+        SELECT string_agg(
+          concat_ws(E'\n',
+            'Ngày ' || lt.ngay_thu || ': ' || lt.tieu_de,
+            lt.mo_ta,
+            lt.dia_diem,
+            (
+              SELECT string_agg(concat_ws(': ', hd.ten, hd.mo_ta), '; ' ORDER BY hd.thu_tu, hd.id)
+              FROM hoat_dong_trong_ngay hd
+              WHERE hd.lich_trinh_id = lt.id
+            )
+          ), E'\n' ORDER BY lt.ngay_thu, lt.id)
+        FROM lich_trinh lt
+        WHERE lt.tour_id = t.id
+      )
+    ), 8000)::TEXT AS noi_dung
+  FROM tour t
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  WHERE t.dang_hoat_dong = TRUE
+    AND ($3::INT IS NULL OR t.id = $3::INT)
+)
+SELECT src.id, src.noi_dung, md5(src.noi_dung)::TEXT AS noi_dung_hash
+FROM src
+LEFT JOIN tour_embeddings e ON e.tour_id = src.id
+WHERE e.tour_id IS NULL
+  OR e.embedding IS NULL
+  OR e.mo_hinh IS DISTINCT FROM $1::TEXT
+  OR e.noi_dung_hash IS DISTINCT FROM md5(src.noi_dung)
+ORDER BY src.id
+LIMIT $2
+`
+
+type GetStaleTourEmbeddingSourcesParams struct {
+	MoHinh string `json:"mo_hinh"`
+	Limit  int32  `json:"limit"`
+	TourID *int32 `json:"tour_id"`
+}
+
+type GetStaleTourEmbeddingSourcesRow struct {
+	ID          int32  `json:"id"`
+	NoiDung     string `json:"noi_dung"`
+	NoiDungHash string `json:"noi_dung_hash"`
+}
+
+// Văn bản nguồn để tạo embedding cho tour: tiêu đề, mô tả, danh mục, thời lượng, điểm đến, lịch trình và hoạt động.
+// noi_dung_hash = md5(noi_dung) dùng để biết embedding đã lỗi thời hay chưa
+// Lấy các tour chưa có embedding, có nội dung đã thay đổi hoặc được tạo bằng mô hình khác.
+// tour_id = NULL: quét toàn bộ tour đang hoạt động (đồng bộ định kỳ)
+func (q *Queries) GetStaleTourEmbeddingSources(ctx context.Context, arg GetStaleTourEmbeddingSourcesParams) ([]GetStaleTourEmbeddingSourcesRow, error) {
+	rows, err := q.db.Query(ctx, getStaleTourEmbeddingSources, arg.MoHinh, arg.Limit, arg.TourID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStaleTourEmbeddingSourcesRow
+	for rows.Next() {
+		var i GetStaleTourEmbeddingSourcesRow
+		if err := rows.Scan(&i.ID, &i.NoiDung, &i.NoiDungHash); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTourEmbedding = `-- name: UpsertTourEmbedding :exec
+INSERT INTO tour_embeddings (tour_id, embedding, mo_hinh, noi_dung_hash)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (tour_id)
+DO UPDATE SET
+    embedding = EXCLUDED.embedding,
+    mo_hinh = EXCLUDED.mo_hinh,
+    noi_dung_hash = EXCLUDED.noi_dung_hash,
+    ngay_cap_nhat = NOW()
+`
+
+type UpsertTourEmbeddingParams struct {
+	TourID      int32            `json:"tour_id"`
+	Embedding   *pgvector.Vector `json:"embedding"`
+	MoHinh      *string          `json:"mo_hinh"`
+	NoiDungHash *string          `json:"noi_dung_hash"`
+}
+
+func (q *Queries) UpsertTourEmbedding(ctx context.Context, arg UpsertTourEmbeddingParams) error {
+	_, err := q.db.Exec(ctx, upsertTourEmbedding,
+		arg.TourID,
+		arg.Embedding,
+		arg.MoHinh,
+		arg.NoiDungHash,
+	)
+	return err
+}
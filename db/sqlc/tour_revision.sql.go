@@ -0,0 +1,450 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: tour_revision.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const cancelPendingTourRevisions = `-- name: CancelPendingTourRevisions :execrows
+UPDATE phien_ban_tour
+SET trang_thai = 'da_huy'
+WHERE tour_id = $1 AND trang_thai = 'cho_duyet'
+`
+
+func (q *Queries) CancelPendingTourRevisions(ctx context.Context, tourID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelPendingTourRevisions, tourID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createTourRevision = `-- name: CreateTourRevision :one
+INSERT INTO phien_ban_tour (tour_id, so_phien_ban, loai, noi_dung, ghi_chu, nguoi_gui_id)
+VALUES (
+    $1,
+    (SELECT COALESCE(MAX(pb.so_phien_ban), 0) + 1 FROM phien_ban_tour pb WHERE pb.tour_id = $1),
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING id, tour_id, so_phien_ban, loai, trang_thai, noi_dung, ghi_chu, nguoi_gui_id, nguoi_duyet_id, nhan_xet_duyet, ngay_tao, ngay_duyet
+`
+
+type CreateTourRevisionParams struct {
+	TourID     int32       `json:"tour_id"`
+	Loai       string      `json:"loai"`
+	NoiDung    []byte      `json:"noi_dung"`
+	GhiChu     *string     `json:"ghi_chu"`
+	NguoiGuiID pgtype.UUID `json:"nguoi_gui_id"`
+}
+
+func (q *Queries) CreateTourRevision(ctx context.Context, arg CreateTourRevisionParams) (PhienBanTour, error) {
+	row := q.db.QueryRow(ctx, createTourRevision,
+		arg.TourID,
+		arg.Loai,
+		arg.NoiDung,
+		arg.GhiChu,
+		arg.NguoiGuiID,
+	)
+	var i PhienBanTour
+	err := row.Scan(
+		&i.ID,
+		&i.TourID,
+		&i.SoPhienBan,
+		&i.Loai,
+		&i.TrangThai,
+		&i.NoiDung,
+		&i.GhiChu,
+		&i.NguoiGuiID,
+		&i.NguoiDuyetID,
+		&i.NhanXetDuyet,
+		&i.NgayTao,
+		&i.NgayDuyet,
+	)
+	return i, err
+}
+
+const getPendingTourRevision = `-- name: GetPendingTourRevision :one
+SELECT id, tour_id, so_phien_ban, loai, trang_thai, noi_dung, ghi_chu, nguoi_gui_id, nguoi_duyet_id, nhan_xet_duyet, ngay_tao, ngay_duyet FROM phien_ban_tour
+WHERE tour_id = $1 AND trang_thai = 'cho_duyet'
+`
+
+func (q *Queries) GetPendingTourRevision(ctx context.Context, tourID int32) (PhienBanTour, error) {
+	row := q.db.QueryRow(ctx, getPendingTourRevision, tourID)
+	var i PhienBanTour
+	err := row.Scan(
+		&i.ID,
+		&i.TourID,
+		&i.SoPhienBan,
+		&i.Loai,
+		&i.TrangThai,
+		&i.NoiDung,
+		&i.GhiChu,
+		&i.NguoiGuiID,
+		&i.NguoiDuyetID,
+		&i.NhanXetDuyet,
+		&i.NgayTao,
+		&i.NgayDuyet,
+	)
+	return i, err
+}
+
+const getTourContentSnapshot = `-- name: GetTourContentSnapshot :one
+SELECT tour_noi_dung($1::INT)::JSONB AS noi_dung
+`
+
+// Nội dung hiện tại của tour theo định dạng models.CreateTourRequest
+func (q *Queries) GetTourContentSnapshot(ctx context.Context, tourID int32) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getTourContentSnapshot, tourID)
+	var noi_dung []byte
+	err := row.Scan(&noi_dung)
+	return noi_dung, err
+}
+
+const getTourForUpdate = `-- name: GetTourForUpdate :one
+
+SELECT id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id FROM tour
+WHERE id = $1
+FOR UPDATE
+`
+
+// ===========================================
+// KIỂM DUYỆT TOUR & PHIÊN BẢN NỘI DUNG
+// ===========================================
+// Khóa dòng tour trong transaction gửi / duyệt phiên bản
+func (q *Queries) GetTourForUpdate(ctx context.Context, id int32) (Tour, error) {
+	row := q.db.QueryRow(ctx, getTourForUpdate, id)
+	var i Tour
+	err := row.Scan(
+		&i.ID,
+		&i.TieuDe,
+		&i.MoTa,
+		&i.DanhMucID,
+		&i.SoNgay,
+		&i.SoDem,
+		&i.GiaNguoiLon,
+		&i.GiaTreEm,
+		&i.DonViTienTe,
+		&i.TrangThai,
+		&i.NoiBat,
+		&i.NhaCungCapID,
+		&i.DangHoatDong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
+	)
+	return i, err
+}
+
+const getTourRevisionByID = `-- name: GetTourRevisionByID :one
+SELECT
+    pb.id, pb.tour_id, pb.so_phien_ban, pb.loai, pb.trang_thai, pb.noi_dung, pb.ghi_chu, pb.nguoi_gui_id, pb.nguoi_duyet_id, pb.nhan_xet_duyet, pb.ngay_tao, pb.ngay_duyet,
+    t.tieu_de AS tieu_de_tour,
+    COALESCE(t.trang_thai, 'nhap')::TEXT AS trang_thai_tour,
+    t.nha_cung_cap_id,
+    ncc.ten AS ten_nha_cung_cap
+FROM phien_ban_tour pb
+JOIN tour t ON t.id = pb.tour_id
+LEFT JOIN nha_cung_cap ncc ON ncc.id = t.nha_cung_cap_id
+WHERE pb.id = $1
+`
+
+type GetTourRevisionByIDRow struct {
+	ID            int32            `json:"id"`
+	TourID        int32            `json:"tour_id"`
+	SoPhienBan    int32            `json:"so_phien_ban"`
+	Loai          string           `json:"loai"`
+	TrangThai     string           `json:"trang_thai"`
+	NoiDung       []byte           `json:"noi_dung"`
+	GhiChu        *string          `json:"ghi_chu"`
+	NguoiGuiID    pgtype.UUID      `json:"nguoi_gui_id"`
+	NguoiDuyetID  pgtype.UUID      `json:"nguoi_duyet_id"`
+	NhanXetDuyet  *string          `json:"nhan_xet_duyet"`
+	NgayTao       pgtype.Timestamp `json:"ngay_tao"`
+	NgayDuyet     pgtype.Timestamp `json:"ngay_duyet"`
+	TieuDeTour    string           `json:"tieu_de_tour"`
+	TrangThaiTour string           `json:"trang_thai_tour"`
+	NhaCungCapID  pgtype.UUID      `json:"nha_cung_cap_id"`
+	TenNhaCungCap *string          `json:"ten_nha_cung_cap"`
+}
+
+func (q *Queries) GetTourRevisionByID(ctx context.Context, id int32) (GetTourRevisionByIDRow, error) {
+	row := q.db.QueryRow(ctx, getTourRevisionByID, id)
+	var i GetTourRevisionByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.TourID,
+		&i.SoPhienBan,
+		&i.Loai,
+		&i.TrangThai,
+		&i.NoiDung,
+		&i.GhiChu,
+		&i.NguoiGuiID,
+		&i.NguoiDuyetID,
+		&i.NhanXetDuyet,
+		&i.NgayTao,
+		&i.NgayDuyet,
+		&i.TieuDeTour,
+		&i.TrangThaiTour,
+		&i.NhaCungCapID,
+		&i.TenNhaCungCap,
+	)
+	return i, err
+}
+
+const listTourRevisions = `-- name: ListTourRevisions :many
+SELECT
+    pb.id,
+    pb.tour_id,
+    pb.so_phien_ban,
+    pb.loai,
+    pb.trang_thai,
+    pb.ghi_chu,
+    pb.nguoi_gui_id,
+    pb.nguoi_duyet_id,
+    pb.nhan_xet_duyet,
+    pb.ngay_tao,
+    pb.ngay_duyet,
+    t.tieu_de AS tieu_de_tour,
+    COALESCE(t.trang_thai, 'nhap')::TEXT AS trang_thai_tour,
+    t.nha_cung_cap_id,
+    ncc.ten AS ten_nha_cung_cap,
+    COUNT(*) OVER()::INT AS tong
+FROM phien_ban_tour pb
+JOIN tour t ON t.id = pb.tour_id
+LEFT JOIN nha_cung_cap ncc ON ncc.id = t.nha_cung_cap_id
+WHERE pb.trang_thai = COALESCE($1::TEXT, 'cho_duyet')
+  AND ($2::TEXT IS NULL OR pb.loai = $2::TEXT)
+  AND ($3::INT IS NULL OR pb.tour_id = $3::INT)
+  AND ($4::UUID IS NULL OR t.nha_cung_cap_id = $4::UUID)
+ORDER BY pb.ngay_tao ASC, pb.id ASC
+LIMIT $6::INT OFFSET $5::INT
+`
+
+type ListTourRevisionsParams struct {
+	TrangThai    *string     `json:"trang_thai"`
+	Loai         *string     `json:"loai"`
+	TourID       *int32      `json:"tour_id"`
+	NhaCungCapID pgtype.UUID `json:"nha_cung_cap_id"`
+	Offset       int32       `json:"offset"`
+	Limit        int32       `json:"limit"`
+}
+
+type ListTourRevisionsRow struct {
+	ID            int32            `json:"id"`
+	TourID        int32            `json:"tour_id"`
+	SoPhienBan    int32            `json:"so_phien_ban"`
+	Loai          string           `json:"loai"`
+	TrangThai     string           `json:"trang_thai"`
+	GhiChu        *string          `json:"ghi_chu"`
+	NguoiGuiID    pgtype.UUID      `json:"nguoi_gui_id"`
+	NguoiDuyetID  pgtype.UUID      `json:"nguoi_duyet_id"`
+	NhanXetDuyet  *string          `json:"nhan_xet_duyet"`
+	NgayTao       pgtype.Timestamp `json:"ngay_tao"`
+	NgayDuyet     pgtype.Timestamp `json:"ngay_duyet"`
+	TieuDeTour    string           `json:"tieu_de_tour"`
+	TrangThaiTour string           `json:"trang_thai_tour"`
+	NhaCungCapID  pgtype.UUID      `json:"nha_cung_cap_id"`
+	TenNhaCungCap *string          `json:"ten_nha_cung_cap"`
+	Tong          int32            `json:"tong"`
+}
+
+// Hàng đợi kiểm duyệt (mặc định các phiên bản chờ duyệt, cũ nhất trước); không kèm nội dung
+func (q *Queries) ListTourRevisions(ctx context.Context, arg ListTourRevisionsParams) ([]ListTourRevisionsRow, error) {
+	rows, err := q.db.Query(ctx, listTourRevisions,
+		arg.TrangThai,
+		arg.Loai,
+		arg.TourID,
+		arg.NhaCungCapID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTourRevisionsRow
+	for rows.Next() {
+		var i ListTourRevisionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TourID,
+			&i.SoPhienBan,
+			&i.Loai,
+			&i.TrangThai,
+			&i.GhiChu,
+			&i.NguoiGuiID,
+			&i.NguoiDuyetID,
+			&i.NhanXetDuyet,
+			&i.NgayTao,
+			&i.NgayDuyet,
+			&i.TieuDeTour,
+			&i.TrangThaiTour,
+			&i.NhaCungCapID,
+			&i.TenNhaCungCap,
+			&i.Tong,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTourRevisionsByTour = `-- name: ListTourRevisionsByTour :many
+SELECT
+    pb.id, pb.tour_id, pb.so_phien_ban, pb.loai, pb.trang_thai, pb.noi_dung, pb.ghi_chu, pb.nguoi_gui_id, pb.nguoi_duyet_id, pb.nhan_xet_duyet, pb.ngay_tao, pb.ngay_duyet,
+    COUNT(*) OVER()::INT AS tong
+FROM phien_ban_tour pb
+WHERE pb.tour_id = $1
+ORDER BY pb.so_phien_ban DESC
+LIMIT $3::INT OFFSET $2::INT
+`
+
+type ListTourRevisionsByTourParams struct {
+	TourID int32 `json:"tour_id"`
+	Offset int32 `json:"offset"`
+	Limit  int32 `json:"limit"`
+}
+
+type ListTourRevisionsByTourRow struct {
+	ID           int32            `json:"id"`
+	TourID       int32            `json:"tour_id"`
+	SoPhienBan   int32            `json:"so_phien_ban"`
+	Loai         string           `json:"loai"`
+	TrangThai    string           `json:"trang_thai"`
+	NoiDung      []byte           `json:"noi_dung"`
+	GhiChu       *string          `json:"ghi_chu"`
+	NguoiGuiID   pgtype.UUID      `json:"nguoi_gui_id"`
+	NguoiDuyetID pgtype.UUID      `json:"nguoi_duyet_id"`
+	NhanXetDuyet *string          `json:"nhan_xet_duyet"`
+	NgayTao      pgtype.Timestamp `json:"ngay_tao"`
+	NgayDuyet    pgtype.Timestamp `json:"ngay_duyet"`
+	Tong         int32            `json:"tong"`
+}
+
+// Lịch sử phiên bản của một tour, mới nhất trước
+func (q *Queries) ListTourRevisionsByTour(ctx context.Context, arg ListTourRevisionsByTourParams) ([]ListTourRevisionsByTourRow, error) {
+	rows, err := q.db.Query(ctx, listTourRevisionsByTour, arg.TourID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTourRevisionsByTourRow
+	for rows.Next() {
+		var i ListTourRevisionsByTourRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TourID,
+			&i.SoPhienBan,
+			&i.Loai,
+			&i.TrangThai,
+			&i.NoiDung,
+			&i.GhiChu,
+			&i.NguoiGuiID,
+			&i.NguoiDuyetID,
+			&i.NhanXetDuyet,
+			&i.NgayTao,
+			&i.NgayDuyet,
+			&i.Tong,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reviewTourRevision = `-- name: ReviewTourRevision :one
+UPDATE phien_ban_tour
+SET trang_thai = $1,
+    nguoi_duyet_id = $2,
+    nhan_xet_duyet = $3,
+    ngay_duyet = CURRENT_TIMESTAMP
+WHERE id = $4 AND trang_thai = 'cho_duyet'
+RETURNING id, tour_id, so_phien_ban, loai, trang_thai, noi_dung, ghi_chu, nguoi_gui_id, nguoi_duyet_id, nhan_xet_duyet, ngay_tao, ngay_duyet
+`
+
+type ReviewTourRevisionParams struct {
+	TrangThai    string      `json:"trang_thai"`
+	NguoiDuyetID pgtype.UUID `json:"nguoi_duyet_id"`
+	NhanXetDuyet *string     `json:"nhan_xet_duyet"`
+	ID           int32       `json:"id"`
+}
+
+// Chỉ phiên bản đang chờ duyệt mới được duyệt / từ chối (pgx.ErrNoRows nếu đã xử lý hoặc đã hủy)
+func (q *Queries) ReviewTourRevision(ctx context.Context, arg ReviewTourRevisionParams) (PhienBanTour, error) {
+	row := q.db.QueryRow(ctx, reviewTourRevision,
+		arg.TrangThai,
+		arg.NguoiDuyetID,
+		arg.NhanXetDuyet,
+		arg.ID,
+	)
+	var i PhienBanTour
+	err := row.Scan(
+		&i.ID,
+		&i.TourID,
+		&i.SoPhienBan,
+		&i.Loai,
+		&i.TrangThai,
+		&i.NoiDung,
+		&i.GhiChu,
+		&i.NguoiGuiID,
+		&i.NguoiDuyetID,
+		&i.NhanXetDuyet,
+		&i.NgayTao,
+		&i.NgayDuyet,
+	)
+	return i, err
+}
+
+const setTourModerationStatus = `-- name: SetTourModerationStatus :one
+UPDATE tour
+SET trang_thai = $1::TEXT,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING id, tieu_de, mo_ta, danh_muc_id, so_ngay, so_dem, gia_nguoi_lon, gia_tre_em, don_vi_tien_te, trang_thai, noi_bat, nha_cung_cap_id, dang_hoat_dong, ngay_tao, ngay_cap_nhat, chinh_sach_huy_id
+`
+
+type SetTourModerationStatusParams struct {
+	TrangThai string `json:"trang_thai"`
+	ID        int32  `json:"id"`
+}
+
+func (q *Queries) SetTourModerationStatus(ctx context.Context, arg SetTourModerationStatusParams) (Tour, error) {
+	row := q.db.QueryRow(ctx, setTourModerationStatus, arg.TrangThai, arg.ID)
+	var i Tour
+	err := row.Scan(
+		&i.ID,
+		&i.TieuDe,
+		&i.MoTa,
+		&i.DanhMucID,
+		&i.SoNgay,
+		&i.SoDem,
+		&i.GiaNguoiLon,
+		&i.GiaTreEm,
+		&i.DonViTienTe,
+		&i.TrangThai,
+		&i.NoiBat,
+		&i.NhaCungCapID,
+		&i.DangHoatDong,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+		&i.ChinhSachHuyID,
+	)
+	return i, err
+}
@@ -0,0 +1,592 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: tour_search.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pgvector/pgvector-go"
+)
+
+const getTourSearchFacets = `-- name: GetTourSearchFacets :many
+WITH ggt AS (
+  SELECT DISTINCT ON (tour_id) tour_id, phan_tram
+  FROM giam_gia_tour
+  WHERE CURRENT_DATE BETWEEN ngay_bat_dau AND ngay_ket_thuc
+  ORDER BY tour_id, phan_tram DESC
+),
+dg AS (
+  SELECT tour_id, AVG(diem_danh_gia)::FLOAT AS avg_rating
+  FROM danh_gia
+  WHERE dang_hoat_dong = TRUE
+  GROUP BY tour_id
+),
+base AS (
+  SELECT
+    t.id, t.tieu_de, t.mo_ta, t.danh_muc_id, dm.ten AS danh_muc_ten, t.so_ngay,
+    ggt.phan_tram AS giam_gia_phan_tram,
+    CASE
+      WHEN ggt.phan_tram IS NOT NULL THEN ROUND(t.gia_nguoi_lon * (1 - ggt.phan_tram / 100), 2)
+      ELSE t.gia_nguoi_lon
+    END::NUMERIC AS gia_sau_giam,
+    COALESCE(dg.avg_rating, 0)::FLOAT AS avg_rating
+  FROM tour t
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  LEFT JOIN ggt ON ggt.tour_id = t.id
+  LEFT JOIN dg ON dg.tour_id = t.id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+),
+f AS (
+  SELECT b.id, b.danh_muc_id, b.danh_muc_ten,
+    CASE
+      WHEN b.gia_sau_giam < 2000000 THEN 'duoi_2tr'
+      WHEN b.gia_sau_giam < 5000000 THEN '2tr_5tr'
+      WHEN b.gia_sau_giam < 10000000 THEN '5tr_10tr'
+      WHEN b.gia_sau_giam < 20000000 THEN '10tr_20tr'
+      ELSE 'tren_20tr'
+    END AS khoang_gia,
+    CASE
+      WHEN b.so_ngay <= 1 THEN '1_ngay'
+      WHEN b.so_ngay <= 3 THEN '2_3_ngay'
+      WHEN b.so_ngay <= 7 THEN '4_7_ngay'
+      ELSE 'tren_7_ngay'
+    END AS thoi_luong,
+    (
+      $1::TEXT IS NULL
+      OR to_tsvector('vietnamese', b.tieu_de || ' ' || COALESCE(b.mo_ta, '')) @@ plainto_tsquery('vietnamese', $1)
+      OR EXISTS (
+        SELECT 1 FROM lich_trinh lt
+        JOIN hoat_dong_trong_ngay hd ON hd.lich_trinh_id = lt.id
+        WHERE lt.tour_id = b.id AND to_tsvector('vietnamese', hd.ten) @@ plainto_tsquery('vietnamese', $1)
+      )
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        JOIN diem_den d ON d.id = tdd.diem_den_id
+        WHERE tdd.tour_id = b.id
+          AND unaccent(lower(d.ten || ' ' || COALESCE(d.tinh, '') || ' ' || COALESCE(d.quoc_gia, ''))) LIKE '%' || unaccent(lower($1)) || '%'
+      )
+    ) AS m_tu_khoa,
+    ($2::INT[] IS NULL OR b.danh_muc_id = ANY($2::INT[])) AS m_danh_muc,
+    (
+      $3::INT[] IS NULL
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        WHERE tdd.tour_id = b.id AND tdd.diem_den_id = ANY($3::INT[])
+      )
+    ) AS m_diem_den,
+    (
+      ($4::NUMERIC IS NULL OR b.gia_sau_giam >= $4::NUMERIC)
+      AND ($5::NUMERIC IS NULL OR b.gia_sau_giam <= $5::NUMERIC)
+    ) AS m_gia,
+    (
+      ($6::INT IS NULL OR b.so_ngay >= $6::INT)
+      AND ($7::INT IS NULL OR b.so_ngay <= $7::INT)
+    ) AS m_thoi_luong,
+    ($8::FLOAT IS NULL OR b.avg_rating >= $8::FLOAT) AS m_danh_gia,
+    (
+      ($9::DATE IS NULL AND $10::DATE IS NULL)
+      OR EXISTS (
+        SELECT 1 FROM khoi_hanh_tour k
+        WHERE k.tour_id = b.id
+          AND k.trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+          AND k.ngay_khoi_hanh >= GREATEST(COALESCE($9::DATE, CURRENT_DATE), CURRENT_DATE)
+          AND ($10::DATE IS NULL OR k.ngay_khoi_hanh <= $10::DATE)
+      )
+    ) AS m_khoi_hanh,
+    ($11::BOOLEAN IS NULL OR (b.giam_gia_phan_tram IS NOT NULL) = $11::BOOLEAN) AS m_giam_gia
+  FROM base b
+)
+SELECT 'tong'::TEXT AS nhom, ''::TEXT AS khoa, ''::TEXT AS nhan, COUNT(*)::INT AS so_luong
+FROM f
+WHERE m_tu_khoa AND m_danh_muc AND m_diem_den AND m_gia AND m_thoi_luong AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+UNION ALL
+SELECT 'danh_muc'::TEXT, f.danh_muc_id::TEXT, COALESCE(MAX(f.danh_muc_ten), '')::TEXT, COUNT(*)::INT
+FROM f
+WHERE f.danh_muc_id IS NOT NULL
+  AND m_tu_khoa AND m_diem_den AND m_gia AND m_thoi_luong AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+GROUP BY f.danh_muc_id
+UNION ALL
+SELECT 'diem_den'::TEXT, d.id::TEXT, d.ten::TEXT, COUNT(DISTINCT f.id)::INT
+FROM f
+JOIN tour_diem_den tdd ON tdd.tour_id = f.id
+JOIN diem_den d ON d.id = tdd.diem_den_id
+WHERE m_tu_khoa AND m_danh_muc AND m_gia AND m_thoi_luong AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+GROUP BY d.id, d.ten
+UNION ALL
+SELECT 'khoang_gia'::TEXT, f.khoang_gia::TEXT, ''::TEXT, COUNT(*)::INT
+FROM f
+WHERE m_tu_khoa AND m_danh_muc AND m_diem_den AND m_thoi_luong AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+GROUP BY f.khoang_gia
+UNION ALL
+SELECT 'thoi_luong'::TEXT, f.thoi_luong::TEXT, ''::TEXT, COUNT(*)::INT
+FROM f
+WHERE m_tu_khoa AND m_danh_muc AND m_diem_den AND m_gia AND m_danh_gia AND m_khoi_hanh AND m_giam_gia
+GROUP BY f.thoi_luong
+`
+
+type GetTourSearchFacetsParams struct {
+	Keyword     *string        `json:"keyword"`
+	DanhMucIds  []int32        `json:"danh_muc_ids"`
+	DiemDenIds  []int32        `json:"diem_den_ids"`
+	GiaMin      pgtype.Numeric `json:"gia_min"`
+	GiaMax      pgtype.Numeric `json:"gia_max"`
+	SoNgayMin   *int32         `json:"so_ngay_min"`
+	SoNgayMax   *int32         `json:"so_ngay_max"`
+	RatingMin   *float64       `json:"rating_min"`
+	KhoiHanhTu  pgtype.Date    `json:"khoi_hanh_tu"`
+	KhoiHanhDen pgtype.Date    `json:"khoi_hanh_den"`
+	CoGiamGia   *bool          `json:"co_giam_gia"`
+}
+
+type GetTourSearchFacetsRow struct {
+	Nhom    string `json:"nhom"`
+	Khoa    string `json:"khoa"`
+	Nhan    string `json:"nhan"`
+	SoLuong int32  `json:"so_luong"`
+}
+
+// nhom: tong | danh_muc | diem_den | khoang_gia | thoi_luong
+func (q *Queries) GetTourSearchFacets(ctx context.Context, arg GetTourSearchFacetsParams) ([]GetTourSearchFacetsRow, error) {
+	rows, err := q.db.Query(ctx, getTourSearchFacets,
+		arg.Keyword,
+		arg.DanhMucIds,
+		arg.DiemDenIds,
+		arg.GiaMin,
+		arg.GiaMax,
+		arg.SoNgayMin,
+		arg.SoNgayMax,
+		arg.RatingMin,
+		arg.KhoiHanhTu,
+		arg.KhoiHanhDen,
+		arg.CoGiamGia,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTourSearchFacetsRow
+	for rows.Next() {
+		var i GetTourSearchFacetsRow
+		if err := rows.Scan(
+			&i.Nhom,
+			&i.Khoa,
+			&i.Nhan,
+			&i.SoLuong,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchToursFaceted = `-- name: SearchToursFaceted :many
+
+WITH ggt AS (
+  SELECT DISTINCT ON (tour_id) tour_id, phan_tram
+  FROM giam_gia_tour
+  WHERE CURRENT_DATE BETWEEN ngay_bat_dau AND ngay_ket_thuc
+  ORDER BY tour_id, phan_tram DESC
+),
+dg AS (
+  SELECT tour_id, AVG(diem_danh_gia)::FLOAT AS avg_rating, COUNT(*)::INT AS total_reviews
+  FROM danh_gia
+  WHERE dang_hoat_dong = TRUE
+  GROUP BY tour_id
+),
+kh AS (
+  SELECT tour_id, MIN(ngay_khoi_hanh)::DATE AS next_departure_date
+  FROM khoi_hanh_tour
+  WHERE ngay_khoi_hanh >= CURRENT_DATE AND trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+  GROUP BY tour_id
+),
+base AS (
+  SELECT
+    t.id, t.tieu_de, t.mo_ta, t.danh_muc_id, dm.ten AS danh_muc_ten,
+    t.so_ngay, t.so_dem, t.gia_nguoi_lon, t.don_vi_tien_te, t.noi_bat, t.ngay_tao,
+    ggt.phan_tram AS giam_gia_phan_tram,
+    CASE
+      WHEN ggt.phan_tram IS NOT NULL THEN ROUND(t.gia_nguoi_lon * (1 - ggt.phan_tram / 100), 2)
+      ELSE t.gia_nguoi_lon
+    END::NUMERIC AS gia_sau_giam,
+    COALESCE(dg.avg_rating, 0)::FLOAT AS avg_rating,
+    COALESCE(dg.total_reviews, 0)::INT AS total_reviews,
+    kh.next_departure_date
+  FROM tour t
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  LEFT JOIN ggt ON ggt.tour_id = t.id
+  LEFT JOIN dg ON dg.tour_id = t.id
+  LEFT JOIN kh ON kh.tour_id = t.id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo'
+),
+f AS (
+  SELECT b.id, b.tieu_de, b.mo_ta, b.danh_muc_id, b.danh_muc_ten, b.so_ngay, b.so_dem, b.gia_nguoi_lon, b.don_vi_tien_te, b.noi_bat, b.ngay_tao, b.giam_gia_phan_tram, b.gia_sau_giam, b.avg_rating, b.total_reviews, b.next_departure_date,
+    (
+      $4::TEXT IS NULL
+      OR to_tsvector('vietnamese', b.tieu_de || ' ' || COALESCE(b.mo_ta, '')) @@ plainto_tsquery('vietnamese', $4)
+      OR EXISTS (
+        SELECT 1 FROM lich_trinh lt
+        JOIN hoat_dong_trong_ngay hd ON hd.lich_trinh_id = lt.id
+        WHERE lt.tour_id = b.id AND to_tsvector('vietnamese', hd.ten) @@ plainto_tsquery('vietnamese', $4)
+      )
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        JOIN diem_den d ON d.id = tdd.diem_den_id
+        WHERE tdd.tour_id = b.id
+          AND unaccent(lower(d.ten || ' ' || COALESCE(d.tinh, '') || ' ' || COALESCE(d.quoc_gia, ''))) LIKE '%' || unaccent(lower($4)) || '%'
+      )
+    ) AS m_tu_khoa,
+    ($5::INT[] IS NULL OR b.danh_muc_id = ANY($5::INT[])) AS m_danh_muc,
+    (
+      $6::INT[] IS NULL
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        WHERE tdd.tour_id = b.id AND tdd.diem_den_id = ANY($6::INT[])
+      )
+    ) AS m_diem_den,
+    (
+      ($7::NUMERIC IS NULL OR b.gia_sau_giam >= $7::NUMERIC)
+      AND ($8::NUMERIC IS NULL OR b.gia_sau_giam <= $8::NUMERIC)
+    ) AS m_gia,
+    (
+      ($9::INT IS NULL OR b.so_ngay >= $9::INT)
+      AND ($10::INT IS NULL OR b.so_ngay <= $10::INT)
+    ) AS m_thoi_luong,
+    ($11::FLOAT IS NULL OR b.avg_rating >= $11::FLOAT) AS m_danh_gia,
+    (
+      ($12::DATE IS NULL AND $13::DATE IS NULL)
+      OR EXISTS (
+        SELECT 1 FROM khoi_hanh_tour k
+        WHERE k.tour_id = b.id
+          AND k.trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+          AND k.ngay_khoi_hanh >= GREATEST(COALESCE($12::DATE, CURRENT_DATE), CURRENT_DATE)
+          AND ($13::DATE IS NULL OR k.ngay_khoi_hanh <= $13::DATE)
+      )
+    ) AS m_khoi_hanh,
+    ($14::BOOLEAN IS NULL OR (b.giam_gia_phan_tram IS NOT NULL) = $14::BOOLEAN) AS m_giam_gia
+  FROM base b
+)
+SELECT
+  f.id, f.tieu_de, f.mo_ta, f.danh_muc_id, f.danh_muc_ten, f.so_ngay, f.so_dem,
+  f.gia_nguoi_lon, f.gia_sau_giam, f.giam_gia_phan_tram, f.don_vi_tien_te, f.noi_bat,
+  f.avg_rating, f.total_reviews, f.next_departure_date,
+  COALESCE(anh.duong_dan, '')::TEXT AS anh_chinh,
+  COALESCE((
+    SELECT array_agg(d.ten ORDER BY d.ten)
+    FROM tour_diem_den tdd
+    JOIN diem_den d ON d.id = tdd.diem_den_id
+    WHERE tdd.tour_id = f.id
+  ), '{}')::TEXT[] AS diem_den
+FROM f
+LEFT JOIN LATERAL (
+  SELECT a.duong_dan FROM anh_tour a
+  WHERE a.tour_id = f.id
+  ORDER BY COALESCE(a.la_anh_chinh, FALSE) DESC, COALESCE(a.thu_tu_hien_thi, 0) ASC
+  LIMIT 1
+) anh ON TRUE
+WHERE f.m_tu_khoa AND f.m_danh_muc AND f.m_diem_den AND f.m_gia
+  AND f.m_thoi_luong AND f.m_danh_gia AND f.m_khoi_hanh AND f.m_giam_gia
+ORDER BY
+  CASE WHEN $1::TEXT = 'price_asc' THEN f.gia_sau_giam END ASC,
+  CASE WHEN $1::TEXT = 'price_desc' THEN f.gia_sau_giam END DESC,
+  CASE WHEN $1::TEXT = 'rating' THEN f.avg_rating END DESC,
+  CASE WHEN $1::TEXT = 'departure' THEN f.next_departure_date END ASC NULLS LAST,
+  CASE WHEN $1::TEXT = 'newest' THEN f.ngay_tao END DESC,
+  f.noi_bat DESC,
+  f.ngay_tao DESC,
+  f.id DESC
+LIMIT $3::INT OFFSET $2::INT
+`
+
+type SearchToursFacetedParams struct {
+	SortBy      string         `json:"sort_by"`
+	Offset      int32          `json:"offset"`
+	Limit       int32          `json:"limit"`
+	Keyword     *string        `json:"keyword"`
+	DanhMucIds  []int32        `json:"danh_muc_ids"`
+	DiemDenIds  []int32        `json:"diem_den_ids"`
+	GiaMin      pgtype.Numeric `json:"gia_min"`
+	GiaMax      pgtype.Numeric `json:"gia_max"`
+	SoNgayMin   *int32         `json:"so_ngay_min"`
+	SoNgayMax   *int32         `json:"so_ngay_max"`
+	RatingMin   *float64       `json:"rating_min"`
+	KhoiHanhTu  pgtype.Date    `json:"khoi_hanh_tu"`
+	KhoiHanhDen pgtype.Date    `json:"khoi_hanh_den"`
+	CoGiamGia   *bool          `json:"co_giam_gia"`
+}
+
+type SearchToursFacetedRow struct {
+	ID                int32          `json:"id"`
+	TieuDe            string         `json:"tieu_de"`
+	MoTa              *string        `json:"mo_ta"`
+	DanhMucID         *int32         `json:"danh_muc_id"`
+	DanhMucTen        *string        `json:"danh_muc_ten"`
+	SoNgay            int32          `json:"so_ngay"`
+	SoDem             int32          `json:"so_dem"`
+	GiaNguoiLon       pgtype.Numeric `json:"gia_nguoi_lon"`
+	GiaSauGiam        pgtype.Numeric `json:"gia_sau_giam"`
+	GiamGiaPhanTram   pgtype.Numeric `json:"giam_gia_phan_tram"`
+	DonViTienTe       *string        `json:"don_vi_tien_te"`
+	NoiBat            *bool          `json:"noi_bat"`
+	AvgRating         float64        `json:"avg_rating"`
+	TotalReviews      int32          `json:"total_reviews"`
+	NextDepartureDate pgtype.Date    `json:"next_departure_date"`
+	AnhChinh          string         `json:"anh_chinh"`
+	DiemDen           []string       `json:"diem_den"`
+}
+
+// Tìm kiếm tour có facet: cả hai truy vấn dùng chung bộ lọc.
+// Mỗi điều kiện lọc được tính thành một cột boolean (m_*) để đếm facet của một nhóm
+// theo mọi bộ lọc còn lại (bỏ qua chính nhóm đó), nhờ vậy thanh lọc hiển thị được số lượng của từng lựa chọn.
+// Khoảng giá (VND, theo giá sau giảm): duoi_2tr | 2tr_5tr | 5tr_10tr | 10tr_20tr | tren_20tr
+// Khoảng thời lượng: 1_ngay | 2_3_ngay | 4_7_ngay | tren_7_ngay
+func (q *Queries) SearchToursFaceted(ctx context.Context, arg SearchToursFacetedParams) ([]SearchToursFacetedRow, error) {
+	rows, err := q.db.Query(ctx, searchToursFaceted,
+		arg.SortBy,
+		arg.Offset,
+		arg.Limit,
+		arg.Keyword,
+		arg.DanhMucIds,
+		arg.DiemDenIds,
+		arg.GiaMin,
+		arg.GiaMax,
+		arg.SoNgayMin,
+		arg.SoNgayMax,
+		arg.RatingMin,
+		arg.KhoiHanhTu,
+		arg.KhoiHanhDen,
+		arg.CoGiamGia,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchToursFacetedRow
+	for rows.Next() {
+		var i SearchToursFacetedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TieuDe,
+			&i.MoTa,
+			&i.DanhMucID,
+			&i.DanhMucTen,
+			&i.SoNgay,
+			&i.SoDem,
+			&i.GiaNguoiLon,
+			&i.GiaSauGiam,
+			&i.GiamGiaPhanTram,
+			&i.DonViTienTe,
+			&i.NoiBat,
+			&i.AvgRating,
+			&i.TotalReviews,
+			&i.NextDepartureDate,
+			&i.AnhChinh,
+			&i.DiemDen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchToursSemantic = `-- name: SearchToursSemantic :many
+WITH ggt AS (
+  SELECT DISTINCT ON (tour_id) tour_id, phan_tram
+  FROM giam_gia_tour
+  WHERE CURRENT_DATE BETWEEN ngay_bat_dau AND ngay_ket_thuc
+  ORDER BY tour_id, phan_tram DESC
+),
+dg AS (
+  SELECT tour_id, AVG(diem_danh_gia)::FLOAT AS avg_rating, COUNT(*)::INT AS total_reviews
+  FROM danh_gia
+  WHERE dang_hoat_dong = TRUE
+  GROUP BY tour_id
+),
+kh AS (
+  SELECT tour_id, MIN(ngay_khoi_hanh)::DATE AS next_departure_date
+  FROM khoi_hanh_tour
+  WHERE ngay_khoi_hanh >= CURRENT_DATE AND trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+  GROUP BY tour_id
+),
+base AS (
+  SELECT
+    t.id, t.tieu_de, t.mo_ta, t.danh_muc_id, dm.ten AS danh_muc_ten,
+    t.so_ngay, t.so_dem, t.gia_nguoi_lon, t.don_vi_tien_te, t.noi_bat,
+    ggt.phan_tram AS giam_gia_phan_tram,
+    CASE
+      WHEN ggt.phan_tram IS NOT NULL THEN ROUND(t.gia_nguoi_lon * (1 - ggt.phan_tram / 100), 2)
+      ELSE t.gia_nguoi_lon
+    END::NUMERIC AS gia_sau_giam,
+    COALESCE(dg.avg_rating, 0)::FLOAT AS avg_rating,
+    COALESCE(dg.total_reviews, 0)::INT AS total_reviews,
+    kh.next_departure_date,
+    (e.embedding <=> $3::vector)::FLOAT AS khoang_cach
+  FROM tour t
+  JOIN tour_embeddings e ON e.tour_id = t.id AND e.mo_hinh = $4::TEXT
+  LEFT JOIN danh_muc_tour dm ON dm.id = t.danh_muc_id
+  LEFT JOIN ggt ON ggt.tour_id = t.id
+  LEFT JOIN dg ON dg.tour_id = t.id
+  LEFT JOIN kh ON kh.tour_id = t.id
+  WHERE t.dang_hoat_dong = TRUE AND t.trang_thai = 'cong_bo' AND e.embedding IS NOT NULL
+),
+f AS (
+  SELECT b.id, b.tieu_de, b.mo_ta, b.danh_muc_id, b.danh_muc_ten, b.so_ngay, b.so_dem, b.gia_nguoi_lon, b.don_vi_tien_te, b.noi_bat, b.giam_gia_phan_tram, b.gia_sau_giam, b.avg_rating, b.total_reviews, b.next_departure_date, b.khoang_cach
+  FROM base b
+  WHERE ($5::INT[] IS NULL OR b.danh_muc_id = ANY($5::INT[]))
+    AND (
+      $6::INT[] IS NULL
+      OR EXISTS (
+        SELECT 1 FROM tour_diem_den tdd
+        WHERE tdd.tour_id = b.id AND tdd.diem_den_id = ANY($6::INT[])
+      )
+    )
+    AND ($7::NUMERIC IS NULL OR b.gia_sau_giam >= $7::NUMERIC)
+    AND ($8::NUMERIC IS NULL OR b.gia_sau_giam <= $8::NUMERIC)
+    AND ($9::INT IS NULL OR b.so_ngay >= $9::INT)
+    AND ($10::INT IS NULL OR b.so_ngay <= $10::INT)
+    AND ($11::FLOAT IS NULL OR b.avg_rating >= $11::FLOAT)
+    AND (
+      ($12::DATE IS NULL AND $13::DATE IS NULL)
+      OR EXISTS (
+        SELECT 1 FROM khoi_hanh_tour k
+        WHERE k.tour_id = b.id
+          AND k.trang_thai IN ('len_lich', 'xac_nhan', 'con_cho')
+          AND k.ngay_khoi_hanh >= GREATEST(COALESCE($12::DATE, CURRENT_DATE), CURRENT_DATE)
+          AND ($13::DATE IS NULL OR k.ngay_khoi_hanh <= $13::DATE)
+      )
+    )
+    AND ($14::BOOLEAN IS NULL OR (b.giam_gia_phan_tram IS NOT NULL) = $14::BOOLEAN)
+    AND ($15::FLOAT IS NULL OR 1 - b.khoang_cach >= $15::FLOAT)
+)
+SELECT
+  f.id, f.tieu_de, f.mo_ta, f.danh_muc_id, f.danh_muc_ten, f.so_ngay, f.so_dem,
+  f.gia_nguoi_lon, f.gia_sau_giam, f.giam_gia_phan_tram, f.don_vi_tien_te, f.noi_bat,
+  f.avg_rating, f.total_reviews, f.next_departure_date,
+  (1 - f.khoang_cach)::FLOAT AS do_tuong_dong,
+  COALESCE(anh.duong_dan, '')::TEXT AS anh_chinh,
+  COALESCE((
+    SELECT array_agg(d.ten ORDER BY d.ten)
+    FROM tour_diem_den tdd
+    JOIN diem_den d ON d.id = tdd.diem_den_id
+    WHERE tdd.tour_id = f.id
+  ), '{}')::TEXT[] AS diem_den,
+  COUNT(*) OVER ()::INT AS tong
+FROM f
+LEFT JOIN LATERAL (
+  SELECT a.duong_dan FROM anh_tour a
+  WHERE a.tour_id = f.id
+  ORDER BY COALESCE(a.la_anh_chinh, FALSE) DESC, COALESCE(a.thu_tu_hien_thi, 0) ASC
+  LIMIT 1
+) anh ON TRUE
+ORDER BY f.khoang_cach ASC, f.noi_bat DESC, f.id DESC
+LIMIT $2::INT OFFSET $1::INT
+`
+
+type SearchToursSemanticParams struct {
+	Offset         int32            `json:"offset"`
+	Limit          int32            `json:"limit"`
+	Embedding      *pgvector.Vector `json:"embedding"`
+	MoHinh         string           `json:"mo_hinh"`
+	DanhMucIds     []int32          `json:"danh_muc_ids"`
+	DiemDenIds     []int32          `json:"diem_den_ids"`
+	GiaMin         pgtype.Numeric   `json:"gia_min"`
+	GiaMax         pgtype.Numeric   `json:"gia_max"`
+	SoNgayMin      *int32           `json:"so_ngay_min"`
+	SoNgayMax      *int32           `json:"so_ngay_max"`
+	RatingMin      *float64         `json:"rating_min"`
+	KhoiHanhTu     pgtype.Date      `json:"khoi_hanh_tu"`
+	KhoiHanhDen    pgtype.Date      `json:"khoi_hanh_den"`
+	CoGiamGia      *bool            `json:"co_giam_gia"`
+	DoTuongDongMin *float64         `json:"do_tuong_dong_min"`
+}
+
+type SearchToursSemanticRow struct {
+	ID                int32          `json:"id"`
+	TieuDe            string         `json:"tieu_de"`
+	MoTa              *string        `json:"mo_ta"`
+	DanhMucID         *int32         `json:"danh_muc_id"`
+	DanhMucTen        *string        `json:"danh_muc_ten"`
+	SoNgay            int32          `json:"so_ngay"`
+	SoDem             int32          `json:"so_dem"`
+	GiaNguoiLon       pgtype.Numeric `json:"gia_nguoi_lon"`
+	GiaSauGiam        pgtype.Numeric `json:"gia_sau_giam"`
+	GiamGiaPhanTram   pgtype.Numeric `json:"giam_gia_phan_tram"`
+	DonViTienTe       *string        `json:"don_vi_tien_te"`
+	NoiBat            *bool          `json:"noi_bat"`
+	AvgRating         float64        `json:"avg_rating"`
+	TotalReviews      int32          `json:"total_reviews"`
+	NextDepartureDate pgtype.Date    `json:"next_departure_date"`
+	DoTuongDong       float64        `json:"do_tuong_dong"`
+	AnhChinh          string         `json:"anh_chinh"`
+	DiemDen           []string       `json:"diem_den"`
+	Tong              int32          `json:"tong"`
+}
+
+// Tìm kiếm ngữ nghĩa: xếp hạng theo độ tương đồng cosine giữa embedding câu truy vấn và embedding tour
+// (chỉ so với embedding cùng mô hình), các bộ lọc có cấu trúc là điều kiện bắt buộc như SearchToursFaceted
+func (q *Queries) SearchToursSemantic(ctx context.Context, arg SearchToursSemanticParams) ([]SearchToursSemanticRow, error) {
+	rows, err := q.db.Query(ctx, searchToursSemantic,
+		arg.Offset,
+		arg.Limit,
+		arg.Embedding,
+		arg.MoHinh,
+		arg.DanhMucIds,
+		arg.DiemDenIds,
+		arg.GiaMin,
+		arg.GiaMax,
+		arg.SoNgayMin,
+		arg.SoNgayMax,
+		arg.RatingMin,
+		arg.KhoiHanhTu,
+		arg.KhoiHanhDen,
+		arg.CoGiamGia,
+		arg.DoTuongDongMin,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchToursSemanticRow
+	for rows.Next() {
+		var i SearchToursSemanticRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TieuDe,
+			&i.MoTa,
+			&i.DanhMucID,
+			&i.DanhMucTen,
+			&i.SoNgay,
+			&i.SoDem,
+			&i.GiaNguoiLon,
+			&i.GiaSauGiam,
+			&i.GiamGiaPhanTram,
+			&i.DonViTienTe,
+			&i.NoiBat,
+			&i.AvgRating,
+			&i.TotalReviews,
+			&i.NextDepartureDate,
+			&i.DoTuongDong,
+			&i.AnhChinh,
+			&i.DiemDen,
+			&i.Tong,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
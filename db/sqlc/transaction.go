@@ -2,8 +2,10 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"travia.backend/api/utils"
 )
@@ -76,8 +78,10 @@ type ActivityInput struct {
 // Tương ứng với bảng: cau_hinh_nhom_tour
 // JSON field từ API: cau_hinh_nhom_tours
 type GroupConfigInput struct {
-	SoNhoNhat *int32 // Column: so_nho_nhat
-	SoLonNhat *int32 // Column: so_lon_nhat
+	SoNhoNhat         *int32 // Column: so_nho_nhat
+	SoLonNhat         *int32 // Column: so_lon_nhat
+	TuoiTreEmToiThieu *int32 // Column: tuoi_tre_em_toi_thieu
+	TuoiTreEmToiDa    *int32 // Column: tuoi_tre_em_toi_da
 }
 
 // DepartureInput cho lịch khởi hành
@@ -270,9 +274,11 @@ func (t *Travia) CreateTourWithDetails(
 	// ============================================================
 	if params.CauHinhNhomTours != nil {
 		gcParam := CreateGroupConfigParams{
-			TourID:    tour.ID,
-			SoNhoNhat: params.CauHinhNhomTours.SoNhoNhat,
-			SoLonNhat: params.CauHinhNhomTours.SoLonNhat,
+			TourID:            tour.ID,
+			SoNhoNhat:         params.CauHinhNhomTours.SoNhoNhat,
+			SoLonNhat:         params.CauHinhNhomTours.SoLonNhat,
+			TuoiTreEmToiThieu: params.CauHinhNhomTours.TuoiTreEmToiThieu,
+			TuoiTreEmToiDa:    params.CauHinhNhomTours.TuoiTreEmToiDa,
 		}
 
 		groupConfig, err := qtx.CreateGroupConfig(ctx, gcParam)
@@ -330,14 +336,15 @@ func (t *Travia) CreateTourWithDetails(
 	return result, nil
 }
 
-// UpdateTourWithDetails cập nhật tour và các dữ liệu liên quan
-// Note: Đây là một implementation đơn giản, có thể cần logic phức tạp hơn
-// để xử lý việc thêm/sửa/xóa images, destinations, itineraries
+// UpdateTourWithDetails cập nhật tour và các dữ liệu liên quan trong 1 transaction.
+// Ảnh, điểm đến, lịch trình và hoạt động được thay thế toàn bộ theo params; cấu hình nhóm được cập nhật (hoặc tạo mới).
+// Lịch khởi hành đã có được giữ nguyên vì có thể đã có đặt chỗ, params.LichKhoiHanhTours chỉ thêm lịch mới.
+// Dùng kết quả trả về có tên để mọi lỗi (kể cả err khai báo lại trong vòng lặp) đều rollback
 func (t *Travia) UpdateTourWithDetails(
 	ctx context.Context,
 	tourID int32,
 	params CreateTourWithDetailsParams,
-) (*CreateTourWithDetailsResult, error) {
+) (result *CreateTourWithDetailsResult, err error) {
 
 	tx, err := t.db.Begin(ctx)
 	if err != nil {
@@ -350,22 +357,391 @@ func (t *Travia) UpdateTourWithDetails(
 		}
 	}()
 
-	_ = t.Queries.WithTx(tx) // qtx will be used when implemented
-
-	// TODO: Implement update logic
-	// 1. Update tour basic info
-	// 2. Delete old images, add new ones (or implement smart diff)
-	// 3. Delete old destinations, add new ones
-	// 4. Delete old itineraries & activities, add new ones
-	// 5. Update group config
+	result, err = applyTourDetails(ctx, t.Queries.WithTx(tx), tourID, params)
+	if err != nil {
+		return nil, err
+	}
 
 	if err = tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil, fmt.Errorf("UpdateTourWithDetails not yet implemented")
+	return result, nil
+}
+
+// applyTourDetails ghi nội dung tour theo params bằng qtx (đã ở trong transaction của hàm gọi)
+func applyTourDetails(
+	ctx context.Context,
+	qtx *Queries,
+	tourID int32,
+	params CreateTourWithDetailsParams,
+) (*CreateTourWithDetailsResult, error) {
+	result := &CreateTourWithDetailsResult{
+		Images:       make([]AnhTour, 0),
+		Destinations: make([]int32, 0),
+		Itineraries:  make([]ItineraryWithActivities, 0),
+		Departures:   make([]KhoiHanhTour, 0),
+	}
+
+	// BƯỚC 1: CẬP NHẬT THÔNG TIN TOUR
+	tour, err := qtx.UpdateTour(ctx, UpdateTourParams{
+		ID:           tourID,
+		TieuDe:       &params.Tour.TieuDe,
+		MoTa:         params.Tour.MoTa,
+		DanhMucID:    params.Tour.DanhMucID,
+		SoNgay:       &params.Tour.SoNgay,
+		SoDem:        &params.Tour.SoDem,
+		GiaNguoiLon:  params.Tour.GiaNguoiLon,
+		GiaTreEm:     params.Tour.GiaTreEm,
+		DonViTienTe:  params.Tour.DonViTienTe,
+		TrangThai:    params.Tour.TrangThai,
+		NoiBat:       params.Tour.NoiBat,
+		NhaCungCapID: params.Tour.NhaCungCapID,
+		DangHoatDong: params.Tour.DangHoatDong,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tour: %w", err)
+	}
+	result.Tour = tour
+
+	// BƯỚC 2: THAY ẢNH TOUR
+	if err = qtx.DeleteTourImagesByTour(ctx, tourID); err != nil {
+		return nil, fmt.Errorf("failed to delete tour images: %w", err)
+	}
+	for i, imgInput := range params.HinhAnhTours {
+		img, err := qtx.AddTourImage(ctx, AddTourImageParams{
+			TourID:       tourID,
+			DuongDan:     imgInput.Link,
+			MoTa:         imgInput.MoTaAlt,
+			LaAnhChinh:   imgInput.LaAnhChinh,
+			ThuTuHienThi: imgInput.ThuTuHienThi,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add tour image #%d: %w", i+1, err)
+		}
+		result.Images = append(result.Images, img)
+	}
+
+	// BƯỚC 3: THAY ĐIỂM ĐẾN
+	if err = qtx.DeleteTourDestinationsByTour(ctx, tourID); err != nil {
+		return nil, fmt.Errorf("failed to delete tour destinations: %w", err)
+	}
+	for i, destInput := range params.DiaDiemTours {
+		err := qtx.AddTourDestination(ctx, AddTourDestinationParams{
+			TourID:        tourID,
+			DiemDenID:     destInput.DiemDenID,
+			ThuTuThamQuan: destInput.ThuTuThamQuan,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add destination #%d (ID: %d): %w",
+				i+1, destInput.DiemDenID, err)
+		}
+		result.Destinations = append(result.Destinations, destInput.DiemDenID)
+	}
+
+	// BƯỚC 4: THAY LỊCH TRÌNH VÀ HOẠT ĐỘNG (hoạt động bị xóa theo lịch trình - ON DELETE CASCADE)
+	if err = qtx.DeleteItinerariesByTour(ctx, tourID); err != nil {
+		return nil, fmt.Errorf("failed to delete itineraries: %w", err)
+	}
+	for _, itinInput := range params.LichTrinhTours {
+		var gioBatDau, gioKetThuc pgtype.Time
+		if itinInput.GioBatDau != nil {
+			if err := gioBatDau.Scan(utils.OnlyTine(*itinInput.GioBatDau)); err != nil {
+				return nil, fmt.Errorf("invalid gio_bat_dau format for day %d: %w",
+					itinInput.NgayThu, err)
+			}
+		}
+		if itinInput.GioKetThuc != nil {
+			if err := gioKetThuc.Scan(utils.OnlyTine(*itinInput.GioKetThuc)); err != nil {
+				return nil, fmt.Errorf("invalid gio_ket_thuc format for day %d: %w",
+					itinInput.NgayThu, err)
+			}
+		}
+
+		lichTrinh, err := qtx.CreateItinerary(ctx, CreateItineraryParams{
+			TourID:         tourID,
+			NgayThu:        itinInput.NgayThu,
+			TieuDe:         itinInput.TieuDe,
+			MoTa:           itinInput.MoTa,
+			GioBatDau:      gioBatDau,
+			GioKetThuc:     gioKetThuc,
+			DiaDiem:        itinInput.DiaDiem,
+			ThongTinLuuTru: itinInput.ThongTinLuuTru,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create itinerary for day %d: %w",
+				itinInput.NgayThu, err)
+		}
+
+		ltWithAct := ItineraryWithActivities{
+			Itinerary:  lichTrinh,
+			Activities: make([]HoatDongTrongNgay, 0),
+		}
+		for j, actInput := range itinInput.Activities {
+			var actGioBatDau, actGioKetThuc pgtype.Time
+			if actInput.GioBatDau != nil {
+				if err := actGioBatDau.Scan(utils.OnlyTine(*actInput.GioBatDau)); err != nil {
+					return nil, fmt.Errorf("invalid gio_bat_dau format for activity #%d on day %d: %w",
+						j+1, itinInput.NgayThu, err)
+				}
+			}
+			if actInput.GioKetThuc != nil {
+				if err := actGioKetThuc.Scan(utils.OnlyTine(*actInput.GioKetThuc)); err != nil {
+					return nil, fmt.Errorf("invalid gio_ket_thuc format for activity #%d on day %d: %w",
+						j+1, itinInput.NgayThu, err)
+				}
+			}
+
+			activity, err := qtx.CreateActivity(ctx, CreateActivityParams{
+				LichTrinhID: lichTrinh.ID,
+				Ten:         actInput.Ten,
+				GioBatDau:   actGioBatDau,
+				GioKetThuc:  actGioKetThuc,
+				MoTa:        actInput.MoTa,
+				ThuTu:       actInput.ThuTu,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create activity #%d for day %d: %w",
+					j+1, itinInput.NgayThu, err)
+			}
+			ltWithAct.Activities = append(ltWithAct.Activities, activity)
+		}
+
+		result.Itineraries = append(result.Itineraries, ltWithAct)
+	}
+
+	// BƯỚC 5: CẬP NHẬT CẤU HÌNH NHÓM (NẾU CÓ)
+	if params.CauHinhNhomTours != nil {
+		groupConfig, err := qtx.UpdateGroupConfig(ctx, UpdateGroupConfigParams{
+			TourID:            tourID,
+			SoNhoNhat:         params.CauHinhNhomTours.SoNhoNhat,
+			SoLonNhat:         params.CauHinhNhomTours.SoLonNhat,
+			TuoiTreEmToiThieu: params.CauHinhNhomTours.TuoiTreEmToiThieu,
+			TuoiTreEmToiDa:    params.CauHinhNhomTours.TuoiTreEmToiDa,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			groupConfig, err = qtx.CreateGroupConfig(ctx, CreateGroupConfigParams{
+				TourID:            tourID,
+				SoNhoNhat:         params.CauHinhNhomTours.SoNhoNhat,
+				SoLonNhat:         params.CauHinhNhomTours.SoLonNhat,
+				TuoiTreEmToiThieu: params.CauHinhNhomTours.TuoiTreEmToiThieu,
+				TuoiTreEmToiDa:    params.CauHinhNhomTours.TuoiTreEmToiDa,
+			})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update group config: %w", err)
+		}
+		result.GroupConfigID = &groupConfig.ID
+	}
+
+	// BƯỚC 6: THÊM LỊCH KHỞI HÀNH MỚI (NẾU CÓ)
+	for i, depInput := range params.LichKhoiHanhTours {
+		var ngayKhoiHanh, ngayKetThuc pgtype.Date
+		if err := ngayKhoiHanh.Scan(depInput.NgayKhoiHanh); err != nil {
+			return nil, fmt.Errorf("invalid ngay_khoi_hanh format for departure #%d: %w",
+				i+1, err)
+		}
+		if err := ngayKetThuc.Scan(depInput.NgayKetThuc); err != nil {
+			return nil, fmt.Errorf("invalid ngay_ket_thuc format for departure #%d: %w",
+				i+1, err)
+		}
+
+		var trangThai NullTrangThaiKhoiHanh
+		if depInput.TrangThai != nil && *depInput.TrangThai != "" {
+			trangThai.Valid = true
+			trangThai.TrangThaiKhoiHanh = TrangThaiKhoiHanh(*depInput.TrangThai)
+		}
+
+		departure, err := qtx.CreateDeparture(ctx, CreateDepartureParams{
+			TourID:       tourID,
+			NgayKhoiHanh: ngayKhoiHanh,
+			NgayKetThuc:  ngayKetThuc,
+			SucChua:      depInput.SucChua,
+			TrangThai:    trangThai,
+			GhiChu:       depInput.GhiChu,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create departure #%d: %w", i+1, err)
+		}
+		result.Departures = append(result.Departures, departure)
+	}
+
+	return result, nil
+}
+
+// Trạng thái kiểm duyệt của tour (tour.trang_thai) và phiên bản (phien_ban_tour)
+const (
+	TourStatusDraft     = "nhap"
+	TourStatusPending   = "cho_duyet"
+	TourStatusPublished = "cong_bo"
+	TourStatusRejected  = "tu_choi"
+	TourStatusArchived  = "luu_tru"
+
+	TourRevisionSubmit = "gui_duyet" // bản nháp gửi duyệt lần đầu
+	TourRevisionEdit   = "chinh_sua" // chỉnh sửa tour đang công bố
+
+	TourRevisionPending   = "cho_duyet"
+	TourRevisionApproved  = "da_duyet"
+	TourRevisionRejected  = "tu_choi"
+	TourRevisionCancelled = "da_huy"
+)
+
+// SubmitTourRevision gửi nội dung tour chờ duyệt, thay cho phiên bản đang chờ (nếu có).
+// arg.NoiDung rỗng thì chụp nội dung hiện tại của tour. Phiên bản gui_duyet chuyển tour sang cho_duyet;
+// phiên bản chinh_sua giữ nguyên tour đang công bố cho đến khi được duyệt
+func (t *Travia) SubmitTourRevision(ctx context.Context, arg CreateTourRevisionParams) (revision PhienBanTour, err error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return PhienBanTour{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if _, err = qtx.GetTourForUpdate(ctx, arg.TourID); err != nil {
+		return PhienBanTour{}, fmt.Errorf("failed to lock tour: %w", err)
+	}
+	if _, err = qtx.CancelPendingTourRevisions(ctx, arg.TourID); err != nil {
+		return PhienBanTour{}, fmt.Errorf("failed to cancel pending revisions: %w", err)
+	}
+	if len(arg.NoiDung) == 0 {
+		if arg.NoiDung, err = qtx.GetTourContentSnapshot(ctx, arg.TourID); err != nil {
+			return PhienBanTour{}, fmt.Errorf("failed to snapshot tour content: %w", err)
+		}
+	}
+	if revision, err = qtx.CreateTourRevision(ctx, arg); err != nil {
+		return PhienBanTour{}, fmt.Errorf("failed to create tour revision: %w", err)
+	}
+	if arg.Loai == TourRevisionSubmit {
+		if _, err = qtx.SetTourModerationStatus(ctx, SetTourModerationStatusParams{
+			ID:        arg.TourID,
+			TrangThai: TourStatusPending,
+		}); err != nil {
+			return PhienBanTour{}, fmt.Errorf("failed to update tour status: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return PhienBanTour{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return revision, nil
+}
+
+// ApproveTourRevision duyệt phiên bản đang chờ của tour tourID và áp dụng nội dung của nó
+// (details, đã dựng từ phien_ban_tour.noi_dung) lên tour trong cùng transaction.
+// Trả về pgx.ErrNoRows nếu phiên bản không còn chờ duyệt
+func (t *Travia) ApproveTourRevision(ctx context.Context, tourID int32, arg ReviewTourRevisionParams, details CreateTourWithDetailsParams) (revision PhienBanTour, result *CreateTourWithDetailsResult, err error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return PhienBanTour{}, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	// Khóa tour trước phiên bản (cùng thứ tự với SubmitTourRevision / WithdrawTourRevision)
+	if _, err = qtx.GetTourForUpdate(ctx, tourID); err != nil {
+		return PhienBanTour{}, nil, fmt.Errorf("failed to lock tour: %w", err)
+	}
+	arg.TrangThai = TourRevisionApproved
+	if revision, err = qtx.ReviewTourRevision(ctx, arg); err != nil {
+		return PhienBanTour{}, nil, err
+	}
+	if revision.TourID != tourID {
+		err = pgx.ErrNoRows
+		return PhienBanTour{}, nil, err
+	}
+	if result, err = applyTourDetails(ctx, qtx, tourID, details); err != nil {
+		return PhienBanTour{}, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return PhienBanTour{}, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return revision, result, nil
+}
+
+// RejectTourRevision từ chối phiên bản đang chờ của tour tourID; bản nháp gửi duyệt bị từ chối thì tour chuyển sang tu_choi.
+// Trả về pgx.ErrNoRows nếu phiên bản không còn chờ duyệt
+func (t *Travia) RejectTourRevision(ctx context.Context, tourID int32, arg ReviewTourRevisionParams) (revision PhienBanTour, err error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return PhienBanTour{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if _, err = qtx.GetTourForUpdate(ctx, tourID); err != nil {
+		return PhienBanTour{}, fmt.Errorf("failed to lock tour: %w", err)
+	}
+	arg.TrangThai = TourRevisionRejected
+	if revision, err = qtx.ReviewTourRevision(ctx, arg); err != nil {
+		return PhienBanTour{}, err
+	}
+	if revision.TourID != tourID {
+		err = pgx.ErrNoRows
+		return PhienBanTour{}, err
+	}
+	if revision.Loai == TourRevisionSubmit {
+		if _, err = qtx.SetTourModerationStatus(ctx, SetTourModerationStatusParams{
+			ID:        revision.TourID,
+			TrangThai: TourStatusRejected,
+		}); err != nil {
+			return PhienBanTour{}, fmt.Errorf("failed to update tour status: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return PhienBanTour{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return revision, nil
 }
 
+// WithdrawTourRevision hủy phiên bản đang chờ duyệt của tour rồi đổi trạng thái tour theo arg (kiểm tra nhà cung cấp).
+// arg.TrangThai nil: chỉ rút phiên bản, bản nháp đang chờ duyệt quay về nhap
+func (t *Travia) WithdrawTourRevision(ctx context.Context, arg UpdateTourStatusParams) (tour Tour, err error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return Tour{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if tour, err = qtx.GetTourForUpdate(ctx, arg.ID); err != nil {
+		return Tour{}, err
+	}
+	if _, err = qtx.CancelPendingTourRevisions(ctx, arg.ID); err != nil {
+		return Tour{}, fmt.Errorf("failed to cancel pending revisions: %w", err)
+	}
+	if arg.TrangThai == nil && tour.TrangThai != nil && *tour.TrangThai == TourStatusPending {
+		draft := TourStatusDraft
+		arg.TrangThai = &draft
+	}
+	if arg.TrangThai != nil {
+		if tour, err = qtx.UpdateTourStatus(ctx, arg); err != nil {
+			return Tour{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return Tour{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return tour, nil
+}
 
 type CreateSupplierWithUserParams struct {
 	CreateUserParams
@@ -447,4 +823,685 @@ func (t *Travia) UpdateSupplierWithUser(ctx context.Context, req UpdateSupplierW
 		NhaCungCap: supplier,
 	}, nil
 
-}
\ No newline at end of file
+}
+
+type SyncSupplierPayoutsResult struct {
+	CapNhat []ChiTraNhaCungCap
+	DaHuy   []ChiTraNhaCungCap
+}
+
+// SyncSupplierPayouts tính lại sổ chi trả nhà cung cấp trong một transaction:
+// cập nhật/tạo khoản chi trả theo khởi hành và hủy các khoản không còn hợp lệ
+func (t *Travia) SyncSupplierPayouts(ctx context.Context, tyLeHoaHong pgtype.Numeric) (*SyncSupplierPayoutsResult, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	updated, err := qtx.UpsertSupplierPayouts(ctx, tyLeHoaHong)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert supplier payouts: %w", err)
+	}
+	cancelled, err := qtx.CancelStaleSupplierPayouts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel stale supplier payouts: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &SyncSupplierPayoutsResult{
+		CapNhat: updated,
+		DaHuy:   cancelled,
+	}, nil
+}
+
+// CreateSupplierBankAccount thêm tài khoản ngân hàng; tài khoản đầu tiên
+// hoặc tài khoản được đánh dấu mặc định sẽ thay thế tài khoản mặc định hiện tại
+func (t *Travia) CreateSupplierBankAccount(ctx context.Context, arg CreateBankAccountParams) (TaiKhoanNganHang, error) {
+	var account TaiKhoanNganHang
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return account, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	existing, err := qtx.GetSupplierBankAccounts(ctx, arg.NhaCungCapID)
+	if err != nil {
+		return account, fmt.Errorf("failed to get bank accounts: %w", err)
+	}
+	laMacDinh := len(existing) == 0 || (arg.LaMacDinh != nil && *arg.LaMacDinh)
+	if laMacDinh {
+		if err = qtx.ClearDefaultBankAccount(ctx, arg.NhaCungCapID); err != nil {
+			return account, fmt.Errorf("failed to clear default bank account: %w", err)
+		}
+	}
+	arg.LaMacDinh = &laMacDinh
+	account, err = qtx.CreateBankAccount(ctx, arg)
+	if err != nil {
+		return account, fmt.Errorf("failed to create bank account: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return account, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return account, nil
+}
+
+// ChangeDefaultBankAccount đổi tài khoản ngân hàng mặc định của nhà cung cấp
+func (t *Travia) ChangeDefaultBankAccount(ctx context.Context, arg SetDefaultBankAccountParams) (TaiKhoanNganHang, error) {
+	var account TaiKhoanNganHang
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return account, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if err = qtx.ClearDefaultBankAccount(ctx, arg.NhaCungCapID); err != nil {
+		return account, fmt.Errorf("failed to clear default bank account: %w", err)
+	}
+	account, err = qtx.SetDefaultBankAccount(ctx, arg)
+	if err != nil {
+		return account, fmt.Errorf("failed to set default bank account: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return account, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return account, nil
+}
+
+// ReconcileBankStatementLine xác nhận giao dịch chuyển khoản khớp với dòng sao kê:
+// giao dịch thanh_cong, booking da_thanh_toan và dòng sao kê da_doi_soat trong cùng một transaction
+func (t *Travia) ReconcileBankStatementLine(ctx context.Context, lineID int32, transactionID int32, bankRef string) (ConfirmTransactionPaymentRow, error) {
+	var confirmed ConfirmTransactionPaymentRow
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return confirmed, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	confirmed, err = qtx.ConfirmTransactionPayment(ctx, ConfirmTransactionPaymentParams{
+		ID:                  transactionID,
+		MaThamChieu:         &bankRef,
+		PhuongThucThanhToan: "bank_transfer",
+	})
+	if err != nil {
+		return confirmed, fmt.Errorf("failed to confirm transaction: %w", err)
+	}
+	_, err = qtx.UpdateBankStatementLineStatus(ctx, UpdateBankStatementLineStatusParams{
+		ID:         lineID,
+		TrangThai:  TrangThaiDoiSoatDaDoiSoat,
+		GiaoDichID: &transactionID,
+	})
+	if err != nil {
+		return confirmed, fmt.Errorf("failed to update bank statement line: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return confirmed, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return confirmed, nil
+}
+
+// CancelBookingWithRefundResult là kết quả hủy booking kèm khoản hoàn tiền được tạo (nếu có)
+type CancelBookingWithRefundResult struct {
+	CancelBookingRow
+	HoanTien *LichSuGiaoDich `json:"hoan_tien"`
+}
+
+// CancelBookingWithRefund hủy booking và tạo yêu cầu hoàn tiền (trạng thái dang_xuly, chờ admin duyệt)
+// cho giao dịch thanh toán thành công của booking trong cùng một transaction
+func (t *Travia) CancelBookingWithRefund(ctx context.Context, bookingID int32, refundCode string) (CancelBookingWithRefundResult, error) {
+	var result CancelBookingWithRefundResult
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	result.CancelBookingRow, err = qtx.CancelBooking(ctx, bookingID)
+	if err != nil {
+		return result, err
+	}
+
+	soTienHoan, _ := result.SoTienHoan.Float64Value()
+	if soTienHoan.Valid && soTienHoan.Float64 > 0 {
+		paid, getErr := qtx.GetPaidTransactionByBooking(ctx, &bookingID)
+		if getErr != nil && !errors.Is(getErr, pgx.ErrNoRows) {
+			err = getErr
+			return result, fmt.Errorf("failed to get paid transaction: %w", err)
+		}
+		if getErr == nil {
+			lyDo := result.LyDo
+			refund, createErr := qtx.CreateRefundTransaction(ctx, CreateRefundTransactionParams{
+				MaGiaoDichNoiBo: refundCode,
+				SoTien:          result.SoTienHoan,
+				LyDo:            &lyDo,
+				GiaoDichGocID:   paid.ID,
+			})
+			if createErr != nil && !errors.Is(createErr, pgx.ErrNoRows) {
+				err = createErr
+				return result, fmt.Errorf("failed to create refund transaction: %w", err)
+			}
+			if createErr == nil {
+				result.HoanTien = &refund
+			}
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// CancellationPolicyTierInput là một mốc hoàn tiền của chính sách hủy
+type CancellationPolicyTierInput struct {
+	SoNgayToiThieu int32
+	PhanTramHoan   pgtype.Numeric
+}
+
+// CancellationPolicyWithTiers là chính sách hủy kèm các mốc hoàn tiền
+type CancellationPolicyWithTiers struct {
+	ChinhSachHuy
+	CacBac []BacChinhSachHuy `json:"cac_bac"`
+}
+
+// CreateCancellationPolicyWithTiers tạo chính sách hủy và các mốc hoàn tiền trong cùng một transaction
+func (t *Travia) CreateCancellationPolicyWithTiers(ctx context.Context, arg CreateCancellationPolicyParams, tiers []CancellationPolicyTierInput) (CancellationPolicyWithTiers, error) {
+	var result CancellationPolicyWithTiers
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	result.ChinhSachHuy, err = qtx.CreateCancellationPolicy(ctx, arg)
+	if err != nil {
+		return result, fmt.Errorf("failed to create cancellation policy: %w", err)
+	}
+	result.CacBac, err = createCancellationPolicyTiers(ctx, qtx, result.ID, tiers)
+	if err != nil {
+		return result, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateCancellationPolicyWithTiers cập nhật chính sách hủy và thay toàn bộ các mốc hoàn tiền.
+// Trả về pgx.ErrNoRows nếu chính sách không thuộc nhà cung cấp
+func (t *Travia) UpdateCancellationPolicyWithTiers(ctx context.Context, arg UpdateCancellationPolicyParams, tiers []CancellationPolicyTierInput) (CancellationPolicyWithTiers, error) {
+	var result CancellationPolicyWithTiers
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	result.ChinhSachHuy, err = qtx.UpdateCancellationPolicy(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+	if err = qtx.DeleteCancellationPolicyTiers(ctx, result.ID); err != nil {
+		return result, fmt.Errorf("failed to delete cancellation policy tiers: %w", err)
+	}
+	result.CacBac, err = createCancellationPolicyTiers(ctx, qtx, result.ID, tiers)
+	if err != nil {
+		return result, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+func createCancellationPolicyTiers(ctx context.Context, qtx *Queries, policyID int32, tiers []CancellationPolicyTierInput) ([]BacChinhSachHuy, error) {
+	created := make([]BacChinhSachHuy, 0, len(tiers))
+	for _, tier := range tiers {
+		bac, err := qtx.CreateCancellationPolicyTier(ctx, CreateCancellationPolicyTierParams{
+			ChinhSachHuyID: policyID,
+			SoNgayToiThieu: tier.SoNgayToiThieu,
+			PhanTramHoan:   tier.PhanTramHoan,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cancellation policy tier: %w", err)
+		}
+		created = append(created, bac)
+	}
+	return created, nil
+}
+
+// ReplaceBookingPassengersParams chứa danh sách hành khách mới của booking.
+// Khi số người lớn / trẻ em khác booking hiện tại thì booking được tính lại giá
+type ReplaceBookingPassengersParams struct {
+	BookingID  int32
+	SoNguoiLon int32
+	SoTreEm    int32
+	TinhLaiGia bool
+	Passengers []AddPassengersParams
+}
+
+// ReplaceBookingPassengers ghi lại toàn bộ hành khách của booking và tính lại giá nếu loại khách thay đổi.
+// Trả về booking sau khi tính lại giá (nil nếu không đổi).
+// pgx.ErrNoRows: booking đã thanh toán / hủy / hoàn thành, hoặc không còn cho phép đổi loại khách
+func (t *Travia) ReplaceBookingPassengers(ctx context.Context, arg ReplaceBookingPassengersParams) (*DatCho, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	// Khóa booking và chỉ cho sửa khi chưa thanh toán
+	if _, err = qtx.LockEditableBookingForPassengers(ctx, arg.BookingID); err != nil {
+		return nil, fmt.Errorf("booking is not editable: %w", err)
+	}
+
+	var repriced *DatCho
+	if arg.TinhLaiGia {
+		booking, updateErr := qtx.UpdateBookingPassengerMix(ctx, UpdateBookingPassengerMixParams{
+			ID:         arg.BookingID,
+			SoNguoiLon: arg.SoNguoiLon,
+			SoTreEm:    arg.SoTreEm,
+		})
+		if updateErr != nil {
+			err = updateErr
+			return nil, fmt.Errorf("failed to reprice booking: %w", err)
+		}
+		repriced = &booking
+
+		// Giao dịch đang chờ mang số tiền cũ nên không được dùng tiếp
+		bookingID := arg.BookingID
+		if _, err = qtx.CancelPendingBookingTransactions(ctx, &bookingID); err != nil {
+			return nil, fmt.Errorf("failed to cancel pending transactions: %w", err)
+		}
+	}
+
+	if err = qtx.DeletePassengersByBooking(ctx, arg.BookingID); err != nil {
+		return nil, fmt.Errorf("failed to delete passengers: %w", err)
+	}
+	if _, err = qtx.AddPassengers(ctx, arg.Passengers); err != nil {
+		return nil, fmt.Errorf("failed to add passengers: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return repriced, nil
+}
+
+// EnableTwoFactor kích hoạt 2FA và thay toàn bộ mã khôi phục bằng danh sách mới (đã băm)
+func (t *Travia) EnableTwoFactor(ctx context.Context, userID pgtype.UUID, recoveryHashes []string) error {
+	return t.replaceRecoveryCodes(ctx, userID, recoveryHashes, true)
+}
+
+// ReplaceRecoveryCodes thay toàn bộ mã khôi phục của người dùng bằng danh sách mới (đã băm)
+func (t *Travia) ReplaceRecoveryCodes(ctx context.Context, userID pgtype.UUID, recoveryHashes []string) error {
+	return t.replaceRecoveryCodes(ctx, userID, recoveryHashes, false)
+}
+
+func (t *Travia) replaceRecoveryCodes(ctx context.Context, userID pgtype.UUID, recoveryHashes []string, activate bool) error {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if activate {
+		if err = qtx.ActivateTwoFactor(ctx, userID); err != nil {
+			return fmt.Errorf("failed to activate two-factor: %w", err)
+		}
+	}
+	if err = qtx.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	params := make([]CreateRecoveryCodesParams, 0, len(recoveryHashes))
+	for _, h := range recoveryHashes {
+		params = append(params, CreateRecoveryCodesParams{NguoiDungID: userID, MaBam: h})
+	}
+	if _, err = qtx.CreateRecoveryCodes(ctx, params); err != nil {
+		return fmt.Errorf("failed to create recovery codes: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DisableTwoFactor tắt 2FA: xóa khóa TOTP và mọi mã khôi phục
+func (t *Travia) DisableTwoFactor(ctx context.Context, userID pgtype.UUID) error {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if err = qtx.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if err = qtx.DeleteTwoFactor(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete two-factor: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetRolePermissions thay toàn bộ quyền của vai trò bằng danh sách mới
+func (t *Travia) SetRolePermissions(ctx context.Context, roleCode string, permissions []string) error {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if err = qtx.DeleteRolePermissions(ctx, roleCode); err != nil {
+		return fmt.Errorf("failed to delete role permissions: %w", err)
+	}
+	if len(permissions) > 0 {
+		if err = qtx.AddRolePermissions(ctx, AddRolePermissionsParams{VaiTroMa: roleCode, Quyen: permissions}); err != nil {
+			return fmt.Errorf("failed to add role permissions: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetUserRoles thay toàn bộ vai trò được gán thêm của người dùng
+func (t *Travia) SetUserRoles(ctx context.Context, userID pgtype.UUID, roles []string, assignedBy pgtype.UUID) error {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if err = qtx.DeleteUserRoles(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user roles: %w", err)
+	}
+	if len(roles) > 0 {
+		if err = qtx.AddUserRoles(ctx, AddUserRolesParams{NguoiDungID: userID, VaiTro: roles, NguoiGan: assignedBy}); err != nil {
+			return fmt.Errorf("failed to add user roles: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// InviteSupplierStaff tạo tài khoản người dùng (chưa kích hoạt) và bản ghi nhân viên của nhà cung cấp
+func (t *Travia) InviteSupplierStaff(ctx context.Context, user CreateUserParams, staff CreateSupplierStaffParams) (NhanVienNhaCungCap, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return NhanVienNhaCungCap{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	createdUser, err := qtx.CreateUser(ctx, user)
+	if err != nil {
+		return NhanVienNhaCungCap{}, fmt.Errorf("failed to create user: %w", err)
+	}
+	staff.NguoiDungID = createdUser.ID
+	member, err := qtx.CreateSupplierStaff(ctx, staff)
+	if err != nil {
+		return NhanVienNhaCungCap{}, fmt.Errorf("failed to create supplier staff: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return NhanVienNhaCungCap{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return member, nil
+}
+
+// AcceptSupplierStaffInvitation đặt mật khẩu, kích hoạt tài khoản và đánh dấu nhân viên đã tham gia
+func (t *Travia) AcceptSupplierStaffInvitation(ctx context.Context, staffID int32, userID pgtype.UUID, passwordHash string) error {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if err = qtx.ActivateInvitedUser(ctx, ActivateInvitedUserParams{ID: userID, MatKhauMaHoa: passwordHash}); err != nil {
+		return fmt.Errorf("failed to activate user: %w", err)
+	}
+	if err = qtx.ActivateSupplierStaff(ctx, staffID); err != nil {
+		return fmt.Errorf("failed to activate supplier staff: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// EraseUserAccount thực hiện yêu cầu xóa tài khoản: ẩn danh dữ liệu phải lưu cho kế toán
+// (dat_cho, hanh_khach, lich_su_giao_dich, bản ghi nguoi_dung) và xóa hẳn phần còn lại
+func (t *Travia) EraseUserAccount(ctx context.Context, requestID int32, userID pgtype.UUID, anonymizedEmail, passwordHash string) error {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	if err = qtx.AnonymizeUserPassengers(ctx, userID); err != nil {
+		return fmt.Errorf("failed to anonymize passengers: %w", err)
+	}
+	if err = qtx.AnonymizeUserTransactions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to anonymize transactions: %w", err)
+	}
+
+	deletes := []struct {
+		name string
+		fn   func(context.Context, pgtype.UUID) error
+	}{
+		{"reviews", qtx.DeleteUserReviews},
+		{"favorites", qtx.DeleteUserFavorites},
+		{"chat history", qtx.DeleteChatHistoryByUserID},
+		{"view history", qtx.DeleteUserViewHistory},
+		{"preferences", qtx.DeleteUserPreferences},
+		{"notifications", qtx.DeleteUserNotifications},
+		{"waitlist entries", qtx.DeleteUserWaitlistEntries},
+		{"seat holds", qtx.DeleteUserFinishedSeatHolds},
+		{"contacts", qtx.DeleteUserContacts},
+		{"blog comments", qtx.DeleteUserBlogComments},
+		{"sessions", qtx.DeleteUserSessions},
+		{"login history", qtx.DeleteUserLoginHistory},
+		{"password reset otps", qtx.DeleteUserPasswordResetOtps},
+		{"recovery codes", qtx.DeleteUserRecoveryCodes},
+		{"two factor", qtx.DeleteUserTwoFactor},
+		{"user roles", qtx.DeleteUserRoles},
+		{"oauth accounts", qtx.DeleteUserOAuthAccounts},
+	}
+	for _, d := range deletes {
+		if err = d.fn(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", d.name, err)
+		}
+	}
+
+	if err = qtx.AnonymizeUser(ctx, AnonymizeUserParams{
+		ID:           userID,
+		Email:        anonymizedEmail,
+		MatKhauMaHoa: passwordHash,
+	}); err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if err = qtx.CompleteAccountDeletion(ctx, requestID); err != nil {
+		return fmt.Errorf("failed to complete deletion request: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreatePartnerWithAccount tạo tài khoản dịch vụ (đứng tên booking qua API) và đối tác
+func (t *Travia) CreatePartnerWithAccount(ctx context.Context, user CreateUserParams, partner CreatePartnerParams) (DoiTac, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return DoiTac{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	createdUser, err := qtx.CreateUser(ctx, user)
+	if err != nil {
+		return DoiTac{}, fmt.Errorf("failed to create service account: %w", err)
+	}
+	partner.NguoiDungID = createdUser.ID
+	created, err := qtx.CreatePartner(ctx, partner)
+	if err != nil {
+		return DoiTac{}, fmt.Errorf("failed to create partner: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return DoiTac{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return created, nil
+}
+
+// CreatePartnerBooking tạo booking từ mã giữ chỗ và ghi nhận đối tác / khóa API tạo booking để tính hoa hồng
+func (t *Travia) CreatePartnerBooking(ctx context.Context, arg CreateBookingFromHoldParams, partnerID, apiKeyID int32) (int32, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	bookingID, err := qtx.CreateBookingFromHold(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+	if err = qtx.AttributeBookingToPartner(ctx, AttributeBookingToPartnerParams{
+		ID:        bookingID,
+		DoiTacID:  &partnerID,
+		KhoaApiID: &apiKeyID,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to attribute booking to partner: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return bookingID, nil
+}
+
+// CreateOAuthUser tạo tài khoản mới từ đăng nhập mạng xã hội (chưa có mật khẩu riêng) và liên kết tài khoản OAuth
+func (t *Travia) CreateOAuthUser(ctx context.Context, user CreateUserParams, account CreateOAuthAccountParams) (NguoiDung, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return NguoiDung{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	qtx := t.Queries.WithTx(tx)
+
+	createdUser, err := qtx.CreateUser(ctx, user)
+	if err != nil {
+		return NguoiDung{}, fmt.Errorf("failed to create user: %w", err)
+	}
+	if err = qtx.MarkUserWithoutPassword(ctx, createdUser.ID); err != nil {
+		return NguoiDung{}, fmt.Errorf("failed to mark user without password: %w", err)
+	}
+	createdUser.CoMatKhau = false
+
+	account.NguoiDungID = createdUser.ID
+	if _, err = qtx.CreateOAuthAccount(ctx, account); err != nil {
+		return NguoiDung{}, fmt.Errorf("failed to link oauth account: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return NguoiDung{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return createdUser, nil
+}
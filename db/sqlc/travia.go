@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,7 +13,30 @@ type Z interface {
 	// Transaction methods for complex operations
 	CreateTourWithDetails(ctx context.Context, params CreateTourWithDetailsParams) (*CreateTourWithDetailsResult, error)
 	UpdateTourWithDetails(ctx context.Context, tourID int32, params CreateTourWithDetailsParams) (*CreateTourWithDetailsResult, error)
+	SubmitTourRevision(ctx context.Context, arg CreateTourRevisionParams) (PhienBanTour, error)
+	ApproveTourRevision(ctx context.Context, tourID int32, arg ReviewTourRevisionParams, details CreateTourWithDetailsParams) (PhienBanTour, *CreateTourWithDetailsResult, error)
+	RejectTourRevision(ctx context.Context, tourID int32, arg ReviewTourRevisionParams) (PhienBanTour, error)
+	WithdrawTourRevision(ctx context.Context, arg UpdateTourStatusParams) (Tour, error)
 	CreateSupplierWithUser(ctx context.Context, req CreateSupplierWithUserParams) (*CreateSupplierWithUserResult, error)
+	SyncSupplierPayouts(ctx context.Context, tyLeHoaHong pgtype.Numeric) (*SyncSupplierPayoutsResult, error)
+	CreateSupplierBankAccount(ctx context.Context, arg CreateBankAccountParams) (TaiKhoanNganHang, error)
+	ChangeDefaultBankAccount(ctx context.Context, arg SetDefaultBankAccountParams) (TaiKhoanNganHang, error)
+	ReconcileBankStatementLine(ctx context.Context, lineID int32, transactionID int32, bankRef string) (ConfirmTransactionPaymentRow, error)
+	CancelBookingWithRefund(ctx context.Context, bookingID int32, refundCode string) (CancelBookingWithRefundResult, error)
+	CreateCancellationPolicyWithTiers(ctx context.Context, arg CreateCancellationPolicyParams, tiers []CancellationPolicyTierInput) (CancellationPolicyWithTiers, error)
+	UpdateCancellationPolicyWithTiers(ctx context.Context, arg UpdateCancellationPolicyParams, tiers []CancellationPolicyTierInput) (CancellationPolicyWithTiers, error)
+	ReplaceBookingPassengers(ctx context.Context, arg ReplaceBookingPassengersParams) (*DatCho, error)
+	EnableTwoFactor(ctx context.Context, userID pgtype.UUID, recoveryHashes []string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID pgtype.UUID, recoveryHashes []string) error
+	DisableTwoFactor(ctx context.Context, userID pgtype.UUID) error
+	SetRolePermissions(ctx context.Context, roleCode string, permissions []string) error
+	SetUserRoles(ctx context.Context, userID pgtype.UUID, roles []string, assignedBy pgtype.UUID) error
+	InviteSupplierStaff(ctx context.Context, user CreateUserParams, staff CreateSupplierStaffParams) (NhanVienNhaCungCap, error)
+	AcceptSupplierStaffInvitation(ctx context.Context, staffID int32, userID pgtype.UUID, passwordHash string) error
+	EraseUserAccount(ctx context.Context, requestID int32, userID pgtype.UUID, anonymizedEmail, passwordHash string) error
+	CreatePartnerWithAccount(ctx context.Context, user CreateUserParams, partner CreatePartnerParams) (DoiTac, error)
+	CreatePartnerBooking(ctx context.Context, arg CreateBookingFromHoldParams, partnerID, apiKeyID int32) (int32, error)
+	CreateOAuthUser(ctx context.Context, user CreateUserParams, account CreateOAuthAccountParams) (NguoiDung, error)
 }
 
 type Travia struct {
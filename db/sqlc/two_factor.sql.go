@@ -0,0 +1,234 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: two_factor.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const activateTwoFactor = `-- name: ActivateTwoFactor :exec
+UPDATE xac_thuc_hai_buoc
+SET da_kich_hoat = TRUE,
+    ngay_kich_hoat = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) ActivateTwoFactor(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, activateTwoFactor, nguoiDungID)
+	return err
+}
+
+const consumeTwoFactorStep = `-- name: ConsumeTwoFactorStep :execrows
+UPDATE xac_thuc_hai_buoc
+SET buoc_thoi_gian_cuoi = $1::bigint,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = $2
+    AND (buoc_thoi_gian_cuoi IS NULL OR buoc_thoi_gian_cuoi < $1::bigint)
+`
+
+type ConsumeTwoFactorStepParams struct {
+	BuocThoiGian int64       `json:"buoc_thoi_gian"`
+	NguoiDungID  pgtype.UUID `json:"nguoi_dung_id"`
+}
+
+// Ghi nhận bước thời gian của mã TOTP vừa dùng; 0 dòng nghĩa là mã đã được dùng trước đó
+func (q *Queries) ConsumeTwoFactorStep(ctx context.Context, arg ConsumeTwoFactorStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, consumeTwoFactorStep, arg.BuocThoiGian, arg.NguoiDungID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countRemainingRecoveryCodes = `-- name: CountRemainingRecoveryCodes :one
+SELECT COUNT(*)::int FROM ma_khoi_phuc_2fa
+WHERE nguoi_dung_id = $1 AND da_dung_luc IS NULL
+`
+
+func (q *Queries) CountRemainingRecoveryCodes(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, countRemainingRecoveryCodes, nguoiDungID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+type CreateRecoveryCodesParams struct {
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	MaBam       string      `json:"ma_bam"`
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM ma_khoi_phuc_2fa
+WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteRecoveryCodes, nguoiDungID)
+	return err
+}
+
+const deleteTwoFactor = `-- name: DeleteTwoFactor :exec
+DELETE FROM xac_thuc_hai_buoc
+WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) DeleteTwoFactor(ctx context.Context, nguoiDungID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteTwoFactor, nguoiDungID)
+	return err
+}
+
+const getTwoFactorByUser = `-- name: GetTwoFactorByUser :one
+
+SELECT nguoi_dung_id, bi_mat_ma_hoa, da_kich_hoat, buoc_thoi_gian_cuoi, ngay_kich_hoat, ngay_tao, ngay_cap_nhat FROM xac_thuc_hai_buoc
+WHERE nguoi_dung_id = $1
+`
+
+// ===========================================
+// XÁC THỰC HAI BƯỚC (TOTP 2FA)
+// ===========================================
+func (q *Queries) GetTwoFactorByUser(ctx context.Context, nguoiDungID pgtype.UUID) (XacThucHaiBuoc, error) {
+	row := q.db.QueryRow(ctx, getTwoFactorByUser, nguoiDungID)
+	var i XacThucHaiBuoc
+	err := row.Scan(
+		&i.NguoiDungID,
+		&i.BiMatMaHoa,
+		&i.DaKichHoat,
+		&i.BuocThoiGianCuoi,
+		&i.NgayKichHoat,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const getTwoFactorPolicies = `-- name: GetTwoFactorPolicies :many
+SELECT vai_tro, bat_buoc, nguoi_cap_nhat, ngay_cap_nhat FROM chinh_sach_2fa
+ORDER BY vai_tro
+`
+
+func (q *Queries) GetTwoFactorPolicies(ctx context.Context) ([]ChinhSach2fa, error) {
+	rows, err := q.db.Query(ctx, getTwoFactorPolicies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChinhSach2fa
+	for rows.Next() {
+		var i ChinhSach2fa
+		if err := rows.Scan(
+			&i.VaiTro,
+			&i.BatBuoc,
+			&i.NguoiCapNhat,
+			&i.NgayCapNhat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isTwoFactorRequired = `-- name: IsTwoFactorRequired :one
+SELECT COALESCE((
+    SELECT bat_buoc FROM chinh_sach_2fa WHERE vai_tro = $1
+), FALSE)::bool AS bat_buoc
+`
+
+func (q *Queries) IsTwoFactorRequired(ctx context.Context, vaiTro VaiTroNguoiDung) (bool, error) {
+	row := q.db.QueryRow(ctx, isTwoFactorRequired, vaiTro)
+	var bat_buoc bool
+	err := row.Scan(&bat_buoc)
+	return bat_buoc, err
+}
+
+const upsertPendingTwoFactor = `-- name: UpsertPendingTwoFactor :one
+INSERT INTO xac_thuc_hai_buoc (nguoi_dung_id, bi_mat_ma_hoa)
+VALUES ($1, $2)
+ON CONFLICT (nguoi_dung_id) DO UPDATE
+SET bi_mat_ma_hoa = EXCLUDED.bi_mat_ma_hoa,
+    buoc_thoi_gian_cuoi = NULL,
+    ngay_tao = CURRENT_TIMESTAMP,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE xac_thuc_hai_buoc.da_kich_hoat = FALSE
+RETURNING nguoi_dung_id, bi_mat_ma_hoa, da_kich_hoat, buoc_thoi_gian_cuoi, ngay_kich_hoat, ngay_tao, ngay_cap_nhat
+`
+
+type UpsertPendingTwoFactorParams struct {
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	BiMatMaHoa  string      `json:"bi_mat_ma_hoa"`
+}
+
+// Tạo (hoặc tạo lại) khóa TOTP chờ kích hoạt; không ghi đè khi 2FA đang bật
+func (q *Queries) UpsertPendingTwoFactor(ctx context.Context, arg UpsertPendingTwoFactorParams) (XacThucHaiBuoc, error) {
+	row := q.db.QueryRow(ctx, upsertPendingTwoFactor, arg.NguoiDungID, arg.BiMatMaHoa)
+	var i XacThucHaiBuoc
+	err := row.Scan(
+		&i.NguoiDungID,
+		&i.BiMatMaHoa,
+		&i.DaKichHoat,
+		&i.BuocThoiGianCuoi,
+		&i.NgayKichHoat,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const upsertTwoFactorPolicy = `-- name: UpsertTwoFactorPolicy :one
+INSERT INTO chinh_sach_2fa (vai_tro, bat_buoc, nguoi_cap_nhat)
+VALUES ($1, $2, $3)
+ON CONFLICT (vai_tro) DO UPDATE
+SET bat_buoc = EXCLUDED.bat_buoc,
+    nguoi_cap_nhat = EXCLUDED.nguoi_cap_nhat,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+RETURNING vai_tro, bat_buoc, nguoi_cap_nhat, ngay_cap_nhat
+`
+
+type UpsertTwoFactorPolicyParams struct {
+	VaiTro       VaiTroNguoiDung `json:"vai_tro"`
+	BatBuoc      bool            `json:"bat_buoc"`
+	NguoiCapNhat pgtype.UUID     `json:"nguoi_cap_nhat"`
+}
+
+func (q *Queries) UpsertTwoFactorPolicy(ctx context.Context, arg UpsertTwoFactorPolicyParams) (ChinhSach2fa, error) {
+	row := q.db.QueryRow(ctx, upsertTwoFactorPolicy, arg.VaiTro, arg.BatBuoc, arg.NguoiCapNhat)
+	var i ChinhSach2fa
+	err := row.Scan(
+		&i.VaiTro,
+		&i.BatBuoc,
+		&i.NguoiCapNhat,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE ma_khoi_phuc_2fa
+SET da_dung_luc = CURRENT_TIMESTAMP
+WHERE nguoi_dung_id = $1
+    AND ma_bam = $2
+    AND da_dung_luc IS NULL
+`
+
+type UseRecoveryCodeParams struct {
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	MaBam       string      `json:"ma_bam"`
+}
+
+// Dùng một mã khôi phục (mỗi mã chỉ dùng được một lần)
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, useRecoveryCode, arg.NguoiDungID, arg.MaBam)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -13,7 +13,7 @@ import (
 
 const changePassword = `-- name: ChangePassword :exec
 UPDATE nguoi_dung
-SET mat_khau_ma_hoa = $1
+SET mat_khau_ma_hoa = $1, co_mat_khau = TRUE
 WHERE id = $2
 `
 
@@ -66,9 +66,9 @@ func (q *Queries) DeleteExpiredOTPs(ctx context.Context) error {
 
 const forgotPassword = `-- name: ForgotPassword :exec
 UPDATE nguoi_dung
-SET mat_khau_ma_hoa = $1
+SET mat_khau_ma_hoa = $1, co_mat_khau = TRUE
 WHERE email = $2
-RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau
 `
 
 type ForgotPasswordParams struct {
@@ -198,9 +198,9 @@ func (q *Queries) InvalidateAllOTPsForEmail(ctx context.Context, email string) e
 
 const resetPassword = `-- name: ResetPassword :one
 UPDATE nguoi_dung
-SET mat_khau_ma_hoa = $1
+SET mat_khau_ma_hoa = $1, co_mat_khau = TRUE
 WHERE email = $2
-RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau
 `
 
 type ResetPasswordParams struct {
@@ -222,6 +222,7 @@ func (q *Queries) ResetPassword(ctx context.Context, arg ResetPasswordParams) (N
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
 	)
 	return i, err
 }
@@ -233,7 +234,7 @@ SET ho_ten = COALESCE($2, nguoi_dung.ho_ten),
     so_dien_thoai = COALESCE($4, nguoi_dung.so_dien_thoai),
     ngay_cap_nhat = CURRENT_TIMESTAMP
 WHERE id = $1 AND nguoi_dung.dang_hoat_dong = TRUE
-RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat
+RETURNING id, ho_ten, email, mat_khau_ma_hoa, so_dien_thoai, vai_tro, dang_hoat_dong, xac_thuc, ngay_tao, ngay_cap_nhat, co_mat_khau
 `
 
 type UpdateUserParams struct {
@@ -262,6 +263,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (NguoiDu
 		&i.XacThuc,
 		&i.NgayTao,
 		&i.NgayCapNhat,
+		&i.CoMatKhau,
 	)
 	return i, err
 }
@@ -0,0 +1,338 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: waitlist.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countMyWaitlist = `-- name: CountMyWaitlist :one
+SELECT COUNT(*)::int FROM danh_sach_cho
+WHERE nguoi_dung_id = $1
+`
+
+func (q *Queries) CountMyWaitlist(ctx context.Context, nguoiDungID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, countMyWaitlist, nguoiDungID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const countWaitlistByDeparture = `-- name: CountWaitlistByDeparture :one
+SELECT
+    COUNT(*)::int AS so_luot_cho,
+    COALESCE(SUM(so_nguoi_lon + so_tre_em), 0)::int AS so_khach_cho
+FROM danh_sach_cho
+WHERE khoi_hanh_id = $1 AND trang_thai = 'dang_cho'
+`
+
+type CountWaitlistByDepartureRow struct {
+	SoLuotCho  int32 `json:"so_luot_cho"`
+	SoKhachCho int32 `json:"so_khach_cho"`
+}
+
+// Số lượt và số khách đang chờ của một khởi hành
+func (q *Queries) CountWaitlistByDeparture(ctx context.Context, khoiHanhID int32) (CountWaitlistByDepartureRow, error) {
+	row := q.db.QueryRow(ctx, countWaitlistByDeparture, khoiHanhID)
+	var i CountWaitlistByDepartureRow
+	err := row.Scan(&i.SoLuotCho, &i.SoKhachCho)
+	return i, err
+}
+
+const getDeparturesWithPromotableWaitlist = `-- name: GetDeparturesWithPromotableWaitlist :many
+SELECT kh.id
+FROM khoi_hanh_tour kh
+WHERE kh.trang_thai NOT IN ('huy', 'hoan_thanh')
+    AND kh.ngay_khoi_hanh > CURRENT_DATE
+    AND EXISTS (
+        SELECT 1 FROM danh_sach_cho d
+        WHERE d.khoi_hanh_id = kh.id
+            AND d.trang_thai = 'dang_cho'
+            AND d.so_nguoi_lon + d.so_tre_em <= kh.suc_chua - COALESCE(kh.so_cho_da_dat, 0)
+    )
+`
+
+// Các khởi hành còn chỗ trống đủ cho ít nhất một lượt đang chờ
+func (q *Queries) GetDeparturesWithPromotableWaitlist(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.Query(ctx, getDeparturesWithPromotableWaitlist)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMyWaitlist = `-- name: GetMyWaitlist :many
+SELECT
+    d.id, d.khoi_hanh_id, d.nguoi_dung_id, d.so_nguoi_lon, d.so_tre_em, d.trang_thai, d.giu_cho_id, d.moi_luc, d.ngay_tao, d.ngay_cap_nhat,
+    kh.tour_id,
+    t.tieu_de AS ten_tour,
+    kh.ngay_khoi_hanh,
+    g.ma_giu_cho,
+    g.het_han_luc,
+    (
+        SELECT COUNT(*)
+        FROM danh_sach_cho d2
+        WHERE d2.khoi_hanh_id = d.khoi_hanh_id
+            AND d2.trang_thai = 'dang_cho'
+            AND (d2.ngay_tao, d2.id) < (d.ngay_tao, d.id)
+    )::int + 1 AS vi_tri
+FROM danh_sach_cho d
+JOIN khoi_hanh_tour kh ON kh.id = d.khoi_hanh_id
+JOIN tour t ON t.id = kh.tour_id
+LEFT JOIN giu_cho g ON g.id = d.giu_cho_id
+WHERE d.nguoi_dung_id = $1
+ORDER BY d.ngay_tao DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetMyWaitlistParams struct {
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	Limit       int32       `json:"limit"`
+	Offset      int32       `json:"offset"`
+}
+
+type GetMyWaitlistRow struct {
+	ID           int32            `json:"id"`
+	KhoiHanhID   int32            `json:"khoi_hanh_id"`
+	NguoiDungID  pgtype.UUID      `json:"nguoi_dung_id"`
+	SoNguoiLon   int32            `json:"so_nguoi_lon"`
+	SoTreEm      int32            `json:"so_tre_em"`
+	TrangThai    string           `json:"trang_thai"`
+	GiuChoID     *int32           `json:"giu_cho_id"`
+	MoiLuc       pgtype.Timestamp `json:"moi_luc"`
+	NgayTao      pgtype.Timestamp `json:"ngay_tao"`
+	NgayCapNhat  pgtype.Timestamp `json:"ngay_cap_nhat"`
+	TourID       int32            `json:"tour_id"`
+	TenTour      string           `json:"ten_tour"`
+	NgayKhoiHanh pgtype.Date      `json:"ngay_khoi_hanh"`
+	MaGiuCho     pgtype.UUID      `json:"ma_giu_cho"`
+	HetHanLuc    pgtype.Timestamp `json:"het_han_luc"`
+	ViTri        int32            `json:"vi_tri"`
+}
+
+// Danh sách chờ của người dùng kèm vị trí trong hàng đợi và giữ chỗ được mời (nếu có)
+func (q *Queries) GetMyWaitlist(ctx context.Context, arg GetMyWaitlistParams) ([]GetMyWaitlistRow, error) {
+	rows, err := q.db.Query(ctx, getMyWaitlist, arg.NguoiDungID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMyWaitlistRow
+	for rows.Next() {
+		var i GetMyWaitlistRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.KhoiHanhID,
+			&i.NguoiDungID,
+			&i.SoNguoiLon,
+			&i.SoTreEm,
+			&i.TrangThai,
+			&i.GiuChoID,
+			&i.MoiLuc,
+			&i.NgayTao,
+			&i.NgayCapNhat,
+			&i.TourID,
+			&i.TenTour,
+			&i.NgayKhoiHanh,
+			&i.MaGiuCho,
+			&i.HetHanLuc,
+			&i.ViTri,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const joinWaitlist = `-- name: JoinWaitlist :one
+
+INSERT INTO danh_sach_cho (
+    khoi_hanh_id,
+    nguoi_dung_id,
+    so_nguoi_lon,
+    so_tre_em
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, khoi_hanh_id, nguoi_dung_id, so_nguoi_lon, so_tre_em, trang_thai, giu_cho_id, moi_luc, ngay_tao, ngay_cap_nhat
+`
+
+type JoinWaitlistParams struct {
+	KhoiHanhID  int32       `json:"khoi_hanh_id"`
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+	SoNguoiLon  int32       `json:"so_nguoi_lon"`
+	SoTreEm     int32       `json:"so_tre_em"`
+}
+
+// ===========================================
+// DANH SÁCH CHỜ KHỞI HÀNH (WAITLIST)
+// ===========================================
+// Đăng ký vào danh sách chờ của khởi hành
+func (q *Queries) JoinWaitlist(ctx context.Context, arg JoinWaitlistParams) (DanhSachCho, error) {
+	row := q.db.QueryRow(ctx, joinWaitlist,
+		arg.KhoiHanhID,
+		arg.NguoiDungID,
+		arg.SoNguoiLon,
+		arg.SoTreEm,
+	)
+	var i DanhSachCho
+	err := row.Scan(
+		&i.ID,
+		&i.KhoiHanhID,
+		&i.NguoiDungID,
+		&i.SoNguoiLon,
+		&i.SoTreEm,
+		&i.TrangThai,
+		&i.GiuChoID,
+		&i.MoiLuc,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const leaveWaitlist = `-- name: LeaveWaitlist :one
+UPDATE danh_sach_cho
+SET trang_thai = 'da_huy',
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+WHERE id = $1
+    AND nguoi_dung_id = $2
+    AND trang_thai = 'dang_cho'
+RETURNING id, khoi_hanh_id, nguoi_dung_id, so_nguoi_lon, so_tre_em, trang_thai, giu_cho_id, moi_luc, ngay_tao, ngay_cap_nhat
+`
+
+type LeaveWaitlistParams struct {
+	ID          int32       `json:"id"`
+	NguoiDungID pgtype.UUID `json:"nguoi_dung_id"`
+}
+
+// Khách rời danh sách chờ (chỉ khi chưa được mời)
+func (q *Queries) LeaveWaitlist(ctx context.Context, arg LeaveWaitlistParams) (DanhSachCho, error) {
+	row := q.db.QueryRow(ctx, leaveWaitlist, arg.ID, arg.NguoiDungID)
+	var i DanhSachCho
+	err := row.Scan(
+		&i.ID,
+		&i.KhoiHanhID,
+		&i.NguoiDungID,
+		&i.SoNguoiLon,
+		&i.SoTreEm,
+		&i.TrangThai,
+		&i.GiuChoID,
+		&i.MoiLuc,
+		&i.NgayTao,
+		&i.NgayCapNhat,
+	)
+	return i, err
+}
+
+const promoteWaitlist = `-- name: PromoteWaitlist :many
+SELECT
+    m.danh_sach_cho_id::int AS danh_sach_cho_id,
+    m.khoi_hanh_id::int AS khoi_hanh_id,
+    m.nguoi_dung_id::uuid AS nguoi_dung_id,
+    m.so_nguoi_lon::int AS so_nguoi_lon,
+    m.so_tre_em::int AS so_tre_em,
+    m.ma_giu_cho::uuid AS ma_giu_cho,
+    m.het_han_luc::timestamp AS het_han_luc,
+    m.email::text AS email,
+    m.ho_ten::text AS ho_ten,
+    m.ten_tour::text AS ten_tour,
+    m.ngay_khoi_hanh::date AS ngay_khoi_hanh
+FROM moi_danh_sach_cho($1::int, $2::int) m
+`
+
+type PromoteWaitlistParams struct {
+	KhoiHanhID int32 `json:"khoi_hanh_id"`
+	SoPhut     int32 `json:"so_phut"`
+}
+
+type PromoteWaitlistRow struct {
+	DanhSachChoID int32            `json:"danh_sach_cho_id"`
+	KhoiHanhID    int32            `json:"khoi_hanh_id"`
+	NguoiDungID   pgtype.UUID      `json:"nguoi_dung_id"`
+	SoNguoiLon    int32            `json:"so_nguoi_lon"`
+	SoTreEm       int32            `json:"so_tre_em"`
+	MaGiuCho      pgtype.UUID      `json:"ma_giu_cho"`
+	HetHanLuc     pgtype.Timestamp `json:"het_han_luc"`
+	Email         string           `json:"email"`
+	HoTen         string           `json:"ho_ten"`
+	TenTour       string           `json:"ten_tour"`
+	NgayKhoiHanh  pgtype.Date      `json:"ngay_khoi_hanh"`
+}
+
+// Mời các lượt chờ của khởi hành (tạo giữ chỗ có thời hạn), trả về thông tin để gửi thông báo và email
+func (q *Queries) PromoteWaitlist(ctx context.Context, arg PromoteWaitlistParams) ([]PromoteWaitlistRow, error) {
+	rows, err := q.db.Query(ctx, promoteWaitlist, arg.KhoiHanhID, arg.SoPhut)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PromoteWaitlistRow
+	for rows.Next() {
+		var i PromoteWaitlistRow
+		if err := rows.Scan(
+			&i.DanhSachChoID,
+			&i.KhoiHanhID,
+			&i.NguoiDungID,
+			&i.SoNguoiLon,
+			&i.SoTreEm,
+			&i.MaGiuCho,
+			&i.HetHanLuc,
+			&i.Email,
+			&i.HoTen,
+			&i.TenTour,
+			&i.NgayKhoiHanh,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const syncWaitlistOffers = `-- name: SyncWaitlistOffers :execrows
+UPDATE danh_sach_cho d
+SET trang_thai = CASE g.trang_thai
+        WHEN 'da_su_dung' THEN 'da_dat'
+        WHEN 'het_han' THEN 'het_han'
+        ELSE 'da_huy'
+    END,
+    ngay_cap_nhat = CURRENT_TIMESTAMP
+FROM giu_cho g
+WHERE g.id = d.giu_cho_id
+    AND d.trang_thai = 'da_moi'
+    AND g.trang_thai <> 'dang_giu'
+`
+
+// Cập nhật lượt chờ đã được mời theo trạng thái giữ chỗ: đã dùng -> da_dat, hết hạn -> het_han, bị hủy -> da_huy
+func (q *Queries) SyncWaitlistOffers(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, syncWaitlistOffers)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
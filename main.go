@@ -23,6 +23,10 @@ import (
 // @name Authorization
 // @responseHeader 200 {string} X-Response-Time "Response time in milliseconds"
 
+// @securityDefinitions.apikey PartnerApiKey
+// @in header
+// @name X-API-Key
+
 func init() {
 	config.LoadEnv()
 